@@ -8,20 +8,22 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
+	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
 	"github.com/noah-isme/gema-go-api/internal/config"
 	"github.com/noah-isme/gema-go-api/internal/database"
 	"github.com/noah-isme/gema-go-api/internal/handler"
 	"github.com/noah-isme/gema-go-api/internal/middleware"
-	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/observability"
 	"github.com/noah-isme/gema-go-api/internal/repository"
 	"github.com/noah-isme/gema-go-api/internal/router"
 	"github.com/noah-isme/gema-go-api/internal/service"
+	"github.com/noah-isme/gema-go-api/internal/utils"
 	"github.com/noah-isme/gema-go-api/pkg/ai"
 	cloud "github.com/noah-isme/gema-go-api/pkg/cloudinary"
 	dockerexec "github.com/noah-isme/gema-go-api/pkg/docker"
@@ -32,46 +34,75 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to load configuration: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+	settings := config.NewProvider(cfg)
 
 	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	observability.SetLogContentPolicy(observability.LogContentPolicy{
+		MaxLength: cfg.LogContentMaxLength,
+		Redact:    cfg.LogRedactContent,
+	})
+	shutdownTracing, err := observability.InitTracing(context.Background(), observability.TracingConfig{
+		Endpoint:       cfg.OTelEndpoint,
+		ServiceName:    cfg.OTelServiceName,
+		ServiceVersion: cfg.AppVersion,
+		Environment:    cfg.AppEnv,
+	})
+	if err != nil {
+		log.Fatalf("failed to initialise tracing: %v", err)
+	}
+	if cfg.OTelEndpoint != "" {
+		logger.Info().Str("endpoint", cfg.OTelEndpoint).Msg("exporting traces over otlp")
+	}
+	for _, weak := range cfg.WeakSecrets() {
+		logger.Warn().Err(weak).Msg("weak secret configured; startup will fail in production")
+	}
 
-	db, err := database.ConnectPostgres(cfg.DatabaseURL)
+	db, err := database.ConnectPostgres(cfg.DatabaseURL, database.PoolConfig{
+		MaxOpenConns:    cfg.DatabaseMaxOpenConns,
+		MaxIdleConns:    cfg.DatabaseMaxIdleConns,
+		ConnMaxLifetime: cfg.DatabaseConnMaxLifetime,
+		ConnMaxIdleTime: cfg.DatabaseConnMaxIdleTime,
+	})
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
+	if sqlDB, err := db.DB(); err == nil {
+		if err := observability.RegisterDBStats(sqlDB, "postgres"); err != nil {
+			logger.Warn().Err(err).Msg("failed to register database pool metrics")
+		}
+	}
 
-	// Gabungan migrasi dari kedua cabang
-	if err := db.AutoMigrate(
-		&models.Student{},
-		&models.Assignment{},
-		&models.Submission{},
-		&models.SubmissionGradeHistory{},
-		&models.WebAssignment{},
-		&models.WebSubmission{},
-		&models.CodingTask{},
-		&models.CodingSubmission{},
-		&models.CodingEvaluation{},
-		&models.ActivityLog{},
-		&models.ChatMessage{},
-		&models.Notification{},
-		&models.DiscussionThread{},
-		&models.DiscussionReply{},
-		&models.Announcement{},
-		&models.GalleryItem{},
-		&models.TutorialArticle{},
-		&models.TutorialProject{},
-		&models.RoadmapStage{},
-		&models.ContactSubmission{},
-		&models.UploadRecord{},
-	); err != nil {
-		log.Fatalf("failed to migrate database: %v", err)
+	switch cfg.DatabaseMigrations {
+	case "auto":
+		if err := database.Migrate(context.Background(), db, logger); err != nil {
+			log.Fatalf("failed to migrate database: %v", err)
+		}
+	case "verify":
+		if err := database.CheckSchema(context.Background(), db); err != nil {
+			log.Fatalf("refusing to start: %v", err)
+		}
 	}
 
-	redisClient, err := database.ConnectRedis(cfg.RedisURL)
-	if err != nil {
-		log.Fatalf("failed to connect to redis: %v", err)
+	var redisClient *redis.Client
+	if cfg.RedisURL != "" {
+		redisClient, err = database.ConnectRedis(cfg.RedisURL)
+		if err != nil {
+			log.Fatalf("failed to connect to redis: %v", err)
+		}
+		defer redisClient.Close()
+	} else {
+		logger.Warn().Int("max_entries", cfg.CacheMemoryMaxEntries).Msg("redis url not configured; using in-memory cache fallback")
+	}
+	cacheRetry := cache.WriteRetry{MaxRetries: cfg.CacheWriteRetries, BaseDelay: cfg.CacheWriteRetryDelay}
+	cacheStore := cache.WithWriteRetry(cache.New(redisClient, cfg.CacheMemoryMaxEntries), cacheRetry)
+	// Chat previews are shared across nodes, so they are only cached in Redis.
+	var chatCache cache.Store
+	if redisClient != nil {
+		chatCache = cacheStore
 	}
-	defer redisClient.Close()
 
 	var natsConn *nats.Conn
 	if cfg.NATSURL != "" {
@@ -81,6 +112,21 @@ func main() {
 		}
 		defer natsConn.Drain()
 	}
+	var natsRelay *service.NATSRelay
+	switch {
+	case natsConn == nil:
+	case cfg.NATSJetStream:
+		natsRelay, err = service.NewJetStreamRelay(natsConn, cfg.RedisPubSubChannel, service.JetStreamConfig{
+			Stream: cfg.NATSStream,
+			MaxAge: cfg.NATSStreamMaxAge,
+			Node:   cfg.NATSNodeName,
+		})
+		if err != nil {
+			log.Fatalf("failed to set up nats jetstream: %v", err)
+		}
+	default:
+		natsRelay = service.NewNATSRelay(natsConn)
+	}
 
 	uploader, err := cloud.New(cloud.Config{
 		CloudName: cfg.CloudinaryCloudName,
@@ -92,13 +138,18 @@ func main() {
 		log.Fatalf("failed to create cloudinary client: %v", err)
 	}
 
-	validate := validator.New(validator.WithRequiredStructEnabled())
+	// Student work is stored privately and only reachable through the
+	// signed, access-checked download endpoints.
+	privateUploader := uploader.Private()
+
+	validate := utils.NewValidator()
 
 	// Repositori gabungan
 	assignmentRepo := repository.NewAssignmentRepository(db)
 	submissionRepo := repository.NewSubmissionRepository(db)
 	adminStudentRepo := repository.NewAdminStudentRepository(db)
 	adminSubmissionRepo := repository.NewAdminSubmissionRepository(db)
+	fingerprintRepo := repository.NewSubmissionFingerprintRepository(db)
 	activityRepo := repository.NewActivityLogRepository(db)
 	analyticsRepo := repository.NewAdminAnalyticsRepository(db)
 	announcementRepo := repository.NewAnnouncementRepository(db)
@@ -106,6 +157,7 @@ func main() {
 	tutorialArticleRepo := repository.NewTutorialArticleRepository(db)
 	tutorialProjectRepo := repository.NewTutorialProjectRepository(db)
 	roadmapRepo := repository.NewRoadmapStageRepository(db)
+	roadmapProgressRepo := repository.NewRoadmapProgressRepository(db)
 	contactRepo := repository.NewContactRepository(db)
 	uploadRepo := repository.NewUploadRepository(db)
 
@@ -120,54 +172,157 @@ func main() {
 	discussionRepo := repository.NewDiscussionRepository(db)
 
 	// Services
+	submissionLimits := service.SubmissionSizeLimits{DefaultMB: cfg.SubmissionMaxMB, RoleMB: cfg.SubmissionRoleMaxMB, AssignmentMaxMB: cfg.SubmissionAssignmentMaxMB}
 	assignmentService := service.NewAssignmentService(assignmentRepo, validate, uploader, logger)
-	submissionService := service.NewSubmissionService(submissionRepo, assignmentRepo, validate, uploader, logger)
-	dashboardService := service.NewStudentDashboardService(assignmentRepo, submissionRepo, redisClient, cfg.DashboardCacheTTL, logger)
-	webLabService := service.NewWebLabService(webAssignmentRepo, webSubmissionRepo, studentRepo, validate, uploader, logger)
-	activityService := service.NewActivityService(activityRepo, validate, logger)
+	similarityService := service.NewSubmissionSimilarityService(fingerprintRepo, logger)
+	dashboardService := service.NewStudentDashboardService(assignmentRepo, submissionRepo, cacheStore, func() time.Duration { return settings.Current().DashboardCacheTTL }, logger)
+	dashboardInvalidator := service.NewDashboardCacheInvalidator(cacheStore, logger)
+	activityBroker := service.NewActivityBroker(redisClient, cfg.RedisPubSubChannel, natsRelay, logger)
+	webhookService := service.NewWebhookService(repository.NewWebhookRepository(db), repository.NewOutboxRepository(db), validate, service.WebhookConfig{
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		RetryDelay:  cfg.WebhookRetryDelay,
+		Timeout:     cfg.WebhookTimeout,
+	}, logger)
+	activityService := service.NewActivityService(activityRepo, activityBroker, webhookService, validate, logger)
+	webArchiveLimits := service.WebArchiveLimits{MaxEntries: cfg.WebArchiveMaxEntries, MaxFileMB: cfg.WebArchiveMaxFileMB}
+	webLabService := service.NewWebLabService(webAssignmentRepo, webSubmissionRepo, studentRepo, validate, uploader, submissionLimits, webArchiveLimits, activityService, logger)
+	submissionService := service.NewSubmissionService(submissionRepo, assignmentRepo, validate, privateUploader, similarityService, cfg.SubmissionMimeTypes, submissionLimits, activityService, dashboardInvalidator, logger)
 	adminStudentService := service.NewAdminStudentService(adminStudentRepo, validate, activityService, logger)
-	adminAssignmentService := service.NewAdminAssignmentService(assignmentRepo, validate, activityService, logger)
-	adminGradingService := service.NewAdminGradingService(adminSubmissionRepo, validate, activityService, logger)
-	adminAnalyticsService := service.NewAdminAnalyticsService(analyticsRepo, redisClient, cfg.AnalyticsCacheTTL, logger)
-	adminGalleryService := service.NewAdminGalleryService(galleryRepo, validate, activityService, logger)
-	adminAnnouncementService := service.NewAdminAnnouncementService(announcementRepo, redisClient, validate, activityService, logger)
-	notificationService := service.NewNotificationService(notificationRepo, redisClient, cfg.RedisPubSubChannel, natsConn, validate, logger)
-	chatService := service.NewChatService(chatRepo, redisClient, cfg.RedisPubSubChannel, natsConn, validate, logger)
+	adminAssignmentService := service.NewAdminAssignmentService(assignmentRepo, validate, submissionLimits, activityService, logger)
+	adminGradingService := service.NewAdminGradingService(adminSubmissionRepo, validate, activityService, dashboardInvalidator, logger)
+	adminAnalyticsService := service.NewAdminAnalyticsService(analyticsRepo, cacheStore, func() time.Duration { return settings.Current().AnalyticsCacheTTL }, activityService, logger)
+	adminGalleryService := service.NewAdminGalleryService(galleryRepo, uploader, cfg.UploadMaxMB, validate, activityService, logger)
+	adminAnnouncementService := service.NewAdminAnnouncementService(announcementRepo, cacheStore, validate, activityService, logger)
+	adminRoadmapService := service.NewAdminRoadmapService(roadmapRepo, cacheStore, validate, activityService, logger)
+	notificationService := service.NewNotificationService(notificationRepo, repository.NewOutboxRepository(db), redisClient, cfg.RedisPubSubChannel, natsRelay, validate, logger)
+	adminNotificationService := service.NewAdminNotificationService(notificationService, adminStudentRepo, validate, activityService, logger)
+	chatService := service.NewChatService(chatRepo, redisClient, chatCache, cfg.RedisPubSubChannel, service.ChatStreamConfig{
+		Enabled: cfg.RedisChatStreams,
+		MaxLen:  cfg.RedisChatStreamMaxLen,
+		MaxAge:  cfg.RedisChatStreamMaxAge,
+	}, natsRelay, validate, activityService, logger)
 	discussionService := service.NewDiscussionService(discussionRepo, notificationService, validate, logger)
-	activityFeedService := service.NewActivityFeedService(activityRepo, redisClient, 45*time.Second, logger)
-	announcementService := service.NewAnnouncementService(announcementRepo, redisClient, cfg.AnnouncementsCacheTTL, logger)
+	discussionAutoCloser := service.NewDiscussionAutoCloser(discussionRepo, notificationService, service.DiscussionAutoCloseConfig{
+		InactiveAfter: cfg.DiscussionStaleAfter,
+		Interval:      cfg.DiscussionStaleCheck,
+		Action:        cfg.DiscussionStaleAction,
+		NotifyAuthor:  cfg.DiscussionStaleNotify,
+	}, logger)
+	activityPruner := service.NewActivityPruner(activityService, service.ActivityRetentionConfig{
+		Retention: cfg.ActivityRetention,
+		Interval:  cfg.ActivityPruneInterval,
+	}, logger)
+	activityFeedService := service.NewActivityFeedService(activityRepo, cacheStore, 45*time.Second, activityBroker, logger)
+	announcementService := service.NewAnnouncementService(announcementRepo, cacheStore, func() time.Duration { return settings.Current().AnnouncementsCacheTTL }, logger)
 	galleryService := service.NewGalleryService(galleryRepo, cfg.GalleryCDNBaseURL, logger)
-	tutorialContentService := service.NewTutorialContentService(tutorialArticleRepo, tutorialProjectRepo, validate, logger)
-	roadmapService := service.NewRoadmapService(roadmapRepo, redisClient, cfg.RoadmapCacheTTL, logger)
+	tutorialContentService := service.NewTutorialContentService(tutorialArticleRepo, tutorialProjectRepo, validate, activityService, logger)
+	roadmapService := service.NewRoadmapService(roadmapRepo, roadmapProgressRepo, cacheStore, cfg.RoadmapCacheTTL, logger)
 
-	contactDelivery := service.NewLogContactDelivery(logger)
-	contactService := service.NewContactService(contactRepo, redisClient, validate, contactDelivery, logger)
-	adminContactService := service.NewAdminContactService(contactRepo, logger)
-	uploadService := service.NewUploadService(uploader, uploadRepo, cfg.UploadMaxMB, logger)
+	var contactDelivery service.ContactDelivery = service.NewLogContactDelivery(logger)
+	if cfg.ContactDeliveryProvider == "smtp" {
+		contactDelivery = service.NewSMTPContactDelivery(service.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+			To:       cfg.ContactNotifyTo,
+		}, logger)
+	}
+	contactDelivery = service.WithContactDeliveryRetry(contactDelivery, service.ContactDeliveryRetry{
+		MaxRetries: cfg.ContactDeliveryRetries,
+		BaseDelay:  cfg.ContactDeliveryRetryDelay,
+	})
+	contactService := service.NewContactService(contactRepo, redisClient, validate, contactDelivery, service.ContactLimits{
+		DedupeTTL:      cfg.ContactDedupeTTL,
+		PerEmail:       cfg.ContactRateLimit,
+		PerEmailWindow: cfg.ContactRateWindow,
+	}, activityService, logger)
+	contactRedeliverer := service.NewContactRedeliverer(contactRepo, contactDelivery, service.ContactRedeliveryConfig{
+		After:    cfg.ContactRedeliverAfter,
+		Interval: cfg.ContactRedeliverInterval,
+	}, logger)
+	adminContactService := service.NewAdminContactService(contactRepo, validate, activityService, logger)
+	uploadService := service.NewUploadService(uploader, uploadRepo, cfg.UploadMaxMB, cfg.UploadMimeTypes, service.DirectUploadConfig{Signer: uploader, MaxMB: cfg.UploadDirectMaxMB}, validate, logger)
 	seedService := service.NewSeedService(announcementRepo, galleryRepo, cfg.SeedEnabled, cfg.SeedToken, logger)
+	accessDenylist := service.NewTokenDenylist(redisClient, service.AccessTokenDenylistPrefix)
+	authService := service.NewAuthService(service.AuthTokenConfig{
+		AccessSecret:          cfg.JWTSecret,
+		RefreshSecret:         cfg.JWTRefreshSecret,
+		PreviousRefreshSecret: cfg.JWTRefreshSecretPrevious,
+		AccessTTL:             cfg.JWTAccessTTL,
+		RefreshTTL:            cfg.JWTRefreshTTL,
+	}, service.NewStudentAccountLookup(studentRepo), redisClient, accessDenylist, validate, logger)
 
 	serviceCtx, serviceCancel := context.WithCancel(context.Background())
 	chatService.Start(serviceCtx)
 	notificationService.Start(serviceCtx)
+	activityBroker.Start(serviceCtx)
+	discussionAutoCloser.Start(serviceCtx)
+	activityPruner.Start(serviceCtx)
+	contactRedeliverer.Start(serviceCtx)
+	webhookService.Start(serviceCtx)
+
+	executorImages := cfg.DockerAllowedImages
+	if len(executorImages) == 0 {
+		executorImages = service.CodingLanguageImages()
+	}
 
 	executor, err := dockerexec.NewDockerExecutor(dockerexec.Config{
-		Host:          cfg.DockerHost,
-		Timeout:       cfg.ExecutionTimeout,
-		MemoryLimitMB: int64(cfg.CodeRunMemoryMB),
-		CPUShares:     int64(cfg.CodeRunCPUShares),
-		WorkingDir:    "/workspace",
-		Logger:        logger,
+		Host:           cfg.DockerHost,
+		Timeout:        cfg.ExecutionTimeout,
+		MemoryLimitMB:  int64(cfg.CodeRunMemoryMB),
+		CPUShares:      int64(cfg.CodeRunCPUShares),
+		CPUQuota:       int64(cfg.CodeRunCPUQuota),
+		CPUPeriod:      int64(cfg.CodeRunCPUPeriod),
+		PidsLimit:      int64(cfg.CodeRunPidsLimit),
+		DiskQuotaMB:    int64(cfg.CodeRunDiskMB),
+		MaxOutputBytes: int64(cfg.CodeRunMaxOutputKB) << 10,
+		MaxConcurrent:  cfg.CodeRunMaxConcurrent,
+		MaxQueue:       cfg.CodeRunMaxQueue,
+		QueueTimeout:   cfg.CodeRunQueueTimeout,
+		WorkingDir:     "/workspace",
+		AllowedImages:  executorImages,
+		Logger:         logger,
 	})
 	if err != nil {
 		log.Fatalf("failed to create docker executor: %v", err)
 	}
 	defer executor.Close()
 
+	if cfg.DockerPrewarmImages {
+		go func() {
+			if err := executor.EnsureImages(serviceCtx, executorImages); err != nil {
+				logger.Warn().Err(err).Msg("failed to prewarm executor images")
+			}
+		}()
+	}
+
+	tokenPrices := make(map[string]ai.TokenPrice, len(cfg.AITokenPrices))
+	for model, price := range cfg.AITokenPrices {
+		tokenPrices[model] = ai.TokenPrice{Prompt: price.Prompt, Completion: price.Completion}
+	}
+
+	aiSettings := func() ai.ModelSettings {
+		current := settings.Current()
+		return ai.ModelSettings{Model: current.AIModel, Temperature: current.AITemperature}
+	}
+
 	var evaluator ai.Evaluator
 	switch cfg.AIProvider {
 	case "openai":
 		if cfg.OpenAIAPIKey != "" {
-			eval, evalErr := ai.NewOpenAIEvaluator(ai.OpenAIConfig{APIKey: cfg.OpenAIAPIKey, Logger: logger})
+			eval, evalErr := ai.NewOpenAIEvaluator(ai.OpenAIConfig{
+				APIKey:         cfg.OpenAIAPIKey,
+				Model:          cfg.AIModel,
+				MaxTokens:      cfg.AIMaxTokens,
+				Temperature:    cfg.AITemperature,
+				Settings:       aiSettings,
+				MaxRetries:     cfg.AIMaxRetries,
+				RetryBaseDelay: cfg.AIRetryBaseDelay,
+				Prices:         tokenPrices,
+				Logger:         logger,
+			})
 			if evalErr != nil {
 				log.Fatalf("failed to create openai evaluator: %v", evalErr)
 			}
@@ -177,7 +332,17 @@ func main() {
 		}
 	case "anthropic":
 		if cfg.AnthropicAPIKey != "" {
-			eval, evalErr := ai.NewAnthropicEvaluator(ai.AnthropicConfig{APIKey: cfg.AnthropicAPIKey})
+			eval, evalErr := ai.NewAnthropicEvaluator(ai.AnthropicConfig{
+				APIKey:         cfg.AnthropicAPIKey,
+				Model:          cfg.AIModel,
+				MaxTokens:      cfg.AIMaxTokens,
+				Temperature:    cfg.AITemperature,
+				Settings:       aiSettings,
+				MaxRetries:     cfg.AIMaxRetries,
+				RetryBaseDelay: cfg.AIRetryBaseDelay,
+				Prices:         tokenPrices,
+				Logger:         logger,
+			})
 			if evalErr != nil {
 				log.Fatalf("failed to create anthropic evaluator: %v", evalErr)
 			}
@@ -185,6 +350,8 @@ func main() {
 		} else {
 			logger.Warn().Msg("anthropic provider selected but API key missing; AI evaluation disabled")
 		}
+	case "rulebased":
+		evaluator = ai.NewRuleBasedEvaluator()
 	default:
 		if cfg.AIProvider != "" {
 			logger.Warn().Str("provider", cfg.AIProvider).Msg("unknown AI provider, AI evaluation disabled")
@@ -192,6 +359,10 @@ func main() {
 	}
 
 	codingTaskService := service.NewCodingTaskService(codingTaskRepo, logger)
+	var codingLogStorage service.FileStorage
+	if cfg.CodeRunPersistLogs {
+		codingLogStorage = privateUploader
+	}
 	codingSubmissionService := service.NewCodingSubmissionService(
 		codingSubmissionRepo,
 		codingTaskRepo,
@@ -200,15 +371,25 @@ func main() {
 		validate,
 		logger,
 		service.CodingSubmissionConfig{
-			ExecutionTimeout: cfg.ExecutionTimeout,
-			MemoryLimitMB:    cfg.CodeRunMemoryMB,
-			CPUShares:        cfg.CodeRunCPUShares,
+			ExecutionTimeout:   cfg.ExecutionTimeout,
+			MemoryLimitMB:      cfg.CodeRunMemoryMB,
+			CPUShares:          cfg.CodeRunCPUShares,
+			CPUQuota:           cfg.CodeRunCPUQuota,
+			CPUPeriod:          cfg.CodeRunCPUPeriod,
+			PidsLimit:          cfg.CodeRunPidsLimit,
+			DiskQuotaMB:        cfg.CodeRunDiskMB,
+			EvaluationCache:    cacheStore,
+			EvaluationCacheTTL: cfg.AIEvaluationCacheTTL,
+			MaxOutputBytes:     int64(cfg.CodeRunMaxOutputKB) << 10,
+			InlineOutputBytes:  cfg.CodeRunInlineOutputKB << 10,
+			LogStorage:         codingLogStorage,
 		},
 	)
 
 	// Handlers
 	assignmentHandler := handler.NewAssignmentHandler(assignmentService, validate, logger)
 	submissionHandler := handler.NewSubmissionHandler(submissionService, validate, logger)
+	similarityHandler := handler.NewSubmissionSimilarityHandler(similarityService, logger)
 	studentDashboardHandler := handler.NewStudentDashboardHandler(dashboardService, logger)
 	webLabHandler := handler.NewWebLabHandler(webLabService, validate, logger)
 	codingTaskHandler := handler.NewCodingTaskHandler(codingTaskService, logger)
@@ -220,6 +401,7 @@ func main() {
 	adminActivityHandler := handler.NewAdminActivityHandler(activityService, logger)
 	adminGalleryHandler := handler.NewAdminGalleryHandler(adminGalleryService, logger)
 	adminAnnouncementHandler := handler.NewAdminAnnouncementHandler(adminAnnouncementService, logger)
+	adminNotificationHandler := handler.NewAdminNotificationHandler(adminNotificationService, logger)
 	chatHandler := handler.NewChatHandler(chatService, validate, logger)
 	notificationHandler := handler.NewNotificationHandler(notificationService, logger, cfg.SSEClientTimeout)
 	discussionHandler := handler.NewDiscussionHandler(discussionService, validate, logger)
@@ -228,19 +410,25 @@ func main() {
 	galleryHandler := handler.NewGalleryHandler(galleryService, logger)
 	tutorialContentHandler := handler.NewTutorialContentHandler(tutorialContentService, logger)
 	roadmapHandler := handler.NewRoadmapHandler(roadmapService, logger)
+	adminRoadmapHandler := handler.NewAdminRoadmapHandler(adminRoadmapService, logger)
 	adminContactHandler := handler.NewAdminContactHandler(adminContactService, logger)
+	adminWebhookHandler := handler.NewAdminWebhookHandler(webhookService, logger)
 	contactHandler := handler.NewContactHandler(contactService, logger)
 	uploadHandler := handler.NewUploadHandler(uploadService, logger)
 	seedHandler := handler.NewSeedHandler(seedService, logger)
+	authHandler := handler.NewAuthHandler(authService, logger)
 
 	// App & router
 	app := fiber.New(fiber.Config{
 		AppName:      cfg.AppName,
 		ServerHeader: cfg.AppName,
+		// Fiber rejects bodies over 4MB by default, below the upload limits.
+		BodyLimit: cfg.RequestBodyLimit(),
 	})
 
-	middleware.Register(app, middleware.Config{Logger: &logger})
+	middleware.Register(app, middleware.Config{Logger: &logger, FeatureFlagSecret: cfg.FeatureFlagSecret})
 	app.Get("/metrics", observability.MetricsHandler())
+	jwtOptions := middleware.JWTOptions{Denylist: accessDenylist, FailOpen: cfg.JWTDenylistFailOpen}
 	router.Register(app, cfg, router.Dependencies{
 		AssignmentHandler:        assignmentHandler,
 		SubmissionHandler:        submissionHandler,
@@ -251,9 +439,11 @@ func main() {
 		AdminStudentHandler:      adminStudentHandler,
 		AdminAssignmentHandler:   adminAssignmentHandler,
 		AdminGradingHandler:      adminGradingHandler,
+		SimilarityHandler:        similarityHandler,
 		AdminAnalyticsHandler:    adminAnalyticsHandler,
 		AdminActivityHandler:     adminActivityHandler,
 		AdminAnnouncementHandler: adminAnnouncementHandler,
+		AdminNotificationHandler: adminNotificationHandler,
 		AdminGalleryHandler:      adminGalleryHandler,
 		ChatHandler:              chatHandler,
 		NotificationHandler:      notificationHandler,
@@ -262,13 +452,22 @@ func main() {
 		AnnouncementHandler:      announcementHandler,
 		GalleryHandler:           galleryHandler,
 		AdminContactHandler:      adminContactHandler,
+		AdminWebhookHandler:      adminWebhookHandler,
 		RoadmapHandler:           roadmapHandler,
+		AdminRoadmapHandler:      adminRoadmapHandler,
 		TutorialContentHandler:   tutorialContentHandler,
 		ContactHandler:           contactHandler,
 		UploadHandler:            uploadHandler,
 		SeedHandler:              seedHandler,
-		JWTMiddleware:            middleware.JWTProtected(cfg.JWTSecret),
+		AuthHandler:              authHandler,
+		JWTMiddleware:            middleware.JWTProtected(cfg.JWTSecret, jwtOptions),
+		OptionalJWTMiddleware:    middleware.JWTOptional(cfg.JWTSecret, jwtOptions),
+		ReadinessProbes:          readinessProbes(db, redisClient, natsConn, executor),
+		RateLimitRedis:           redisClient,
+		IdempotencyRedis:         redisClient,
+		ConfigProvider:           settings,
 	})
+	go reloadOnHangup(serviceCtx, settings, logger)
 
 	go func() {
 		if err := app.Listen(cfg.HTTPAddress()); err != nil {
@@ -276,15 +475,104 @@ func main() {
 		}
 	}()
 
-	waitForShutdown(app, serviceCancel)
+	waitForShutdown(app, serviceCancel, webhookService, chatService, notificationService, activityBroker)
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), tracingFlushTimeout)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		log.Printf("failed to flush traces: %v", err)
+	}
+}
+
+// readinessProbes checks Postgres and, when configured, Redis and NATS. The
+// Docker daemon only backs the coding lab, so its probe is optional.
+func readinessProbes(db *gorm.DB, redisClient *redis.Client, natsConn *nats.Conn, executor *dockerexec.DockerExecutor) []handler.ReadinessProbe {
+	probes := []handler.ReadinessProbe{{
+		Name: "postgres",
+		Check: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}}
+	if redisClient != nil {
+		probes = append(probes, handler.ReadinessProbe{
+			Name: "redis",
+			Check: func(ctx context.Context) error {
+				return redisClient.Ping(ctx).Err()
+			},
+		})
+	}
+	if natsConn != nil {
+		probes = append(probes, handler.ReadinessProbe{
+			Name:  "nats",
+			Check: natsConn.FlushWithContext,
+		})
+	}
+	probes = append(probes, handler.ReadinessProbe{
+		Name:     "docker",
+		Check:    executor.Ping,
+		Optional: true,
+	})
+	return probes
 }
 
-func waitForShutdown(app *fiber.App, stopBackground context.CancelFunc) {
+// realtimeDrainTimeout bounds how long shutdown waits for websocket and SSE
+// clients to be told to reconnect and for their queued messages to flush.
+const realtimeDrainTimeout = 3 * time.Second
+
+// webhookDrainTimeout bounds how long shutdown spends delivering the webhooks
+// the last requests recorded; the rest wait in the outbox.
+const webhookDrainTimeout = 5 * time.Second
+
+// tracingFlushTimeout bounds how long shutdown waits to export buffered spans.
+const tracingFlushTimeout = 5 * time.Second
+
+// reloadOnHangup reloads the hot-reloadable configuration on every SIGHUP
+// until ctx ends.
+func reloadOnHangup(ctx context.Context, settings *config.Provider, logger zerolog.Logger) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			changed, err := settings.Reload()
+			if err != nil {
+				logger.Error().Err(err).Msg("configuration reload rejected; keeping current values")
+				continue
+			}
+			logger.Info().Strs("changed", changed).Msg("configuration reloaded")
+		}
+	}
+}
+
+// realtimeDrainer is a service holding long-lived client connections.
+type realtimeDrainer interface {
+	Shutdown(ctx context.Context) error
+}
+
+func waitForShutdown(app *fiber.App, stopBackground context.CancelFunc, webhooks service.WebhookService, drainers ...realtimeDrainer) {
 	shutdownCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
 	<-shutdownCtx.Done()
 
+	// Realtime clients are drained first, while the Redis and NATS consumers
+	// still deliver, so they can reconnect to another instance cleanly.
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), realtimeDrainTimeout)
+	for _, drainer := range drainers {
+		if err := drainer.Shutdown(drainCtx); err != nil {
+			log.Printf("realtime drain incomplete: %v", err)
+		}
+	}
+	cancelDrain()
+
 	if stopBackground != nil {
 		stopBackground()
 	}
@@ -296,5 +584,12 @@ func waitForShutdown(app *fiber.App, stopBackground context.CancelFunc) {
 		log.Printf("graceful shutdown failed: %v", err)
 	}
 
+	// Webhooks go last so events from requests finished above are sent too.
+	webhookCtx, cancelWebhooks := context.WithTimeout(context.Background(), webhookDrainTimeout)
+	defer cancelWebhooks()
+	if err := webhooks.Shutdown(webhookCtx); err != nil {
+		log.Printf("webhook drain incomplete: %v", err)
+	}
+
 	log.Println("server stopped")
 }
@@ -0,0 +1,47 @@
+// Command migrate applies the database schema and records its version, so the
+// API can boot with GEMA_DATABASE_MIGRATIONS=verify. With -check it only
+// reports whether the schema is current.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/rs/zerolog"
+
+	"github.com/noah-isme/gema-go-api/internal/config"
+	"github.com/noah-isme/gema-go-api/internal/database"
+)
+
+func main() {
+	check := flag.Bool("check", false, "only verify the schema is up to date; exit 1 when it is not")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load configuration: %v", err)
+	}
+
+	logger := zerolog.New(os.Stdout).With().Timestamp().Str("component", "migrate").Logger()
+
+	db, err := database.ConnectPostgres(cfg.DatabaseURL, database.PoolConfig{MaxOpenConns: 2})
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+
+	ctx := context.Background()
+	if *check {
+		if err := database.CheckSchema(ctx, db); err != nil {
+			log.Fatalf("%v", err)
+		}
+		logger.Info().Str("version", database.SchemaVersion()).Msg("schema is up to date")
+		return
+	}
+
+	if err := database.Migrate(ctx, db, logger); err != nil {
+		log.Fatalf("failed to migrate database: %v", err)
+	}
+	logger.Info().Str("version", database.SchemaVersion()).Msg("schema migrated")
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrMiss is returned by Store.Get when a key is absent or has expired.
+var ErrMiss = errors.New("cache miss")
+
+// Store abstracts the get/set operations shared by cached services so they can
+// run against Redis or the in-process fallback interchangeably.
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// New returns a Redis-backed store when a client is available, an in-memory LRU
+// bounded to maxEntries when Redis is absent, or nil when caching is disabled.
+func New(client *redis.Client, maxEntries int) Store {
+	if client != nil {
+		return NewRedisStore(client)
+	}
+	if maxEntries > 0 {
+		return NewMemoryStore(maxEntries)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStats reports the effectiveness of the in-memory store.
+type MemoryStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+// MemoryStore is a bounded, concurrency-safe LRU cache with per-key TTLs used
+// when Redis is not configured.
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+	hits       uint64
+	misses     uint64
+	now        func() time.Time
+}
+
+// NewMemoryStore builds an LRU store holding at most maxEntries keys.
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = 1
+	}
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element, maxEntries),
+		now:        time.Now,
+	}
+}
+
+// Get returns the cached payload, promoting the key to most recently used.
+func (s *MemoryStore) Get(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		s.misses++
+		return "", ErrMiss
+	}
+
+	entry := element.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && !s.now().Before(entry.expiresAt) {
+		s.removeElement(element)
+		s.misses++
+		return "", ErrMiss
+	}
+
+	s.order.MoveToFront(element)
+	s.hits++
+	return entry.value, nil
+}
+
+// Set stores the payload, evicting the least recently used key when full.
+// A non-positive TTL keeps the entry until it is evicted.
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = s.now().Add(ttl)
+	}
+
+	if element, ok := s.entries[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.value = string(value)
+		entry.expiresAt = expiresAt
+		s.order.MoveToFront(element)
+		return nil
+	}
+
+	element := s.order.PushFront(&memoryEntry{key: key, value: string(value), expiresAt: expiresAt})
+	s.entries[key] = element
+
+	for s.order.Len() > s.maxEntries {
+		s.removeElement(s.order.Back())
+	}
+
+	return nil
+}
+
+// Delete removes the provided keys.
+func (s *MemoryStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := s.entries[key]; ok {
+			s.removeElement(element)
+		}
+	}
+	return nil
+}
+
+// DeletePrefix removes every key starting with prefix.
+func (s *MemoryStore) DeletePrefix(_ context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, element := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			s.removeElement(element)
+		}
+	}
+	return nil
+}
+
+// Stats returns a snapshot of hit/miss counters and current size.
+func (s *MemoryStore) Stats() MemoryStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return MemoryStats{Hits: s.hits, Misses: s.misses, Entries: s.order.Len()}
+}
+
+func (s *MemoryStore) removeElement(element *list.Element) {
+	entry := element.Value.(*memoryEntry)
+	delete(s.entries, entry.key)
+	s.order.Remove(element)
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStoreHitsMissesAndExpiry(t *testing.T) {
+	ctx := context.Background()
+	current := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(10)
+	store.now = func() time.Time { return current }
+
+	_, err := store.Get(ctx, "dashboard:student:1")
+	require.ErrorIs(t, err, ErrMiss)
+
+	require.NoError(t, store.Set(ctx, "dashboard:student:1", []byte(`{"ok":true}`), time.Minute))
+
+	value, err := store.Get(ctx, "dashboard:student:1")
+	require.NoError(t, err)
+	require.Equal(t, `{"ok":true}`, value)
+
+	current = current.Add(time.Minute)
+	_, err = store.Get(ctx, "dashboard:student:1")
+	require.ErrorIs(t, err, ErrMiss)
+
+	stats := store.Stats()
+	require.Equal(t, uint64(1), stats.Hits)
+	require.Equal(t, uint64(2), stats.Misses)
+	require.Zero(t, stats.Entries)
+}
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(2)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, store.Set(ctx, "b", []byte("2"), 0))
+
+	_, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Set(ctx, "c", []byte("3"), 0))
+
+	_, err = store.Get(ctx, "b")
+	require.ErrorIs(t, err, ErrMiss)
+	_, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+	_, err = store.Get(ctx, "c")
+	require.NoError(t, err)
+	require.Equal(t, 2, store.Stats().Entries)
+}
+
+func TestMemoryStoreDeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(10)
+
+	require.NoError(t, store.Set(ctx, "announcements:active:v1:1:20", []byte("x"), time.Minute))
+	require.NoError(t, store.Set(ctx, "roadmap:v1:sequence", []byte("y"), time.Minute))
+	require.NoError(t, store.DeletePrefix(ctx, "announcements:"))
+
+	_, err := store.Get(ctx, "announcements:active:v1:1:20")
+	require.ErrorIs(t, err, ErrMiss)
+	_, err = store.Get(ctx, "roadmap:v1:sequence")
+	require.NoError(t, err)
+}
+
+func TestMemoryStoreConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(16)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				key := fmt.Sprintf("key:%d", (worker+i)%32)
+				_ = store.Set(ctx, key, []byte("v"), time.Minute)
+				_, _ = store.Get(ctx, key)
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	require.LessOrEqual(t, store.Stats().Entries, 16)
+}
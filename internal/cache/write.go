@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultWriteRetryDelay = 50 * time.Millisecond
+	maxWriteRetryDelay     = time.Second
+)
+
+var (
+	cacheWrites = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gema",
+		Subsystem: "cache",
+		Name:      "writes_total",
+		Help:      "Number of cache writes segmented by component and result",
+	}, []string{"component", "result"})
+
+	cacheWriteRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "gema",
+		Subsystem: "cache",
+		Name:      "write_retries_total",
+		Help:      "Number of cache writes retried after a transient failure",
+	})
+)
+
+// WriteRetry bounds how often a transient Set failure is retried. Delays grow
+// exponentially from BaseDelay (50ms when unset) with jitter, capped at one
+// second.
+type WriteRetry struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+}
+
+// WithWriteRetry wraps store so Set retries transient failures such as
+// timeouts, dropped connections or a Redis replica still loading. Reads and
+// deletes are passed through untouched. A nil store or zero retries returns
+// store as is.
+func WithWriteRetry(store Store, retry WriteRetry) Store {
+	if store == nil || retry.MaxRetries <= 0 {
+		return store
+	}
+	if retry.BaseDelay <= 0 {
+		retry.BaseDelay = defaultWriteRetryDelay
+	}
+	return &retryStore{Store: store, retry: retry}
+}
+
+type retryStore struct {
+	Store
+	retry WriteRetry
+}
+
+func (s *retryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	for attempt := 0; ; attempt++ {
+		err := s.Store.Set(ctx, key, value, ttl)
+		if err == nil || attempt >= s.retry.MaxRetries || !isTransientWriteError(err) {
+			return err
+		}
+
+		cacheWriteRetries.Inc()
+		timer := time.NewTimer(s.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *retryStore) backoff(attempt int) time.Duration {
+	ceiling := s.retry.BaseDelay << attempt
+	if ceiling <= 0 || ceiling > maxWriteRetryDelay {
+		ceiling = maxWriteRetryDelay
+	}
+	return ceiling/2 + time.Duration(rand.Int63n(int64(ceiling/2)+1))
+}
+
+// isTransientWriteError reports whether a failed write is worth repeating.
+// Cancelled requests and command errors such as a wrong key type are not.
+func isTransientWriteError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	for _, prefix := range []string{"LOADING", "TRYAGAIN", "BUSY", "MASTERDOWN", "CLUSTERDOWN"} {
+		if redis.HasErrorPrefix(err, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Write encodes value as JSON and stores it under key, counting the outcome
+// against component. A nil store is a no-op. Errors are returned so callers
+// can log them, but a failed cache write should never fail the request.
+func Write(ctx context.Context, store Store, component, key string, value interface{}, ttl time.Duration) error {
+	if store == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(value)
+	if err == nil {
+		err = store.Set(ctx, key, payload, ttl)
+	}
+	if err != nil {
+		cacheWrites.WithLabelValues(component, "failed").Inc()
+		return err
+	}
+	cacheWrites.WithLabelValues(component, "stored").Inc()
+	return nil
+}
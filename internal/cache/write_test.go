@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type flakyStore struct {
+	*MemoryStore
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *flakyStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return f.MemoryStore.Set(ctx, key, value, ttl)
+}
+
+func TestWithWriteRetryRecoversFromTransientFailures(t *testing.T) {
+	ctx := context.Background()
+	flaky := &flakyStore{MemoryStore: NewMemoryStore(10), failures: 2, err: syscall.ECONNRESET}
+	store := WithWriteRetry(flaky, WriteRetry{MaxRetries: 2, BaseDelay: time.Millisecond})
+
+	require.NoError(t, Write(ctx, store, "test", "roadmap:v1", map[string]int{"stages": 3}, time.Minute))
+	require.Equal(t, 3, flaky.calls)
+
+	value, err := store.Get(ctx, "roadmap:v1")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"stages":3}`, value)
+}
+
+func TestWithWriteRetryGivesUp(t *testing.T) {
+	ctx := context.Background()
+
+	flaky := &flakyStore{MemoryStore: NewMemoryStore(10), failures: 5, err: syscall.ECONNREFUSED}
+	store := WithWriteRetry(flaky, WriteRetry{MaxRetries: 2, BaseDelay: time.Millisecond})
+	require.ErrorIs(t, Write(ctx, store, "test", "key", "value", time.Minute), syscall.ECONNREFUSED)
+	require.Equal(t, 3, flaky.calls)
+
+	permanent := &flakyStore{MemoryStore: NewMemoryStore(10), failures: 5, err: errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")}
+	store = WithWriteRetry(permanent, WriteRetry{MaxRetries: 2, BaseDelay: time.Millisecond})
+	require.Error(t, Write(ctx, store, "test", "key", "value", time.Minute))
+	require.Equal(t, 1, permanent.calls)
+}
+
+func TestWithWriteRetryStopsWhenContextEnds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	flaky := &flakyStore{MemoryStore: NewMemoryStore(10), failures: 5, err: syscall.ECONNRESET}
+	store := WithWriteRetry(flaky, WriteRetry{MaxRetries: 3, BaseDelay: time.Hour})
+	require.Error(t, store.Set(ctx, "key", []byte("value"), time.Minute))
+	require.Equal(t, 1, flaky.calls)
+}
+
+func TestWriteSkipsNilStore(t *testing.T) {
+	require.NoError(t, Write(context.Background(), nil, "test", "key", "value", time.Minute))
+	require.Nil(t, WithWriteRetry(nil, WriteRetry{MaxRetries: 2}))
+}
@@ -0,0 +1,63 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock reports the current time. Services depend on it rather than calling
+// time.Now directly so time-sensitive behaviour can be pinned in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Real returns the system clock.
+func Real() Clock {
+	return realClock{}
+}
+
+// Func adapts a plain function to the Clock interface.
+type Func func() time.Time
+
+// Now implements Clock.
+func (f Func) Now() time.Time {
+	return f()
+}
+
+// Fixed is a Clock frozen at a settable instant.
+type Fixed struct {
+	mu      sync.RWMutex
+	current time.Time
+}
+
+// NewFixed returns a clock that always reports t until moved.
+func NewFixed(t time.Time) *Fixed {
+	return &Fixed{current: t}
+}
+
+// Now implements Clock.
+func (f *Fixed) Now() time.Time {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.current
+}
+
+// Set moves the clock to t.
+func (f *Fixed) Set(t time.Time) {
+	f.mu.Lock()
+	f.current = t
+	f.mu.Unlock()
+}
+
+// Advance moves the clock forward by d.
+func (f *Fixed) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.current = f.current.Add(d)
+	f.mu.Unlock()
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,37 +14,118 @@ import (
 
 // Config holds runtime configuration values for the API service.
 type Config struct {
-	AppName                string
-	AppEnv                 string
-	AppPort                string
-	WSPort                 string
-	DatabaseURL            string
-	RedisURL               string
-	RedisPubSubChannel     string
-	NATSURL                string
-	JWTSecret              string
-	JWTRefreshSecret       string
-	CloudinaryCloudName    string
-	CloudinaryAPIKey       string
-	CloudinaryAPISecret    string
-	CloudinaryUploadFolder string
-	DashboardCacheTTL      time.Duration
-	AnalyticsCacheTTL      time.Duration
-	AnnouncementsCacheTTL  time.Duration
-	RoadmapCacheTTL        time.Duration
-	SSEClientTimeout       time.Duration
-	DockerHost             string
-	ExecutionTimeout       time.Duration
-	CodeRunMemoryMB        int
-	CodeRunCPUShares       int
-	AIProvider             string
-	OpenAIAPIKey           string
-	AnthropicAPIKey        string
-	UploadMaxMB            int
-	ContactInboxProvider   string
-	GalleryCDNBaseURL      string
-	SeedEnabled            bool
-	SeedToken              string
+	AppName                   string
+	AppVersion                string
+	AppEnv                    string
+	AppPort                   string
+	WSPort                    string
+	DatabaseURL               string
+	DatabaseMaxOpenConns      int
+	DatabaseMaxIdleConns      int
+	DatabaseConnMaxLifetime   time.Duration
+	DatabaseConnMaxIdleTime   time.Duration
+	DatabaseMigrations        string
+	RedisURL                  string
+	RedisPubSubChannel        string
+	RedisChatStreams          bool
+	RedisChatStreamMaxLen     int64
+	RedisChatStreamMaxAge     time.Duration
+	NATSURL                   string
+	NATSJetStream             bool
+	NATSStream                string
+	NATSStreamMaxAge          time.Duration
+	NATSNodeName              string
+	OTelEndpoint              string
+	OTelServiceName           string
+	JWTSecret                 string
+	JWTRefreshSecret          string
+	JWTRefreshSecretPrevious  string
+	JWTAccessTTL              time.Duration
+	JWTRefreshTTL             time.Duration
+	JWTDenylistFailOpen       bool
+	CloudinaryCloudName       string
+	CloudinaryAPIKey          string
+	CloudinaryAPISecret       string
+	CloudinaryUploadFolder    string
+	DashboardCacheTTL         time.Duration
+	AnalyticsCacheTTL         time.Duration
+	AnnouncementsCacheTTL     time.Duration
+	RoadmapCacheTTL           time.Duration
+	CacheMemoryMaxEntries     int
+	CacheWriteRetries         int
+	CacheWriteRetryDelay      time.Duration
+	SSEClientTimeout          time.Duration
+	DockerHost                string
+	DockerAllowedImages       []string
+	DockerPrewarmImages       bool
+	ExecutionTimeout          time.Duration
+	CodeRunMemoryMB           int
+	CodeRunCPUShares          int
+	CodeRunCPUQuota           int
+	CodeRunCPUPeriod          int
+	CodeRunPidsLimit          int
+	CodeRunDiskMB             int
+	CodeRunMaxConcurrent      int
+	CodeRunMaxQueue           int
+	CodeRunQueueTimeout       time.Duration
+	CodeRunInlineOutputKB     int
+	CodeRunMaxOutputKB        int
+	CodeRunPersistLogs        bool
+	AIProvider                string
+	AIModel                   string
+	AIMaxTokens               int
+	AITemperature             float32
+	AIMaxRetries              int
+	AIRetryBaseDelay          time.Duration
+	AIEvaluationCacheTTL      time.Duration
+	AITokenPrices             map[string]AITokenPrice
+	OpenAIAPIKey              string
+	AnthropicAPIKey           string
+	UploadMaxMB               int
+	UploadMimeTypes           []string
+	UploadDirectMaxMB         int
+	SubmissionMimeTypes       []string
+	SubmissionMaxMB           int
+	SubmissionRoleMaxMB       map[string]int
+	SubmissionAssignmentMaxMB int
+	WebArchiveMaxEntries      int
+	WebArchiveMaxFileMB       int
+	DiscussionStaleAfter      time.Duration
+	DiscussionStaleAction     string
+	DiscussionStaleNotify     bool
+	DiscussionStaleCheck      time.Duration
+	ActivityRetention         time.Duration
+	ActivityPruneInterval     time.Duration
+	ContactInboxProvider      string
+	ContactDeliveryProvider   string
+	ContactNotifyTo           []string
+	ContactDeliveryRetries    int
+	ContactDeliveryRetryDelay time.Duration
+	ContactRedeliverAfter     time.Duration
+	ContactRedeliverInterval  time.Duration
+	ContactDedupeTTL          time.Duration
+	ContactRateLimit          int
+	ContactRateWindow         time.Duration
+	WebhookMaxAttempts        int
+	WebhookRetryDelay         time.Duration
+	WebhookTimeout            time.Duration
+	SMTPHost                  string
+	SMTPPort                  int
+	SMTPUsername              string
+	SMTPPassword              string
+	SMTPFrom                  string
+	GalleryCDNBaseURL         string
+	SeedEnabled               bool
+	SeedToken                 string
+	FeatureFlagSecret         string
+	LogContentMaxLength       int
+	LogRedactContent          bool
+}
+
+// AITokenPrice is what an AI model charges in USD per million tokens.
+type AITokenPrice struct {
+	Prompt     float64
+	Completion float64
 }
 
 // HTTPAddress returns the address the HTTP server should listen on.
@@ -53,6 +137,17 @@ func (c Config) HTTPAddress() string {
 	return fmt.Sprintf(":%s", c.AppPort)
 }
 
+// RequestBodyLimit returns the largest request body, in bytes, the HTTP
+// server accepts: the biggest upload or submission limit any caller can
+// face, plus a megabyte for the multipart framing and other form fields.
+func (c Config) RequestBodyLimit() int {
+	largest := max(c.UploadMaxMB, c.SubmissionMaxMB, c.SubmissionAssignmentMaxMB)
+	for _, mb := range c.SubmissionRoleMaxMB {
+		largest = max(largest, mb)
+	}
+	return (largest + 1) * 1024 * 1024
+}
+
 // WSAddress returns the address the WebSocket server should listen on.
 func (c Config) WSAddress() string {
 	if c.WSPort == "" {
@@ -66,7 +161,9 @@ func (c Config) WSAddress() string {
 	return fmt.Sprintf(":%s", c.WSPort)
 }
 
-// Load reads configuration values from environment variables and optional .env file.
+// Load reads configuration values from environment variables and optional .env
+// file. It only fails when a value cannot be parsed; call Validate on the result
+// to check that the configuration as a whole is usable.
 func Load() (Config, error) {
 	_ = godotenv.Load()
 
@@ -76,131 +173,348 @@ func Load() (Config, error) {
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
 	v.SetDefault("app.name", "GEMA API")
+	v.SetDefault("app.version", "dev")
 	v.SetDefault("app.env", "development")
 	v.SetDefault("app.port", "8080")
 	v.SetDefault("cloudinary.folder", "gema/tutorial")
+	v.SetDefault("database.max_open_conns", 25)
+	v.SetDefault("database.max_idle_conns", 10)
+	v.SetDefault("database.conn_max_lifetime", "30m")
+	v.SetDefault("database.conn_max_idle_time", "5m")
+	v.SetDefault("database.migrations", "auto")
 	v.SetDefault("ws.port", "")
 	v.SetDefault("dashboard.cache_ttl", "5m")
 	v.SetDefault("analytics.cache_ttl", "2m")
 	v.SetDefault("announcements.cache_ttl", "5m")
 	v.SetDefault("roadmap.cache_ttl", "2m")
+	v.SetDefault("cache.memory_max_entries", 1024)
+	v.SetDefault("cache.write_retries", 2)
+	v.SetDefault("cache.write_retry_delay_ms", 50)
 	v.SetDefault("sse.client_timeout", "55s")
 	v.SetDefault("execution_timeout_ms", 5000)
+	v.SetDefault("docker_allowed_images", "")
+	v.SetDefault("docker_prewarm_images", false)
 	v.SetDefault("code_run_memory_mb", 256)
 	v.SetDefault("code_run_cpu_shares", 512)
+	v.SetDefault("code_run_cpu_quota", 0)
+	v.SetDefault("code_run_cpu_period", 0)
+	v.SetDefault("code_run_pids_limit", 64)
+	v.SetDefault("code_run_disk_mb", 64)
+	v.SetDefault("code_run_max_concurrent", 8)
+	v.SetDefault("code_run_max_queue", 32)
+	v.SetDefault("code_run_queue_timeout_ms", 10000)
+	v.SetDefault("code_run_inline_output_kb", 64)
+	v.SetDefault("code_run_max_output_kb", 1024)
+	v.SetDefault("code_run_persist_logs", false)
 	v.SetDefault("ai.provider", "openai")
+	v.SetDefault("ai.model", "")
+	v.SetDefault("ai.max_tokens", 0)
+	v.SetDefault("ai.temperature", 0)
+	v.SetDefault("ai.max_retries", 2)
+	v.SetDefault("ai.retry_base_delay_ms", 500)
+	v.SetDefault("ai.evaluation_cache_ttl", "24h")
+	v.SetDefault("ai.token_prices", "")
+	v.SetDefault("jwt.refresh_secret_previous", "")
+	v.SetDefault("jwt.access_ttl", "15m")
+	v.SetDefault("jwt.refresh_ttl", "720h")
+	v.SetDefault("jwt.denylist_fail_open", true)
 	v.SetDefault("redis.pubsub_channel", "gema:events")
+	v.SetDefault("redis.chat_streams", false)
+	v.SetDefault("redis.chat_stream_max_len", 10000)
+	v.SetDefault("redis.chat_stream_max_age", "1h")
 	v.SetDefault("nats.url", "")
+	v.SetDefault("nats.jetstream", false)
+	v.SetDefault("nats.stream", "GEMA_EVENTS")
+	v.SetDefault("nats.stream_max_age", "24h")
+	v.SetDefault("nats.node_name", "")
+	v.SetDefault("otel.exporter_otlp_endpoint", "")
+	v.SetDefault("otel.service_name", "gema-go-api")
+	// The standard OpenTelemetry variables are honoured as well.
+	_ = v.BindEnv("otel.exporter_otlp_endpoint", "GEMA_OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_ENDPOINT")
+	_ = v.BindEnv("otel.service_name", "GEMA_OTEL_SERVICE_NAME", "OTEL_SERVICE_NAME")
 	v.SetDefault("upload.max_mb", 10)
+	v.SetDefault("upload.allowed_mime_types", "")
+	v.SetDefault("upload.direct_max_mb", 100)
+	v.SetDefault("submission.allowed_mime_types", "")
+	v.SetDefault("submission.max_mb", 10)
+	v.SetDefault("submission.role_max_mb", "")
+	v.SetDefault("submission.assignment_max_mb", 100)
+	v.SetDefault("submission.web_archive_max_entries", 500)
+	v.SetDefault("submission.web_archive_max_file_mb", 0)
+	v.SetDefault("discussion.stale_after", "0")
+	v.SetDefault("discussion.stale_action", "lock")
+	v.SetDefault("discussion.stale_notify", false)
+	v.SetDefault("discussion.stale_check_interval", "1h")
+	v.SetDefault("activity.retention", "0")
+	v.SetDefault("activity.prune_interval", "24h")
 	v.SetDefault("contact.inbox_provider", "email")
+	v.SetDefault("contact.delivery_provider", "log")
+	v.SetDefault("contact.notify_to", "")
+	v.SetDefault("contact.delivery_retries", 2)
+	v.SetDefault("contact.delivery_retry_delay_ms", 500)
+	v.SetDefault("contact.redeliver_after", "10m")
+	v.SetDefault("contact.redeliver_interval", "5m")
+	v.SetDefault("contact.dedupe_ttl", "5m")
+	v.SetDefault("contact.rate_limit_per_email", 3)
+	v.SetDefault("contact.rate_limit_window", "1h")
+	v.SetDefault("webhook.max_attempts", 5)
+	v.SetDefault("webhook.retry_delay", "1s")
+	v.SetDefault("webhook.timeout", "10s")
+	v.SetDefault("smtp.host", "")
+	v.SetDefault("smtp.port", 587)
+	v.SetDefault("smtp.username", "")
+	v.SetDefault("smtp.password", "")
+	v.SetDefault("smtp.from", "")
 	v.SetDefault("gallery.cdn_baseurl", "")
 	v.SetDefault("seed.enabled", false)
 	v.SetDefault("seed.token", "")
+	v.SetDefault("feature_flags.secret", "")
+	v.SetDefault("log.content_max_length", 64)
+	v.SetDefault("log.redact_content", true)
 
-	ttlString := v.GetString("dashboard.cache_ttl")
-	if ttlString == "" {
-		ttlString = "5m"
+	// Parse problems are collected so a misconfigured environment reports
+	// every bad value at once instead of the first one only.
+	var problems []error
+	duration := func(key, label string) time.Duration {
+		raw := strings.TrimSpace(v.GetString(key))
+		value, err := time.ParseDuration(raw)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("invalid %s %q: expected a duration such as 30s or 5m", label, raw))
+		}
+		return value
 	}
 
-	ttl, err := time.ParseDuration(ttlString)
+	connMaxLifetime := duration("database.conn_max_lifetime", "database connection lifetime (GEMA_DATABASE_CONN_MAX_LIFETIME)")
+	connMaxIdleTime := duration("database.conn_max_idle_time", "database connection idle time (GEMA_DATABASE_CONN_MAX_IDLE_TIME)")
+	ttl := duration("dashboard.cache_ttl", "dashboard cache ttl (GEMA_DASHBOARD_CACHE_TTL)")
+	analyticsTTL := duration("analytics.cache_ttl", "analytics cache ttl (GEMA_ANALYTICS_CACHE_TTL)")
+	announcementsTTL := duration("announcements.cache_ttl", "announcements cache ttl (GEMA_ANNOUNCEMENTS_CACHE_TTL)")
+	roadmapTTL := duration("roadmap.cache_ttl", "roadmap cache ttl (GEMA_ROADMAP_CACHE_TTL)")
+	evaluationCacheTTL := duration("ai.evaluation_cache_ttl", "ai evaluation cache ttl (GEMA_AI_EVALUATION_CACHE_TTL)")
+	sseTimeout := duration("sse.client_timeout", "sse client timeout (GEMA_SSE_CLIENT_TIMEOUT)")
+	staleAfter := duration("discussion.stale_after", "discussion stale window (GEMA_DISCUSSION_STALE_AFTER)")
+	staleCheck := duration("discussion.stale_check_interval", "discussion stale check interval (GEMA_DISCUSSION_STALE_CHECK_INTERVAL)")
+	activityRetention := duration("activity.retention", "activity retention (GEMA_ACTIVITY_RETENTION)")
+	activityPruneInterval := duration("activity.prune_interval", "activity prune interval (GEMA_ACTIVITY_PRUNE_INTERVAL)")
+	redeliverAfter := duration("contact.redeliver_after", "contact redelivery window (GEMA_CONTACT_REDELIVER_AFTER)")
+	redeliverInterval := duration("contact.redeliver_interval", "contact redelivery interval (GEMA_CONTACT_REDELIVER_INTERVAL)")
+	contactDedupeTTL := duration("contact.dedupe_ttl", "contact dedupe ttl (GEMA_CONTACT_DEDUPE_TTL)")
+	contactRateWindow := duration("contact.rate_limit_window", "contact rate limit window (GEMA_CONTACT_RATE_LIMIT_WINDOW)")
+	chatStreamMaxAge := duration("redis.chat_stream_max_age", "chat stream max age (GEMA_REDIS_CHAT_STREAM_MAX_AGE)")
+	natsStreamMaxAge := duration("nats.stream_max_age", "nats stream max age (GEMA_NATS_STREAM_MAX_AGE)")
+	webhookRetryDelay := duration("webhook.retry_delay", "webhook retry delay (GEMA_WEBHOOK_RETRY_DELAY)")
+	webhookTimeout := duration("webhook.timeout", "webhook timeout (GEMA_WEBHOOK_TIMEOUT)")
+	jwtAccessTTL := duration("jwt.access_ttl", "jwt access ttl (GEMA_JWT_ACCESS_TTL)")
+	jwtRefreshTTL := duration("jwt.refresh_ttl", "jwt refresh ttl (GEMA_JWT_REFRESH_TTL)")
+
+	aiTokenPrices, err := parseTokenPrices(v.GetString("ai.token_prices"))
 	if err != nil {
-		return Config{}, fmt.Errorf("invalid dashboard cache ttl: %w", err)
+		problems = append(problems, err)
 	}
 
-	analyticsTTLString := v.GetString("analytics.cache_ttl")
-	if analyticsTTLString == "" {
-		analyticsTTLString = "2m"
+	if len(problems) > 0 {
+		return Config{}, errors.Join(problems...)
 	}
 
-	analyticsTTL, err := time.ParseDuration(analyticsTTLString)
-	if err != nil {
-		return Config{}, fmt.Errorf("invalid analytics cache ttl: %w", err)
+	timeoutMs := v.GetInt("execution_timeout_ms")
+	if timeoutMs <= 0 {
+		timeoutMs = 5000
 	}
 
-	announcementsTTLString := v.GetString("announcements.cache_ttl")
-	if announcementsTTLString == "" {
-		announcementsTTLString = "5m"
+	cfg := Config{
+		AppName:                   v.GetString("app.name"),
+		AppVersion:                v.GetString("app.version"),
+		AppEnv:                    v.GetString("app.env"),
+		AppPort:                   v.GetString("app.port"),
+		WSPort:                    v.GetString("ws.port"),
+		DatabaseURL:               v.GetString("database.url"),
+		DatabaseMaxOpenConns:      v.GetInt("database.max_open_conns"),
+		DatabaseMaxIdleConns:      v.GetInt("database.max_idle_conns"),
+		DatabaseConnMaxLifetime:   connMaxLifetime,
+		DatabaseConnMaxIdleTime:   connMaxIdleTime,
+		DatabaseMigrations:        strings.ToLower(strings.TrimSpace(v.GetString("database.migrations"))),
+		RedisURL:                  v.GetString("redis.url"),
+		RedisPubSubChannel:        v.GetString("redis.pubsub_channel"),
+		RedisChatStreams:          v.GetBool("redis.chat_streams"),
+		RedisChatStreamMaxLen:     v.GetInt64("redis.chat_stream_max_len"),
+		RedisChatStreamMaxAge:     chatStreamMaxAge,
+		NATSURL:                   v.GetString("nats.url"),
+		NATSJetStream:             v.GetBool("nats.jetstream"),
+		NATSStream:                strings.TrimSpace(v.GetString("nats.stream")),
+		NATSStreamMaxAge:          natsStreamMaxAge,
+		NATSNodeName:              natsNodeName(v.GetString("nats.node_name")),
+		OTelEndpoint:              strings.TrimSpace(v.GetString("otel.exporter_otlp_endpoint")),
+		OTelServiceName:           v.GetString("otel.service_name"),
+		JWTSecret:                 v.GetString("jwt.secret"),
+		JWTRefreshSecret:          v.GetString("jwt.refresh_secret"),
+		JWTRefreshSecretPrevious:  v.GetString("jwt.refresh_secret_previous"),
+		JWTAccessTTL:              jwtAccessTTL,
+		JWTRefreshTTL:             jwtRefreshTTL,
+		JWTDenylistFailOpen:       v.GetBool("jwt.denylist_fail_open"),
+		CloudinaryCloudName:       v.GetString("cloudinary.cloud_name"),
+		CloudinaryAPIKey:          v.GetString("cloudinary.api_key"),
+		CloudinaryAPISecret:       v.GetString("cloudinary.api_secret"),
+		CloudinaryUploadFolder:    v.GetString("cloudinary.folder"),
+		DashboardCacheTTL:         ttl,
+		AnalyticsCacheTTL:         analyticsTTL,
+		AnnouncementsCacheTTL:     announcementsTTL,
+		RoadmapCacheTTL:           roadmapTTL,
+		CacheMemoryMaxEntries:     v.GetInt("cache.memory_max_entries"),
+		CacheWriteRetries:         v.GetInt("cache.write_retries"),
+		CacheWriteRetryDelay:      time.Duration(v.GetInt("cache.write_retry_delay_ms")) * time.Millisecond,
+		SSEClientTimeout:          sseTimeout,
+		DockerHost:                v.GetString("docker_host"),
+		DockerAllowedImages:       splitList(v.GetString("docker_allowed_images")),
+		DockerPrewarmImages:       v.GetBool("docker_prewarm_images"),
+		ExecutionTimeout:          time.Duration(timeoutMs) * time.Millisecond,
+		CodeRunMemoryMB:           v.GetInt("code_run_memory_mb"),
+		CodeRunCPUShares:          v.GetInt("code_run_cpu_shares"),
+		CodeRunCPUQuota:           v.GetInt("code_run_cpu_quota"),
+		CodeRunCPUPeriod:          v.GetInt("code_run_cpu_period"),
+		CodeRunPidsLimit:          v.GetInt("code_run_pids_limit"),
+		CodeRunDiskMB:             v.GetInt("code_run_disk_mb"),
+		CodeRunMaxConcurrent:      v.GetInt("code_run_max_concurrent"),
+		CodeRunMaxQueue:           v.GetInt("code_run_max_queue"),
+		CodeRunQueueTimeout:       time.Duration(v.GetInt("code_run_queue_timeout_ms")) * time.Millisecond,
+		CodeRunInlineOutputKB:     v.GetInt("code_run_inline_output_kb"),
+		CodeRunMaxOutputKB:        v.GetInt("code_run_max_output_kb"),
+		CodeRunPersistLogs:        v.GetBool("code_run_persist_logs"),
+		AIProvider:                strings.ToLower(strings.TrimSpace(v.GetString("ai.provider"))),
+		AIModel:                   v.GetString("ai.model"),
+		AIMaxTokens:               v.GetInt("ai.max_tokens"),
+		AITemperature:             float32(v.GetFloat64("ai.temperature")),
+		AIMaxRetries:              v.GetInt("ai.max_retries"),
+		AIRetryBaseDelay:          time.Duration(v.GetInt("ai.retry_base_delay_ms")) * time.Millisecond,
+		AIEvaluationCacheTTL:      evaluationCacheTTL,
+		AITokenPrices:             aiTokenPrices,
+		OpenAIAPIKey:              v.GetString("openai_api_key"),
+		AnthropicAPIKey:           v.GetString("anthropic_api_key"),
+		UploadMaxMB:               v.GetInt("upload.max_mb"),
+		UploadMimeTypes:           splitList(v.GetString("upload.allowed_mime_types")),
+		UploadDirectMaxMB:         v.GetInt("upload.direct_max_mb"),
+		SubmissionMimeTypes:       splitList(v.GetString("submission.allowed_mime_types")),
+		SubmissionMaxMB:           v.GetInt("submission.max_mb"),
+		SubmissionRoleMaxMB:       parseRoleLimits(v.GetString("submission.role_max_mb")),
+		SubmissionAssignmentMaxMB: v.GetInt("submission.assignment_max_mb"),
+		WebArchiveMaxEntries:      v.GetInt("submission.web_archive_max_entries"),
+		WebArchiveMaxFileMB:       v.GetInt("submission.web_archive_max_file_mb"),
+		DiscussionStaleAfter:      staleAfter,
+		DiscussionStaleAction:     strings.ToLower(strings.TrimSpace(v.GetString("discussion.stale_action"))),
+		DiscussionStaleNotify:     v.GetBool("discussion.stale_notify"),
+		DiscussionStaleCheck:      staleCheck,
+		ActivityRetention:         activityRetention,
+		ActivityPruneInterval:     activityPruneInterval,
+		ContactInboxProvider:      strings.ToLower(v.GetString("contact.inbox_provider")),
+		ContactDeliveryProvider:   strings.ToLower(strings.TrimSpace(v.GetString("contact.delivery_provider"))),
+		ContactNotifyTo:           splitList(v.GetString("contact.notify_to")),
+		ContactDeliveryRetries:    v.GetInt("contact.delivery_retries"),
+		ContactDeliveryRetryDelay: time.Duration(v.GetInt("contact.delivery_retry_delay_ms")) * time.Millisecond,
+		ContactRedeliverAfter:     redeliverAfter,
+		ContactRedeliverInterval:  redeliverInterval,
+		ContactDedupeTTL:          contactDedupeTTL,
+		ContactRateLimit:          v.GetInt("contact.rate_limit_per_email"),
+		ContactRateWindow:         contactRateWindow,
+		WebhookMaxAttempts:        v.GetInt("webhook.max_attempts"),
+		WebhookRetryDelay:         webhookRetryDelay,
+		WebhookTimeout:            webhookTimeout,
+		SMTPHost:                  strings.TrimSpace(v.GetString("smtp.host")),
+		SMTPPort:                  v.GetInt("smtp.port"),
+		SMTPUsername:              v.GetString("smtp.username"),
+		SMTPPassword:              v.GetString("smtp.password"),
+		SMTPFrom:                  strings.TrimSpace(v.GetString("smtp.from")),
+		GalleryCDNBaseURL:         strings.TrimRight(v.GetString("gallery.cdn_baseurl"), "/"),
+		SeedEnabled:               v.GetBool("seed.enabled"),
+		SeedToken:                 v.GetString("seed.token"),
+		FeatureFlagSecret:         v.GetString("feature_flags.secret"),
+		LogContentMaxLength:       v.GetInt("log.content_max_length"),
+		LogRedactContent:          v.GetBool("log.redact_content"),
 	}
 
-	announcementsTTL, err := time.ParseDuration(announcementsTTLString)
-	if err != nil {
-		return Config{}, fmt.Errorf("invalid announcements cache ttl: %w", err)
+	if cfg.ContactDeliveryProvider == "" {
+		cfg.ContactDeliveryProvider = "log"
 	}
 
-	roadmapTTLString := v.GetString("roadmap.cache_ttl")
-	if roadmapTTLString == "" {
-		roadmapTTLString = "2m"
+	if cfg.UploadMaxMB <= 0 {
+		cfg.UploadMaxMB = 10
 	}
 
-	roadmapTTL, err := time.ParseDuration(roadmapTTLString)
-	if err != nil {
-		return Config{}, fmt.Errorf("invalid roadmap cache ttl: %w", err)
+	if cfg.UploadDirectMaxMB <= 0 {
+		cfg.UploadDirectMaxMB = 100
 	}
 
-	sseTimeoutString := v.GetString("sse.client_timeout")
-	if sseTimeoutString == "" {
-		sseTimeoutString = "55s"
+	if cfg.SubmissionMaxMB <= 0 {
+		cfg.SubmissionMaxMB = 10
 	}
 
-	sseTimeout, err := time.ParseDuration(sseTimeoutString)
-	if err != nil {
-		return Config{}, fmt.Errorf("invalid sse client timeout: %w", err)
+	if cfg.SubmissionAssignmentMaxMB <= 0 {
+		cfg.SubmissionAssignmentMaxMB = 100
 	}
 
-	timeoutMs := v.GetInt("execution_timeout_ms")
-	if timeoutMs <= 0 {
-		timeoutMs = 5000
+	return cfg, nil
+}
+
+// splitList parses a comma separated value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, part := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			items = append(items, trimmed)
+		}
 	}
+	return items
+}
 
-	cfg := Config{
-		AppName:                v.GetString("app.name"),
-		AppEnv:                 v.GetString("app.env"),
-		AppPort:                v.GetString("app.port"),
-		WSPort:                 v.GetString("ws.port"),
-		DatabaseURL:            v.GetString("database.url"),
-		RedisURL:               v.GetString("redis.url"),
-		RedisPubSubChannel:     v.GetString("redis.pubsub_channel"),
-		NATSURL:                v.GetString("nats.url"),
-		JWTSecret:              v.GetString("jwt.secret"),
-		JWTRefreshSecret:       v.GetString("jwt.refresh_secret"),
-		CloudinaryCloudName:    v.GetString("cloudinary.cloud_name"),
-		CloudinaryAPIKey:       v.GetString("cloudinary.api_key"),
-		CloudinaryAPISecret:    v.GetString("cloudinary.api_secret"),
-		CloudinaryUploadFolder: v.GetString("cloudinary.folder"),
-		DashboardCacheTTL:      ttl,
-		AnalyticsCacheTTL:      analyticsTTL,
-		AnnouncementsCacheTTL:  announcementsTTL,
-		RoadmapCacheTTL:        roadmapTTL,
-		SSEClientTimeout:       sseTimeout,
-		DockerHost:             v.GetString("docker_host"),
-		ExecutionTimeout:       time.Duration(timeoutMs) * time.Millisecond,
-		CodeRunMemoryMB:        v.GetInt("code_run_memory_mb"),
-		CodeRunCPUShares:       v.GetInt("code_run_cpu_shares"),
-		AIProvider:             strings.ToLower(v.GetString("ai.provider")),
-		OpenAIAPIKey:           v.GetString("openai_api_key"),
-		AnthropicAPIKey:        v.GetString("anthropic_api_key"),
-		UploadMaxMB:            v.GetInt("upload.max_mb"),
-		ContactInboxProvider:   strings.ToLower(v.GetString("contact.inbox_provider")),
-		GalleryCDNBaseURL:      strings.TrimRight(v.GetString("gallery.cdn_baseurl"), "/"),
-		SeedEnabled:            v.GetBool("seed.enabled"),
-		SeedToken:              v.GetString("seed.token"),
-	}
-
-	if cfg.JWTSecret == "" || cfg.JWTRefreshSecret == "" {
-		return Config{}, fmt.Errorf("jwt secrets must be provided")
-	}
-
-	if cfg.CodeRunMemoryMB <= 0 {
-		cfg.CodeRunMemoryMB = 256
-	}
-
-	if cfg.CodeRunCPUShares <= 0 {
-		cfg.CodeRunCPUShares = 512
+// natsNodeName returns the configured node name, falling back to the host
+// name. It names the node's JetStream consumers, so it must stay the same
+// across restarts and differ between replicas.
+func natsNodeName(value string) string {
+	if name := strings.TrimSpace(value); name != "" {
+		return name
 	}
+	host, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return host
+}
 
-	if cfg.UploadMaxMB <= 0 {
-		cfg.UploadMaxMB = 10
+// parseRoleLimits parses "role=mb" pairs such as "teacher=50,admin=100",
+// skipping malformed or non-positive entries.
+func parseRoleLimits(value string) map[string]int {
+	limits := make(map[string]int)
+	for _, item := range splitList(value) {
+		role, mb, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(mb))
+		if err != nil || size <= 0 {
+			continue
+		}
+		limits[strings.ToLower(strings.TrimSpace(role))] = size
 	}
+	return limits
+}
 
-	return cfg, nil
+// parseTokenPrices parses "model=prompt:completion" pairs of USD prices per
+// million tokens, such as "gpt-4o-mini=0.15:0.6".
+func parseTokenPrices(value string) (map[string]AITokenPrice, error) {
+	prices := make(map[string]AITokenPrice)
+	for _, item := range splitList(value) {
+		model, pair, ok := strings.Cut(item, "=")
+		prompt, completion, okPair := strings.Cut(pair, ":")
+		if !ok || !okPair || strings.TrimSpace(model) == "" {
+			return nil, fmt.Errorf("invalid ai token price %q: expected model=prompt:completion", item)
+		}
+		promptPrice, err := strconv.ParseFloat(strings.TrimSpace(prompt), 64)
+		if err != nil || promptPrice < 0 {
+			return nil, fmt.Errorf("invalid ai prompt token price %q", item)
+		}
+		completionPrice, err := strconv.ParseFloat(strings.TrimSpace(completion), 64)
+		if err != nil || completionPrice < 0 {
+			return nil, fmt.Errorf("invalid ai completion token price %q", item)
+		}
+		prices[strings.TrimSpace(model)] = AITokenPrice{Prompt: promptPrice, Completion: completionPrice}
+	}
+	return prices, nil
 }
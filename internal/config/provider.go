@@ -0,0 +1,123 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/joho/godotenv"
+)
+
+// startupEnv records the variables the process was started with, so a reload
+// re-reads .env without overriding anything set in the real environment.
+var startupEnv = func() map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, entry := range os.Environ() {
+		if key, _, ok := strings.Cut(entry, "="); ok {
+			keys[key] = struct{}{}
+		}
+	}
+	return keys
+}()
+
+// reloadableField is a configuration field Reload applies while the process
+// runs. apply copies the field from src into dst and reports whether it
+// changed.
+type reloadableField struct {
+	name  string
+	apply func(dst *Config, src Config) bool
+}
+
+// reloadableFields lists every hot-reloadable field. All other fields are read
+// once at startup and need a restart to change.
+var reloadableFields = []reloadableField{
+	{"GEMA_DASHBOARD_CACHE_TTL", func(dst *Config, src Config) bool { return swap(&dst.DashboardCacheTTL, src.DashboardCacheTTL) }},
+	{"GEMA_ANALYTICS_CACHE_TTL", func(dst *Config, src Config) bool { return swap(&dst.AnalyticsCacheTTL, src.AnalyticsCacheTTL) }},
+	{"GEMA_ANNOUNCEMENTS_CACHE_TTL", func(dst *Config, src Config) bool {
+		return swap(&dst.AnnouncementsCacheTTL, src.AnnouncementsCacheTTL)
+	}},
+	{"GEMA_AI_MODEL", func(dst *Config, src Config) bool { return swap(&dst.AIModel, src.AIModel) }},
+	{"GEMA_AI_TEMPERATURE", func(dst *Config, src Config) bool { return swap(&dst.AITemperature, src.AITemperature) }},
+}
+
+func swap[T comparable](dst *T, src T) bool {
+	if *dst == src {
+		return false
+	}
+	*dst = src
+	return true
+}
+
+// ReloadableFields returns the environment variables Reload picks up.
+func ReloadableFields() []string {
+	names := make([]string, len(reloadableFields))
+	for i, field := range reloadableFields {
+		names[i] = field.name
+	}
+	return names
+}
+
+// Provider serves the live configuration. Services that honour hot reloads
+// read their values through Current on every use instead of copying them at
+// startup.
+type Provider struct {
+	current atomic.Pointer[Config]
+	// mu serialises reloads; readers never block.
+	mu   sync.Mutex
+	load func() (Config, error)
+}
+
+// NewProvider returns a provider serving cfg until the first reload.
+func NewProvider(cfg Config) *Provider {
+	provider := &Provider{load: reloadFromEnvironment}
+	provider.current.Store(&cfg)
+	return provider
+}
+
+// Current returns the configuration in effect.
+func (p *Provider) Current() Config {
+	return *p.current.Load()
+}
+
+// Reload loads and validates the configuration again and applies the
+// hot-reloadable fields, returning the names of those that changed. On error
+// the running configuration is left untouched.
+func (p *Provider) Reload() ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	next, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+
+	updated := p.Current()
+	changed := make([]string, 0)
+	for _, field := range reloadableFields {
+		if field.apply(&updated, next) {
+			changed = append(changed, field.name)
+		}
+	}
+	if len(changed) > 0 {
+		p.current.Store(&updated)
+	}
+	return changed, nil
+}
+
+// reloadFromEnvironment re-reads .env, letting its values replace ones an
+// earlier read set, and loads the configuration.
+func reloadFromEnvironment() (Config, error) {
+	values, err := godotenv.Read()
+	if err == nil {
+		for key, value := range values {
+			if _, fromProcess := startupEnv[key]; !fromProcess {
+				_ = os.Setenv(key, value)
+			}
+		}
+	}
+	return Load()
+}
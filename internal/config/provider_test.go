@@ -0,0 +1,84 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/noah-isme/gema-go-api/pkg/ai"
+)
+
+func TestProviderReloadAppliesOnlyReloadableFields(t *testing.T) {
+	provider := NewProvider(validConfig())
+	observedTTL := func() time.Duration { return provider.Current().DashboardCacheTTL }
+	require.Zero(t, observedTTL())
+
+	// The evaluator is wired to the provider the way main wires it; the
+	// reloaded model must reach the requests it sends.
+	var requestedModel string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model string `json:"model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requestedModel = body.Model
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": `{"score": 1, "verdict": "pass", "feedback": "ok"}`}},
+		})
+	}))
+	defer server.Close()
+	startup := provider.Current()
+	evaluator, err := ai.NewAnthropicEvaluator(ai.AnthropicConfig{
+		APIKey:      "test-key",
+		BaseURL:     server.URL,
+		Model:       startup.AIModel,
+		Temperature: startup.AITemperature,
+		Settings: func() ai.ModelSettings {
+			current := provider.Current()
+			return ai.ModelSettings{Model: current.AIModel, Temperature: current.AITemperature}
+		},
+	})
+	require.NoError(t, err)
+
+	next := validConfig()
+	next.DashboardCacheTTL = 90 * time.Second
+	next.AIModel = "gpt-4o"
+	next.JWTAccessTTL = time.Hour
+	next.JWTRefreshTTL = 2 * time.Hour
+	provider.load = func() (Config, error) { return next, nil }
+
+	changed, err := provider.Reload()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"GEMA_DASHBOARD_CACHE_TTL", "GEMA_AI_MODEL"}, changed)
+	require.Equal(t, 90*time.Second, observedTTL())
+	require.Equal(t, "gpt-4o", provider.Current().AIModel)
+	_, err = evaluator.Evaluate(context.Background(), ai.EvaluationInput{})
+	require.NoError(t, err)
+	require.Equal(t, "gpt-4o", requestedModel)
+	require.Equal(t, 15*time.Minute, provider.Current().JWTAccessTTL, "restart-only fields keep their startup value")
+
+	changed, err = provider.Reload()
+	require.NoError(t, err)
+	require.Empty(t, changed)
+}
+
+func TestProviderReloadKeepsValuesWhenInvalid(t *testing.T) {
+	provider := NewProvider(validConfig())
+
+	invalid := validConfig()
+	invalid.DashboardCacheTTL = -time.Minute
+	provider.load = func() (Config, error) { return invalid, nil }
+	_, err := provider.Reload()
+	require.ErrorContains(t, err, "GEMA_DASHBOARD_CACHE_TTL")
+
+	provider.load = func() (Config, error) { return Config{}, errors.New("bad duration") }
+	_, err = provider.Reload()
+	require.Error(t, err)
+	require.Zero(t, provider.Current().DashboardCacheTTL)
+}
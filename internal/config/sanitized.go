@@ -0,0 +1,313 @@
+package config
+
+import (
+	"github.com/noah-isme/gema-go-api/internal/featureflags"
+)
+
+// SanitizedConfig is the operator-facing view of the running configuration.
+// It is built from an explicit allowlist: secrets (JWT secrets, API keys,
+// tokens) and connection strings that may embed credentials (database,
+// Redis, NATS, Docker host) never appear, only whether they are configured.
+type SanitizedConfig struct {
+	App          SanitizedApp          `json:"app"`
+	Auth         SanitizedAuth         `json:"auth"`
+	Database     SanitizedDatabase     `json:"database"`
+	Cache        SanitizedCache        `json:"cache"`
+	Execution    SanitizedExecution    `json:"execution"`
+	Uploads      SanitizedUploads      `json:"uploads"`
+	AI           SanitizedAI           `json:"ai"`
+	Discussions  SanitizedDiscussions  `json:"discussions"`
+	Activity     SanitizedActivity     `json:"activity"`
+	Contact      SanitizedContact      `json:"contact"`
+	Webhooks     SanitizedWebhooks     `json:"webhooks"`
+	FeatureFlags SanitizedFeatureFlags `json:"feature_flags"`
+	Logging      SanitizedLogging      `json:"logging"`
+	Integrations SanitizedIntegrations `json:"integrations"`
+}
+
+// SanitizedApp describes the service identity and listeners.
+type SanitizedApp struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Env     string `json:"env"`
+	Port    string `json:"port"`
+	WSPort  string `json:"ws_port"`
+}
+
+// SanitizedAuth lists token lifetimes and revocation behaviour.
+type SanitizedAuth struct {
+	AccessTokenTTL   string `json:"access_token_ttl"`
+	RefreshTokenTTL  string `json:"refresh_token_ttl"`
+	DenylistFailOpen bool   `json:"denylist_fail_open"`
+}
+
+// SanitizedDatabase lists the connection pool limits. The database URL is
+// never included.
+type SanitizedDatabase struct {
+	MaxOpenConns    int    `json:"max_open_conns"`
+	MaxIdleConns    int    `json:"max_idle_conns"`
+	ConnMaxLifetime string `json:"conn_max_lifetime"`
+	ConnMaxIdleTime string `json:"conn_max_idle_time"`
+	Migrations      string `json:"migrations"`
+}
+
+// SanitizedCache lists cache TTLs and sizes.
+type SanitizedCache struct {
+	DashboardTTL     string `json:"dashboard_ttl"`
+	AnalyticsTTL     string `json:"analytics_ttl"`
+	AnnouncementsTTL string `json:"announcements_ttl"`
+	RoadmapTTL       string `json:"roadmap_ttl"`
+	MemoryMaxEntries int    `json:"memory_max_entries"`
+	WriteRetries     int    `json:"write_retries"`
+	WriteRetryDelay  string `json:"write_retry_delay"`
+	SSEClientTimeout string `json:"sse_client_timeout"`
+}
+
+// SanitizedExecution lists the coding sandbox limits.
+type SanitizedExecution struct {
+	Timeout       string   `json:"timeout"`
+	MemoryMB      int      `json:"memory_mb"`
+	CPUShares     int      `json:"cpu_shares"`
+	CPUQuota      int      `json:"cpu_quota"`
+	CPUPeriod     int      `json:"cpu_period"`
+	PidsLimit     int      `json:"pids_limit"`
+	DiskMB        int      `json:"disk_mb"`
+	MaxConcurrent int      `json:"max_concurrent"`
+	MaxQueue      int      `json:"max_queue"`
+	QueueTimeout  string   `json:"queue_timeout"`
+	AllowedImages []string `json:"allowed_images"`
+	PrewarmImages bool     `json:"prewarm_images"`
+}
+
+// SanitizedUploads lists upload and submission limits.
+type SanitizedUploads struct {
+	MaxMB                     int            `json:"max_mb"`
+	MimeTypes                 []string       `json:"mime_types"`
+	DirectMaxMB               int            `json:"direct_max_mb"`
+	SubmissionMimeTypes       []string       `json:"submission_mime_types"`
+	SubmissionMaxMB           int            `json:"submission_max_mb"`
+	SubmissionRoleMaxMB       map[string]int `json:"submission_role_max_mb"`
+	SubmissionAssignmentMaxMB int            `json:"submission_assignment_max_mb"`
+	WebArchiveMaxEntries      int            `json:"web_archive_max_entries"`
+	WebArchiveMaxFileMB       int            `json:"web_archive_max_file_mb"`
+	CloudinaryFolder          string         `json:"cloudinary_folder"`
+}
+
+// SanitizedAI names the evaluation provider without its key.
+type SanitizedAI struct {
+	Provider       string  `json:"provider"`
+	Model          string  `json:"model"`
+	MaxTokens      int     `json:"max_tokens"`
+	Temperature    float32 `json:"temperature"`
+	MaxRetries     int     `json:"max_retries"`
+	RetryBaseDelay string  `json:"retry_base_delay"`
+	CacheTTL       string  `json:"evaluation_cache_ttl"`
+	KeyConfigured  bool    `json:"key_configured"`
+}
+
+// SanitizedDiscussions describes the stale thread auto-close job.
+type SanitizedDiscussions struct {
+	StaleAfter    string `json:"stale_after"`
+	StaleAction   string `json:"stale_action"`
+	StaleNotify   bool   `json:"stale_notify"`
+	CheckInterval string `json:"check_interval"`
+}
+
+// SanitizedActivity describes the activity log retention job.
+type SanitizedActivity struct {
+	Retention     string `json:"retention"`
+	PruneInterval string `json:"prune_interval"`
+}
+
+// SanitizedContact describes contact form delivery without SMTP credentials.
+type SanitizedContact struct {
+	DeliveryProvider   string `json:"delivery_provider"`
+	SMTPHost           string `json:"smtp_host"`
+	SMTPPort           int    `json:"smtp_port"`
+	SMTPAuthConfigured bool   `json:"smtp_auth_configured"`
+	SMTPFrom           string `json:"smtp_from"`
+	NotifyRecipients   int    `json:"notify_recipients"`
+	DeliveryRetries    int    `json:"delivery_retries"`
+	RetryDelay         string `json:"delivery_retry_delay"`
+	RedeliverAfter     string `json:"redeliver_after"`
+	RedeliverInterval  string `json:"redeliver_interval"`
+	DedupeTTL          string `json:"dedupe_ttl"`
+	RateLimitPerEmail  int    `json:"rate_limit_per_email"`
+	RateLimitWindow    string `json:"rate_limit_window"`
+}
+
+// SanitizedWebhooks describes webhook delivery.
+type SanitizedWebhooks struct {
+	MaxAttempts int    `json:"max_attempts"`
+	RetryDelay  string `json:"retry_delay"`
+	Timeout     string `json:"timeout"`
+}
+
+// SanitizedFeatureFlags lists the flags requests may toggle.
+type SanitizedFeatureFlags struct {
+	Known         []featureflags.Flag `json:"known"`
+	TokensEnabled bool                `json:"tokens_enabled"`
+}
+
+// SanitizedLogging describes how user content is written to logs.
+type SanitizedLogging struct {
+	ContentMaxLength int  `json:"content_max_length"`
+	RedactContent    bool `json:"redact_content"`
+}
+
+// SanitizedIntegrations reports which backing services are configured.
+type SanitizedIntegrations struct {
+	DatabaseConfigured    bool   `json:"database_configured"`
+	RedisConfigured       bool   `json:"redis_configured"`
+	RedisPubSubChannel    string `json:"redis_pubsub_channel"`
+	RedisChatStreams      bool   `json:"redis_chat_streams"`
+	RedisChatStreamMaxLen int64  `json:"redis_chat_stream_max_len"`
+	RedisChatStreamMaxAge string `json:"redis_chat_stream_max_age"`
+	NATSConfigured        bool   `json:"nats_configured"`
+	NATSJetStream         bool   `json:"nats_jetstream"`
+	NATSStream            string `json:"nats_stream"`
+	NATSNodeName          string `json:"nats_node_name"`
+	TracingConfigured     bool   `json:"tracing_configured"`
+	CloudinaryConfigured  bool   `json:"cloudinary_configured"`
+	ContactInboxProvider  string `json:"contact_inbox_provider"`
+	GalleryCDNBaseURL     string `json:"gallery_cdn_base_url"`
+	SeedEnabled           bool   `json:"seed_enabled"`
+}
+
+// Sanitized returns the configuration with every secret redacted. New
+// configuration fields stay hidden until they are added here explicitly.
+func (c Config) Sanitized() SanitizedConfig {
+	return SanitizedConfig{
+		App: SanitizedApp{
+			Name:    c.AppName,
+			Version: c.AppVersion,
+			Env:     c.AppEnv,
+			Port:    c.AppPort,
+			WSPort:  c.WSPort,
+		},
+		Auth: SanitizedAuth{
+			AccessTokenTTL:   c.JWTAccessTTL.String(),
+			RefreshTokenTTL:  c.JWTRefreshTTL.String(),
+			DenylistFailOpen: c.JWTDenylistFailOpen,
+		},
+		Database: SanitizedDatabase{
+			MaxOpenConns:    c.DatabaseMaxOpenConns,
+			MaxIdleConns:    c.DatabaseMaxIdleConns,
+			ConnMaxLifetime: c.DatabaseConnMaxLifetime.String(),
+			ConnMaxIdleTime: c.DatabaseConnMaxIdleTime.String(),
+			Migrations:      c.DatabaseMigrations,
+		},
+		Cache: SanitizedCache{
+			DashboardTTL:     c.DashboardCacheTTL.String(),
+			AnalyticsTTL:     c.AnalyticsCacheTTL.String(),
+			AnnouncementsTTL: c.AnnouncementsCacheTTL.String(),
+			RoadmapTTL:       c.RoadmapCacheTTL.String(),
+			MemoryMaxEntries: c.CacheMemoryMaxEntries,
+			WriteRetries:     c.CacheWriteRetries,
+			WriteRetryDelay:  c.CacheWriteRetryDelay.String(),
+			SSEClientTimeout: c.SSEClientTimeout.String(),
+		},
+		Execution: SanitizedExecution{
+			Timeout:       c.ExecutionTimeout.String(),
+			MemoryMB:      c.CodeRunMemoryMB,
+			CPUShares:     c.CodeRunCPUShares,
+			CPUQuota:      c.CodeRunCPUQuota,
+			CPUPeriod:     c.CodeRunCPUPeriod,
+			PidsLimit:     c.CodeRunPidsLimit,
+			DiskMB:        c.CodeRunDiskMB,
+			MaxConcurrent: c.CodeRunMaxConcurrent,
+			MaxQueue:      c.CodeRunMaxQueue,
+			QueueTimeout:  c.CodeRunQueueTimeout.String(),
+			AllowedImages: c.DockerAllowedImages,
+			PrewarmImages: c.DockerPrewarmImages,
+		},
+		Uploads: SanitizedUploads{
+			MaxMB:                     c.UploadMaxMB,
+			MimeTypes:                 c.UploadMimeTypes,
+			DirectMaxMB:               c.UploadDirectMaxMB,
+			SubmissionMimeTypes:       c.SubmissionMimeTypes,
+			SubmissionMaxMB:           c.SubmissionMaxMB,
+			SubmissionRoleMaxMB:       c.SubmissionRoleMaxMB,
+			SubmissionAssignmentMaxMB: c.SubmissionAssignmentMaxMB,
+			WebArchiveMaxEntries:      c.WebArchiveMaxEntries,
+			WebArchiveMaxFileMB:       c.WebArchiveMaxFileMB,
+			CloudinaryFolder:          c.CloudinaryUploadFolder,
+		},
+		AI: SanitizedAI{
+			Provider:       c.AIProvider,
+			Model:          c.AIModel,
+			MaxTokens:      c.AIMaxTokens,
+			Temperature:    c.AITemperature,
+			MaxRetries:     c.AIMaxRetries,
+			RetryBaseDelay: c.AIRetryBaseDelay.String(),
+			CacheTTL:       c.AIEvaluationCacheTTL.String(),
+			KeyConfigured:  c.aiKeyConfigured(),
+		},
+		Discussions: SanitizedDiscussions{
+			StaleAfter:    c.DiscussionStaleAfter.String(),
+			StaleAction:   c.DiscussionStaleAction,
+			StaleNotify:   c.DiscussionStaleNotify,
+			CheckInterval: c.DiscussionStaleCheck.String(),
+		},
+		Activity: SanitizedActivity{
+			Retention:     c.ActivityRetention.String(),
+			PruneInterval: c.ActivityPruneInterval.String(),
+		},
+		Contact: SanitizedContact{
+			DeliveryProvider:   c.ContactDeliveryProvider,
+			SMTPHost:           c.SMTPHost,
+			SMTPPort:           c.SMTPPort,
+			SMTPAuthConfigured: c.SMTPUsername != "" && c.SMTPPassword != "",
+			SMTPFrom:           c.SMTPFrom,
+			NotifyRecipients:   len(c.ContactNotifyTo),
+			DeliveryRetries:    c.ContactDeliveryRetries,
+			RetryDelay:         c.ContactDeliveryRetryDelay.String(),
+			RedeliverAfter:     c.ContactRedeliverAfter.String(),
+			RedeliverInterval:  c.ContactRedeliverInterval.String(),
+			DedupeTTL:          c.ContactDedupeTTL.String(),
+			RateLimitPerEmail:  c.ContactRateLimit,
+			RateLimitWindow:    c.ContactRateWindow.String(),
+		},
+		Webhooks: SanitizedWebhooks{
+			MaxAttempts: c.WebhookMaxAttempts,
+			RetryDelay:  c.WebhookRetryDelay.String(),
+			Timeout:     c.WebhookTimeout.String(),
+		},
+		FeatureFlags: SanitizedFeatureFlags{
+			Known:         featureflags.Known(),
+			TokensEnabled: c.FeatureFlagSecret != "",
+		},
+		Logging: SanitizedLogging{
+			ContentMaxLength: c.LogContentMaxLength,
+			RedactContent:    c.LogRedactContent,
+		},
+		Integrations: SanitizedIntegrations{
+			DatabaseConfigured:    c.DatabaseURL != "",
+			RedisConfigured:       c.RedisURL != "",
+			RedisPubSubChannel:    c.RedisPubSubChannel,
+			RedisChatStreams:      c.RedisChatStreams,
+			RedisChatStreamMaxLen: c.RedisChatStreamMaxLen,
+			RedisChatStreamMaxAge: c.RedisChatStreamMaxAge.String(),
+			NATSConfigured:        c.NATSURL != "",
+			NATSJetStream:         c.NATSJetStream,
+			NATSStream:            c.NATSStream,
+			NATSNodeName:          c.NATSNodeName,
+			TracingConfigured:     c.OTelEndpoint != "",
+			CloudinaryConfigured:  c.CloudinaryCloudName != "" && c.CloudinaryAPIKey != "" && c.CloudinaryAPISecret != "",
+			ContactInboxProvider:  c.ContactInboxProvider,
+			GalleryCDNBaseURL:     c.GalleryCDNBaseURL,
+			SeedEnabled:           c.SeedEnabled,
+		},
+	}
+}
+
+func (c Config) aiKeyConfigured() bool {
+	switch c.AIProvider {
+	case "openai":
+		return c.OpenAIAPIKey != ""
+	case "anthropic":
+		return c.AnthropicAPIKey != ""
+	default:
+		return false
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Minimum strength of a configured secret. The entropy figure is a Shannon
+// estimate over the secret's own characters, so long but repetitive values
+// such as "passwordpassword..." fall short while `openssl rand -hex 16` or
+// longer passes.
+const (
+	minSecretLength      = 32
+	minSecretEntropyBits = 96
+)
+
+// ErrWeakSecret indicates a configured secret is too short or too predictable.
+var ErrWeakSecret = errors.New("secret is too weak")
+
+// IsProduction reports whether the service runs with APP_ENV production.
+func (c Config) IsProduction() bool {
+	env := strings.ToLower(strings.TrimSpace(c.AppEnv))
+	return env == "production" || env == "prod"
+}
+
+// WeakSecrets checks every configured secret that guards an endpoint: the JWT
+// signing secrets (including a previous refresh secret kept during rotation),
+// the seed token when seeding is enabled, and the feature flag token secret
+// when set. Validate rejects weak secrets in production; other environments
+// only log them.
+func (c Config) WeakSecrets() []error {
+	secrets := []struct {
+		name  string
+		value string
+	}{
+		{"GEMA_JWT_SECRET", c.JWTSecret},
+		{"GEMA_JWT_REFRESH_SECRET", c.JWTRefreshSecret},
+	}
+	if c.JWTRefreshSecretPrevious != "" {
+		secrets = append(secrets, struct {
+			name  string
+			value string
+		}{"GEMA_JWT_REFRESH_SECRET_PREVIOUS", c.JWTRefreshSecretPrevious})
+	}
+	if c.SeedEnabled {
+		secrets = append(secrets, struct {
+			name  string
+			value string
+		}{"GEMA_SEED_TOKEN", c.SeedToken})
+	}
+	if c.FeatureFlagSecret != "" {
+		secrets = append(secrets, struct {
+			name  string
+			value string
+		}{"GEMA_FEATURE_FLAGS_SECRET", c.FeatureFlagSecret})
+	}
+
+	var weak []error
+	for _, secret := range secrets {
+		if err := CheckSecretStrength(secret.name, secret.value); err != nil {
+			weak = append(weak, err)
+		}
+	}
+	return weak
+}
+
+// CheckSecretStrength returns an ErrWeakSecret describing why value is not
+// strong enough to guard an endpoint, or nil when it is.
+func CheckSecretStrength(name, value string) error {
+	length := len([]rune(value))
+	if length < minSecretLength {
+		return fmt.Errorf("%w: %s must be at least %d characters, got %d", ErrWeakSecret, name, minSecretLength, length)
+	}
+	if bits := secretEntropyBits(value); bits < minSecretEntropyBits {
+		return fmt.Errorf("%w: %s is too predictable (about %.0f bits of entropy, need %d)", ErrWeakSecret, name, bits, minSecretEntropyBits)
+	}
+	return nil
+}
+
+// secretEntropyBits estimates the entropy of value from the frequency of its
+// characters.
+func secretEntropyBits(value string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+	if total == 0 {
+		return 0
+	}
+
+	perChar := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		perChar -= p * math.Log2(p)
+	}
+	return perChar * float64(total)
+}
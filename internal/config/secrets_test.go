@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const strongSecret = "9f3c1a7e5b2d8046c4e1f0a9b7d3e2c5"
+
+func setSecretEnv(t *testing.T, env, seedToken string) {
+	t.Helper()
+	t.Setenv("GEMA_APP_ENV", env)
+	t.Setenv("GEMA_JWT_SECRET", strongSecret)
+	t.Setenv("GEMA_JWT_REFRESH_SECRET", "e4b8a2f6c0d9371b5a8e6f2c4d0b9a73")
+	t.Setenv("GEMA_FEATURE_FLAGS_SECRET", "")
+	t.Setenv("GEMA_SEED_ENABLED", "true")
+	t.Setenv("GEMA_SEED_TOKEN", seedToken)
+}
+
+func TestLoadRejectsWeakSeedTokenInProduction(t *testing.T) {
+	setSecretEnv(t, "production", "seed-token")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	err = cfg.Validate()
+	require.ErrorIs(t, err, ErrWeakSecret)
+	require.ErrorContains(t, err, "GEMA_SEED_TOKEN")
+}
+
+func TestLoadAcceptsWeakSeedTokenInDevelopment(t *testing.T) {
+	setSecretEnv(t, "development", "seed-token")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+	require.Len(t, cfg.WeakSecrets(), 1)
+}
+
+func TestLoadAcceptsStrongSecretsInProduction(t *testing.T) {
+	setSecretEnv(t, "production", "7d1e9b3f5a0c8e2d4b6f1a3c5e7d9b0f")
+	t.Setenv("GEMA_OPENAI_API_KEY", "sk-test")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+	require.Empty(t, cfg.WeakSecrets())
+}
+
+func TestCheckSecretStrength(t *testing.T) {
+	require.NoError(t, CheckSecretStrength("secret", strongSecret))
+	require.ErrorIs(t, CheckSecretStrength("secret", "short"), ErrWeakSecret)
+	require.ErrorIs(t, CheckSecretStrength("secret", "abababababababababababababababab"), ErrWeakSecret)
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Validate checks that the loaded configuration is usable and returns every
+// problem found, joined into one error, or nil. Weak secrets are only an error
+// in production, as is an AI provider selected without its API key; other
+// environments log those at startup instead.
+func (c Config) Validate() error {
+	var problems []error
+	addf := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if c.JWTSecret == "" {
+		addf("GEMA_JWT_SECRET is required")
+	}
+	if c.JWTRefreshSecret == "" {
+		addf("GEMA_JWT_REFRESH_SECRET is required")
+	} else if c.JWTRefreshSecret == c.JWTSecret {
+		addf("GEMA_JWT_REFRESH_SECRET must differ from GEMA_JWT_SECRET")
+	}
+	if c.IsProduction() {
+		problems = append(problems, c.WeakSecrets()...)
+	}
+	if c.JWTAccessTTL <= 0 {
+		addf("GEMA_JWT_ACCESS_TTL must be positive, got %s", c.JWTAccessTTL)
+	} else if c.JWTRefreshTTL <= c.JWTAccessTTL {
+		addf("GEMA_JWT_REFRESH_TTL (%s) must exceed GEMA_JWT_ACCESS_TTL (%s)", c.JWTRefreshTTL, c.JWTAccessTTL)
+	}
+
+	if c.DatabaseMaxOpenConns < 0 || c.DatabaseMaxIdleConns < 0 {
+		addf("GEMA_DATABASE_MAX_OPEN_CONNS and GEMA_DATABASE_MAX_IDLE_CONNS must not be negative")
+	} else if c.DatabaseMaxOpenConns > 0 && c.DatabaseMaxIdleConns > c.DatabaseMaxOpenConns {
+		addf("GEMA_DATABASE_MAX_IDLE_CONNS (%d) must not exceed GEMA_DATABASE_MAX_OPEN_CONNS (%d)", c.DatabaseMaxIdleConns, c.DatabaseMaxOpenConns)
+	}
+
+	switch c.DatabaseMigrations {
+	case "auto", "verify", "skip":
+	default:
+		addf("invalid GEMA_DATABASE_MIGRATIONS %q: expected auto, verify or skip", c.DatabaseMigrations)
+	}
+
+	positive := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"GEMA_SSE_CLIENT_TIMEOUT", c.SSEClientTimeout},
+		{"GEMA_EXECUTION_TIMEOUT_MS", c.ExecutionTimeout},
+	}
+	for _, d := range positive {
+		if d.value <= 0 {
+			addf("%s must be positive, got %s", d.name, d.value)
+		}
+	}
+
+	nonNegative := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"GEMA_DATABASE_CONN_MAX_LIFETIME", c.DatabaseConnMaxLifetime},
+		{"GEMA_DATABASE_CONN_MAX_IDLE_TIME", c.DatabaseConnMaxIdleTime},
+		{"GEMA_DASHBOARD_CACHE_TTL", c.DashboardCacheTTL},
+		{"GEMA_ANALYTICS_CACHE_TTL", c.AnalyticsCacheTTL},
+		{"GEMA_ANNOUNCEMENTS_CACHE_TTL", c.AnnouncementsCacheTTL},
+		{"GEMA_ROADMAP_CACHE_TTL", c.RoadmapCacheTTL},
+		{"GEMA_AI_EVALUATION_CACHE_TTL", c.AIEvaluationCacheTTL},
+		{"GEMA_CODE_RUN_QUEUE_TIMEOUT_MS", c.CodeRunQueueTimeout},
+		{"GEMA_DISCUSSION_STALE_AFTER", c.DiscussionStaleAfter},
+		{"GEMA_DISCUSSION_STALE_CHECK_INTERVAL", c.DiscussionStaleCheck},
+		{"GEMA_ACTIVITY_RETENTION", c.ActivityRetention},
+		{"GEMA_ACTIVITY_PRUNE_INTERVAL", c.ActivityPruneInterval},
+		{"GEMA_CONTACT_REDELIVER_AFTER", c.ContactRedeliverAfter},
+		{"GEMA_CONTACT_REDELIVER_INTERVAL", c.ContactRedeliverInterval},
+		{"GEMA_CONTACT_DEDUPE_TTL", c.ContactDedupeTTL},
+		{"GEMA_CONTACT_RATE_LIMIT_WINDOW", c.ContactRateWindow},
+		{"GEMA_WEBHOOK_RETRY_DELAY", c.WebhookRetryDelay},
+		{"GEMA_WEBHOOK_TIMEOUT", c.WebhookTimeout},
+		{"GEMA_NATS_STREAM_MAX_AGE", c.NATSStreamMaxAge},
+		{"GEMA_REDIS_CHAT_STREAM_MAX_AGE", c.RedisChatStreamMaxAge},
+	}
+	for _, d := range nonNegative {
+		if d.value < 0 {
+			addf("%s must not be negative, got %s", d.name, d.value)
+		}
+	}
+
+	if c.DockerHost != "" {
+		if err := validateDockerHost(c.DockerHost); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	if c.CodeRunMemoryMB <= 0 {
+		addf("GEMA_CODE_RUN_MEMORY_MB must be positive, got %d", c.CodeRunMemoryMB)
+	}
+	if c.CodeRunCPUShares <= 0 {
+		addf("GEMA_CODE_RUN_CPU_SHARES must be positive, got %d", c.CodeRunCPUShares)
+	}
+	if c.CodeRunCPUQuota < 0 || c.CodeRunCPUPeriod < 0 {
+		addf("GEMA_CODE_RUN_CPU_QUOTA and GEMA_CODE_RUN_CPU_PERIOD must not be negative")
+	}
+	if c.CodeRunPidsLimit < 0 || c.CodeRunDiskMB < 0 {
+		addf("GEMA_CODE_RUN_PIDS_LIMIT and GEMA_CODE_RUN_DISK_MB must not be negative")
+	}
+	if c.CodeRunInlineOutputKB < 0 || c.CodeRunMaxOutputKB < 0 {
+		addf("GEMA_CODE_RUN_INLINE_OUTPUT_KB and GEMA_CODE_RUN_MAX_OUTPUT_KB must not be negative")
+	}
+
+	switch c.AIProvider {
+	case "", "rulebased":
+	case "openai", "anthropic":
+		if !c.aiKeyConfigured() && c.IsProduction() {
+			addf("GEMA_AI_PROVIDER is %s but %s is empty", c.AIProvider, c.aiKeyName())
+		}
+	default:
+		addf("invalid GEMA_AI_PROVIDER %q: expected openai, anthropic or rulebased", c.AIProvider)
+	}
+	if c.AIMaxTokens < 0 || c.AIMaxRetries < 0 {
+		addf("GEMA_AI_MAX_TOKENS and GEMA_AI_MAX_RETRIES must not be negative")
+	}
+
+	if c.RedisChatStreamMaxLen < 0 {
+		addf("GEMA_REDIS_CHAT_STREAM_MAX_LEN must not be negative, got %d", c.RedisChatStreamMaxLen)
+	}
+	if c.NATSJetStream && c.NATSStream == "" {
+		addf("GEMA_NATS_JETSTREAM is enabled but GEMA_NATS_STREAM is empty")
+	}
+
+	if c.WebhookMaxAttempts < 0 {
+		addf("GEMA_WEBHOOK_MAX_ATTEMPTS must not be negative, got %d", c.WebhookMaxAttempts)
+	}
+
+	if c.DiscussionStaleAction != "lock" && c.DiscussionStaleAction != "archive" {
+		addf("invalid GEMA_DISCUSSION_STALE_ACTION %q: expected lock or archive", c.DiscussionStaleAction)
+	}
+
+	switch c.ContactDeliveryProvider {
+	case "log":
+	case "smtp":
+		if c.SMTPHost == "" || c.SMTPFrom == "" || len(c.ContactNotifyTo) == 0 {
+			addf("smtp contact delivery requires GEMA_SMTP_HOST, GEMA_SMTP_FROM and GEMA_CONTACT_NOTIFY_TO")
+		}
+		if c.SMTPPort <= 0 || c.SMTPPort > 65535 {
+			addf("invalid GEMA_SMTP_PORT %d", c.SMTPPort)
+		}
+	default:
+		addf("invalid GEMA_CONTACT_DELIVERY_PROVIDER %q: expected log or smtp", c.ContactDeliveryProvider)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n%w", errors.Join(problems...))
+}
+
+func (c Config) aiKeyName() string {
+	if c.AIProvider == "anthropic" {
+		return "GEMA_ANTHROPIC_API_KEY"
+	}
+	return "GEMA_OPENAI_API_KEY"
+}
+
+// validateDockerHost accepts the daemon addresses the Docker client does, such
+// as unix:///var/run/docker.sock or tcp://docker:2376.
+func validateDockerHost(host string) error {
+	parsed, err := url.Parse(host)
+	if err != nil {
+		return fmt.Errorf("invalid GEMA_DOCKER_HOST %q: %v", host, err)
+	}
+	switch parsed.Scheme {
+	case "unix", "npipe":
+		if parsed.Path == "" {
+			return fmt.Errorf("invalid GEMA_DOCKER_HOST %q: missing socket path", host)
+		}
+	case "tcp", "http", "https":
+		if parsed.Host == "" {
+			return fmt.Errorf("invalid GEMA_DOCKER_HOST %q: missing host", host)
+		}
+	default:
+		return fmt.Errorf("invalid GEMA_DOCKER_HOST %q: expected a unix, npipe, tcp or http(s) address", host)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func validConfig() Config {
+	return Config{
+		AppEnv:                  "development",
+		JWTSecret:               strongSecret,
+		JWTRefreshSecret:        "e4b8a2f6c0d9371b5a8e6f2c4d0b9a73",
+		JWTAccessTTL:            15 * time.Minute,
+		JWTRefreshTTL:           720 * time.Hour,
+		SSEClientTimeout:        55 * time.Second,
+		ExecutionTimeout:        5 * time.Second,
+		DockerHost:              "unix:///var/run/docker.sock",
+		CodeRunMemoryMB:         256,
+		CodeRunCPUShares:        512,
+		AIProvider:              "openai",
+		DiscussionStaleAction:   "lock",
+		ContactDeliveryProvider: "log",
+		DatabaseMigrations:      "auto",
+	}
+}
+
+func TestValidateAcceptsUsableConfig(t *testing.T) {
+	require.NoError(t, validConfig().Validate())
+}
+
+func TestValidateListsEveryProblem(t *testing.T) {
+	cfg := validConfig()
+	cfg.JWTSecret = ""
+	cfg.JWTRefreshTTL = time.Minute
+	cfg.DockerHost = "docker:2376"
+	cfg.CodeRunMemoryMB = 0
+	cfg.CodeRunCPUShares = -1
+	cfg.AIProvider = "gemini"
+	cfg.ContactDeliveryProvider = "smtp"
+	cfg.NATSJetStream = true
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	for _, want := range []string{
+		"GEMA_JWT_SECRET is required",
+		"GEMA_JWT_REFRESH_TTL",
+		"GEMA_DOCKER_HOST",
+		"GEMA_CODE_RUN_MEMORY_MB",
+		"GEMA_CODE_RUN_CPU_SHARES",
+		"GEMA_AI_PROVIDER",
+		"GEMA_SMTP_HOST",
+		"GEMA_NATS_STREAM",
+	} {
+		require.ErrorContains(t, err, want)
+	}
+}
+
+func TestValidateRequiresAIKeyInProduction(t *testing.T) {
+	cfg := validConfig()
+	require.NoError(t, cfg.Validate())
+
+	cfg.AppEnv = "production"
+	require.ErrorContains(t, cfg.Validate(), "GEMA_OPENAI_API_KEY is empty")
+
+	cfg.OpenAIAPIKey = "sk-test"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidateRejectsSharedJWTSecrets(t *testing.T) {
+	cfg := validConfig()
+	cfg.JWTRefreshSecret = cfg.JWTSecret
+	require.ErrorContains(t, cfg.Validate(), "GEMA_JWT_REFRESH_SECRET must differ from GEMA_JWT_SECRET")
+}
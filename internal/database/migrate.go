@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+// migrationLockKey is the Postgres advisory lock held while migrating, so two
+// instances started together apply the schema one after the other.
+const migrationLockKey = 0x67656d61
+
+// ErrSchemaOutdated is returned by CheckSchema when the database has not been
+// migrated to the schema this build expects.
+var ErrSchemaOutdated = errors.New("database schema is not up to date")
+
+// SchemaMigration records a schema version applied by Migrate.
+type SchemaMigration struct {
+	Version   string    `gorm:"primaryKey;size:64"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName keeps the marker table name stable.
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// schemaModels lists every model Migrate creates or updates.
+func schemaModels() []interface{} {
+	return []interface{}{
+		&models.Student{},
+		&models.Assignment{},
+		&models.Submission{},
+		&models.SubmissionGradeHistory{},
+		&models.SubmissionFingerprint{},
+		&models.WebAssignment{},
+		&models.WebSubmission{},
+		&models.CodingTask{},
+		&models.CodingTaskTestCase{},
+		&models.CodingSubmission{},
+		&models.CodingEvaluation{},
+		&models.ActivityLog{},
+		&models.ChatMessage{},
+		&models.ChatReadReceipt{},
+		&models.ChatRoom{},
+		&models.ChatRoomMember{},
+		&models.Notification{},
+		&models.NotificationMute{},
+		&models.OutboxEvent{},
+		&models.DiscussionThread{},
+		&models.DiscussionReply{},
+		&models.Announcement{},
+		&models.GalleryItem{},
+		&models.TutorialArticle{},
+		&models.TutorialProject{},
+		&models.RoadmapStage{},
+		&models.RoadmapProgress{},
+		&models.ContactSubmission{},
+		&models.UploadRecord{},
+		&models.WebhookSubscription{},
+		&models.WebhookDeadLetter{},
+	}
+}
+
+// SchemaVersion identifies the schema this build expects. It is derived from
+// the field names, types and tags of the migrated models, so any model change
+// yields a new version without a hand-maintained counter.
+func SchemaVersion() string {
+	hash := sha256.New()
+	for _, model := range schemaModels() {
+		writeStructShape(hash, reflect.TypeOf(model).Elem())
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+func writeStructShape(w interface{ Write([]byte) (int, error) }, t reflect.Type) {
+	fmt.Fprintf(w, "%s{", t.String())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			writeStructShape(w, field.Type)
+			continue
+		}
+		fmt.Fprintf(w, "%s %s %q;", field.Name, field.Type.String(), field.Tag)
+	}
+	fmt.Fprint(w, "}")
+}
+
+// Migrate brings the schema up to date and records SchemaVersion. On Postgres
+// it holds an advisory lock for the duration, so concurrent callers wait
+// rather than race each other.
+func Migrate(ctx context.Context, db *gorm.DB, logger zerolog.Logger) error {
+	db = db.WithContext(ctx)
+	if db.Dialector.Name() != "postgres" {
+		return migrate(ctx, db, logger)
+	}
+
+	// The lock is held by a session, so every statement runs on one connection.
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer func() {
+			if err := conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey).Error; err != nil {
+				logger.Warn().Err(err).Msg("failed to release migration lock")
+			}
+		}()
+		return migrate(ctx, conn, logger)
+	})
+}
+
+func migrate(ctx context.Context, db *gorm.DB, logger zerolog.Logger) error {
+	if err := db.AutoMigrate(append(schemaModels(), &SchemaMigration{})...); err != nil {
+		return fmt.Errorf("migrate models: %w", err)
+	}
+	if err := repository.EnsureSubmissionVersionIndex(ctx, db); err != nil {
+		logger.Warn().Err(err).Msg("submission version index not created; concurrent uploads may share a version number")
+	}
+	if err := repository.NormalizeStudentEmails(ctx, db); err != nil {
+		logger.Warn().Err(err).Msg("student emails not normalised; imports may not match mixed-case addresses")
+	}
+	if err := repository.PrefixUploadChecksums(ctx, db); err != nil {
+		logger.Warn().Err(err).Msg("legacy upload checksums not labelled; they will not match new uploads")
+	}
+	if db.Dialector.Name() == "postgres" {
+		if err := repository.EnsureUploadChecksumIndex(ctx, db); err != nil {
+			logger.Warn().Err(err).Msg("upload checksum index not created; duplicate uploads are still deduplicated by lookup")
+		}
+		if err := repository.EnsureTutorialSearchIndex(ctx, db); err != nil {
+			return fmt.Errorf("migrate tutorial search index: %w", err)
+		}
+	}
+
+	marker := SchemaMigration{Version: SchemaVersion(), AppliedAt: time.Now().UTC()}
+	if err := db.Where(SchemaMigration{Version: marker.Version}).FirstOrCreate(&marker).Error; err != nil {
+		return fmt.Errorf("record schema version: %w", err)
+	}
+	return nil
+}
+
+// CheckSchema returns ErrSchemaOutdated unless Migrate has recorded the
+// current SchemaVersion.
+func CheckSchema(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return fmt.Errorf("%w: no schema_migrations table, run cmd/migrate", ErrSchemaOutdated)
+	}
+
+	var count int64
+	if err := db.Model(&SchemaMigration{}).Where("version = ?", SchemaVersion()).Count(&count).Error; err != nil {
+		return fmt.Errorf("check schema version: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: version %s has not been applied, run cmd/migrate", ErrSchemaOutdated, SchemaVersion())
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMigrateRecordsSchemaVersion(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:migrate_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.ErrorIs(t, CheckSchema(ctx, db), ErrSchemaOutdated)
+
+	require.NoError(t, Migrate(ctx, db, zerolog.Nop()))
+	require.NoError(t, CheckSchema(ctx, db))
+
+	// Migrating again is a no-op and keeps a single marker.
+	require.NoError(t, Migrate(ctx, db, zerolog.Nop()))
+	var markers int64
+	require.NoError(t, db.Model(&SchemaMigration{}).Count(&markers).Error)
+	require.EqualValues(t, 1, markers)
+
+	require.NoError(t, db.Where("1 = 1").Delete(&SchemaMigration{}).Error)
+	require.NoError(t, db.Create(&SchemaMigration{Version: "older", AppliedAt: time.Now()}).Error)
+	require.ErrorIs(t, CheckSchema(ctx, db), ErrSchemaOutdated)
+}
+
+func TestSchemaVersionIsStable(t *testing.T) {
+	require.Equal(t, SchemaVersion(), SchemaVersion())
+	require.Len(t, SchemaVersion(), 16)
+}
@@ -2,13 +2,25 @@ package database
 
 import (
 	"fmt"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// ConnectPostgres establishes a connection to the PostgreSQL database using the provided DSN.
-func ConnectPostgres(dsn string) (*gorm.DB, error) {
+// PoolConfig tunes the connection pool behind a GORM handle. Zero values keep
+// the database/sql defaults: unlimited open connections, two idle ones, and
+// no lifetime or idle time limit.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// ConnectPostgres establishes a connection to the PostgreSQL database using the provided DSN
+// and applies the pool settings.
+func ConnectPostgres(dsn string, pool PoolConfig) (*gorm.DB, error) {
 	if dsn == "" {
 		return nil, fmt.Errorf("postgres dsn must not be empty")
 	}
@@ -18,5 +30,32 @@ func ConnectPostgres(dsn string) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
 	}
 
+	if err := ApplyPool(db, pool); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
+
+// ApplyPool configures the connection pool of the *sql.DB underlying db.
+func ApplyPool(db *gorm.DB, pool PoolConfig) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to access connection pool: %w", err)
+	}
+
+	if pool.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	}
+	if pool.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	if pool.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	}
+	if pool.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestApplyPoolConfiguresSQLDB(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:pool_test?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, ApplyPool(db, PoolConfig{
+		MaxOpenConns:    7,
+		MaxIdleConns:    3,
+		ConnMaxLifetime: time.Minute,
+		ConnMaxIdleTime: 30 * time.Second,
+	}))
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.Equal(t, 7, sqlDB.Stats().MaxOpenConnections)
+}
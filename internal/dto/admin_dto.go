@@ -17,6 +17,12 @@ type PaginationMeta struct {
 	TotalPages int   `json:"total_pages"`
 }
 
+// CursorMeta carries the token for the next page of a keyset-paginated list.
+// NextCursor is empty on the last page.
+type CursorMeta struct {
+	NextCursor string `json:"next_cursor"`
+}
+
 // AdminStudentListRequest defines filters for listing students.
 type AdminStudentListRequest struct {
 	Page     int
@@ -90,50 +96,134 @@ type AdminAssignmentCreateRequest struct {
 	MaxScore    float64            `json:"max_score" validate:"required,gt=0"`
 	Rubric      map[string]float64 `json:"rubric" validate:"omitempty,dive,keys,required,endkeys,gt=0"`
 	FileURL     string             `json:"file_url" validate:"omitempty,url"`
+	AllowLate   bool               `json:"allow_late"`
+	LatePenalty float64            `json:"late_penalty_percent" validate:"gte=0,lte=100"`
+	// MaxSubmissionMB overrides the global submission size limit; zero keeps the default.
+	MaxSubmissionMB int `json:"max_submission_mb" validate:"gte=0,lte=1024"`
 }
 
 // AdminAssignmentUpdateRequest allows patching assignment metadata.
 type AdminAssignmentUpdateRequest struct {
-	Title       *string            `json:"title" validate:"omitempty,min=3"`
-	Description *string            `json:"description" validate:"omitempty,min=5"`
-	DueDate     *string            `json:"due_date" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
-	MaxScore    *float64           `json:"max_score" validate:"omitempty,gt=0"`
-	Rubric      map[string]float64 `json:"rubric" validate:"omitempty,dive,keys,required,endkeys,gt=0"`
-	FileURL     *string            `json:"file_url" validate:"omitempty,url"`
+	Title           *string            `json:"title" validate:"omitempty,min=3"`
+	Description     *string            `json:"description" validate:"omitempty,min=5"`
+	DueDate         *string            `json:"due_date" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	MaxScore        *float64           `json:"max_score" validate:"omitempty,gt=0"`
+	Rubric          map[string]float64 `json:"rubric" validate:"omitempty,dive,keys,required,endkeys,gt=0"`
+	FileURL         *string            `json:"file_url" validate:"omitempty,url"`
+	AllowLate       *bool              `json:"allow_late"`
+	LatePenalty     *float64           `json:"late_penalty_percent" validate:"omitempty,gte=0,lte=100"`
+	MaxSubmissionMB *int               `json:"max_submission_mb" validate:"omitempty,gte=0,lte=1024"`
 }
 
 // AdminAssignmentResponse serializes assignment data for admin clients.
 type AdminAssignmentResponse struct {
-	ID          uint               `json:"id"`
-	Title       string             `json:"title"`
-	Description string             `json:"description"`
-	DueDate     time.Time          `json:"due_date"`
-	FileURL     string             `json:"file_url"`
-	MaxScore    float64            `json:"max_score"`
-	Rubric      map[string]float64 `json:"rubric"`
-	CreatedAt   time.Time          `json:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at"`
+	ID              uint               `json:"id"`
+	Title           string             `json:"title"`
+	Description     string             `json:"description"`
+	DueDate         time.Time          `json:"due_date"`
+	FileURL         string             `json:"file_url"`
+	MaxScore        float64            `json:"max_score"`
+	Rubric          map[string]float64 `json:"rubric"`
+	AllowLate       bool               `json:"allow_late"`
+	LatePenalty     float64            `json:"late_penalty_percent"`
+	MaxSubmissionMB int                `json:"max_submission_mb"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
 }
 
 // NewAdminAssignmentResponse converts a model into a DTO for admin clients.
 func NewAdminAssignmentResponse(model models.Assignment) AdminAssignmentResponse {
 	return AdminAssignmentResponse{
-		ID:          model.ID,
-		Title:       model.Title,
-		Description: model.Description,
-		DueDate:     model.DueDate,
-		FileURL:     model.FileURL,
-		MaxScore:    model.MaxScore,
-		Rubric:      floatMapFromJSON(model.Rubric),
-		CreatedAt:   model.CreatedAt,
-		UpdatedAt:   model.UpdatedAt,
+		ID:              model.ID,
+		Title:           model.Title,
+		Description:     model.Description,
+		DueDate:         model.DueDate,
+		FileURL:         model.FileURL,
+		MaxScore:        model.MaxScore,
+		Rubric:          FloatMapFromJSON(model.Rubric),
+		AllowLate:       model.AllowLate,
+		LatePenalty:     model.LatePenaltyPercent,
+		MaxSubmissionMB: model.MaxSubmissionMB,
+		CreatedAt:       model.CreatedAt,
+		UpdatedAt:       model.UpdatedAt,
 	}
 }
 
+// AdminAssignmentImportRowResult reports the outcome of a single CSV row.
+type AdminAssignmentImportRowResult struct {
+	Line         int    `json:"line"`
+	Title        string `json:"title"`
+	AssignmentID *uint  `json:"assignment_id,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// AdminAssignmentImportResponse summarises a bulk assignment import.
+type AdminAssignmentImportResponse struct {
+	Strict  bool                             `json:"strict"`
+	Total   int                              `json:"total"`
+	Created int                              `json:"created"`
+	Failed  int                              `json:"failed"`
+	Rows    []AdminAssignmentImportRowResult `json:"rows"`
+}
+
+// AdminStudentImportRecord is one row of a student import CSV.
+type AdminStudentImportRecord struct {
+	Name  string `validate:"required,max=255"`
+	Email string `validate:"required,email,max=255"`
+	Class string `validate:"omitempty,max=128"`
+}
+
+// AdminStudentImportRowResult reports the outcome of a single CSV row.
+// Action is "created" or "updated" for rows that were written.
+type AdminStudentImportRowResult struct {
+	Line   int    `json:"line"`
+	Email  string `json:"email"`
+	Action string `json:"action,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AdminStudentImportResponse summarises a bulk student import.
+type AdminStudentImportResponse struct {
+	Total   int                           `json:"total"`
+	Created int                           `json:"created"`
+	Updated int                           `json:"updated"`
+	Failed  int                           `json:"failed"`
+	Rows    []AdminStudentImportRowResult `json:"rows"`
+}
+
+// AdminNotificationBatchRequest describes a notification sent to a cohort.
+// Recipients come either from an uploaded CSV of user IDs or from Class.
+type AdminNotificationBatchRequest struct {
+	Type     string `json:"type" form:"type" validate:"required,max=64"`
+	Message  string `json:"message" form:"message" validate:"required,min=1,max=2000"`
+	Class    string `json:"class" form:"class" validate:"omitempty,max=128"`
+	Priority string `json:"priority" form:"priority" validate:"omitempty,oneof=low normal high"`
+}
+
+// AdminNotificationBatchRowResult reports the outcome for a single recipient.
+type AdminNotificationBatchRowResult struct {
+	Line           int    `json:"line,omitempty"`
+	UserID         string `json:"user_id"`
+	NotificationID *uint  `json:"notification_id,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// AdminNotificationBatchResponse summarises a batch notification send.
+type AdminNotificationBatchResponse struct {
+	Total     int                               `json:"total"`
+	Delivered int                               `json:"delivered"`
+	Failed    int                               `json:"failed"`
+	Rows      []AdminNotificationBatchRowResult `json:"rows"`
+}
+
 // AdminGradeSubmissionRequest captures payloads for grading submissions.
+// When RubricScores is set the score is their total and Score is ignored.
+// Reason is required when the submission already has a grade.
 type AdminGradeSubmissionRequest struct {
-	Score    float64 `json:"score" validate:"required,gte=0"`
-	Feedback string  `json:"feedback" validate:"omitempty,max=5000"`
+	Score        float64            `json:"score" validate:"required_without=RubricScores,gte=0"`
+	RubricScores map[string]float64 `json:"rubric_scores" validate:"omitempty,dive,keys,required,endkeys,gte=0"`
+	Feedback     string             `json:"feedback" validate:"omitempty,max=5000"`
+	Reason       string             `json:"reason" validate:"omitempty,max=1000"`
 }
 
 // GradeDistributionResponse represents aggregated grade buckets.
@@ -145,17 +235,84 @@ type WeeklyEngagementPoint struct {
 	Submissions int64     `json:"submissions"`
 }
 
+// AdminAnalyticsQuery scopes analytics to submissions created between From and
+// To, inclusive. Nil bounds leave the range open; both nil means all time.
+type AdminAnalyticsQuery struct {
+	From *time.Time
+	To   *time.Time
+}
+
 // AdminAnalyticsResponse aggregates analytics metrics for administrators.
+// From and To echo the requested range.
 type AdminAnalyticsResponse struct {
 	ActiveStudents    int64                     `json:"active_students"`
 	OnTimeSubmissions int64                     `json:"on_time_submissions"`
 	LateSubmissions   int64                     `json:"late_submissions"`
 	GradeDistribution GradeDistributionResponse `json:"grade_distribution"`
+	AveragePercent    *float64                  `json:"average_grade_percent,omitempty"`
 	WeeklyEngagement  []WeeklyEngagementPoint   `json:"weekly_engagement"`
+	From              *time.Time                `json:"from,omitempty"`
+	To                *time.Time                `json:"to,omitempty"`
+	GeneratedAt       time.Time                 `json:"generated_at"`
+	CacheHit          bool                      `json:"cache_hit"`
+}
+
+// AdminAssignmentAnalyticsResponse breaks analytics down for one assignment.
+// Only each student's latest submission version counts towards the timing,
+// grade and completion figures; Submissions counts every version.
+type AdminAssignmentAnalyticsResponse struct {
+	AssignmentID      uint                      `json:"assignment_id"`
+	Title             string                    `json:"title"`
+	DueDate           time.Time                 `json:"due_date"`
+	Submissions       int64                     `json:"submissions"`
+	StudentsSubmitted int64                     `json:"students_submitted"`
+	ActiveStudents    int64                     `json:"active_students"`
+	CompletionRate    float64                   `json:"completion_rate"`
+	OnTimeSubmissions int64                     `json:"on_time_submissions"`
+	LateSubmissions   int64                     `json:"late_submissions"`
+	GradeDistribution GradeDistributionResponse `json:"grade_distribution"`
+	AveragePercent    *float64                  `json:"average_grade_percent,omitempty"`
+	MedianPercent     *float64                  `json:"median_grade_percent,omitempty"`
 	GeneratedAt       time.Time                 `json:"generated_at"`
 	CacheHit          bool                      `json:"cache_hit"`
 }
 
+// AdminGradeExportQuery selects the submissions in a grade export. A zero
+// AssignmentID exports every assignment.
+type AdminGradeExportQuery struct {
+	AssignmentID uint
+	Format       string
+}
+
+// AdminGradingQueueQuery selects the assignment a grader works through.
+// Anonymized hides the student's identity in the returned submission.
+type AdminGradingQueueQuery struct {
+	AssignmentID uint
+	Anonymized   bool
+}
+
+// AdminGradingQueueResponse is the submission a grader should grade next. A
+// nil Submission with AllGraded false means every remaining submission is
+// currently claimed by another grader.
+type AdminGradingQueueResponse struct {
+	AssignmentID   uint                `json:"assignment_id"`
+	Submission     *SubmissionResponse `json:"submission"`
+	ClaimExpiresAt *time.Time          `json:"claim_expires_at,omitempty"`
+	Remaining      int64               `json:"remaining"`
+	AllGraded      bool                `json:"all_graded"`
+	Anonymized     bool                `json:"anonymized"`
+}
+
+// AdminAnalyticsReport is the downloadable form of the analytics summary. A
+// nil From or To means the range is open on that side.
+type AdminAnalyticsReport struct {
+	ExportedAt      time.Time              `json:"exported_at"`
+	From            *time.Time             `json:"from"`
+	To              *time.Time             `json:"to"`
+	EngagementSince time.Time              `json:"weekly_engagement_since"`
+	Analytics       AdminAnalyticsResponse `json:"analytics"`
+}
+
 // AdminActivityListRequest defines filters for retrieving activity logs.
 type AdminActivityListRequest struct {
 	Page       int
@@ -165,6 +322,13 @@ type AdminActivityListRequest struct {
 	EntityType string
 }
 
+// AdminActivityExportQuery bounds an activity export by creation time. Nil
+// bounds leave the range open.
+type AdminActivityExportQuery struct {
+	From *time.Time
+	To   *time.Time
+}
+
 // AdminActivityCreateRequest captures manual activity log creation payloads.
 type AdminActivityCreateRequest struct {
 	Action     string                 `json:"action" validate:"required,min=3"`
@@ -191,13 +355,21 @@ type AdminActivityListResponse struct {
 	Pagination PaginationMeta          `json:"pagination"`
 }
 
-// AdminContactListRequest defines filters for contact submissions.
+// AdminContactListRequest defines filters for contact submissions. Emails
+// are masked unless RevealEmail is set.
 type AdminContactListRequest struct {
-	Page     int
-	PageSize int
-	Status   string
-	Search   string
-	Sort     string
+	Page        int
+	PageSize    int
+	Status      string
+	Search      string
+	Sort        string
+	RevealEmail bool
+}
+
+// AdminContactStatusRequest moves a contact submission through the inbox
+// workflow.
+type AdminContactStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=queued sent read resolved spam"`
 }
 
 // AdminContactResponse serializes contact submissions for admin views.
@@ -210,6 +382,7 @@ type AdminContactResponse struct {
 	Status      string     `json:"status"`
 	Source      string     `json:"source"`
 	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 	DeliveredAt *time.Time `json:"delivered_at"`
 }
 
@@ -219,24 +392,48 @@ type AdminContactListResponse struct {
 	Pagination PaginationMeta         `json:"pagination"`
 }
 
-// AdminGalleryRequest captures gallery mutation payloads.
+// AdminGalleryRequest captures gallery mutation payloads. Featured and
+// SortOrder keep their current values when omitted on update.
 type AdminGalleryRequest struct {
-	Title   string   `json:"title" validate:"required,min=3"`
-	Caption string   `json:"caption" validate:"omitempty,max=500"`
-	ImageURL string  `json:"image_url" validate:"required,url"`
-	Tags    []string `json:"tags" validate:"omitempty,dive,required"`
+	Title     string   `json:"title" validate:"required,min=3"`
+	Caption   string   `json:"caption" validate:"omitempty,max=500"`
+	ImageURL  string   `json:"image_url" validate:"required,url"`
+	Tags      []string `json:"tags" validate:"omitempty,dive,required"`
+	Featured  *bool    `json:"featured"`
+	SortOrder *int     `json:"sort_order" validate:"omitempty,gte=0"`
+}
+
+// AdminGalleryUploadRequest captures the form fields sent alongside an
+// uploaded gallery image.
+type AdminGalleryUploadRequest struct {
+	Title     string   `form:"title" validate:"required,min=3"`
+	Caption   string   `form:"caption" validate:"omitempty,max=500"`
+	Tags      []string `form:"tags" validate:"omitempty,dive,required"`
+	Featured  bool     `form:"featured"`
+	SortOrder int      `form:"sort_order" validate:"gte=0"`
+}
+
+// AdminGalleryReorderRequest lists gallery item IDs in their new display
+// order.
+type AdminGalleryReorderRequest struct {
+	IDs []uint `json:"ids" validate:"required,min=1,max=500,unique,dive,gt=0"`
 }
 
 // AdminGalleryResponse serializes gallery items for admin routes.
 type AdminGalleryResponse struct {
-	ID        uint      `json:"id"`
-	Slug      string    `json:"slug"`
-	Title     string    `json:"title"`
-	Caption   string    `json:"caption"`
-	ImageURL  string    `json:"image_url"`
-	Tags      []string  `json:"tags"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           uint      `json:"id"`
+	Slug         string    `json:"slug"`
+	Title        string    `json:"title"`
+	Caption      string    `json:"caption"`
+	ImageURL     string    `json:"image_url"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	Featured     bool      `json:"featured"`
+	SortOrder    int       `json:"sort_order"`
+	Tags         []string  `json:"tags"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 // AdminGalleryListResponse wraps paginated gallery data.
@@ -253,25 +450,71 @@ type AdminGalleryListRequest struct {
 	Search   string
 }
 
-// AdminAnnouncementRequest captures admin announcement payloads.
-type AdminAnnouncementRequest struct {
-	Title    string `json:"title" validate:"required,min=5"`
-	Body     string `json:"body" validate:"required,min=20"`
-	StartsAt string `json:"starts_at" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
-	EndsAt   string `json:"ends_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
-	IsPinned bool   `json:"is_pinned"`
+// AdminRoadmapStageRequest captures roadmap stage creation payloads.
+type AdminRoadmapStageRequest struct {
+	Title          string            `json:"title" validate:"required,min=3"`
+	Description    string            `json:"description" validate:"omitempty,max=2000"`
+	Sequence       int               `json:"sequence" validate:"omitempty,gte=0"`
+	EstimatedHours int               `json:"estimated_hours" validate:"omitempty,gte=1,lte=200"`
+	Icon           string            `json:"icon" validate:"omitempty,max=64"`
+	Tags           []string          `json:"tags" validate:"omitempty,dive,required"`
+	Skills         map[string]string `json:"skills" validate:"omitempty,max=50"`
+}
+
+// AdminRoadmapStageUpdateRequest patches a roadmap stage. Only the fields
+// present are changed.
+type AdminRoadmapStageUpdateRequest struct {
+	Title          *string            `json:"title" validate:"omitempty,min=3"`
+	Description    *string            `json:"description" validate:"omitempty,max=2000"`
+	Sequence       *int               `json:"sequence" validate:"omitempty,gte=0"`
+	EstimatedHours *int               `json:"estimated_hours" validate:"omitempty,gte=1,lte=200"`
+	Icon           *string            `json:"icon" validate:"omitempty,max=64"`
+	Tags           *[]string          `json:"tags" validate:"omitempty,dive,required"`
+	Skills         *map[string]string `json:"skills" validate:"omitempty,max=50"`
 }
 
-// AdminAnnouncementResponse serializes admin announcement entities.
+// AdminRoadmapReorderRequest lists roadmap stage IDs in their new order.
+type AdminRoadmapReorderRequest struct {
+	IDs []uint `json:"ids" validate:"required,min=1,max=500,unique,dive,gt=0"`
+}
+
+// AdminAnnouncementRequest captures admin announcement payloads. Audience
+// lists the class names or roles that should see the announcement; empty
+// means everyone.
+type AdminAnnouncementRequest struct {
+	Title    string   `json:"title" validate:"required,min=5"`
+	Body     string   `json:"body" validate:"required,min=20"`
+	StartsAt string   `json:"starts_at" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	EndsAt   string   `json:"ends_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	IsPinned bool     `json:"is_pinned"`
+	Audience []string `json:"audience" validate:"omitempty,max=50,dive,max=128"`
+}
+
+// AdminAnnouncementUpdateRequest patches an announcement. An empty EndsAt
+// clears the end so the announcement no longer expires.
+type AdminAnnouncementUpdateRequest struct {
+	Title    *string   `json:"title" validate:"omitempty,min=5"`
+	Body     *string   `json:"body" validate:"omitempty,min=20"`
+	StartsAt *string   `json:"starts_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	EndsAt   *string   `json:"ends_at"`
+	IsPinned *bool     `json:"is_pinned"`
+	Audience *[]string `json:"audience" validate:"omitempty,max=50,dive,max=128"`
+}
+
+// AdminAnnouncementResponse serializes admin announcement entities. Status is
+// scheduled, active or expired as of the request.
 type AdminAnnouncementResponse struct {
-	ID       uint       `json:"id"`
-	Slug     string     `json:"slug"`
-	Title    string     `json:"title"`
-	Body     string     `json:"body"`
-	StartsAt time.Time  `json:"starts_at"`
-	EndsAt   *time.Time `json:"ends_at"`
-	IsPinned bool       `json:"is_pinned"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uint       `json:"id"`
+	Slug      string     `json:"slug"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	StartsAt  time.Time  `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+	IsPinned  bool       `json:"is_pinned"`
+	Audience  []string   `json:"audience"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
 }
 
 // AdminAnnouncementListResponse wraps paginated announcements.
@@ -281,10 +524,12 @@ type AdminAnnouncementListResponse struct {
 }
 
 // AdminAnnouncementListRequest captures filters for admin announcement list.
+// An empty Status lists every announcement.
 type AdminAnnouncementListRequest struct {
 	Page     int
 	PageSize int
 	Search   string
+	Status   string `validate:"omitempty,oneof=scheduled active expired"`
 }
 
 func boolMapFromJSON(data datatypes.JSONMap) map[string]bool {
@@ -311,7 +556,9 @@ func boolMapFromJSON(data datatypes.JSONMap) map[string]bool {
 	return result
 }
 
-func floatMapFromJSON(data datatypes.JSONMap) map[string]float64 {
+// FloatMapFromJSON reads a JSON column of numbers, such as a rubric, skipping
+// values that are not numeric.
+func FloatMapFromJSON(data datatypes.JSONMap) map[string]float64 {
 	result := make(map[string]float64)
 	if data == nil {
 		return result
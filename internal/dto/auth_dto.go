@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// AuthRefreshRequest exchanges a refresh token for a new token pair.
+type AuthRefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// AuthLogoutRequest ends a session. TokenID and ExpiresAt describe the access
+// token that authenticated the request; the optional refresh token is revoked
+// with it.
+type AuthLogoutRequest struct {
+	TokenID      string    `json:"-"`
+	ExpiresAt    time.Time `json:"-"`
+	RefreshToken string    `json:"refresh_token"`
+}
+
+// AuthTokenResponse carries a short-lived access token and the refresh token
+// that replaces the one presented.
+type AuthTokenResponse struct {
+	AccessToken           string    `json:"access_token"`
+	TokenType             string    `json:"token_type"`
+	ExpiresIn             int64     `json:"expires_in"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
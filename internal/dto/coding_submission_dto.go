@@ -7,22 +7,108 @@ type CodingSubmissionRequest struct {
 	TaskID   uint   `json:"task_id" validate:"required,gt=0"`
 	Language string `json:"language" validate:"required"`
 	Source   string `json:"source" validate:"required,min=1"`
+	Stdin    string `json:"stdin" validate:"max=65536"`
+}
+
+// CodingSubmissionFilter defines query parameters for listing coding
+// submissions. StudentID is ignored for students, who only see their own.
+type CodingSubmissionFilter struct {
+	TaskID    uint   `query:"task_id"`
+	StudentID uint   `query:"student_id"`
+	Status    string `query:"status" validate:"omitempty,oneof=pending completed failed compile_error timeout evaluated"`
+	Language  string `query:"language"`
+	Page      int    `query:"page"`
+	PageSize  int    `query:"page_size"`
+}
+
+// CodingSubmissionListResponse wraps coding submissions and pagination metadata.
+type CodingSubmissionListResponse struct {
+	Items      []CodingSubmissionResponse `json:"items"`
+	Pagination Pagination                 `json:"pagination"`
 }
 
 // CodingSubmissionResponse represents a coding submission to API consumers.
 type CodingSubmissionResponse struct {
-	ID          uint                       `json:"id"`
-	TaskID      uint                       `json:"task_id"`
-	StudentID   uint                       `json:"student_id"`
-	Language    string                     `json:"language"`
-	Source      string                     `json:"source,omitempty"`
-	Status      string                     `json:"status"`
-	Output      string                     `json:"output"`
-	Error       string                     `json:"error"`
-	CPUTimeMs   int64                      `json:"cpu_time_ms"`
-	MemoryKB    int64                      `json:"memory_kb"`
-	Task        CodingTaskResponse         `json:"task"`
-	Evaluations []CodingEvaluationResponse `json:"evaluations"`
+	ID            uint                       `json:"id"`
+	TaskID        uint                       `json:"task_id"`
+	StudentID     uint                       `json:"student_id"`
+	Language      string                     `json:"language"`
+	Source        string                     `json:"source,omitempty"`
+	Stdin         string                     `json:"stdin,omitempty"`
+	Status        string                     `json:"status"`
+	Output        string                     `json:"output"`
+	Error         string                     `json:"error"`
+	CompileOutput string                     `json:"compile_output,omitempty"`
+	CompileError  string                     `json:"compile_error,omitempty"`
+	CPUTimeMs     int64                      `json:"cpu_time_ms"`
+	MemoryKB      int64                      `json:"memory_kb"`
+	Comparison    *CodingOutputComparison    `json:"comparison,omitempty"`
+	Score         *float64                   `json:"score,omitempty"`
+	HasFullLog    bool                       `json:"full_log_available,omitempty"`
+	EnvVars       []string                   `json:"env_vars,omitempty"`
+	TestResults   []CodingTestCaseResult     `json:"test_results,omitempty"`
+	Task          CodingTaskResponse         `json:"task"`
+	Evaluations   []CodingEvaluationResponse `json:"evaluations"`
+}
+
+// CodingOutputComparison reports how program output was checked against the expected output.
+type CodingOutputComparison struct {
+	Mode    string `json:"mode"`
+	Matched bool   `json:"matched"`
+	Diff    string `json:"diff,omitempty"`
+}
+
+// CodingTestCaseResult reports a submission's outcome for one test case.
+// Output and errors of hidden cases are only shown to staff.
+type CodingTestCaseResult struct {
+	TestCaseID   uint    `json:"test_case_id"`
+	Passed       bool    `json:"passed"`
+	Hidden       bool    `json:"hidden"`
+	Weight       float64 `json:"weight"`
+	ActualOutput string  `json:"actual_output,omitempty"`
+	Error        string  `json:"error,omitempty"`
+	TimedOut     bool    `json:"timed_out,omitempty"`
+	CPUTimeMs    int64   `json:"cpu_time_ms"`
+}
+
+// Frame types sent over the coding lab execution stream.
+const (
+	CodingStreamOutput = "output"
+	CodingStreamResult = "result"
+	CodingStreamError  = "error"
+)
+
+// CodingStreamMessage is a single frame of a streamed submission run. Output
+// frames carry a chunk of stdout or stderr; the closing result frame carries
+// the exit code, resource usage, whether output hit the executor's output
+// limit and the stored submission.
+type CodingStreamMessage struct {
+	Type       string                    `json:"type"`
+	Stream     string                    `json:"stream,omitempty"`
+	Data       string                    `json:"data,omitempty"`
+	ExitCode   *int                      `json:"exit_code,omitempty"`
+	TimedOut   bool                      `json:"timed_out,omitempty"`
+	Truncated  bool                      `json:"truncated,omitempty"`
+	DurationMs int64                     `json:"duration_ms,omitempty"`
+	CPUTimeMs  int64                     `json:"cpu_time_ms,omitempty"`
+	MemoryKB   int64                     `json:"memory_kb,omitempty"`
+	Submission *CodingSubmissionResponse `json:"submission,omitempty"`
+	Error      string                    `json:"error,omitempty"`
+}
+
+// CodingLanguageStats reports execution outcomes and resource usage for one language.
+// Rates are percentages of the language's total submissions.
+type CodingLanguageStats struct {
+	Language     string  `json:"language"`
+	Total        int64   `json:"total"`
+	Succeeded    int64   `json:"succeeded"`
+	Failed       int64   `json:"failed"`
+	TimedOut     int64   `json:"timed_out"`
+	SuccessRate  float64 `json:"success_rate"`
+	FailureRate  float64 `json:"failure_rate"`
+	TimeoutRate  float64 `json:"timeout_rate"`
+	AvgCPUTimeMs float64 `json:"avg_cpu_time_ms"`
+	AvgMemoryKB  float64 `json:"avg_memory_kb"`
 }
 
 // CodingEvaluationResponse describes the AI evaluation payload.
@@ -36,23 +122,57 @@ type CodingEvaluationResponse struct {
 	Provider string                 `json:"provider"`
 }
 
-// NewCodingSubmissionResponse builds a response DTO from a model.
-func NewCodingSubmissionResponse(submission models.CodingSubmission, includeSource bool) CodingSubmissionResponse {
+// NewCodingSubmissionResponse builds a response DTO from a model. Hidden test
+// case details are stripped unless includeHidden is set.
+func NewCodingSubmissionResponse(submission models.CodingSubmission, includeSource, includeHidden bool) CodingSubmissionResponse {
 	response := CodingSubmissionResponse{
-		ID:        submission.ID,
-		TaskID:    submission.TaskID,
-		StudentID: submission.StudentID,
-		Language:  submission.Language,
-		Status:    submission.Status,
-		Output:    submission.Output,
-		Error:     submission.Error,
-		CPUTimeMs: submission.CPUTimeMs,
-		MemoryKB:  submission.MemoryKB,
-		Task:      NewCodingTaskResponse(submission.Task),
+		ID:            submission.ID,
+		TaskID:        submission.TaskID,
+		StudentID:     submission.StudentID,
+		Language:      submission.Language,
+		Status:        submission.Status,
+		Output:        submission.Output,
+		Error:         submission.Error,
+		CompileOutput: submission.CompileOutput,
+		CompileError:  submission.CompileError,
+		CPUTimeMs:     submission.CPUTimeMs,
+		MemoryKB:      submission.MemoryKB,
+		Score:         submission.Score,
+		HasFullLog:    submission.LogURL != "",
+		EnvVars:       submission.EnvNameList(),
+		Task:          NewCodingTaskResponse(submission.Task),
 	}
 
 	if includeSource {
 		response.Source = submission.Source
+		response.Stdin = submission.Stdin
+	}
+
+	if submission.Matched != nil {
+		response.Comparison = &CodingOutputComparison{
+			Mode:    submission.Comparison,
+			Matched: *submission.Matched,
+			Diff:    submission.OutputDiff,
+		}
+	}
+
+	if results := submission.TestResultList(); len(results) > 0 {
+		response.TestResults = make([]CodingTestCaseResult, 0, len(results))
+		for _, result := range results {
+			item := CodingTestCaseResult{
+				TestCaseID: result.TestCaseID,
+				Passed:     result.Passed,
+				Hidden:     result.Hidden,
+				Weight:     result.Weight,
+				TimedOut:   result.TimedOut,
+				CPUTimeMs:  result.CPUTimeMs,
+			}
+			if !result.Hidden || includeHidden {
+				item.ActualOutput = result.ActualOutput
+				item.Error = result.Error
+			}
+			response.TestResults = append(response.TestResults, item)
+		}
 	}
 
 	if len(submission.Evaluations) > 0 {
@@ -8,8 +8,34 @@ type CodingTaskFilter struct {
 	Difficulty string   `query:"difficulty"`
 	Tags       []string `query:"tags"`
 	Search     string   `query:"search"`
+	Sort       string   `query:"sort"`
 	Page       int      `query:"page"`
 	PageSize   int      `query:"page_size"`
+	// IncludeInactive exposes deactivated tasks; only staff may set it.
+	IncludeInactive bool `query:"-"`
+	// StudentID is the authenticated student, whose best score is reported
+	// per task. Zero omits scores.
+	StudentID uint `query:"-"`
+}
+
+// CodingTaskAppliedFilters echoes the normalised filters a task list was built with.
+type CodingTaskAppliedFilters struct {
+	Language   string   `json:"language,omitempty"`
+	Difficulty string   `json:"difficulty,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Search     string   `json:"search,omitempty"`
+	Sort       string   `json:"sort"`
+}
+
+// CodingTaskEnvRequest replaces the environment variables a coding task
+// passes to every run. An empty map clears them.
+type CodingTaskEnvRequest struct {
+	Env map[string]string `json:"env"`
+}
+
+// CodingTaskActiveRequest toggles whether a coding task accepts submissions.
+type CodingTaskActiveRequest struct {
+	Active *bool `json:"active"`
 }
 
 // Pagination describes pagination metadata for list responses.
@@ -29,12 +55,18 @@ type CodingTaskResponse struct {
 	Difficulty     string   `json:"difficulty"`
 	Tags           []string `json:"tags"`
 	ExpectedOutput string   `json:"expected_output"`
+	Comparison     string   `json:"output_comparison"`
+	Active         bool     `json:"active"`
+	EnvVars        []string `json:"env_vars,omitempty"`
+	// BestScore is the viewing student's highest evaluation score, if any.
+	BestScore *float64 `json:"best_score,omitempty"`
 }
 
 // CodingTaskListResponse wraps coding tasks and pagination metadata.
 type CodingTaskListResponse struct {
-	Items      []CodingTaskResponse `json:"items"`
-	Pagination Pagination           `json:"pagination"`
+	Items      []CodingTaskResponse     `json:"items"`
+	Pagination Pagination               `json:"pagination"`
+	Filters    CodingTaskAppliedFilters `json:"filters"`
 }
 
 // CodingTaskDetailResponse extends CodingTaskResponse with metadata.
@@ -53,6 +85,9 @@ func NewCodingTaskResponse(task models.CodingTask) CodingTaskResponse {
 		Difficulty:     task.Difficulty,
 		Tags:           task.TagsSlice(),
 		ExpectedOutput: task.ExpectedOutput,
+		Comparison:     task.ComparisonMode(),
+		Active:         task.Active,
+		EnvVars:        task.EnvNames(),
 	}
 }
 
@@ -54,11 +54,77 @@ func NewChatMessageResponseSlice(messages []models.ChatMessage) []ChatMessageRes
 	return out
 }
 
+// ChatDisconnectRequest describes a moderator kicking a chat user.
+type ChatDisconnectRequest struct {
+	Reason          string `json:"reason" validate:"omitempty,max=255"`
+	CooldownSeconds int    `json:"cooldown_seconds" validate:"gte=0,lte=86400"`
+}
+
+// ChatDisconnectResponse reports the outcome of a moderator disconnect.
+// ClosedConnections only counts connections held by the node that served the request.
+type ChatDisconnectResponse struct {
+	UserID            string     `json:"user_id"`
+	ClosedConnections int        `json:"closed_connections"`
+	BlockedUntil      *time.Time `json:"blocked_until,omitempty"`
+}
+
+// ChatRoomSummary reports the latest message and unread count for a room.
+type ChatRoomSummary struct {
+	RoomID      string               `json:"room_id"`
+	LastMessage *ChatMessageResponse `json:"last_message"`
+	UnreadCount int64                `json:"unread_count"`
+}
+
+// ChatRoomCreateRequest creates a group room. The creator is always a member.
+type ChatRoomCreateRequest struct {
+	Name    string   `json:"name" validate:"omitempty,max=255"`
+	Members []string `json:"members" validate:"required,min=1,max=50,dive,required,max=64"`
+}
+
+// ChatRoomMembersRequest adds members to a group room.
+type ChatRoomMembersRequest struct {
+	Members []string `json:"members" validate:"required,min=1,max=50,dive,required,max=64"`
+}
+
+// ChatRoomResponse describes a group room and its members.
+type ChatRoomResponse struct {
+	RoomID    string    `json:"room_id"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"created_by"`
+	Members   []string  `json:"members"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewChatRoomResponse converts a room with its members into a DTO.
+func NewChatRoomResponse(room models.ChatRoom) ChatRoomResponse {
+	members := make([]string, 0, len(room.Members))
+	for _, member := range room.Members {
+		members = append(members, member.UserID)
+	}
+	return ChatRoomResponse{
+		RoomID:    room.RoomID,
+		Name:      room.Name,
+		CreatedBy: room.CreatedBy,
+		Members:   members,
+		CreatedAt: room.CreatedAt,
+	}
+}
+
 // NotificationCreateRequest describes the payload to create a notification.
+// An empty Priority means normal.
 type NotificationCreateRequest struct {
-	UserID  string `json:"user_id" validate:"required,max=64"`
-	Type    string `json:"type" validate:"required,max=64"`
-	Message string `json:"message" validate:"required,min=1,max=2000"`
+	UserID   string `json:"user_id" validate:"required,max=64"`
+	Type     string `json:"type" validate:"required,max=64"`
+	Message  string `json:"message" validate:"required,min=1,max=2000"`
+	Priority string `json:"priority" validate:"omitempty,oneof=low normal high"`
+}
+
+// NotificationBulkCreateRequest sends the same notification to many users.
+type NotificationBulkCreateRequest struct {
+	UserIDs  []string `json:"user_ids" validate:"required,min=1,dive,required,max=64"`
+	Type     string   `json:"type" validate:"required,max=64"`
+	Message  string   `json:"message" validate:"required,min=1,max=2000"`
+	Priority string   `json:"priority" validate:"omitempty,oneof=low normal high"`
 }
 
 // NotificationResponse represents notification data returned to clients.
@@ -67,11 +133,17 @@ type NotificationResponse struct {
 	UserID    string    `json:"user_id"`
 	Type      string    `json:"type"`
 	Message   string    `json:"message"`
+	Priority  string    `json:"priority"`
 	Read      bool      `json:"read"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// NotificationMuteResponse lists the notification types a user has muted.
+type NotificationMuteResponse struct {
+	Types []string `json:"types"`
+}
+
 // NewNotificationResponse converts a notification model to DTO.
 func NewNotificationResponse(model models.Notification) NotificationResponse {
 	return NotificationResponse{
@@ -79,6 +151,7 @@ func NewNotificationResponse(model models.Notification) NotificationResponse {
 		UserID:    model.UserID,
 		Type:      model.Type,
 		Message:   model.Message,
+		Priority:  model.Priority,
 		Read:      model.Read,
 		CreatedAt: model.CreatedAt,
 		UpdatedAt: model.UpdatedAt,
@@ -100,8 +173,11 @@ type DiscussionThreadCreateRequest struct {
 }
 
 // DiscussionThreadUpdateRequest updates an existing thread.
+// Pinned and Status may only be changed by teachers and admins.
 type DiscussionThreadUpdateRequest struct {
-	Title *string `json:"title" validate:"omitempty,min=3,max=255"`
+	Title  *string `json:"title" validate:"omitempty,min=3,max=255"`
+	Pinned *bool   `json:"pinned"`
+	Status *string `json:"status" validate:"omitempty,oneof=open locked archived"`
 }
 
 // DiscussionThreadResponse describes a thread returned by the API.
@@ -110,6 +186,9 @@ type DiscussionThreadResponse struct {
 	Title     string                    `json:"title"`
 	AuthorID  string                    `json:"author_id"`
 	Metadata  map[string]string         `json:"metadata,omitempty"`
+	Pinned    bool                      `json:"pinned"`
+	Status    string                    `json:"status"`
+	ClosedAt  *time.Time                `json:"closed_at,omitempty"`
 	CreatedAt time.Time                 `json:"created_at"`
 	UpdatedAt time.Time                 `json:"updated_at"`
 	Replies   []DiscussionReplyResponse `json:"replies,omitempty"`
@@ -137,9 +216,15 @@ func NewDiscussionThreadResponse(model models.DiscussionThread) DiscussionThread
 		ID:        model.ID,
 		Title:     model.Title,
 		AuthorID:  model.AuthorID,
+		Pinned:    model.Pinned,
+		Status:    model.Status,
+		ClosedAt:  model.ClosedAt,
 		CreatedAt: model.CreatedAt,
 		UpdatedAt: model.UpdatedAt,
 	}
+	if response.Status == "" {
+		response.Status = models.DiscussionThreadOpen
+	}
 	if model.Metadata != nil {
 		response.Metadata = make(map[string]string)
 		for key, value := range model.Metadata {
@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/noah-isme/gema-go-api/internal/models"
+)
 
 // ActivityFeedRequest describes the incoming query for active activities.
 type ActivityFeedRequest struct {
@@ -23,6 +27,20 @@ type ActivityFeedItem struct {
 	CreatedAt  time.Time              `json:"created_at"`
 }
 
+// NewActivityFeedItem converts an activity log entry for the feed.
+func NewActivityFeedItem(entry models.ActivityLog) ActivityFeedItem {
+	return ActivityFeedItem{
+		ID:         entry.ID,
+		ActorID:    entry.ActorID,
+		ActorRole:  entry.ActorRole,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Metadata:   map[string]interface{}(entry.Metadata),
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
 // ActivityFeedResponse wraps paginated activity items.
 type ActivityFeedResponse struct {
 	Items      []ActivityFeedItem `json:"items"`
@@ -41,6 +59,18 @@ type AnnouncementResponse struct {
 	CreatedAt time.Time  `json:"created_at"`
 }
 
+// AnnouncementViewer identifies who is reading announcements. Both fields are
+// empty for anonymous readers, who only see announcements for everyone.
+type AnnouncementViewer struct {
+	Role  string
+	Class string
+}
+
+// Audience returns the normalized audience entries the viewer matches.
+func (v AnnouncementViewer) Audience() []string {
+	return models.NormalizeAudience([]string{v.Class, v.Role})
+}
+
 // AnnouncementListResponse contains paginated announcements.
 type AnnouncementListResponse struct {
 	Items      []AnnouncementResponse `json:"items"`
@@ -50,12 +80,16 @@ type AnnouncementListResponse struct {
 
 // GalleryItemResponse represents an item in the gallery feed.
 type GalleryItemResponse struct {
-	ID        uint      `json:"id"`
-	Title     string    `json:"title"`
-	Caption   string    `json:"caption"`
-	ImageURL  string    `json:"image_url"`
-	Tags      []string  `json:"tags"`
-	CreatedAt time.Time `json:"created_at"`
+	ID           uint      `json:"id"`
+	Title        string    `json:"title"`
+	Caption      string    `json:"caption"`
+	ImageURL     string    `json:"image_url"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	Featured     bool      `json:"featured"`
+	Tags         []string  `json:"tags"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 // GalleryListResponse contains paginated gallery items.
@@ -84,14 +118,87 @@ type ContactResponse struct {
 
 // UploadResponse describes the stored asset metadata returned to the client.
 type UploadResponse struct {
-	URL       string `json:"url"`
-	SizeBytes int64  `json:"size_bytes"`
-	MimeType  string `json:"mime_type"`
-	Checksum  string `json:"checksum"`
-	FileName  string `json:"file_name"`
+	ID        uint      `json:"id"`
+	UserID    *uint     `json:"user_id,omitempty"`
+	URL       string    `json:"url"`
+	SizeBytes int64     `json:"size_bytes"`
+	MimeType  string    `json:"mime_type"`
+	Checksum  string    `json:"checksum"`
+	FileName  string    `json:"file_name"`
+	Width     int       `json:"width,omitempty"`
+	Height    int       `json:"height,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UploadListResponse wraps a page of uploads.
+type UploadListResponse struct {
+	Items      []UploadResponse `json:"items"`
+	Pagination PaginationMeta   `json:"pagination"`
+}
+
+// UploadSignRequest describes a file the client wants to send straight to
+// Cloudinary.
+type UploadSignRequest struct {
+	FileName  string `json:"file_name" validate:"required,max=255"`
+	SizeBytes int64  `json:"size_bytes" validate:"required,gt=0"`
+}
+
+// UploadSignResponse holds the form fields the browser posts to UploadURL
+// together with the file.
+type UploadSignResponse struct {
+	UploadURL string `json:"upload_url"`
+	CloudName string `json:"cloud_name"`
+	APIKey    string `json:"api_key"`
+	Timestamp int64  `json:"timestamp"`
+	Folder    string `json:"folder,omitempty"`
+	PublicID  string `json:"public_id"`
+	// AllowedFormats, MaxFileSize and Transformation are signed and must be
+	// posted as the allowed_formats, max_file_size and transformation form
+	// fields.
+	AllowedFormats string    `json:"allowed_formats,omitempty"`
+	MaxFileSize    int64     `json:"max_file_size"`
+	Transformation string    `json:"transformation,omitempty"`
+	Signature      string    `json:"signature"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	MaxBytes       int64     `json:"max_bytes"`
+}
+
+// UploadConfirmRequest echoes fields of Cloudinary's upload response. Only
+// public_id and version are covered by Cloudinary's signature, so the size,
+// format and dimensions are looked up from Cloudinary rather than taken from
+// the client.
+type UploadConfirmRequest struct {
+	PublicID         string `json:"public_id" validate:"required,max=255"`
+	Version          int64  `json:"version" validate:"required,gt=0"`
+	Signature        string `json:"signature" validate:"required,len=40,hexadecimal"`
+	ResourceType     string `json:"resource_type" validate:"required,oneof=image video raw"`
+	OriginalFilename string `json:"original_filename" validate:"omitempty,max=255"`
 }
 
 // SeedRequest contains optional overrides for seed operations.
 type SeedRequest struct {
 	Force bool `json:"force"`
 }
+
+// SeedResult reports what a seed run changed, or would change on a dry run,
+// for one content type. Items are matched by slug.
+type SeedResult struct {
+	ContentType string `json:"content_type"`
+	Created     int    `json:"created"`
+	Updated     int    `json:"updated"`
+	Affected    int64  `json:"affected"`
+	DryRun      bool   `json:"dry_run"`
+}
+
+// SeedContentStatus counts how many bundled items of a content type exist.
+type SeedContentStatus struct {
+	ContentType string `json:"content_type"`
+	Expected    int    `json:"expected"`
+	Present     int    `json:"present"`
+}
+
+// SeedStatus reports whether the bundled seed content has been applied.
+type SeedStatus struct {
+	Seeded       bool                `json:"seeded"`
+	ContentTypes []SeedContentStatus `json:"content_types"`
+}
@@ -14,6 +14,14 @@ type RoadmapStageResponse struct {
 	Tags           []string          `json:"tags"`
 	Skills         map[string]string `json:"skills"`
 	UpdatedAt      time.Time         `json:"updated_at"`
+	Completed      *bool             `json:"completed,omitempty"`
+	CompletedAt    *time.Time        `json:"completed_at,omitempty"`
+}
+
+// RoadmapProgressResponse reports a stage the student completed.
+type RoadmapProgressResponse struct {
+	StageID     uint      `json:"stage_id"`
+	CompletedAt time.Time `json:"completed_at"`
 }
 
 // RoadmapStageListResult wraps paginated roadmap stages.
@@ -31,11 +39,13 @@ type RoadmapStageFilters struct {
 	Sort   string   `json:"sort,omitempty"`
 }
 
-// RoadmapStageListRequest captures query params.
+// RoadmapStageListRequest captures query params. A non-zero StudentID adds
+// that student's completion status to each stage.
 type RoadmapStageListRequest struct {
-	Page     int
-	PageSize int
-	Sort     string
-	Search   string
-	Tags     []string
+	Page      int
+	PageSize  int
+	Sort      string
+	Search    string
+	Tags      []string
+	StudentID uint
 }
@@ -6,18 +6,36 @@ import "time"
 type StudentDashboardResponse struct {
 	Summary           ProgressSummary      `json:"summary"`
 	Pending           []AssignmentProgress `json:"pending_assignments"`
+	UpcomingDeadlines []AssignmentProgress `json:"upcoming_deadlines"`
+	Streak            SubmissionStreak     `json:"submission_streak"`
 	RecentSubmissions []SubmissionActivity `json:"recent_submissions"`
 }
 
+// StudentDashboardQuery controls how the pending assignment list is ordered
+// and grouped, and how far ahead upcoming deadlines reach.
+type StudentDashboardQuery struct {
+	Sort          string
+	Group         string
+	DueWithinDays int
+}
+
+// SubmissionStreak counts consecutive calendar days with at least one
+// submission. The current streak stays alive until a full day is missed.
+type SubmissionStreak struct {
+	CurrentDays int `json:"current_days"`
+	LongestDays int `json:"longest_days"`
+}
+
 // ProgressSummary captures aggregated statistics for the dashboard.
 type ProgressSummary struct {
-	TotalAssignments int     `json:"total_assignments"`
-	Submitted        int     `json:"submitted"`
-	Graded           int     `json:"graded"`
-	Pending          int     `json:"pending"`
-	Overdue          int     `json:"overdue"`
-	AverageGrade     float64 `json:"average_grade"`
-	CompletionRate   float64 `json:"completion_rate"`
+	TotalAssignments int      `json:"total_assignments"`
+	Submitted        int      `json:"submitted"`
+	Graded           int      `json:"graded"`
+	Pending          int      `json:"pending"`
+	Overdue          int      `json:"overdue"`
+	AverageGrade     float64  `json:"average_grade"`
+	AveragePercent   *float64 `json:"average_grade_percent,omitempty"`
+	CompletionRate   float64  `json:"completion_rate"`
 }
 
 // AssignmentProgress describes the state of a single assignment relative to a student.
@@ -30,6 +48,7 @@ type AssignmentProgress struct {
 	SubmissionID  *uint     `json:"submission_id"`
 	SubmissionURL string    `json:"submission_url"`
 	Grade         *float64  `json:"grade"`
+	GradePercent  *float64  `json:"grade_percent,omitempty"`
 	Feedback      string    `json:"feedback"`
 	UpdatedAt     time.Time `json:"updated_at"`
 	Overdue       bool      `json:"overdue"`
@@ -42,6 +61,7 @@ type SubmissionActivity struct {
 	AssignmentName string    `json:"assignment_name"`
 	Status         string    `json:"status"`
 	Grade          *float64  `json:"grade"`
+	GradePercent   *float64  `json:"grade_percent,omitempty"`
 	Feedback       string    `json:"feedback"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
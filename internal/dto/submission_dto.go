@@ -10,6 +10,8 @@ import (
 type SubmissionCreateRequest struct {
 	AssignmentID uint `form:"assignment_id" validate:"required,gt=0"`
 	StudentID    uint `form:"student_id" validate:"required,gt=0"`
+	// Role is the uploader's role, set by the handler to pick a size limit.
+	Role string `form:"-"`
 }
 
 // SubmissionUpdateRequest is used to grade or update a submission.
@@ -23,7 +25,7 @@ type SubmissionUpdateRequest struct {
 type SubmissionFilter struct {
 	AssignmentID *uint   `query:"assignment_id"`
 	StudentID    *uint   `query:"student_id"`
-	Status       *string `query:"status" validate:"omitempty,oneof=submitted graded"`
+	Status       *string `query:"status" validate:"omitempty,oneof=submitted graded late"`
 }
 
 // SubmissionResponse is returned to API clients when viewing submissions.
@@ -31,10 +33,17 @@ type SubmissionResponse struct {
 	ID           uint                             `json:"id"`
 	AssignmentID uint                             `json:"assignment_id"`
 	StudentID    uint                             `json:"student_id"`
-	FileURL      string                           `json:"file_url"`
+	Version      int                              `json:"version"`
+	FileURL      string                           `json:"file_url,omitempty"`
 	Status       string                           `json:"status"`
 	Grade        *float64                         `json:"grade"`
+	GradePercent *float64                         `json:"grade_percent,omitempty"`
+	FinalGrade   *float64                         `json:"final_grade"`
+	FinalPercent *float64                         `json:"final_grade_percent,omitempty"`
+	Late         bool                             `json:"late"`
+	LatePenalty  float64                          `json:"late_penalty_percent"`
 	Feedback     string                           `json:"feedback"`
+	RubricScores map[string]float64               `json:"rubric_scores,omitempty"`
 	GradedBy     *uint                            `json:"graded_by"`
 	GradedAt     *time.Time                       `json:"graded_at"`
 	History      []SubmissionGradeHistoryResponse `json:"history"`
@@ -44,6 +53,13 @@ type SubmissionResponse struct {
 	Student      StudentLite                      `json:"student"`
 }
 
+// SubmissionDownloadResponse points an authorised viewer at a submission
+// file. ExpiresAt is set when the URL is short-lived.
+type SubmissionDownloadResponse struct {
+	URL       string     `json:"url"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
 // AssignmentLite summarizes an assignment in submission responses.
 type AssignmentLite struct {
 	ID      uint      `json:"id"`
@@ -53,10 +69,30 @@ type AssignmentLite struct {
 
 // SubmissionGradeHistoryResponse serializes grading history entries.
 type SubmissionGradeHistoryResponse struct {
-	Score    float64   `json:"score"`
-	Feedback string    `json:"feedback"`
-	GradedBy uint      `json:"graded_by"`
-	GradedAt time.Time `json:"graded_at"`
+	ID            uint      `json:"id"`
+	PreviousScore *float64  `json:"previous_score"`
+	Score         float64   `json:"score"`
+	ScorePercent  *float64  `json:"score_percent,omitempty"`
+	Feedback      string    `json:"feedback"`
+	Reason        string    `json:"reason,omitempty"`
+	GradedBy      uint      `json:"graded_by"`
+	GradedAt      time.Time `json:"graded_at"`
+}
+
+// NewSubmissionGradeHistoryResponse converts a grading history entry into a
+// DTO. The percentage is omitted unless the assignment was loaded.
+func NewSubmissionGradeHistoryResponse(entry models.SubmissionGradeHistory, assignment models.Assignment) SubmissionGradeHistoryResponse {
+	score := entry.Score
+	return SubmissionGradeHistoryResponse{
+		ID:            entry.ID,
+		PreviousScore: entry.PreviousScore,
+		Score:         entry.Score,
+		ScorePercent:  assignment.ScorePercent(&score),
+		Feedback:      entry.Feedback,
+		Reason:        entry.Reason,
+		GradedBy:      entry.GradedBy,
+		GradedAt:      entry.GradedAt,
+	}
 }
 
 // StudentLite summarizes a student without exposing full profile data.
@@ -72,9 +108,13 @@ func NewSubmissionResponse(model models.Submission) SubmissionResponse {
 		ID:           model.ID,
 		AssignmentID: model.AssignmentID,
 		StudentID:    model.StudentID,
+		Version:      model.Version,
 		FileURL:      model.FileURL,
 		Status:       model.Status,
 		Grade:        model.Grade,
+		FinalGrade:   model.PenalizedGrade(),
+		Late:         model.Late,
+		LatePenalty:  model.LatePenaltyPercent,
 		Feedback:     model.Feedback,
 		GradedBy:     model.GradedBy,
 		GradedAt:     model.GradedAt,
@@ -82,12 +122,20 @@ func NewSubmissionResponse(model models.Submission) SubmissionResponse {
 		UpdatedAt:    model.UpdatedAt,
 	}
 
+	if len(model.RubricScores) > 0 {
+		response.RubricScores = FloatMapFromJSON(model.RubricScores)
+	}
+
+	// Percentages need the assignment's max score, so they are only filled
+	// in when the assignment was preloaded.
 	if model.Assignment.ID != 0 {
 		response.Assignment = AssignmentLite{
 			ID:      model.Assignment.ID,
 			Title:   model.Assignment.Title,
 			DueDate: model.Assignment.DueDate,
 		}
+		response.GradePercent = model.Assignment.ScorePercent(response.Grade)
+		response.FinalPercent = model.Assignment.ScorePercent(response.FinalGrade)
 	}
 
 	if model.Student.ID != 0 {
@@ -101,12 +149,7 @@ func NewSubmissionResponse(model models.Submission) SubmissionResponse {
 	if len(model.History) > 0 {
 		history := make([]SubmissionGradeHistoryResponse, 0, len(model.History))
 		for _, entry := range model.History {
-			history = append(history, SubmissionGradeHistoryResponse{
-				Score:    entry.Score,
-				Feedback: entry.Feedback,
-				GradedBy: entry.GradedBy,
-				GradedAt: entry.GradedAt,
-			})
+			history = append(history, NewSubmissionGradeHistoryResponse(entry, model.Assignment))
 		}
 		response.History = history
 	}
@@ -115,6 +158,16 @@ func NewSubmissionResponse(model models.Submission) SubmissionResponse {
 }
 
 // NewSubmissionResponseSlice converts submission models into DTOs.
+// VisibleTo returns the response as viewerID may see it: the stored file URL
+// is left out unless the viewer owns the submission. Staff fetch other
+// students' files through the download endpoint, which checks access.
+func (r SubmissionResponse) VisibleTo(viewerID uint) SubmissionResponse {
+	if viewerID == 0 || viewerID != r.StudentID {
+		r.FileURL = ""
+	}
+	return r
+}
+
 func NewSubmissionResponseSlice(models []models.Submission) []SubmissionResponse {
 	responses := make([]SubmissionResponse, 0, len(models))
 	for _, submission := range models {
@@ -123,3 +176,18 @@ func NewSubmissionResponseSlice(models []models.Submission) []SubmissionResponse
 
 	return responses
 }
+
+// SubmissionSimilarityMatch describes another submission that resembles the inspected one.
+type SubmissionSimilarityMatch struct {
+	SubmissionID uint    `json:"submission_id"`
+	StudentID    uint    `json:"student_id"`
+	Similarity   float64 `json:"similarity"`
+}
+
+// SubmissionSimilarityResponse ranks similar submissions for the same assignment.
+type SubmissionSimilarityResponse struct {
+	SubmissionID uint                        `json:"submission_id"`
+	AssignmentID uint                        `json:"assignment_id"`
+	Threshold    float64                     `json:"threshold"`
+	Matches      []SubmissionSimilarityMatch `json:"matches"`
+}
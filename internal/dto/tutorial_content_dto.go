@@ -64,6 +64,35 @@ type TutorialProjectCreateRequest struct {
 	Status         string   `json:"status" validate:"omitempty,oneof=draft published archived"`
 }
 
+// TutorialArticleUpdateRequest patches an article. Only the fields present
+// are changed; Reslug regenerates the slug from the (possibly new) title.
+type TutorialArticleUpdateRequest struct {
+	Title          *string   `json:"title" validate:"omitempty,min=3"`
+	Summary        *string   `json:"summary" validate:"omitempty,max=600"`
+	Content        *string   `json:"content" validate:"omitempty,min=20"`
+	Tags           *[]string `json:"tags" validate:"omitempty,dive,required"`
+	ThumbnailURL   *string   `json:"thumbnail_url" validate:"omitempty,url"`
+	Author         *string   `json:"author" validate:"omitempty,max=160"`
+	ReadingMinutes *int      `json:"reading_minutes" validate:"omitempty,gte=1,lte=300"`
+	Status         *string   `json:"status" validate:"omitempty,oneof=draft published archived"`
+	Reslug         bool      `json:"reslug"`
+}
+
+// TutorialProjectUpdateRequest patches a project. Only the fields present
+// are changed; Reslug regenerates the slug from the (possibly new) title.
+type TutorialProjectUpdateRequest struct {
+	Title          *string   `json:"title" validate:"omitempty,min=3"`
+	Summary        *string   `json:"summary" validate:"omitempty,max=600"`
+	Content        *string   `json:"content" validate:"omitempty,min=20"`
+	Difficulty     *string   `json:"difficulty" validate:"omitempty,oneof=beginner intermediate advanced"`
+	EstimatedHours *int      `json:"estimated_hours" validate:"omitempty,gte=1,lte=200"`
+	Tags           *[]string `json:"tags" validate:"omitempty,dive,required"`
+	RepoURL        *string   `json:"repo_url" validate:"omitempty,url"`
+	PreviewURL     *string   `json:"preview_url" validate:"omitempty,url"`
+	Status         *string   `json:"status" validate:"omitempty,oneof=draft published archived"`
+	Reslug         bool      `json:"reslug"`
+}
+
 // TutorialArticleListResult wraps article list data.
 type TutorialArticleListResult struct {
 	Items      []TutorialArticleResponse `json:"items"`
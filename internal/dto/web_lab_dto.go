@@ -13,8 +13,20 @@ type WebAssignmentResponse struct {
 	Requirements string    `json:"requirements"`
 	Assets       []string  `json:"assets"`
 	Rubric       string    `json:"rubric"`
+	HasReference bool      `json:"has_reference"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+	// Limits is filled in by the web lab service for the requesting user.
+	Limits *WebSubmissionLimits `json:"limits,omitempty"`
+}
+
+// WebSubmissionLimits lists the constraints a submitted archive must meet.
+// Scope names where MaxSizeMB came from: assignment, role or default.
+type WebSubmissionLimits struct {
+	MaxSizeMB  int    `json:"max_size_mb"`
+	Scope      string `json:"scope"`
+	MaxEntries int    `json:"max_entries"`
+	MaxFileMB  int    `json:"max_file_mb"`
 }
 
 // NewWebAssignmentResponse converts a model into a DTO.
@@ -25,6 +37,7 @@ func NewWebAssignmentResponse(model models.WebAssignment) WebAssignmentResponse
 		Requirements: model.Requirements,
 		Assets:       model.AssetList(),
 		Rubric:       model.Rubric,
+		HasReference: model.ReferenceZipURL != "",
 		CreatedAt:    model.CreatedAt,
 		UpdatedAt:    model.UpdatedAt,
 	}
@@ -40,10 +53,38 @@ func NewWebAssignmentResponseSlice(assignments []models.WebAssignment) []WebAssi
 	return responses
 }
 
+// WebAssignmentCreateRequest captures the payload for creating a web lab
+// assignment.
+type WebAssignmentCreateRequest struct {
+	Title        string   `json:"title" validate:"required,min=3"`
+	Requirements string   `json:"requirements"`
+	Assets       []string `json:"assets" validate:"omitempty,dive,required"`
+	Rubric       string   `json:"rubric"`
+	// MaxSubmissionMB overrides the global submission size limit; zero keeps the default.
+	MaxSubmissionMB int `json:"max_submission_mb" validate:"gte=0,lte=1024"`
+	// ReferenceZipURL points at a reference solution archive submissions are
+	// compared against. It can also be uploaded through the reference endpoint.
+	ReferenceZipURL string `json:"reference_zip_url" validate:"omitempty,url,startswith=http,max=512"`
+}
+
+// WebAssignmentUpdateRequest patches a web lab assignment. Nil fields are
+// left unchanged.
+type WebAssignmentUpdateRequest struct {
+	Title           *string  `json:"title" validate:"omitempty,min=3"`
+	Requirements    *string  `json:"requirements"`
+	Assets          []string `json:"assets" validate:"omitempty,dive,required"`
+	Rubric          *string  `json:"rubric"`
+	MaxSubmissionMB *int     `json:"max_submission_mb" validate:"omitempty,gte=0,lte=1024"`
+	// ReferenceZipURL replaces the reference solution; an empty string removes it.
+	ReferenceZipURL *string `json:"reference_zip_url" validate:"omitempty,max=512"`
+}
+
 // WebSubmissionCreateRequest captures the payload for creating a submission.
 type WebSubmissionCreateRequest struct {
 	AssignmentID uint `form:"assignment_id" json:"assignment_id" validate:"required"`
 	StudentID    uint `form:"student_id" json:"student_id" validate:"required"`
+	// Role is the uploader's role, set by the handler to pick a size limit.
+	Role string `form:"-" json:"-"`
 }
 
 // WebSubmissionResponse serializes a submission for API clients.
@@ -60,6 +101,13 @@ type WebSubmissionResponse struct {
 	Assignment   *WebAssignmentResponse `json:"assignment,omitempty"`
 }
 
+// WebSubmissionRegradeResponse reports a regraded submission along with the
+// score it had before.
+type WebSubmissionRegradeResponse struct {
+	Submission    WebSubmissionResponse `json:"submission"`
+	PreviousScore *float64              `json:"previous_score"`
+}
+
 // NewWebSubmissionResponse converts a submission model into a DTO.
 func NewWebSubmissionResponse(model models.WebSubmission) WebSubmissionResponse {
 	response := WebSubmissionResponse{
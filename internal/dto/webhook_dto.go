@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/noah-isme/gema-go-api/internal/models"
+)
+
+// WebhookSubscriptionRequest registers a URL for one event type. Secret is
+// the shared key deliveries are signed with.
+type WebhookSubscriptionRequest struct {
+	EventType string `json:"event_type" validate:"required,oneof=submission.graded assignment.created contact.submitted"`
+	URL       string `json:"url" validate:"required,url,startswith=http,max=512"`
+	Secret    string `json:"secret" validate:"required,min=16,max=128"`
+}
+
+// WebhookSubscriptionResponse serialises a subscription without its secret.
+type WebhookSubscriptionResponse struct {
+	ID        uint      `json:"id"`
+	EventType string    `json:"event_type"`
+	URL       string    `json:"url"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDeadLetterResponse serialises a delivery that failed every attempt.
+type WebhookDeadLetterResponse struct {
+	ID             uint            `json:"id"`
+	SubscriptionID uint            `json:"subscription_id"`
+	DeliveryID     string          `json:"delivery_id"`
+	EventType      string          `json:"event_type"`
+	URL            string          `json:"url"`
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// WebhookDeadLetterListResponse wraps paginated dead letters.
+type WebhookDeadLetterListResponse struct {
+	Items      []WebhookDeadLetterResponse `json:"items"`
+	Pagination PaginationMeta              `json:"pagination"`
+}
+
+// NewWebhookSubscriptionResponse converts a model into a subscription DTO.
+func NewWebhookSubscriptionResponse(subscription models.WebhookSubscription) WebhookSubscriptionResponse {
+	return WebhookSubscriptionResponse{
+		ID:        subscription.ID,
+		EventType: subscription.EventType,
+		URL:       subscription.URL,
+		CreatedBy: subscription.CreatedBy,
+		CreatedAt: subscription.CreatedAt,
+	}
+}
+
+// NewWebhookDeadLetterResponse converts a model into a dead letter DTO.
+func NewWebhookDeadLetterResponse(letter models.WebhookDeadLetter) WebhookDeadLetterResponse {
+	return WebhookDeadLetterResponse{
+		ID:             letter.ID,
+		SubscriptionID: letter.SubscriptionID,
+		DeliveryID:     letter.DeliveryID,
+		EventType:      letter.EventType,
+		URL:            letter.URL,
+		Payload:        json.RawMessage(letter.Payload),
+		Attempts:       letter.Attempts,
+		LastError:      letter.LastError,
+		CreatedAt:      letter.CreatedAt,
+	}
+}
@@ -0,0 +1,127 @@
+package featureflags
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Flag names an experimental code path that can be enabled per request.
+type Flag string
+
+// AsyncCodingExecution stores coding lab submissions as pending and runs them
+// in the background instead of within the request.
+const AsyncCodingExecution Flag = "async_coding_execution"
+
+// known lists the flags that may be toggled by a request token. Anything else is ignored.
+var known = map[Flag]struct{}{
+	AsyncCodingExecution: {},
+}
+
+// Known returns the toggleable flags in name order.
+func Known() []Flag {
+	flags := make([]Flag, 0, len(known))
+	for flag := range known {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i] < flags[j] })
+	return flags
+}
+
+// ErrInvalidToken indicates the feature token is malformed, expired or carries a bad signature.
+var ErrInvalidToken = errors.New("invalid feature token")
+
+// Set is the collection of flags enabled for a request.
+type Set map[Flag]struct{}
+
+// Has reports whether the flag is part of the set.
+func (s Set) Has(flag Flag) bool {
+	_, ok := s[flag]
+	return ok
+}
+
+type contextKey struct{}
+
+// ContextKey is the key flags are stored under. Fiber locals share the key so
+// services receiving c.Context() resolve the same set as c.UserContext().
+var ContextKey = contextKey{}
+
+// WithFlags returns a context carrying the provided flag set.
+func WithFlags(ctx context.Context, set Set) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, ContextKey, set)
+}
+
+// FromContext returns the flag set bound to the context, if any.
+func FromContext(ctx context.Context) Set {
+	if ctx == nil {
+		return nil
+	}
+	if set, ok := ctx.Value(ContextKey).(Set); ok {
+		return set
+	}
+	return nil
+}
+
+// Enabled reports whether the flag is switched on for the request carried by ctx.
+func Enabled(ctx context.Context, flag Flag) bool {
+	return FromContext(ctx).Has(flag)
+}
+
+// Sign issues a token of the form "<flags>.<expiry>.<signature>" enabling the given flags until expiresAt.
+func Sign(secret string, flags []Flag, expiresAt time.Time) string {
+	names := make([]string, 0, len(flags))
+	for _, flag := range flags {
+		names = append(names, string(flag))
+	}
+	sort.Strings(names)
+
+	payload := strings.Join(names, ",") + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + signature(secret, payload)
+}
+
+// Verify validates the token and returns the allowlisted flags it enables.
+func Verify(secret, token string, now time.Time) (Set, error) {
+	if strings.TrimSpace(secret) == "" {
+		return nil, ErrInvalidToken
+	}
+
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signature(secret, payload))) {
+		return nil, ErrInvalidToken
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return nil, ErrInvalidToken
+	}
+
+	set := make(Set)
+	for _, name := range strings.Split(parts[0], ",") {
+		flag := Flag(strings.TrimSpace(name))
+		if _, ok := known[flag]; ok {
+			set[flag] = struct{}{}
+		}
+	}
+
+	return set, nil
+}
+
+func signature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
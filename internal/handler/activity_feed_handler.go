@@ -1,16 +1,25 @@
 package handler
 
 import (
+	"bufio"
+	"encoding/json"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/observability"
 	"github.com/noah-isme/gema-go-api/internal/service"
 	"github.com/noah-isme/gema-go-api/internal/utils"
 )
 
+// activityStreamKeepAlive is how often an idle activity stream sends a
+// comment so proxies keep the connection open.
+const activityStreamKeepAlive = 15 * time.Second
+
 // ActivityFeedHandler serves the public activity endpoints.
 type ActivityFeedHandler struct {
 	service service.ActivityFeedService
@@ -25,9 +34,11 @@ func NewActivityFeedHandler(service service.ActivityFeedService, logger zerolog.
 	}
 }
 
-// Register wires the activity feed routes.
-func (h *ActivityFeedHandler) Register(router fiber.Router) {
+// Register wires the activity feed routes. requireStaff guards the live
+// stream, which carries every new audit entry.
+func (h *ActivityFeedHandler) Register(router fiber.Router, requireStaff ...fiber.Handler) {
 	router.Get("/active", h.active)
+	router.Get("/stream", append(requireStaff, h.stream)...)
 }
 
 func (h *ActivityFeedHandler) active(c *fiber.Ctx) error {
@@ -45,20 +56,12 @@ func (h *ActivityFeedHandler) active(c *fiber.Ctx) error {
 	if pageSize <= 0 {
 		pageSize = 20
 	}
-	var userID *uint
-	if v := c.Query("userId"); v != "" {
-		if parsed, err := strconv.ParseUint(v, 10, 64); err == nil {
-			val := uint(parsed)
-			userID = &val
-		}
-	}
-	req := dto.ActivityFeedRequest{
-		Page:     page,
-		PageSize: pageSize,
-		UserID:   userID,
-		Type:     c.Query("type"),
-		Action:   c.Query("action"),
+	req, err := parseActivityFeedFilters(c)
+	if err != nil {
+		return sendRequestError(c, err)
 	}
+	req.Page = page
+	req.PageSize = pageSize
 
 	result, err := h.service.ListActive(c.Context(), req)
 	if err != nil {
@@ -74,3 +77,78 @@ func (h *ActivityFeedHandler) active(c *fiber.Ctx) error {
 
 	return utils.SendSuccess(c, "active activities retrieved", result)
 }
+
+// stream pushes activity entries as they are recorded, narrowed by the same
+// filters as the feed. It closes with a reconnect event on shutdown.
+func (h *ActivityFeedHandler) stream(c *fiber.Ctx) error {
+	req, err := parseActivityFeedFilters(c)
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	stream, cleanup := h.service.Stream(req)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cleanup()
+
+		ticker := time.NewTicker(activityStreamKeepAlive)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case item, ok := <-stream:
+				if !ok {
+					_ = writeReconnectEvent(w)
+					return
+				}
+				if err := writeActivityEvent(w, item); err != nil {
+					observability.RealtimeErrorsTotal().WithLabelValues("activities", "write").Inc()
+					h.logger.Debug().Err(err).Msg("failed to write activity event")
+					return
+				}
+			case <-ticker.C:
+				if err := writeKeepAlive(w); err != nil {
+					observability.RealtimeErrorsTotal().WithLabelValues("activities", "keepalive").Inc()
+					h.logger.Debug().Err(err).Msg("failed to write activity keepalive")
+					return
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+// parseActivityFeedFilters reads the actor, entity type and action filters.
+// actorId and entityType are accepted alongside the original userId and type.
+func parseActivityFeedFilters(c *fiber.Ctx) (dto.ActivityFeedRequest, error) {
+	req := dto.ActivityFeedRequest{
+		Type:   c.Query("entityType", c.Query("type")),
+		Action: c.Query("action"),
+	}
+	if v := c.Query("actorId", c.Query("userId")); v != "" {
+		parsed, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return dto.ActivityFeedRequest{}, fmt.Errorf("invalid actor id")
+		}
+		actorID := uint(parsed)
+		req.UserID = &actorID
+	}
+	return req, nil
+}
+
+func writeActivityEvent(w *bufio.Writer, item dto.ActivityFeedItem) error {
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: activity\nid: %d\ndata: %s\n\n", item.ID, payload); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"bufio"
+	"errors"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/middleware"
 	"github.com/noah-isme/gema-go-api/internal/service"
 	"github.com/noah-isme/gema-go-api/internal/utils"
 )
@@ -27,6 +32,8 @@ func NewAdminActivityHandler(service service.ActivityService, logger zerolog.Log
 func (h *AdminActivityHandler) Register(router fiber.Router) {
 	router.Get("", h.list)
 	router.Post("", h.create)
+	router.Delete("", middleware.RequireRole("admin"), h.prune)
+	router.Get("/export", h.export)
 }
 
 func (h *AdminActivityHandler) list(c *fiber.Ctx) error {
@@ -82,7 +89,7 @@ func (h *AdminActivityHandler) create(c *fiber.Ctx) error {
 	entry, err := h.service.Create(c.Context(), actor, payload)
 	if err != nil {
 		if isValidationError(err) {
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+			return sendValidationError(c, err)
 		}
 		requestLogger(h.logger, c).Error().Err(err).Msg("failed to create activity log")
 		return utils.SendError(c, fiber.StatusInternalServerError, "failed to create activity log")
@@ -90,3 +97,56 @@ func (h *AdminActivityHandler) create(c *fiber.Ctx) error {
 
 	return utils.SendSuccessWithStatus(c, fiber.StatusCreated, "activity log created", entry)
 }
+
+// prune deletes entries created before the required older_than timestamp.
+// Admin only.
+func (h *AdminActivityHandler) prune(c *fiber.Ctx) error {
+	olderThan, err := optionalTimeQuery(c, "older_than")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+	if olderThan == nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "older_than required")
+	}
+
+	removed, err := h.service.Prune(c.Context(), *olderThan, activityActorFromContext(c))
+	if err != nil {
+		if errors.Is(err, service.ErrActivityPruneCutoff) {
+			return sendServiceError(c, h.logger, err)
+		}
+		requestLogger(h.logger, c).Error().Err(err).Int64("removed", removed).Msg("failed to prune activity logs")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to prune activity logs")
+	}
+
+	return utils.SendSuccess(c, "activity logs pruned", fiber.Map{"removed": removed, "older_than": olderThan.UTC()})
+}
+
+// export streams entries as newline-delimited JSON. The body is written after
+// the handler returns, so failures can only truncate the download.
+func (h *AdminActivityHandler) export(c *fiber.Ctx) error {
+	from, err := optionalTimeQuery(c, "from")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+	to, err := optionalTimeQuery(c, "to")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+	if from != nil && to != nil && from.After(*to) {
+		return utils.SendError(c, fiber.StatusBadRequest, "from must not be after to")
+	}
+
+	ctx := c.UserContext()
+	actor := activityActorFromContext(c)
+	logger := requestLogger(h.logger, c)
+	query := dto.AdminActivityExportQuery{From: from, To: to}
+
+	c.Attachment("activities-" + time.Now().UTC().Format("20060102T150405Z") + ".ndjson")
+	c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if rows, err := h.service.Export(ctx, w, query, actor); err != nil {
+			logger.Error().Err(err).Int("rows", rows).Msg("activity export stream failed")
+		}
+	})
+	return nil
+}
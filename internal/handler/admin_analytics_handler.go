@@ -1,9 +1,16 @@
 package handler
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 
+	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/service"
 	"github.com/noah-isme/gema-go-api/internal/utils"
 )
@@ -25,10 +32,17 @@ func NewAdminAnalyticsHandler(service service.AdminAnalyticsService, logger zero
 // Register attaches analytics routes to the router group.
 func (h *AdminAnalyticsHandler) Register(router fiber.Router) {
 	router.Get("", h.get)
+	router.Get("/export", h.export)
+	router.Get("/assignments/:id", h.assignment)
 }
 
 func (h *AdminAnalyticsHandler) get(c *fiber.Ctx) error {
-	summary, err := h.service.GetSummary(c.Context())
+	query, err := analyticsQuery(c)
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	summary, err := h.service.GetSummary(c.Context(), query)
 	if err != nil {
 		requestLogger(h.logger, c).Error().Err(err).Msg("failed to fetch analytics summary")
 		return utils.SendError(c, fiber.StatusInternalServerError, "failed to load analytics")
@@ -36,3 +50,71 @@ func (h *AdminAnalyticsHandler) get(c *fiber.Ctx) error {
 
 	return utils.SendSuccess(c, "analytics summary", summary)
 }
+
+func (h *AdminAnalyticsHandler) export(c *fiber.Ctx) error {
+	query, err := analyticsQuery(c)
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.Query("format", service.AnalyticsExportCSV)))
+	report, err := h.service.Export(c.Context(), activityActorFromContext(c), format, query)
+	if err != nil {
+		if errors.Is(err, service.ErrAnalyticsExportFormat) {
+			return utils.SendError(c, fiber.StatusBadRequest, "format must be csv or json")
+		}
+		requestLogger(h.logger, c).Error().Err(err).Msg("failed to export analytics")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to export analytics")
+	}
+
+	c.Attachment(report.Filename)
+	c.Set(fiber.HeaderContentType, report.ContentType)
+	return c.SendStream(bytes.NewReader(report.Data), len(report.Data))
+}
+
+func (h *AdminAnalyticsHandler) assignment(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	analytics, err := h.service.AssignmentAnalytics(c.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrAssignmentNotFound) {
+			return utils.SendError(c, fiber.StatusNotFound, "assignment not found")
+		}
+		requestLogger(h.logger, c).Error().Err(err).Uint("assignment_id", id).Msg("failed to fetch assignment analytics")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to load assignment analytics")
+	}
+
+	return utils.SendSuccess(c, "assignment analytics", analytics)
+}
+
+// analyticsQuery reads the optional RFC3339 from/to range and checks that
+// from is not after to.
+func analyticsQuery(c *fiber.Ctx) (dto.AdminAnalyticsQuery, error) {
+	from, err := optionalTimeQuery(c, "from")
+	if err != nil {
+		return dto.AdminAnalyticsQuery{}, err
+	}
+	to, err := optionalTimeQuery(c, "to")
+	if err != nil {
+		return dto.AdminAnalyticsQuery{}, err
+	}
+	if from != nil && to != nil && from.After(*to) {
+		return dto.AdminAnalyticsQuery{}, service.ErrAnalyticsInvalidRange
+	}
+	return dto.AdminAnalyticsQuery{From: from, To: to}, nil
+}
+
+func optionalTimeQuery(c *fiber.Ctx, name string) (*time.Time, error) {
+	raw := strings.TrimSpace(c.Query(name))
+	if raw == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+	}
+	return &parsed, nil
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 
@@ -27,6 +29,7 @@ func NewAdminAnnouncementHandler(service service.AdminAnnouncementService, logge
 func (h *AdminAnnouncementHandler) Register(router fiber.Router) {
 	router.Get("", h.list)
 	router.Post("", h.create)
+	router.Patch("/:id", h.update)
 }
 
 func (h *AdminAnnouncementHandler) list(c *fiber.Ctx) error {
@@ -48,15 +51,19 @@ func (h *AdminAnnouncementHandler) list(c *fiber.Ctx) error {
 		Page:     page,
 		PageSize: pageSize,
 		Search:   c.Query("search"),
+		Status:   c.Query("status"),
 	}
 
 	result, err := h.service.List(c.Context(), req)
 	if err != nil {
+		if isValidationError(err) {
+			return sendValidationError(c, err)
+		}
 		h.logger.Error().Err(err).Msg("failed to list announcements")
 		return utils.SendError(c, fiber.StatusInternalServerError, "failed to list announcements")
 	}
 
-	meta := fiber.Map{"pagination": result.Pagination, "filters": fiber.Map{"search": req.Search}}
+	meta := fiber.Map{"pagination": result.Pagination, "filters": fiber.Map{"search": req.Search, "status": req.Status}}
 	return utils.OK(c, result.Items, "announcements retrieved", meta)
 }
 
@@ -70,7 +77,10 @@ func (h *AdminAnnouncementHandler) create(c *fiber.Ctx) error {
 	announcement, err := h.service.Create(c.Context(), payload, actor)
 	if err != nil {
 		if isValidationError(err) {
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+			return sendValidationError(c, err)
+		}
+		if errors.Is(err, service.ErrAnnouncementInvalidSchedule) {
+			return sendServiceError(c, h.logger, err)
 		}
 		h.logger.Error().Err(err).Msg("failed to create announcement")
 		return utils.SendError(c, fiber.StatusInternalServerError, "failed to create announcement")
@@ -78,3 +88,32 @@ func (h *AdminAnnouncementHandler) create(c *fiber.Ctx) error {
 
 	return utils.SendSuccessWithStatus(c, fiber.StatusCreated, "announcement created", announcement)
 }
+
+func (h *AdminAnnouncementHandler) update(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid identifier")
+	}
+
+	var payload dto.AdminAnnouncementUpdateRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	}
+
+	announcement, err := h.service.Update(c.Context(), id, payload, activityActorFromContext(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAdminAnnouncementNotFound):
+			return utils.SendError(c, fiber.StatusNotFound, "announcement not found")
+		case isValidationError(err):
+			return sendValidationError(c, err)
+		case errors.Is(err, service.ErrAnnouncementInvalidSchedule):
+			return sendServiceError(c, h.logger, err)
+		default:
+			requestLogger(h.logger, c).Error().Err(err).Uint("announcement_id", id).Msg("failed to update announcement")
+			return utils.SendError(c, fiber.StatusInternalServerError, "failed to update announcement")
+		}
+	}
+
+	return utils.SendSuccess(c, "announcement updated", announcement)
+}
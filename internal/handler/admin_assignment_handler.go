@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
@@ -28,6 +29,7 @@ func NewAdminAssignmentHandler(service service.AdminAssignmentService, logger ze
 // Register attaches assignment admin routes to the router group.
 func (h *AdminAssignmentHandler) Register(router fiber.Router) {
 	router.Post("", h.create)
+	router.Post("/import", h.importCSV)
 	router.Patch("/:id", h.update)
 	router.Delete("/:id", h.delete)
 	router.Get("/:id", h.get)
@@ -43,10 +45,10 @@ func (h *AdminAssignmentHandler) create(c *fiber.Ctx) error {
 	assignment, err := h.service.Create(c.Context(), payload, actor)
 	if err != nil {
 		switch {
-		case errors.Is(err, service.ErrAdminAssignmentInvalidDueDate):
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrAdminAssignmentInvalidDueDate), errors.Is(err, service.ErrAssignmentSizeLimitTooHigh):
+			return sendServiceError(c, h.logger, err)
 		case isValidationError(err):
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+			return sendValidationError(c, err)
 		default:
 			requestLogger(h.logger, c).Error().Err(err).Msg("failed to create assignment")
 			return utils.SendError(c, fiber.StatusInternalServerError, "failed to create assignment")
@@ -56,6 +58,40 @@ func (h *AdminAssignmentHandler) create(c *fiber.Ctx) error {
 	return utils.SendSuccessWithStatus(c, fiber.StatusCreated, "assignment created", assignment)
 }
 
+func (h *AdminAssignmentHandler) importCSV(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "file is required")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "unable to read file")
+	}
+	defer file.Close()
+
+	strict := strings.EqualFold(c.Query("strict", c.FormValue("strict")), "true")
+	actor := activityActorFromContext(c)
+	report, err := h.service.ImportCSV(c.Context(), file, strict, actor)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAdminAssignmentImportRejected):
+			return utils.SendAPIError(c, utils.APIError{Status: fiber.StatusUnprocessableEntity, Code: "IMPORT_REJECTED", Message: err.Error(), Details: report})
+		case errors.Is(err, service.ErrAdminAssignmentImportInvalid):
+			return sendServiceError(c, h.logger, err)
+		default:
+			requestLogger(h.logger, c).Error().Err(err).Msg("failed to import assignments")
+			return utils.SendError(c, fiber.StatusInternalServerError, "failed to import assignments")
+		}
+	}
+
+	status := fiber.StatusCreated
+	if report.Created == 0 {
+		status = fiber.StatusOK
+	}
+	return utils.SendSuccessWithStatus(c, status, "assignments imported", report)
+}
+
 func (h *AdminAssignmentHandler) update(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
@@ -73,10 +109,10 @@ func (h *AdminAssignmentHandler) update(c *fiber.Ctx) error {
 		switch {
 		case errors.Is(err, service.ErrAdminAssignmentNotFound):
 			return utils.SendError(c, fiber.StatusNotFound, "assignment not found")
-		case errors.Is(err, service.ErrAdminAssignmentInvalidDueDate):
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrAdminAssignmentInvalidDueDate), errors.Is(err, service.ErrAssignmentSizeLimitTooHigh):
+			return sendServiceError(c, h.logger, err)
 		case isValidationError(err):
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+			return sendValidationError(c, err)
 		default:
 			requestLogger(h.logger, c).Error().Err(err).Msg("failed to update assignment")
 			return utils.SendError(c, fiber.StatusInternalServerError, "failed to update assignment")
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/noah-isme/gema-go-api/internal/config"
+	"github.com/noah-isme/gema-go-api/internal/utils"
+)
+
+// AdminConfig returns a handler exposing the sanitized running configuration.
+func AdminConfig(cfg config.Config) fiber.Handler {
+	view := cfg.Sanitized()
+	return func(c *fiber.Ctx) error {
+		return utils.SendSuccess(c, "configuration retrieved", view)
+	}
+}
+
+// AdminLiveConfig exposes the sanitized configuration currently served by
+// provider, including values changed by a reload.
+func AdminLiveConfig(provider *config.Provider) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return utils.SendSuccess(c, "configuration retrieved", provider.Current().Sanitized())
+	}
+}
+
+// AdminConfigReload reloads the hot-reloadable configuration fields, the same
+// as sending the process SIGHUP. An invalid configuration is rejected and the
+// running values are kept.
+func AdminConfigReload(provider *config.Provider) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		changed, err := provider.Reload()
+		if err != nil {
+			return utils.SendAPIError(c, utils.APIError{Status: fiber.StatusUnprocessableEntity, Code: "CONFIG_INVALID", Message: err.Error()})
+		}
+		return utils.SendSuccess(c, "configuration reloaded", fiber.Map{
+			"changed":    changed,
+			"reloadable": config.ReloadableFields(),
+			"config":     provider.Current().Sanitized(),
+		})
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
@@ -29,6 +30,7 @@ func NewAdminContactHandler(service service.AdminContactService, logger zerolog.
 func (h *AdminContactHandler) Register(router fiber.Router) {
 	router.Get("", h.list)
 	router.Get("/:id", h.get)
+	router.Patch("/:id/status", h.updateStatus)
 }
 
 func (h *AdminContactHandler) list(c *fiber.Ctx) error {
@@ -53,6 +55,11 @@ func (h *AdminContactHandler) list(c *fiber.Ctx) error {
 		Search:   c.Query("search"),
 		Sort:     c.Query("sort"),
 	}
+	if raw := c.Query("reveal_email"); raw != "" {
+		if req.RevealEmail, err = strconv.ParseBool(raw); err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "invalid reveal_email flag")
+		}
+	}
 
 	result, err := h.service.List(c.Context(), req)
 	if err != nil {
@@ -75,7 +82,7 @@ func (h *AdminContactHandler) list(c *fiber.Ctx) error {
 func (h *AdminContactHandler) get(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	submission, err := h.service.Get(c.Context(), id)
@@ -89,3 +96,32 @@ func (h *AdminContactHandler) get(c *fiber.Ctx) error {
 
 	return utils.OK(c, submission, "contact submission retrieved", nil)
 }
+
+func (h *AdminContactHandler) updateStatus(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	var payload dto.AdminContactStatusRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	}
+
+	submission, err := h.service.UpdateStatus(c.Context(), id, payload, activityActorFromContext(c))
+	if err != nil {
+		switch {
+		case isValidationError(err):
+			return sendValidationError(c, err)
+		case errors.Is(err, service.ErrAdminContactNotFound):
+			return utils.SendError(c, fiber.StatusNotFound, "contact submission not found")
+		case errors.Is(err, service.ErrContactStatusTransition):
+			return sendServiceError(c, h.logger, err)
+		default:
+			h.logger.Error().Err(err).Uint("contact_id", id).Msg("failed to update contact status")
+			return utils.SendError(c, fiber.StatusInternalServerError, "failed to update contact status")
+		}
+	}
+
+	return utils.SendSuccess(c, "contact status updated", submission)
+}
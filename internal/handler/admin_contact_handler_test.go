@@ -1,11 +1,14 @@
 package handler_test
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
@@ -34,7 +37,7 @@ func TestAdminContactHandler_List(t *testing.T) {
 	require.NoError(t, db.Create(&item).Error)
 
 	repo := repository.NewContactRepository(db)
-	svc := service.NewAdminContactService(repo, zerolog.Nop())
+	svc := service.NewAdminContactService(repo, validator.New(), nil, zerolog.Nop())
 	h := handler.NewAdminContactHandler(svc, zerolog.Nop())
 
 	app := fiber.New()
@@ -45,3 +48,29 @@ func TestAdminContactHandler_List(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 }
+
+func TestAdminContactHandler_UpdateStatus(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:admin_contact_handler_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.ContactSubmission{}))
+
+	item := models.ContactSubmission{ReferenceID: "status", Name: "Rina", Email: "rina@example.com", Message: "Need help", Status: models.ContactStatusSpam}
+	require.NoError(t, db.Create(&item).Error)
+
+	svc := service.NewAdminContactService(repository.NewContactRepository(db), validator.New(), nil, zerolog.Nop())
+	app := fiber.New()
+	handler.NewAdminContactHandler(svc, zerolog.Nop()).Register(app.Group("/api/admin/contacts"))
+
+	patch := func(status string) int {
+		req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/api/admin/contacts/%d/status", item.ID), strings.NewReader(`{"status":"`+status+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusConflict, patch("resolved"))
+	require.Equal(t, http.StatusBadRequest, patch("archived"))
+	require.Equal(t, http.StatusOK, patch("read"))
+	require.Equal(t, http.StatusOK, patch("resolved"))
+}
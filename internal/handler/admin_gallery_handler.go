@@ -2,6 +2,8 @@ package handler
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
@@ -29,6 +31,7 @@ func NewAdminGalleryHandler(service service.AdminGalleryService, logger zerolog.
 func (h *AdminGalleryHandler) Register(router fiber.Router) {
 	router.Get("", h.list)
 	router.Post("", h.create)
+	router.Patch("/reorder", h.reorder)
 	router.Patch("/:id", h.update)
 	router.Delete("/:id", h.delete)
 }
@@ -73,6 +76,10 @@ func (h *AdminGalleryHandler) list(c *fiber.Ctx) error {
 }
 
 func (h *AdminGalleryHandler) create(c *fiber.Ctx) error {
+	if strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEMultipartForm) {
+		return h.upload(c)
+	}
+
 	var payload dto.AdminGalleryRequest
 	if err := c.BodyParser(&payload); err != nil {
 		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
@@ -82,7 +89,7 @@ func (h *AdminGalleryHandler) create(c *fiber.Ctx) error {
 	item, err := h.service.Create(c.Context(), payload, actor)
 	if err != nil {
 		if isValidationError(err) {
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+			return sendValidationError(c, err)
 		}
 		h.logger.Error().Err(err).Msg("failed to create gallery item")
 		return utils.SendError(c, fiber.StatusInternalServerError, "failed to create gallery item")
@@ -91,6 +98,52 @@ func (h *AdminGalleryHandler) create(c *fiber.Ctx) error {
 	return utils.SendSuccessWithStatus(c, fiber.StatusCreated, "gallery item created", item)
 }
 
+// upload handles multipart creates carrying the image file itself.
+func (h *AdminGalleryHandler) upload(c *fiber.Ctx) error {
+	file, err := c.FormFile("image")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "image is required")
+	}
+
+	payload := dto.AdminGalleryUploadRequest{
+		Title:   c.FormValue("title"),
+		Caption: c.FormValue("caption"),
+		Tags:    splitAndTrim(c.FormValue("tags")),
+	}
+	if raw := c.FormValue("featured"); raw != "" {
+		if payload.Featured, err = strconv.ParseBool(raw); err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "invalid featured flag")
+		}
+	}
+	if raw := c.FormValue("sort_order"); raw != "" {
+		if payload.SortOrder, err = strconv.Atoi(raw); err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "invalid sort order")
+		}
+	}
+
+	actor := activityActorFromContext(c)
+	item, err := h.service.CreateItem(c.Context(), payload, file, actor)
+	if err != nil {
+		var typeErr *service.FileTypeError
+		var sizeErr *service.SizeLimitError
+		switch {
+		case isValidationError(err):
+			return sendValidationError(c, err)
+		case errors.As(err, &typeErr):
+			return utils.Fail(c, fiber.StatusBadRequest, service.ErrUploadTypeNotAllowed.Error(), fileTypeDetails(typeErr))
+		case errors.As(err, &sizeErr):
+			return utils.Fail(c, fiber.StatusRequestEntityTooLarge, sizeErr.Error(), sizeLimitDetails(sizeErr))
+		case errors.Is(err, service.ErrGalleryImageUnreadable):
+			return sendServiceError(c, h.logger, err)
+		default:
+			h.logger.Error().Err(err).Msg("failed to upload gallery item")
+			return utils.SendError(c, fiber.StatusInternalServerError, "failed to create gallery item")
+		}
+	}
+
+	return utils.SendSuccessWithStatus(c, fiber.StatusCreated, "gallery item created", item)
+}
+
 func (h *AdminGalleryHandler) update(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
@@ -106,7 +159,7 @@ func (h *AdminGalleryHandler) update(c *fiber.Ctx) error {
 	if err != nil {
 		switch {
 		case isValidationError(err):
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+			return sendValidationError(c, err)
 		case errors.Is(err, service.ErrAdminGalleryNotFound):
 			return utils.SendError(c, fiber.StatusNotFound, "gallery item not found")
 		default:
@@ -118,6 +171,28 @@ func (h *AdminGalleryHandler) update(c *fiber.Ctx) error {
 	return utils.SendSuccess(c, "gallery item updated", item)
 }
 
+func (h *AdminGalleryHandler) reorder(c *fiber.Ctx) error {
+	var payload dto.AdminGalleryReorderRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	}
+	actor := activityActorFromContext(c)
+
+	if err := h.service.Reorder(c.Context(), payload, actor); err != nil {
+		switch {
+		case isValidationError(err):
+			return sendValidationError(c, err)
+		case errors.Is(err, service.ErrAdminGalleryNotFound):
+			return utils.SendError(c, fiber.StatusNotFound, "gallery item not found")
+		default:
+			h.logger.Error().Err(err).Msg("failed to reorder gallery items")
+			return utils.SendError(c, fiber.StatusInternalServerError, "failed to reorder gallery items")
+		}
+	}
+
+	return utils.SendSuccess(c, "gallery items reordered", fiber.Map{"ids": payload.IDs})
+}
+
 func (h *AdminGalleryHandler) delete(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
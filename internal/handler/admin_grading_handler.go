@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"bufio"
 	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
@@ -27,7 +30,10 @@ func NewAdminGradingHandler(service service.AdminGradingService, logger zerolog.
 
 // Register attaches grading endpoints to the router group.
 func (h *AdminGradingHandler) Register(router fiber.Router) {
+	router.Get("/export", h.export)
+	router.Post("/next", h.next)
 	router.Patch("/:id/grade", h.grade)
+	router.Get("/:id/grade-history", h.history)
 }
 
 func (h *AdminGradingHandler) grade(c *fiber.Ctx) error {
@@ -47,10 +53,10 @@ func (h *AdminGradingHandler) grade(c *fiber.Ctx) error {
 		switch {
 		case errors.Is(err, service.ErrAdminSubmissionNotFound):
 			return utils.SendError(c, fiber.StatusNotFound, "submission not found")
-		case errors.Is(err, service.ErrScoreExceedsMax):
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrScoreExceedsMax), errors.Is(err, service.ErrRubricScoresInvalid), errors.Is(err, service.ErrGradeReasonRequired):
+			return sendServiceError(c, h.logger, err)
 		case isValidationError(err):
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+			return sendValidationError(c, err)
 		default:
 			requestLogger(h.logger, c).Error().Err(err).Uint("submission_id", id).Msg("failed to grade submission")
 			return utils.SendError(c, fiber.StatusInternalServerError, "failed to grade submission")
@@ -59,3 +65,82 @@ func (h *AdminGradingHandler) grade(c *fiber.Ctx) error {
 
 	return utils.SendSuccess(c, "submission graded", submission)
 }
+
+// history returns the submission's grade changes, oldest first.
+func (h *AdminGradingHandler) history(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid identifier")
+	}
+
+	history, err := h.service.GradeHistory(c.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrAdminSubmissionNotFound) {
+			return utils.SendError(c, fiber.StatusNotFound, "submission not found")
+		}
+		requestLogger(h.logger, c).Error().Err(err).Uint("submission_id", id).Msg("failed to load grade history")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to load grade history")
+	}
+
+	return utils.SendSuccess(c, "grade history retrieved", history)
+}
+
+// next claims the oldest ungraded submission of an assignment for the caller.
+func (h *AdminGradingHandler) next(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(strings.TrimSpace(c.Query("assignment_id")), 10, 64)
+	if err != nil || id == 0 {
+		return utils.SendError(c, fiber.StatusBadRequest, "assignment_id must be a positive integer")
+	}
+
+	query := dto.AdminGradingQueueQuery{AssignmentID: uint(id), Anonymized: c.QueryBool("anonymized", false)}
+	result, err := h.service.NextUngraded(c.UserContext(), query, activityActorFromContext(c))
+	if err != nil {
+		if errors.Is(err, service.ErrGradingAssignmentNotFound) {
+			return utils.SendError(c, fiber.StatusNotFound, "assignment not found")
+		}
+		requestLogger(h.logger, c).Error().Err(err).Uint64("assignment_id", id).Msg("failed to claim next submission")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to load grading queue")
+	}
+
+	message := "next submission claimed"
+	switch {
+	case result.AllGraded:
+		message = "all submissions graded"
+	case result.Submission == nil:
+		message = "remaining submissions are claimed by other graders"
+	}
+	return utils.SendSuccess(c, message, result)
+}
+
+// export streams the grade CSV. The body is written after the handler
+// returns, so failures past the header can only truncate the download; they
+// are logged and the activity entry is skipped.
+func (h *AdminGradingHandler) export(c *fiber.Ctx) error {
+	query := dto.AdminGradeExportQuery{Format: c.Query("format", service.GradeExportCSV)}
+	if raw := strings.TrimSpace(c.Query("assignment_id")); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || id == 0 {
+			return utils.SendError(c, fiber.StatusBadRequest, "assignment_id must be a positive integer")
+		}
+		query.AssignmentID = uint(id)
+	}
+
+	export, err := h.service.ExportGrades(c.UserContext(), activityActorFromContext(c), query)
+	if err != nil {
+		if errors.Is(err, service.ErrGradeExportFormat) {
+			return utils.SendError(c, fiber.StatusBadRequest, "format must be csv")
+		}
+		requestLogger(h.logger, c).Error().Err(err).Msg("failed to prepare grade export")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to export grades")
+	}
+
+	logger := requestLogger(h.logger, c)
+	c.Attachment(export.Filename)
+	c.Set(fiber.HeaderContentType, export.ContentType)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := export.Stream(w); err != nil {
+			logger.Error().Err(err).Str("filename", export.Filename).Msg("grade export stream failed")
+		}
+	})
+	return nil
+}
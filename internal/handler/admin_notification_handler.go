@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/service"
+	"github.com/noah-isme/gema-go-api/internal/utils"
+)
+
+// AdminNotificationHandler exposes batch notification endpoints for admins.
+type AdminNotificationHandler struct {
+	service service.AdminNotificationService
+	logger  zerolog.Logger
+}
+
+// NewAdminNotificationHandler constructs the handler.
+func NewAdminNotificationHandler(service service.AdminNotificationService, logger zerolog.Logger) *AdminNotificationHandler {
+	return &AdminNotificationHandler{
+		service: service,
+		logger:  logger.With().Str("component", "admin_notification_handler").Logger(),
+	}
+}
+
+// Register attaches notification admin routes to the router group.
+func (h *AdminNotificationHandler) Register(router fiber.Router) {
+	router.Post("/batch", h.sendBatch)
+}
+
+func (h *AdminNotificationHandler) sendBatch(c *fiber.Ctx) error {
+	var payload dto.AdminNotificationBatchRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	}
+
+	var recipients io.Reader
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "unable to read file")
+		}
+		defer file.Close()
+		recipients = file
+	}
+
+	actor := activityActorFromContext(c)
+	report, err := h.service.SendBatch(c.Context(), recipients, payload, actor)
+	if err != nil {
+		switch {
+		case isValidationError(err):
+			return sendValidationError(c, err)
+		case errors.Is(err, service.ErrAdminNotificationRecipientsRequired),
+			errors.Is(err, service.ErrAdminNotificationImportInvalid):
+			return sendServiceError(c, h.logger, err)
+		default:
+			requestLogger(h.logger, c).Error().Err(err).Msg("failed to send notification batch")
+			return utils.SendError(c, fiber.StatusInternalServerError, "failed to send notifications")
+		}
+	}
+
+	return utils.SendSuccess(c, "notifications sent", report)
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/service"
+	"github.com/noah-isme/gema-go-api/internal/utils"
+)
+
+// AdminRoadmapHandler manages roadmap stage admin endpoints.
+type AdminRoadmapHandler struct {
+	service service.AdminRoadmapService
+	logger  zerolog.Logger
+}
+
+// NewAdminRoadmapHandler constructs the handler.
+func NewAdminRoadmapHandler(service service.AdminRoadmapService, logger zerolog.Logger) *AdminRoadmapHandler {
+	return &AdminRoadmapHandler{
+		service: service,
+		logger:  logger.With().Str("component", "admin_roadmap_handler").Logger(),
+	}
+}
+
+// Register attaches routes.
+func (h *AdminRoadmapHandler) Register(router fiber.Router) {
+	router.Post("", h.create)
+	router.Patch("/reorder", h.reorder)
+	router.Patch("/:id", h.update)
+	router.Delete("/:id", h.delete)
+}
+
+func (h *AdminRoadmapHandler) create(c *fiber.Ctx) error {
+	var payload dto.AdminRoadmapStageRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	}
+
+	stage, err := h.service.Create(c.Context(), payload, activityActorFromContext(c))
+	if err != nil {
+		if isValidationError(err) {
+			return sendValidationError(c, err)
+		}
+		h.logger.Error().Err(err).Msg("failed to create roadmap stage")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to create roadmap stage")
+	}
+
+	return utils.SendSuccessWithStatus(c, fiber.StatusCreated, "roadmap stage created", stage)
+}
+
+func (h *AdminRoadmapHandler) update(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid identifier")
+	}
+	var payload dto.AdminRoadmapStageUpdateRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	}
+
+	stage, err := h.service.Update(c.Context(), id, payload, activityActorFromContext(c))
+	if err != nil {
+		switch {
+		case isValidationError(err):
+			return sendValidationError(c, err)
+		case errors.Is(err, service.ErrRoadmapStageNotFound):
+			return utils.SendError(c, fiber.StatusNotFound, "roadmap stage not found")
+		default:
+			h.logger.Error().Err(err).Uint("stage_id", id).Msg("failed to update roadmap stage")
+			return utils.SendError(c, fiber.StatusInternalServerError, "failed to update roadmap stage")
+		}
+	}
+
+	return utils.SendSuccess(c, "roadmap stage updated", stage)
+}
+
+func (h *AdminRoadmapHandler) reorder(c *fiber.Ctx) error {
+	var payload dto.AdminRoadmapReorderRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	}
+
+	if err := h.service.Reorder(c.Context(), payload, activityActorFromContext(c)); err != nil {
+		switch {
+		case isValidationError(err):
+			return sendValidationError(c, err)
+		case errors.Is(err, service.ErrRoadmapStageNotFound):
+			return utils.SendError(c, fiber.StatusNotFound, "roadmap stage not found")
+		default:
+			h.logger.Error().Err(err).Msg("failed to reorder roadmap stages")
+			return utils.SendError(c, fiber.StatusInternalServerError, "failed to reorder roadmap stages")
+		}
+	}
+
+	return utils.SendSuccess(c, "roadmap stages reordered", fiber.Map{"ids": payload.IDs})
+}
+
+func (h *AdminRoadmapHandler) delete(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid identifier")
+	}
+
+	if err := h.service.Delete(c.Context(), id, activityActorFromContext(c)); err != nil {
+		if errors.Is(err, service.ErrRoadmapStageNotFound) {
+			return utils.SendError(c, fiber.StatusNotFound, "roadmap stage not found")
+		}
+		h.logger.Error().Err(err).Uint("stage_id", id).Msg("failed to delete roadmap stage")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to delete roadmap stage")
+	}
+
+	return utils.SendSuccess(c, "roadmap stage deleted", fiber.Map{"id": id})
+}
@@ -28,11 +28,40 @@ func NewAdminStudentHandler(service service.AdminStudentService, logger zerolog.
 // Register attaches student admin routes to the router group.
 func (h *AdminStudentHandler) Register(router fiber.Router) {
 	router.Get("", h.list)
+	router.Post("/import", h.importCSV)
 	router.Get("/:id", h.get)
 	router.Patch("/:id", h.update)
 	router.Delete("/:id", h.delete)
 }
 
+func (h *AdminStudentHandler) importCSV(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "file is required")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "unable to read file")
+	}
+	defer file.Close()
+
+	report, err := h.service.Import(c.Context(), file, activityActorFromContext(c))
+	if err != nil {
+		if errors.Is(err, service.ErrAdminStudentImportInvalid) {
+			return sendServiceError(c, h.logger, err)
+		}
+		requestLogger(h.logger, c).Error().Err(err).Msg("failed to import students")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to import students")
+	}
+
+	status := fiber.StatusOK
+	if report.Created > 0 {
+		status = fiber.StatusCreated
+	}
+	return utils.SendSuccessWithStatus(c, status, "students imported", report)
+}
+
 func (h *AdminStudentHandler) list(c *fiber.Ctx) error {
 	page, err := parseQueryInt(c, "page")
 	if err != nil {
@@ -106,7 +135,7 @@ func (h *AdminStudentHandler) update(c *fiber.Ctx) error {
 		case errors.Is(err, service.ErrAdminStudentNotFound):
 			return utils.SendError(c, fiber.StatusNotFound, "student not found")
 		case isValidationError(err):
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+			return sendValidationError(c, err)
 		default:
 			requestLogger(h.logger, c).Error().Err(err).Msg("failed to update student")
 			return utils.SendError(c, fiber.StatusInternalServerError, "failed to update student")
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/service"
+	"github.com/noah-isme/gema-go-api/internal/utils"
+)
+
+// AdminWebhookHandler manages webhook subscriptions and their dead letters.
+type AdminWebhookHandler struct {
+	service service.WebhookService
+	logger  zerolog.Logger
+}
+
+// NewAdminWebhookHandler constructs the handler.
+func NewAdminWebhookHandler(service service.WebhookService, logger zerolog.Logger) *AdminWebhookHandler {
+	return &AdminWebhookHandler{
+		service: service,
+		logger:  logger.With().Str("component", "admin_webhook_handler").Logger(),
+	}
+}
+
+// Register attaches routes.
+func (h *AdminWebhookHandler) Register(router fiber.Router) {
+	router.Get("", h.list)
+	router.Post("", h.create)
+	router.Get("/dead-letters", h.deadLetters)
+	router.Delete("/:id", h.delete)
+}
+
+func (h *AdminWebhookHandler) list(c *fiber.Ctx) error {
+	subscriptions, err := h.service.ListSubscriptions(c.Context())
+	if err != nil {
+		requestLogger(h.logger, c).Error().Err(err).Msg("failed to list webhook subscriptions")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to list webhook subscriptions")
+	}
+	return utils.SendSuccess(c, "webhook subscriptions retrieved", subscriptions)
+}
+
+func (h *AdminWebhookHandler) create(c *fiber.Ctx) error {
+	var payload dto.WebhookSubscriptionRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	}
+
+	subscription, err := h.service.CreateSubscription(c.Context(), payload, activityActorFromContext(c))
+	if err != nil {
+		if isValidationError(err) {
+			return sendValidationError(c, err)
+		}
+		requestLogger(h.logger, c).Error().Err(err).Msg("failed to create webhook subscription")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to create webhook subscription")
+	}
+
+	return utils.SendSuccessWithStatus(c, fiber.StatusCreated, "webhook subscription created", subscription)
+}
+
+func (h *AdminWebhookHandler) delete(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid identifier")
+	}
+
+	if err := h.service.DeleteSubscription(c.Context(), id); err != nil {
+		if errors.Is(err, service.ErrWebhookSubscriptionNotFound) {
+			return sendServiceError(c, h.logger, err)
+		}
+		requestLogger(h.logger, c).Error().Err(err).Uint("subscription_id", id).Msg("failed to delete webhook subscription")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to delete webhook subscription")
+	}
+
+	return utils.SendSuccess(c, "webhook subscription deleted", nil)
+}
+
+func (h *AdminWebhookHandler) deadLetters(c *fiber.Ctx) error {
+	page, err := parseQueryInt(c, "page")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid page")
+	}
+	pageSize, err := parseQueryInt(c, "pageSize")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid page size")
+	}
+
+	result, err := h.service.ListDeadLetters(c.Context(), page, pageSize)
+	if err != nil {
+		requestLogger(h.logger, c).Error().Err(err).Msg("failed to list webhook dead letters")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to list webhook dead letters")
+	}
+
+	return utils.OK(c, result.Items, "webhook dead letters retrieved", fiber.Map{"pagination": result.Pagination})
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 
+	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/service"
 	"github.com/noah-isme/gema-go-api/internal/utils"
 )
@@ -43,7 +44,10 @@ func (h *AnnouncementHandler) list(c *fiber.Ctx) error {
 		pageSize = 20
 	}
 
-	result, err := h.service.ListActive(c.Context(), page, pageSize)
+	// Anonymous readers only get announcements for everyone; signed-in readers
+	// also see those targeted at their class or role.
+	viewer := dto.AnnouncementViewer{Role: userRoleFromContext(c), Class: userClassFromContext(c)}
+	result, err := h.service.ListActive(c.Context(), page, pageSize, viewer)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("failed to list announcements")
 		return utils.SendError(c, fiber.StatusInternalServerError, "failed to list announcements")
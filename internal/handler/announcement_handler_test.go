@@ -21,13 +21,15 @@ import (
 type mockAnnouncementService struct {
 	lastPage     int
 	lastPageSize int
+	lastViewer   dto.AnnouncementViewer
 	response     dto.AnnouncementListResponse
 	err          error
 }
 
-func (m *mockAnnouncementService) ListActive(_ context.Context, page, pageSize int) (dto.AnnouncementListResponse, error) {
+func (m *mockAnnouncementService) ListActive(_ context.Context, page, pageSize int, viewer dto.AnnouncementViewer) (dto.AnnouncementListResponse, error) {
 	m.lastPage = page
 	m.lastPageSize = pageSize
+	m.lastViewer = viewer
 	if m.err != nil {
 		return dto.AnnouncementListResponse{}, m.err
 	}
@@ -67,6 +69,7 @@ func TestAnnouncementHandler_ListSuccess(t *testing.T) {
 	require.True(t, body.Data.CacheHit)
 	require.Equal(t, 1, svc.lastPage)
 	require.Equal(t, 20, svc.lastPageSize)
+	require.Equal(t, dto.AnnouncementViewer{}, svc.lastViewer)
 }
 
 func TestAnnouncementHandler_InvalidPage(t *testing.T) {
@@ -92,3 +95,21 @@ func TestAnnouncementHandler_ServiceError(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
 }
+
+func TestAnnouncementHandler_PassesViewerFromContext(t *testing.T) {
+	svc := &mockAnnouncementService{}
+	logger := zerolog.New(io.Discard)
+	app := fiber.New()
+	identify := func(c *fiber.Ctx) error {
+		c.Locals("user_role", "student")
+		c.Locals("user_class", "XI-1")
+		return c.Next()
+	}
+	handler.NewAnnouncementHandler(svc, logger).Register(app.Group("/api/announcements", identify))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/announcements", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, dto.AnnouncementViewer{Role: "student", Class: "XI-1"}, svc.lastViewer)
+}
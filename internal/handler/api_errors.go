@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/noah-isme/gema-go-api/internal/service"
+	"github.com/noah-isme/gema-go-api/internal/utils"
+)
+
+// sentinelAPIError assigns a status and stable code to a service sentinel.
+// An empty message reuses the error's own text.
+type sentinelAPIError struct {
+	target  error
+	status  int
+	code    string
+	message string
+}
+
+// sentinelAPIErrors is checked in order, so more specific errors go first.
+var sentinelAPIErrors = []sentinelAPIError{
+	{service.ErrAssignmentNotFound, fiber.StatusNotFound, "ASSIGNMENT_NOT_FOUND", "assignment not found"},
+	{service.ErrSubmissionNotFound, fiber.StatusNotFound, "SUBMISSION_NOT_FOUND", "submission not found"},
+	{service.ErrSubmissionPastDue, fiber.StatusBadRequest, "SUBMISSION_PAST_DUE", ""},
+	{service.ErrSubmissionGraded, fiber.StatusConflict, "SUBMISSION_GRADED", ""},
+	{service.ErrSubmissionSuperseded, fiber.StatusConflict, "SUBMISSION_SUPERSEDED", ""},
+	{service.ErrAssignmentSizeLimitTooHigh, fiber.StatusBadRequest, "ASSIGNMENT_SIZE_LIMIT_TOO_HIGH", ""},
+
+	{service.ErrUnsupportedLanguage, fiber.StatusBadRequest, "UNSUPPORTED_LANGUAGE", "language not supported"},
+	{service.ErrCodingTaskNotFound, fiber.StatusNotFound, "CODING_TASK_NOT_FOUND", ""},
+	{service.ErrCodingSubmissionNotFound, fiber.StatusNotFound, "CODING_SUBMISSION_NOT_FOUND", ""},
+	{service.ErrCodingSubmissionLogNotFound, fiber.StatusNotFound, "CODING_SUBMISSION_LOG_NOT_FOUND", ""},
+	{service.ErrCodingTaskInactive, fiber.StatusConflict, "CODING_TASK_INACTIVE", "coding task is inactive and no longer accepts submissions"},
+	{service.ErrCodingSubmissionForbidden, fiber.StatusForbidden, "FORBIDDEN", "forbidden"},
+	{service.ErrLanguageRuntimeUnavailable, fiber.StatusServiceUnavailable, "LANGUAGE_RUNTIME_UNAVAILABLE", "language runtime unavailable"},
+	{service.ErrExecutorBusy, fiber.StatusTooManyRequests, "EXECUTOR_BUSY", "code runners are busy, please retry shortly"},
+	{service.ErrStreamingUnavailable, fiber.StatusServiceUnavailable, "STREAMING_UNAVAILABLE", "streaming execution unavailable"},
+	{service.ErrEvaluatorUnavailable, fiber.StatusServiceUnavailable, "EVALUATOR_UNAVAILABLE", "evaluator unavailable"},
+
+	{service.ErrWebAssignmentNotFound, fiber.StatusNotFound, "ASSIGNMENT_NOT_FOUND", "assignment not found"},
+	{service.ErrWebSubmissionNotFound, fiber.StatusNotFound, "SUBMISSION_NOT_FOUND", "submission not found"},
+	{service.ErrWebSubmissionArchiveUnavailable, fiber.StatusBadGateway, "SUBMISSION_ARCHIVE_UNAVAILABLE", "submission archive could not be downloaded"},
+	{service.ErrWebSubmissionArchiveChanged, fiber.StatusConflict, "SUBMISSION_ARCHIVE_CHANGED", "submission archive no longer matches the original upload"},
+	{service.ErrWebSubmissionFileRequired, fiber.StatusBadRequest, "FILE_REQUIRED", "file is required"},
+	{service.ErrWebSubmissionUnsupportedType, fiber.StatusBadRequest, "UNSUPPORTED_FILE_TYPE", "submission must be a zip archive"},
+	{service.ErrWebSubmissionTooLarge, fiber.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", ""},
+	{service.ErrWebSubmissionInvalidArchive, fiber.StatusBadRequest, "INVALID_ARCHIVE", "invalid zip archive"},
+	{service.ErrWebSubmissionTooManyFiles, fiber.StatusRequestEntityTooLarge, "ARCHIVE_TOO_MANY_FILES", ""},
+	{service.ErrWebSubmissionFileTooLarge, fiber.StatusRequestEntityTooLarge, "ARCHIVE_FILE_TOO_LARGE", ""},
+	{service.ErrWebSubmissionDangerousFile, fiber.StatusBadRequest, "ARCHIVE_DANGEROUS_FILE", "submission contains disallowed files"},
+
+	{service.ErrInvalidCursor, fiber.StatusBadRequest, "INVALID_CURSOR", ""},
+
+	{service.ErrTutorialArticleNotFound, fiber.StatusNotFound, "ARTICLE_NOT_FOUND", "article not found"},
+	{service.ErrTutorialProjectNotFound, fiber.StatusNotFound, "PROJECT_NOT_FOUND", "project not found"},
+	{service.ErrInvalidCodingTaskSort, fiber.StatusBadRequest, "INVALID_SORT", ""},
+	{service.ErrInvalidCodingTaskEnv, fiber.StatusBadRequest, "CODING_TASK_ENV_INVALID", ""},
+	{service.ErrSimilarityUnavailable, fiber.StatusNotFound, "SIMILARITY_UNAVAILABLE", ""},
+
+	{service.ErrDiscussionForbidden, fiber.StatusForbidden, "FORBIDDEN", ""},
+	{service.ErrDiscussionThreadClosed, fiber.StatusConflict, "THREAD_CLOSED", ""},
+	{service.ErrChatRoomForbidden, fiber.StatusForbidden, "FORBIDDEN", ""},
+	{service.ErrChatRoomNotFound, fiber.StatusNotFound, "CHAT_ROOM_NOT_FOUND", ""},
+	{service.ErrChatRoomMemberNotFound, fiber.StatusNotFound, "CHAT_ROOM_MEMBER_NOT_FOUND", ""},
+	{service.ErrNotificationTypeInvalid, fiber.StatusBadRequest, "NOTIFICATION_TYPE_INVALID", ""},
+
+	{service.ErrUploadTooLarge, fiber.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", ""},
+	{service.ErrUploadTypeNotAllowed, fiber.StatusBadRequest, "FILE_TYPE_NOT_ALLOWED", ""},
+	{service.ErrUploadScanFailed, fiber.StatusBadRequest, "UPLOAD_SCAN_FAILED", ""},
+	{service.ErrUploadNotFound, fiber.StatusNotFound, "UPLOAD_NOT_FOUND", ""},
+	{service.ErrDirectUploadDisabled, fiber.StatusServiceUnavailable, "DIRECT_UPLOAD_DISABLED", ""},
+	{service.ErrDirectUploadInvalid, fiber.StatusBadRequest, "DIRECT_UPLOAD_INVALID", ""},
+	{service.ErrDirectUploadNotOwned, fiber.StatusForbidden, "FORBIDDEN", ""},
+	{service.ErrGalleryImageUnreadable, fiber.StatusBadRequest, "IMAGE_UNREADABLE", ""},
+
+	{service.ErrAnalyticsInvalidRange, fiber.StatusBadRequest, "ANALYTICS_RANGE_INVALID", ""},
+	{service.ErrActivityPruneCutoff, fiber.StatusBadRequest, "PRUNE_CUTOFF_INVALID", ""},
+	{service.ErrAnnouncementInvalidSchedule, fiber.StatusBadRequest, "ANNOUNCEMENT_SCHEDULE_INVALID", ""},
+	{service.ErrAdminAssignmentInvalidDueDate, fiber.StatusBadRequest, "ASSIGNMENT_DUE_DATE_INVALID", ""},
+	{service.ErrAdminAssignmentImportInvalid, fiber.StatusBadRequest, "IMPORT_FILE_INVALID", ""},
+	{service.ErrAdminStudentImportInvalid, fiber.StatusBadRequest, "IMPORT_FILE_INVALID", ""},
+	{service.ErrAdminNotificationImportInvalid, fiber.StatusBadRequest, "IMPORT_FILE_INVALID", ""},
+	{service.ErrAdminNotificationRecipientsRequired, fiber.StatusBadRequest, "NOTIFICATION_RECIPIENTS_REQUIRED", ""},
+	{service.ErrContactStatusTransition, fiber.StatusConflict, "CONTACT_STATUS_TRANSITION_INVALID", ""},
+	{service.ErrScoreExceedsMax, fiber.StatusBadRequest, "SCORE_EXCEEDS_MAX", ""},
+	{service.ErrRubricScoresInvalid, fiber.StatusBadRequest, "RUBRIC_SCORES_INVALID", ""},
+	{service.ErrGradeReasonRequired, fiber.StatusBadRequest, "GRADE_REASON_REQUIRED", ""},
+	{service.ErrWebhookSubscriptionNotFound, fiber.StatusNotFound, "WEBHOOK_SUBSCRIPTION_NOT_FOUND", ""},
+
+	{service.ErrInvalidRefreshToken, fiber.StatusUnauthorized, "INVALID_REFRESH_TOKEN", ""},
+	{service.ErrRefreshTokenRevoked, fiber.StatusUnauthorized, "REFRESH_TOKEN_REVOKED", ""},
+	{service.ErrRefreshAccountInactive, fiber.StatusUnauthorized, "REFRESH_ACCOUNT_INACTIVE", ""},
+	{service.ErrTokenNotRevocable, fiber.StatusBadRequest, "TOKEN_NOT_REVOCABLE", "token has no id or expiry and cannot be revoked"},
+}
+
+// apiErrorFor maps a service error to its API error. Validation, file type
+// and size limit errors carry structured fields or details; known sentinels
+// get their code from sentinelAPIErrors. ok is false for unexpected errors,
+// which callers log and report as INTERNAL_ERROR.
+func apiErrorFor(err error) (utils.APIError, bool) {
+	if apiErr, ok := utils.ValidationAPIError(err); ok {
+		return apiErr, true
+	}
+
+	var typeErr *service.FileTypeError
+	if errors.As(err, &typeErr) {
+		return utils.APIError{
+			Status:  fiber.StatusBadRequest,
+			Code:    "FILE_TYPE_NOT_ALLOWED",
+			Message: service.ErrUploadTypeNotAllowed.Error(),
+			Details: fileTypeDetails(typeErr),
+		}, true
+	}
+
+	var sizeErr *service.SizeLimitError
+	if errors.As(err, &sizeErr) {
+		return utils.APIError{
+			Status:  fiber.StatusRequestEntityTooLarge,
+			Code:    "PAYLOAD_TOO_LARGE",
+			Message: sizeErr.Error(),
+			Details: sizeLimitDetails(sizeErr),
+		}, true
+	}
+
+	for _, mapping := range sentinelAPIErrors {
+		if !errors.Is(err, mapping.target) {
+			continue
+		}
+		message := mapping.message
+		if message == "" {
+			message = err.Error()
+		}
+		return utils.APIError{Status: mapping.status, Code: mapping.code, Message: message}, true
+	}
+
+	return utils.APIError{}, false
+}
+
+// sendServiceError answers err with its API error. Errors apiErrorFor does
+// not know are logged and answered with INTERNAL_ERROR, so their text never
+// reaches the client.
+func sendServiceError(c *fiber.Ctx, logger zerolog.Logger, err error) error {
+	if apiErr, ok := apiErrorFor(err); ok {
+		return utils.SendAPIError(c, apiErr)
+	}
+	requestLogger(logger, c).Error().Err(err).Str("path", c.Path()).Msg("request failed")
+	return utils.SendAPIError(c, internalAPIError())
+}
+
+// sendRequestError answers a request the handler could not parse. Known
+// service errors keep their code; anything else is 400 INVALID_REQUEST, so
+// err must come from the handler's own parsing, whose messages name the
+// offending parameter.
+func sendRequestError(c *fiber.Ctx, err error) error {
+	if apiErr, ok := apiErrorFor(err); ok {
+		return utils.SendAPIError(c, apiErr)
+	}
+	return utils.SendAPIError(c, utils.APIError{
+		Status:  fiber.StatusBadRequest,
+		Code:    utils.CodeInvalidRequest,
+		Message: err.Error(),
+	})
+}
+
+// internalAPIError is the response for errors apiErrorFor does not know.
+func internalAPIError() utils.APIError {
+	return utils.APIError{
+		Status:  fiber.StatusInternalServerError,
+		Code:    utils.CodeInternal,
+		Message: "internal server error",
+	}
+}
@@ -82,15 +82,12 @@ func (h *AssignmentHandler) list(c *fiber.Ctx) error {
 func (h *AssignmentHandler) get(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	assignment, err := h.service.Get(c.Context(), id)
 	if err != nil {
-		if errors.Is(err, service.ErrAssignmentNotFound) {
-			return utils.SendError(c, fiber.StatusNotFound, "assignment not found")
-		}
-		return h.internalError(c, err)
+		return h.handleError(c, err)
 	}
 
 	return utils.SendSuccess(c, "assignment retrieved", assignment)
@@ -119,7 +116,7 @@ func (h *AssignmentHandler) create(c *fiber.Ctx) error {
 func (h *AssignmentHandler) update(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	payload := dto.AssignmentUpdateRequest{}
@@ -149,34 +146,26 @@ func (h *AssignmentHandler) update(c *fiber.Ctx) error {
 func (h *AssignmentHandler) delete(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	if err := h.service.Delete(c.Context(), id); err != nil {
-		if errors.Is(err, service.ErrAssignmentNotFound) {
-			return utils.SendError(c, fiber.StatusNotFound, "assignment not found")
-		}
-		return h.internalError(c, err)
+		return h.handleError(c, err)
 	}
 
 	return utils.SendSuccess(c, "assignment deleted", fiber.Map{"id": id})
 }
 
 func (h *AssignmentHandler) handleError(c *fiber.Ctx, err error) error {
-	var validationErrors validator.ValidationErrors
-	switch {
-	case errors.Is(err, service.ErrAssignmentNotFound):
-		return utils.SendError(c, fiber.StatusNotFound, "assignment not found")
-	case errors.As(err, &validationErrors):
-		return utils.SendError(c, fiber.StatusBadRequest, validationErrors.Error())
-	default:
-		return h.internalError(c, err)
+	if apiErr, ok := apiErrorFor(err); ok {
+		return utils.SendAPIError(c, apiErr)
 	}
+	return h.internalError(c, err)
 }
 
 func (h *AssignmentHandler) internalError(c *fiber.Ctx, err error) error {
 	h.logger.Error().Err(err).Msg("internal server error")
-	return utils.SendError(c, fiber.StatusInternalServerError, "internal server error")
+	return utils.SendAPIError(c, internalAPIError())
 }
 
 func parseUintParam(c *fiber.Ctx, name string) (uint, error) {
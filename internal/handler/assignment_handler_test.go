@@ -25,6 +25,7 @@ import (
 	"github.com/noah-isme/gema-go-api/internal/repository"
 	"github.com/noah-isme/gema-go-api/internal/router"
 	"github.com/noah-isme/gema-go-api/internal/service"
+	"github.com/noah-isme/gema-go-api/internal/utils"
 )
 
 type testAssignmentUploader struct{}
@@ -48,7 +49,7 @@ func setupAssignmentApp(t *testing.T) *fiber.App {
 	submissionRepo := repository.NewSubmissionRepository(db)
 
 	assignmentService := service.NewAssignmentService(assignmentRepo, validate, uploader, logger)
-	submissionService := service.NewSubmissionService(submissionRepo, assignmentRepo, validate, uploader, logger)
+	submissionService := service.NewSubmissionService(submissionRepo, assignmentRepo, validate, uploader, nil, nil, service.SubmissionSizeLimits{}, nil, nil, logger)
 
 	app := fiber.New()
 
@@ -120,6 +121,45 @@ func TestAssignmentHandlerCreateAndList(t *testing.T) {
 	require.Equal(t, "due_date", listBody.Meta.Sort)
 }
 
+func TestAssignmentHandlerErrorCodes(t *testing.T) {
+	app := setupAssignmentApp(t)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v2/tutorial/assignments/999999", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	var notFound struct {
+		Success bool   `json:"success"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	decodeResponse(t, resp, &notFound)
+	require.False(t, notFound.Success)
+	require.Equal(t, "ASSIGNMENT_NOT_FOUND", notFound.Code)
+	require.Equal(t, "assignment not found", notFound.Message)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("title", "DS"))
+	require.NoError(t, writer.WriteField("description", "Implement heaps"))
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", "/api/v2/tutorial/assignments", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var invalid struct {
+		Code   string             `json:"code"`
+		Fields []utils.FieldError `json:"fields"`
+	}
+	decodeResponse(t, resp, &invalid)
+	require.Equal(t, "VALIDATION_FAILED", invalid.Code)
+	require.Contains(t, invalid.Fields, utils.FieldError{Field: "title", Tag: "min", Message: "title must be at least 3 characters"})
+	require.Contains(t, invalid.Fields, utils.FieldError{Field: "due_date", Tag: "required", Message: "due_date is required"})
+}
+
 func decodeResponse(t *testing.T, resp *http.Response, target interface{}) {
 	t.Helper()
 	data, err := io.ReadAll(resp.Body)
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/service"
+	"github.com/noah-isme/gema-go-api/internal/utils"
+)
+
+// AuthHandler renews sessions with refresh tokens.
+type AuthHandler struct {
+	service service.AuthService
+	logger  zerolog.Logger
+}
+
+// NewAuthHandler constructs an auth handler.
+func NewAuthHandler(service service.AuthService, logger zerolog.Logger) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+		logger:  logger.With().Str("component", "auth_handler").Logger(),
+	}
+}
+
+// Register wires auth routes. Refresh is public since the refresh token is
+// the credential; logout runs behind requireToken, which authenticates the
+// access token being revoked.
+func (h *AuthHandler) Register(router fiber.Router, requireToken fiber.Handler) {
+	router.Post("/refresh", h.refresh)
+	router.Post("/logout", requireToken, h.logout)
+}
+
+func (h *AuthHandler) refresh(c *fiber.Ctx) error {
+	var payload dto.AuthRefreshRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	}
+
+	response, err := h.service.Refresh(c.Context(), payload)
+	if err != nil {
+		if apiErr, ok := apiErrorFor(err); ok {
+			return utils.SendAPIError(c, apiErr)
+		}
+		requestLogger(h.logger, c).Error().Err(err).Msg("failed to refresh token")
+		return utils.SendAPIError(c, internalAPIError())
+	}
+
+	return utils.SendSuccess(c, "token refreshed", response)
+}
+
+func (h *AuthHandler) logout(c *fiber.Ctx) error {
+	var payload dto.AuthLogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&payload); err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+		}
+	}
+	payload.TokenID, _ = c.Locals("token_jti").(string)
+	payload.ExpiresAt, _ = c.Locals("token_expires_at").(time.Time)
+
+	if err := h.service.Logout(c.Context(), payload); err != nil {
+		if apiErr, ok := apiErrorFor(err); ok {
+			return utils.SendAPIError(c, apiErr)
+		}
+		requestLogger(h.logger, c).Error().Err(err).Msg("failed to log out")
+		return utils.SendAPIError(c, internalAPIError())
+	}
+
+	return utils.SendSuccess(c, "logged out", nil)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -36,8 +37,13 @@ func NewChatHandler(service service.ChatService, validator *validator.Validate,
 
 // Register binds chat routes under the provided router group.
 func (h *ChatHandler) Register(router fiber.Router) {
+	// The group's JWT middleware authenticates the upgrade, reading the token
+	// from the token query parameter when the browser cannot send a header.
 	router.Use("/ws", func(c *fiber.Ctx) error {
 		if websocket.IsWebSocketUpgrade(c) {
+			if c.Locals("user_id") == nil {
+				return utils.SendError(c, fiber.StatusUnauthorized, "authentication required")
+			}
 			ctx := c.UserContext()
 			if ctx == nil {
 				ctx = context.Background()
@@ -51,6 +57,12 @@ func (h *ChatHandler) Register(router fiber.Router) {
 
 	router.Get("/ws", websocket.New(h.handleConnection))
 	router.Get("/history", h.history)
+	router.Get("/rooms/summary", h.roomSummaries)
+	router.Post("/rooms", h.createRoom)
+	router.Post("/rooms/:roomId/read", h.markRoomRead)
+	router.Post("/rooms/:roomId/members", h.addRoomMembers)
+	router.Delete("/rooms/:roomId/members/:userId", h.removeRoomMember)
+	router.Post("/users/:userId/disconnect", h.disconnectUser)
 }
 
 func (h *ChatHandler) handleConnection(conn *websocket.Conn) {
@@ -68,7 +80,7 @@ func (h *ChatHandler) handleConnection(conn *websocket.Conn) {
 		return
 	}
 
-	role := fmt.Sprint(conn.Locals("user_role"))
+	role, _ := conn.Locals("user_role").(string)
 	correlation := fmt.Sprint(conn.Locals("correlation_id"))
 	baseCtx, _ := conn.Locals("request_ctx").(context.Context)
 
@@ -116,7 +128,7 @@ func (h *ChatHandler) history(c *fiber.Ctx) error {
 	}
 
 	if err := h.validator.Struct(query); err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendValidationError(c, err)
 	}
 
 	ctx := c.UserContext()
@@ -125,14 +137,149 @@ func (h *ChatHandler) history(c *fiber.Ctx) error {
 	}
 	ctx = middleware.ContextWithCorrelation(ctx, middleware.GetCorrelationID(c))
 
-	messages, err := h.service.History(ctx, query)
+	messages, err := h.service.History(ctx, userIDStringFromContext(c), userRoleFromContext(c), query)
 	if err != nil {
-		return utils.SendError(c, fiber.StatusInternalServerError, err.Error())
+		if errors.Is(err, service.ErrChatNotAuthorised) {
+			return utils.SendError(c, fiber.StatusForbidden, "not a member of this room")
+		}
+		return sendServiceError(c, h.logger, err)
 	}
 
 	return utils.SendSuccess(c, "chat history", messages)
 }
 
+func (h *ChatHandler) roomSummaries(c *fiber.Ctx) error {
+	userID := userIDStringFromContext(c)
+	if userID == "" {
+		return utils.SendError(c, fiber.StatusUnauthorized, "user id missing")
+	}
+
+	raw := strings.TrimSpace(c.Query("room_ids"))
+	if raw == "" {
+		return utils.SendError(c, fiber.StatusBadRequest, "room_ids required")
+	}
+
+	summaries, err := h.service.RoomSummaries(c.Context(), userID, userRoleFromContext(c), strings.Split(raw, ","))
+	if err != nil {
+		if errors.Is(err, service.ErrChatTooManyRooms) {
+			return utils.SendError(c, fiber.StatusBadRequest, fmt.Sprintf("at most %d rooms per request", service.MaxChatRoomSummaries))
+		}
+		requestLogger(h.logger, c).Error().Err(err).Msg("failed to build chat room summaries")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to load room summaries")
+	}
+
+	return utils.SendSuccess(c, "chat room summaries", summaries)
+}
+
+func (h *ChatHandler) markRoomRead(c *fiber.Ctx) error {
+	userID := userIDStringFromContext(c)
+	if userID == "" {
+		return utils.SendError(c, fiber.StatusUnauthorized, "user id missing")
+	}
+
+	roomID := strings.TrimSpace(c.Params("roomId"))
+	if err := h.service.MarkRoomRead(c.Context(), userID, roomID); err != nil {
+		requestLogger(h.logger, c).Error().Err(err).Msg("failed to mark chat room read")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to mark room read")
+	}
+
+	return utils.SendSuccess(c, "chat room marked read", fiber.Map{"room_id": roomID})
+}
+
+func (h *ChatHandler) createRoom(c *fiber.Ctx) error {
+	if userIDStringFromContext(c) == "" {
+		return utils.SendError(c, fiber.StatusUnauthorized, "user id missing")
+	}
+
+	var payload dto.ChatRoomCreateRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	room, err := h.service.CreateRoom(c.Context(), activityActorFromContext(c), payload)
+	if err != nil {
+		return h.roomError(c, err, "failed to create chat room")
+	}
+
+	return utils.SendSuccess(c, "chat room created", room)
+}
+
+func (h *ChatHandler) addRoomMembers(c *fiber.Ctx) error {
+	if userIDStringFromContext(c) == "" {
+		return utils.SendError(c, fiber.StatusUnauthorized, "user id missing")
+	}
+
+	var payload dto.ChatRoomMembersRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	room, err := h.service.AddRoomMembers(c.Context(), activityActorFromContext(c), c.Params("roomId"), payload)
+	if err != nil {
+		return h.roomError(c, err, "failed to add chat room members")
+	}
+
+	return utils.SendSuccess(c, "chat room members added", room)
+}
+
+func (h *ChatHandler) removeRoomMember(c *fiber.Ctx) error {
+	if userIDStringFromContext(c) == "" {
+		return utils.SendError(c, fiber.StatusUnauthorized, "user id missing")
+	}
+
+	room, err := h.service.RemoveRoomMember(c.Context(), activityActorFromContext(c), c.Params("roomId"), c.Params("userId"))
+	if err != nil {
+		return h.roomError(c, err, "failed to remove chat room member")
+	}
+
+	return utils.SendSuccess(c, "chat room member removed", room)
+}
+
+func (h *ChatHandler) roomError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrChatRoomForbidden):
+		return sendServiceError(c, h.logger, err)
+	case errors.Is(err, service.ErrChatRoomNotFound), errors.Is(err, service.ErrChatRoomMemberNotFound):
+		return sendServiceError(c, h.logger, err)
+	case errors.Is(err, service.ErrChatRoomFull):
+		return utils.SendError(c, fiber.StatusBadRequest, fmt.Sprintf("a room holds at most %d members", service.MaxChatRoomMembers))
+	case isValidationError(err):
+		return sendValidationError(c, err)
+	default:
+		requestLogger(h.logger, c).Error().Err(err).Msg(message)
+		return utils.SendError(c, fiber.StatusInternalServerError, message)
+	}
+}
+
+func (h *ChatHandler) disconnectUser(c *fiber.Ctx) error {
+	userID := strings.TrimSpace(c.Params("userId"))
+	if userID == "" {
+		return utils.SendError(c, fiber.StatusBadRequest, "user id required")
+	}
+
+	var payload dto.ChatDisconnectRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&payload); err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "invalid request body")
+		}
+	}
+
+	result, err := h.service.Disconnect(c.Context(), activityActorFromContext(c), userID, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrChatModerationForbidden):
+			return utils.SendError(c, fiber.StatusForbidden, "insufficient permissions")
+		case isValidationError(err):
+			return sendValidationError(c, err)
+		default:
+			requestLogger(h.logger, c).Error().Err(err).Str("target_user_id", userID).Msg("failed to disconnect chat user")
+			return utils.SendError(c, fiber.StatusInternalServerError, "failed to disconnect user")
+		}
+	}
+
+	return utils.SendSuccess(c, "chat user disconnected", result)
+}
+
 func websocketUserID(conn *websocket.Conn) string {
 	if value := conn.Locals("user_id"); value != nil {
 		switch v := value.(type) {
@@ -0,0 +1,77 @@
+package handler_test
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	fiberws "github.com/gofiber/websocket/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noah-isme/gema-go-api/internal/handler"
+	"github.com/noah-isme/gema-go-api/internal/middleware"
+	"github.com/noah-isme/gema-go-api/internal/service"
+)
+
+// wsChatService records the options of the connections it serves; other
+// ChatService methods are not used by the upgrade.
+type wsChatService struct {
+	service.ChatService
+	connections chan service.ChatConnectionOptions
+}
+
+func (s *wsChatService) ServeConnection(conn *fiberws.Conn, opts service.ChatConnectionOptions) {
+	s.connections <- opts
+	_ = conn.Close()
+}
+
+func TestChatHandler_WebsocketQueryTokenAuth(t *testing.T) {
+	const secret = "chat-secret"
+
+	svc := &wsChatService{connections: make(chan service.ChatConnectionOptions, 1)}
+	app := fiber.New()
+	chat := app.Group("/api/v2/chat", middleware.JWTProtected(secret, middleware.JWTOptions{}), middleware.RequireRole("student", "teacher", "admin"))
+	handler.NewChatHandler(svc, validator.New(), zerolog.Nop()).Register(chat)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(listener) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	url := "ws://" + listener.Addr().String() + "/api/v2/chat/ws?room_id=room-1&token="
+	dialer := websocket.Dialer{HandshakeTimeout: 3 * time.Second}
+
+	for name, token := range map[string]string{"missing": "", "invalid": "not-a-token"} {
+		_, resp, err := dialer.Dial(url+token, nil)
+		require.ErrorIs(t, err, websocket.ErrBadHandshake, name)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode, name)
+		_ = resp.Body.Close()
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  "42",
+		"role": "student",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	require.NoError(t, err)
+
+	conn, resp, err := dialer.Dial(url+token, nil)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	defer conn.Close()
+
+	select {
+	case opts := <-svc.connections:
+		require.Equal(t, "42", opts.UserID)
+		require.Equal(t, "student", opts.Role)
+		require.Equal(t, "room-1", opts.RoomID)
+	case <-time.After(3 * time.Second):
+		t.Fatal("websocket connection was not served")
+	}
+}
@@ -1,17 +1,27 @@
 package handler
 
 import (
+	"context"
 	"errors"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
 	"github.com/rs/zerolog"
 
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/middleware"
+	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/service"
 	"github.com/noah-isme/gema-go-api/internal/utils"
 )
 
+// streamRequestTimeout bounds how long a stream connection may wait for the submission payload.
+const streamRequestTimeout = 30 * time.Second
+
 // CodingSubmissionHandler exposes submission endpoints for the coding lab.
 type CodingSubmissionHandler struct {
 	service   service.CodingSubmissionService
@@ -30,11 +40,32 @@ func NewCodingSubmissionHandler(service service.CodingSubmissionService, validat
 
 // Register wires the handler endpoints into the router group.
 func (h *CodingSubmissionHandler) Register(router fiber.Router) {
+	router.Use("/stream", func(c *fiber.Ctx) error {
+		if websocket.IsWebSocketUpgrade(c) {
+			ctx := c.UserContext()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			ctx = middleware.ContextWithCorrelation(ctx, middleware.GetCorrelationID(c))
+			c.Locals("request_ctx", ctx)
+			return c.Next()
+		}
+		return fiber.ErrUpgradeRequired
+	})
+
+	router.Get("/stream", websocket.New(h.stream))
+	router.Get("", h.list)
 	router.Post("", h.create)
 	router.Get("/:id", h.get)
+	router.Get("/:id/log", h.log)
 	router.Post("/:id/evaluate", h.evaluate)
 }
 
+// RegisterAdmin wires the staff-only submission reporting routes.
+func (h *CodingSubmissionHandler) RegisterAdmin(router fiber.Router) {
+	router.Get("/language-stats", h.languageStats)
+}
+
 func (h *CodingSubmissionHandler) create(c *fiber.Ctx) error {
 	var payload dto.CodingSubmissionRequest
 	if err := c.BodyParser(&payload); err != nil {
@@ -42,7 +73,7 @@ func (h *CodingSubmissionHandler) create(c *fiber.Ctx) error {
 	}
 
 	if err := h.validator.Struct(payload); err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendValidationError(c, err)
 	}
 
 	studentID := userIDFromContext(c)
@@ -54,14 +85,56 @@ func (h *CodingSubmissionHandler) create(c *fiber.Ctx) error {
 	if err != nil {
 		return h.handleError(c, err)
 	}
+	if response.Status == models.CodingSubmissionStatusPending {
+		return utils.SendSuccessWithStatus(c, fiber.StatusAccepted, "submission queued", response)
+	}
 
 	return utils.SendSuccess(c, "submission created", response)
 }
 
+func (h *CodingSubmissionHandler) list(c *fiber.Ctx) error {
+	filter := dto.CodingSubmissionFilter{
+		Status:   strings.ToLower(strings.TrimSpace(c.Query("status"))),
+		Language: c.Query("language"),
+	}
+
+	taskID, err := parseQueryUint(c, "task_id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid task_id")
+	}
+	if taskID != nil {
+		filter.TaskID = *taskID
+	}
+	studentID, err := parseQueryUint(c, "student_id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid student_id")
+	}
+	if studentID != nil {
+		filter.StudentID = *studentID
+	}
+	if page, err := parseQueryInt(c, "page"); err == nil {
+		filter.Page = page
+	}
+	if pageSize, err := parseQueryInt(c, "page_size"); err == nil {
+		filter.PageSize = pageSize
+	}
+
+	if err := h.validator.Struct(filter); err != nil {
+		return sendValidationError(c, err)
+	}
+
+	response, err := h.service.List(c.Context(), filter, userIDFromContext(c), userRoleFromContext(c))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return utils.SendSuccess(c, "submissions retrieved", response)
+}
+
 func (h *CodingSubmissionHandler) get(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	response, err := h.service.Get(c.Context(), id, userIDFromContext(c), userRoleFromContext(c))
@@ -72,10 +145,25 @@ func (h *CodingSubmissionHandler) get(c *fiber.Ctx) error {
 	return utils.SendSuccess(c, "submission retrieved", response)
 }
 
+func (h *CodingSubmissionHandler) log(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	response, err := h.service.Log(c.Context(), id, userRoleFromContext(c))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return utils.SendSuccess(c, "submission log retrieved", response)
+}
+
 func (h *CodingSubmissionHandler) evaluate(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	evaluatorID := userIDFromContext(c)
@@ -84,7 +172,7 @@ func (h *CodingSubmissionHandler) evaluate(c *fiber.Ctx) error {
 		return utils.SendError(c, fiber.StatusForbidden, "insufficient permissions")
 	}
 
-	evaluation, err := h.service.Evaluate(c.Context(), id, evaluatorID, role)
+	evaluation, err := h.service.Evaluate(c.Context(), id, evaluatorID, role, c.QueryBool("force"))
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -92,21 +180,104 @@ func (h *CodingSubmissionHandler) evaluate(c *fiber.Ctx) error {
 	return utils.SendSuccess(c, "submission evaluated", evaluation)
 }
 
+func (h *CodingSubmissionHandler) languageStats(c *fiber.Ctx) error {
+	stats, err := h.service.LanguageStats(c.Context())
+	if err != nil {
+		requestLogger(h.logger, c).Error().Err(err).Msg("failed to aggregate coding language stats")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to load language statistics")
+	}
+
+	return utils.SendSuccess(c, "language statistics retrieved", stats)
+}
+
+// stream runs a submission sent as the first websocket message and relays its
+// output frames, finishing with a result or error frame before closing.
+func (h *CodingSubmissionHandler) stream(conn *websocket.Conn) {
+	defer conn.Close()
+
+	studentID, err := strconv.ParseUint(websocketUserID(conn), 10, 64)
+	if err != nil || studentID == 0 {
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(fiber.StatusUnauthorized, "unauthorized"))
+		return
+	}
+
+	var payload dto.CodingSubmissionRequest
+	_ = conn.SetReadDeadline(time.Now().Add(streamRequestTimeout))
+	if err := conn.ReadJSON(&payload); err != nil {
+		h.closeStream(conn, dto.CodingStreamMessage{Type: dto.CodingStreamError, Error: "invalid request body"})
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	if err := h.validator.Struct(payload); err != nil {
+		message := "invalid payload"
+		if fields, ok := utils.ValidationFieldErrors(err); ok && len(fields) > 0 {
+			message = fields[0].Message
+		}
+		h.closeStream(conn, dto.CodingStreamMessage{Type: dto.CodingStreamError, Error: message})
+		return
+	}
+
+	baseCtx, _ := conn.Locals("request_ctx").(context.Context)
+	if baseCtx == nil {
+		baseCtx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(baseCtx)
+	defer cancel()
+
+	// Stop the run as soon as the client goes away.
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	emit := func(message dto.CodingStreamMessage) {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := conn.WriteJSON(message); err != nil {
+			h.logger.Debug().Err(err).Msg("failed to write coding stream frame")
+			cancel()
+		}
+	}
+
+	result, err := h.service.Stream(ctx, uint(studentID), payload, emit)
+	if err != nil {
+		h.closeStream(conn, dto.CodingStreamMessage{Type: dto.CodingStreamError, Error: h.apiError(err).Message})
+		return
+	}
+
+	h.closeStream(conn, result)
+}
+
+func (h *CodingSubmissionHandler) closeStream(conn *websocket.Conn, message dto.CodingStreamMessage) {
+	if err := conn.WriteJSON(message); err != nil {
+		h.logger.Debug().Err(err).Msg("failed to write final coding stream frame")
+		return
+	}
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, message.Type))
+}
+
 func (h *CodingSubmissionHandler) handleError(c *fiber.Ctx, err error) error {
-	var validationErrors validator.ValidationErrors
+	apiErr := h.apiError(err)
+	if apiErr.Status == fiber.StatusTooManyRequests {
+		c.Set(fiber.HeaderRetryAfter, "5")
+	}
+	return utils.SendAPIError(c, apiErr)
+}
+
+func (h *CodingSubmissionHandler) apiError(err error) utils.APIError {
+	apiErr, ok := apiErrorFor(err)
 	switch {
-	case errors.Is(err, service.ErrUnsupportedLanguage):
-		return utils.SendError(c, fiber.StatusBadRequest, "language not supported")
-	case errors.Is(err, service.ErrCodingTaskNotFound), errors.Is(err, service.ErrCodingSubmissionNotFound):
-		return utils.SendError(c, fiber.StatusNotFound, err.Error())
-	case errors.Is(err, service.ErrCodingSubmissionForbidden):
-		return utils.SendError(c, fiber.StatusForbidden, "forbidden")
-	case errors.Is(err, service.ErrEvaluatorUnavailable):
-		return utils.SendError(c, fiber.StatusServiceUnavailable, "evaluator unavailable")
-	case errors.As(err, &validationErrors):
-		return utils.SendError(c, fiber.StatusBadRequest, validationErrors.Error())
-	default:
+	case !ok:
 		h.logger.Error().Err(err).Msg("submission operation failed")
-		return utils.SendError(c, fiber.StatusInternalServerError, "internal server error")
+		return internalAPIError()
+	case errors.Is(err, service.ErrLanguageRuntimeUnavailable):
+		h.logger.Error().Err(err).Msg("execution image rejected by executor allowlist")
 	}
+	return apiErr
 }
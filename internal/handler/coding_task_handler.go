@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 
@@ -29,11 +31,24 @@ func (h *CodingTaskHandler) Register(router fiber.Router) {
 	router.Get("/:id", h.get)
 }
 
+// RegisterAdmin wires the staff-only task management routes.
+func (h *CodingTaskHandler) RegisterAdmin(router fiber.Router) {
+	router.Patch("/:id/active", h.setActive)
+	router.Put("/:id/env", h.setEnv)
+}
+
 func (h *CodingTaskHandler) list(c *fiber.Ctx) error {
 	filter := dto.CodingTaskFilter{
 		Language:   c.Query("language"),
 		Difficulty: c.Query("difficulty"),
 		Search:     c.Query("search"),
+		Sort:       c.Query("sort"),
+	}
+
+	if isStaffRequest(c) {
+		filter.IncludeInactive = true
+	} else {
+		filter.StudentID = userIDFromContext(c)
 	}
 
 	if tags := c.Query("tags"); tags != "" {
@@ -49,6 +64,9 @@ func (h *CodingTaskHandler) list(c *fiber.Ctx) error {
 
 	tasks, err := h.service.List(c.Context(), filter)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCodingTaskSort) {
+			return sendServiceError(c, h.logger, err)
+		}
 		h.logger.Error().Err(err).Msg("failed to list coding tasks")
 		return utils.SendError(c, fiber.StatusInternalServerError, "failed to retrieve tasks")
 	}
@@ -59,7 +77,7 @@ func (h *CodingTaskHandler) list(c *fiber.Ctx) error {
 func (h *CodingTaskHandler) get(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	task, err := h.service.Get(c.Context(), id)
@@ -73,3 +91,55 @@ func (h *CodingTaskHandler) get(c *fiber.Ctx) error {
 
 	return utils.SendSuccess(c, "coding task retrieved", task)
 }
+
+func (h *CodingTaskHandler) setActive(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	var payload dto.CodingTaskActiveRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+	if payload.Active == nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "active is required")
+	}
+
+	task, err := h.service.SetActive(c.Context(), id, *payload.Active)
+	if err != nil {
+		if err == service.ErrCodingTaskNotFound {
+			return utils.SendError(c, fiber.StatusNotFound, "coding task not found")
+		}
+		h.logger.Error().Err(err).Uint("task_id", id).Msg("failed to update coding task availability")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to update task")
+	}
+
+	return utils.SendSuccess(c, "coding task updated", task)
+}
+
+func (h *CodingTaskHandler) setEnv(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	var payload dto.CodingTaskEnvRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	task, err := h.service.SetEnv(c.Context(), id, payload.Env)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCodingTaskEnv):
+			return sendServiceError(c, h.logger, err)
+		case errors.Is(err, service.ErrCodingTaskNotFound):
+			return utils.SendError(c, fiber.StatusNotFound, "coding task not found")
+		}
+		h.logger.Error().Err(err).Uint("task_id", id).Msg("failed to update coding task environment")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to update task")
+	}
+
+	return utils.SendSuccess(c, "coding task environment updated", task)
+}
@@ -51,6 +51,8 @@ func (h *ContactHandler) submit(c *fiber.Ctx) error {
 			return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
 		case errors.Is(err, service.ErrContactDuplicate):
 			return utils.SendError(c, fiber.StatusTooManyRequests, "duplicate submission")
+		case errors.Is(err, service.ErrContactRateLimited):
+			return utils.SendError(c, fiber.StatusTooManyRequests, "too many submissions, please try again later")
 		default:
 			h.logger.Error().Err(err).Msg("failed to process contact submission")
 			return utils.SendError(c, fiber.StatusInternalServerError, "failed to submit contact form")
@@ -97,6 +97,7 @@ func TestContactHandler_ServiceErrors(t *testing.T) {
 	}{
 		{name: "spam", err: service.ErrContactSpam, statusCode: fiber.StatusBadRequest},
 		{name: "duplicate", err: service.ErrContactDuplicate, statusCode: fiber.StatusTooManyRequests},
+		{name: "rate_limited", err: service.ErrContactRateLimited, statusCode: fiber.StatusTooManyRequests},
 		{name: "generic", err: errors.New("boom"), statusCode: fiber.StatusInternalServerError},
 	}
 
@@ -60,7 +60,7 @@ func (h *DiscussionHandler) listThreads(c *fiber.Ctx) error {
 
 	threads, err := h.service.ListThreads(ctx, limit, offset)
 	if err != nil {
-		return utils.SendError(c, fiber.StatusInternalServerError, err.Error())
+		return h.handleError(c, err)
 	}
 
 	return utils.SendSuccess(c, "threads", threads)
@@ -69,7 +69,7 @@ func (h *DiscussionHandler) listThreads(c *fiber.Ctx) error {
 func (h *DiscussionHandler) getThread(c *fiber.Ctx) error {
 	id, err := parseUintParamValue(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	includeReplies := strings.ToLower(strings.TrimSpace(c.Query("include_replies"))) == "true"
@@ -78,7 +78,7 @@ func (h *DiscussionHandler) getThread(c *fiber.Ctx) error {
 
 	thread, err := h.service.GetThread(ctx, uint(id), includeReplies)
 	if err != nil {
-		return utils.SendError(c, fiber.StatusInternalServerError, err.Error())
+		return h.handleError(c, err)
 	}
 
 	return utils.SendSuccess(c, "thread", thread)
@@ -99,11 +99,10 @@ func (h *DiscussionHandler) createThread(c *fiber.Ctx) error {
 
 	response, err := h.service.CreateThread(ctx, userID, userRoleFromContext(c), payload)
 	if err != nil {
-		status := fiber.StatusInternalServerError
 		if isValidationError(err) {
-			status = fiber.StatusBadRequest
+			return sendValidationError(c, err)
 		}
-		return utils.SendError(c, status, err.Error())
+		return h.handleError(c, err)
 	}
 
 	return utils.SendSuccessWithStatus(c, fiber.StatusCreated, "thread created", response)
@@ -117,7 +116,7 @@ func (h *DiscussionHandler) updateThread(c *fiber.Ctx) error {
 
 	id, err := parseUintParamValue(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	var payload dto.DiscussionThreadUpdateRequest
@@ -129,13 +128,10 @@ func (h *DiscussionHandler) updateThread(c *fiber.Ctx) error {
 
 	response, err := h.service.UpdateThread(ctx, uint(id), userID, userRoleFromContext(c), payload)
 	if err != nil {
-		status := fiber.StatusInternalServerError
-		if errors.Is(err, service.ErrDiscussionForbidden) {
-			status = fiber.StatusForbidden
-		} else if isValidationError(err) {
-			status = fiber.StatusBadRequest
+		if isValidationError(err) {
+			return sendValidationError(c, err)
 		}
-		return utils.SendError(c, status, err.Error())
+		return h.handleError(c, err)
 	}
 
 	return utils.SendSuccess(c, "thread updated", response)
@@ -149,20 +145,13 @@ func (h *DiscussionHandler) deleteThread(c *fiber.Ctx) error {
 
 	id, err := parseUintParamValue(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	ctx := withRequestContext(c)
 
 	if err := h.service.DeleteThread(ctx, uint(id), userID, userRoleFromContext(c)); err != nil {
-		status := fiber.StatusInternalServerError
-		if errors.Is(err, service.ErrDiscussionForbidden) {
-			status = fiber.StatusForbidden
-		}
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			status = fiber.StatusNotFound
-		}
-		return utils.SendError(c, status, err.Error())
+		return h.handleError(c, err)
 	}
 
 	return utils.SendSuccess(c, "thread deleted", nil)
@@ -190,9 +179,19 @@ func (h *DiscussionHandler) listReplies(c *fiber.Ctx) error {
 
 	ctx := withRequestContext(c)
 
+	// A cursor parameter, even an empty one for the first page, switches to
+	// keyset pagination; the next page's cursor is returned in meta.
+	if c.Context().QueryArgs().Has("cursor") {
+		replies, next, err := h.service.ListRepliesAfter(ctx, uint(threadID), c.Query("cursor"), limit)
+		if err != nil {
+			return h.handleError(c, err)
+		}
+		return utils.OK(c, replies, "replies", dto.CursorMeta{NextCursor: next})
+	}
+
 	replies, err := h.service.ListReplies(ctx, uint(threadID), limit, offset)
 	if err != nil {
-		return utils.SendError(c, fiber.StatusInternalServerError, err.Error())
+		return h.handleError(c, err)
 	}
 
 	return utils.SendSuccess(c, "replies", replies)
@@ -213,18 +212,24 @@ func (h *DiscussionHandler) createReply(c *fiber.Ctx) error {
 
 	reply, err := h.service.CreateReply(ctx, userID, userRoleFromContext(c), payload)
 	if err != nil {
-		status := fiber.StatusInternalServerError
 		if isValidationError(err) {
-			status = fiber.StatusBadRequest
-		} else if errors.Is(err, gorm.ErrRecordNotFound) {
-			status = fiber.StatusNotFound
+			return sendValidationError(c, err)
 		}
-		return utils.SendError(c, status, err.Error())
+		return h.handleError(c, err)
 	}
 
 	return utils.SendSuccessWithStatus(c, fiber.StatusCreated, "reply created", reply)
 }
 
+// handleError answers a discussion service error. Missing threads come
+// through from the repository as gorm.ErrRecordNotFound.
+func (h *DiscussionHandler) handleError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.SendAPIError(c, utils.APIError{Status: fiber.StatusNotFound, Code: "THREAD_NOT_FOUND", Message: "thread not found"})
+	}
+	return sendServiceError(c, h.logger, err)
+}
+
 func parseUintParamValue(c *fiber.Ctx, key string) (uint64, error) {
 	value := strings.TrimSpace(c.Params(key))
 	if value == "" {
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -46,8 +47,15 @@ func (h *GalleryHandler) list(c *fiber.Ctx) error {
 	}
 	tags := parseTags(c.Query("tags"))
 	search := strings.TrimSpace(c.Query("search"))
+	featured := false
+	if raw := c.Query("featured"); raw != "" {
+		featured, err = strconv.ParseBool(raw)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "invalid featured flag")
+		}
+	}
 
-	result, err := h.service.List(c.Context(), tags, search, page, pageSize)
+	result, err := h.service.List(c.Context(), tags, search, featured, page, pageSize)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("failed to list gallery items")
 		return utils.SendError(c, fiber.StatusInternalServerError, "failed to list gallery items")
@@ -20,15 +20,17 @@ import (
 type mockGalleryService struct {
 	lastTags     []string
 	lastSearch   string
+	lastFeatured bool
 	lastPage     int
 	lastPageSize int
 	response     dto.GalleryListResponse
 	err          error
 }
 
-func (m *mockGalleryService) List(_ context.Context, tags []string, search string, page, pageSize int) (dto.GalleryListResponse, error) {
+func (m *mockGalleryService) List(_ context.Context, tags []string, search string, featured bool, page, pageSize int) (dto.GalleryListResponse, error) {
 	m.lastTags = append([]string(nil), tags...)
 	m.lastSearch = search
+	m.lastFeatured = featured
 	m.lastPage = page
 	m.lastPageSize = pageSize
 	if m.err != nil {
@@ -68,6 +70,23 @@ func TestGalleryHandler_ListSuccess(t *testing.T) {
 	require.Equal(t, "Show", svc.lastSearch)
 	require.Equal(t, 2, svc.lastPage)
 	require.Equal(t, 15, svc.lastPageSize)
+	require.False(t, svc.lastFeatured)
+}
+
+func TestGalleryHandler_FeaturedFilter(t *testing.T) {
+	svc := &mockGalleryService{}
+	logger := zerolog.New(io.Discard)
+	app := fiber.New()
+	handler.NewGalleryHandler(svc, logger).Register(app.Group("/api/gallery"))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/gallery?featured=true", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.True(t, svc.lastFeatured)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/gallery?featured=maybe", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
 }
 
 func TestGalleryHandler_InvalidPage(t *testing.T) {
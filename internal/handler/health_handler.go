@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,6 +11,9 @@ import (
 	"github.com/noah-isme/gema-go-api/internal/utils"
 )
 
+// DefaultReadinessProbeTimeout bounds a readiness probe that sets no timeout.
+const DefaultReadinessProbeTimeout = 2 * time.Second
+
 // HealthResponse represents the payload returned by the health endpoint.
 type HealthResponse struct {
 	Status      string    `json:"status"`
@@ -17,7 +22,32 @@ type HealthResponse struct {
 	Environment string    `json:"environment"`
 }
 
+// ReadinessProbe checks that one dependency is reachable. Optional probes are
+// reported but do not make the service unready.
+type ReadinessProbe struct {
+	Name     string
+	Check    func(ctx context.Context) error
+	Optional bool
+	Timeout  time.Duration
+}
+
+// DependencyStatus is the outcome of one readiness probe.
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	Optional  bool   `json:"optional,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ReadinessResponse represents the payload returned by the readiness endpoint.
+type ReadinessResponse struct {
+	Status       string                      `json:"status"`
+	Timestamp    time.Time                   `json:"timestamp"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
 // HealthCheck returns a handler that reports application health information.
+// It touches no dependencies, so it doubles as the liveness check.
 func HealthCheck(cfg config.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		payload := HealthResponse{
@@ -30,3 +60,86 @@ func HealthCheck(cfg config.Config) fiber.Handler {
 		return utils.SendSuccess(c, "service healthy", payload)
 	}
 }
+
+// ReadinessCheck returns a handler that runs every probe concurrently and
+// responds 503 with the per-dependency status when a required probe fails.
+// A failing optional probe marks the service degraded but still ready.
+func ReadinessCheck(probes ...ReadinessProbe) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		results := runReadinessProbes(c.UserContext(), probes)
+
+		payload := ReadinessResponse{
+			Status:       "ok",
+			Timestamp:    time.Now().UTC(),
+			Dependencies: make(map[string]DependencyStatus, len(probes)),
+		}
+		for i, probe := range probes {
+			result := results[i]
+			payload.Dependencies[probe.Name] = result
+			if result.Status == "ok" {
+				continue
+			}
+			if !probe.Optional {
+				payload.Status = "unavailable"
+			} else if payload.Status == "ok" {
+				payload.Status = "degraded"
+			}
+		}
+
+		if payload.Status == "unavailable" {
+			return utils.Fail(c, fiber.StatusServiceUnavailable, "service not ready", payload)
+		}
+		return utils.SendSuccess(c, "service ready", payload)
+	}
+}
+
+func runReadinessProbes(ctx context.Context, probes []ReadinessProbe) []DependencyStatus {
+	results := make([]DependencyStatus, len(probes))
+
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func(i int, probe ReadinessProbe) {
+			defer wg.Done()
+
+			timeout := probe.Timeout
+			if timeout <= 0 {
+				timeout = DefaultReadinessProbeTimeout
+			}
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := runReadinessProbe(probeCtx, probe)
+			result := DependencyStatus{
+				Status:    "ok",
+				Optional:  probe.Optional,
+				LatencyMS: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				result.Status = "down"
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}(i, probe)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runReadinessProbe returns when the probe finishes or its deadline passes,
+// so a check that ignores its context cannot hold the response.
+func runReadinessProbe(ctx context.Context, probe ReadinessProbe) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- probe.Check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
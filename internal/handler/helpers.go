@@ -12,6 +12,7 @@ import (
 
 	"github.com/noah-isme/gema-go-api/internal/middleware"
 	"github.com/noah-isme/gema-go-api/internal/service"
+	"github.com/noah-isme/gema-go-api/internal/utils"
 )
 
 func splitAndTrim(input string) []string {
@@ -33,7 +34,7 @@ func parseQueryInt(c *fiber.Ctx, key string) (int, error) {
 	}
 	parsed, err := strconv.Atoi(value)
 	if err != nil {
-		return 0, err
+		return 0, fmt.Errorf("invalid %s", key)
 	}
 	return parsed, nil
 }
@@ -62,6 +63,15 @@ func userRoleFromContext(c *fiber.Ctx) string {
 	return ""
 }
 
+func userClassFromContext(c *fiber.Ctx) string {
+	if v := c.Locals("user_class"); v != nil {
+		if class, ok := v.(string); ok {
+			return class
+		}
+	}
+	return ""
+}
+
 func userIDStringFromContext(c *fiber.Ctx) string {
 	if v := c.Locals("user_id"); v != nil {
 		switch id := v.(type) {
@@ -81,6 +91,21 @@ func userIDStringFromContext(c *fiber.Ctx) string {
 	return ""
 }
 
+// isStaffRequest reports whether the caller is a teacher or admin.
+func isStaffRequest(c *fiber.Ctx) bool {
+	return service.IsStaffRole(userRoleFromContext(c))
+}
+
+// authorizeSelfOrStaff reports whether the caller may read targetID's data:
+// students only their own, teachers and admins anyone's.
+func authorizeSelfOrStaff(c *fiber.Ctx, targetID uint) bool {
+	if isStaffRequest(c) {
+		return true
+	}
+	userID := userIDFromContext(c)
+	return userID != 0 && userID == targetID
+}
+
 func activityActorFromContext(c *fiber.Ctx) service.ActivityActor {
 	return service.ActivityActor{
 		ID:   userIDFromContext(c),
@@ -102,3 +127,28 @@ func isValidationError(err error) bool {
 	var validationErrors validator.ValidationErrors
 	return errors.As(err, &validationErrors)
 }
+
+// sendValidationError answers a validator failure with a 400 listing each
+// invalid field, its rule and a readable message.
+func sendValidationError(c *fiber.Ctx, err error) error {
+	apiErr, ok := utils.ValidationAPIError(err)
+	if !ok {
+		return utils.SendAPIError(c, utils.APIError{Status: fiber.StatusBadRequest, Code: utils.CodeInvalidRequest, Message: "invalid payload"})
+	}
+	return utils.SendAPIError(c, apiErr)
+}
+
+func fileTypeDetails(err *service.FileTypeError) fiber.Map {
+	return fiber.Map{
+		"detected_type": err.Detected,
+		"allowed_types": err.Allowed,
+	}
+}
+
+func sizeLimitDetails(err *service.SizeLimitError) fiber.Map {
+	return fiber.Map{
+		"size_bytes":  err.Size,
+		"limit_bytes": err.Limit,
+		"scope":       err.Scope,
+	}
+}
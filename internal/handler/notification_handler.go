@@ -4,19 +4,25 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 
+	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/middleware"
 	"github.com/noah-isme/gema-go-api/internal/observability"
 	"github.com/noah-isme/gema-go-api/internal/service"
 	"github.com/noah-isme/gema-go-api/internal/utils"
 )
 
+// sseReconnectDelay is the retry hint sent when a stream closes for shutdown.
+const sseReconnectDelay = time.Second
+
 // NotificationHandler manages SSE notification streams and CRUD operations.
 type NotificationHandler struct {
 	service service.NotificationService
@@ -38,6 +44,9 @@ func (h *NotificationHandler) Register(router fiber.Router) {
 	router.Get("/", h.list)
 	router.Get("/stream", h.stream)
 	router.Patch("/:id/read", h.markRead)
+	router.Get("/mutes", h.listMutes)
+	router.Put("/mutes/:type", h.mute)
+	router.Delete("/mutes/:type", h.unmute)
 }
 
 func (h *NotificationHandler) list(c *fiber.Ctx) error {
@@ -61,9 +70,19 @@ func (h *NotificationHandler) list(c *fiber.Ctx) error {
 	}
 	ctx = middleware.ContextWithCorrelation(ctx, middleware.GetCorrelationID(c))
 
+	// A cursor parameter, even an empty one for the first page, switches to
+	// keyset pagination; the next page's cursor is returned in meta.
+	if c.Context().QueryArgs().Has("cursor") {
+		notifications, next, err := h.service.ListAfter(ctx, userID, c.Query("cursor"), limit)
+		if err != nil {
+			return sendServiceError(c, h.logger, err)
+		}
+		return utils.OK(c, notifications, "notifications", dto.CursorMeta{NextCursor: next})
+	}
+
 	notifications, err := h.service.List(ctx, userID, limit, offset)
 	if err != nil {
-		return utils.SendError(c, fiber.StatusInternalServerError, err.Error())
+		return sendServiceError(c, h.logger, err)
 	}
 
 	return utils.SendSuccess(c, "notifications", notifications)
@@ -107,6 +126,9 @@ func (h *NotificationHandler) stream(c *fiber.Ctx) error {
 			select {
 			case notification, ok := <-stream:
 				if !ok {
+					// The service is draining for shutdown; ask the client to
+					// reconnect, which lands it on another instance.
+					_ = writeReconnectEvent(w)
 					return
 				}
 				if err := writeNotificationEvent(w, notification); err != nil {
@@ -153,12 +175,57 @@ func (h *NotificationHandler) markRead(c *fiber.Ctx) error {
 
 	notification, err := h.service.MarkRead(ctx, uint(parsed), userID)
 	if err != nil {
-		return utils.SendError(c, fiber.StatusInternalServerError, err.Error())
+		return sendServiceError(c, h.logger, err)
 	}
 
 	return utils.SendSuccess(c, "notification updated", notification)
 }
 
+func (h *NotificationHandler) listMutes(c *fiber.Ctx) error {
+	userID := userIDStringFromContext(c)
+	if userID == "" {
+		return utils.SendError(c, fiber.StatusUnauthorized, "user not authenticated")
+	}
+
+	mutes, err := h.service.ListMutes(c.UserContext(), userID)
+	if err != nil {
+		return sendServiceError(c, h.logger, err)
+	}
+
+	return utils.SendSuccess(c, "notification mutes", mutes)
+}
+
+func (h *NotificationHandler) mute(c *fiber.Ctx) error {
+	return h.updateMute(c, h.service.Mute, "notification type muted")
+}
+
+func (h *NotificationHandler) unmute(c *fiber.Ctx) error {
+	return h.updateMute(c, h.service.Unmute, "notification type unmuted")
+}
+
+func (h *NotificationHandler) updateMute(c *fiber.Ctx, update func(context.Context, string, string) (dto.NotificationMuteResponse, error), message string) error {
+	userID := userIDStringFromContext(c)
+	if userID == "" {
+		return utils.SendError(c, fiber.StatusUnauthorized, "user not authenticated")
+	}
+
+	notificationType, err := url.PathUnescape(c.Params("type"))
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, service.ErrNotificationTypeInvalid.Error())
+	}
+
+	mutes, err := update(c.UserContext(), userID, notificationType)
+	if err != nil {
+		if errors.Is(err, service.ErrNotificationTypeInvalid) {
+			return sendServiceError(c, h.logger, err)
+		}
+		requestLogger(h.logger, c).Error().Err(err).Str("type", notificationType).Msg("failed to update notification mute")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to update notification mute")
+	}
+
+	return utils.SendSuccess(c, message, mutes)
+}
+
 func writeNotificationEvent(w *bufio.Writer, notification interface{}) error {
 	payload, err := json.Marshal(notification)
 	if err != nil {
@@ -174,6 +241,15 @@ func writeNotificationEvent(w *bufio.Writer, notification interface{}) error {
 	return w.Flush()
 }
 
+// writeReconnectEvent tells the client the stream is closing on purpose and
+// how soon to reconnect.
+func writeReconnectEvent(w *bufio.Writer) error {
+	if _, err := fmt.Fprintf(w, "event: reconnect\nretry: %d\ndata: {}\n\n", sseReconnectDelay.Milliseconds()); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
 func writeKeepAlive(w *bufio.Writer) error {
 	if _, err := fmt.Fprintf(w, ": keep-alive %s\n\n", time.Now().UTC().Format(time.RFC3339)); err != nil {
 		return err
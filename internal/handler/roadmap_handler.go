@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 
@@ -23,9 +26,11 @@ func NewRoadmapHandler(service service.RoadmapService, logger zerolog.Logger) *R
 	}
 }
 
-// Register wires roadmap routes.
-func (h *RoadmapHandler) Register(router fiber.Router) {
+// Register wires roadmap routes. requireStudent guards the progress routes;
+// listing stays public and includes completion status for students.
+func (h *RoadmapHandler) Register(router fiber.Router, requireStudent ...fiber.Handler) {
 	router.Get("/stages", h.listStages)
+	router.Post("/stages/:id/complete", append(requireStudent, h.completeStage)...)
 }
 
 func (h *RoadmapHandler) listStages(c *fiber.Ctx) error {
@@ -50,6 +55,9 @@ func (h *RoadmapHandler) listStages(c *fiber.Ctx) error {
 		Search:   c.Query("search"),
 		Tags:     splitAndTrim(c.Query("tags")),
 	}
+	if strings.EqualFold(userRoleFromContext(c), "student") {
+		req.StudentID = userIDFromContext(c)
+	}
 
 	result, err := h.service.ListStages(c.Context(), req)
 	if err != nil {
@@ -65,3 +73,25 @@ func (h *RoadmapHandler) listStages(c *fiber.Ctx) error {
 
 	return utils.OK(c, result.Items, "roadmap stages retrieved", meta)
 }
+
+func (h *RoadmapHandler) completeStage(c *fiber.Ctx) error {
+	studentID := userIDFromContext(c)
+	if studentID == 0 {
+		return utils.SendError(c, fiber.StatusUnauthorized, "authentication required")
+	}
+	stageID, err := parseUintParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid identifier")
+	}
+
+	progress, err := h.service.MarkStageComplete(c.Context(), studentID, stageID)
+	if err != nil {
+		if errors.Is(err, service.ErrRoadmapStageNotFound) {
+			return utils.SendError(c, fiber.StatusNotFound, "roadmap stage not found")
+		}
+		h.logger.Error().Err(err).Uint("stage_id", stageID).Msg("failed to mark roadmap stage complete")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to record roadmap progress")
+	}
+
+	return utils.SendSuccess(c, "roadmap stage completed", progress)
+}
@@ -13,17 +13,29 @@ import (
 
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/handler"
+	"github.com/noah-isme/gema-go-api/internal/service"
 )
 
 type stubRoadmapService struct {
-	result dto.RoadmapStageListResult
-	err    error
+	result   dto.RoadmapStageListResult
+	err      error
+	lastList *dto.RoadmapStageListRequest
 }
 
-func (s stubRoadmapService) ListStages(context.Context, dto.RoadmapStageListRequest) (dto.RoadmapStageListResult, error) {
+func (s stubRoadmapService) ListStages(_ context.Context, req dto.RoadmapStageListRequest) (dto.RoadmapStageListResult, error) {
+	if s.lastList != nil {
+		*s.lastList = req
+	}
 	return s.result, s.err
 }
 
+func (s stubRoadmapService) MarkStageComplete(_ context.Context, _ uint, stageID uint) (dto.RoadmapProgressResponse, error) {
+	if stageID == 404 {
+		return dto.RoadmapProgressResponse{}, service.ErrRoadmapStageNotFound
+	}
+	return dto.RoadmapProgressResponse{StageID: stageID, CompletedAt: time.Now()}, s.err
+}
+
 func TestRoadmapHandlerListStages(t *testing.T) {
 	app := fiber.New()
 	result := dto.RoadmapStageListResult{
@@ -49,3 +61,27 @@ func TestRoadmapHandlerListStages(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 }
+
+func TestRoadmapHandlerCompleteStage(t *testing.T) {
+	var lastList dto.RoadmapStageListRequest
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", uint(42))
+		c.Locals("user_role", "student")
+		return c.Next()
+	})
+	handler.NewRoadmapHandler(stubRoadmapService{lastList: &lastList}, zerolog.Nop()).Register(app.Group("/api/roadmap"))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/api/roadmap/stages/3/complete", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/api/roadmap/stages/404/complete", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/roadmap/stages", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, uint(42), lastList.StudentID, "students get their completion status")
+}
@@ -25,6 +25,7 @@ func NewSeedHandler(service service.SeedService, logger zerolog.Logger) *SeedHan
 
 // Register wires seed routes.
 func (h *SeedHandler) Register(router fiber.Router) {
+	router.Get("/status", h.status)
 	router.Post("/announcements", h.announcements)
 	router.Post("/gallery", h.gallery)
 }
@@ -37,34 +38,57 @@ type seedGalleryRequest struct {
 	Items []models.GalleryItem `json:"items"`
 }
 
+// announcements seeds the posted items, or the bundled announcements when the
+// body is empty. dry_run=true reports the changes without writing them.
 func (h *SeedHandler) announcements(c *fiber.Ctx) error {
 	token := c.Get("X-Seed-Token")
 	var payload seedAnnouncementsRequest
-	if err := c.BodyParser(&payload); err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&payload); err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+		}
 	}
 
-	affected, err := h.service.SeedAnnouncements(c.Context(), token, payload.Items)
+	result, err := h.service.SeedAnnouncements(c.Context(), token, payload.Items, c.QueryBool("dry_run"))
 	if err != nil {
 		return h.seedError(c, err)
 	}
 
-	return utils.SendSuccess(c, "announcements seeded", fiber.Map{"affected": affected})
+	return utils.SendSuccess(c, seedMessage("announcements", result.DryRun), result)
 }
 
+// gallery seeds the posted items, or the bundled gallery when the body is
+// empty. dry_run=true reports the changes without writing them.
 func (h *SeedHandler) gallery(c *fiber.Ctx) error {
 	token := c.Get("X-Seed-Token")
 	var payload seedGalleryRequest
-	if err := c.BodyParser(&payload); err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&payload); err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+		}
 	}
 
-	affected, err := h.service.SeedGallery(c.Context(), token, payload.Items)
+	result, err := h.service.SeedGallery(c.Context(), token, payload.Items, c.QueryBool("dry_run"))
 	if err != nil {
 		return h.seedError(c, err)
 	}
 
-	return utils.SendSuccess(c, "gallery seeded", fiber.Map{"affected": affected})
+	return utils.SendSuccess(c, seedMessage("gallery", result.DryRun), result)
+}
+
+func (h *SeedHandler) status(c *fiber.Ctx) error {
+	status, err := h.service.Status(c.Context())
+	if err != nil {
+		return h.seedError(c, err)
+	}
+	return utils.SendSuccess(c, "seed status retrieved", status)
+}
+
+func seedMessage(contentType string, dryRun bool) string {
+	if dryRun {
+		return contentType + " seed dry run"
+	}
+	return contentType + " seeded"
 }
 
 func (h *SeedHandler) seedError(c *fiber.Ctx, err error) error {
@@ -14,6 +14,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
 
+	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/handler"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/service"
@@ -25,25 +26,32 @@ type mockSeedService struct {
 	lastToken         string
 	lastAnnouncements []models.Announcement
 	lastGallery       []models.GalleryItem
+	lastDryRun        bool
 	affected          int64
 }
 
-func (m *mockSeedService) SeedAnnouncements(_ context.Context, token string, items []models.Announcement) (int64, error) {
+func (m *mockSeedService) SeedAnnouncements(_ context.Context, token string, items []models.Announcement, dryRun bool) (dto.SeedResult, error) {
 	m.lastToken = token
 	m.lastAnnouncements = items
+	m.lastDryRun = dryRun
 	if m.announcementsErr != nil {
-		return 0, m.announcementsErr
+		return dto.SeedResult{}, m.announcementsErr
 	}
-	return m.affected, nil
+	return dto.SeedResult{ContentType: "announcements", Created: len(items), Affected: m.affected, DryRun: dryRun}, nil
 }
 
-func (m *mockSeedService) SeedGallery(_ context.Context, token string, items []models.GalleryItem) (int64, error) {
+func (m *mockSeedService) SeedGallery(_ context.Context, token string, items []models.GalleryItem, dryRun bool) (dto.SeedResult, error) {
 	m.lastToken = token
 	m.lastGallery = items
+	m.lastDryRun = dryRun
 	if m.galleryErr != nil {
-		return 0, m.galleryErr
+		return dto.SeedResult{}, m.galleryErr
 	}
-	return m.affected, nil
+	return dto.SeedResult{ContentType: "gallery", Created: len(items), Affected: m.affected, DryRun: dryRun}, nil
+}
+
+func (m *mockSeedService) Status(context.Context) (dto.SeedStatus, error) {
+	return dto.SeedStatus{Seeded: true}, nil
 }
 
 func TestSeedHandler_AnnouncementsSuccess(t *testing.T) {
@@ -68,12 +76,14 @@ func TestSeedHandler_AnnouncementsSuccess(t *testing.T) {
 		Success bool `json:"success"`
 		Data    struct {
 			Affected int64 `json:"affected"`
+			Created  int   `json:"created"`
 		} `json:"data"`
 	}
 	decodeResponse(t, resp, &response)
 
 	require.True(t, response.Success)
 	require.Equal(t, int64(2), response.Data.Affected)
+	require.Equal(t, 1, response.Data.Created)
 	require.Equal(t, "secret", svc.lastToken)
 	require.Len(t, svc.lastAnnouncements, 1)
 }
@@ -134,3 +144,27 @@ func TestSeedHandler_InvalidPayload(t *testing.T) {
 	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
 	require.Nil(t, svc.lastAnnouncements)
 }
+
+func TestSeedHandler_DryRunWithBundledContent(t *testing.T) {
+	svc := &mockSeedService{}
+	logger := zerolog.New(io.Discard)
+	app := fiber.New()
+	handler.NewSeedHandler(svc, logger).Register(app.Group("/api/seed"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/seed/gallery?dry_run=true", nil)
+	req.Header.Set("X-Seed-Token", "secret")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Message string         `json:"message"`
+		Data    dto.SeedResult `json:"data"`
+	}
+	decodeResponse(t, resp, &response)
+	require.Equal(t, "gallery seed dry run", response.Message)
+	require.True(t, response.Data.DryRun)
+	require.True(t, svc.lastDryRun)
+	require.Empty(t, svc.lastGallery)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 
+	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/middleware"
 	"github.com/noah-isme/gema-go-api/internal/service"
 	"github.com/noah-isme/gema-go-api/internal/utils"
@@ -27,21 +29,65 @@ func NewStudentDashboardHandler(service service.StudentDashboardService, logger
 	}
 }
 
-// Register attaches the dashboard endpoint.
+// Register attaches the dashboard endpoints. Students read their own
+// dashboard at /dashboard; /dashboard/:id lets teachers and admins read any
+// student's and students only their own.
 func (h *StudentDashboardHandler) Register(router fiber.Router) {
 	router.Get("/dashboard", middleware.WithAuth(h.getDashboard, middleware.AuthOptions{
 		Role: middleware.AuthRoleStudent,
 	}))
+	router.Get("/dashboard/:id", middleware.WithAuth(h.getStudentDashboard, middleware.AuthOptions{
+		Role: middleware.AuthRoleAny,
+	}))
 }
 
 func (h *StudentDashboardHandler) getDashboard(c *fiber.Ctx) error {
 	studentID, err := extractUserID(c)
 	if err != nil {
-		return utils.Fail(c, fiber.StatusUnauthorized, err.Error(), fiber.Map{"field": "user_id"})
+		return utils.SendAPIError(c, utils.APIError{Status: fiber.StatusUnauthorized, Code: "UNAUTHORIZED", Message: err.Error(), Details: fiber.Map{"field": "user_id"}})
 	}
 
-	dashboard, cacheHit, err := h.service.GetDashboard(c.Context(), studentID)
+	return h.respond(c, studentID)
+}
+
+func (h *StudentDashboardHandler) getStudentDashboard(c *fiber.Ctx) error {
+	studentID, err := parseUintParam(c, "id")
 	if err != nil {
+		return utils.SendAPIError(c, utils.APIError{Status: fiber.StatusBadRequest, Code: utils.CodeInvalidRequest, Message: err.Error(), Details: fiber.Map{"field": "id"}})
+	}
+	if !authorizeSelfOrStaff(c, studentID) {
+		return utils.Fail(c, fiber.StatusForbidden, "insufficient permissions", nil)
+	}
+
+	return h.respond(c, studentID)
+}
+
+func (h *StudentDashboardHandler) respond(c *fiber.Ctx, studentID uint) error {
+	dueWithin, err := parseQueryInt(c, "due_within_days")
+	if err != nil {
+		return utils.Fail(c, fiber.StatusBadRequest, "invalid due_within_days", fiber.Map{"due_within_days": fmt.Sprintf("1-%d", service.DashboardMaxDueWithinDays)})
+	}
+
+	query := dto.StudentDashboardQuery{
+		Sort:          c.Query("sort"),
+		Group:         c.Query("group"),
+		DueWithinDays: dueWithin,
+	}
+
+	dashboard, cacheHit, err := h.service.GetDashboard(c.Context(), studentID, query)
+	if err != nil {
+		if errors.Is(err, service.ErrDashboardQueryInvalid) {
+			return utils.SendAPIError(c, utils.APIError{
+				Status:  fiber.StatusBadRequest,
+				Code:    "DASHBOARD_QUERY_INVALID",
+				Message: err.Error(),
+				Details: fiber.Map{
+					"sort":            []string{service.DashboardSortDueDate, service.DashboardSortDueDateDesc, service.DashboardSortTitle, service.DashboardSortUpdatedAt},
+					"group":           []string{service.DashboardGroupNone, service.DashboardGroupOverdueFirst},
+					"due_within_days": fmt.Sprintf("1-%d", service.DashboardMaxDueWithinDays),
+				},
+			})
+		}
 		h.logger.Error().Err(err).Uint("student_id", studentID).Msg("failed to load dashboard")
 		return utils.Fail(c, fiber.StatusInternalServerError, "failed to load dashboard", nil)
 	}
@@ -18,16 +18,18 @@ import (
 )
 
 type stubStudentDashboardService struct {
-	response dto.StudentDashboardResponse
-	err      error
-	calls    int
-	lastID   uint
-	cacheHit bool
+	response  dto.StudentDashboardResponse
+	err       error
+	calls     int
+	lastID    uint
+	lastQuery dto.StudentDashboardQuery
+	cacheHit  bool
 }
 
-func (s *stubStudentDashboardService) GetDashboard(_ context.Context, studentID uint) (dto.StudentDashboardResponse, bool, error) {
+func (s *stubStudentDashboardService) GetDashboard(_ context.Context, studentID uint, query dto.StudentDashboardQuery) (dto.StudentDashboardResponse, bool, error) {
 	s.calls++
 	s.lastID = studentID
+	s.lastQuery = query
 	if s.err != nil {
 		return dto.StudentDashboardResponse{}, false, s.err
 	}
@@ -53,7 +55,7 @@ func TestStudentDashboardHandler_Success(t *testing.T) {
 	})
 	handler.NewStudentDashboardHandler(svc, logger).Register(group)
 
-	req := httptest.NewRequest(http.MethodGet, "/api/v2/student/dashboard", nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/student/dashboard?sort=title&group=overdue_first", nil)
 	resp, err := app.Test(req, -1)
 	require.NoError(t, err)
 	require.Equal(t, fiber.StatusOK, resp.StatusCode)
@@ -71,6 +73,7 @@ func TestStudentDashboardHandler_Success(t *testing.T) {
 	require.Equal(t, "dashboard retrieved", payload.Message)
 	require.Equal(t, response.Summary.TotalAssignments, payload.Data.Summary.TotalAssignments)
 	require.Equal(t, uint(33), svc.lastID)
+	require.Equal(t, dto.StudentDashboardQuery{Sort: "title", Group: "overdue_first"}, svc.lastQuery)
 	require.Equal(t, 1, svc.calls)
 	require.NotNil(t, payload.Meta)
 	require.Contains(t, payload.Meta, "cache_hit")
@@ -100,4 +103,58 @@ func TestStudentDashboardHandler_Unauthorized(t *testing.T) {
 	require.Equal(t, 0, svc.calls)
 }
 
+func TestStudentDashboardHandler_DueWindow(t *testing.T) {
+	svc := &stubStudentDashboardService{}
+	app := fiber.New()
+	group := app.Group("/api/v2/student", func(c *fiber.Ctx) error {
+		c.Locals("user_id", uint(33))
+		c.Locals("user_role", "student")
+		return c.Next()
+	})
+	handler.NewStudentDashboardHandler(svc, zerolog.Nop()).Register(group)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v2/student/dashboard?due_within_days=3", nil), -1)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, 3, svc.lastQuery.DueWithinDays)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/v2/student/dashboard?due_within_days=soon", nil), -1)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, 1, svc.calls)
+}
+
+func TestStudentDashboardHandler_ByStudentID(t *testing.T) {
+	svc := &stubStudentDashboardService{}
+	role := "student"
+	app := fiber.New()
+	group := app.Group("/api/v2/student", func(c *fiber.Ctx) error {
+		c.Locals("user_id", uint(33))
+		c.Locals("user_role", role)
+		return c.Next()
+	})
+	handler.NewStudentDashboardHandler(svc, zerolog.Nop()).Register(group)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v2/student/dashboard/34", nil), -1)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	require.Equal(t, 0, svc.calls)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/v2/student/dashboard/33", nil), -1)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, uint(33), svc.lastID)
+
+	role = "teacher"
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/v2/student/dashboard/34", nil), -1)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, uint(34), svc.lastID)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/v2/student/dashboard/abc", nil), -1)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, 2, svc.calls)
+}
+
 var _ service.StudentDashboardService = (*stubStudentDashboardService)(nil)
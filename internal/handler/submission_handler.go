@@ -33,7 +33,10 @@ func NewSubmissionHandler(service service.SubmissionService, validator *validato
 func (h *SubmissionHandler) Register(router fiber.Router) {
 	router.Get("", h.list)
 	router.Post("", h.create)
+	router.Get("/:id/versions", h.versions)
+	router.Get("/:id/download", h.download)
 	router.Patch("/:id", h.update)
+	router.Delete("/:id", h.withdraw)
 }
 
 func (h *SubmissionHandler) list(c *fiber.Ctx) error {
@@ -48,7 +51,12 @@ func (h *SubmissionHandler) list(c *fiber.Ctx) error {
 		filter.Status = &status
 	}
 
-	submissions, err := h.service.List(c.Context(), filter)
+	viewerID := userIDFromContext(c)
+	if viewerID == 0 {
+		return utils.SendError(c, fiber.StatusUnauthorized, "user not authenticated")
+	}
+
+	submissions, err := h.service.List(c.Context(), filter, viewerID, userRoleFromContext(c))
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -60,15 +68,16 @@ func (h *SubmissionHandler) create(c *fiber.Ctx) error {
 	var payload dto.SubmissionCreateRequest
 	assignmentID, err := parseFormUint(c, "assignment_id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 	studentID, err := parseFormUint(c, "student_id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	payload.AssignmentID = *assignmentID
 	payload.StudentID = *studentID
+	payload.Role = userRoleFromContext(c)
 
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -86,7 +95,7 @@ func (h *SubmissionHandler) create(c *fiber.Ctx) error {
 func (h *SubmissionHandler) update(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	var payload dto.SubmissionUpdateRequest
@@ -99,22 +108,72 @@ func (h *SubmissionHandler) update(c *fiber.Ctx) error {
 		return h.handleError(c, err)
 	}
 
-	return utils.SendSuccess(c, "submission updated", submission)
+	return utils.SendSuccess(c, "submission updated", submission.VisibleTo(userIDFromContext(c)))
+}
+
+func (h *SubmissionHandler) withdraw(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	studentID := userIDFromContext(c)
+	if studentID == 0 {
+		return utils.SendError(c, fiber.StatusUnauthorized, "user not authenticated")
+	}
+
+	if err := h.service.Withdraw(c.Context(), id, studentID); err != nil {
+		return h.handleError(c, err)
+	}
+
+	return utils.SendSuccess(c, "submission withdrawn", nil)
+}
+
+func (h *SubmissionHandler) versions(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	viewerID := userIDFromContext(c)
+	if viewerID == 0 {
+		return utils.SendError(c, fiber.StatusUnauthorized, "user not authenticated")
+	}
+
+	versions, err := h.service.ListVersions(c.Context(), id, viewerID, userRoleFromContext(c))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return utils.SendSuccess(c, "submission versions retrieved", versions)
+}
+
+func (h *SubmissionHandler) download(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	viewerID := userIDFromContext(c)
+	if viewerID == 0 {
+		return utils.SendError(c, fiber.StatusUnauthorized, "user not authenticated")
+	}
+
+	download, err := h.service.Download(c.Context(), id, viewerID, userRoleFromContext(c))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return utils.SendSuccess(c, "submission download ready", download)
 }
 
 func (h *SubmissionHandler) handleError(c *fiber.Ctx, err error) error {
-	var validationErrors validator.ValidationErrors
-	switch {
-	case errors.Is(err, service.ErrAssignmentNotFound):
-		return utils.SendError(c, fiber.StatusNotFound, "assignment not found")
-	case errors.Is(err, service.ErrSubmissionNotFound):
-		return utils.SendError(c, fiber.StatusNotFound, "submission not found")
-	case errors.As(err, &validationErrors):
-		return utils.SendError(c, fiber.StatusBadRequest, validationErrors.Error())
-	default:
-		h.logger.Error().Err(err).Msg("internal server error")
-		return utils.SendError(c, fiber.StatusInternalServerError, "internal server error")
+	if apiErr, ok := apiErrorFor(err); ok {
+		return utils.SendAPIError(c, apiErr)
 	}
+	h.logger.Error().Err(err).Msg("internal server error")
+	return utils.SendAPIError(c, internalAPIError())
 }
 
 func parseQueryUint(c *fiber.Ctx, key string) (*uint, error) {
@@ -124,7 +183,7 @@ func parseQueryUint(c *fiber.Ctx, key string) (*uint, error) {
 	}
 	parsed, err := strconv.ParseUint(value, 10, 64)
 	if err != nil {
-		return nil, err
+		return nil, errors.New("invalid " + key)
 	}
 	result := uint(parsed)
 	return &result, nil
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
@@ -33,8 +34,19 @@ func (s *submissionTestUploader) Upload(_ context.Context, name string, _ io.Rea
 	return "https://files.test/" + name, nil
 }
 
+// submissionViewer is the caller the test app authenticates requests as.
+type submissionViewer struct {
+	id   uint
+	role string
+}
+
 func setupSubmissionApp(t *testing.T) (*fiber.App, *gorm.DB) {
 	t.Helper()
+	return setupSubmissionAppAs(t, &submissionViewer{id: 1})
+}
+
+func setupSubmissionAppAs(t *testing.T, viewer *submissionViewer) (*fiber.App, *gorm.DB) {
+	t.Helper()
 
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	require.NoError(t, err)
@@ -48,7 +60,7 @@ func setupSubmissionApp(t *testing.T) (*fiber.App, *gorm.DB) {
 	submissionRepo := repository.NewSubmissionRepository(db)
 
 	assignmentService := service.NewAssignmentService(assignmentRepo, validate, uploader, logger)
-	submissionService := service.NewSubmissionService(submissionRepo, assignmentRepo, validate, uploader, logger)
+	submissionService := service.NewSubmissionService(submissionRepo, assignmentRepo, validate, uploader, nil, nil, service.SubmissionSizeLimits{}, nil, nil, logger)
 
 	app := fiber.New()
 	assignmentHandler := handler.NewAssignmentHandler(assignmentService, validate, logger)
@@ -58,7 +70,10 @@ func setupSubmissionApp(t *testing.T) (*fiber.App, *gorm.DB) {
 		AssignmentHandler: assignmentHandler,
 		SubmissionHandler: submissionHandler,
 		JWTMiddleware: func(c *fiber.Ctx) error {
-			c.Locals("user_id", uint(1))
+			c.Locals("user_id", viewer.id)
+			if viewer.role != "" {
+				c.Locals("user_role", viewer.role)
+			}
 			return c.Next()
 		},
 	})
@@ -134,3 +149,241 @@ func TestSubmissionHandlerUploadAndGrade(t *testing.T) {
 	require.Equal(t, 95.0, *updateBody.Data.Grade)
 	require.Equal(t, "graded", updateBody.Data.Status)
 }
+
+func TestSubmissionHandlerResubmissionVersions(t *testing.T) {
+	viewer := &submissionViewer{role: "student"}
+	app, db := setupSubmissionAppAs(t, viewer)
+
+	student := models.Student{Name: "Rita", Email: "rita.versions@example.com"}
+	require.NoError(t, db.Create(&student).Error)
+	viewer.id = student.ID
+
+	assignment := models.Assignment{
+		Title:       "Essay Draft",
+		Description: "Submit drafts",
+		DueDate:     time.Now().Add(3 * time.Hour),
+	}
+	require.NoError(t, db.Create(&assignment).Error)
+
+	submit := func(name string) dto.SubmissionResponse {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		require.NoError(t, writer.WriteField("assignment_id", strconv.FormatUint(uint64(assignment.ID), 10)))
+		require.NoError(t, writer.WriteField("student_id", strconv.FormatUint(uint64(student.ID), 10)))
+		part, err := writer.CreateFormFile("file", name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte("draft"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest("POST", "/api/v2/tutorial/submissions", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var payload struct {
+			Data dto.SubmissionResponse `json:"data"`
+		}
+		decodeResponse(t, resp, &payload)
+		return payload.Data
+	}
+
+	first := submit("draft-1.txt")
+	second := submit("draft-2.txt")
+	require.Equal(t, 1, first.Version)
+	require.Equal(t, 2, second.Version)
+
+	listReq := httptest.NewRequest("GET", "/api/v2/tutorial/submissions?student_id="+strconv.FormatUint(uint64(student.ID), 10), nil)
+	listResp, err := app.Test(listReq)
+	require.NoError(t, err)
+	var listBody struct {
+		Data []dto.SubmissionResponse `json:"data"`
+	}
+	decodeResponse(t, listResp, &listBody)
+	require.Len(t, listBody.Data, 1)
+	require.Equal(t, second.ID, listBody.Data[0].ID)
+	require.Equal(t, "https://files.test/draft-2.txt", listBody.Data[0].FileURL)
+
+	versionsReq := httptest.NewRequest("GET", "/api/v2/tutorial/submissions/"+strconv.FormatUint(uint64(first.ID), 10)+"/versions", nil)
+	versionsResp, err := app.Test(versionsReq)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, versionsResp.StatusCode)
+	var versionsBody struct {
+		Data []dto.SubmissionResponse `json:"data"`
+	}
+	decodeResponse(t, versionsResp, &versionsBody)
+	require.Len(t, versionsBody.Data, 2)
+	require.Equal(t, 2, versionsBody.Data[0].Version)
+	require.Equal(t, "https://files.test/draft-1.txt", versionsBody.Data[1].FileURL)
+
+	viewer.id = student.ID + 1000
+	otherResp, err := app.Test(httptest.NewRequest("GET", "/api/v2/tutorial/submissions/"+strconv.FormatUint(uint64(first.ID), 10)+"/versions", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, otherResp.StatusCode, "other students cannot read someone else's version history")
+
+	otherListResp, err := app.Test(httptest.NewRequest("GET", "/api/v2/tutorial/submissions?student_id="+strconv.FormatUint(uint64(student.ID), 10), nil))
+	require.NoError(t, err)
+	var otherList struct {
+		Data []dto.SubmissionResponse `json:"data"`
+	}
+	decodeResponse(t, otherListResp, &otherList)
+	require.Empty(t, otherList.Data, "students only list their own submissions")
+
+	viewer.role = "teacher"
+	teacherResp, err := app.Test(httptest.NewRequest("GET", "/api/v2/tutorial/submissions/"+strconv.FormatUint(uint64(first.ID), 10)+"/versions", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, teacherResp.StatusCode)
+	var teacherVersions struct {
+		Data []dto.SubmissionResponse `json:"data"`
+	}
+	decodeResponse(t, teacherResp, &teacherVersions)
+	require.Len(t, teacherVersions.Data, 2)
+	require.Empty(t, teacherVersions.Data[0].FileURL, "staff fetch files through the download endpoint")
+
+	teacherListResp, err := app.Test(httptest.NewRequest("GET", "/api/v2/tutorial/submissions?student_id="+strconv.FormatUint(uint64(student.ID), 10), nil))
+	require.NoError(t, err)
+	var teacherList struct {
+		Data []dto.SubmissionResponse `json:"data"`
+	}
+	decodeResponse(t, teacherListResp, &teacherList)
+	require.Len(t, teacherList.Data, 1)
+	require.Empty(t, teacherList.Data[0].FileURL)
+}
+
+func TestSubmissionHandlerLatePolicy(t *testing.T) {
+	app, db := setupSubmissionApp(t)
+
+	student := models.Student{Name: "Lars", Email: "lars.late@example.com"}
+	require.NoError(t, db.Create(&student).Error)
+
+	closed := models.Assignment{Title: "Closed", Description: "No late work", DueDate: time.Now().Add(-time.Hour), LatePenaltyPercent: 50}
+	lenient := models.Assignment{Title: "Lenient", Description: "Late work ok", DueDate: time.Now().Add(-time.Hour), AllowLate: true, LatePenaltyPercent: 20}
+	require.NoError(t, db.Create(&closed).Error)
+	require.NoError(t, db.Create(&lenient).Error)
+
+	submit := func(assignmentID uint) *http.Response {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		require.NoError(t, writer.WriteField("assignment_id", strconv.FormatUint(uint64(assignmentID), 10)))
+		require.NoError(t, writer.WriteField("student_id", strconv.FormatUint(uint64(student.ID), 10)))
+		part, err := writer.CreateFormFile("file", "late.txt")
+		require.NoError(t, err)
+		_, err = part.Write([]byte("late work"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest("POST", "/api/v2/tutorial/submissions", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	rejected := submit(closed.ID)
+	require.Equal(t, fiber.StatusBadRequest, rejected.StatusCode)
+
+	accepted := submit(lenient.ID)
+	require.Equal(t, fiber.StatusOK, accepted.StatusCode)
+	var createResp struct {
+		Data dto.SubmissionResponse `json:"data"`
+	}
+	decodeResponse(t, accepted, &createResp)
+	require.Equal(t, models.SubmissionStatusLate, createResp.Data.Status)
+	require.True(t, createResp.Data.Late)
+	require.Equal(t, 20.0, createResp.Data.LatePenalty)
+
+	gradeBody, err := json.Marshal(map[string]interface{}{"grade": 80})
+	require.NoError(t, err)
+	updateReq := httptest.NewRequest("PATCH", "/api/v2/tutorial/submissions/"+strconv.FormatUint(uint64(createResp.Data.ID), 10), bytes.NewReader(gradeBody))
+	updateReq.Header.Set("Content-Type", "application/json")
+	updateResp, err := app.Test(updateReq)
+	require.NoError(t, err)
+	var updateBody struct {
+		Data dto.SubmissionResponse `json:"data"`
+	}
+	decodeResponse(t, updateResp, &updateBody)
+	require.Equal(t, models.SubmissionStatusGraded, updateBody.Data.Status)
+	require.True(t, updateBody.Data.Late)
+	require.NotNil(t, updateBody.Data.FinalGrade)
+	require.InDelta(t, 64.0, *updateBody.Data.FinalGrade, 0.001)
+}
+
+func TestSubmissionHandlerRejectsDisallowedFileType(t *testing.T) {
+	app, db := setupSubmissionApp(t)
+
+	student := models.Student{Name: "Mime", Email: "mime-check@example.com"}
+	require.NoError(t, db.Create(&student).Error)
+
+	assignment := models.Assignment{Title: "Essay", Description: "Write", DueDate: time.Now().Add(time.Hour)}
+	require.NoError(t, db.Create(&assignment).Error)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("assignment_id", strconv.FormatUint(uint64(assignment.ID), 10)))
+	require.NoError(t, writer.WriteField("student_id", strconv.FormatUint(uint64(student.ID), 10)))
+	part, err := writer.CreateFormFile("file", "photo.png")
+	require.NoError(t, err)
+	_, err = part.Write([]byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A})
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", "/api/v2/tutorial/submissions", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var errorBody struct {
+		Success bool `json:"success"`
+		Details struct {
+			DetectedType string   `json:"detected_type"`
+			AllowedTypes []string `json:"allowed_types"`
+		} `json:"details"`
+	}
+	decodeResponse(t, resp, &errorBody)
+	require.False(t, errorBody.Success)
+	require.Equal(t, "image/*", errorBody.Details.DetectedType)
+	require.Equal(t, service.DefaultSubmissionMimeTypes, errorBody.Details.AllowedTypes)
+}
+
+func TestSubmissionHandlerRejectsFileOverAssignmentLimit(t *testing.T) {
+	app, db := setupSubmissionApp(t)
+
+	student := models.Student{Name: "Size", Email: "size-check@example.com"}
+	require.NoError(t, db.Create(&student).Error)
+
+	assignment := models.Assignment{Title: "Notes", Description: "Upload", DueDate: time.Now().Add(time.Hour), MaxSubmissionMB: 1}
+	require.NoError(t, db.Create(&assignment).Error)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("assignment_id", strconv.FormatUint(uint64(assignment.ID), 10)))
+	require.NoError(t, writer.WriteField("student_id", strconv.FormatUint(uint64(student.ID), 10)))
+	part, err := writer.CreateFormFile("file", "notes.txt")
+	require.NoError(t, err)
+	_, err = part.Write(bytes.Repeat([]byte("a"), 2*1024*1024))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", "/api/v2/tutorial/submissions", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	var errorBody struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+		Details struct {
+			LimitBytes int64  `json:"limit_bytes"`
+			SizeBytes  int64  `json:"size_bytes"`
+			Scope      string `json:"scope"`
+		} `json:"details"`
+	}
+	decodeResponse(t, resp, &errorBody)
+	require.False(t, errorBody.Success)
+	require.Equal(t, "file is 2 MB, exceeding the 1 MB assignment limit", errorBody.Message)
+	require.Equal(t, int64(1024*1024), errorBody.Details.LimitBytes)
+	require.Equal(t, service.SizeLimitScopeAssignment, errorBody.Details.Scope)
+}
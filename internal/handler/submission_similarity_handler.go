@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/noah-isme/gema-go-api/internal/service"
+	"github.com/noah-isme/gema-go-api/internal/utils"
+)
+
+// SubmissionSimilarityHandler exposes plagiarism similarity reports to admins and teachers.
+type SubmissionSimilarityHandler struct {
+	service service.SubmissionSimilarityService
+	logger  zerolog.Logger
+}
+
+// NewSubmissionSimilarityHandler constructs the handler.
+func NewSubmissionSimilarityHandler(service service.SubmissionSimilarityService, logger zerolog.Logger) *SubmissionSimilarityHandler {
+	return &SubmissionSimilarityHandler{
+		service: service,
+		logger:  logger.With().Str("component", "submission_similarity_handler").Logger(),
+	}
+}
+
+// Register attaches similarity endpoints to the admin submissions group.
+func (h *SubmissionSimilarityHandler) Register(router fiber.Router) {
+	router.Get("/:id/similarity", h.get)
+}
+
+func (h *SubmissionSimilarityHandler) get(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid identifier")
+	}
+
+	threshold := service.DefaultSimilarityThreshold
+	if raw := c.Query("threshold"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			return utils.SendError(c, fiber.StatusBadRequest, "threshold must be between 0 and 1")
+		}
+		threshold = parsed
+	}
+
+	report, err := h.service.Matches(c.Context(), id, threshold)
+	if err != nil {
+		if errors.Is(err, service.ErrSimilarityUnavailable) {
+			return sendServiceError(c, h.logger, err)
+		}
+		requestLogger(h.logger, c).Error().Err(err).Uint("submission_id", id).Msg("failed to load similarity report")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to load similarity report")
+	}
+
+	return utils.SendSuccess(c, "submission similarity", report)
+}
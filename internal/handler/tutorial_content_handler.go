@@ -1,9 +1,6 @@
 package handler
 
 import (
-	"errors"
-	"strings"
-
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 
@@ -37,19 +34,22 @@ func (h *TutorialContentHandler) RegisterPublic(router fiber.Router) {
 // RegisterAdmin wires admin-only tutorial routes.
 func (h *TutorialContentHandler) RegisterAdmin(router fiber.Router) {
 	router.Post("/articles", h.createArticle)
+	router.Put("/articles/:id", h.updateArticle)
+	router.Delete("/articles/:id", h.deleteArticle)
 	router.Post("/projects", h.createProject)
+	router.Put("/projects/:id", h.updateProject)
+	router.Delete("/projects/:id", h.deleteProject)
 }
 
 func (h *TutorialContentHandler) listArticles(c *fiber.Ctx) error {
 	req, err := h.parseListRequest(c)
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	result, err := h.service.ListArticles(c.Context(), req)
 	if err != nil {
-		h.logger.Error().Err(err).Msg("failed to list tutorial articles")
-		return utils.SendError(c, fiber.StatusInternalServerError, "failed to list articles")
+		return sendServiceError(c, h.logger, err)
 	}
 
 	meta := fiber.Map{
@@ -63,13 +63,12 @@ func (h *TutorialContentHandler) listArticles(c *fiber.Ctx) error {
 func (h *TutorialContentHandler) listProjects(c *fiber.Ctx) error {
 	req, err := h.parseListRequest(c)
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	result, err := h.service.ListProjects(c.Context(), req)
 	if err != nil {
-		h.logger.Error().Err(err).Msg("failed to list tutorial projects")
-		return utils.SendError(c, fiber.StatusInternalServerError, "failed to list projects")
+		return sendServiceError(c, h.logger, err)
 	}
 
 	meta := fiber.Map{
@@ -83,16 +82,12 @@ func (h *TutorialContentHandler) listProjects(c *fiber.Ctx) error {
 func (h *TutorialContentHandler) getArticle(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	article, err := h.service.GetArticle(c.Context(), id)
 	if err != nil {
-		if errors.Is(err, service.ErrTutorialArticleNotFound) {
-			return utils.SendError(c, fiber.StatusNotFound, "article not found")
-		}
-		h.logger.Error().Err(err).Msg("failed to get tutorial article")
-		return utils.SendError(c, fiber.StatusInternalServerError, "failed to fetch article")
+		return sendServiceError(c, h.logger, err)
 	}
 
 	return utils.OK(c, article, "tutorial article retrieved", nil)
@@ -101,16 +96,12 @@ func (h *TutorialContentHandler) getArticle(c *fiber.Ctx) error {
 func (h *TutorialContentHandler) getProject(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	project, err := h.service.GetProject(c.Context(), id)
 	if err != nil {
-		if errors.Is(err, service.ErrTutorialProjectNotFound) {
-			return utils.SendError(c, fiber.StatusNotFound, "project not found")
-		}
-		h.logger.Error().Err(err).Msg("failed to get tutorial project")
-		return utils.SendError(c, fiber.StatusInternalServerError, "failed to fetch project")
+		return sendServiceError(c, h.logger, err)
 	}
 
 	return utils.OK(c, project, "tutorial project retrieved", nil)
@@ -119,16 +110,15 @@ func (h *TutorialContentHandler) getProject(c *fiber.Ctx) error {
 func (h *TutorialContentHandler) createArticle(c *fiber.Ctx) error {
 	var payload dto.TutorialArticleCreateRequest
 	if err := c.BodyParser(&payload); err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+		return utils.SendAPIError(c, utils.APIError{Status: fiber.StatusBadRequest, Code: utils.CodeInvalidRequest, Message: "invalid payload"})
 	}
 
 	article, err := h.service.CreateArticle(c.Context(), payload)
 	if err != nil {
 		if isValidationError(err) {
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+			return sendValidationError(c, err)
 		}
-		h.logger.Error().Err(err).Msg("failed to create tutorial article")
-		return utils.SendError(c, fiber.StatusInternalServerError, "failed to create article")
+		return sendServiceError(c, h.logger, err)
 	}
 
 	return utils.SendSuccessWithStatus(c, fiber.StatusCreated, "tutorial article created", article)
@@ -137,21 +127,88 @@ func (h *TutorialContentHandler) createArticle(c *fiber.Ctx) error {
 func (h *TutorialContentHandler) createProject(c *fiber.Ctx) error {
 	var payload dto.TutorialProjectCreateRequest
 	if err := c.BodyParser(&payload); err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+		return utils.SendAPIError(c, utils.APIError{Status: fiber.StatusBadRequest, Code: utils.CodeInvalidRequest, Message: "invalid payload"})
 	}
 
 	project, err := h.service.CreateProject(c.Context(), payload)
 	if err != nil {
 		if isValidationError(err) {
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+			return sendValidationError(c, err)
 		}
-		h.logger.Error().Err(err).Msg("failed to create tutorial project")
-		return utils.SendError(c, fiber.StatusInternalServerError, "failed to create project")
+		return sendServiceError(c, h.logger, err)
 	}
 
 	return utils.SendSuccessWithStatus(c, fiber.StatusCreated, "tutorial project created", project)
 }
 
+func (h *TutorialContentHandler) updateArticle(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+	var payload dto.TutorialArticleUpdateRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendAPIError(c, utils.APIError{Status: fiber.StatusBadRequest, Code: utils.CodeInvalidRequest, Message: "invalid payload"})
+	}
+
+	article, err := h.service.UpdateArticle(c.Context(), id, payload, activityActorFromContext(c))
+	if err != nil {
+		if isValidationError(err) {
+			return sendValidationError(c, err)
+		}
+		return sendServiceError(c, h.logger, err)
+	}
+
+	return utils.SendSuccess(c, "tutorial article updated", article)
+}
+
+func (h *TutorialContentHandler) deleteArticle(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	if err := h.service.DeleteArticle(c.Context(), id, activityActorFromContext(c)); err != nil {
+		return sendServiceError(c, h.logger, err)
+	}
+
+	return utils.SendSuccess(c, "tutorial article deleted", fiber.Map{"id": id})
+}
+
+func (h *TutorialContentHandler) updateProject(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+	var payload dto.TutorialProjectUpdateRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendAPIError(c, utils.APIError{Status: fiber.StatusBadRequest, Code: utils.CodeInvalidRequest, Message: "invalid payload"})
+	}
+
+	project, err := h.service.UpdateProject(c.Context(), id, payload, activityActorFromContext(c))
+	if err != nil {
+		if isValidationError(err) {
+			return sendValidationError(c, err)
+		}
+		return sendServiceError(c, h.logger, err)
+	}
+
+	return utils.SendSuccess(c, "tutorial project updated", project)
+}
+
+func (h *TutorialContentHandler) deleteProject(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	if err := h.service.DeleteProject(c.Context(), id, activityActorFromContext(c)); err != nil {
+		return sendServiceError(c, h.logger, err)
+	}
+
+	return utils.SendSuccess(c, "tutorial project deleted", fiber.Map{"id": id})
+}
+
 func (h *TutorialContentHandler) parseListRequest(c *fiber.Ctx) (dto.TutorialContentListRequest, error) {
 	page, err := parseQueryInt(c, "page")
 	if err != nil {
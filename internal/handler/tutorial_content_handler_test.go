@@ -3,7 +3,6 @@ package handler_test
 import (
 	"bytes"
 	"encoding/json"
-	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -31,7 +30,7 @@ func TestTutorialContentHandler_CreateAndList(t *testing.T) {
 	validate := validator.New(validator.WithRequiredStructEnabled())
 	articleRepo := repository.NewTutorialArticleRepository(db)
 	projectRepo := repository.NewTutorialProjectRepository(db)
-	contentService := service.NewTutorialContentService(articleRepo, projectRepo, validate, zerolog.Nop())
+	contentService := service.NewTutorialContentService(articleRepo, projectRepo, validate, nil, zerolog.Nop())
 	contentHandler := handler.NewTutorialContentHandler(contentService, zerolog.Nop())
 
 	app := fiber.New()
@@ -41,7 +40,7 @@ func TestTutorialContentHandler_CreateAndList(t *testing.T) {
 	body := map[string]interface{}{
 		"title":           "Concurrency in Go",
 		"summary":         "Learn goroutines",
-		"content":         "detailed content...",
+		"content":         "detailed content about goroutines",
 		"tags":            []string{"go", "backend"},
 		"reading_minutes": 8,
 	}
@@ -77,10 +76,34 @@ func TestTutorialContentHandler_CreateAndList(t *testing.T) {
 	require.Equal(t, "go", listPayload.Meta.Filters.Search)
 }
 
-func decodeResponse(t *testing.T, resp *http.Response, target interface{}) {
-	t.Helper()
-	body, err := io.ReadAll(resp.Body)
+func TestTutorialContentHandlerErrorCodes(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:tutorial_content_errors?mode=memory&cache=shared"), &gorm.Config{})
 	require.NoError(t, err)
-	require.NoError(t, resp.Body.Close())
-	require.NoError(t, json.Unmarshal(body, target))
+	require.NoError(t, db.AutoMigrate(&models.TutorialArticle{}, &models.TutorialProject{}))
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	contentService := service.NewTutorialContentService(repository.NewTutorialArticleRepository(db), repository.NewTutorialProjectRepository(db), validate, nil, zerolog.Nop())
+	app := fiber.New()
+	handler.NewTutorialContentHandler(contentService, zerolog.Nop()).RegisterPublic(app.Group("/api/tutorial"))
+
+	type errorBody struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/tutorial/articles/999", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	var notFound errorBody
+	decodeResponse(t, resp, &notFound)
+	require.Equal(t, "ARTICLE_NOT_FOUND", notFound.Code)
+
+	require.NoError(t, db.Migrator().DropTable(&models.TutorialProject{}))
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/tutorial/projects/1", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	var internal errorBody
+	decodeResponse(t, resp, &internal)
+	require.Equal(t, "INTERNAL_ERROR", internal.Code)
+	require.NotContains(t, internal.Message, "no such table")
 }
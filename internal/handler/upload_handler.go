@@ -2,10 +2,12 @@ package handler
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 
+	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/service"
 	"github.com/noah-isme/gema-go-api/internal/utils"
 )
@@ -26,7 +28,11 @@ func NewUploadHandler(service service.UploadService, logger zerolog.Logger) *Upl
 
 // Register wires upload routes.
 func (h *UploadHandler) Register(router fiber.Router) {
+	router.Get("", h.list)
 	router.Post("", h.upload)
+	router.Post("/sign", h.sign)
+	router.Post("/confirm", h.confirm)
+	router.Delete("/:id", h.delete)
 }
 
 func (h *UploadHandler) upload(c *fiber.Ctx) error {
@@ -35,18 +41,16 @@ func (h *UploadHandler) upload(c *fiber.Ctx) error {
 		return utils.SendError(c, fiber.StatusBadRequest, "file is required")
 	}
 
-	var userID *uint
-	if id, ok := c.Locals("user_id").(uint); ok && id > 0 {
-		userID = &id
-	}
-
-	result, err := h.service.Upload(c.Context(), file, userID)
+	result, err := h.service.Upload(c.Context(), file, uploadUserID(c))
 	if err != nil {
+		var typeErr *service.FileTypeError
 		switch {
+		case errors.As(err, &typeErr):
+			return utils.Fail(c, fiber.StatusBadRequest, service.ErrUploadTypeNotAllowed.Error(), fileTypeDetails(typeErr))
 		case errors.Is(err, service.ErrUploadTooLarge):
-			return utils.SendError(c, fiber.StatusRequestEntityTooLarge, err.Error())
+			return sendServiceError(c, h.logger, err)
 		case errors.Is(err, service.ErrUploadTypeNotAllowed), errors.Is(err, service.ErrUploadScanFailed):
-			return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+			return sendServiceError(c, h.logger, err)
 		default:
 			h.logger.Error().Err(err).Msg("upload failed")
 			return utils.SendError(c, fiber.StatusInternalServerError, "upload failed")
@@ -55,3 +59,137 @@ func (h *UploadHandler) upload(c *fiber.Ctx) error {
 
 	return utils.SendSuccess(c, "upload successful", result)
 }
+
+func (h *UploadHandler) sign(c *fiber.Ctx) error {
+	var payload dto.UploadSignRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	}
+
+	result, err := h.service.SignDirect(c.Context(), payload, uploadUserID(c))
+	if err != nil {
+		var typeErr *service.FileTypeError
+		var sizeErr *service.SizeLimitError
+		switch {
+		case isValidationError(err):
+			return sendValidationError(c, err)
+		case errors.As(err, &typeErr):
+			return utils.Fail(c, fiber.StatusBadRequest, service.ErrUploadTypeNotAllowed.Error(), fileTypeDetails(typeErr))
+		case errors.As(err, &sizeErr):
+			return utils.Fail(c, fiber.StatusRequestEntityTooLarge, sizeErr.Error(), sizeLimitDetails(sizeErr))
+		case errors.Is(err, service.ErrDirectUploadDisabled):
+			return sendServiceError(c, h.logger, err)
+		default:
+			h.logger.Error().Err(err).Msg("failed to sign upload")
+			return utils.SendError(c, fiber.StatusInternalServerError, "failed to sign upload")
+		}
+	}
+
+	return utils.SendSuccess(c, "upload signed", result)
+}
+
+func (h *UploadHandler) confirm(c *fiber.Ctx) error {
+	var payload dto.UploadConfirmRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	}
+
+	result, err := h.service.ConfirmDirect(c.Context(), payload, uploadUserID(c))
+	if err != nil {
+		var typeErr *service.FileTypeError
+		var sizeErr *service.SizeLimitError
+		switch {
+		case isValidationError(err):
+			return sendValidationError(c, err)
+		case errors.Is(err, service.ErrDirectUploadInvalid):
+			return sendServiceError(c, h.logger, err)
+		case errors.Is(err, service.ErrDirectUploadNotOwned):
+			return sendServiceError(c, h.logger, err)
+		case errors.As(err, &typeErr):
+			return utils.Fail(c, fiber.StatusBadRequest, service.ErrUploadTypeNotAllowed.Error(), fileTypeDetails(typeErr))
+		case errors.As(err, &sizeErr):
+			return utils.Fail(c, fiber.StatusRequestEntityTooLarge, sizeErr.Error(), sizeLimitDetails(sizeErr))
+		case errors.Is(err, service.ErrDirectUploadDisabled):
+			return sendServiceError(c, h.logger, err)
+		default:
+			h.logger.Error().Err(err).Msg("failed to confirm upload")
+			return utils.SendError(c, fiber.StatusInternalServerError, "failed to confirm upload")
+		}
+	}
+
+	return utils.SendSuccessWithStatus(c, fiber.StatusCreated, "upload recorded", result)
+}
+
+func (h *UploadHandler) list(c *fiber.Ctx) error {
+	page, err := parseQueryInt(c, "page")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid page")
+	}
+	pageSize, err := parseQueryInt(c, "pageSize")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid page size")
+	}
+
+	scope, ok := uploadScope(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+	if scope == nil {
+		// Admins see everyone's uploads and may narrow to one user.
+		userID, err := parseQueryInt(c, "user_id")
+		if err != nil || userID < 0 {
+			return utils.SendError(c, fiber.StatusBadRequest, "invalid user_id")
+		}
+		if userID > 0 {
+			id := uint(userID)
+			scope = &id
+		}
+	}
+
+	result, err := h.service.ListByUser(c.Context(), scope, page, pageSize)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to list uploads")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to list uploads")
+	}
+
+	return utils.SendSuccess(c, "uploads retrieved", result)
+}
+
+func (h *UploadHandler) delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil || id == 0 {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid upload id")
+	}
+
+	scope, ok := uploadScope(c)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "unauthorized")
+	}
+
+	if err := h.service.Delete(c.Context(), uint(id), scope); err != nil {
+		if errors.Is(err, service.ErrUploadNotFound) {
+			return sendServiceError(c, h.logger, err)
+		}
+		h.logger.Error().Err(err).Uint64("upload_id", id).Msg("failed to delete upload")
+		return utils.SendError(c, fiber.StatusInternalServerError, "failed to delete upload")
+	}
+
+	return utils.SendSuccess(c, "upload deleted", nil)
+}
+
+// uploadScope returns the user whose uploads the caller may manage, or nil
+// for admins, who may manage all of them. ok is false without a user.
+func uploadScope(c *fiber.Ctx) (*uint, bool) {
+	if userRoleFromContext(c) == "admin" {
+		return nil, true
+	}
+	userID := uploadUserID(c)
+	return userID, userID != nil
+}
+
+func uploadUserID(c *fiber.Ctx) *uint {
+	if id, ok := c.Locals("user_id").(uint); ok && id > 0 {
+		return &id
+	}
+	return nil
+}
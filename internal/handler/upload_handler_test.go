@@ -8,6 +8,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
@@ -20,9 +21,43 @@ import (
 )
 
 type mockUploadService struct {
-	lastUserID *uint
-	response   dto.UploadResponse
-	err        error
+	lastUserID  *uint
+	lastConfirm dto.UploadConfirmRequest
+	lastDeleted uint
+	response    dto.UploadResponse
+	signed      dto.UploadSignResponse
+	err         error
+}
+
+func (m *mockUploadService) ListByUser(_ context.Context, userID *uint, page, pageSize int) (dto.UploadListResponse, error) {
+	m.lastUserID = userID
+	if m.err != nil {
+		return dto.UploadListResponse{}, m.err
+	}
+	return dto.UploadListResponse{Items: []dto.UploadResponse{m.response}, Pagination: dto.PaginationMeta{Page: 1, PageSize: 20, TotalItems: 1, TotalPages: 1}}, nil
+}
+
+func (m *mockUploadService) Delete(_ context.Context, id uint, userID *uint) error {
+	m.lastUserID = userID
+	m.lastDeleted = id
+	return m.err
+}
+
+func (m *mockUploadService) SignDirect(_ context.Context, _ dto.UploadSignRequest, userID *uint) (dto.UploadSignResponse, error) {
+	m.lastUserID = userID
+	if m.err != nil {
+		return dto.UploadSignResponse{}, m.err
+	}
+	return m.signed, nil
+}
+
+func (m *mockUploadService) ConfirmDirect(_ context.Context, req dto.UploadConfirmRequest, userID *uint) (dto.UploadResponse, error) {
+	m.lastUserID = userID
+	m.lastConfirm = req
+	if m.err != nil {
+		return dto.UploadResponse{}, m.err
+	}
+	return m.response, nil
 }
 
 func (m *mockUploadService) Upload(_ context.Context, file *multipart.FileHeader, userID *uint) (dto.UploadResponse, error) {
@@ -125,3 +160,115 @@ func TestUploadHandler_ServiceErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestUploadHandler_SignDirect(t *testing.T) {
+	svc := &mockUploadService{signed: dto.UploadSignResponse{UploadURL: "https://api.cloudinary.com/v1_1/demo/auto/upload", PublicID: "u7-report-abc", Signature: "sig"}}
+	app := fiber.New()
+	group := app.Group("/api/upload", func(c *fiber.Ctx) error {
+		c.Locals("user_id", uint(7))
+		return c.Next()
+	})
+	handler.NewUploadHandler(svc, zerolog.New(io.Discard)).Register(group)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/upload/sign", strings.NewReader(`{"file_name":"report.pdf","size_bytes":52428800}`))
+	req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var payload struct {
+		Data dto.UploadSignResponse `json:"data"`
+	}
+	decodeResponse(t, resp, &payload)
+	require.Equal(t, "u7-report-abc", payload.Data.PublicID)
+	require.Equal(t, uint(7), *svc.lastUserID)
+}
+
+func TestUploadHandler_ConfirmDirect(t *testing.T) {
+	cases := []struct {
+		name       string
+		err        error
+		statusCode int
+	}{
+		{name: "recorded", statusCode: fiber.StatusCreated},
+		{name: "invalid", err: service.ErrDirectUploadInvalid, statusCode: fiber.StatusBadRequest},
+		{name: "not_owned", err: service.ErrDirectUploadNotOwned, statusCode: fiber.StatusForbidden},
+		{name: "disabled", err: service.ErrDirectUploadDisabled, statusCode: fiber.StatusServiceUnavailable},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &mockUploadService{err: tc.err}
+			app := fiber.New()
+			handler.NewUploadHandler(svc, zerolog.New(io.Discard)).Register(app.Group("/api/upload"))
+
+			body := `{"public_id":"gema/u7-report-abc","version":1714557590,"signature":"0123456789abcdef0123456789abcdef01234567","secure_url":"https://res.cloudinary.com/demo/image/upload/v1714557590/gema/u7-report-abc.pdf","bytes":1024,"format":"pdf","resource_type":"image"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/upload/confirm", strings.NewReader(body))
+			req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, tc.statusCode, resp.StatusCode)
+			require.Equal(t, int64(1714557590), svc.lastConfirm.Version)
+		})
+	}
+}
+
+func newScopedUploadApp(svc *mockUploadService, userID uint, role string) *fiber.App {
+	app := fiber.New()
+	group := app.Group("/api/upload", func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		c.Locals("user_role", role)
+		return c.Next()
+	})
+	handler.NewUploadHandler(svc, zerolog.New(io.Discard)).Register(group)
+	return app
+}
+
+func TestUploadHandler_ListScopesToCaller(t *testing.T) {
+	svc := &mockUploadService{response: dto.UploadResponse{ID: 4, URL: "https://cdn.example.com/file.png"}}
+	app := newScopedUploadApp(svc, 7, "student")
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/upload?user_id=9", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.NotNil(t, svc.lastUserID)
+	require.Equal(t, uint(7), *svc.lastUserID, "students cannot list other users' uploads")
+
+	var payload struct {
+		Data dto.UploadListResponse `json:"data"`
+	}
+	decodeResponse(t, resp, &payload)
+	require.Len(t, payload.Data.Items, 1)
+	require.Equal(t, int64(1), payload.Data.Pagination.TotalItems)
+
+	admin := newScopedUploadApp(svc, 1, "admin")
+	resp, err = admin.Test(httptest.NewRequest(http.MethodGet, "/api/upload", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Nil(t, svc.lastUserID)
+
+	resp, err = admin.Test(httptest.NewRequest(http.MethodGet, "/api/upload?user_id=9", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, uint(9), *svc.lastUserID)
+}
+
+func TestUploadHandler_Delete(t *testing.T) {
+	svc := &mockUploadService{}
+	app := newScopedUploadApp(svc, 7, "teacher")
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodDelete, "/api/upload/12", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, uint(12), svc.lastDeleted)
+	require.Equal(t, uint(7), *svc.lastUserID)
+
+	svc.err = service.ErrUploadNotFound
+	resp, err = app.Test(httptest.NewRequest(http.MethodDelete, "/api/upload/12", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodDelete, "/api/upload/abc", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
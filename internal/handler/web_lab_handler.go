@@ -9,6 +9,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/middleware"
 	"github.com/noah-isme/gema-go-api/internal/service"
 	"github.com/noah-isme/gema-go-api/internal/utils"
 )
@@ -34,12 +35,16 @@ func (h *WebLabHandler) Register(router fiber.Router) {
 	assignments := router.Group("/assignments")
 	assignments.Get("", h.listAssignments)
 	assignments.Get("/:id", h.getAssignment)
+	assignments.Post("", middleware.RequireRole("teacher", "admin"), h.createAssignment)
+	assignments.Patch("/:id", middleware.RequireRole("teacher", "admin"), h.updateAssignment)
+	assignments.Post("/:id/reference", middleware.RequireRole("teacher", "admin"), h.uploadReference)
 
 	router.Post("/submissions", h.createSubmission)
+	router.Post("/submissions/:id/regrade", middleware.RequireRole("teacher", "admin"), h.regrade)
 }
 
 func (h *WebLabHandler) listAssignments(c *fiber.Ctx) error {
-	assignments, err := h.service.ListAssignments(c.Context())
+	assignments, err := h.service.ListAssignments(c.Context(), userRoleFromContext(c))
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -50,10 +55,10 @@ func (h *WebLabHandler) listAssignments(c *fiber.Ctx) error {
 func (h *WebLabHandler) getAssignment(c *fiber.Ctx) error {
 	id, err := parseUintParam(c, "id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
-	assignment, err := h.service.GetAssignment(c.Context(), id)
+	assignment, err := h.service.GetAssignment(c.Context(), id, userRoleFromContext(c))
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -61,15 +66,67 @@ func (h *WebLabHandler) getAssignment(c *fiber.Ctx) error {
 	return utils.SendSuccess(c, "assignment retrieved", assignment)
 }
 
+func (h *WebLabHandler) createAssignment(c *fiber.Ctx) error {
+	var payload dto.WebAssignmentCreateRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	}
+
+	assignment, err := h.service.CreateAssignment(c.Context(), payload, activityActorFromContext(c))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return utils.SendSuccessWithStatus(c, fiber.StatusCreated, "assignment created", assignment)
+}
+
+func (h *WebLabHandler) updateAssignment(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	var payload dto.WebAssignmentUpdateRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "invalid payload")
+	}
+
+	assignment, err := h.service.UpdateAssignment(c.Context(), id, payload, activityActorFromContext(c))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return utils.SendSuccess(c, "assignment updated", assignment)
+}
+
+func (h *WebLabHandler) uploadReference(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "file is required")
+	}
+
+	assignment, err := h.service.UploadReference(c.Context(), id, file, activityActorFromContext(c))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return utils.SendSuccess(c, "reference uploaded", assignment)
+}
+
 func (h *WebLabHandler) createSubmission(c *fiber.Ctx) error {
 	studentID, err := studentIDFromContext(c)
 	if err != nil {
-		return utils.SendError(c, fiber.StatusForbidden, err.Error())
+		return utils.SendAPIError(c, utils.APIError{Status: fiber.StatusForbidden, Code: "FORBIDDEN", Message: err.Error()})
 	}
 
 	assignmentID, err := parseFormUint(c, "assignment_id")
 	if err != nil {
-		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+		return sendRequestError(c, err)
 	}
 
 	file, err := c.FormFile("file")
@@ -84,6 +141,7 @@ func (h *WebLabHandler) createSubmission(c *fiber.Ctx) error {
 	payload := dto.WebSubmissionCreateRequest{
 		AssignmentID: *assignmentID,
 		StudentID:    studentID,
+		Role:         userRoleFromContext(c),
 	}
 
 	submission, err := h.service.CreateSubmission(c.Context(), payload, file)
@@ -94,29 +152,31 @@ func (h *WebLabHandler) createSubmission(c *fiber.Ctx) error {
 	return utils.SendSuccess(c, "submission processed", submission)
 }
 
+func (h *WebLabHandler) regrade(c *fiber.Ctx) error {
+	id, err := parseUintParam(c, "id")
+	if err != nil {
+		return sendRequestError(c, err)
+	}
+
+	result, err := h.service.Regrade(c.Context(), id, activityActorFromContext(c))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return utils.SendSuccess(c, "submission regraded", result)
+}
+
 func (h *WebLabHandler) handleError(c *fiber.Ctx, err error) error {
-	var validationErrors validator.ValidationErrors
-	switch {
-	case errors.Is(err, service.ErrWebAssignmentNotFound):
-		return utils.SendError(c, fiber.StatusNotFound, "assignment not found")
-	case errors.Is(err, service.ErrStudentNotFound):
-		return utils.SendError(c, fiber.StatusForbidden, "student not found")
-	case errors.Is(err, service.ErrWebSubmissionFileRequired):
-		return utils.SendError(c, fiber.StatusBadRequest, "file is required")
-	case errors.Is(err, service.ErrWebSubmissionUnsupportedType):
-		return utils.SendError(c, fiber.StatusBadRequest, "submission must be a zip archive")
-	case errors.Is(err, service.ErrWebSubmissionTooLarge):
-		return utils.SendError(c, fiber.StatusRequestEntityTooLarge, "submission exceeds the 10 MB limit")
-	case errors.Is(err, service.ErrWebSubmissionInvalidArchive):
-		return utils.SendError(c, fiber.StatusBadRequest, "invalid zip archive")
-	case errors.Is(err, service.ErrWebSubmissionDangerousFile):
-		return utils.SendError(c, fiber.StatusBadRequest, "submission contains disallowed files")
-	case errors.As(err, &validationErrors):
-		return utils.SendError(c, fiber.StatusBadRequest, validationErrors.Error())
-	default:
-		h.logger.Error().Err(err).Msg("internal server error")
-		return utils.SendError(c, fiber.StatusInternalServerError, "internal server error")
+	// Web lab submitters must be enrolled students, so an unknown student
+	// is a permission problem here rather than a missing resource.
+	if errors.Is(err, service.ErrStudentNotFound) {
+		return utils.SendAPIError(c, utils.APIError{Status: fiber.StatusForbidden, Code: "STUDENT_NOT_FOUND", Message: "student not found"})
+	}
+	if apiErr, ok := apiErrorFor(err); ok {
+		return utils.SendAPIError(c, apiErr)
 	}
+	h.logger.Error().Err(err).Msg("internal server error")
+	return utils.SendAPIError(c, internalAPIError())
 }
 
 func studentIDFromContext(c *fiber.Ctx) (uint, error) {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
@@ -58,6 +59,9 @@ func setupWebLabApp(t *testing.T) (*fiber.App, *gorm.DB, models.Student, models.
 		repository.NewStudentRepository(db),
 		validate,
 		uploader,
+		service.SubmissionSizeLimits{},
+		service.WebArchiveLimits{},
+		nil,
 		logger,
 	)
 
@@ -68,6 +72,7 @@ func setupWebLabApp(t *testing.T) (*fiber.App, *gorm.DB, models.Student, models.
 		WebLabHandler: webLabHandler,
 		JWTMiddleware: func(c *fiber.Ctx) error {
 			c.Locals("user_id", student.ID)
+			c.Locals("user_role", c.Get("X-Test-Role", "student"))
 			return c.Next()
 		},
 	})
@@ -93,6 +98,7 @@ func TestWebLabHandler_ListAssignments(t *testing.T) {
 
 	require.True(t, body.Success)
 	require.NotEmpty(t, body.Data)
+	require.NotNil(t, body.Data[0].Limits)
 	require.Equal(t, "assignments retrieved", body.Message)
 }
 
@@ -165,6 +171,49 @@ func TestWebLabHandler_SubmissionRejectsInvalidZip(t *testing.T) {
 	require.False(t, bodyResp.Success)
 }
 
+func TestWebLabHandler_UploadReferenceRequiresStaff(t *testing.T) {
+	app, db, _, assignment := setupWebLabApp(t)
+
+	newRequest := func(role string) *http.Request {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "reference.zip")
+		require.NoError(t, err)
+		_, err = part.Write(buildZip([]zipEntry{
+			{Name: "index.html", Content: []byte("<html><head></head><body>Ref</body></html>")},
+		}))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest("POST", fmt.Sprintf("/api/v2/web-lab/assignments/%d/reference", assignment.ID), body)
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("X-Test-Role", role)
+		return req
+	}
+
+	resp, err := app.Test(newRequest("student"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	resp, err = app.Test(newRequest("teacher"))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var bodyResp struct {
+		Success bool                      `json:"success"`
+		Data    dto.WebAssignmentResponse `json:"data"`
+		Message string                    `json:"message"`
+	}
+	decodeResponse(t, resp, &bodyResp)
+	require.Equal(t, "reference uploaded", bodyResp.Message)
+	require.True(t, bodyResp.Data.HasReference)
+
+	var stored models.WebAssignment
+	require.NoError(t, db.First(&stored, assignment.ID).Error)
+	require.Equal(t, "https://example.com/uploads/reference.zip", stored.ReferenceZipURL)
+}
+
 type zipEntry struct {
 	Name    string
 	Content []byte
@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// CorrelationIDField is the log field carrying the request's correlation ID.
+const CorrelationIDField = "correlation_id"
+
+type contextKey struct{}
+
+// ContextKey is the key the correlation ID is stored under. Fiber locals share
+// the key so services receiving c.Context() resolve the same ID as
+// c.UserContext().
+var ContextKey = contextKey{}
+
+// WithCorrelationID returns a context carrying the correlation ID. Blank IDs
+// leave the context unchanged.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	correlationID = strings.TrimSpace(correlationID)
+	if correlationID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, ContextKey, correlationID)
+}
+
+// CorrelationID returns the correlation ID bound to the context, if any.
+func CorrelationID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(ContextKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// FromContext returns base with the correlation ID from ctx attached, so every
+// line logged while serving a request can be traced back to it. Without an ID
+// a copy of base is returned.
+func FromContext(ctx context.Context, base zerolog.Logger) *zerolog.Logger {
+	if id := CorrelationID(ctx); id != "" {
+		base = base.With().Str(CorrelationIDField, id).Logger()
+	}
+	return &base
+}
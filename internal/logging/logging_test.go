@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContextAttachesCorrelationID(t *testing.T) {
+	var buf bytes.Buffer
+	base := zerolog.New(&buf)
+
+	ctx := WithCorrelationID(context.Background(), " req-123 ")
+	FromContext(ctx, base).Info().Msg("handled")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "req-123", line[CorrelationIDField])
+	require.Equal(t, "handled", line["message"])
+}
+
+func TestFromContextWithoutCorrelationID(t *testing.T) {
+	var buf bytes.Buffer
+	base := zerolog.New(&buf)
+
+	FromContext(WithCorrelationID(context.Background(), "  "), base).Info().Msg("handled")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.NotContains(t, line, CorrelationIDField)
+}
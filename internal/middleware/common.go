@@ -12,7 +12,8 @@ import (
 
 // Config customises the middleware registration pipeline.
 type Config struct {
-	Logger *zerolog.Logger
+	Logger            *zerolog.Logger
+	FeatureFlagSecret string
 }
 
 // Register attaches the common middlewares used across the API.
@@ -24,11 +25,14 @@ func Register(app *fiber.App, cfg Config) {
 
 	app.Use(recover.New())
 	app.Use(CorrelationID())
+	app.Use(HTTPMetrics())
+	app.Use(FeatureFlags(cfg.FeatureFlagSecret))
 	app.Use(Observability(requestLogger))
 	app.Use(logger.New())
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
-		AllowMethods: "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowOrigins:  "*",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, " + FeatureFlagHeader,
+		AllowMethods:  "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		ExposeHeaders: CorrelationIDHeader,
 	}))
 }
@@ -6,16 +6,17 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-)
 
-type correlationIDKey struct{}
+	"github.com/noah-isme/gema-go-api/internal/logging"
+)
 
-var correlationKey = correlationIDKey{}
+// CorrelationIDHeader carries the correlation identifier on requests and is echoed on every response.
+const CorrelationIDHeader = "X-Correlation-ID"
 
 // CorrelationID middleware ensures every request carries a correlation identifier for tracing across services.
 func CorrelationID() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		incoming := strings.TrimSpace(c.Get("X-Correlation-ID"))
+		incoming := strings.TrimSpace(c.Get(CorrelationIDHeader))
 		if incoming == "" {
 			incoming = strings.TrimSpace(c.Get("X-Request-ID"))
 		}
@@ -24,10 +25,9 @@ func CorrelationID() fiber.Handler {
 		}
 
 		c.Locals("correlation_id", incoming)
-		c.Set("X-Correlation-ID", incoming)
-
-		ctx := context.WithValue(c.Context(), correlationKey, incoming)
-		c.SetUserContext(ctx)
+		c.Locals(logging.ContextKey, incoming)
+		c.Set(CorrelationIDHeader, incoming)
+		c.SetUserContext(logging.WithCorrelationID(c.UserContext(), incoming))
 
 		return c.Next()
 	}
@@ -35,15 +35,7 @@ func CorrelationID() fiber.Handler {
 
 // CorrelationIDFromContext extracts the correlation identifier from context, if present.
 func CorrelationIDFromContext(ctx context.Context) string {
-	if ctx == nil {
-		return ""
-	}
-	if value := ctx.Value(correlationKey); value != nil {
-		if id, ok := value.(string); ok {
-			return id
-		}
-	}
-	return ""
+	return logging.CorrelationID(ctx)
 }
 
 // GetCorrelationID returns the correlation identifier bound to the active request.
@@ -61,11 +53,5 @@ func GetCorrelationID(c *fiber.Ctx) string {
 
 // ContextWithCorrelation attaches the correlation identifier to the provided context.
 func ContextWithCorrelation(ctx context.Context, correlationID string) context.Context {
-	if ctx == nil {
-		ctx = context.Background()
-	}
-	if strings.TrimSpace(correlationID) == "" {
-		return ctx
-	}
-	return context.WithValue(ctx, correlationKey, strings.TrimSpace(correlationID))
+	return logging.WithCorrelationID(ctx, correlationID)
 }
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noah-isme/gema-go-api/internal/logging"
+)
+
+func TestCorrelationIDEchoedAndBoundToContext(t *testing.T) {
+	app := fiber.New()
+	app.Use(CorrelationID())
+	app.Get("/probe", func(c *fiber.Ctx) error {
+		if logging.CorrelationID(c.Context()) != "req-42" || logging.CorrelationID(c.UserContext()) != "req-42" {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusBadRequest, "bad request")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/probe", nil)
+	req.Header.Set(CorrelationIDHeader, "req-42")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "req-42", resp.Header.Get(CorrelationIDHeader))
+
+	req = httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set("X-Request-ID", "req-43")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, "req-43", resp.Header.Get(CorrelationIDHeader))
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/missing", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	require.NotEmpty(t, resp.Header.Get(CorrelationIDHeader))
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/noah-isme/gema-go-api/internal/featureflags"
+)
+
+// FeatureFlagHeader carries a signed token enabling experimental code paths for a single request.
+const FeatureFlagHeader = "X-Feature-Flags"
+
+// FeatureFlags binds per-request feature toggles from a signed header. The header is ignored
+// entirely unless a secret is configured and the token verifies against it.
+func FeatureFlags(secret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := strings.TrimSpace(c.Get(FeatureFlagHeader))
+		if token == "" || strings.TrimSpace(secret) == "" {
+			return c.Next()
+		}
+
+		flags, err := featureflags.Verify(secret, token, time.Now())
+		if err != nil || len(flags) == 0 {
+			return c.Next()
+		}
+
+		c.Locals(featureflags.ContextKey, flags)
+		c.SetUserContext(featureflags.WithFlags(c.UserContext(), flags))
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noah-isme/gema-go-api/internal/featureflags"
+)
+
+func TestFeatureFlagsActivateOnlyWithValidToken(t *testing.T) {
+	const secret = "canary-secret"
+
+	app := fiber.New()
+	app.Use(FeatureFlags(secret))
+	app.Get("/probe", func(c *fiber.Ctx) error {
+		if featureflags.Enabled(c.Context(), featureflags.AsyncCodingExecution) &&
+			featureflags.Enabled(c.UserContext(), featureflags.AsyncCodingExecution) {
+			return c.SendString("on")
+		}
+		return c.SendString("off")
+	})
+
+	valid := featureflags.Sign(secret, []featureflags.Flag{featureflags.AsyncCodingExecution}, time.Now().Add(time.Hour))
+	cases := map[string]string{
+		"valid":          valid,
+		"missing":        "",
+		"forged":         featureflags.Sign("other-secret", []featureflags.Flag{featureflags.AsyncCodingExecution}, time.Now().Add(time.Hour)),
+		"expired":        featureflags.Sign(secret, []featureflags.Flag{featureflags.AsyncCodingExecution}, time.Now().Add(-time.Minute)),
+		"unknown flag":   featureflags.Sign(secret, []featureflags.Flag{"drop_tables"}, time.Now().Add(time.Hour)),
+		"tampered flags": "drop_tables" + valid[len(featureflags.AsyncCodingExecution):],
+	}
+
+	for name, token := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/probe", nil)
+		if token != "" {
+			req.Header.Set(FeatureFlagHeader, token)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		body := make([]byte, 3)
+		n, _ := resp.Body.Read(body)
+		expected := "off"
+		if name == "valid" {
+			expected = "on"
+		}
+		require.Equal(t, expected, string(body[:n]), name)
+	}
+}
+
+func TestFeatureFlagsIgnoredWithoutSecret(t *testing.T) {
+	app := fiber.New()
+	app.Use(FeatureFlags(""))
+	app.Get("/probe", func(c *fiber.Ctx) error {
+		require.False(t, featureflags.Enabled(c.Context(), featureflags.AsyncCodingExecution))
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/probe", nil)
+	req.Header.Set(FeatureFlagHeader, featureflags.Sign("", []featureflags.Flag{featureflags.AsyncCodingExecution}, time.Now().Add(time.Hour)))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+}
@@ -0,0 +1,309 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/noah-isme/gema-go-api/internal/utils"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client-chosen key of a retryable request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed from an earlier request.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	defaultIdempotencyTTL   = 24 * time.Hour
+	// idempotencyPendingTTL bounds how long a request in flight holds its key,
+	// so a key is freed again if the instance dies mid-request.
+	idempotencyPendingTTL = 2 * time.Minute
+)
+
+// IdempotencyConfig configures an Idempotent instance for one route group.
+type IdempotencyConfig struct {
+	// Identifier namespaces the stored responses of the route group.
+	Identifier string
+	// TTL is how long a response is replayed for its key. Defaults to 24 hours.
+	TTL time.Duration
+	// Redis shares stored responses across instances. Without it, or while it
+	// is unreachable, responses are kept per instance.
+	Redis *redis.Client
+}
+
+// idempotentResponse is what is stored under a key: the fingerprint of the
+// request that claimed it and, once that request finished, its response.
+type idempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Pending     bool   `json:"pending,omitempty"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Idempotent honours the Idempotency-Key header on POST and PATCH requests of
+// authenticated callers. The first request with a key is processed and, when
+// it succeeds, its response is stored for TTL; repeats with the same key get
+// that response back with Idempotent-Replayed: true instead of being processed
+// again. Keys are scoped per user, method and path. A repeat sent while the
+// first is still running gets 409, and reusing a key for a different body gets
+// 422. Failed responses are not stored, so the client may retry with the same
+// key. Requests without the header, or from anonymous callers, pass through.
+func Idempotent(cfg IdempotencyConfig) fiber.Handler {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultIdempotencyTTL
+	}
+
+	local := newMemoryIdempotencyStore()
+
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodPost && c.Method() != fiber.MethodPatch {
+			return c.Next()
+		}
+		idempotencyKey := c.Get(IdempotencyKeyHeader)
+		if idempotencyKey == "" {
+			return c.Next()
+		}
+		user := c.Locals("user_id")
+		userID := fmt.Sprintf("%v", user)
+		if user == nil || userID == "" || userID == "0" {
+			return c.Next()
+		}
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			return utils.SendError(c, fiber.StatusBadRequest, fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+		}
+
+		key := idempotencyStorageKey(cfg.Identifier, userID, c.Method(), c.Path(), idempotencyKey)
+		claim := idempotentResponse{Fingerprint: requestFingerprint(c), Pending: true}
+
+		ctx := c.UserContext()
+		var store idempotencyStore = local
+		if cfg.Redis != nil {
+			store = redisIdempotencyStore{client: cfg.Redis}
+		}
+		existing, err := store.claim(ctx, key, claim)
+		if err != nil && cfg.Redis != nil {
+			store = local
+			existing, err = store.claim(ctx, key, claim)
+		}
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			switch {
+			case existing.Fingerprint != claim.Fingerprint:
+				return utils.SendError(c, fiber.StatusUnprocessableEntity, fmt.Sprintf("%s was already used for a different request", IdempotencyKeyHeader))
+			case existing.Pending:
+				return utils.SendError(c, fiber.StatusConflict, fmt.Sprintf("a request with this %s is still in progress", IdempotencyKeyHeader))
+			}
+			c.Set(IdempotentReplayedHeader, "true")
+			if existing.ContentType != "" {
+				c.Set(fiber.HeaderContentType, existing.ContentType)
+			}
+			return c.Status(existing.Status).Send(existing.Body)
+		}
+
+		if err := c.Next(); err != nil {
+			store.release(ctx, key)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status < fiber.StatusOK || status >= fiber.StatusMultipleChoices {
+			store.release(ctx, key)
+			return nil
+		}
+		store.save(ctx, key, idempotentResponse{
+			Fingerprint: claim.Fingerprint,
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        append([]byte(nil), c.Response().Body()...),
+		}, cfg.TTL)
+		return nil
+	}
+}
+
+// requestFingerprint hashes what the request asks for. Multipart bodies are
+// hashed by their parsed fields and file contents rather than raw bytes, since
+// clients pick a fresh boundary for every retry of the same upload. A body
+// that does not parse as multipart falls back to its raw bytes.
+func requestFingerprint(c *fiber.Ctx) string {
+	if !strings.HasPrefix(strings.ToLower(c.Get(fiber.HeaderContentType)), fiber.MIMEMultipartForm) {
+		sum := sha256.Sum256(c.Body())
+		return hex.EncodeToString(sum[:])
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		sum := sha256.Sum256(c.Body())
+		return hex.EncodeToString(sum[:])
+	}
+
+	hash := sha256.New()
+	// Each part is length-prefixed so adjacent values cannot run together.
+	write := func(parts ...string) {
+		for _, part := range parts {
+			fmt.Fprintf(hash, "%d:%s;", len(part), part)
+		}
+	}
+	for _, name := range sortedKeys(form.Value) {
+		for _, value := range form.Value[name] {
+			write("field", name, value)
+		}
+	}
+	for _, name := range sortedKeys(form.File) {
+		for _, header := range form.File[name] {
+			write("file", name, header.Filename, fileDigest(header))
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// fileDigest hashes an uploaded file's contents, or returns "" when it cannot
+// be read, which the handler will reject anyway.
+func fileDigest(header *multipart.FileHeader) string {
+	file, err := header.Open()
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// idempotencyStorageKey hashes the client's key so arbitrary header values
+// make well-formed storage keys.
+func idempotencyStorageKey(identifier, userID, method, path, idempotencyKey string) string {
+	sum := sha256.Sum256([]byte(idempotencyKey))
+	return fmt.Sprintf("idempotency:%s:user:%s:%s %s:%s", identifier, userID, method, path, hex.EncodeToString(sum[:]))
+}
+
+// idempotencyStore claims keys and keeps the responses stored under them.
+// claim stores pending under key unless the key is taken, in which case it
+// returns what is stored there. save and release are best effort: a lost
+// write only means a repeat is processed again or waits for the claim to
+// expire.
+type idempotencyStore interface {
+	claim(ctx context.Context, key string, pending idempotentResponse) (*idempotentResponse, error)
+	save(ctx context.Context, key string, response idempotentResponse, ttl time.Duration)
+	release(ctx context.Context, key string)
+}
+
+type redisIdempotencyStore struct {
+	client *redis.Client
+}
+
+func (s redisIdempotencyStore) claim(ctx context.Context, key string, pending idempotentResponse) (*idempotentResponse, error) {
+	payload, err := json.Marshal(pending)
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := s.client.SetNX(ctx, key, payload, idempotencyPendingTTL).Result()
+	if err != nil {
+		return nil, err
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	stored, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// The claim expired in between; take it over.
+		return nil, s.client.Set(ctx, key, payload, idempotencyPendingTTL).Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	var existing idempotentResponse
+	if err := json.Unmarshal(stored, &existing); err != nil {
+		return nil, fmt.Errorf("decode idempotent response: %w", err)
+	}
+	return &existing, nil
+}
+
+func (s redisIdempotencyStore) save(ctx context.Context, key string, response idempotentResponse, ttl time.Duration) {
+	payload, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	_ = s.client.Set(ctx, key, payload, ttl).Err()
+}
+
+func (s redisIdempotencyStore) release(ctx context.Context, key string) {
+	_ = s.client.Del(ctx, key).Err()
+}
+
+// memoryIdempotencyStore keeps claims and responses for a single instance.
+type memoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryIdempotencyEntry
+	nextSweep time.Time
+}
+
+type memoryIdempotencyEntry struct {
+	response  idempotentResponse
+	expiresAt time.Time
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{entries: make(map[string]memoryIdempotencyEntry)}
+}
+
+func (m *memoryIdempotencyStore) claim(_ context.Context, key string, pending idempotentResponse) (*idempotentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.After(m.nextSweep) {
+		for k, entry := range m.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(m.entries, k)
+			}
+		}
+		m.nextSweep = now.Add(idempotencyPendingTTL)
+	}
+
+	if entry, ok := m.entries[key]; ok && now.Before(entry.expiresAt) {
+		existing := entry.response
+		return &existing, nil
+	}
+	m.entries[key] = memoryIdempotencyEntry{response: pending, expiresAt: now.Add(idempotencyPendingTTL)}
+	return nil, nil
+}
+
+func (m *memoryIdempotencyStore) save(_ context.Context, key string, response idempotentResponse, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = memoryIdempotencyEntry{response: response, expiresAt: time.Now().Add(ttl)}
+}
+
+func (m *memoryIdempotencyStore) release(_ context.Context, key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
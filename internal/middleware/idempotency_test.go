@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotentApp(cfg IdempotencyConfig, calls *int64) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if id := c.Get("X-Test-User"); id != "" {
+			c.Locals("user_id", id)
+		}
+		return c.Next()
+	})
+	app.Use(Idempotent(cfg))
+	app.Post("/submissions", func(c *fiber.Ctx) error {
+		call := atomic.AddInt64(calls, 1)
+		if strings.Contains(string(c.Body()), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"call": call})
+		}
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"call": call})
+	})
+	return app
+}
+
+func idempotentRequest(t *testing.T, app *fiber.App, userID, key, body string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/submissions", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if userID != "" {
+		req.Header.Set("X-Test-User", userID)
+	}
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	payload, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(payload)
+}
+
+func TestIdempotentReplaysStoredResponseAcrossInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	var calls int64
+	cfg := IdempotencyConfig{Identifier: "submissions", TTL: time.Hour, Redis: client}
+	first := newIdempotentApp(cfg, &calls)
+	second := newIdempotentApp(cfg, &calls)
+
+	resp, body := idempotentRequest(t, first, "7", "retry-1", `{"answer":1}`)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	require.Empty(t, resp.Header.Get(IdempotentReplayedHeader))
+
+	resp, replayed := idempotentRequest(t, second, "7", "retry-1", `{"answer":1}`)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	require.Equal(t, "true", resp.Header.Get(IdempotentReplayedHeader))
+	require.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+	require.Equal(t, body, replayed)
+	require.EqualValues(t, 1, atomic.LoadInt64(&calls))
+
+	resp, _ = idempotentRequest(t, first, "7", "retry-1", `{"answer":2}`)
+	require.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode, "a reused key with another body is rejected")
+
+	resp, _ = idempotentRequest(t, first, "8", "retry-1", `{"answer":1}`)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode, "keys are scoped per user")
+	require.EqualValues(t, 2, atomic.LoadInt64(&calls))
+
+	require.Greater(t, mr.TTL(idempotencyStorageKey("submissions", "7", fiber.MethodPost, "/submissions", "retry-1")), 59*time.Minute)
+}
+
+func TestIdempotentRejectsRepeatWhileInProgress(t *testing.T) {
+	var calls int64
+	held := make(chan struct{})
+	release := make(chan struct{})
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", c.Get("X-Test-User"))
+		return c.Next()
+	})
+	app.Use(Idempotent(IdempotencyConfig{Identifier: "submissions"}))
+	app.Post("/submissions", func(c *fiber.Ctx) error {
+		atomic.AddInt64(&calls, 1)
+		close(held)
+		<-release
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	done := make(chan int)
+	go func() {
+		req := httptest.NewRequest(http.MethodPost, "/submissions", strings.NewReader(`{}`))
+		req.Header.Set("X-Test-User", "7")
+		req.Header.Set(IdempotencyKeyHeader, "retry-1")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			done <- 0
+			return
+		}
+		done <- resp.StatusCode
+	}()
+	<-held
+
+	resp, _ := idempotentRequest(t, app, "7", "retry-1", `{}`)
+	require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+	close(release)
+	require.Equal(t, fiber.StatusCreated, <-done)
+	resp, _ = idempotentRequest(t, app, "7", "retry-1", `{}`)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	require.Equal(t, "true", resp.Header.Get(IdempotentReplayedHeader))
+	require.EqualValues(t, 1, atomic.LoadInt64(&calls))
+}
+
+func TestIdempotentDoesNotStoreFailures(t *testing.T) {
+	var calls int64
+	app := newIdempotentApp(IdempotencyConfig{Identifier: "submissions"}, &calls)
+
+	for i := 1; i <= 2; i++ {
+		resp, body := idempotentRequest(t, app, "7", "retry-1", `{"answer":"invalid"}`)
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		require.Contains(t, body, `"call":`+strconv.Itoa(i), "failed responses are processed again")
+	}
+
+	for i := 3; i <= 4; i++ {
+		resp, _ := idempotentRequest(t, app, "", "retry-1", `{}`)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		require.Empty(t, resp.Header.Get(IdempotentReplayedHeader), "anonymous requests pass through")
+	}
+	require.EqualValues(t, 4, atomic.LoadInt64(&calls))
+
+	resp, _ := idempotentRequest(t, app, "7", strings.Repeat("k", maxIdempotencyKeyLength+1), `{}`)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestIdempotentFingerprintsMultipartByContent(t *testing.T) {
+	var calls int64
+	app := newIdempotentApp(IdempotencyConfig{Identifier: "submissions"}, &calls)
+
+	send := func(boundary, note, content string) *http.Response {
+		t.Helper()
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		require.NoError(t, writer.SetBoundary(boundary))
+		require.NoError(t, writer.WriteField("note", note))
+		part, err := writer.CreateFormFile("file", "answer.zip")
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/submissions", body)
+		req.Header.Set(fiber.HeaderContentType, writer.FormDataContentType())
+		req.Header.Set("X-Test-User", "7")
+		req.Header.Set(IdempotencyKeyHeader, "upload-1")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := send("first-boundary", "v1", "zip bytes")
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	resp = send("second-boundary", "v1", "zip bytes")
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	require.Equal(t, "true", resp.Header.Get(IdempotentReplayedHeader), "a retry with a new boundary is the same request")
+
+	resp = send("third-boundary", "v1", "other bytes")
+	require.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode, "a different file is a different request")
+
+	resp = send("fourth-boundary", "v2", "zip bytes")
+	require.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode, "a different field is a different request")
+	require.EqualValues(t, 1, atomic.LoadInt64(&calls))
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,48 +12,126 @@ import (
 	"github.com/noah-isme/gema-go-api/internal/utils"
 )
 
+// TokenDenylist reports whether a token ID was revoked before its token
+// expired.
+type TokenDenylist interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// JWTOptions configures revocation checks for JWTProtected and JWTOptional.
+type JWTOptions struct {
+	// Denylist rejects revoked tokens; nil skips the check. Tokens without a
+	// jti claim cannot be revoked and are not checked.
+	Denylist TokenDenylist
+	// FailOpen accepts tokens while the denylist cannot be reached, so an
+	// outage does not lock everyone out. Otherwise such requests get 503.
+	FailOpen bool
+}
+
 // JWTProtected returns a middleware that validates JWT bearer tokens.
-func JWTProtected(secret string) fiber.Handler {
+// Websocket upgrades may pass the token in a token query parameter instead,
+// since browsers cannot set headers on them.
+func JWTProtected(secret string, opts JWTOptions) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		authorization := c.Get("Authorization")
+		authorization := bearerAuthorization(c)
 		if authorization == "" {
 			return utils.SendError(c, fiber.StatusUnauthorized, "authorization header missing")
 		}
+		return authenticate(c, secret, opts, authorization)
+	}
+}
 
-		const bearer = "Bearer "
-		if !strings.HasPrefix(strings.ToLower(authorization), strings.ToLower(bearer)) {
-			return utils.SendError(c, fiber.StatusUnauthorized, "invalid authorization header")
+// JWTOptional validates a bearer token when one is sent and lets anonymous
+// requests through, for public endpoints that tailor their response to the
+// caller. A token that is sent but invalid is still rejected.
+func JWTOptional(secret string, opts JWTOptions) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authorization := bearerAuthorization(c)
+		if authorization == "" {
+			return c.Next()
 		}
+		return authenticate(c, secret, opts, authorization)
+	}
+}
 
-		tokenString := strings.TrimSpace(authorization[len(bearer):])
-		if tokenString == "" {
-			return utils.SendError(c, fiber.StatusUnauthorized, "invalid token")
-		}
+// bearerAuthorization returns the Authorization header, or for websocket
+// upgrades without one, a bearer credential built from the token query
+// parameter.
+func bearerAuthorization(c *fiber.Ctx) string {
+	if authorization := c.Get(fiber.HeaderAuthorization); authorization != "" {
+		return authorization
+	}
+	if !strings.EqualFold(c.Get(fiber.HeaderUpgrade), "websocket") {
+		return ""
+	}
+	if token := strings.TrimSpace(c.Query("token")); token != "" {
+		return "Bearer " + token
+	}
+	return ""
+}
 
-		token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method")
-			}
-			return []byte(secret), nil
-		})
-		if err != nil || !token.Valid {
-			return utils.SendError(c, fiber.StatusUnauthorized, "invalid token")
-		}
+// authenticate validates the bearer token and stores its identity claims in
+// the request locals, along with the token's jti and expiry so it can be
+// revoked.
+func authenticate(c *fiber.Ctx, secret string, opts JWTOptions, authorization string) error {
+	const bearer = "Bearer "
+	if !strings.HasPrefix(strings.ToLower(authorization), strings.ToLower(bearer)) {
+		return utils.SendError(c, fiber.StatusUnauthorized, "invalid authorization header")
+	}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return utils.SendError(c, fiber.StatusUnauthorized, "invalid token claims")
-		}
+	tokenString := strings.TrimSpace(authorization[len(bearer):])
+	if tokenString == "" {
+		return utils.SendError(c, fiber.StatusUnauthorized, "invalid token")
+	}
 
-		if userID := extractUserIDFromClaims(claims); userID != nil {
-			c.Locals("user_id", *userID)
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
 		}
-		if role := extractUserRoleFromClaims(claims); role != "" {
-			c.Locals("user_role", role)
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid {
+		return utils.SendError(c, fiber.StatusUnauthorized, "invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "invalid token claims")
+	}
+	// Refresh tokens only buy new token pairs at the refresh endpoint; they
+	// must never authorize requests, even if signed with the access secret.
+	if typ, _ := claims["typ"].(string); typ == "refresh" {
+		return utils.SendError(c, fiber.StatusUnauthorized, "invalid token type")
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti != "" && opts.Denylist != nil {
+		revoked, err := opts.Denylist.IsRevoked(c.UserContext(), jti)
+		switch {
+		case err != nil && !opts.FailOpen:
+			return utils.SendError(c, fiber.StatusServiceUnavailable, "token revocation check unavailable")
+		case err == nil && revoked:
+			return utils.SendError(c, fiber.StatusUnauthorized, "token has been revoked")
 		}
+	}
 
-		return c.Next()
+	if userID := extractUserIDFromClaims(claims); userID != nil {
+		c.Locals("user_id", *userID)
+	}
+	if role := extractUserRoleFromClaims(claims); role != "" {
+		c.Locals("user_role", role)
 	}
+	if class, ok := claims["class"].(string); ok && strings.TrimSpace(class) != "" {
+		c.Locals("user_class", strings.TrimSpace(class))
+	}
+	if jti != "" {
+		c.Locals("token_jti", jti)
+	}
+	if expiresAt, err := claims.GetExpirationTime(); err == nil && expiresAt != nil {
+		c.Locals("token_expires_at", expiresAt.Time)
+	}
+
+	return c.Next()
 }
 
 func extractUserIDFromClaims(claims jwt.MapClaims) *uint {
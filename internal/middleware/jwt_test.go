@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type stubDenylist struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (d stubDenylist) IsRevoked(_ context.Context, jti string) (bool, error) {
+	return d.revoked[jti], d.err
+}
+
+func signedTestToken(t *testing.T, jti string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  "7",
+		"role": "student",
+		"jti":  jti,
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return token
+}
+
+func TestJWTProtectedRejectsRevokedTokens(t *testing.T) {
+	denylist := stubDenylist{revoked: map[string]bool{"revoked": true}}
+
+	app := fiber.New()
+	app.Get("/probe", JWTProtected("secret", JWTOptions{Denylist: denylist}), func(c *fiber.Ctx) error {
+		if c.Locals("user_id") != uint(7) || c.Locals("token_jti") != "active" || c.Locals("token_expires_at") == nil {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	for jti, expected := range map[string]int{"active": fiber.StatusOK, "revoked": fiber.StatusUnauthorized} {
+		req := httptest.NewRequest(http.MethodGet, "/probe", nil)
+		req.Header.Set("Authorization", "Bearer "+signedTestToken(t, jti))
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, expected, resp.StatusCode, jti)
+	}
+}
+
+func TestJWTProtectedDenylistOutage(t *testing.T) {
+	denylist := stubDenylist{err: errors.New("redis down")}
+
+	cases := map[bool]int{true: fiber.StatusOK, false: fiber.StatusServiceUnavailable}
+	for failOpen, expected := range cases {
+		app := fiber.New()
+		app.Get("/probe", JWTProtected("secret", JWTOptions{Denylist: denylist, FailOpen: failOpen}), func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/probe", nil)
+		req.Header.Set("Authorization", "Bearer "+signedTestToken(t, "any"))
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, expected, resp.StatusCode)
+	}
+}
+
+func TestJWTProtectedRejectsRefreshTokens(t *testing.T) {
+	app := fiber.New()
+	app.Get("/probe", JWTProtected("secret", JWTOptions{}), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	for typ, expected := range map[string]int{"access": fiber.StatusOK, "refresh": fiber.StatusUnauthorized} {
+		// Signed with the access secret, so only the type claim tells them apart.
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "7",
+			"typ": typ,
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("secret"))
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/probe", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, expected, resp.StatusCode, typ)
+	}
+}
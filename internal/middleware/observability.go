@@ -1,8 +1,11 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -57,6 +60,74 @@ func Observability(logger zerolog.Logger) fiber.Handler {
 	}
 }
 
+// unmatchedRoute labels requests that no route handled, so unknown paths
+// cannot grow the metric series.
+const unmatchedRoute = "unmatched"
+
+// HTTPMetrics records request count, latency and in-flight requests for every
+// route, labelled by the route pattern rather than the raw path so IDs do not
+// create new series.
+func HTTPMetrics() fiber.Handler {
+	observability.RegisterMetrics()
+
+	var (
+		handlerRoutesOnce sync.Once
+		handlerRoutes     map[string]struct{}
+	)
+
+	return func(c *fiber.Ctx) error {
+		method := c.Method()
+		inFlight := observability.HTTPRequestsInFlight().WithLabelValues(method)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		own := c.Route()
+		start := time.Now()
+		err := c.Next()
+		duration := time.Since(start)
+
+		// A request that passed through a group's Use middleware but matched
+		// no handler is left on the Use route, so only handler routes count.
+		route := unmatchedRoute
+		if current := c.Route(); current != nil && current != own && current.Path != "" {
+			handlerRoutesOnce.Do(func() { handlerRoutes = handlerRouteSet(c.App()) })
+			if _, ok := handlerRoutes[current.Method+" "+current.Path]; ok {
+				route = current.Path
+			}
+		}
+		status := strconv.Itoa(responseStatus(c, err))
+
+		observability.HTTPRequests().WithLabelValues(method, route, status).Inc()
+		observability.HTTPRequestDuration().WithLabelValues(method, route, status).Observe(duration.Seconds())
+
+		return err
+	}
+}
+
+// handlerRouteSet indexes the method and path of every route registered with
+// a handler, leaving out Use middleware routes.
+func handlerRouteSet(app *fiber.App) map[string]struct{} {
+	routes := app.GetRoutes(true)
+	set := make(map[string]struct{}, len(routes))
+	for _, route := range routes {
+		set[route.Method+" "+route.Path] = struct{}{}
+	}
+	return set
+}
+
+// responseStatus is the status the error handler will answer with when the
+// chain returned an error, and the response status otherwise.
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
 func routeTemplate(c *fiber.Ctx) string {
 	if c.Route() != nil && c.Route().Path != "" {
 		return c.Route().Path
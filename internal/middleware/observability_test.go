@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noah-isme/gema-go-api/internal/observability"
+)
+
+func TestHTTPMetricsLabelsByRoutePattern(t *testing.T) {
+	app := fiber.New()
+	app.Use(HTTPMetrics())
+	app.Get("/metrics-test/items/:id", func(c *fiber.Ctx) error {
+		if c.Params("id") == "missing" {
+			return fiber.NewError(fiber.StatusNotFound, "not found")
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	requests := observability.HTTPRequests()
+	ok := requests.WithLabelValues(http.MethodGet, "/metrics-test/items/:id", "204")
+	missing := requests.WithLabelValues(http.MethodGet, "/metrics-test/items/:id", "404")
+	unmatched := requests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")
+	okBefore, missingBefore, unmatchedBefore := testutil.ToFloat64(ok), testutil.ToFloat64(missing), testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/metrics-test/items/1", "/metrics-test/items/2", "/metrics-test/items/missing", "/metrics-test/unknown/3"} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	require.Equal(t, okBefore+2, testutil.ToFloat64(ok))
+	require.Equal(t, missingBefore+1, testutil.ToFloat64(missing))
+	require.Equal(t, unmatchedBefore+1, testutil.ToFloat64(unmatched))
+	require.Zero(t, testutil.ToFloat64(observability.HTTPRequestsInFlight().WithLabelValues(http.MethodGet)))
+}
+
+func TestHTTPMetricsIgnoresGroupMiddlewareRoutes(t *testing.T) {
+	app := fiber.New()
+	app.Use(HTTPMetrics())
+	group := app.Group("/metrics-group", func(c *fiber.Ctx) error { return c.Next() })
+	group.Get("/items", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+
+	requests := observability.HTTPRequests()
+	items := requests.WithLabelValues(http.MethodGet, "/metrics-group/items", "204")
+	middlewareRoute := requests.WithLabelValues(http.MethodGet, "/metrics-group", "404")
+	unmatched := requests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")
+	itemsBefore, unmatchedBefore := testutil.ToFloat64(items), testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/metrics-group/items", "/metrics-group/unknown"} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	require.Equal(t, itemsBefore+1, testutil.ToFloat64(items))
+	require.Equal(t, unmatchedBefore+1, testutil.ToFloat64(unmatched))
+	require.Zero(t, testutil.ToFloat64(middlewareRoute))
+}
@@ -1,31 +1,164 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/noah-isme/gema-go-api/internal/utils"
 )
 
-// RateLimit creates a per-user rate limiter middleware instance.
-func RateLimit(identifier string, max int, window time.Duration) fiber.Handler {
-	if max <= 0 {
-		max = 10
+// RateLimitConfig configures a RateLimit instance for one route group.
+type RateLimitConfig struct {
+	// Identifier namespaces the counters of the route group.
+	Identifier string
+	// Max is the number of requests allowed per window. Defaults to 10.
+	Max int
+	// Window is the fixed window the requests are counted in. Defaults to one second.
+	Window time.Duration
+	// Limit resolves the allowance for the current request, e.g. from the
+	// caller's role. Results <= 0 fall back to Max.
+	Limit func(c *fiber.Ctx) int
+	// Redis shares counters across instances. Without it, or while it is
+	// unreachable, counters are kept per instance.
+	Redis *redis.Client
+}
+
+// rateLimitScript counts a hit in a fixed window and returns the count and
+// the window's remaining milliseconds. The expiry is repaired if a previous
+// caller died between INCR and PEXPIRE.
+var rateLimitScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}
+`)
+
+// RateLimit throttles requests per caller in fixed windows. Authenticated
+// callers are counted by user ID, everyone else by IP. Responses carry
+// X-RateLimit-Limit and X-RateLimit-Remaining; rejected ones also carry
+// Retry-After.
+func RateLimit(cfg RateLimitConfig) fiber.Handler {
+	if cfg.Max <= 0 {
+		cfg.Max = 10
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Second
+	}
+
+	local := newMemoryRateCounter()
+
+	return func(c *fiber.Ctx) error {
+		limit := cfg.Max
+		if cfg.Limit != nil {
+			if resolved := cfg.Limit(c); resolved > 0 {
+				limit = resolved
+			}
+		}
+
+		key := rateLimitKey(cfg.Identifier, c)
+		var (
+			count int64
+			ttl   time.Duration
+		)
+		shared := cfg.Redis != nil
+		if shared {
+			var err error
+			count, ttl, err = redisRateHit(c.UserContext(), cfg.Redis, key, cfg.Window)
+			shared = err == nil
+		}
+		if !shared {
+			count, ttl = local.hit(key, cfg.Window, time.Now())
+		}
+
+		remaining := int64(limit) - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+		c.Set("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+
+		if count > int64(limit) {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(ttl.Seconds()))))
+			return utils.Fail(c, fiber.StatusTooManyRequests, "too many requests", nil)
+		}
+
+		return c.Next()
+	}
+}
+
+// LimitByRole resolves the allowance from the authenticated caller's role.
+// Roles missing from limits, and anonymous callers, get the group's Max.
+func LimitByRole(limits map[string]int) func(c *fiber.Ctx) int {
+	return func(c *fiber.Ctx) int {
+		return limits[normalizeRoleValue(c.Locals("user_role"))]
+	}
+}
+
+func rateLimitKey(identifier string, c *fiber.Ctx) string {
+	if userID := c.Locals("user_id"); userID != nil {
+		if id := fmt.Sprintf("%v", userID); id != "" && id != "0" {
+			return fmt.Sprintf("ratelimit:%s:user:%s", identifier, id)
+		}
+	}
+	return fmt.Sprintf("ratelimit:%s:ip:%s", identifier, c.IP())
+}
+
+func redisRateHit(ctx context.Context, client *redis.Client, key string, window time.Duration) (int64, time.Duration, error) {
+	values, err := rateLimitScript.Run(ctx, client, []string{key}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, err
 	}
-	if window <= 0 {
-		window = time.Second
+	if len(values) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rate limit reply: %v", values)
 	}
+	return values[0], time.Duration(values[1]) * time.Millisecond, nil
+}
+
+// memoryRateCounter keeps fixed-window counters for a single instance.
+type memoryRateCounter struct {
+	mu        sync.Mutex
+	windows   map[string]rateWindow
+	nextSweep time.Time
+}
 
-	return limiter.New(limiter.Config{
-		Max:        max,
-		Expiration: window,
-		KeyGenerator: func(c *fiber.Ctx) string {
-			userID := fmt.Sprintf("%v", c.Locals("user_id"))
-			if userID == "" || userID == "0" {
-				userID = c.IP()
+type rateWindow struct {
+	count     int64
+	expiresAt time.Time
+}
+
+func newMemoryRateCounter() *memoryRateCounter {
+	return &memoryRateCounter{windows: make(map[string]rateWindow)}
+}
+
+func (m *memoryRateCounter) hit(key string, window time.Duration, now time.Time) (int64, time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.After(m.nextSweep) {
+		for k, w := range m.windows {
+			if !now.Before(w.expiresAt) {
+				delete(m.windows, k)
 			}
-			return fmt.Sprintf("%s:%s", identifier, userID)
-		},
-	})
+		}
+		m.nextSweep = now.Add(window)
+	}
+
+	current, ok := m.windows[key]
+	if !ok || !now.Before(current.expiresAt) {
+		current = rateWindow{expiresAt: now.Add(window)}
+	}
+	current.count++
+	m.windows[key] = current
+
+	return current.count, current.expiresAt.Sub(now)
 }
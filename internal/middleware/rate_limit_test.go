@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitedApp(cfg RateLimitConfig) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if id := c.Get("X-Test-User"); id != "" {
+			c.Locals("user_id", id)
+			c.Locals("user_role", c.Get("X-Test-Role"))
+		}
+		return c.Next()
+	})
+	app.Use(RateLimit(cfg))
+	app.Get("/probe", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	return app
+}
+
+func rateLimitedRequest(t *testing.T, app *fiber.App, userID, role string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/probe", nil)
+	if userID != "" {
+		req.Header.Set("X-Test-User", userID)
+		req.Header.Set("X-Test-Role", role)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestRateLimitSharesCountersAcrossInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cfg := RateLimitConfig{Identifier: "chat", Max: 2, Window: time.Minute, Redis: client}
+	first := newRateLimitedApp(cfg)
+	second := newRateLimitedApp(cfg)
+
+	resp := rateLimitedRequest(t, first, "7", "student")
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "2", resp.Header.Get("X-RateLimit-Limit"))
+	require.Equal(t, "1", resp.Header.Get("X-RateLimit-Remaining"))
+
+	resp = rateLimitedRequest(t, second, "7", "student")
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+
+	resp = rateLimitedRequest(t, first, "7", "student")
+	require.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, "60", resp.Header.Get(fiber.HeaderRetryAfter))
+
+	// Another user, and anonymous callers keyed by IP, have their own buckets.
+	resp = rateLimitedRequest(t, second, "8", "student")
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	resp = rateLimitedRequest(t, second, "", "")
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+
+	keys := mr.Keys()
+	require.Len(t, keys, 3)
+	require.Contains(t, keys, "ratelimit:chat:user:7")
+	require.Contains(t, keys, "ratelimit:chat:user:8")
+}
+
+func TestRateLimitResolvesLimitByRole(t *testing.T) {
+	app := newRateLimitedApp(RateLimitConfig{
+		Identifier: "chat",
+		Max:        1,
+		Window:     time.Minute,
+		Limit:      LimitByRole(map[string]int{"teacher": 3}),
+	})
+
+	for i := 0; i < 3; i++ {
+		resp := rateLimitedRequest(t, app, "1", "Teacher")
+		require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+		require.Equal(t, "3", resp.Header.Get("X-RateLimit-Limit"))
+	}
+	resp := rateLimitedRequest(t, app, "1", "teacher")
+	require.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+
+	resp = rateLimitedRequest(t, app, "2", "student")
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "1", resp.Header.Get("X-RateLimit-Limit"))
+	resp = rateLimitedRequest(t, app, "2", "student")
+	require.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	require.NotEmpty(t, resp.Header.Get(fiber.HeaderRetryAfter))
+}
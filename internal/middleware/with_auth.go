@@ -17,8 +17,9 @@ const (
 
 // AuthOptions configures the WithAuth helper.
 type AuthOptions struct {
-	Role        string
-	RequireUser bool
+	Role           string
+	RequireUser    bool
+	AllowAnonymous bool
 }
 
 // WithAuth wraps a handler with basic authentication/authorization guards.
@@ -29,7 +30,7 @@ func WithAuth(handler fiber.Handler, opts AuthOptions) fiber.Handler {
 	}
 
 	requireUser := opts.RequireUser
-	if !requireUser && role != AuthRoleAny {
+	if !requireUser && (role != AuthRoleAny || !opts.AllowAnonymous) {
 		requireUser = true
 	}
 
@@ -40,7 +41,7 @@ func WithAuth(handler fiber.Handler, opts AuthOptions) fiber.Handler {
 		}
 
 		if role == AuthRoleAny {
-			// Allow anonymous access when RequireUser=false; otherwise userID must exist.
+			// Allow anonymous access only when explicitly opted in; otherwise userID must exist.
 			if !requireUser || userID != nil {
 				return handler(c)
 			}
@@ -70,7 +70,7 @@ func TestWithAuthAnyAllowsAnonymousWhenOptedIn(t *testing.T) {
 	app := fiber.New()
 	app.Get("/", middleware.WithAuth(func(c *fiber.Ctx) error {
 		return c.SendStatus(fiber.StatusOK)
-	}, middleware.AuthOptions{Role: middleware.AuthRoleAny, RequireUser: false}))
+	}, middleware.AuthOptions{Role: middleware.AuthRoleAny, RequireUser: false, AllowAnonymous: true}))
 
 	resp := perform(t, app)
 	require.Equal(t, fiber.StatusOK, resp.StatusCode)
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"gorm.io/datatypes"
@@ -8,19 +9,46 @@ import (
 
 // Assignment represents a tutorial assignment definition.
 type Assignment struct {
-	ID          uint              `gorm:"primaryKey" json:"id"`
-	Title       string            `gorm:"size:255;not null" json:"title"`
-	Description string            `gorm:"type:text" json:"description"`
-	DueDate     time.Time         `gorm:"not null" json:"due_date"`
-	FileURL     string            `gorm:"size:512" json:"file_url"`
-	MaxScore    float64           `gorm:"not null;default:100" json:"max_score"`
-	Rubric      datatypes.JSONMap `gorm:"type:json" json:"rubric"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
-	Submissions []Submission
+	ID                 uint              `gorm:"primaryKey" json:"id"`
+	Title              string            `gorm:"size:255;not null" json:"title"`
+	Description        string            `gorm:"type:text" json:"description"`
+	DueDate            time.Time         `gorm:"not null" json:"due_date"`
+	FileURL            string            `gorm:"size:512" json:"file_url"`
+	MaxScore           float64           `gorm:"not null;default:100" json:"max_score"`
+	Rubric             datatypes.JSONMap `gorm:"type:json" json:"rubric"`
+	AllowLate          bool              `gorm:"not null;default:false" json:"allow_late"`
+	LatePenaltyPercent float64           `gorm:"not null;default:0" json:"late_penalty_percent"`
+	MaxSubmissionMB    int               `gorm:"not null;default:0" json:"max_submission_mb"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+	Submissions        []Submission
 }
 
 // IsPastDue returns true when the assignment deadline has already passed.
 func (a Assignment) IsPastDue(reference time.Time) bool {
 	return reference.After(a.DueDate)
 }
+
+// LatePenalty is the grade penalty applied to late submissions. Only
+// assignments that accept late work carry one; the rest reject it outright.
+func (a Assignment) LatePenalty() float64 {
+	if !a.AllowLate {
+		return 0
+	}
+	return a.LatePenaltyPercent
+}
+
+// ScorePercent expresses a raw score as a percentage of MaxScore, rounded to
+// two decimals. It returns nil when score is nil or MaxScore is not positive.
+func (a Assignment) ScorePercent(score *float64) *float64 {
+	if score == nil || a.MaxScore <= 0 {
+		return nil
+	}
+	percent := RoundPercent(*score / a.MaxScore * 100)
+	return &percent
+}
+
+// RoundPercent rounds a percentage to two decimals for display.
+func RoundPercent(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -1,35 +1,84 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+)
 
 // CodingSubmissionStatus enumerates possible submission states.
 const (
-	CodingSubmissionStatusPending   = "pending"
-	CodingSubmissionStatusCompleted = "completed"
-	CodingSubmissionStatusFailed    = "failed"
-	CodingSubmissionStatusTimeout   = "timeout"
-	CodingSubmissionStatusEvaluated = "evaluated"
+	CodingSubmissionStatusPending      = "pending"
+	CodingSubmissionStatusCompleted    = "completed"
+	CodingSubmissionStatusFailed       = "failed"
+	CodingSubmissionStatusCompileError = "compile_error"
+	CodingSubmissionStatusTimeout      = "timeout"
+	CodingSubmissionStatusEvaluated    = "evaluated"
 )
 
 // CodingSubmission represents a student's code submission for a coding task.
 type CodingSubmission struct {
-	ID          uint               `gorm:"primaryKey" json:"id"`
-	TaskID      uint               `gorm:"not null" json:"task_id"`
-	StudentID   uint               `gorm:"not null" json:"student_id"`
-	Language    string             `gorm:"size:32;not null" json:"language"`
-	Source      string             `gorm:"type:text" json:"source"`
-	Status      string             `gorm:"size:32;not null" json:"status"`
-	Output      string             `gorm:"type:text" json:"output"`
-	Error       string             `gorm:"type:text" json:"error"`
-	CPUTimeMs   int64              `gorm:"default:0" json:"cpu_time_ms"`
-	MemoryKB    int64              `gorm:"default:0" json:"memory_kb"`
-	CreatedAt   time.Time          `json:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at"`
-	Task        CodingTask         `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Evaluations []CodingEvaluation `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ID            uint               `gorm:"primaryKey" json:"id"`
+	TaskID        uint               `gorm:"not null" json:"task_id"`
+	StudentID     uint               `gorm:"not null" json:"student_id"`
+	Language      string             `gorm:"size:32;not null" json:"language"`
+	Source        string             `gorm:"type:text" json:"source"`
+	Stdin         string             `gorm:"type:text" json:"stdin"`
+	Status        string             `gorm:"size:32;not null" json:"status"`
+	Output        string             `gorm:"type:text" json:"output"`
+	Error         string             `gorm:"type:text" json:"error"`
+	CompileOutput string             `gorm:"type:text" json:"compile_output"`
+	CompileError  string             `gorm:"type:text" json:"compile_error"`
+	CPUTimeMs     int64              `gorm:"default:0" json:"cpu_time_ms"`
+	MemoryKB      int64              `gorm:"default:0" json:"memory_kb"`
+	Comparison    string             `gorm:"size:32" json:"output_comparison"`
+	Matched       *bool              `json:"output_matched"`
+	OutputDiff    string             `gorm:"type:text" json:"output_diff"`
+	Score         *float64           `json:"score"`
+	LogURL        string             `gorm:"size:512" json:"-"`
+	EnvNames      string             `gorm:"type:text" json:"-"`
+	TestResults   datatypes.JSON     `gorm:"type:json" json:"-"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	Task          CodingTask         `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Evaluations   []CodingEvaluation `gorm:"foreignKey:SubmissionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// EnvNameList returns the names of the task environment variables the
+// submission ran with.
+func (s CodingSubmission) EnvNameList() []string {
+	if s.EnvNames == "" {
+		return nil
+	}
+	return strings.Split(s.EnvNames, ",")
 }
 
 // HasBeenEvaluated reports whether the submission has evaluation feedback.
 func (s CodingSubmission) HasBeenEvaluated() bool {
 	return s.Status == CodingSubmissionStatusEvaluated
 }
+
+// SetTestResults serializes per-case grading results into the JSON column.
+func (s *CodingSubmission) SetTestResults(results []CodingTestCaseResult) {
+	data, err := json.Marshal(results)
+	if err != nil {
+		s.TestResults = datatypes.JSON([]byte("[]"))
+		return
+	}
+	s.TestResults = datatypes.JSON(data)
+}
+
+// TestResultList deserializes the stored per-case grading results.
+func (s CodingSubmission) TestResultList() []CodingTestCaseResult {
+	if len(s.TestResults) == 0 {
+		return nil
+	}
+
+	var results []CodingTestCaseResult
+	if err := json.Unmarshal(s.TestResults, &results); err != nil {
+		return nil
+	}
+	return results
+}
@@ -1,22 +1,53 @@
 package models
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Output comparison modes applied when checking program output against ExpectedOutput.
+const (
+	OutputComparisonExact      = "exact"
+	OutputComparisonTrimmed    = "trimmed"
+	OutputComparisonWhitespace = "whitespace"
+	OutputComparisonNumeric    = "numeric"
 )
 
 // CodingTask represents a coding lab exercise available to students.
 type CodingTask struct {
-	ID             uint      `gorm:"primaryKey" json:"id"`
-	Title          string    `gorm:"size:255;not null" json:"title"`
-	Prompt         string    `gorm:"type:text;not null" json:"prompt"`
-	StarterCode    string    `gorm:"type:text" json:"starter_code"`
-	Language       string    `gorm:"size:32;not null" json:"language"`
-	Difficulty     string    `gorm:"size:32;not null" json:"difficulty"`
-	Tags           string    `gorm:"type:text" json:"tags"`
-	ExpectedOutput string    `gorm:"type:text" json:"expected_output"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             uint                 `gorm:"primaryKey" json:"id"`
+	Title          string               `gorm:"size:255;not null" json:"title"`
+	Prompt         string               `gorm:"type:text;not null" json:"prompt"`
+	StarterCode    string               `gorm:"type:text" json:"starter_code"`
+	Language       string               `gorm:"size:32;not null" json:"language"`
+	Difficulty     string               `gorm:"size:32;not null" json:"difficulty"`
+	Tags           string               `gorm:"type:text" json:"tags"`
+	ExpectedOutput string               `gorm:"type:text" json:"expected_output"`
+	Active         bool                 `gorm:"not null;default:true" json:"active"`
+	Comparison     string               `gorm:"size:32;not null;default:'exact'" json:"output_comparison"`
+	Tolerance      float64              `gorm:"not null;default:0" json:"numeric_tolerance"`
+	Env            datatypes.JSONMap    `gorm:"type:json" json:"-"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+	TestCases      []CodingTaskTestCase `gorm:"foreignKey:TaskID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// ComparisonMode returns the configured output comparison, defaulting to exact.
+func (t CodingTask) ComparisonMode() string {
+	switch strings.ToLower(strings.TrimSpace(t.Comparison)) {
+	case OutputComparisonTrimmed:
+		return OutputComparisonTrimmed
+	case OutputComparisonWhitespace:
+		return OutputComparisonWhitespace
+	case OutputComparisonNumeric:
+		return OutputComparisonNumeric
+	default:
+		return OutputComparisonExact
+	}
 }
 
 // TagsSlice returns the tags as a slice of strings.
@@ -35,3 +66,34 @@ func (t CodingTask) TagsSlice() []string {
 	}
 	return tags
 }
+
+// EnvVars returns the task's environment variables with string values.
+func (t CodingTask) EnvVars() map[string]string {
+	if len(t.Env) == 0 {
+		return nil
+	}
+
+	vars := make(map[string]string, len(t.Env))
+	for name, value := range t.Env {
+		if text, ok := value.(string); ok {
+			vars[name] = text
+		} else {
+			vars[name] = fmt.Sprint(value)
+		}
+	}
+	return vars
+}
+
+// EnvNames returns the sorted names of the task's environment variables.
+func (t CodingTask) EnvNames() []string {
+	if len(t.Env) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(t.Env))
+	for name := range t.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// CodingTaskTestCase is a single autograder case for a coding task. Hidden
+// cases are graded but never reveal their input or expected output to students.
+type CodingTaskTestCase struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	TaskID         uint      `gorm:"not null;index" json:"task_id"`
+	Input          string    `gorm:"type:text" json:"input"`
+	ExpectedOutput string    `gorm:"type:text" json:"expected_output"`
+	Weight         float64   `gorm:"not null;default:1" json:"weight"`
+	Hidden         bool      `gorm:"not null;default:false" json:"hidden"`
+	Position       int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// EffectiveWeight returns the case weight, treating non-positive weights as 1.
+func (c CodingTaskTestCase) EffectiveWeight() float64 {
+	if c.Weight <= 0 {
+		return 1
+	}
+	return c.Weight
+}
+
+// CodingTestCaseResult records how a submission fared against one test case.
+type CodingTestCaseResult struct {
+	TestCaseID   uint    `json:"test_case_id"`
+	Passed       bool    `json:"passed"`
+	Hidden       bool    `json:"hidden"`
+	Weight       float64 `json:"weight"`
+	ActualOutput string  `json:"actual_output,omitempty"`
+	Error        string  `json:"error,omitempty"`
+	TimedOut     bool    `json:"timed_out,omitempty"`
+	CPUTimeMs    int64   `json:"cpu_time_ms"`
+}
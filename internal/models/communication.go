@@ -18,26 +18,84 @@ type ChatMessage struct {
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
+// ChatReadReceipt tracks how far a user has read within a chat room.
+type ChatReadReceipt struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     string    `gorm:"size:64;uniqueIndex:idx_chat_read_receipt_user_room" json:"user_id"`
+	RoomID     string    `gorm:"size:128;uniqueIndex:idx_chat_read_receipt_user_room" json:"room_id"`
+	LastReadAt time.Time `gorm:"not null" json:"last_read_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ChatRoom is a group conversation whose members are listed explicitly instead
+// of being derived from the room name.
+type ChatRoom struct {
+	ID        uint             `gorm:"primaryKey" json:"id"`
+	RoomID    string           `gorm:"size:128;uniqueIndex" json:"room_id"`
+	Name      string           `gorm:"size:255" json:"name"`
+	CreatedBy string           `gorm:"size:64;index" json:"created_by"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Members   []ChatRoomMember `gorm:"foreignKey:RoomID;references:RoomID" json:"members"`
+}
+
+// ChatRoomMember grants a user access to a ChatRoom.
+type ChatRoomMember struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	RoomID    string    `gorm:"size:128;uniqueIndex:idx_chat_room_member" json:"room_id"`
+	UserID    string    `gorm:"size:64;uniqueIndex:idx_chat_room_member;index" json:"user_id"`
+	AddedBy   string    `gorm:"size:64" json:"added_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Notification priorities. High priority notifications are critical alerts:
+// they are listed first and are delivered even when their type is muted.
+const (
+	NotificationPriorityLow    = "low"
+	NotificationPriorityNormal = "normal"
+	NotificationPriorityHigh   = "high"
+)
+
 // Notification represents a push notification targeted to a specific user.
 type Notification struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	UserID    string    `gorm:"size:64;index" json:"user_id"`
 	Type      string    `gorm:"size:64" json:"type"`
 	Message   string    `gorm:"type:text" json:"message"`
+	Priority  string    `gorm:"size:16;not null;default:normal" json:"priority"`
 	Read      bool      `gorm:"not null;default:false" json:"read"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// NotificationMute silences one notification type for a user.
+type NotificationMute struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    string    `gorm:"size:64;uniqueIndex:idx_notification_mute" json:"user_id"`
+	Type      string    `gorm:"size:64;uniqueIndex:idx_notification_mute" json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Discussion thread statuses.
+const (
+	DiscussionThreadOpen     = "open"
+	DiscussionThreadLocked   = "locked"
+	DiscussionThreadArchived = "archived"
+)
+
 // DiscussionThread represents a discussion forum topic.
 type DiscussionThread struct {
 	ID        uint              `gorm:"primaryKey" json:"id"`
 	Title     string            `gorm:"size:255;not null" json:"title"`
 	AuthorID  string            `gorm:"size:64;index" json:"author_id"`
 	Metadata  datatypes.JSONMap `gorm:"type:json" json:"metadata"`
+	Pinned    bool              `gorm:"not null;default:false" json:"pinned"`
+	Status    string            `gorm:"size:16;not null;default:open;index" json:"status"`
+	ClosedAt  *time.Time        `json:"closed_at"`
 	CreatedAt time.Time         `json:"created_at"`
 	UpdatedAt time.Time         `json:"updated_at"`
-	Replies   []DiscussionReply `json:"replies"`
+	Replies   []DiscussionReply `gorm:"foreignKey:ThreadID" json:"replies"`
 }
 
 // DiscussionReply represents a reply within a discussion thread.
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"strings"
 	"time"
 
@@ -8,29 +9,102 @@ import (
 )
 
 // Announcement represents a broadcast message displayed to end users.
+// Audience lists the class names and roles it is meant for; an empty
+// audience means everyone.
 type Announcement struct {
-	ID        uint       `gorm:"primaryKey" json:"id"`
-	Slug      string     `gorm:"size:128;uniqueIndex" json:"slug"`
-	Title     string     `gorm:"size:255;not null" json:"title"`
-	Body      string     `gorm:"type:text;not null" json:"body"`
-	StartsAt  time.Time  `gorm:"index" json:"starts_at"`
-	EndsAt    *time.Time `gorm:"index" json:"ends_at"`
-	IsPinned  bool       `gorm:"index" json:"is_pinned"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Slug        string     `gorm:"size:128;uniqueIndex" json:"slug"`
+	Title       string     `gorm:"size:255;not null" json:"title"`
+	Body        string     `gorm:"type:text;not null" json:"body"`
+	StartsAt    time.Time  `gorm:"index" json:"starts_at"`
+	EndsAt      *time.Time `gorm:"index" json:"ends_at"`
+	IsPinned    bool       `gorm:"index" json:"is_pinned"`
+	AudienceRaw string     `gorm:"column:audience;type:text" json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Audience    []string   `gorm:"-" json:"audience"`
+}
+
+// BeforeSave stores the audience as a JSON array of lowercase entries.
+func (a *Announcement) BeforeSave(tx *gorm.DB) error {
+	a.Audience = NormalizeAudience(a.Audience)
+	a.AudienceRaw = ""
+	if len(a.Audience) > 0 {
+		encoded, err := json.Marshal(a.Audience)
+		if err != nil {
+			return err
+		}
+		a.AudienceRaw = string(encoded)
+	}
+	return nil
+}
+
+// AfterFind hydrates the audience list after retrieval.
+func (a *Announcement) AfterFind(tx *gorm.DB) error {
+	a.Audience = []string{}
+	if strings.TrimSpace(a.AudienceRaw) == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(a.AudienceRaw), &a.Audience)
+}
+
+// NormalizeAudience lowercases and trims audience entries, dropping blanks
+// and duplicates so stored audiences compare exactly against viewers.
+func NormalizeAudience(entries []string) []string {
+	normalized := make([]string, 0, len(entries))
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		value := strings.ToLower(strings.TrimSpace(entry))
+		if value == "" {
+			continue
+		}
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		normalized = append(normalized, value)
+	}
+	return normalized
+}
+
+const (
+	// AnnouncementStatusScheduled marks an announcement whose start is still ahead.
+	AnnouncementStatusScheduled = "scheduled"
+	// AnnouncementStatusActive marks an announcement currently shown to users.
+	AnnouncementStatusActive = "active"
+	// AnnouncementStatusExpired marks an announcement whose end has passed.
+	AnnouncementStatusExpired = "expired"
+)
+
+// Status reports where now falls in the announcement's publishing window.
+// The window includes both StartsAt and EndsAt.
+func (a Announcement) Status(now time.Time) string {
+	switch {
+	case now.Before(a.StartsAt):
+		return AnnouncementStatusScheduled
+	case a.EndsAt != nil && now.After(*a.EndsAt):
+		return AnnouncementStatusExpired
+	default:
+		return AnnouncementStatusActive
+	}
 }
 
 // GalleryItem captures media published in the public gallery.
 type GalleryItem struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Slug      string    `gorm:"size:128;uniqueIndex" json:"slug"`
-	Title     string    `gorm:"size:255;not null" json:"title"`
-	Caption   string    `gorm:"type:text" json:"caption"`
-	ImagePath string    `gorm:"size:512;not null" json:"image_path"`
-	TagsRaw   string    `gorm:"column:tags;type:text" json:"-"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Tags      []string  `gorm:"-" json:"tags"`
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Slug          string    `gorm:"size:128;uniqueIndex" json:"slug"`
+	Title         string    `gorm:"size:255;not null" json:"title"`
+	Caption       string    `gorm:"type:text" json:"caption"`
+	ImagePath     string    `gorm:"size:512;not null" json:"image_path"`
+	ThumbnailPath string    `gorm:"size:512" json:"thumbnail_path"`
+	Width         int       `json:"width"`
+	Height        int       `json:"height"`
+	Featured      bool      `gorm:"not null;default:false;index" json:"featured"`
+	SortOrder     int       `gorm:"not null;default:0" json:"sort_order"`
+	TagsRaw       string    `gorm:"column:tags;type:text" json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Tags          []string  `gorm:"-" json:"tags"`
 }
 
 // BeforeSave normalises tag data before persisting.
@@ -80,29 +154,63 @@ func decodeTags(raw string) []string {
 	return tags
 }
 
-// ContactSubmission stores inbound enquiries.
+// ContactSubmission stores inbound enquiries. The redelivery job leases queued
+// submissions through ClaimedBy and ClaimedUntil so that replicas do not email
+// the same submission side by side.
 type ContactSubmission struct {
-	ID          uint       `gorm:"primaryKey" json:"id"`
-	ReferenceID string     `gorm:"size:64;uniqueIndex" json:"reference_id"`
-	Name        string     `gorm:"size:128;not null" json:"name"`
-	Email       string     `gorm:"size:160;not null" json:"email"`
-	Message     string     `gorm:"type:text;not null" json:"message"`
-	Source      string     `gorm:"size:64" json:"source"`
-	Status      string     `gorm:"size:32;not null" json:"status"`
-	Checksum    string     `gorm:"size:128;index" json:"checksum"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	DeliveredAt *time.Time `json:"delivered_at"`
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	ReferenceID  string     `gorm:"size:64;uniqueIndex" json:"reference_id"`
+	Name         string     `gorm:"size:128;not null" json:"name"`
+	Email        string     `gorm:"size:160;not null" json:"email"`
+	Message      string     `gorm:"type:text;not null" json:"message"`
+	Source       string     `gorm:"size:64" json:"source"`
+	Status       string     `gorm:"size:32;not null" json:"status"`
+	Checksum     string     `gorm:"size:128;index" json:"checksum"`
+	ClaimedBy    string     `gorm:"size:64" json:"-"`
+	ClaimedUntil *time.Time `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeliveredAt  *time.Time `json:"delivered_at"`
 }
 
-// UploadRecord stores metadata about uploaded files.
+const (
+	// ContactStatusQueued marks a submission stored but not yet delivered.
+	ContactStatusQueued = "queued"
+	// ContactStatusSent marks a submission delivered to the support team.
+	ContactStatusSent = "sent"
+	// ContactStatusRead marks a submission an admin has opened for triage.
+	ContactStatusRead = "read"
+	// ContactStatusResolved marks a submission that needs no further action.
+	ContactStatusResolved = "resolved"
+	// ContactStatusSpam marks a submission dismissed as spam.
+	ContactStatusSpam = "spam"
+)
+
+// UploadRecord stores metadata about uploaded files. Checksum holds
+// "<algorithm>:<hex digest>" as built by UploadChecksum.
 type UploadRecord struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	UserID    *uint     `gorm:"index" json:"user_id"`
-	FileName  string    `gorm:"size:255;not null" json:"file_name"`
-	URL       string    `gorm:"size:512;not null" json:"url"`
-	MimeType  string    `gorm:"size:128;not null" json:"mime_type"`
-	SizeBytes int64     `gorm:"not null" json:"size_bytes"`
-	Checksum  string    `gorm:"size:128;index" json:"checksum"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	UserID    *uint          `gorm:"index" json:"user_id"`
+	FileName  string         `gorm:"size:255;not null" json:"file_name"`
+	URL       string         `gorm:"size:512;not null" json:"url"`
+	MimeType  string         `gorm:"size:128;not null" json:"mime_type"`
+	SizeBytes int64          `gorm:"not null" json:"size_bytes"`
+	Checksum  string         `gorm:"size:128;index" json:"checksum"`
+	Width     int            `json:"width"`
+	Height    int            `json:"height"`
+	CreatedAt time.Time      `json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+const (
+	// UploadChecksumSHA256 labels digests computed by the API over the stored bytes.
+	UploadChecksumSHA256 = "sha256"
+	// UploadChecksumMD5 labels Cloudinary etags recorded for direct uploads.
+	UploadChecksumMD5 = "md5"
+)
+
+// UploadChecksum formats a digest with its algorithm, so checksums computed
+// different ways never compare equal.
+func UploadChecksum(algorithm, digest string) string {
+	return algorithm + ":" + strings.ToLower(digest)
 }
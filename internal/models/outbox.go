@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// OutboxEvent is a cross-node event written in the same transaction as the
+// rows it announces and published afterwards by a dispatcher, so a crash
+// between the commit and the publish delays the event instead of losing it.
+// A dispatcher leases unsent events through ClaimedBy and ClaimedUntil so
+// that nodes do not publish the same event side by side.
+type OutboxEvent struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Topic        string     `gorm:"size:64;not null;index:idx_outbox_pending,priority:1" json:"topic"`
+	Payload      string     `gorm:"type:text;not null" json:"payload"`
+	Attempts     int        `gorm:"not null;default:0" json:"attempts"`
+	LastError    string     `gorm:"type:text" json:"last_error"`
+	ClaimedBy    string     `gorm:"size:64" json:"claimed_by"`
+	ClaimedUntil *time.Time `json:"claimed_until"`
+	SentAt       *time.Time `gorm:"index:idx_outbox_pending,priority:2" json:"sent_at"`
+	CreatedAt    time.Time  `gorm:"index" json:"created_at"`
+}
+
+// TableName stores events in the outbox table.
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
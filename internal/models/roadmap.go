@@ -40,3 +40,13 @@ func (r *RoadmapStage) AfterFind(tx *gorm.DB) error {
 	r.Tags = decodeTags(r.TagsRaw)
 	return nil
 }
+
+// RoadmapProgress marks a roadmap stage as completed by a student. A student
+// completes a stage at most once.
+type RoadmapProgress struct {
+	ID          uint      `gorm:"primaryKey"`
+	StudentID   uint      `gorm:"not null;uniqueIndex:idx_roadmap_progress_student_stage"`
+	StageID     uint      `gorm:"not null;uniqueIndex:idx_roadmap_progress_student_stage;index"`
+	CompletedAt time.Time `gorm:"not null"`
+	CreatedAt   time.Time
+}
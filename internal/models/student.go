@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/datatypes"
@@ -33,6 +34,18 @@ type Student struct {
 	DeletedAt gorm.DeletedAt    `gorm:"index" json:"-"`
 }
 
+// BeforeSave stores the email in the form NormalizeEmail gives it, so the
+// unique index treats addresses differing only in case as the same student.
+func (s *Student) BeforeSave(tx *gorm.DB) error {
+	s.Email = NormalizeEmail(s.Email)
+	return nil
+}
+
+// NormalizeEmail trims and lowercases an email address.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 // IsActive returns true when the student is active and not soft deleted.
 func (s Student) IsActive() bool {
 	return s.Status == StudentStatusActive && s.DeletedAt.Valid == false
@@ -1,23 +1,37 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
 
 // Submission represents a file submitted by a student for an assignment.
 type Submission struct {
-	ID           uint                     `gorm:"primaryKey" json:"id"`
-	AssignmentID uint                     `gorm:"not null" json:"assignment_id"`
-	StudentID    uint                     `gorm:"not null" json:"student_id"`
-	FileURL      string                   `gorm:"size:512" json:"file_url"`
-	Status       string                   `gorm:"size:32;not null" json:"status"`
-	Grade        *float64                 `json:"grade"`
-	Feedback     string                   `gorm:"type:text" json:"feedback"`
-	GradedBy     *uint                    `json:"graded_by"`
-	GradedAt     *time.Time               `json:"graded_at"`
-	CreatedAt    time.Time                `json:"created_at"`
-	UpdatedAt    time.Time                `json:"updated_at"`
-	Assignment   Assignment               `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"assignment"`
-	Student      Student                  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"student"`
-	History      []SubmissionGradeHistory `gorm:"foreignKey:SubmissionID" json:"history"`
+	ID                 uint                     `gorm:"primaryKey" json:"id"`
+	AssignmentID       uint                     `gorm:"not null" json:"assignment_id"`
+	StudentID          uint                     `gorm:"not null" json:"student_id"`
+	Version            int                      `gorm:"not null;default:1" json:"version"`
+	FileURL            string                   `gorm:"size:512" json:"file_url"`
+	Status             string                   `gorm:"size:32;not null" json:"status"`
+	Grade              *float64                 `json:"grade"`
+	Feedback           string                   `gorm:"type:text" json:"feedback"`
+	RubricScores       datatypes.JSONMap        `gorm:"type:json" json:"rubric_scores"`
+	Late               bool                     `gorm:"not null;default:false" json:"late"`
+	LatePenaltyPercent float64                  `gorm:"not null;default:0" json:"late_penalty_percent"`
+	SimilarityScore    *float64                 `json:"similarity_score"`
+	SimilarityMatchID  *uint                    `json:"similarity_match_id"`
+	GradedBy           *uint                    `json:"graded_by"`
+	GradedAt           *time.Time               `json:"graded_at"`
+	ClaimedBy          *uint                    `gorm:"index" json:"-"`
+	ClaimExpiresAt     *time.Time               `json:"-"`
+	CreatedAt          time.Time                `json:"created_at"`
+	UpdatedAt          time.Time                `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt           `gorm:"index" json:"-"`
+	Assignment         Assignment               `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"assignment"`
+	Student            Student                  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"student"`
+	History            []SubmissionGradeHistory `gorm:"foreignKey:SubmissionID" json:"history"`
 }
 
 const (
@@ -25,6 +39,8 @@ const (
 	SubmissionStatusSubmitted = "submitted"
 	// SubmissionStatusGraded indicates the submission has been evaluated.
 	SubmissionStatusGraded = "graded"
+	// SubmissionStatusLate indicates the submission was accepted after the due date and awaits grading.
+	SubmissionStatusLate = "late"
 )
 
 // IsGraded reports whether the submission has a final grade.
@@ -32,13 +48,40 @@ func (s Submission) IsGraded() bool {
 	return s.Status == SubmissionStatusGraded
 }
 
-// SubmissionGradeHistory captures the evolution of grading decisions over time.
-type SubmissionGradeHistory struct {
+// PenalizedGrade applies the recorded late penalty to the grade, if any.
+func (s Submission) PenalizedGrade() *float64 {
+	if s.Grade == nil {
+		return nil
+	}
+	final := *s.Grade
+	if s.Late && s.LatePenaltyPercent > 0 {
+		final = final * (1 - s.LatePenaltyPercent/100)
+	}
+	return &final
+}
+
+// SubmissionFingerprint stores the shingle sketch used to compare submissions for similarity.
+type SubmissionFingerprint struct {
 	ID           uint      `gorm:"primaryKey" json:"id"`
-	SubmissionID uint      `gorm:"index;not null" json:"submission_id"`
-	Score        float64   `gorm:"not null" json:"score"`
-	Feedback     string    `gorm:"type:text" json:"feedback"`
-	GradedBy     uint      `gorm:"not null" json:"graded_by"`
-	GradedAt     time.Time `gorm:"not null" json:"graded_at"`
+	SubmissionID uint      `gorm:"uniqueIndex;not null" json:"submission_id"`
+	AssignmentID uint      `gorm:"index;not null" json:"assignment_id"`
+	StudentID    uint      `gorm:"not null" json:"student_id"`
+	Hashes       string    `gorm:"type:text;not null" json:"-"`
+	ShingleCount int       `gorm:"not null" json:"shingle_count"`
 	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SubmissionGradeHistory captures the evolution of grading decisions over time.
+// PreviousScore is nil for a first grade; overrides carry the grader's Reason.
+type SubmissionGradeHistory struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	SubmissionID  uint      `gorm:"index;not null" json:"submission_id"`
+	PreviousScore *float64  `json:"previous_score"`
+	Score         float64   `gorm:"not null" json:"score"`
+	Feedback      string    `gorm:"type:text" json:"feedback"`
+	Reason        string    `gorm:"type:text" json:"reason"`
+	GradedBy      uint      `gorm:"not null" json:"graded_by"`
+	GradedAt      time.Time `gorm:"not null" json:"graded_at"`
+	CreatedAt     time.Time `json:"created_at"`
 }
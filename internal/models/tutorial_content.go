@@ -22,7 +22,8 @@ type TutorialArticle struct {
 	PublishedAt    *time.Time `gorm:"index"`
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
-	Tags           []string `gorm:"-"`
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	Tags           []string       `gorm:"-"`
 }
 
 // TutorialProject stores project-based tutorial content.
@@ -40,7 +41,8 @@ type TutorialProject struct {
 	Status         string `gorm:"size:32;default:'draft'"`
 	UpdatedAt      time.Time
 	CreatedAt      time.Time
-	Tags           []string `gorm:"-"`
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	Tags           []string       `gorm:"-"`
 }
 
 // BeforeSave normalises article data prior to persistence.
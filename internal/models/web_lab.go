@@ -9,14 +9,16 @@ import (
 
 // WebAssignment represents a frontend lab assignment definition.
 type WebAssignment struct {
-	ID           uint            `gorm:"primaryKey" json:"id"`
-	Title        string          `gorm:"size:255;not null" json:"title"`
-	Requirements string          `gorm:"type:text" json:"requirements"`
-	Assets       datatypes.JSON  `gorm:"type:json" json:"-"`
-	Rubric       string          `gorm:"type:text" json:"rubric"`
-	CreatedAt    time.Time       `json:"created_at"`
-	UpdatedAt    time.Time       `json:"updated_at"`
-	Submissions  []WebSubmission `gorm:"foreignKey:AssignmentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ID              uint            `gorm:"primaryKey" json:"id"`
+	Title           string          `gorm:"size:255;not null" json:"title"`
+	Requirements    string          `gorm:"type:text" json:"requirements"`
+	Assets          datatypes.JSON  `gorm:"type:json" json:"-"`
+	Rubric          string          `gorm:"type:text" json:"rubric"`
+	ReferenceZipURL string          `gorm:"size:512" json:"-"`
+	MaxSubmissionMB int             `gorm:"not null;default:0" json:"max_submission_mb"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	Submissions     []WebSubmission `gorm:"foreignKey:AssignmentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
 // SetAssets serializes the provided asset list into the JSON storage column.
@@ -45,17 +47,20 @@ func (a WebAssignment) AssetList() []string {
 
 // WebSubmission models a student's submission for a web lab assignment.
 type WebSubmission struct {
-	ID           uint          `gorm:"primaryKey" json:"id"`
-	AssignmentID uint          `gorm:"not null" json:"assignment_id"`
-	StudentID    uint          `gorm:"not null" json:"student_id"`
-	ZipURL       string        `gorm:"size:512" json:"zip_url"`
-	Status       string        `gorm:"size:32;not null" json:"status"`
-	Feedback     string        `gorm:"type:text" json:"feedback"`
-	Score        *float64      `json:"score"`
-	CreatedAt    time.Time     `json:"created_at"`
-	UpdatedAt    time.Time     `json:"updated_at"`
-	Assignment   WebAssignment `gorm:"foreignKey:AssignmentID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"assignment"`
-	Student      Student       `gorm:"foreignKey:StudentID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"student"`
+	ID           uint     `gorm:"primaryKey" json:"id"`
+	AssignmentID uint     `gorm:"not null" json:"assignment_id"`
+	StudentID    uint     `gorm:"not null" json:"student_id"`
+	ZipURL       string   `gorm:"size:512" json:"zip_url"`
+	Status       string   `gorm:"size:32;not null" json:"status"`
+	Feedback     string   `gorm:"type:text" json:"feedback"`
+	Score        *float64 `json:"score"`
+	// Checksum is the SHA-256 of the uploaded archive, used to make sure a
+	// regrade analyses the same bytes that were originally submitted.
+	Checksum   string        `gorm:"size:64" json:"-"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	Assignment WebAssignment `gorm:"foreignKey:AssignmentID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"assignment"`
+	Student    Student       `gorm:"foreignKey:StudentID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"student"`
 }
 
 const (
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// WebhookSubscription registers an external URL to receive one event type.
+// Deliveries are signed with Secret, which is never serialised.
+type WebhookSubscription struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	EventType string    `gorm:"size:64;not null;index" json:"event_type"`
+	URL       string    `gorm:"size:512;not null" json:"url"`
+	Secret    string    `gorm:"size:128;not null" json:"-"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDeadLetter records a webhook delivery that failed every attempt,
+// keeping the payload so it can be inspected or replayed by hand.
+type WebhookDeadLetter struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint           `gorm:"index;not null" json:"subscription_id"`
+	DeliveryID     string         `gorm:"size:64;not null" json:"delivery_id"`
+	EventType      string         `gorm:"size:64;not null" json:"event_type"`
+	URL            string         `gorm:"size:512;not null" json:"url"`
+	Payload        datatypes.JSON `gorm:"type:json" json:"payload"`
+	Attempts       int            `gorm:"not null" json:"attempts"`
+	LastError      string         `gorm:"size:512" json:"last_error"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
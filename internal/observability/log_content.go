@@ -0,0 +1,74 @@
+package observability
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// DefaultLogContentMaxLength is how many characters of user content a log
+// entry keeps when no policy has been configured.
+const DefaultLogContentMaxLength = 64
+
+// LogContentPolicy controls how user-generated content (chat messages,
+// discussion titles, contact messages) appears in logs. Redact, the default,
+// keeps only its length. Without it content is truncated to MaxLength
+// characters with email addresses masked.
+type LogContentPolicy struct {
+	MaxLength int
+	Redact    bool
+}
+
+var (
+	logContentPolicy atomic.Value
+	logEmailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+// SetLogContentPolicy replaces the process-wide policy used by LogContent. A
+// non-positive MaxLength falls back to DefaultLogContentMaxLength.
+func SetLogContentPolicy(policy LogContentPolicy) {
+	if policy.MaxLength <= 0 {
+		policy.MaxLength = DefaultLogContentMaxLength
+	}
+	logContentPolicy.Store(policy)
+}
+
+// CurrentLogContentPolicy returns the policy LogContent applies. Content is
+// redacted until a policy is set.
+func CurrentLogContentPolicy() LogContentPolicy {
+	if policy, ok := logContentPolicy.Load().(LogContentPolicy); ok {
+		return policy
+	}
+	return LogContentPolicy{MaxLength: DefaultLogContentMaxLength, Redact: true}
+}
+
+// LogContent prepares user content for a log field under the current policy.
+// Full message bodies never reach the log.
+func LogContent(value string) string {
+	return CurrentLogContentPolicy().Apply(value)
+}
+
+// Apply prepares value for logging under the policy.
+func (p LogContentPolicy) Apply(value string) string {
+	length := utf8.RuneCountInString(value)
+	if p.Redact {
+		return fmt.Sprintf("[redacted %d chars]", length)
+	}
+	if length == 0 {
+		return ""
+	}
+
+	maxLength := p.MaxLength
+	if maxLength <= 0 {
+		maxLength = DefaultLogContentMaxLength
+	}
+
+	preview := strings.Join(strings.Fields(value), " ")
+	preview = logEmailPattern.ReplaceAllString(preview, "[email]")
+	if runes := []rune(preview); len(runes) > maxLength {
+		preview = fmt.Sprintf("%s… [%d chars]", string(runes[:maxLength]), length)
+	}
+	return preview
+}
@@ -1,13 +1,19 @@
 package observability
 
 import (
+	"database/sql"
+	"errors"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 var (
 	registerOnce                sync.Once
+	httpRequestsTotal           *prometheus.CounterVec
+	httpRequestDuration         *prometheus.HistogramVec
+	httpRequestsInFlight        *prometheus.GaugeVec
 	adminRequestsTotal          *prometheus.CounterVec
 	adminLatencySeconds         *prometheus.HistogramVec
 	adminErrorsTotal            *prometheus.CounterVec
@@ -36,6 +42,22 @@ var (
 // RegisterMetrics initialises the Prometheus collectors used for admin observability.
 func RegisterMetrics() {
 	registerOnce.Do(func() {
+		httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests served segmented by route pattern.",
+		}, []string{"method", "route", "status"})
+
+		httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Latency distribution for HTTP requests segmented by route pattern.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0},
+		}, []string{"method", "route", "status"})
+
+		httpRequestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests currently being served, including open websocket and SSE streams.",
+		}, []string{"method"})
+
 		adminRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name: "admin_requests_total",
 			Help: "Total number of admin API requests served.",
@@ -159,6 +181,9 @@ func RegisterMetrics() {
 		})
 
 		prometheus.MustRegister(
+			httpRequestsTotal,
+			httpRequestDuration,
+			httpRequestsInFlight,
 			adminRequestsTotal,
 			adminLatencySeconds,
 			adminErrorsTotal,
@@ -186,6 +211,24 @@ func RegisterMetrics() {
 	})
 }
 
+// HTTPRequests exposes the counter for every HTTP request.
+func HTTPRequests() *prometheus.CounterVec {
+	RegisterMetrics()
+	return httpRequestsTotal
+}
+
+// HTTPRequestDuration exposes the latency histogram for every HTTP request.
+func HTTPRequestDuration() *prometheus.HistogramVec {
+	RegisterMetrics()
+	return httpRequestDuration
+}
+
+// HTTPRequestsInFlight exposes the gauge of requests being served.
+func HTTPRequestsInFlight() *prometheus.GaugeVec {
+	RegisterMetrics()
+	return httpRequestsInFlight
+}
+
 // AdminRequests exposes the counter for admin requests.
 func AdminRequests() *prometheus.CounterVec {
 	RegisterMetrics()
@@ -323,3 +366,15 @@ func UploadLatency() prometheus.Histogram {
 	RegisterMetrics()
 	return uploadLatency
 }
+
+// RegisterDBStats publishes the pool statistics of db (open, in-use and idle
+// connections, waits and closes) as go_sql_* gauges labelled with name.
+// Registering the same name twice is a no-op.
+func RegisterDBStats(db *sql.DB, name string) error {
+	err := prometheus.Register(collectors.NewDBStatsCollector(db, name))
+	var already prometheus.AlreadyRegisteredError
+	if errors.As(err, &already) {
+		return nil
+	}
+	return err
+}
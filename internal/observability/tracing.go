@@ -0,0 +1,98 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// otlpTracesPath is appended to a base collector endpoint, as the
+// OpenTelemetry specification does for OTEL_EXPORTER_OTLP_ENDPOINT.
+const otlpTracesPath = "/v1/traces"
+
+// TracingConfig configures the OTLP trace exporter.
+type TracingConfig struct {
+	// Endpoint is the collector's OTLP/HTTP base URL, e.g.
+	// http://otel-collector:4318. Tracing stays disabled when it is empty.
+	Endpoint       string
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+}
+
+// ConfigurePropagation installs the W3C trace context and baggage propagators
+// so spans can be continued across Redis and NATS events.
+func ConfigurePropagation() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+// InitTracing installs a tracer provider that batches spans to the OTLP/HTTP
+// collector at cfg.Endpoint. Without an endpoint it only configures
+// propagation and spans stay no-ops. The returned function flushes pending
+// spans and must be called on shutdown.
+func InitTracing(ctx context.Context, cfg TracingConfig) (func(context.Context) error, error) {
+	ConfigurePropagation()
+
+	if strings.TrimSpace(cfg.Endpoint) == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	options, err := otlpEndpointOptions(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			semconv.ServiceName(cfg.ServiceName),
+			semconv.ServiceVersion(cfg.ServiceVersion),
+			semconv.DeploymentEnvironment(cfg.Environment),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// otlpEndpointOptions points the exporter at a collector base URL. Plain http
+// URLs disable TLS.
+func otlpEndpointOptions(endpoint string) ([]otlptracehttp.Option, error) {
+	parsed, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("invalid otlp endpoint %q: expected an http or https URL", endpoint)
+	}
+
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(parsed.Host),
+		otlptracehttp.WithURLPath(strings.TrimSuffix(parsed.Path, "/") + otlpTracesPath),
+	}
+	if parsed.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	return options, nil
+}
@@ -0,0 +1,29 @@
+package observability
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitTracingWithoutEndpointIsNoop(t *testing.T) {
+	shutdown, err := InitTracing(context.Background(), TracingConfig{ServiceName: "gema-go-api"})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+}
+
+func TestInitTracingRejectsInvalidEndpoint(t *testing.T) {
+	_, err := InitTracing(context.Background(), TracingConfig{Endpoint: "otel-collector:4318"})
+	require.Error(t, err)
+}
+
+func TestOTLPEndpointOptions(t *testing.T) {
+	options, err := otlpEndpointOptions("http://otel-collector:4318")
+	require.NoError(t, err)
+	require.Len(t, options, 3)
+
+	options, err = otlpEndpointOptions("https://collector.example.com/otlp/")
+	require.NoError(t, err)
+	require.Len(t, options, 2)
+}
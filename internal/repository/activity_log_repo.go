@@ -23,6 +23,8 @@ type ActivityLogRepository interface {
 	Create(ctx context.Context, entry *models.ActivityLog) error
 	List(ctx context.Context, filter ActivityLogFilter) ([]models.ActivityLog, int64, error)
 	ListRecent(ctx context.Context, filter ActivityLogRecentFilter) ([]models.ActivityLog, int64, error)
+	DeleteBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	EachForExport(ctx context.Context, filter ActivityLogExportFilter, batchSize int, fn func([]models.ActivityLog) error) error
 }
 
 type activityLogRepository struct {
@@ -128,3 +130,41 @@ func (r *activityLogRepository) ListRecent(ctx context.Context, filter ActivityL
 
 	return entries, total, nil
 }
+
+// DeleteBefore removes up to limit entries created before cutoff, oldest
+// first, and reports how many were removed. Callers loop until fewer than
+// limit rows go, keeping each delete short.
+func (r *activityLogRepository) DeleteBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	oldest := r.db.Model(&models.ActivityLog{}).
+		Select("id").
+		Where("created_at < ?", cutoff).
+		Order("id ASC").
+		Limit(limit)
+
+	result := r.db.WithContext(ctx).Where("id IN (?)", oldest).Delete(&models.ActivityLog{})
+	return result.RowsAffected, result.Error
+}
+
+// ActivityLogExportFilter bounds an activity export by creation time. Zero
+// bounds leave the range open.
+type ActivityLogExportFilter struct {
+	Since time.Time
+	Until time.Time
+}
+
+// EachForExport hands matching entries to fn in batches of batchSize, oldest
+// first.
+func (r *activityLogRepository) EachForExport(ctx context.Context, filter ActivityLogExportFilter, batchSize int, fn func([]models.ActivityLog) error) error {
+	query := r.db.WithContext(ctx)
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at <= ?", filter.Until)
+	}
+
+	var batch []models.ActivityLog
+	return query.FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
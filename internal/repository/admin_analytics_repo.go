@@ -9,11 +9,20 @@ import (
 	"github.com/noah-isme/gema-go-api/internal/models"
 )
 
+// AnalyticsFilter limits analytics to submissions created within [From, To].
+// A nil bound leaves the range open on that side.
+type AnalyticsFilter struct {
+	From *time.Time
+	To   *time.Time
+}
+
 // AdminAnalyticsRepository supplies data for administrator analytics dashboards.
 type AdminAnalyticsRepository interface {
 	CountActiveStudents(ctx context.Context) (int64, error)
-	ListSubmissionsWithAssignments(ctx context.Context) ([]models.Submission, error)
+	ListSubmissionsWithAssignments(ctx context.Context, filter AnalyticsFilter) ([]models.Submission, error)
 	ListSubmissionsSince(ctx context.Context, since time.Time) ([]models.Submission, error)
+	GetAssignment(ctx context.Context, id uint) (models.Assignment, error)
+	ListAssignmentSubmissions(ctx context.Context, assignmentID uint) ([]models.Submission, error)
 }
 
 type adminAnalyticsRepository struct {
@@ -35,9 +44,17 @@ func (r *adminAnalyticsRepository) CountActiveStudents(ctx context.Context) (int
 	return count, err
 }
 
-func (r *adminAnalyticsRepository) ListSubmissionsWithAssignments(ctx context.Context) ([]models.Submission, error) {
+func (r *adminAnalyticsRepository) ListSubmissionsWithAssignments(ctx context.Context, filter AnalyticsFilter) ([]models.Submission, error) {
+	query := r.db.WithContext(ctx)
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+
 	var submissions []models.Submission
-	err := r.db.WithContext(ctx).
+	err := query.
 		Preload("Assignment").
 		Preload("Student").
 		Find(&submissions).Error
@@ -53,3 +70,23 @@ func (r *adminAnalyticsRepository) ListSubmissionsSince(ctx context.Context, sin
 		Find(&submissions).Error
 	return submissions, err
 }
+
+func (r *adminAnalyticsRepository) GetAssignment(ctx context.Context, id uint) (models.Assignment, error) {
+	var assignment models.Assignment
+	err := r.db.WithContext(ctx).First(&assignment, id).Error
+	return assignment, err
+}
+
+// ListAssignmentSubmissions returns every submission version for the
+// assignment, newest version first for each student.
+func (r *adminAnalyticsRepository) ListAssignmentSubmissions(ctx context.Context, assignmentID uint) ([]models.Submission, error) {
+	var submissions []models.Submission
+	err := r.db.WithContext(ctx).
+		Where("assignment_id = ?", assignmentID).
+		Order("student_id ASC").
+		Order("version DESC").
+		Order("created_at DESC").
+		Preload("Assignment").
+		Find(&submissions).Error
+	return submissions, err
+}
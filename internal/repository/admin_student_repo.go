@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/noah-isme/gema-go-api/internal/models"
 )
@@ -26,8 +28,12 @@ type AdminStudentRepository interface {
 	GetByID(ctx context.Context, id uint) (models.Student, error)
 	Update(ctx context.Context, id uint, updates map[string]interface{}) (models.Student, error)
 	SoftDelete(ctx context.Context, id uint) error
+	UpsertByEmail(ctx context.Context, students []*models.Student) (map[string]bool, error)
 }
 
+// studentUpsertBatchSize is how many rows UpsertByEmail inserts per statement.
+const studentUpsertBatchSize = 200
+
 type adminStudentRepository struct {
 	db *gorm.DB
 }
@@ -128,3 +134,62 @@ func (r *adminStudentRepository) SoftDelete(ctx context.Context, id uint) error
 		return nil
 	})
 }
+
+// UpsertByEmail creates the given students, updating name and class of any
+// whose email already exists (archived students included), in one
+// transaction. Emails are normalised with models.NormalizeEmail, so matching
+// ignores case. It returns the normalised emails that already existed. Emails
+// must be unique within students.
+func (r *adminStudentRepository) UpsertByEmail(ctx context.Context, students []*models.Student) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(students) == 0 {
+		return existing, nil
+	}
+
+	emails := make([]string, 0, len(students))
+	for _, student := range students {
+		student.Email = models.NormalizeEmail(student.Email)
+		emails = append(emails, student.Email)
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var found []string
+		if err := tx.Unscoped().Model(&models.Student{}).Where("email IN ?", emails).Pluck("email", &found).Error; err != nil {
+			return err
+		}
+		for _, email := range found {
+			existing[email] = true
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "email"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "class", "updated_at"}),
+		}).CreateInBatches(students, studentUpsertBatchSize).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// NormalizeStudentEmails lowercases student emails stored before emails were
+// normalised. Deployments holding addresses that differ only in case keep
+// them; the update is skipped, and the error says so, until the duplicates
+// are merged.
+func NormalizeStudentEmails(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+
+	var duplicates int64
+	err := db.Raw(`SELECT COUNT(*) FROM (
+		SELECT LOWER(TRIM(email)) FROM students
+		GROUP BY LOWER(TRIM(email)) HAVING COUNT(*) > 1
+	) AS duplicates`).Scan(&duplicates).Error
+	if err != nil {
+		return err
+	}
+	if duplicates > 0 {
+		return fmt.Errorf("skipping student email normalisation: %d emails differ only in case", duplicates)
+	}
+
+	return db.Exec("UPDATE students SET email = LOWER(TRIM(email)) WHERE email <> LOWER(TRIM(email))").Error
+}
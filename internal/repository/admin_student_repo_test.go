@@ -33,6 +33,32 @@ func TestAdminStudentRepositoryListFiltersAndSorts(t *testing.T) {
 	require.Equal(t, "Bob Stone", students[0].Name, "expected newest record first")
 }
 
+func TestAdminStudentRepositoryUpsertByEmailIgnoresCase(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:admin_student_email_case?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Student{}))
+	require.NoError(t, db.Exec("INSERT INTO students (name, email, status) VALUES (?, ?, ?)", "Legacy", "Legacy@Example.com", models.StudentStatusActive).Error)
+	require.NoError(t, NormalizeStudentEmails(context.Background(), db))
+
+	repo := NewAdminStudentRepository(db)
+	existing, err := repo.UpsertByEmail(context.Background(), []*models.Student{
+		{Name: "Legacy Renamed", Email: " LEGACY@example.COM ", Class: "B"},
+		{Name: "Fresh", Email: "Fresh@Example.com"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"legacy@example.com": true}, existing)
+
+	var students []models.Student
+	require.NoError(t, db.Order("id").Find(&students).Error)
+	require.Len(t, students, 2)
+	require.Equal(t, "legacy@example.com", students[0].Email)
+	require.Equal(t, "Legacy Renamed", students[0].Name)
+	require.Equal(t, "fresh@example.com", students[1].Email)
+
+	require.NoError(t, db.Exec("INSERT INTO students (name, email, status) VALUES (?, ?, ?)", "Twin", "FRESH@example.com", models.StudentStatusActive).Error)
+	require.Error(t, NormalizeStudentEmails(context.Background(), db))
+}
+
 func setupTestDB(t *testing.T) *gorm.DB {
 	t.Helper()
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
@@ -2,17 +2,31 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/noah-isme/gema-go-api/internal/models"
 )
 
+// SubmissionExportFilter narrows a grade export. A zero AssignmentID covers
+// every assignment.
+type SubmissionExportFilter struct {
+	AssignmentID uint
+}
+
 // AdminSubmissionRepository provides persistence helpers for grading workflows.
 type AdminSubmissionRepository interface {
 	GetByID(ctx context.Context, id uint) (models.Submission, error)
-	Update(ctx context.Context, submission *models.Submission) error
-	CreateHistory(ctx context.Context, history *models.SubmissionGradeHistory) error
+	GetLatestVersion(ctx context.Context, assignmentID, studentID uint) (models.Submission, error)
+	SaveGrade(ctx context.Context, submission *models.Submission, history *models.SubmissionGradeHistory) error
+	ListHistory(ctx context.Context, submissionID uint) ([]models.SubmissionGradeHistory, error)
+	EachLatestForExport(ctx context.Context, filter SubmissionExportFilter, batchSize int, fn func([]models.Submission) error) error
+	AssignmentExists(ctx context.Context, assignmentID uint) (bool, error)
+	CountUngraded(ctx context.Context, assignmentID uint) (int64, error)
+	FindClaim(ctx context.Context, assignmentID, graderID uint, now time.Time) (models.Submission, error)
+	ListClaimable(ctx context.Context, assignmentID uint, now time.Time, limit int) ([]models.Submission, error)
+	Claim(ctx context.Context, submissionID, graderID uint, now, expiresAt time.Time) (bool, error)
 }
 
 type adminSubmissionRepository struct {
@@ -24,25 +38,143 @@ func NewAdminSubmissionRepository(db *gorm.DB) AdminSubmissionRepository {
 	return &adminSubmissionRepository{db: db}
 }
 
-func (r *adminSubmissionRepository) GetByID(ctx context.Context, id uint) (models.Submission, error) {
-	var submission models.Submission
-	if err := r.db.WithContext(ctx).
+func (r *adminSubmissionRepository) baseQuery(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
 		Preload("Assignment").
 		Preload("Student").
 		Preload("History", func(tx *gorm.DB) *gorm.DB {
 			return tx.Order("graded_at DESC")
-		}).
-		First(&submission, id).Error; err != nil {
+		})
+}
+
+func (r *adminSubmissionRepository) GetByID(ctx context.Context, id uint) (models.Submission, error) {
+	var submission models.Submission
+	if err := r.baseQuery(ctx).First(&submission, id).Error; err != nil {
 		return models.Submission{}, err
 	}
 
 	return submission, nil
 }
 
-func (r *adminSubmissionRepository) Update(ctx context.Context, submission *models.Submission) error {
-	return r.db.WithContext(ctx).Save(submission).Error
+func (r *adminSubmissionRepository) GetLatestVersion(ctx context.Context, assignmentID, studentID uint) (models.Submission, error) {
+	var submission models.Submission
+	if err := r.baseQuery(ctx).
+		Where("assignment_id = ?", assignmentID).
+		Where("student_id = ?", studentID).
+		Order("version DESC").
+		Order("created_at DESC").
+		First(&submission).Error; err != nil {
+		return models.Submission{}, err
+	}
+
+	return submission, nil
+}
+
+// SaveGrade stores a graded submission together with its history entry, so
+// a grade is never recorded without its audit trail.
+func (r *adminSubmissionRepository) SaveGrade(ctx context.Context, submission *models.Submission, history *models.SubmissionGradeHistory) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(submission).Error; err != nil {
+			return err
+		}
+
+		history.SubmissionID = submission.ID
+		return tx.Create(history).Error
+	})
+}
+
+// ListHistory returns a submission's grading history, oldest first.
+func (r *adminSubmissionRepository) ListHistory(ctx context.Context, submissionID uint) ([]models.SubmissionGradeHistory, error) {
+	var history []models.SubmissionGradeHistory
+	if err := r.db.WithContext(ctx).
+		Where("submission_id = ?", submissionID).
+		Order("graded_at ASC").
+		Order("id ASC").
+		Find(&history).Error; err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// latestVersionClause limits a submissions query to each student's newest
+// version of an assignment.
+const latestVersionClause = `NOT EXISTS (SELECT 1 FROM submissions newer WHERE newer.assignment_id = submissions.assignment_id
+	AND newer.student_id = submissions.student_id AND newer.version > submissions.version AND newer.deleted_at IS NULL)`
+
+// EachLatestForExport hands the latest version of every student's submission
+// to fn in batches of batchSize, so exports never hold the full result set.
+func (r *adminSubmissionRepository) EachLatestForExport(ctx context.Context, filter SubmissionExportFilter, batchSize int, fn func([]models.Submission) error) error {
+	query := r.db.WithContext(ctx).
+		Preload("Assignment").
+		Preload("Student").
+		Where(latestVersionClause)
+	if filter.AssignmentID != 0 {
+		query = query.Where("submissions.assignment_id = ?", filter.AssignmentID)
+	}
+
+	var batch []models.Submission
+	return query.FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+func (r *adminSubmissionRepository) AssignmentExists(ctx context.Context, assignmentID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Assignment{}).Where("id = ?", assignmentID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *adminSubmissionRepository) ungraded(ctx context.Context, assignmentID uint) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&models.Submission{}).
+		Where("submissions.assignment_id = ?", assignmentID).
+		Where("submissions.status <> ?", models.SubmissionStatusGraded).
+		Where(latestVersionClause)
+}
+
+// CountUngraded counts the latest versions still awaiting a grade, claimed or
+// not.
+func (r *adminSubmissionRepository) CountUngraded(ctx context.Context, assignmentID uint) (int64, error) {
+	var count int64
+	err := r.ungraded(ctx, assignmentID).Count(&count).Error
+	return count, err
+}
+
+// FindClaim returns the ungraded submission graderID holds an unexpired claim
+// on, if any.
+func (r *adminSubmissionRepository) FindClaim(ctx context.Context, assignmentID, graderID uint, now time.Time) (models.Submission, error) {
+	var submission models.Submission
+	err := r.ungraded(ctx, assignmentID).
+		Preload("Assignment").
+		Preload("Student").
+		Where("submissions.claimed_by = ? AND submissions.claim_expires_at > ?", graderID, now).
+		Order("submissions.created_at ASC").
+		First(&submission).Error
+	return submission, err
+}
+
+// ListClaimable returns the oldest ungraded submissions nobody holds a live
+// claim on.
+func (r *adminSubmissionRepository) ListClaimable(ctx context.Context, assignmentID uint, now time.Time, limit int) ([]models.Submission, error) {
+	var submissions []models.Submission
+	err := r.ungraded(ctx, assignmentID).
+		Where("submissions.claimed_by IS NULL OR submissions.claim_expires_at IS NULL OR submissions.claim_expires_at <= ?", now).
+		Order("submissions.created_at ASC").
+		Order("submissions.id ASC").
+		Limit(limit).
+		Find(&submissions).Error
+	return submissions, err
 }
 
-func (r *adminSubmissionRepository) CreateHistory(ctx context.Context, history *models.SubmissionGradeHistory) error {
-	return r.db.WithContext(ctx).Create(history).Error
+// Claim assigns the submission to graderID until expiresAt. The conditional
+// update only succeeds while the submission is ungraded and unclaimed (or its
+// claim has lapsed), so concurrent graders never win the same row.
+func (r *adminSubmissionRepository) Claim(ctx context.Context, submissionID, graderID uint, now, expiresAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Submission{}).
+		Where("id = ? AND status <> ?", submissionID, models.SubmissionStatusGraded).
+		Where("claimed_by IS NULL OR claim_expires_at IS NULL OR claim_expires_at <= ? OR claimed_by = ?", now, graderID).
+		Updates(map[string]interface{}{"claimed_by": graderID, "claim_expires_at": expiresAt})
+	return result.RowsAffected == 1, result.Error
 }
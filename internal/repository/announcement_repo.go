@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 	"time"
 
@@ -11,25 +12,36 @@ import (
 	"github.com/noah-isme/gema-go-api/internal/models"
 )
 
-// AnnouncementFilter filters announcement list queries.
+// AnnouncementFilter filters announcement list queries. A zero Now uses the
+// current time. Audience holds the viewer's class and role; announcements
+// targeted at neither are skipped, while untargeted ones always match.
 type AnnouncementFilter struct {
 	Page     int
 	PageSize int
+	Now      time.Time
+	Audience []string
 }
 
-// AdminAnnouncementFilter extends filtering options.
+// AdminAnnouncementFilter extends filtering options. Status limits results to
+// one of the models.AnnouncementStatus values as of Now.
 type AdminAnnouncementFilter struct {
 	Page     int
 	PageSize int
 	Search   string
+	Status   string
+	Now      time.Time
 }
 
 // AnnouncementRepository exposes persistence helpers for announcements.
 type AnnouncementRepository interface {
 	ListActive(ctx context.Context, filter AnnouncementFilter) ([]models.Announcement, int64, error)
 	UpsertBatch(ctx context.Context, items []models.Announcement) (int64, error)
+	ExistingSlugs(ctx context.Context, slugs []string) ([]string, error)
 	ListAll(ctx context.Context, filter AdminAnnouncementFilter) ([]models.Announcement, int64, error)
 	Create(ctx context.Context, announcement *models.Announcement) error
+	GetByID(ctx context.Context, id uint) (models.Announcement, error)
+	Update(ctx context.Context, announcement *models.Announcement) error
+	NextStart(ctx context.Context, after time.Time) (*time.Time, error)
 }
 
 type announcementRepository struct {
@@ -41,10 +53,16 @@ func NewAnnouncementRepository(db *gorm.DB) AnnouncementRepository {
 	return &announcementRepository{db: db}
 }
 
+// ListActive returns announcements inside their publishing window, pinned
+// ones first. Pinning only affects ordering; it does not extend the window.
 func (r *announcementRepository) ListActive(ctx context.Context, filter AnnouncementFilter) ([]models.Announcement, int64, error) {
-	now := time.Now()
+	now := filter.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
 	query := r.db.WithContext(ctx).Model(&models.Announcement{})
-	query = query.Where("is_pinned = ? OR (starts_at <= ? AND (ends_at IS NULL OR ends_at >= ?))", true, now, now)
+	query = query.Where("starts_at <= ? AND (ends_at IS NULL OR ends_at >= ?)", now, now)
+	query = query.Where(audienceCondition(r.db, models.NormalizeAudience(filter.Audience)))
 
 	countQuery := query.Session(&gorm.Session{})
 	var total int64
@@ -69,6 +87,21 @@ func (r *announcementRepository) ListActive(ctx context.Context, filter Announce
 	return items, total, nil
 }
 
+// audienceCondition matches announcements for everyone plus those whose JSON
+// audience array contains one of the given entries.
+func audienceCondition(db *gorm.DB, audience []string) *gorm.DB {
+	condition := db.Where("audience IS NULL OR audience = ''")
+	escaper := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	for _, entry := range audience {
+		encoded, err := json.Marshal(entry)
+		if err != nil {
+			continue
+		}
+		condition = condition.Or(`audience LIKE ? ESCAPE '\'`, "%"+escaper.Replace(string(encoded))+"%")
+	}
+	return condition
+}
+
 func (r *announcementRepository) UpsertBatch(ctx context.Context, items []models.Announcement) (int64, error) {
 	if len(items) == 0 {
 		return 0, nil
@@ -76,13 +109,23 @@ func (r *announcementRepository) UpsertBatch(ctx context.Context, items []models
 
 	tx := r.db.WithContext(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "slug"}},
-		DoUpdates: clause.AssignmentColumns([]string{"title", "body", "starts_at", "ends_at", "is_pinned", "updated_at"}),
+		DoUpdates: clause.AssignmentColumns([]string{"title", "body", "starts_at", "ends_at", "is_pinned", "audience", "updated_at"}),
 	})
 
 	result := tx.Create(&items)
 	return result.RowsAffected, result.Error
 }
 
+// ExistingSlugs returns which of slugs are already stored.
+func (r *announcementRepository) ExistingSlugs(ctx context.Context, slugs []string) ([]string, error) {
+	existing := []string{}
+	if len(slugs) == 0 {
+		return existing, nil
+	}
+	err := r.db.WithContext(ctx).Model(&models.Announcement{}).Where("slug IN ?", slugs).Pluck("slug", &existing).Error
+	return existing, err
+}
+
 func (r *announcementRepository) ListAll(ctx context.Context, filter AdminAnnouncementFilter) ([]models.Announcement, int64, error) {
 	query := r.db.WithContext(ctx).Model(&models.Announcement{})
 
@@ -91,6 +134,19 @@ func (r *announcementRepository) ListAll(ctx context.Context, filter AdminAnnoun
 		query = query.Where("LOWER(title) LIKE ? OR LOWER(body) LIKE ?", pattern, pattern)
 	}
 
+	now := filter.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	switch filter.Status {
+	case models.AnnouncementStatusScheduled:
+		query = query.Where("starts_at > ?", now)
+	case models.AnnouncementStatusActive:
+		query = query.Where("starts_at <= ? AND (ends_at IS NULL OR ends_at >= ?)", now, now)
+	case models.AnnouncementStatusExpired:
+		query = query.Where("starts_at <= ? AND ends_at < ?", now, now)
+	}
+
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -115,3 +171,30 @@ func (r *announcementRepository) ListAll(ctx context.Context, filter AdminAnnoun
 func (r *announcementRepository) Create(ctx context.Context, announcement *models.Announcement) error {
 	return r.db.WithContext(ctx).Create(announcement).Error
 }
+
+func (r *announcementRepository) GetByID(ctx context.Context, id uint) (models.Announcement, error) {
+	var announcement models.Announcement
+	if err := r.db.WithContext(ctx).First(&announcement, id).Error; err != nil {
+		return models.Announcement{}, err
+	}
+	return announcement, nil
+}
+
+func (r *announcementRepository) Update(ctx context.Context, announcement *models.Announcement) error {
+	return r.db.WithContext(ctx).Save(announcement).Error
+}
+
+// NextStart returns the earliest start time after the given instant, or nil
+// when nothing is scheduled.
+func (r *announcementRepository) NextStart(ctx context.Context, after time.Time) (*time.Time, error) {
+	var announcement models.Announcement
+	err := r.db.WithContext(ctx).
+		Where("starts_at > ?", after).
+		Order("starts_at ASC").
+		Limit(1).
+		Find(&announcement).Error
+	if err != nil || announcement.ID == 0 {
+		return nil, err
+	}
+	return &announcement.StartsAt, nil
+}
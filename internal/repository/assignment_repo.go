@@ -23,6 +23,7 @@ type AssignmentRepository interface {
 	ListWithFilter(ctx context.Context, filter AssignmentFilter) ([]models.Assignment, int64, error)
 	GetByID(ctx context.Context, id uint) (models.Assignment, error)
 	Create(ctx context.Context, assignment *models.Assignment) error
+	CreateBatch(ctx context.Context, assignments []*models.Assignment) error
 	Update(ctx context.Context, assignment *models.Assignment) error
 	Delete(ctx context.Context, id uint) error
 }
@@ -93,6 +94,21 @@ func (r *assignmentRepository) Create(ctx context.Context, assignment *models.As
 	return r.db.WithContext(ctx).Create(assignment).Error
 }
 
+func (r *assignmentRepository) CreateBatch(ctx context.Context, assignments []*models.Assignment) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, assignment := range assignments {
+			if err := tx.Create(assignment).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *assignmentRepository) Update(ctx context.Context, assignment *models.Assignment) error {
 	return r.db.WithContext(ctx).Save(assignment).Error
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/noah-isme/gema-go-api/internal/models"
 )
@@ -15,6 +16,14 @@ type ChatRepository interface {
 	ListByRoom(ctx context.Context, roomID string, before time.Time, limit int) ([]models.ChatMessage, error)
 	ListBySender(ctx context.Context, senderID string, limit int) ([]models.ChatMessage, error)
 	LatestByRoom(ctx context.Context, roomID string) (models.ChatMessage, error)
+	LatestByRooms(ctx context.Context, roomIDs []string) ([]models.ChatMessage, error)
+	CountUnreadByRooms(ctx context.Context, userID string, roomIDs []string) (map[string]int64, error)
+	MarkRead(ctx context.Context, userID, roomID string, at time.Time) error
+	CreateRoom(ctx context.Context, room *models.ChatRoom) error
+	GetRoom(ctx context.Context, roomID string) (models.ChatRoom, error)
+	AddRoomMembers(ctx context.Context, members []models.ChatRoomMember) error
+	RemoveRoomMember(ctx context.Context, roomID, userID string) error
+	RoomMembership(ctx context.Context, roomID, userID string) (registered bool, member bool, err error)
 }
 
 type chatRepository struct {
@@ -74,3 +83,110 @@ func (r *chatRepository) LatestByRoom(ctx context.Context, roomID string) (model
 	}
 	return message, nil
 }
+
+func (r *chatRepository) LatestByRooms(ctx context.Context, roomIDs []string) ([]models.ChatMessage, error) {
+	if len(roomIDs) == 0 {
+		return []models.ChatMessage{}, nil
+	}
+
+	latest := r.db.WithContext(ctx).Model(&models.ChatMessage{}).
+		Select("MAX(id)").
+		Where("room_id IN ?", roomIDs).
+		Group("room_id")
+
+	var messages []models.ChatMessage
+	if err := r.db.WithContext(ctx).Where("id IN (?)", latest).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *chatRepository) CountUnreadByRooms(ctx context.Context, userID string, roomIDs []string) (map[string]int64, error) {
+	counts := make(map[string]int64, len(roomIDs))
+	if len(roomIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		RoomID string
+		Unread int64
+	}
+	if err := r.db.WithContext(ctx).
+		Table("chat_messages AS m").
+		Select("m.room_id AS room_id, COUNT(*) AS unread").
+		Joins("LEFT JOIN chat_read_receipts AS r ON r.room_id = m.room_id AND r.user_id = ?", userID).
+		Where("m.room_id IN ?", roomIDs).
+		Where("m.sender_id <> ?", userID).
+		Where("r.last_read_at IS NULL OR m.created_at > r.last_read_at").
+		Group("m.room_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.RoomID] = row.Unread
+	}
+	return counts, nil
+}
+
+func (r *chatRepository) MarkRead(ctx context.Context, userID, roomID string, at time.Time) error {
+	receipt := models.ChatReadReceipt{UserID: userID, RoomID: roomID, LastReadAt: at}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "room_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_read_at", "updated_at"}),
+	}).Create(&receipt).Error
+}
+
+// CreateRoom stores the room together with its Members.
+func (r *chatRepository) CreateRoom(ctx context.Context, room *models.ChatRoom) error {
+	return r.db.WithContext(ctx).Create(room).Error
+}
+
+// GetRoom loads a group room with its members ordered by when they joined.
+func (r *chatRepository) GetRoom(ctx context.Context, roomID string) (models.ChatRoom, error) {
+	var room models.ChatRoom
+	err := r.db.WithContext(ctx).
+		Preload("Members", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("room_id = ?", roomID).
+		First(&room).Error
+	return room, err
+}
+
+// AddRoomMembers inserts members, skipping users already in the room.
+func (r *chatRepository) AddRoomMembers(ctx context.Context, members []models.ChatRoomMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+}
+
+// RemoveRoomMember deletes a membership, returning gorm.ErrRecordNotFound when
+// the user was not a member.
+func (r *chatRepository) RemoveRoomMember(ctx context.Context, roomID, userID string) error {
+	result := r.db.WithContext(ctx).Where("room_id = ? AND user_id = ?", roomID, userID).Delete(&models.ChatRoomMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RoomMembership reports whether roomID is a registered group room and, if so,
+// whether userID is one of its members.
+func (r *chatRepository) RoomMembership(ctx context.Context, roomID, userID string) (bool, bool, error) {
+	var rooms int64
+	if err := r.db.WithContext(ctx).Model(&models.ChatRoom{}).Where("room_id = ?", roomID).Count(&rooms).Error; err != nil {
+		return false, false, err
+	}
+	if rooms == 0 {
+		return false, false, nil
+	}
+
+	var members int64
+	if err := r.db.WithContext(ctx).Model(&models.ChatRoomMember{}).Where("room_id = ? AND user_id = ?", roomID, userID).Count(&members).Error; err != nil {
+		return true, false, err
+	}
+	return true, members > 0, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"strings"
 
 	"gorm.io/gorm"
 
@@ -13,7 +14,31 @@ type CodingSubmissionRepository interface {
 	Create(ctx context.Context, submission *models.CodingSubmission) error
 	Update(ctx context.Context, submission *models.CodingSubmission) error
 	GetByID(ctx context.Context, id uint) (models.CodingSubmission, error)
+	List(ctx context.Context, query CodingSubmissionQuery) ([]models.CodingSubmission, int64, error)
 	SaveEvaluation(ctx context.Context, evaluation *models.CodingEvaluation) error
+	LanguageStats(ctx context.Context) ([]CodingLanguageStats, error)
+}
+
+// CodingSubmissionQuery defines filters and pagination for coding submissions.
+// Zero values match every submission.
+type CodingSubmissionQuery struct {
+	StudentID uint
+	TaskID    uint
+	Status    string
+	Language  string
+	Offset    int
+	Limit     int
+}
+
+// CodingLanguageStats aggregates execution outcomes for one language.
+type CodingLanguageStats struct {
+	Language     string
+	Total        int64
+	Succeeded    int64
+	Failed       int64
+	TimedOut     int64
+	AvgCPUTimeMs float64
+	AvgMemoryKB  float64
 }
 
 // NewCodingSubmissionRepository constructs a coding submission repository.
@@ -45,6 +70,70 @@ func (r *codingSubmissionRepository) GetByID(ctx context.Context, id uint) (mode
 	return submission, nil
 }
 
+func (r *codingSubmissionRepository) List(ctx context.Context, query CodingSubmissionQuery) ([]models.CodingSubmission, int64, error) {
+	db := r.db.WithContext(ctx).Model(&models.CodingSubmission{})
+
+	if query.StudentID != 0 {
+		db = db.Where("student_id = ?", query.StudentID)
+	}
+	if query.TaskID != 0 {
+		db = db.Where("task_id = ?", query.TaskID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.Language != "" {
+		db = db.Where("LOWER(language) = ?", strings.ToLower(query.Language))
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if query.Offset > 0 {
+		db = db.Offset(query.Offset)
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+
+	var submissions []models.CodingSubmission
+	err := db.Preload("Task").
+		Order("created_at DESC").
+		Order("id DESC").
+		Find(&submissions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return submissions, total, nil
+}
+
 func (r *codingSubmissionRepository) SaveEvaluation(ctx context.Context, evaluation *models.CodingEvaluation) error {
 	return r.db.WithContext(ctx).Create(evaluation).Error
 }
+
+// LanguageStats groups submissions by language. Compile errors count as
+// failures. Evaluation overwrites the execution status, so evaluated
+// submissions count as failed when they recorded an execution or compile
+// error and as succeeded otherwise.
+func (r *codingSubmissionRepository) LanguageStats(ctx context.Context) ([]CodingLanguageStats, error) {
+	var stats []CodingLanguageStats
+	err := r.db.WithContext(ctx).
+		Model(&models.CodingSubmission{}).
+		Select(`language,
+			COUNT(*) AS total,
+			SUM(CASE WHEN status = ? OR (status = ? AND COALESCE(error, '') = '' AND COALESCE(compile_error, '') = '') THEN 1 ELSE 0 END) AS succeeded,
+			SUM(CASE WHEN status IN (?, ?) OR (status = ? AND (COALESCE(error, '') <> '' OR COALESCE(compile_error, '') <> '')) THEN 1 ELSE 0 END) AS failed,
+			SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS timed_out,
+			COALESCE(AVG(cpu_time_ms), 0) AS avg_cpu_time_ms,
+			COALESCE(AVG(memory_kb), 0) AS avg_memory_kb`,
+			models.CodingSubmissionStatusCompleted, models.CodingSubmissionStatusEvaluated,
+			models.CodingSubmissionStatusFailed, models.CodingSubmissionStatusCompileError, models.CodingSubmissionStatusEvaluated,
+			models.CodingSubmissionStatusTimeout,
+		).
+		Group("language").
+		Order("language ASC").
+		Scan(&stats).Error
+	return stats, err
+}
@@ -5,25 +5,39 @@ import (
 	"fmt"
 	"strings"
 
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 
 	"github.com/noah-isme/gema-go-api/internal/models"
 )
 
+// Coding task sort orders accepted by CodingTaskQuery.
+const (
+	CodingTaskSortNewest  = "newest"
+	CodingTaskSortPopular = "popular"
+)
+
 // CodingTaskQuery defines filters and pagination for coding tasks.
 type CodingTaskQuery struct {
 	Language   string
 	Difficulty string
 	Tags       []string
 	Search     string
-	Offset     int
-	Limit      int
+	ActiveOnly bool
+	// Sort is CodingTaskSortNewest (the default) or CodingTaskSortPopular,
+	// which orders by submission count.
+	Sort   string
+	Offset int
+	Limit  int
 }
 
 // CodingTaskRepository exposes persistence operations for coding tasks.
 type CodingTaskRepository interface {
 	List(ctx context.Context, query CodingTaskQuery) ([]models.CodingTask, int64, error)
 	GetByID(ctx context.Context, id uint) (models.CodingTask, error)
+	BestScores(ctx context.Context, studentID uint, taskIDs []uint) (map[uint]float64, error)
+	SetActive(ctx context.Context, id uint, active bool) (models.CodingTask, error)
+	SetEnv(ctx context.Context, id uint, env map[string]string) (models.CodingTask, error)
 }
 
 // NewCodingTaskRepository constructs a coding task repository.
@@ -38,6 +52,10 @@ type codingTaskRepository struct {
 func (r *codingTaskRepository) List(ctx context.Context, query CodingTaskQuery) ([]models.CodingTask, int64, error) {
 	db := r.db.WithContext(ctx).Model(&models.CodingTask{})
 
+	if query.ActiveOnly {
+		db = db.Where("active = ?", true)
+	}
+
 	if query.Language != "" {
 		db = db.Where("LOWER(language) = ?", strings.ToLower(query.Language))
 	}
@@ -74,6 +92,9 @@ func (r *codingTaskRepository) List(ctx context.Context, query CodingTaskQuery)
 		db = db.Limit(query.Limit)
 	}
 
+	if query.Sort == CodingTaskSortPopular {
+		db = db.Order("(SELECT COUNT(*) FROM coding_submissions WHERE coding_submissions.task_id = coding_tasks.id) DESC")
+	}
 	db = db.Order("created_at DESC")
 
 	var tasks []models.CodingTask
@@ -84,10 +105,70 @@ func (r *codingTaskRepository) List(ctx context.Context, query CodingTaskQuery)
 	return tasks, total, nil
 }
 
+// BestScores returns the student's highest evaluation score for each of the
+// given tasks. Tasks the student has no evaluated submission for are absent.
+func (r *codingTaskRepository) BestScores(ctx context.Context, studentID uint, taskIDs []uint) (map[uint]float64, error) {
+	scores := make(map[uint]float64)
+	if studentID == 0 || len(taskIDs) == 0 {
+		return scores, nil
+	}
+
+	var rows []struct {
+		TaskID    uint
+		BestScore float64
+	}
+	err := r.db.WithContext(ctx).
+		Table("coding_evaluations").
+		Select("coding_submissions.task_id AS task_id, MAX(coding_evaluations.score) AS best_score").
+		Joins("JOIN coding_submissions ON coding_submissions.id = coding_evaluations.submission_id").
+		Where("coding_submissions.student_id = ? AND coding_submissions.task_id IN ?", studentID, taskIDs).
+		Group("coding_submissions.task_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		scores[row.TaskID] = row.BestScore
+	}
+	return scores, nil
+}
+
 func (r *codingTaskRepository) GetByID(ctx context.Context, id uint) (models.CodingTask, error) {
 	var task models.CodingTask
-	if err := r.db.WithContext(ctx).First(&task, id).Error; err != nil {
+	err := r.db.WithContext(ctx).
+		Preload("TestCases", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, id ASC")
+		}).
+		First(&task, id).Error
+	if err != nil {
 		return models.CodingTask{}, err
 	}
 	return task, nil
 }
+
+func (r *codingTaskRepository) SetActive(ctx context.Context, id uint, active bool) (models.CodingTask, error) {
+	result := r.db.WithContext(ctx).Model(&models.CodingTask{}).Where("id = ?", id).Update("active", active)
+	if result.Error != nil {
+		return models.CodingTask{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.CodingTask{}, gorm.ErrRecordNotFound
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *codingTaskRepository) SetEnv(ctx context.Context, id uint, env map[string]string) (models.CodingTask, error) {
+	value := datatypes.JSONMap{}
+	for name, v := range env {
+		value[name] = v
+	}
+	result := r.db.WithContext(ctx).Model(&models.CodingTask{}).Where("id = ?", id).Update("env", value)
+	if result.Error != nil {
+		return models.CodingTask{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.CodingTask{}, gorm.ErrRecordNotFound
+	}
+	return r.GetByID(ctx, id)
+}
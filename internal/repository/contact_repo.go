@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -24,6 +25,7 @@ type ContactRepository interface {
 	UpdateStatus(ctx context.Context, id uint, status string) error
 	List(ctx context.Context, filter AdminContactFilter) ([]models.ContactSubmission, int64, error)
 	GetByID(ctx context.Context, id uint) (models.ContactSubmission, error)
+	ClaimQueued(ctx context.Context, owner string, updatedBefore, now, until time.Time, limit int) ([]models.ContactSubmission, error)
 }
 
 type contactRepository struct {
@@ -43,7 +45,7 @@ func (r *contactRepository) UpdateStatus(ctx context.Context, id uint, status st
 	return r.db.WithContext(ctx).
 		Model(&models.ContactSubmission{}).
 		Where("id = ?", id).
-		Updates(map[string]interface{}{"status": status}).
+		Updates(map[string]interface{}{"status": status, "claimed_until": nil}).
 		Error
 }
 
@@ -93,3 +95,43 @@ func (r *contactRepository) GetByID(ctx context.Context, id uint) (models.Contac
 	err := r.db.WithContext(ctx).First(&submission, id).Error
 	return submission, err
 }
+
+// ClaimQueued leases up to limit queued submissions last touched before
+// updatedBefore to owner until the given time, least recently attempted
+// first, and returns the ones it won. UpdateStatus releases the lease.
+func (r *contactRepository) ClaimQueued(ctx context.Context, owner string, updatedBefore, now, until time.Time, limit int) ([]models.ContactSubmission, error) {
+	db := r.db.WithContext(ctx)
+	claimable := func(query *gorm.DB) *gorm.DB {
+		return query.Where("status = ? AND updated_at < ?", models.ContactStatusQueued, updatedBefore).
+			Where("claimed_until IS NULL OR claimed_until < ?", now)
+	}
+
+	var ids []uint
+	if err := claimable(db.Model(&models.ContactSubmission{})).
+		Order("updated_at ASC").
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// The claimable condition is repeated so a concurrent claim wins cleanly.
+	// UpdateColumns keeps updated_at, which orders the retries.
+	if err := claimable(db.Model(&models.ContactSubmission{})).
+		Where("id IN ?", ids).
+		UpdateColumns(map[string]interface{}{"claimed_by": owner, "claimed_until": until}).Error; err != nil {
+		return nil, err
+	}
+
+	var submissions []models.ContactSubmission
+	if err := db.Where("id IN ? AND claimed_by = ? AND claimed_until > ?", ids, owner, now).
+		Order("updated_at ASC").
+		Order("id ASC").
+		Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
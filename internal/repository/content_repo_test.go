@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -46,6 +48,78 @@ func TestAnnouncementRepositoryListActiveFiltersAndPaginates(t *testing.T) {
 	require.Equal(t, "active", paged[0].Slug)
 }
 
+func TestAnnouncementRepositoryListAllFiltersByStatus(t *testing.T) {
+	db := setupContentTestDB(t, &models.Announcement{})
+	repo := NewAnnouncementRepository(db)
+
+	now := time.Date(2024, time.June, 1, 8, 0, 0, 0, time.UTC)
+	ended := now.Add(-time.Hour)
+	items := []models.Announcement{
+		{Slug: "scheduled", Title: "Scheduled", Body: "soon", StartsAt: now.Add(time.Hour)},
+		{Slug: "active", Title: "Active", Body: "now", StartsAt: now.Add(-2 * time.Hour)},
+		{Slug: "expired", Title: "Expired", Body: "gone", StartsAt: now.Add(-2 * time.Hour), EndsAt: &ended, IsPinned: true},
+	}
+	require.NoError(t, db.Create(&items).Error)
+
+	for status, slug := range map[string]string{
+		models.AnnouncementStatusScheduled: "scheduled",
+		models.AnnouncementStatusActive:    "active",
+		models.AnnouncementStatusExpired:   "expired",
+	} {
+		found, total, err := repo.ListAll(context.Background(), AdminAnnouncementFilter{Status: status, Now: now})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), total, status)
+		require.Equal(t, slug, found[0].Slug)
+	}
+
+	all, total, err := repo.ListAll(context.Background(), AdminAnnouncementFilter{Now: now})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+	require.Len(t, all, 3)
+
+	active, _, err := repo.ListActive(context.Background(), AnnouncementFilter{Now: now})
+	require.NoError(t, err)
+	require.Len(t, active, 1, "expired pinned announcements are no longer listed")
+
+	next, err := repo.NextStart(context.Background(), now)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	require.True(t, next.Equal(now.Add(time.Hour)))
+}
+
+func TestAnnouncementRepositoryListActiveFiltersByAudience(t *testing.T) {
+	db := setupContentTestDB(t, &models.Announcement{})
+	repo := NewAnnouncementRepository(db)
+
+	past := time.Now().Add(-time.Hour)
+	items := []models.Announcement{
+		{Slug: "everyone", Title: "Everyone", Body: "all", StartsAt: past},
+		{Slug: "class", Title: "Class", Body: "xi", StartsAt: past, Audience: []string{" XI_1 "}},
+		{Slug: "teachers", Title: "Teachers", Body: "staff", StartsAt: past, Audience: []string{"teacher"}},
+	}
+	require.NoError(t, db.Create(&items).Error)
+
+	var stored models.Announcement
+	require.NoError(t, db.Where("slug = ?", "class").First(&stored).Error)
+	require.Equal(t, []string{"xi_1"}, stored.Audience)
+
+	slugs := func(audience ...string) []string {
+		found, total, err := repo.ListActive(context.Background(), AnnouncementFilter{Audience: audience})
+		require.NoError(t, err)
+		require.Equal(t, int64(len(found)), total)
+		result := make([]string, 0, len(found))
+		for _, item := range found {
+			result = append(result, item.Slug)
+		}
+		return result
+	}
+
+	require.ElementsMatch(t, []string{"everyone"}, slugs())
+	require.ElementsMatch(t, []string{"everyone", "class"}, slugs("XI_1", "student"))
+	require.ElementsMatch(t, []string{"everyone"}, slugs("XIA1", "student"), "underscore must not act as a wildcard")
+	require.ElementsMatch(t, []string{"everyone", "teachers"}, slugs("teacher"))
+}
+
 func TestAnnouncementRepositoryUpsertBatch(t *testing.T) {
 	db := setupContentTestDB(t, &models.Announcement{})
 	repo := NewAnnouncementRepository(db)
@@ -103,6 +177,42 @@ func TestGalleryRepositoryListFiltersSearchAndPagination(t *testing.T) {
 	require.Equal(t, "Robotics Club", secondPage[0].Title)
 }
 
+func TestGalleryRepositoryFeaturedOrderingAndReorder(t *testing.T) {
+	db := setupContentTestDB(t, &models.GalleryItem{})
+	repo := NewGalleryRepository(db)
+
+	now := time.Now()
+	items := []models.GalleryItem{
+		{Slug: "old", Title: "Old", ImagePath: "old.jpg", CreatedAt: now.Add(-3 * time.Hour)},
+		{Slug: "new", Title: "New", ImagePath: "new.jpg", CreatedAt: now},
+		{Slug: "hero", Title: "Hero", ImagePath: "hero.jpg", Featured: true, CreatedAt: now.Add(-2 * time.Hour)},
+		{Slug: "pinned", Title: "Pinned", ImagePath: "pinned.jpg", SortOrder: 1, CreatedAt: now.Add(-4 * time.Hour)},
+	}
+	for i := range items {
+		require.NoError(t, db.Create(&items[i]).Error)
+	}
+
+	slugs := func(filter GalleryFilter) []string {
+		listed, _, err := repo.List(context.Background(), filter)
+		require.NoError(t, err)
+		result := make([]string, 0, len(listed))
+		for _, item := range listed {
+			result = append(result, item.Slug)
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"hero", "pinned", "new", "old"}, slugs(GalleryFilter{}))
+	require.Equal(t, []string{"hero"}, slugs(GalleryFilter{FeaturedOnly: true}))
+
+	require.NoError(t, repo.Reorder(context.Background(), []uint{items[0].ID, items[3].ID}))
+	require.Equal(t, []string{"hero", "old", "pinned", "new"}, slugs(GalleryFilter{}))
+
+	err := repo.Reorder(context.Background(), []uint{items[1].ID, 9999})
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.Equal(t, []string{"hero", "old", "pinned", "new"}, slugs(GalleryFilter{}), "failed reorder leaves order untouched")
+}
+
 func TestContactRepositoryCreateAndUpdate(t *testing.T) {
 	db := setupContentTestDB(t, &models.ContactSubmission{})
 	repo := NewContactRepository(db)
@@ -132,9 +242,138 @@ func TestUploadRepositoryCreate(t *testing.T) {
 	require.Equal(t, "application/pdf", stored.MimeType)
 }
 
+func TestUploadRepositoryChecksumLookupAndIndex(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:upload_checksum_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.UploadRecord{}))
+	repo := NewUploadRepository(db)
+	ctx := context.Background()
+
+	owner := uint(9)
+	first := models.UploadRecord{UserID: &owner, FileName: "report.pdf", URL: "https://cdn.example.com/report.pdf", MimeType: "application/pdf", SizeBytes: 2048, Checksum: "abc123"}
+	second := first
+	second.URL = "https://cdn.example.com/report-copy.pdf"
+	require.NoError(t, repo.Create(ctx, &first))
+	require.NoError(t, repo.Create(ctx, &second))
+
+	found, err := repo.FindByChecksum(ctx, owner, "abc123")
+	require.NoError(t, err)
+	require.Equal(t, first.ID, found.ID)
+
+	_, err = repo.FindByChecksum(ctx, 10, "abc123")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.Error(t, EnsureUploadChecksumIndex(ctx, db), "existing duplicates block the unique index")
+
+	require.NoError(t, db.Delete(&models.UploadRecord{}, second.ID).Error)
+	require.NoError(t, EnsureUploadChecksumIndex(ctx, db))
+	require.NoError(t, EnsureUploadChecksumIndex(ctx, db), "creating the index is idempotent")
+
+	duplicate := first
+	duplicate.ID = 0
+	require.Error(t, repo.Create(ctx, &duplicate))
+
+	other := uint(10)
+	sameContent := models.UploadRecord{UserID: &other, FileName: "report.pdf", URL: "https://cdn.example.com/other.pdf", MimeType: "application/pdf", SizeBytes: 2048, Checksum: "abc123"}
+	require.NoError(t, repo.Create(ctx, &sameContent))
+}
+
+func TestPrefixUploadChecksumsLabelsLegacyDigests(t *testing.T) {
+	db := setupContentTestDB(t, &models.UploadRecord{})
+	ctx := context.Background()
+
+	sha := strings.Repeat("a", 64)
+	etag := strings.Repeat("b", 32)
+	labelled := models.UploadChecksum(models.UploadChecksumSHA256, strings.Repeat("c", 64))
+	for _, checksum := range []string{sha, etag, labelled, ""} {
+		require.NoError(t, db.Create(&models.UploadRecord{FileName: "f", URL: "https://cdn.example.com/f", MimeType: "image/png", Checksum: checksum}).Error)
+	}
+
+	require.NoError(t, PrefixUploadChecksums(ctx, db))
+	require.NoError(t, PrefixUploadChecksums(ctx, db), "labelling is idempotent")
+
+	var checksums []string
+	require.NoError(t, db.Model(&models.UploadRecord{}).Order("id").Pluck("checksum", &checksums).Error)
+	require.Equal(t, []string{"sha256:" + sha, "md5:" + etag, labelled, ""}, checksums)
+}
+
+func TestUploadRepositoryListAndSoftDelete(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:upload_list_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.UploadRecord{}))
+	require.NoError(t, EnsureUploadChecksumIndex(context.Background(), db))
+	repo := NewUploadRepository(db)
+	ctx := context.Background()
+
+	owner, other := uint(1), uint(2)
+	base := time.Date(2024, time.May, 1, 8, 0, 0, 0, time.UTC)
+	records := []models.UploadRecord{
+		{UserID: &owner, FileName: "a.pdf", URL: "https://cdn.example.com/a.pdf", MimeType: "application/pdf", SizeBytes: 1, Checksum: "a", CreatedAt: base},
+		{UserID: &owner, FileName: "b.pdf", URL: "https://cdn.example.com/b.pdf", MimeType: "application/pdf", SizeBytes: 1, Checksum: "b", CreatedAt: base.Add(time.Hour)},
+		{UserID: &other, FileName: "c.pdf", URL: "https://cdn.example.com/c.pdf", MimeType: "application/pdf", SizeBytes: 1, Checksum: "c", CreatedAt: base.Add(2 * time.Hour)},
+	}
+	for i := range records {
+		require.NoError(t, repo.Create(ctx, &records[i]))
+	}
+
+	mine, total, err := repo.List(ctx, UploadFilter{UserID: &owner, Page: 1, PageSize: 1})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+	require.Len(t, mine, 1)
+	require.Equal(t, "b.pdf", mine[0].FileName, "newest first")
+
+	all, total, err := repo.List(ctx, UploadFilter{Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+	require.Len(t, all, 3)
+
+	require.NoError(t, repo.Delete(ctx, records[1].ID))
+	_, err = repo.GetByID(ctx, records[1].ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, total, err = repo.List(ctx, UploadFilter{UserID: &owner, Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+
+	var deleted models.UploadRecord
+	require.NoError(t, db.Unscoped().First(&deleted, records[1].ID).Error)
+	require.True(t, deleted.DeletedAt.Valid)
+
+	again := models.UploadRecord{UserID: &owner, FileName: "b.pdf", URL: "https://cdn.example.com/b-again.pdf", MimeType: "application/pdf", SizeBytes: 1, Checksum: "b"}
+	require.NoError(t, repo.Create(ctx, &again), "a deleted file can be uploaded again")
+}
+
+func TestTutorialArticleRepositorySearchFallback(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:tutorial_search_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.TutorialArticle{}))
+	require.NoError(t, EnsureTutorialSearchIndex(context.Background(), db), "no-op outside Postgres")
+	repo := NewTutorialArticleRepository(db)
+	ctx := context.Background()
+
+	articles := []models.TutorialArticle{
+		{Slug: "intro-go", Title: "Intro to Go", Summary: "Basics", Content: "Goroutines and channels explained.", Tags: []string{"backend"}},
+		{Slug: "css-grid", Title: "CSS Grid", Summary: "Layouts", Content: "Build responsive layouts.", Tags: []string{"frontend"}},
+	}
+	for i := range articles {
+		require.NoError(t, repo.Create(ctx, &articles[i]))
+	}
+
+	byContent, total, err := repo.List(ctx, TutorialContentFilter{Search: "Channels", Sort: "relevance", Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	require.Equal(t, "intro-go", byContent[0].Slug)
+
+	byTag, _, err := repo.List(ctx, TutorialContentFilter{Search: "frontend", Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, byTag, 1)
+	require.Equal(t, "css-grid", byTag[0].Slug)
+}
+
 func setupContentTestDB(t *testing.T, models ...interface{}) *gorm.DB {
 	t.Helper()
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	// Each test gets its own in-memory database so seeded rows never leak
+	// between tests.
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
 	require.NoError(t, err)
 	require.NoError(t, db.AutoMigrate(models...))
 	return db
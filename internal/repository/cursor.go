@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor indicates a pagination cursor that was not issued by this
+// repository layer.
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// Cursor marks the last row of a page in (created_at, id) order. Unlike an
+// offset it stays valid while rows are inserted ahead of it, so pages never
+// skip or repeat rows.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// Encode returns the opaque token handed to clients.
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + "." + strconv.FormatUint(uint64(c.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a token produced by Cursor.Encode. An empty token
+// yields nil, meaning the first page.
+func DecodeCursor(token string) (*Cursor, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	rowID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || rowID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{CreatedAt: time.Unix(0, createdAt).UTC(), ID: uint(rowID)}, nil
+}
+
+// keysetPage orders query by (created_at, id), newest first when descending,
+// resumes after the cursor and fetches one extra row so nextCursor can tell
+// whether another page follows.
+func keysetPage(query *gorm.DB, after *Cursor, limit int, descending bool) *gorm.DB {
+	direction, comparison := "ASC", ">"
+	if descending {
+		direction, comparison = "DESC", "<"
+	}
+	if after != nil {
+		query = query.Where("created_at "+comparison+" ? OR (created_at = ? AND id "+comparison+" ?)",
+			after.CreatedAt, after.CreatedAt, after.ID)
+	}
+	return query.
+		Order("created_at " + direction).
+		Order("id " + direction).
+		Limit(limit + 1)
+}
+
+// nextCursor trims the extra row fetched by keysetPage and returns the cursor
+// of the page's last row, or "" when this is the last page.
+func nextCursor[T any](rows []T, limit int, key func(T) Cursor) ([]T, string) {
+	if len(rows) <= limit {
+		return rows, ""
+	}
+	rows = rows[:limit]
+	return rows, key(rows[len(rows)-1]).Encode()
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/models"
+)
+
+func setupCursorTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:cursor_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(models...))
+	return db
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2024, time.May, 1, 8, 30, 0, 123456789, time.UTC), ID: 42}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	require.NoError(t, err)
+	require.Equal(t, cursor, *decoded)
+
+	empty, err := DecodeCursor("")
+	require.NoError(t, err)
+	require.Nil(t, empty)
+
+	_, err = DecodeCursor("not-a-cursor")
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestNotificationListByUserAfterPagesWithoutGapsOrDuplicates(t *testing.T) {
+	db := setupCursorTestDB(t, &models.Notification{}, &models.NotificationMute{})
+	repo := NewNotificationRepository(db)
+
+	// Pairs share a timestamp so pages must break ties on id.
+	base := time.Date(2024, time.May, 1, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < 11; i++ {
+		require.NoError(t, db.Create(&models.Notification{
+			UserID:    "7",
+			Type:      "info",
+			Message:   fmt.Sprintf("n%d", i),
+			CreatedAt: base.Add(time.Duration(i/2) * time.Minute),
+		}).Error)
+	}
+	require.NoError(t, db.Create(&models.Notification{UserID: "8", Type: "info", Message: "other", CreatedAt: base}).Error)
+
+	seen := map[uint]bool{}
+	var previous *models.Notification
+	cursor, pages := "", 0
+	for {
+		items, next, err := repo.ListByUserAfter(context.Background(), "7", cursor, 4)
+		require.NoError(t, err)
+		pages++
+		for i := range items {
+			item := items[i]
+			require.Equal(t, "7", item.UserID)
+			require.False(t, seen[item.ID], "notification %d returned twice", item.ID)
+			seen[item.ID] = true
+			if previous != nil {
+				require.True(t, item.CreatedAt.Before(previous.CreatedAt) ||
+					(item.CreatedAt.Equal(previous.CreatedAt) && item.ID < previous.ID), "notifications out of order")
+			}
+			previous = &item
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	require.Equal(t, 3, pages)
+	require.Len(t, seen, 11)
+}
+
+func TestDiscussionListRepliesAfterPagesOldestFirst(t *testing.T) {
+	db := setupCursorTestDB(t, &models.DiscussionReply{})
+	repo := NewDiscussionRepository(db)
+
+	base := time.Date(2024, time.May, 1, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < 7; i++ {
+		require.NoError(t, db.Create(&models.DiscussionReply{
+			ThreadID:  1,
+			AuthorID:  "7",
+			Content:   fmt.Sprintf("r%d", i),
+			CreatedAt: base.Add(time.Duration(i/3) * time.Second),
+		}).Error)
+	}
+
+	var contents []string
+	cursor := ""
+	for {
+		replies, next, err := repo.ListRepliesAfter(context.Background(), 1, cursor, 3)
+		require.NoError(t, err)
+		for _, reply := range replies {
+			contents = append(contents, reply.Content)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	require.Equal(t, []string{"r0", "r1", "r2", "r3", "r4", "r5", "r6"}, contents)
+
+	_, _, err := repo.ListRepliesAfter(context.Background(), 1, "bogus", 3)
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
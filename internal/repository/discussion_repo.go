@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -18,6 +19,9 @@ type DiscussionRepository interface {
 	DeleteThread(ctx context.Context, id uint) error
 	CreateReply(ctx context.Context, reply *models.DiscussionReply) error
 	ListReplies(ctx context.Context, threadID uint, limit, offset int) ([]models.DiscussionReply, error)
+	ListRepliesAfter(ctx context.Context, threadID uint, cursor string, limit int) ([]models.DiscussionReply, string, error)
+	ListStaleThreads(ctx context.Context, inactiveSince time.Time, limit int) ([]models.DiscussionThread, error)
+	CloseStaleThread(ctx context.Context, id uint, status string, inactiveSince, closedAt time.Time) (bool, error)
 }
 
 type discussionRepository struct {
@@ -39,6 +43,7 @@ func (r *discussionRepository) ListThreads(ctx context.Context, limit, offset in
 
 	var threads []models.DiscussionThread
 	if err := r.db.WithContext(ctx).
+		Where("status <> ?", models.DiscussionThreadArchived).
 		Order("updated_at DESC").
 		Offset(offset).
 		Limit(limit).
@@ -119,3 +124,63 @@ func (r *discussionRepository) ListReplies(ctx context.Context, threadID uint, l
 
 	return replies, nil
 }
+
+// ListRepliesAfter pages a thread's replies oldest first by keyset, resuming
+// after cursor ("" for the first page). It returns the cursor of the next
+// page, or "" on the last one.
+func (r *discussionRepository) ListRepliesAfter(ctx context.Context, threadID uint, cursor string, limit int) ([]models.DiscussionReply, string, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	after, err := DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var replies []models.DiscussionReply
+	query := r.db.WithContext(ctx).Where("thread_id = ?", threadID)
+	if err := keysetPage(query, after, limit, false).Find(&replies).Error; err != nil {
+		return nil, "", err
+	}
+
+	replies, next := nextCursor(replies, limit, func(reply models.DiscussionReply) Cursor {
+		return Cursor{CreatedAt: reply.CreatedAt, ID: reply.ID}
+	})
+	return replies, next, nil
+}
+
+// ListStaleThreads returns open, unpinned threads with no activity since
+// inactiveSince, oldest first.
+func (r *discussionRepository) ListStaleThreads(ctx context.Context, inactiveSince time.Time, limit int) ([]models.DiscussionThread, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var threads []models.DiscussionThread
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND pinned = ? AND updated_at < ?", models.DiscussionThreadOpen, false, inactiveSince).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&threads).Error; err != nil {
+		return nil, err
+	}
+
+	return threads, nil
+}
+
+// CloseStaleThread moves a thread to status without touching updated_at. The
+// stale conditions are re-checked so a reply or pin that landed after
+// ListStaleThreads keeps the thread open; it then reports false.
+func (r *discussionRepository) CloseStaleThread(ctx context.Context, id uint, status string, inactiveSince, closedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.DiscussionThread{}).
+		Where("id = ? AND status = ? AND pinned = ? AND updated_at < ?", id, models.DiscussionThreadOpen, false, inactiveSince).
+		UpdateColumns(map[string]interface{}{
+			"status":    status,
+			"closed_at": closedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -10,22 +10,30 @@ import (
 	"github.com/noah-isme/gema-go-api/internal/models"
 )
 
-// GalleryFilter narrows public gallery queries.
+// GalleryFilter narrows public gallery queries. FeaturedOnly restricts the
+// results to featured items.
 type GalleryFilter struct {
-	Tags     []string
-	Search   string
-	Page     int
-	PageSize int
+	Tags         []string
+	Search       string
+	FeaturedOnly bool
+	Page         int
+	PageSize     int
 }
 
+// galleryOrder puts featured items first, then items with an explicit sort
+// order ascending, then unordered (zero) items newest first.
+const galleryOrder = "featured DESC, sort_order = 0 ASC, sort_order ASC, created_at DESC, id DESC"
+
 // GalleryRepository manages gallery persistence operations.
 type GalleryRepository interface {
 	List(ctx context.Context, filter GalleryFilter) ([]models.GalleryItem, int64, error)
 	UpsertBatch(ctx context.Context, items []models.GalleryItem) (int64, error)
+	ExistingSlugs(ctx context.Context, slugs []string) ([]string, error)
 	GetByID(ctx context.Context, id uint) (models.GalleryItem, error)
 	Create(ctx context.Context, item *models.GalleryItem) error
 	Update(ctx context.Context, item *models.GalleryItem) error
 	Delete(ctx context.Context, id uint) error
+	Reorder(ctx context.Context, ids []uint) error
 }
 
 type galleryRepository struct {
@@ -49,6 +57,10 @@ func (r *galleryRepository) List(ctx context.Context, filter GalleryFilter) ([]m
 		query = query.Where("tags LIKE ?", like)
 	}
 
+	if filter.FeaturedOnly {
+		query = query.Where("featured = ?", true)
+	}
+
 	if filter.Search != "" {
 		pattern := "%" + strings.ToLower(filter.Search) + "%"
 		query = query.Where("LOWER(title) LIKE ? OR LOWER(caption) LIKE ?", pattern, pattern)
@@ -70,7 +82,7 @@ func (r *galleryRepository) List(ctx context.Context, filter GalleryFilter) ([]m
 	}
 
 	var items []models.GalleryItem
-	if err := query.Order("created_at DESC").Find(&items).Error; err != nil {
+	if err := query.Order(galleryOrder).Find(&items).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -91,6 +103,16 @@ func (r *galleryRepository) UpsertBatch(ctx context.Context, items []models.Gall
 	return result.RowsAffected, result.Error
 }
 
+// ExistingSlugs returns which of slugs are already stored.
+func (r *galleryRepository) ExistingSlugs(ctx context.Context, slugs []string) ([]string, error) {
+	existing := []string{}
+	if len(slugs) == 0 {
+		return existing, nil
+	}
+	err := r.db.WithContext(ctx).Model(&models.GalleryItem{}).Where("slug IN ?", slugs).Pluck("slug", &existing).Error
+	return existing, err
+}
+
 func (r *galleryRepository) GetByID(ctx context.Context, id uint) (models.GalleryItem, error) {
 	var item models.GalleryItem
 	err := r.db.WithContext(ctx).First(&item, id).Error
@@ -115,3 +137,24 @@ func (r *galleryRepository) Delete(ctx context.Context, id uint) error {
 	}
 	return nil
 }
+
+// Reorder assigns sort orders 1..n following the given IDs. It fails with
+// gorm.ErrRecordNotFound without changing anything if any ID is missing.
+func (r *galleryRepository) Reorder(ctx context.Context, ids []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.GalleryItem{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != int64(len(ids)) {
+			return gorm.ErrRecordNotFound
+		}
+
+		for index, id := range ids {
+			if err := tx.Model(&models.GalleryItem{}).Where("id = ?", id).Update("sort_order", index+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
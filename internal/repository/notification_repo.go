@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/noah-isme/gema-go-api/internal/models"
 )
@@ -11,11 +12,25 @@ import (
 // NotificationRepository handles persistence for notification entities.
 type NotificationRepository interface {
 	Create(ctx context.Context, notification *models.Notification) error
+	CreateBatch(ctx context.Context, notifications []*models.Notification, batchSize int) error
+	// CreateWithOutbox inserts notifications and, in the same transaction, the
+	// outbox events toEvent builds for them. toEvent runs after the insert, so
+	// the notifications carry their IDs; a nil event skips that notification.
+	CreateWithOutbox(ctx context.Context, notifications []*models.Notification, batchSize int, toEvent func(*models.Notification) (*models.OutboxEvent, error)) error
 	ListByUser(ctx context.Context, userID string, limit, offset int) ([]models.Notification, error)
+	ListByUserAfter(ctx context.Context, userID, cursor string, limit int) ([]models.Notification, string, error)
 	MarkRead(ctx context.Context, id uint, userID string) (models.Notification, error)
 	FindByID(ctx context.Context, id uint) (models.Notification, error)
+	Mute(ctx context.Context, userID, notificationType string) error
+	Unmute(ctx context.Context, userID, notificationType string) error
+	ListMutedTypes(ctx context.Context, userID string) ([]string, error)
+	MutedUsers(ctx context.Context, notificationType string, userIDs []string) (map[string]bool, error)
 }
 
+// notificationPriorityOrder ranks high priority notifications ahead of the
+// rest; recency decides within a rank.
+const notificationPriorityOrder = "CASE priority WHEN 'high' THEN 0 ELSE 1 END"
+
 type notificationRepository struct {
 	db *gorm.DB
 }
@@ -29,6 +44,39 @@ func (r *notificationRepository) Create(ctx context.Context, notification *model
 	return r.db.WithContext(ctx).Create(notification).Error
 }
 
+func (r *notificationRepository) CreateBatch(ctx context.Context, notifications []*models.Notification, batchSize int) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(notifications, batchSize).Error
+}
+
+func (r *notificationRepository) CreateWithOutbox(ctx context.Context, notifications []*models.Notification, batchSize int, toEvent func(*models.Notification) (*models.OutboxEvent, error)) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(notifications, batchSize).Error; err != nil {
+			return err
+		}
+
+		events := make([]*models.OutboxEvent, 0, len(notifications))
+		for _, notification := range notifications {
+			event, err := toEvent(notification)
+			if err != nil {
+				return err
+			}
+			if event != nil {
+				events = append(events, event)
+			}
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(events, batchSize).Error
+	})
+}
+
 func (r *notificationRepository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]models.Notification, error) {
 	if limit <= 0 || limit > 100 {
 		limit = 50
@@ -40,7 +88,11 @@ func (r *notificationRepository) ListByUser(ctx context.Context, userID string,
 	var notifications []models.Notification
 	if err := r.db.WithContext(ctx).
 		Where("user_id = ?", userID).
+		Where("priority = ? OR type NOT IN (?)", models.NotificationPriorityHigh,
+			r.db.Model(&models.NotificationMute{}).Select("type").Where("user_id = ?", userID)).
+		Order(notificationPriorityOrder).
 		Order("created_at DESC").
+		Order("id DESC").
 		Offset(offset).
 		Limit(limit).
 		Find(&notifications).Error; err != nil {
@@ -50,6 +102,35 @@ func (r *notificationRepository) ListByUser(ctx context.Context, userID string,
 	return notifications, nil
 }
 
+// ListByUserAfter pages a user's notifications newest first by keyset,
+// resuming after cursor ("" for the first page). It returns the cursor of the
+// next page, or "" on the last one. Unlike ListByUser it orders by recency
+// only, since a priority rank cannot be resumed from a (created_at, id) cursor.
+func (r *notificationRepository) ListByUserAfter(ctx context.Context, userID, cursor string, limit int) ([]models.Notification, string, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	after, err := DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("priority = ? OR type NOT IN (?)", models.NotificationPriorityHigh,
+			r.db.Model(&models.NotificationMute{}).Select("type").Where("user_id = ?", userID))
+
+	var notifications []models.Notification
+	if err := keysetPage(query, after, limit, true).Find(&notifications).Error; err != nil {
+		return nil, "", err
+	}
+
+	notifications, next := nextCursor(notifications, limit, func(n models.Notification) Cursor {
+		return Cursor{CreatedAt: n.CreatedAt, ID: n.ID}
+	})
+	return notifications, next, nil
+}
+
 func (r *notificationRepository) MarkRead(ctx context.Context, id uint, userID string) (models.Notification, error) {
 	var notification models.Notification
 	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
@@ -75,3 +156,44 @@ func (r *notificationRepository) FindByID(ctx context.Context, id uint) (models.
 	}
 	return notification, nil
 }
+
+func (r *notificationRepository) Mute(ctx context.Context, userID, notificationType string) error {
+	mute := models.NotificationMute{UserID: userID, Type: notificationType}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&mute).Error
+}
+
+func (r *notificationRepository) Unmute(ctx context.Context, userID, notificationType string) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND type = ?", userID, notificationType).
+		Delete(&models.NotificationMute{}).Error
+}
+
+func (r *notificationRepository) ListMutedTypes(ctx context.Context, userID string) ([]string, error) {
+	types := make([]string, 0)
+	err := r.db.WithContext(ctx).
+		Model(&models.NotificationMute{}).
+		Where("user_id = ?", userID).
+		Order("type ASC").
+		Pluck("type", &types).Error
+	return types, err
+}
+
+// MutedUsers reports which of userIDs have muted notificationType.
+func (r *notificationRepository) MutedUsers(ctx context.Context, notificationType string, userIDs []string) (map[string]bool, error) {
+	muted := make(map[string]bool)
+	if len(userIDs) == 0 {
+		return muted, nil
+	}
+
+	var ids []string
+	if err := r.db.WithContext(ctx).
+		Model(&models.NotificationMute{}).
+		Where("type = ? AND user_id IN ?", notificationType, userIDs).
+		Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		muted[id] = true
+	}
+	return muted, nil
+}
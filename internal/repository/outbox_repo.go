@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/models"
+)
+
+// OutboxRepository reads and settles outbox events for a dispatcher.
+type OutboxRepository interface {
+	// Create stores events written outside a wider transaction.
+	Create(ctx context.Context, events []models.OutboxEvent) error
+	// Claim leases up to limit unsent events of topic to owner until until,
+	// oldest first. Events leased to another owner are skipped until their
+	// lease expires.
+	Claim(ctx context.Context, topic, owner string, now, until time.Time, limit int) ([]models.OutboxEvent, error)
+	MarkSent(ctx context.Context, ids []uint, sentAt time.Time) error
+	// RecordFailure counts a failed publish and releases the lease.
+	RecordFailure(ctx context.Context, id uint, reason string) error
+	// Reschedule counts a failed publish and keeps the event from being
+	// claimed again before retryAt.
+	Reschedule(ctx context.Context, id uint, reason string, retryAt time.Time) error
+	// DeleteSentBefore removes events sent before cutoff.
+	DeleteSentBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository constructs a repository backed by GORM.
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Create(ctx context.Context, events []models.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&events).Error
+}
+
+func (r *outboxRepository) Claim(ctx context.Context, topic, owner string, now, until time.Time, limit int) ([]models.OutboxEvent, error) {
+	db := r.db.WithContext(ctx)
+	claimable := func(query *gorm.DB) *gorm.DB {
+		return query.Where("topic = ? AND sent_at IS NULL", topic).
+			Where("claimed_until IS NULL OR claimed_until < ?", now)
+	}
+
+	var ids []uint
+	if err := claimable(db.Model(&models.OutboxEvent{})).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// The claimable condition is repeated so a concurrent claim wins cleanly.
+	if err := claimable(db.Model(&models.OutboxEvent{})).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"claimed_by": owner, "claimed_until": until}).Error; err != nil {
+		return nil, err
+	}
+
+	var events []models.OutboxEvent
+	if err := db.Where("id IN ? AND claimed_by = ? AND claimed_until > ?", ids, owner, now).
+		Order("id ASC").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *outboxRepository) MarkSent(ctx context.Context, ids []uint, sentAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"sent_at": sentAt, "claimed_until": nil}).Error
+}
+
+func (r *outboxRepository) RecordFailure(ctx context.Context, id uint, reason string) error {
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":      gorm.Expr("attempts + 1"),
+			"last_error":    reason,
+			"claimed_until": nil,
+		}).Error
+}
+
+func (r *outboxRepository) Reschedule(ctx context.Context, id uint, reason string, retryAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":      gorm.Expr("attempts + 1"),
+			"last_error":    reason,
+			"claimed_until": retryAt,
+		}).Error
+}
+
+func (r *outboxRepository) DeleteSentBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("sent_at IS NOT NULL AND sent_at < ?", cutoff).
+		Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
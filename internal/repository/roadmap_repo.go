@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/noah-isme/gema-go-api/internal/models"
 )
@@ -21,17 +23,37 @@ type RoadmapStageFilter struct {
 // RoadmapStageRepository exposes roadmap persistence helpers.
 type RoadmapStageRepository interface {
 	List(ctx context.Context, filter RoadmapStageFilter) ([]models.RoadmapStage, int64, error)
+	GetByID(ctx context.Context, id uint) (models.RoadmapStage, error)
+	Create(ctx context.Context, stage *models.RoadmapStage) error
+	Update(ctx context.Context, stage *models.RoadmapStage) error
+	Delete(ctx context.Context, id uint) error
+	Reorder(ctx context.Context, ids []uint) error
+}
+
+// RoadmapProgressRepository persists the stages students have completed.
+type RoadmapProgressRepository interface {
+	MarkComplete(ctx context.Context, studentID, stageID uint, completedAt time.Time) (models.RoadmapProgress, error)
+	ListByStudent(ctx context.Context, studentID uint, stageIDs []uint) ([]models.RoadmapProgress, error)
 }
 
 type roadmapStageRepository struct {
 	db *gorm.DB
 }
 
+type roadmapProgressRepository struct {
+	db *gorm.DB
+}
+
 // NewRoadmapStageRepository constructs a repository.
 func NewRoadmapStageRepository(db *gorm.DB) RoadmapStageRepository {
 	return &roadmapStageRepository{db: db}
 }
 
+// NewRoadmapProgressRepository constructs a roadmap progress repository.
+func NewRoadmapProgressRepository(db *gorm.DB) RoadmapProgressRepository {
+	return &roadmapProgressRepository{db: db}
+}
+
 func (r *roadmapStageRepository) List(ctx context.Context, filter RoadmapStageFilter) ([]models.RoadmapStage, int64, error) {
 	query := r.db.WithContext(ctx).Model(&models.RoadmapStage{})
 
@@ -75,6 +97,85 @@ func (r *roadmapStageRepository) List(ctx context.Context, filter RoadmapStageFi
 	return stages, total, nil
 }
 
+func (r *roadmapStageRepository) GetByID(ctx context.Context, id uint) (models.RoadmapStage, error) {
+	var stage models.RoadmapStage
+	err := r.db.WithContext(ctx).First(&stage, id).Error
+	return stage, err
+}
+
+func (r *roadmapStageRepository) Create(ctx context.Context, stage *models.RoadmapStage) error {
+	return r.db.WithContext(ctx).Create(stage).Error
+}
+
+func (r *roadmapStageRepository) Update(ctx context.Context, stage *models.RoadmapStage) error {
+	return r.db.WithContext(ctx).Save(stage).Error
+}
+
+// Delete removes the stage together with the progress students made on it.
+func (r *roadmapStageRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.RoadmapStage{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("stage_id = ?", id).Delete(&models.RoadmapProgress{}).Error
+	})
+}
+
+// Reorder assigns sequences 1..n following the given IDs. It fails with
+// gorm.ErrRecordNotFound without changing anything if any ID is missing.
+func (r *roadmapStageRepository) Reorder(ctx context.Context, ids []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.RoadmapStage{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != int64(len(ids)) {
+			return gorm.ErrRecordNotFound
+		}
+
+		for index, id := range ids {
+			if err := tx.Model(&models.RoadmapStage{}).Where("id = ?", id).Update("sequence", index+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MarkComplete records that the student completed the stage. Completing a
+// stage again keeps the original completion time.
+func (r *roadmapProgressRepository) MarkComplete(ctx context.Context, studentID, stageID uint, completedAt time.Time) (models.RoadmapProgress, error) {
+	db := r.db.WithContext(ctx)
+	progress := models.RoadmapProgress{StudentID: studentID, StageID: stageID, CompletedAt: completedAt}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "student_id"}, {Name: "stage_id"}},
+		DoNothing: true,
+	}).Create(&progress).Error
+	if err != nil {
+		return models.RoadmapProgress{}, err
+	}
+
+	var stored models.RoadmapProgress
+	err = db.Where("student_id = ? AND stage_id = ?", studentID, stageID).First(&stored).Error
+	return stored, err
+}
+
+// ListByStudent returns the student's progress on the given stages.
+func (r *roadmapProgressRepository) ListByStudent(ctx context.Context, studentID uint, stageIDs []uint) ([]models.RoadmapProgress, error) {
+	if len(stageIDs) == 0 {
+		return nil, nil
+	}
+	var progress []models.RoadmapProgress
+	err := r.db.WithContext(ctx).
+		Where("student_id = ? AND stage_id IN ?", studentID, stageIDs).
+		Find(&progress).Error
+	return progress, err
+}
+
 func roadmapSortClause(sort string) string {
 	switch strings.ToLower(strings.TrimSpace(sort)) {
 	case "sequence", "sequence:asc", "sequence.asc":
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/noah-isme/gema-go-api/internal/models"
+)
+
+// SubmissionFingerprintRepository persists similarity fingerprints for submissions.
+type SubmissionFingerprintRepository interface {
+	Save(ctx context.Context, fingerprint *models.SubmissionFingerprint) error
+	GetBySubmission(ctx context.Context, submissionID uint) (models.SubmissionFingerprint, error)
+	ListByAssignment(ctx context.Context, assignmentID, excludeSubmissionID uint, limit int) ([]models.SubmissionFingerprint, error)
+	RaiseSimilarity(ctx context.Context, submissionID uint, score float64, matchID uint) error
+}
+
+type submissionFingerprintRepository struct {
+	db *gorm.DB
+}
+
+// NewSubmissionFingerprintRepository constructs a fingerprint repository backed by GORM.
+func NewSubmissionFingerprintRepository(db *gorm.DB) SubmissionFingerprintRepository {
+	return &submissionFingerprintRepository{db: db}
+}
+
+func (r *submissionFingerprintRepository) Save(ctx context.Context, fingerprint *models.SubmissionFingerprint) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "submission_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"hashes", "shingle_count", "updated_at"}),
+	}).Create(fingerprint).Error
+}
+
+func (r *submissionFingerprintRepository) GetBySubmission(ctx context.Context, submissionID uint) (models.SubmissionFingerprint, error) {
+	var fingerprint models.SubmissionFingerprint
+	if err := r.db.WithContext(ctx).Where("submission_id = ?", submissionID).First(&fingerprint).Error; err != nil {
+		return models.SubmissionFingerprint{}, err
+	}
+	return fingerprint, nil
+}
+
+func (r *submissionFingerprintRepository) ListByAssignment(ctx context.Context, assignmentID, excludeSubmissionID uint, limit int) ([]models.SubmissionFingerprint, error) {
+	if limit <= 0 {
+		limit = 200
+	}
+
+	var fingerprints []models.SubmissionFingerprint
+	if err := r.db.WithContext(ctx).
+		Where("assignment_id = ?", assignmentID).
+		Where("submission_id <> ?", excludeSubmissionID).
+		Where("submission_id NOT IN (SELECT id FROM submissions WHERE deleted_at IS NOT NULL)").
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&fingerprints).Error; err != nil {
+		return nil, err
+	}
+	return fingerprints, nil
+}
+
+// RaiseSimilarity records the score and match only when it exceeds the submission's current score.
+func (r *submissionFingerprintRepository) RaiseSimilarity(ctx context.Context, submissionID uint, score float64, matchID uint) error {
+	return r.db.WithContext(ctx).Model(&models.Submission{}).
+		Where("id = ?", submissionID).
+		Where("similarity_score IS NULL OR similarity_score < ?", score).
+		Updates(map[string]interface{}{
+			"similarity_score":    score,
+			"similarity_match_id": matchID,
+		}).Error
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"gorm.io/gorm"
 
@@ -13,15 +15,29 @@ type SubmissionFilter struct {
 	AssignmentID *uint
 	StudentID    *uint
 	Status       *string
+	// LatestOnly hides superseded versions of a student's submission.
+	LatestOnly bool
 }
 
+// ErrSubmissionVersionTaken indicates another submission already holds the
+// version number for the student and assignment, typically because two
+// uploads computed the next version at the same time.
+var ErrSubmissionVersionTaken = errors.New("submission version already taken")
+
+// submissionVersionIndex keeps live version numbers unique per student and
+// assignment. Withdrawn versions are soft-deleted and their numbers reused, so
+// they are left out of the index.
+const submissionVersionIndex = "idx_submissions_assignment_student_version"
+
 // SubmissionRepository defines data operations for submissions.
 type SubmissionRepository interface {
 	List(ctx context.Context, filter SubmissionFilter) ([]models.Submission, error)
 	GetByID(ctx context.Context, id uint) (models.Submission, error)
 	GetByAssignmentAndStudent(ctx context.Context, assignmentID, studentID uint) (models.Submission, error)
+	ListVersions(ctx context.Context, assignmentID, studentID uint) ([]models.Submission, error)
 	Create(ctx context.Context, submission *models.Submission) error
 	Update(ctx context.Context, submission *models.Submission) error
+	Delete(ctx context.Context, id uint) error
 }
 
 type submissionRepository struct {
@@ -54,6 +70,10 @@ func (r *submissionRepository) List(ctx context.Context, filter SubmissionFilter
 		query = query.Where("status = ?", *filter.Status)
 	}
 
+	if filter.LatestOnly {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM submissions newer WHERE newer.assignment_id = submissions.assignment_id AND newer.student_id = submissions.student_id AND newer.version > submissions.version AND newer.deleted_at IS NULL)")
+	}
+
 	var submissions []models.Submission
 	if err := query.Order("created_at DESC").Find(&submissions).Error; err != nil {
 		return nil, err
@@ -76,6 +96,7 @@ func (r *submissionRepository) GetByAssignmentAndStudent(ctx context.Context, as
 	if err := r.baseQuery(ctx).
 		Where("assignment_id = ?", assignmentID).
 		Where("student_id = ?", studentID).
+		Order("version DESC").
 		Order("created_at DESC").
 		First(&submission).Error; err != nil {
 		return models.Submission{}, err
@@ -84,10 +105,77 @@ func (r *submissionRepository) GetByAssignmentAndStudent(ctx context.Context, as
 	return submission, nil
 }
 
+func (r *submissionRepository) ListVersions(ctx context.Context, assignmentID, studentID uint) ([]models.Submission, error) {
+	var submissions []models.Submission
+	if err := r.baseQuery(ctx).
+		Where("assignment_id = ?", assignmentID).
+		Where("student_id = ?", studentID).
+		Order("version DESC").
+		Order("created_at DESC").
+		Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+
+	return submissions, nil
+}
+
+// Create inserts the submission, returning ErrSubmissionVersionTaken when its
+// version number is already in use.
 func (r *submissionRepository) Create(ctx context.Context, submission *models.Submission) error {
-	return r.db.WithContext(ctx).Create(submission).Error
+	err := r.db.WithContext(ctx).Create(submission).Error
+	if isDuplicateKey(r.db, err) {
+		return ErrSubmissionVersionTaken
+	}
+	return err
 }
 
 func (r *submissionRepository) Update(ctx context.Context, submission *models.Submission) error {
 	return r.db.WithContext(ctx).Save(submission).Error
 }
+
+// Delete soft-deletes a submission so it no longer counts as an attempt.
+func (r *submissionRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.Submission{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// EnsureSubmissionVersionIndex creates the unique (assignment_id, student_id,
+// version) index on live submissions. Deployments that already stored
+// duplicate versions keep their rows; the index is skipped, and the error says
+// so, until the duplicates are renumbered.
+func EnsureSubmissionVersionIndex(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+
+	var duplicates int64
+	err := db.Raw(`SELECT COUNT(*) FROM (
+		SELECT assignment_id, student_id, version FROM submissions
+		WHERE deleted_at IS NULL
+		GROUP BY assignment_id, student_id, version HAVING COUNT(*) > 1
+	) AS duplicates`).Scan(&duplicates).Error
+	if err != nil {
+		return err
+	}
+	if duplicates > 0 {
+		return fmt.Errorf("skipping %s: %d duplicate submission versions", submissionVersionIndex, duplicates)
+	}
+
+	return db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + submissionVersionIndex + " ON submissions (assignment_id, student_id, version) WHERE deleted_at IS NULL").Error
+}
+
+// isDuplicateKey reports whether err is a unique constraint violation, using
+// the dialector's translation so it holds for Postgres and SQLite alike.
+func isDuplicateKey(db *gorm.DB, err error) bool {
+	if err == nil {
+		return false
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		err = translator.Translate(err)
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/models"
+)
+
+func TestSubmissionRepositoryRejectsDuplicateVersions(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:submission_versions?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Student{}, &models.Assignment{}, &models.Submission{}))
+	require.NoError(t, EnsureSubmissionVersionIndex(context.Background(), db))
+
+	repo := NewSubmissionRepository(db)
+	ctx := context.Background()
+
+	first := models.Submission{AssignmentID: 1, StudentID: 2, Version: 1, FileURL: "https://files.test/a"}
+	require.NoError(t, repo.Create(ctx, &first))
+
+	clash := models.Submission{AssignmentID: 1, StudentID: 2, Version: 1, FileURL: "https://files.test/b"}
+	require.ErrorIs(t, repo.Create(ctx, &clash), ErrSubmissionVersionTaken)
+
+	// A withdrawn version frees its number for the next upload.
+	require.NoError(t, repo.Delete(ctx, first.ID))
+	require.NoError(t, repo.Create(ctx, &clash))
+}
@@ -2,14 +2,29 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/noah-isme/gema-go-api/internal/models"
 )
 
-// TutorialContentFilter narrows tutorial content queries.
+// tutorialSearchConfig is the text search configuration of the tutorial
+// search vectors. Content mixes Indonesian and English, so words are only
+// lowercased, not stemmed.
+const tutorialSearchConfig = "simple"
+
+// tutorialSearchVector weighs title matches above summary and tag matches,
+// and those above matches in the body.
+const tutorialSearchVector = `setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+	setweight(to_tsvector('simple', coalesce(summary, '')), 'B') ||
+	setweight(to_tsvector('simple', replace(coalesce(tags, ''), '|', ' ')), 'B') ||
+	setweight(to_tsvector('simple', coalesce(content, '')), 'C')`
+
+// TutorialContentFilter narrows tutorial content queries. Sort "relevance"
+// orders search results by rank where full-text search is available.
 type TutorialContentFilter struct {
 	Search   string
 	Tags     []string
@@ -23,6 +38,8 @@ type TutorialArticleRepository interface {
 	List(ctx context.Context, filter TutorialContentFilter) ([]models.TutorialArticle, int64, error)
 	GetByID(ctx context.Context, id uint) (models.TutorialArticle, error)
 	Create(ctx context.Context, article *models.TutorialArticle) error
+	Update(ctx context.Context, article *models.TutorialArticle) error
+	Delete(ctx context.Context, id uint) error
 }
 
 // TutorialProjectRepository persists tutorial projects.
@@ -30,43 +47,43 @@ type TutorialProjectRepository interface {
 	List(ctx context.Context, filter TutorialContentFilter) ([]models.TutorialProject, int64, error)
 	GetByID(ctx context.Context, id uint) (models.TutorialProject, error)
 	Create(ctx context.Context, project *models.TutorialProject) error
+	Update(ctx context.Context, project *models.TutorialProject) error
+	Delete(ctx context.Context, id uint) error
 }
 
 type tutorialArticleRepository struct {
-	db *gorm.DB
+	db       *gorm.DB
+	fullText bool
 }
 
 type tutorialProjectRepository struct {
-	db *gorm.DB
+	db       *gorm.DB
+	fullText bool
 }
 
-// NewTutorialArticleRepository constructs an article repository.
+// NewTutorialArticleRepository constructs an article repository. On Postgres
+// searches use the search_vector column created by EnsureTutorialSearchIndex.
 func NewTutorialArticleRepository(db *gorm.DB) TutorialArticleRepository {
-	return &tutorialArticleRepository{db: db}
+	return &tutorialArticleRepository{db: db, fullText: supportsFullTextSearch(db)}
 }
 
-// NewTutorialProjectRepository constructs a project repository.
+// NewTutorialProjectRepository constructs a project repository. On Postgres
+// searches use the search_vector column created by EnsureTutorialSearchIndex.
 func NewTutorialProjectRepository(db *gorm.DB) TutorialProjectRepository {
-	return &tutorialProjectRepository{db: db}
+	return &tutorialProjectRepository{db: db, fullText: supportsFullTextSearch(db)}
 }
 
 func (r *tutorialArticleRepository) List(ctx context.Context, filter TutorialContentFilter) ([]models.TutorialArticle, int64, error) {
 	query := r.db.WithContext(ctx).Model(&models.TutorialArticle{})
-	query = applyTutorialFilters(query, filter)
-	order := tutorialSortClause(filter.Sort)
-	if order != "" {
-		query = query.Order(order)
-	}
+	query = applyTutorialFilters(query, filter, r.fullText)
+	query = applyTutorialSort(query, filter, r.fullText)
 	return paginateTutorialArticles(query, filter.Page, filter.PageSize)
 }
 
 func (r *tutorialProjectRepository) List(ctx context.Context, filter TutorialContentFilter) ([]models.TutorialProject, int64, error) {
 	query := r.db.WithContext(ctx).Model(&models.TutorialProject{})
-	query = applyTutorialFilters(query, filter)
-	order := tutorialSortClause(filter.Sort)
-	if order != "" {
-		query = query.Order(order)
-	}
+	query = applyTutorialFilters(query, filter, r.fullText)
+	query = applyTutorialSort(query, filter, r.fullText)
 	return paginateTutorialProjects(query, filter.Page, filter.PageSize)
 }
 
@@ -90,10 +107,46 @@ func (r *tutorialProjectRepository) Create(ctx context.Context, project *models.
 	return r.db.WithContext(ctx).Create(project).Error
 }
 
-func applyTutorialFilters(query *gorm.DB, filter TutorialContentFilter) *gorm.DB {
-	if filter.Search != "" {
-		pattern := "%" + strings.ToLower(strings.TrimSpace(filter.Search)) + "%"
-		query = query.Where("LOWER(title) LIKE ? OR LOWER(summary) LIKE ?", pattern, pattern)
+func (r *tutorialArticleRepository) Update(ctx context.Context, article *models.TutorialArticle) error {
+	return r.db.WithContext(ctx).Save(article).Error
+}
+
+func (r *tutorialProjectRepository) Update(ctx context.Context, project *models.TutorialProject) error {
+	return r.db.WithContext(ctx).Save(project).Error
+}
+
+// Delete soft-deletes the article so it can be restored later.
+func (r *tutorialArticleRepository) Delete(ctx context.Context, id uint) error {
+	return softDeleteTutorialContent(r.db.WithContext(ctx), &models.TutorialArticle{}, id)
+}
+
+// Delete soft-deletes the project so it can be restored later.
+func (r *tutorialProjectRepository) Delete(ctx context.Context, id uint) error {
+	return softDeleteTutorialContent(r.db.WithContext(ctx), &models.TutorialProject{}, id)
+}
+
+func softDeleteTutorialContent(db *gorm.DB, model interface{}, id uint) error {
+	result := db.Delete(model, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// applyTutorialFilters matches the search against the full-text index when
+// fullText is set, and otherwise falls back to substring matching over the
+// same fields.
+func applyTutorialFilters(query *gorm.DB, filter TutorialContentFilter, fullText bool) *gorm.DB {
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		if fullText {
+			query = query.Where("search_vector @@ plainto_tsquery(?::regconfig, ?)", tutorialSearchConfig, search)
+		} else {
+			pattern := "%" + strings.ToLower(search) + "%"
+			query = query.Where("LOWER(title) LIKE ? OR LOWER(summary) LIKE ? OR LOWER(content) LIKE ? OR tags LIKE ?", pattern, pattern, pattern, pattern)
+		}
 	}
 
 	for _, tag := range filter.Tags {
@@ -150,6 +203,20 @@ func applyPagination(query *gorm.DB, page, pageSize int) *gorm.DB {
 	return query.Offset(offset).Limit(pageSize)
 }
 
+// applyTutorialSort orders by search rank for relevance-sorted searches on
+// Postgres, newest first as a tie-breaker. Everything else uses
+// tutorialSortClause.
+func applyTutorialSort(query *gorm.DB, filter TutorialContentFilter, fullText bool) *gorm.DB {
+	search := strings.TrimSpace(filter.Search)
+	if fullText && search != "" && strings.EqualFold(strings.TrimSpace(filter.Sort), "relevance") {
+		return query.Order(clause.Expr{
+			SQL:  "ts_rank(search_vector, plainto_tsquery(?::regconfig, ?)) DESC, updated_at DESC",
+			Vars: []interface{}{tutorialSearchConfig, search},
+		})
+	}
+	return query.Order(tutorialSortClause(filter.Sort))
+}
+
 func tutorialSortClause(sort string) string {
 	switch strings.ToLower(strings.TrimSpace(sort)) {
 	case "recent", "-updated_at", "updated_at:desc", "updated_at.desc":
@@ -164,3 +231,29 @@ func tutorialSortClause(sort string) string {
 		return "updated_at DESC"
 	}
 }
+
+// EnsureTutorialSearchIndex adds the generated search_vector column and its
+// GIN index to the tutorial article and project tables. It only applies to
+// Postgres; other databases keep the substring search fallback.
+func EnsureTutorialSearchIndex(ctx context.Context, db *gorm.DB) error {
+	if !supportsFullTextSearch(db) {
+		return nil
+	}
+	db = db.WithContext(ctx)
+
+	for _, table := range []string{"tutorial_articles", "tutorial_projects"} {
+		addColumn := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (%s) STORED", table, tutorialSearchVector)
+		if err := db.Exec(addColumn).Error; err != nil {
+			return fmt.Errorf("add %s search vector: %w", table, err)
+		}
+		createIndex := fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_search_vector ON %s USING GIN (search_vector)", table, table)
+		if err := db.Exec(createIndex).Error; err != nil {
+			return fmt.Errorf("index %s search vector: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func supportsFullTextSearch(db *gorm.DB) bool {
+	return db != nil && db.Dialector != nil && db.Dialector.Name() == "postgres"
+}
@@ -2,15 +2,35 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"gorm.io/gorm"
 
 	"github.com/noah-isme/gema-go-api/internal/models"
 )
 
+// uploadChecksumIndex makes (user_id, checksum) unique for live records that
+// have a checksum, so concurrent identical uploads by one user collapse into
+// one row while a deleted file can be uploaded again.
+const uploadChecksumIndex = "idx_upload_records_user_checksum_live"
+
+// legacyUploadChecksumIndex also covered soft-deleted records.
+const legacyUploadChecksumIndex = "idx_upload_records_user_checksum"
+
+// UploadFilter scopes upload listings. A nil UserID lists every user's uploads.
+type UploadFilter struct {
+	UserID   *uint
+	Page     int
+	PageSize int
+}
+
 // UploadRepository persists metadata about uploaded files.
 type UploadRepository interface {
 	Create(ctx context.Context, record *models.UploadRecord) error
+	FindByChecksum(ctx context.Context, userID uint, checksum string) (models.UploadRecord, error)
+	List(ctx context.Context, filter UploadFilter) ([]models.UploadRecord, int64, error)
+	GetByID(ctx context.Context, id uint) (models.UploadRecord, error)
+	Delete(ctx context.Context, id uint) error
 }
 
 type uploadRepository struct {
@@ -25,3 +45,87 @@ func NewUploadRepository(db *gorm.DB) UploadRepository {
 func (r *uploadRepository) Create(ctx context.Context, record *models.UploadRecord) error {
 	return r.db.WithContext(ctx).Create(record).Error
 }
+
+// FindByChecksum returns the oldest upload by userID with the given checksum.
+// Lookups are always scoped to one user so a file uploaded by someone else is
+// never handed out.
+func (r *uploadRepository) FindByChecksum(ctx context.Context, userID uint, checksum string) (models.UploadRecord, error) {
+	var record models.UploadRecord
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND checksum = ?", userID, checksum).
+		Order("id ASC").
+		First(&record).Error
+	return record, err
+}
+
+func (r *uploadRepository) List(ctx context.Context, filter UploadFilter) ([]models.UploadRecord, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.UploadRecord{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var records []models.UploadRecord
+	err := query.
+		Order("created_at DESC, id DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&records).Error
+	return records, total, err
+}
+
+func (r *uploadRepository) GetByID(ctx context.Context, id uint) (models.UploadRecord, error) {
+	var record models.UploadRecord
+	err := r.db.WithContext(ctx).First(&record, id).Error
+	return record, err
+}
+
+// Delete soft-deletes the record; the stored file is removed by the caller.
+func (r *uploadRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.UploadRecord{}, id).Error
+}
+
+// PrefixUploadChecksums labels checksums stored before they carried their
+// algorithm. Server uploads stored 64-character SHA-256 digests and direct
+// uploads 32-character Cloudinary MD5 etags.
+func PrefixUploadChecksums(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+	for length, algorithm := range map[int]string{64: models.UploadChecksumSHA256, 32: models.UploadChecksumMD5} {
+		err := db.Exec("UPDATE upload_records SET checksum = ? || checksum WHERE LENGTH(checksum) = ? AND checksum NOT LIKE '%:%'",
+			algorithm+":", length).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureUploadChecksumIndex creates the unique (user_id, checksum) index on
+// upload records. Deployments that stored identical files before
+// deduplication existed keep their rows; the index is skipped, and the error
+// says so, until the duplicates are cleaned up.
+func EnsureUploadChecksumIndex(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+
+	var duplicates int64
+	err := db.Raw(`SELECT COUNT(*) FROM (
+		SELECT user_id, checksum FROM upload_records
+		WHERE user_id IS NOT NULL AND checksum <> '' AND deleted_at IS NULL
+		GROUP BY user_id, checksum HAVING COUNT(*) > 1
+	) AS duplicates`).Scan(&duplicates).Error
+	if err != nil {
+		return err
+	}
+	if duplicates > 0 {
+		return fmt.Errorf("skipping %s: %d duplicate user/checksum groups", uploadChecksumIndex, duplicates)
+	}
+
+	if err := db.Exec("DROP INDEX IF EXISTS " + legacyUploadChecksumIndex).Error; err != nil {
+		return err
+	}
+	return db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + uploadChecksumIndex + " ON upload_records (user_id, checksum) WHERE checksum <> '' AND deleted_at IS NULL").Error
+}
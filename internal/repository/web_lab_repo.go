@@ -8,10 +8,12 @@ import (
 	"github.com/noah-isme/gema-go-api/internal/models"
 )
 
-// WebAssignmentRepository exposes read operations for web lab assignments.
+// WebAssignmentRepository exposes persistence helpers for web lab assignments.
 type WebAssignmentRepository interface {
 	List(ctx context.Context) ([]models.WebAssignment, error)
 	GetByID(ctx context.Context, id uint) (models.WebAssignment, error)
+	Create(ctx context.Context, assignment *models.WebAssignment) error
+	Update(ctx context.Context, assignment *models.WebAssignment) error
 }
 
 type webAssignmentRepository struct {
@@ -41,6 +43,14 @@ func (r *webAssignmentRepository) GetByID(ctx context.Context, id uint) (models.
 	return assignment, nil
 }
 
+func (r *webAssignmentRepository) Create(ctx context.Context, assignment *models.WebAssignment) error {
+	return r.db.WithContext(ctx).Create(assignment).Error
+}
+
+func (r *webAssignmentRepository) Update(ctx context.Context, assignment *models.WebAssignment) error {
+	return r.db.WithContext(ctx).Omit("Submissions").Save(assignment).Error
+}
+
 // WebSubmissionRepository exposes persistence helpers for web lab submissions.
 type WebSubmissionRepository interface {
 	Create(ctx context.Context, submission *models.WebSubmission) error
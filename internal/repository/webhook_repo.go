@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/models"
+)
+
+// WebhookRepository persists webhook subscriptions and failed deliveries.
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+	// ListSubscriptions returns the subscriptions for eventType, or every
+	// subscription when it is empty, oldest first.
+	ListSubscriptions(ctx context.Context, eventType string) ([]models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id uint) (models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+	CreateDeadLetter(ctx context.Context, letter *models.WebhookDeadLetter) error
+	ListDeadLetters(ctx context.Context, offset, limit int) ([]models.WebhookDeadLetter, int64, error)
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository constructs a repository backed by GORM.
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Create(subscription).Error
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context, eventType string) ([]models.WebhookSubscription, error) {
+	query := r.db.WithContext(ctx).Model(&models.WebhookSubscription{})
+	if eventType != "" {
+		query = query.Where("event_type = ?", eventType)
+	}
+
+	var subscriptions []models.WebhookSubscription
+	if err := query.Order("id ASC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *webhookRepository) GetSubscription(ctx context.Context, id uint) (models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	err := r.db.WithContext(ctx).First(&subscription, id).Error
+	return subscription, err
+}
+
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.WebhookSubscription{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepository) CreateDeadLetter(ctx context.Context, letter *models.WebhookDeadLetter) error {
+	return r.db.WithContext(ctx).Create(letter).Error
+}
+
+func (r *webhookRepository) ListDeadLetters(ctx context.Context, offset, limit int) ([]models.WebhookDeadLetter, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.WebhookDeadLetter{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var letters []models.WebhookDeadLetter
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&letters).Error; err != nil {
+		return nil, 0, err
+	}
+	return letters, total, nil
+}
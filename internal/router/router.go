@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
 
 	"github.com/noah-isme/gema-go-api/internal/config"
 	"github.com/noah-isme/gema-go-api/internal/handler"
@@ -16,6 +17,7 @@ type Dependencies struct {
 	SubmissionHandler        *handler.SubmissionHandler
 	TutorialContentHandler   *handler.TutorialContentHandler
 	RoadmapHandler           *handler.RoadmapHandler
+	AdminRoadmapHandler      *handler.AdminRoadmapHandler
 	StudentDashboardHandler  *handler.StudentDashboardHandler
 	WebLabHandler            *handler.WebLabHandler
 	CodingTaskHandler        *handler.CodingTaskHandler
@@ -23,11 +25,14 @@ type Dependencies struct {
 	AdminStudentHandler      *handler.AdminStudentHandler
 	AdminAssignmentHandler   *handler.AdminAssignmentHandler
 	AdminGradingHandler      *handler.AdminGradingHandler
+	SimilarityHandler        *handler.SubmissionSimilarityHandler
 	AdminAnalyticsHandler    *handler.AdminAnalyticsHandler
 	AdminActivityHandler     *handler.AdminActivityHandler
 	AdminContactHandler      *handler.AdminContactHandler
+	AdminWebhookHandler      *handler.AdminWebhookHandler
 	AdminGalleryHandler      *handler.AdminGalleryHandler
 	AdminAnnouncementHandler *handler.AdminAnnouncementHandler
+	AdminNotificationHandler *handler.AdminNotificationHandler
 	ChatHandler              *handler.ChatHandler
 	NotificationHandler      *handler.NotificationHandler
 	DiscussionHandler        *handler.DiscussionHandler
@@ -37,7 +42,22 @@ type Dependencies struct {
 	ContactHandler           *handler.ContactHandler
 	UploadHandler            *handler.UploadHandler
 	SeedHandler              *handler.SeedHandler
+	AuthHandler              *handler.AuthHandler
 	JWTMiddleware            fiber.Handler
+	// OptionalJWTMiddleware identifies callers of public endpoints when they
+	// send a token; nil leaves those endpoints anonymous.
+	OptionalJWTMiddleware fiber.Handler
+	// ReadinessProbes are checked by /api/v1/health/ready.
+	ReadinessProbes []handler.ReadinessProbe
+	// RateLimitRedis shares rate limit counters across instances; nil keeps
+	// them per instance.
+	RateLimitRedis *redis.Client
+	// IdempotencyRedis shares responses stored for Idempotency-Key retries
+	// across instances; nil keeps them per instance.
+	IdempotencyRedis *redis.Client
+	// ConfigProvider serves the live configuration; when set, admins can
+	// reload its hot-reloadable fields.
+	ConfigProvider *config.Provider
 }
 
 // Register wires the HTTP routes into the fiber application.
@@ -48,12 +68,34 @@ func Register(app *fiber.App, cfg config.Config, deps Dependencies) {
 		return c.Next()
 	})
 	api.Get("/health", handler.HealthCheck(cfg))
+	api.Get("/health/live", handler.HealthCheck(cfg))
+	api.Get("/health/ready", handler.ReadinessCheck(deps.ReadinessProbes...))
+
+	rateLimit := func(identifier string, max int, window time.Duration, byRole map[string]int) fiber.Handler {
+		cfg := middleware.RateLimitConfig{Identifier: identifier, Max: max, Window: window, Redis: deps.RateLimitRedis}
+		if byRole != nil {
+			cfg.Limit = middleware.LimitByRole(byRole)
+		}
+		return middleware.RateLimit(cfg)
+	}
+	idempotent := func(identifier string) fiber.Handler {
+		return middleware.Idempotent(middleware.IdempotencyConfig{Identifier: identifier, Redis: deps.IdempotencyRedis})
+	}
 
 	// Use provided JWT middleware, or a no-op if nil
 	jwtMiddleware := deps.JWTMiddleware
 	if jwtMiddleware == nil {
 		jwtMiddleware = func(c *fiber.Ctx) error { return c.Next() }
 	}
+	optionalJWT := deps.OptionalJWTMiddleware
+	if optionalJWT == nil {
+		optionalJWT = func(c *fiber.Ctx) error { return c.Next() }
+	}
+
+	if deps.AuthHandler != nil {
+		auth := api.Group("/auth", rateLimit("auth", 10, time.Minute, nil))
+		deps.AuthHandler.Register(auth, jwtMiddleware)
+	}
 
 	// Tutorial (assignments & submissions)
 	if deps.AssignmentHandler != nil {
@@ -62,7 +104,7 @@ func Register(app *fiber.App, cfg config.Config, deps Dependencies) {
 		deps.AssignmentHandler.Register(assignmentGroup)
 
 		if deps.SubmissionHandler != nil {
-			submissionGroup := tutorial.Group("/submissions")
+			submissionGroup := tutorial.Group("/submissions", idempotent("submissions"))
 			deps.SubmissionHandler.Register(submissionGroup)
 		}
 	}
@@ -76,8 +118,8 @@ func Register(app *fiber.App, cfg config.Config, deps Dependencies) {
 	}
 
 	if deps.RoadmapHandler != nil {
-		roadmap := app.Group("/api/roadmap")
-		deps.RoadmapHandler.Register(roadmap)
+		roadmap := app.Group("/api/roadmap", optionalJWT)
+		deps.RoadmapHandler.Register(roadmap, jwtMiddleware, middleware.RequireRole("student"))
 	}
 
 	// Web Lab
@@ -94,7 +136,7 @@ func Register(app *fiber.App, cfg config.Config, deps Dependencies) {
 		deps.CodingTaskHandler.Register(taskGroup)
 
 		if deps.CodingSubmissionHandler != nil {
-			submissionGroup := codingLab.Group("/submissions")
+			submissionGroup := codingLab.Group("/submissions", idempotent("coding-submissions"))
 			deps.CodingSubmissionHandler.Register(submissionGroup)
 		}
 	}
@@ -106,21 +148,35 @@ func Register(app *fiber.App, cfg config.Config, deps Dependencies) {
 	}
 
 	if deps.ChatHandler != nil {
-		chat := app.Group("/api/v2/chat", jwtMiddleware, middleware.RequireRole("student", "teacher", "admin"), middleware.RateLimit("chat", 10, time.Second))
+		chat := app.Group("/api/v2/chat", jwtMiddleware, middleware.RequireRole("student", "teacher", "admin"), rateLimit("chat", 10, time.Second, map[string]int{"teacher": 30, "admin": 30}))
 		deps.ChatHandler.Register(chat)
 	}
 
 	if deps.NotificationHandler != nil {
-		notifications := app.Group("/api/v2/notifications", jwtMiddleware, middleware.RequireRole("student", "teacher", "admin"), middleware.RateLimit("notifications", 8, time.Second))
+		notifications := app.Group("/api/v2/notifications", jwtMiddleware, middleware.RequireRole("student", "teacher", "admin"), rateLimit("notifications", 8, time.Second, map[string]int{"teacher": 16, "admin": 16}))
 		deps.NotificationHandler.Register(notifications)
 	}
 
 	if deps.DiscussionHandler != nil {
-		discussions := app.Group("/api/v2/discussion", jwtMiddleware, middleware.RequireRole("student", "teacher", "admin"), middleware.RateLimit("discussion", 20, time.Second))
+		discussions := app.Group("/api/v2/discussion", jwtMiddleware, middleware.RequireRole("student", "teacher", "admin"), rateLimit("discussion", 20, time.Second, map[string]int{"teacher": 40, "admin": 40}))
 		deps.DiscussionHandler.Register(discussions)
 	}
 
-	if deps.AdminStudentHandler != nil || deps.AdminAssignmentHandler != nil || deps.AdminGradingHandler != nil || deps.AdminAnalyticsHandler != nil || deps.AdminActivityHandler != nil || deps.AdminContactHandler != nil || deps.AdminGalleryHandler != nil || deps.AdminAnnouncementHandler != nil {
+	// Registered ahead of the /api/admin group so only admins, not teachers, reach it.
+	if deps.ConfigProvider != nil {
+		app.Get("/api/admin/config", jwtMiddleware, middleware.RequireRole("admin"), handler.AdminLiveConfig(deps.ConfigProvider))
+		app.Post("/api/admin/config/reload", jwtMiddleware, middleware.RequireRole("admin"), handler.AdminConfigReload(deps.ConfigProvider))
+	} else {
+		app.Get("/api/admin/config", jwtMiddleware, middleware.RequireRole("admin"), handler.AdminConfig(cfg))
+	}
+
+	// Webhook subscriptions hold shared secrets, so only admins manage them.
+	if deps.AdminWebhookHandler != nil {
+		webhooks := app.Group("/api/admin/webhooks", jwtMiddleware, middleware.RequireRole("admin"))
+		deps.AdminWebhookHandler.Register(webhooks)
+	}
+
+	if deps.AdminStudentHandler != nil || deps.AdminAssignmentHandler != nil || deps.AdminGradingHandler != nil || deps.SimilarityHandler != nil || deps.AdminAnalyticsHandler != nil || deps.AdminActivityHandler != nil || deps.AdminContactHandler != nil || deps.AdminGalleryHandler != nil || deps.AdminAnnouncementHandler != nil || deps.AdminNotificationHandler != nil || deps.AdminRoadmapHandler != nil || deps.CodingTaskHandler != nil || deps.CodingSubmissionHandler != nil {
 		admin := app.Group("/api/admin", jwtMiddleware, middleware.RequireRole("admin", "teacher"))
 
 		if deps.AdminStudentHandler != nil {
@@ -133,9 +189,24 @@ func Register(app *fiber.App, cfg config.Config, deps Dependencies) {
 			deps.AdminAssignmentHandler.Register(assignmentGroup)
 		}
 
-		if deps.AdminGradingHandler != nil {
-			submissionGroup := admin.Group("/submissions")
-			deps.AdminGradingHandler.Register(submissionGroup)
+		if deps.AdminGradingHandler != nil || deps.SimilarityHandler != nil {
+			submissionGroup := admin.Group("/submissions", idempotent("admin-grading"))
+			if deps.AdminGradingHandler != nil {
+				deps.AdminGradingHandler.Register(submissionGroup)
+			}
+			if deps.SimilarityHandler != nil {
+				deps.SimilarityHandler.Register(submissionGroup)
+			}
+		}
+
+		if deps.CodingTaskHandler != nil {
+			codingTaskGroup := admin.Group("/coding-tasks")
+			deps.CodingTaskHandler.RegisterAdmin(codingTaskGroup)
+		}
+
+		if deps.CodingSubmissionHandler != nil {
+			codingSubmissionGroup := admin.Group("/coding-submissions")
+			deps.CodingSubmissionHandler.RegisterAdmin(codingSubmissionGroup)
 		}
 
 		if deps.AdminAnalyticsHandler != nil {
@@ -161,14 +232,22 @@ func Register(app *fiber.App, cfg config.Config, deps Dependencies) {
 			announcementGroup := admin.Group("/announcements")
 			deps.AdminAnnouncementHandler.Register(announcementGroup)
 		}
+		if deps.AdminNotificationHandler != nil {
+			notificationGroup := admin.Group("/notifications")
+			deps.AdminNotificationHandler.Register(notificationGroup)
+		}
+		if deps.AdminRoadmapHandler != nil {
+			roadmapGroup := admin.Group("/roadmap/stages")
+			deps.AdminRoadmapHandler.Register(roadmapGroup)
+		}
 	}
 	if deps.ActivityFeedHandler != nil {
 		activities := app.Group("/api/activities")
-		deps.ActivityFeedHandler.Register(activities)
+		deps.ActivityFeedHandler.Register(activities, jwtMiddleware, middleware.RequireRole("admin", "teacher"))
 	}
 
 	if deps.AnnouncementHandler != nil {
-		announcements := app.Group("/api/announcements")
+		announcements := app.Group("/api/announcements", optionalJWT)
 		deps.AnnouncementHandler.Register(announcements)
 	}
 
@@ -178,17 +257,24 @@ func Register(app *fiber.App, cfg config.Config, deps Dependencies) {
 	}
 
 	if deps.ContactHandler != nil {
-		contact := app.Group("/api/contact", jwtMiddleware, middleware.RateLimit("contact", 5, time.Minute))
+		contact := app.Group("/api/contact", jwtMiddleware, rateLimit("contact", 5, time.Minute, nil))
 		deps.ContactHandler.Register(contact)
 	}
 
 	if deps.UploadHandler != nil {
-		upload := app.Group("/api/upload", jwtMiddleware, middleware.RequireRole("student", "teacher", "admin"), middleware.RateLimit("upload", 3, time.Minute))
+		uploadLimit := rateLimit("upload", 3, time.Minute, nil)
+		upload := app.Group("/api/upload", jwtMiddleware, middleware.RequireRole("student", "teacher", "admin"), func(c *fiber.Ctx) error {
+			// Only storing files is throttled; listing and deleting are cheap.
+			if c.Method() != fiber.MethodPost {
+				return c.Next()
+			}
+			return uploadLimit(c)
+		})
 		deps.UploadHandler.Register(upload)
 	}
 
 	if deps.SeedHandler != nil {
-		seed := app.Group("/api/seed", jwtMiddleware, middleware.RequireRole("admin"), middleware.RateLimit("seed", 1, time.Minute))
+		seed := app.Group("/api/seed", jwtMiddleware, middleware.RequireRole("admin"), rateLimit("seed", 1, time.Minute, nil))
 		deps.SeedHandler.Register(seed)
 	}
 
@@ -0,0 +1,43 @@
+// Package seed bundles the versioned content the seed endpoints load when a
+// request does not supply its own items.
+package seed
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/noah-isme/gema-go-api/internal/models"
+)
+
+//go:embed content/*.json
+var content embed.FS
+
+// Announcements returns a fresh copy of the bundled announcements.
+func Announcements() ([]models.Announcement, error) {
+	var items []models.Announcement
+	if err := load("content/announcements.json", &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Gallery returns a fresh copy of the bundled gallery items.
+func Gallery() ([]models.GalleryItem, error) {
+	var items []models.GalleryItem
+	if err := load("content/gallery.json", &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func load(name string, target interface{}) error {
+	data, err := content.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("read seed content %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("decode seed content %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package seed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundledContentHasUniqueSlugs(t *testing.T) {
+	announcements, err := Announcements()
+	require.NoError(t, err)
+	require.NotEmpty(t, announcements)
+	seen := map[string]bool{}
+	for _, item := range announcements {
+		require.NotEmpty(t, item.Slug)
+		require.False(t, seen[item.Slug], "duplicate announcement slug %s", item.Slug)
+		require.False(t, item.StartsAt.IsZero(), "announcement %s needs starts_at", item.Slug)
+		seen[item.Slug] = true
+	}
+
+	gallery, err := Gallery()
+	require.NoError(t, err)
+	require.NotEmpty(t, gallery)
+	seen = map[string]bool{}
+	for _, item := range gallery {
+		require.NotEmpty(t, item.Slug)
+		require.NotEmpty(t, item.ImagePath)
+		require.False(t, seen[item.Slug], "duplicate gallery slug %s", item.Slug)
+		seen[item.Slug] = true
+	}
+}
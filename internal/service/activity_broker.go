@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+)
+
+const activityStreamBufferSize = 32
+
+// ActivityStreamFilter narrows a live activity stream. Zero values match
+// every entry.
+type ActivityStreamFilter struct {
+	ActorID    *uint
+	EntityType string
+	Action     string
+}
+
+func (f ActivityStreamFilter) matches(item dto.ActivityFeedItem) bool {
+	if f.ActorID != nil && *f.ActorID != item.ActorID {
+		return false
+	}
+	if f.EntityType != "" && f.EntityType != item.EntityType {
+		return false
+	}
+	if f.Action != "" && f.Action != item.Action {
+		return false
+	}
+	return true
+}
+
+// ActivityBroker fans recorded activity entries out to live stream
+// subscribers. Entries are delivered to this instance's subscribers directly
+// and relayed to the other nodes over Redis pub/sub and NATS when those are
+// configured, as chat and notification events are. Slow subscribers miss
+// entries rather than block the writer.
+type ActivityBroker struct {
+	mu          sync.RWMutex
+	subscribers map[chan dto.ActivityFeedItem]ActivityStreamFilter
+	draining    bool
+	// active counts subscriptions whose streams have not been cleaned up yet.
+	active sync.WaitGroup
+
+	redis        *redis.Client
+	redisChannel string
+	nats         *NATSRelay
+	natsSubject  string
+	nodeID       string
+	logger       zerolog.Logger
+}
+
+type activityEvent struct {
+	Source string               `json:"source"`
+	Item   dto.ActivityFeedItem `json:"item"`
+}
+
+// NewActivityBroker constructs an empty broker. With a channelBase, entries
+// are relayed between nodes through whichever of redisClient and natsRelay
+// is set; Start subscribes to the other nodes' entries.
+func NewActivityBroker(redisClient *redis.Client, channelBase string, natsRelay *NATSRelay, logger zerolog.Logger) *ActivityBroker {
+	channel := ""
+	subject := ""
+	if channelBase != "" {
+		channel = channelBase + ":activity"
+		subject = natsSubjectBase(channelBase) + ".activity"
+	}
+
+	return &ActivityBroker{
+		subscribers:  make(map[chan dto.ActivityFeedItem]ActivityStreamFilter),
+		redis:        redisClient,
+		redisChannel: channel,
+		nats:         natsRelay,
+		natsSubject:  subject,
+		nodeID:       uuid.NewString(),
+		logger:       logger.With().Str("component", "activity_broker").Logger(),
+	}
+}
+
+// Start relays other nodes' entries to this node's subscribers until ctx is
+// done.
+func (b *ActivityBroker) Start(ctx context.Context) {
+	if b.redis != nil && b.redisChannel != "" {
+		go b.consumeRedis(ctx)
+	}
+	if b.nats != nil && b.natsSubject != "" {
+		go b.consumeNATS(ctx)
+	}
+}
+
+// Publish delivers item to every local subscriber whose filter matches it and
+// relays it to the other nodes. A failed relay is logged; local delivery and
+// the recorded entry are unaffected.
+func (b *ActivityBroker) Publish(ctx context.Context, item dto.ActivityFeedItem) {
+	b.deliver(item)
+
+	if err := b.relay(ctx, item); err != nil {
+		b.logger.Warn().Err(err).Str("action", item.Action).Msg("failed to relay activity entry")
+	}
+}
+
+func (b *ActivityBroker) relay(ctx context.Context, item dto.ActivityFeedItem) error {
+	if (b.redis == nil || b.redisChannel == "") && (b.nats == nil || b.natsSubject == "") {
+		return nil
+	}
+
+	payload, err := json.Marshal(activityEvent{Source: b.nodeID, Item: item})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if b.redis != nil && b.redisChannel != "" {
+		errs = append(errs, b.redis.Publish(ctx, b.redisChannel, payload).Err())
+	}
+	if b.nats != nil && b.natsSubject != "" {
+		errs = append(errs, b.nats.publish(ctx, b.natsSubject, payload))
+	}
+	return errors.Join(errs...)
+}
+
+func (b *ActivityBroker) consumeRedis(ctx context.Context) {
+	pubsub := b.redis.Subscribe(ctx, b.redisChannel)
+	defer func() { _ = pubsub.Close() }()
+
+	for {
+		msg, err := pubsub.ReceiveMessage(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			b.logger.Error().Err(err).Msg("activity redis subscription closed")
+			return
+		}
+		_ = b.handleEvent([]byte(msg.Payload))
+	}
+}
+
+func (b *ActivityBroker) consumeNATS(ctx context.Context) {
+	if err := b.nats.subscribe(ctx, b.natsSubject, "gema-activity", b.handleEvent, b.logger); err != nil {
+		b.logger.Error().Err(err).Msg("failed to subscribe to nats activity subject")
+	}
+}
+
+// handleEvent delivers a relayed entry to local subscribers. It only fails
+// for payloads that cannot be decoded.
+func (b *ActivityBroker) handleEvent(payload []byte) error {
+	var event activityEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		b.logger.Warn().Err(err).Msg("invalid activity event payload")
+		return err
+	}
+
+	// This node delivered its own entries when it published them.
+	if event.Source == b.nodeID {
+		return nil
+	}
+	b.deliver(event.Item)
+	return nil
+}
+
+// deliver hands item to every local subscriber whose filter matches it.
+func (b *ActivityBroker) deliver(item dto.ActivityFeedItem) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch, filter := range b.subscribers {
+		if !filter.matches(item) {
+			continue
+		}
+		select {
+		case ch <- item:
+		default:
+		}
+	}
+}
+
+// Subscribe streams entries matching filter. The channel is closed when the
+// broker drains for shutdown; during shutdown it is returned closed.
+func (b *ActivityBroker) Subscribe(filter ActivityStreamFilter) (<-chan dto.ActivityFeedItem, func()) {
+	filter.EntityType = strings.ToLower(strings.TrimSpace(filter.EntityType))
+	filter.Action = strings.ToLower(strings.TrimSpace(filter.Action))
+	channel := make(chan dto.ActivityFeedItem, activityStreamBufferSize)
+
+	b.mu.Lock()
+	if b.draining {
+		b.mu.Unlock()
+		close(channel)
+		return channel, func() {}
+	}
+	b.active.Add(1)
+	b.subscribers[channel] = filter
+	b.mu.Unlock()
+
+	cleanup := func() {
+		b.mu.Lock()
+		// Shutdown may already have closed and removed the channel.
+		if _, subscribed := b.subscribers[channel]; subscribed {
+			delete(b.subscribers, channel)
+			close(channel)
+		}
+		b.mu.Unlock()
+		b.active.Done()
+	}
+	return channel, cleanup
+}
+
+// Shutdown stops accepting subscribers and closes every subscription so the
+// streams end. It waits, until ctx ends, for the streams to finish writing.
+func (b *ActivityBroker) Shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	b.draining = true
+	for ch := range b.subscribers {
+		close(ch)
+		delete(b.subscribers, ch)
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
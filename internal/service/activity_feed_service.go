@@ -8,9 +8,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/observability"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -19,28 +20,48 @@ import (
 // ActivityFeedService exposes functionality for the public activity stream.
 type ActivityFeedService interface {
 	ListActive(ctx context.Context, req dto.ActivityFeedRequest) (dto.ActivityFeedResponse, error)
+	Stream(req dto.ActivityFeedRequest) (<-chan dto.ActivityFeedItem, func())
 }
 
 type activityFeedService struct {
 	repo   repository.ActivityLogRepository
-	cache  *redis.Client
+	cache  cache.Store
 	ttl    time.Duration
+	broker *ActivityBroker
 	logger zerolog.Logger
+	clock  clock.Clock
 }
 
-// NewActivityFeedService builds the activity feed service.
-func NewActivityFeedService(repo repository.ActivityLogRepository, cache *redis.Client, ttl time.Duration, logger zerolog.Logger) ActivityFeedService {
+// NewActivityFeedService builds the activity feed service. Live streams are
+// served from broker, which must be the one ActivityService publishes to.
+func NewActivityFeedService(repo repository.ActivityLogRepository, cache cache.Store, ttl time.Duration, broker *ActivityBroker, logger zerolog.Logger) ActivityFeedService {
 	if ttl <= 0 {
 		ttl = 45 * time.Second
 	}
+	if broker == nil {
+		broker = NewActivityBroker(nil, "", nil, logger)
+	}
 	return &activityFeedService{
 		repo:   repo,
 		cache:  cache,
 		ttl:    ttl,
+		broker: broker,
 		logger: logger.With().Str("component", "activity_feed_service").Logger(),
+		clock:  clock.Real(),
 	}
 }
 
+// Stream subscribes to entries recorded from now on that match the request's
+// actor, entity type and action filters. Call the returned func to
+// unsubscribe.
+func (s *activityFeedService) Stream(req dto.ActivityFeedRequest) (<-chan dto.ActivityFeedItem, func()) {
+	return s.broker.Subscribe(ActivityStreamFilter{
+		ActorID:    req.UserID,
+		EntityType: req.Type,
+		Action:     req.Action,
+	})
+}
+
 func (s *activityFeedService) ListActive(ctx context.Context, req dto.ActivityFeedRequest) (dto.ActivityFeedResponse, error) {
 	start := time.Now()
 	defer func() {
@@ -49,7 +70,7 @@ func (s *activityFeedService) ListActive(ctx context.Context, req dto.ActivityFe
 
 	page := maxInt(req.Page, 1)
 	pageSize := clampPageSize(req.PageSize)
-	now := time.Now()
+	now := s.clock.Now()
 	since := now.Add(-24 * time.Hour)
 
 	filter := repository.ActivityLogRecentFilter{
@@ -73,7 +94,7 @@ func (s *activityFeedService) ListActive(ctx context.Context, req dto.ActivityFe
 
 	cacheKey := s.cacheKey(filter)
 	if cacheKey != "" && s.cache != nil {
-		if cached, err := s.cache.Get(ctx, cacheKey).Result(); err == nil && cached != "" {
+		if cached, err := s.cache.Get(ctx, cacheKey); err == nil && cached != "" {
 			var response dto.ActivityFeedResponse
 			if err := json.Unmarshal([]byte(cached), &response); err == nil {
 				response.CacheHit = true
@@ -91,16 +112,7 @@ func (s *activityFeedService) ListActive(ctx context.Context, req dto.ActivityFe
 
 	items := make([]dto.ActivityFeedItem, 0, len(entries))
 	for _, entry := range entries {
-		items = append(items, dto.ActivityFeedItem{
-			ID:         entry.ID,
-			ActorID:    entry.ActorID,
-			ActorRole:  entry.ActorRole,
-			Action:     entry.Action,
-			EntityType: entry.EntityType,
-			EntityID:   entry.EntityID,
-			Metadata:   map[string]interface{}(entry.Metadata),
-			CreatedAt:  entry.CreatedAt,
-		})
+		items = append(items, dto.NewActivityFeedItem(entry))
 	}
 
 	pagination := dto.PaginationMeta{
@@ -116,11 +128,9 @@ func (s *activityFeedService) ListActive(ctx context.Context, req dto.ActivityFe
 
 	response := dto.ActivityFeedResponse{Items: items, Pagination: pagination, CacheHit: false}
 
-	if cacheKey != "" && s.cache != nil {
-		if payload, err := json.Marshal(response); err == nil {
-			if err := s.cache.Set(ctx, cacheKey, payload, s.ttl).Err(); err != nil {
-				s.logger.Warn().Err(err).Msg("failed to write activity feed cache")
-			}
+	if cacheKey != "" {
+		if err := cache.Write(ctx, s.cache, "activity_feed", cacheKey, response, s.ttl); err != nil {
+			s.logger.Warn().Err(err).Msg("failed to write activity feed cache")
 		}
 	}
 
@@ -6,9 +6,11 @@ import (
 	"time"
 
 	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/validator/v10"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -26,6 +28,14 @@ func (r *activityFeedRepo) List(ctx context.Context, filter repository.ActivityL
 	return nil, 0, nil
 }
 
+func (r *activityFeedRepo) DeleteBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	return 0, nil
+}
+
+func (r *activityFeedRepo) EachForExport(ctx context.Context, filter repository.ActivityLogExportFilter, batchSize int, fn func([]models.ActivityLog) error) error {
+	return nil
+}
+
 func (r *activityFeedRepo) ListRecent(ctx context.Context, filter repository.ActivityLogRecentFilter) ([]models.ActivityLog, int64, error) {
 	filtered := make([]models.ActivityLog, 0)
 	for _, item := range r.items {
@@ -56,7 +66,7 @@ func TestActivityFeedServiceCache(t *testing.T) {
 		{ID: 1, ActorID: 1, ActorRole: "admin", Action: "create", EntityType: "announcement", CreatedAt: now},
 	}}
 
-	svc := NewActivityFeedService(repo, redisClient, time.Minute, testLogger())
+	svc := NewActivityFeedService(repo, cache.NewRedisStore(redisClient), time.Minute, nil, testLogger())
 
 	resp, err := svc.ListActive(context.Background(), dto.ActivityFeedRequest{Page: 1, PageSize: 10})
 	require.NoError(t, err)
@@ -78,7 +88,7 @@ func TestActivityFeedServiceFilters(t *testing.T) {
 		{ID: 2, ActorID: 2, ActorRole: "teacher", Action: "update", EntityType: "gallery", CreatedAt: time.Now()},
 	}}
 
-	svc := NewActivityFeedService(repo, nil, time.Minute, testLogger())
+	svc := NewActivityFeedService(repo, nil, time.Minute, nil, testLogger())
 
 	userID := uint(2)
 	resp, err := svc.ListActive(context.Background(), dto.ActivityFeedRequest{UserID: &userID})
@@ -91,3 +101,88 @@ func TestActivityFeedServiceFilters(t *testing.T) {
 	require.Len(t, respType.Items, 1)
 	require.Equal(t, "announcement", respType.Items[0].EntityType)
 }
+
+func TestActivityFeedServiceStreamsRecordedEntries(t *testing.T) {
+	broker := NewActivityBroker(nil, "", nil, testLogger())
+	recorder := NewActivityService(&memoryActivityRepo{}, broker, nil, validator.New(), testLogger())
+	feed := NewActivityFeedService(&activityFeedRepo{}, nil, time.Minute, broker, testLogger())
+
+	actorID := uint(2)
+	galleryOnly, stopGallery := feed.Stream(dto.ActivityFeedRequest{Type: "Gallery"})
+	byActor, stopActor := feed.Stream(dto.ActivityFeedRequest{UserID: &actorID, Action: "gallery.updated"})
+
+	ctx := context.Background()
+	_, err := recorder.Record(ctx, ActivityEntry{ActorID: 1, ActorRole: "admin", Action: "gallery.updated", EntityType: "gallery"})
+	require.NoError(t, err)
+	_, err = recorder.Record(ctx, ActivityEntry{ActorID: 2, ActorRole: "teacher", Action: "gallery.updated", EntityType: "gallery"})
+	require.NoError(t, err)
+	_, err = recorder.Record(ctx, ActivityEntry{ActorID: 2, ActorRole: "teacher", Action: "announcement.created", EntityType: "announcement"})
+	require.NoError(t, err)
+
+	require.Equal(t, uint(1), (<-galleryOnly).ActorID)
+	require.Equal(t, uint(2), (<-galleryOnly).ActorID)
+	require.Empty(t, galleryOnly, "other entity types are filtered out")
+
+	item := <-byActor
+	require.Equal(t, uint(2), item.ActorID)
+	require.Equal(t, "gallery", item.EntityType)
+	require.Empty(t, byActor)
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- broker.Shutdown(ctx) }()
+
+	// Streams see their channel closed and clean up, which lets Shutdown return.
+	_, open := <-galleryOnly
+	require.False(t, open, "shutdown closes live streams")
+	stopGallery()
+	_, open = <-byActor
+	require.False(t, open)
+	stopActor()
+	require.NoError(t, <-shutdown)
+
+	late, stopLate := feed.Stream(dto.ActivityFeedRequest{})
+	defer stopLate()
+	_, open = <-late
+	require.False(t, open, "no new streams during shutdown")
+}
+
+func TestActivityBrokerRelaysEntriesAcrossNodes(t *testing.T) {
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	defer server.Close()
+
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	origin := NewActivityBroker(redisClient, "gema", nil, testLogger())
+	peer := NewActivityBroker(redisClient, "gema", nil, testLogger())
+	origin.Start(ctx)
+	peer.Start(ctx)
+	require.Eventually(t, func() bool {
+		return server.PubSubNumSub("gema:activity")["gema:activity"] == 2
+	}, time.Second, 10*time.Millisecond)
+
+	local, stopLocal := origin.Subscribe(ActivityStreamFilter{})
+	defer stopLocal()
+	remote, stopRemote := peer.Subscribe(ActivityStreamFilter{EntityType: "gallery"})
+	defer stopRemote()
+
+	origin.Publish(ctx, dto.ActivityFeedItem{ID: 7, ActorID: 1, Action: "gallery.updated", EntityType: "gallery"})
+
+	select {
+	case item := <-remote:
+		require.Equal(t, uint(7), item.ID)
+	case <-time.After(time.Second):
+		t.Fatal("entry was not relayed to the other node")
+	}
+
+	require.Equal(t, uint(7), (<-local).ID)
+	select {
+	case item := <-local:
+		t.Fatalf("origin delivered its own entry twice: %+v", item)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/noah-isme/gema-go-api/internal/clock"
+)
+
+const defaultActivityPruneInterval = 24 * time.Hour
+
+// ActivityRetentionConfig controls the activity log pruning job. A zero
+// Retention disables it.
+type ActivityRetentionConfig struct {
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+// ActivityPruner periodically removes activity log entries older than the
+// retention window.
+type ActivityPruner interface {
+	Start(ctx context.Context)
+	PruneExpired(ctx context.Context) (int64, error)
+}
+
+type activityPruner struct {
+	activities ActivityService
+	cfg        ActivityRetentionConfig
+	logger     zerolog.Logger
+	clock      clock.Clock
+}
+
+// NewActivityPruner constructs the retention job.
+func NewActivityPruner(activities ActivityService, cfg ActivityRetentionConfig, logger zerolog.Logger) ActivityPruner {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultActivityPruneInterval
+	}
+
+	return &activityPruner{
+		activities: activities,
+		cfg:        cfg,
+		logger:     logger.With().Str("component", "activity_pruner").Logger(),
+		clock:      clock.Real(),
+	}
+}
+
+// Start runs PruneExpired every interval until ctx is cancelled.
+func (j *activityPruner) Start(ctx context.Context) {
+	if j.cfg.Retention <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(j.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := j.PruneExpired(ctx); err != nil && ctx.Err() == nil {
+				j.logger.Error().Err(err).Msg("failed to prune activity logs")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// PruneExpired removes entries older than the retention window on behalf of
+// the system actor and returns how many were removed.
+func (j *activityPruner) PruneExpired(ctx context.Context) (int64, error) {
+	if j.cfg.Retention <= 0 {
+		return 0, nil
+	}
+	return j.activities.Prune(ctx, j.clock.Now().Add(-j.cfg.Retention), ActivityActor{Role: "system"})
+}
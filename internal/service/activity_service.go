@@ -2,14 +2,19 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog"
 	"gorm.io/datatypes"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -21,6 +26,16 @@ type ActivityActor struct {
 	Role string
 }
 
+// IsStaffRole reports whether role is a teacher or admin, the roles that may
+// act on other users' data.
+func IsStaffRole(role string) bool {
+	switch strings.ToLower(strings.TrimSpace(role)) {
+	case "teacher", "admin":
+		return true
+	}
+	return false
+}
+
 // ActivityEntry captures the details required to persist an audit entry.
 type ActivityEntry struct {
 	ActorID    uint
@@ -41,20 +56,39 @@ type ActivityService interface {
 	ActivityRecorder
 	List(ctx context.Context, req dto.AdminActivityListRequest) (dto.AdminActivityListResponse, error)
 	Create(ctx context.Context, actor ActivityActor, payload dto.AdminActivityCreateRequest) (dto.AdminActivityResponse, error)
+	Prune(ctx context.Context, olderThan time.Time, actor ActivityActor) (int64, error)
+	Export(ctx context.Context, w io.Writer, query dto.AdminActivityExportQuery, actor ActivityActor) (int, error)
 }
 
+const (
+	activityPruneBatchSize  = 1000
+	activityExportBatchSize = 500
+)
+
+// ErrActivityPruneCutoff indicates a prune cutoff in the future, which would
+// remove entries written after the request.
+var ErrActivityPruneCutoff = errors.New("prune cutoff must not be in the future")
+
 type activityService struct {
 	repo      repository.ActivityLogRepository
+	broker    *ActivityBroker
+	webhooks  WebhookDispatcher
 	validator *validator.Validate
 	logger    zerolog.Logger
+	clock     clock.Clock
 }
 
-// NewActivityService constructs the activity log service.
-func NewActivityService(repo repository.ActivityLogRepository, validator *validator.Validate, logger zerolog.Logger) ActivityService {
+// NewActivityService constructs the activity log service. Recorded entries
+// are published to broker for live streams and offered to webhooks as events
+// when they are not nil.
+func NewActivityService(repo repository.ActivityLogRepository, broker *ActivityBroker, webhooks WebhookDispatcher, validator *validator.Validate, logger zerolog.Logger) ActivityService {
 	return &activityService{
 		repo:      repo,
+		broker:    broker,
+		webhooks:  webhooks,
 		validator: validator,
 		logger:    logger.With().Str("component", "activity_service").Logger(),
+		clock:     clock.Real(),
 	}
 }
 
@@ -98,6 +132,21 @@ func (s *activityService) Record(ctx context.Context, entry ActivityEntry) (dto.
 		return dto.AdminActivityResponse{}, err
 	}
 
+	if s.broker != nil {
+		s.broker.Publish(ctx, dto.NewActivityFeedItem(model))
+	}
+	if s.webhooks != nil {
+		s.webhooks.Dispatch(ctx, WebhookEvent{
+			Type:       model.Action,
+			ActorID:    model.ActorID,
+			ActorRole:  model.ActorRole,
+			EntityType: model.EntityType,
+			EntityID:   model.EntityID,
+			Data:       map[string]interface{}(model.Metadata),
+			OccurredAt: model.CreatedAt,
+		})
+	}
+
 	return dto.NewAdminActivityResponse(model), nil
 }
 
@@ -136,6 +185,88 @@ func (s *activityService) List(ctx context.Context, req dto.AdminActivityListReq
 	return dto.AdminActivityListResponse{Items: responses, Pagination: pagination}, nil
 }
 
+// Prune deletes entries created before olderThan in batches of
+// activityPruneBatchSize so no single delete holds the table for long. When
+// anything was removed an activity.pruned entry records the count; it is
+// newer than the cutoff and so survives the prune.
+func (s *activityService) Prune(ctx context.Context, olderThan time.Time, actor ActivityActor) (int64, error) {
+	if olderThan.After(s.clock.Now()) {
+		return 0, ErrActivityPruneCutoff
+	}
+
+	var removed int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+		deleted, err := s.repo.DeleteBefore(ctx, olderThan, activityPruneBatchSize)
+		removed += deleted
+		if err != nil {
+			return removed, err
+		}
+		if deleted < activityPruneBatchSize {
+			break
+		}
+	}
+
+	if removed > 0 {
+		s.logger.Info().Int64("removed", removed).Time("older_than", olderThan).Msg("activity logs pruned")
+		entry := ActivityEntry{
+			ActorID:    actor.ID,
+			ActorRole:  actor.Role,
+			Action:     "activity.pruned",
+			EntityType: "activity_log",
+			Metadata: map[string]interface{}{
+				"removed":    removed,
+				"older_than": olderThan.UTC().Format(time.RFC3339),
+			},
+		}
+		if _, err := s.Record(ctx, entry); err != nil {
+			s.logger.Warn().Err(err).Msg("failed to record activity prune")
+		}
+	}
+
+	return removed, nil
+}
+
+// Export writes matching entries to w as newline-delimited JSON, oldest
+// first, flushing after every batch when w supports it. It returns the number
+// of entries written and records an export.generated entry on success.
+func (s *activityService) Export(ctx context.Context, w io.Writer, query dto.AdminActivityExportQuery, actor ActivityActor) (int, error) {
+	filter := repository.ActivityLogExportFilter{}
+	filters := map[string]interface{}{}
+	if query.From != nil {
+		filter.Since = *query.From
+		filters["from"] = query.From.UTC().Format(time.RFC3339)
+	}
+	if query.To != nil {
+		filter.Until = *query.To
+		filters["to"] = query.To.UTC().Format(time.RFC3339)
+	}
+
+	flusher, _ := w.(interface{ Flush() error })
+	encoder := json.NewEncoder(w)
+	rows := 0
+	err := s.repo.EachForExport(ctx, filter, activityExportBatchSize, func(batch []models.ActivityLog) error {
+		for _, entry := range batch {
+			if err := encoder.Encode(dto.NewAdminActivityResponse(entry)); err != nil {
+				return err
+			}
+			rows++
+		}
+		if flusher != nil {
+			return flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return rows, err
+	}
+
+	recordExport(ctx, s, s.logger, actor, "activities", "ndjson", rows, filters)
+	return rows, nil
+}
+
 func sanitizeMetadata(metadata map[string]interface{}) datatypes.JSONMap {
 	if metadata == nil {
 		return datatypes.JSONMap{}
@@ -1,13 +1,21 @@
 package service
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
 )
@@ -31,10 +39,18 @@ func (m *memoryActivityRepo) ListRecent(ctx context.Context, filter repository.A
 	return append([]models.ActivityLog(nil), m.entries...), int64(len(m.entries)), nil
 }
 
+func (m *memoryActivityRepo) DeleteBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	return 0, nil
+}
+
+func (m *memoryActivityRepo) EachForExport(ctx context.Context, filter repository.ActivityLogExportFilter, batchSize int, fn func([]models.ActivityLog) error) error {
+	return fn(append([]models.ActivityLog(nil), m.entries...))
+}
+
 func TestActivityServiceRecordMasksEmail(t *testing.T) {
 	repo := &memoryActivityRepo{}
 	validate := validator.New(validator.WithRequiredStructEnabled())
-	svc := NewActivityService(repo, validate, testLogger())
+	svc := NewActivityService(repo, nil, nil, validate, testLogger())
 
 	entry, err := svc.Record(context.Background(), ActivityEntry{
 		ActorID:    1,
@@ -56,3 +72,119 @@ func TestActivityServiceRecordMasksEmail(t *testing.T) {
 func ptrUint(v uint) *uint {
 	return &v
 }
+
+func setupActivityLogDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:activity_logs_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.ActivityLog{}))
+	return db
+}
+
+func TestActivityServicePruneDeletesInBatches(t *testing.T) {
+	db := setupActivityLogDB(t)
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	cutoff := now.AddDate(0, 0, -90)
+
+	entries := make([]models.ActivityLog, 0, activityPruneBatchSize+253)
+	for i := 0; i < activityPruneBatchSize+250; i++ {
+		entries = append(entries, models.ActivityLog{ActorID: 1, ActorRole: "admin", Action: "student.updated", EntityType: "student", CreatedAt: cutoff.Add(-time.Duration(i+1) * time.Minute)})
+	}
+	for i := 0; i < 3; i++ {
+		entries = append(entries, models.ActivityLog{ActorID: 1, ActorRole: "admin", Action: "student.updated", EntityType: "student", CreatedAt: cutoff.Add(time.Duration(i) * time.Hour)})
+	}
+	require.NoError(t, db.CreateInBatches(&entries, 200).Error)
+
+	svc := NewActivityService(repository.NewActivityLogRepository(db), nil, nil, validator.New(), testLogger())
+	svc.(*activityService).clock = clock.NewFixed(now)
+
+	_, err := svc.Prune(context.Background(), now.Add(time.Hour), ActivityActor{ID: 1, Role: "admin"})
+	require.ErrorIs(t, err, ErrActivityPruneCutoff)
+
+	removed, err := svc.Prune(context.Background(), cutoff, ActivityActor{ID: 1, Role: "admin"})
+	require.NoError(t, err)
+	require.Equal(t, int64(activityPruneBatchSize+250), removed)
+
+	var remaining []models.ActivityLog
+	require.NoError(t, db.Order("id ASC").Find(&remaining).Error)
+	require.Len(t, remaining, 4)
+	audit := remaining[3]
+	require.Equal(t, "activity.pruned", audit.Action)
+	require.Equal(t, fmt.Sprint(activityPruneBatchSize+250), fmt.Sprint(audit.Metadata["removed"]))
+	require.Equal(t, cutoff.Format(time.RFC3339), audit.Metadata["older_than"])
+
+	removed, err = svc.Prune(context.Background(), cutoff, ActivityActor{ID: 1, Role: "admin"})
+	require.NoError(t, err)
+	require.Zero(t, removed)
+}
+
+func TestActivityServiceExportWritesNDJSON(t *testing.T) {
+	db := setupActivityLogDB(t)
+	base := time.Date(2024, time.June, 1, 9, 0, 0, 0, time.UTC)
+	for i, action := range []string{"student.created", "assignment.created", "submission.graded"} {
+		entry := models.ActivityLog{ActorID: 2, ActorRole: "teacher", Action: action, EntityType: "test", CreatedAt: base.Add(time.Duration(i) * time.Hour)}
+		require.NoError(t, db.Create(&entry).Error)
+	}
+
+	svc := NewActivityService(repository.NewActivityLogRepository(db), nil, nil, validator.New(), testLogger())
+	from := base.Add(30 * time.Minute)
+
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+	rows, err := svc.Export(context.Background(), writer, dto.AdminActivityExportQuery{From: &from}, ActivityActor{ID: 2, Role: "teacher"})
+	require.NoError(t, err)
+	require.Equal(t, 2, rows)
+
+	scanner := bufio.NewScanner(&buf)
+	var actions []string
+	for scanner.Scan() {
+		var entry dto.AdminActivityResponse
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		actions = append(actions, entry.Action)
+	}
+	require.Equal(t, []string{"assignment.created", "submission.graded"}, actions)
+
+	var audit models.ActivityLog
+	require.NoError(t, db.Where("action = ?", "export.generated").First(&audit).Error)
+	require.Equal(t, "2", fmt.Sprint(audit.Metadata["rows"]))
+	require.Equal(t, "activities", audit.Metadata["report"])
+}
+
+func TestActivityPrunerUsesRetentionWindow(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	recorder := &pruneRecorder{}
+	pruner := NewActivityPruner(recorder, ActivityRetentionConfig{Retention: 30 * 24 * time.Hour}, testLogger())
+	pruner.(*activityPruner).clock = clock.NewFixed(now)
+
+	removed, err := pruner.PruneExpired(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(7), removed)
+	require.Equal(t, now.AddDate(0, 0, -30), recorder.olderThan)
+	require.Equal(t, "system", recorder.actor.Role)
+
+	disabled := NewActivityPruner(recorder, ActivityRetentionConfig{}, testLogger())
+	removed, err = disabled.PruneExpired(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, removed)
+}
+
+type pruneRecorder struct {
+	ActivityService
+	olderThan time.Time
+	actor     ActivityActor
+}
+
+func (p *pruneRecorder) Prune(ctx context.Context, olderThan time.Time, actor ActivityActor) (int64, error) {
+	p.olderThan = olderThan
+	p.actor = actor
+	return 7, nil
+}
+
+func TestIsStaffRole(t *testing.T) {
+	require.True(t, IsStaffRole("teacher"))
+	require.True(t, IsStaffRole(" Admin "))
+	require.False(t, IsStaffRole("student"))
+	require.False(t, IsStaffRole(""))
+}
@@ -1,17 +1,24 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"sort"
+	"strconv"
 	"time"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -19,37 +26,74 @@ import (
 
 // AdminAnalyticsService aggregates analytics for the admin dashboard.
 type AdminAnalyticsService interface {
-	GetSummary(ctx context.Context) (dto.AdminAnalyticsResponse, error)
+	GetSummary(ctx context.Context, query dto.AdminAnalyticsQuery) (dto.AdminAnalyticsResponse, error)
+	Export(ctx context.Context, actor ActivityActor, format string, query dto.AdminAnalyticsQuery) (AnalyticsExport, error)
+	AssignmentAnalytics(ctx context.Context, assignmentID uint) (dto.AdminAssignmentAnalyticsResponse, error)
 }
 
+// Analytics report formats accepted by Export.
+const (
+	AnalyticsExportCSV  = "csv"
+	AnalyticsExportJSON = "json"
+)
+
+// analyticsEngagementWindow is how far back weekly engagement is reported when
+// the query has no start.
+const analyticsEngagementWindow = 56 * 24 * time.Hour
+
+const analyticsCacheKey = "analytics:summary"
+
+var (
+	// ErrAnalyticsExportFormat indicates the requested report format is not supported.
+	ErrAnalyticsExportFormat = errors.New("unsupported analytics export format")
+	// ErrAnalyticsInvalidRange indicates the requested range ends before it starts.
+	ErrAnalyticsInvalidRange = errors.New("analytics range start must not be after its end")
+)
+
+// AnalyticsExport is a rendered analytics report ready to be downloaded.
+type AnalyticsExport struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// gradeBuckets lists the grade distribution buckets from highest to lowest.
+var gradeBuckets = []string{"90-100", "75-89", "60-74", "0-59"}
+
 type adminAnalyticsService struct {
 	repo     repository.AdminAnalyticsRepository
-	cache    *redis.Client
-	cacheTTL time.Duration
+	cache    cache.Store
+	cacheTTL TTLSource
+	activity ActivityRecorder
 	logger   zerolog.Logger
-	now      func() time.Time
+	clock    clock.Clock
 }
 
 // NewAdminAnalyticsService constructs the analytics service.
-func NewAdminAnalyticsService(repo repository.AdminAnalyticsRepository, cache *redis.Client, ttl time.Duration, logger zerolog.Logger) AdminAnalyticsService {
+func NewAdminAnalyticsService(repo repository.AdminAnalyticsRepository, cache cache.Store, ttl TTLSource, activity ActivityRecorder, logger zerolog.Logger) AdminAnalyticsService {
 	return &adminAnalyticsService{
 		repo:     repo,
 		cache:    cache,
 		cacheTTL: ttl,
+		activity: activity,
 		logger:   logger.With().Str("component", "admin_analytics_service").Logger(),
-		now:      time.Now,
+		clock:    clock.Real(),
 	}
 }
 
-func (s *adminAnalyticsService) GetSummary(ctx context.Context) (dto.AdminAnalyticsResponse, error) {
-	const cacheKey = "analytics:summary"
+func (s *adminAnalyticsService) GetSummary(ctx context.Context, query dto.AdminAnalyticsQuery) (dto.AdminAnalyticsResponse, error) {
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		return dto.AdminAnalyticsResponse{}, ErrAnalyticsInvalidRange
+	}
+
+	cacheKey := analyticsSummaryCacheKey(query)
 	tracer := otel.Tracer("github.com/noah-isme/gema-go-api/internal/service/admin_analytics")
 	ctx, span := tracer.Start(ctx, "analytics.aggregate")
 	span.SetAttributes(attribute.String("analytics.cache_key", cacheKey))
 	defer span.End()
 
 	if s.cache != nil {
-		cached, err := s.cache.Get(ctx, cacheKey).Result()
+		cached, err := s.cache.Get(ctx, cacheKey)
 		if err == nil {
 			var response dto.AdminAnalyticsResponse
 			if unmarshalErr := json.Unmarshal([]byte(cached), &response); unmarshalErr == nil {
@@ -57,7 +101,7 @@ func (s *adminAnalyticsService) GetSummary(ctx context.Context) (dto.AdminAnalyt
 				span.SetAttributes(attribute.Bool("analytics.cache_hit", true))
 				return response, nil
 			}
-		} else if err != redis.Nil {
+		} else if !errors.Is(err, cache.ErrMiss) {
 			s.logger.Warn().Err(err).Msg("failed to read analytics cache")
 			span.RecordError(err)
 		}
@@ -70,73 +114,85 @@ func (s *adminAnalyticsService) GetSummary(ctx context.Context) (dto.AdminAnalyt
 		return dto.AdminAnalyticsResponse{}, err
 	}
 
-	submissions, err := s.repo.ListSubmissionsWithAssignments(ctx)
+	submissions, err := s.repo.ListSubmissionsWithAssignments(ctx, repository.AnalyticsFilter{From: query.From, To: query.To})
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "list_submissions_failed")
 		return dto.AdminAnalyticsResponse{}, err
 	}
 
-	summary := s.buildSummary(activeCount, submissions)
+	summary := s.buildSummary(activeCount, submissions, query)
 	span.SetAttributes(
 		attribute.Int64("analytics.active_students", activeCount),
 		attribute.Int("analytics.submission_count", len(submissions)),
 	)
 
-	if s.cache != nil {
-		payload, err := json.Marshal(summary)
-		if err == nil {
-			if err := s.cache.Set(ctx, cacheKey, payload, s.cacheTTL).Err(); err != nil {
-				s.logger.Warn().Err(err).Msg("failed to store analytics cache")
-				span.RecordError(err)
-			}
-		}
+	if err := cache.Write(ctx, s.cache, "analytics", cacheKey, summary, s.cacheTTL()); err != nil {
+		s.logger.Warn().Err(err).Msg("failed to store analytics cache")
+		span.RecordError(err)
 	}
 
 	return summary, nil
 }
 
-func (s *adminAnalyticsService) buildSummary(activeCount int64, submissions []models.Submission) dto.AdminAnalyticsResponse {
-	now := s.now()
+// analyticsSummaryCacheKey keeps the all-time summary under its original key
+// and gives every range its own entry.
+func analyticsSummaryCacheKey(query dto.AdminAnalyticsQuery) string {
+	if query.From == nil && query.To == nil {
+		return analyticsCacheKey
+	}
+	bound := func(t *time.Time) string {
+		if t == nil {
+			return "*"
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return analyticsCacheKey + ":" + bound(query.From) + ":" + bound(query.To)
+}
+
+// engagementSince returns the start of the weekly engagement series: the
+// query's start when given, otherwise analyticsEngagementWindow before its end
+// (or now).
+func engagementSince(query dto.AdminAnalyticsQuery, now time.Time) time.Time {
+	if query.From != nil {
+		return *query.From
+	}
+	end := now
+	if query.To != nil {
+		end = *query.To
+	}
+	return end.Add(-analyticsEngagementWindow)
+}
+
+// buildSummary aggregates submissions already limited to the query range.
+// Weekly buckets start on Monday, so the first and last week may cover only
+// the part of the week inside the range.
+func (s *adminAnalyticsService) buildSummary(activeCount int64, submissions []models.Submission, query dto.AdminAnalyticsQuery) dto.AdminAnalyticsResponse {
+	now := s.clock.Now()
 	onTime := int64(0)
 	late := int64(0)
-	distribution := dto.GradeDistributionResponse{
-		"90-100": 0,
-		"75-89":  0,
-		"60-74":  0,
-		"0-59":   0,
-	}
+	distribution := emptyGradeDistribution()
 
 	weekly := map[time.Time]int64{}
-	cutoff := now.AddDate(0, 0, -56)
+	cutoff := engagementSince(query, now)
+	var percentTotal float64
+	var percentCount int
 
 	for _, submission := range submissions {
-		dueDate := submission.Assignment.DueDate
-		if !dueDate.IsZero() && !submission.CreatedAt.After(dueDate) {
-			onTime++
-		} else if !dueDate.IsZero() {
+		switch submissionTiming(submission) {
+		case submissionLate:
 			late++
+		case submissionOnTime:
+			onTime++
 		}
 
-		maxScore := submission.Assignment.MaxScore
-		if maxScore <= 0 {
-			maxScore = 100
-		}
-		if submission.Grade != nil {
-			percent := (*submission.Grade / maxScore) * 100
-			switch {
-			case percent >= 90:
-				distribution["90-100"]++
-			case percent >= 75:
-				distribution["75-89"]++
-			case percent >= 60:
-				distribution["60-74"]++
-			default:
-				distribution["0-59"]++
-			}
+		if percent := submission.Assignment.ScorePercent(submission.Grade); percent != nil {
+			percentTotal += *percent
+			percentCount++
+			distribution[gradeBucket(*percent)]++
 		}
 
-		if submission.CreatedAt.After(cutoff) {
+		if !submission.CreatedAt.Before(cutoff) {
 			week := startOfWeek(submission.CreatedAt)
 			weekly[week]++
 		}
@@ -153,15 +209,70 @@ func (s *adminAnalyticsService) buildSummary(activeCount int64, submissions []mo
 		engagement = append(engagement, dto.WeeklyEngagementPoint{WeekStart: week, Submissions: weekly[week]})
 	}
 
-	return dto.AdminAnalyticsResponse{
+	response := dto.AdminAnalyticsResponse{
 		ActiveStudents:    activeCount,
 		OnTimeSubmissions: onTime,
 		LateSubmissions:   late,
 		GradeDistribution: distribution,
 		WeeklyEngagement:  engagement,
+		From:              query.From,
+		To:                query.To,
 		GeneratedAt:       now,
 		CacheHit:          false,
 	}
+	if percentCount > 0 {
+		average := models.RoundPercent(percentTotal / float64(percentCount))
+		response.AveragePercent = &average
+	}
+
+	return response
+}
+
+type submissionTimeliness int
+
+const (
+	submissionUndated submissionTimeliness = iota
+	submissionOnTime
+	submissionLate
+)
+
+// submissionTiming classifies a submission against its assignment's due date.
+// Submissions flagged late stay late; assignments without a due date are
+// neither on time nor late.
+func submissionTiming(submission models.Submission) submissionTimeliness {
+	dueDate := submission.Assignment.DueDate
+	switch {
+	case submission.Late:
+		return submissionLate
+	case dueDate.IsZero():
+		return submissionUndated
+	case submission.CreatedAt.After(dueDate):
+		return submissionLate
+	default:
+		return submissionOnTime
+	}
+}
+
+func emptyGradeDistribution() dto.GradeDistributionResponse {
+	distribution := make(dto.GradeDistributionResponse, len(gradeBuckets))
+	for _, bucket := range gradeBuckets {
+		distribution[bucket] = 0
+	}
+	return distribution
+}
+
+// gradeBucket returns the gradeBuckets entry a score percentage falls into.
+func gradeBucket(percent float64) string {
+	switch {
+	case percent >= 90:
+		return "90-100"
+	case percent >= 75:
+		return "75-89"
+	case percent >= 60:
+		return "60-74"
+	default:
+		return "0-59"
+	}
 }
 
 func startOfWeek(t time.Time) time.Time {
@@ -173,3 +284,229 @@ func startOfWeek(t time.Time) time.Time {
 	start := utc.AddDate(0, 0, -(weekday - 1))
 	return time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
 }
+
+func analyticsAssignmentCacheKey(assignmentID uint) string {
+	return fmt.Sprintf("%s:assignment:%d", analyticsCacheKey, assignmentID)
+}
+
+// AssignmentAnalytics reports submission timing, grades and completion for a
+// single assignment.
+func (s *adminAnalyticsService) AssignmentAnalytics(ctx context.Context, assignmentID uint) (dto.AdminAssignmentAnalyticsResponse, error) {
+	cacheKey := analyticsAssignmentCacheKey(assignmentID)
+	tracer := otel.Tracer("github.com/noah-isme/gema-go-api/internal/service/admin_analytics")
+	ctx, span := tracer.Start(ctx, "analytics.assignment")
+	span.SetAttributes(
+		attribute.String("analytics.cache_key", cacheKey),
+		attribute.Int64("analytics.assignment_id", int64(assignmentID)),
+	)
+	defer span.End()
+
+	if s.cache != nil {
+		cached, err := s.cache.Get(ctx, cacheKey)
+		if err == nil {
+			var response dto.AdminAssignmentAnalyticsResponse
+			if unmarshalErr := json.Unmarshal([]byte(cached), &response); unmarshalErr == nil {
+				response.CacheHit = true
+				span.SetAttributes(attribute.Bool("analytics.cache_hit", true))
+				return response, nil
+			}
+		} else if !errors.Is(err, cache.ErrMiss) {
+			s.logger.Warn().Err(err).Msg("failed to read analytics cache")
+			span.RecordError(err)
+		}
+	}
+
+	assignment, err := s.repo.GetAssignment(ctx, assignmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.AdminAssignmentAnalyticsResponse{}, ErrAssignmentNotFound
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "get_assignment_failed")
+		return dto.AdminAssignmentAnalyticsResponse{}, err
+	}
+
+	activeCount, err := s.repo.CountActiveStudents(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "count_active_students_failed")
+		return dto.AdminAssignmentAnalyticsResponse{}, err
+	}
+
+	submissions, err := s.repo.ListAssignmentSubmissions(ctx, assignmentID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "list_submissions_failed")
+		return dto.AdminAssignmentAnalyticsResponse{}, err
+	}
+
+	response := s.buildAssignmentAnalytics(assignment, activeCount, submissions)
+	span.SetAttributes(attribute.Int("analytics.submission_count", len(submissions)))
+
+	if err := cache.Write(ctx, s.cache, "analytics", cacheKey, response, s.cacheTTL()); err != nil {
+		s.logger.Warn().Err(err).Msg("failed to store analytics cache")
+		span.RecordError(err)
+	}
+
+	return response, nil
+}
+
+// buildAssignmentAnalytics aggregates the latest submission version of each
+// student. The completion rate is the percentage of active students who
+// submitted, capped at 100 since inactive students may have submitted too.
+func (s *adminAnalyticsService) buildAssignmentAnalytics(assignment models.Assignment, activeCount int64, submissions []models.Submission) dto.AdminAssignmentAnalyticsResponse {
+	latest := make(map[uint]models.Submission, len(submissions))
+	for _, submission := range submissions {
+		current, ok := latest[submission.StudentID]
+		if !ok || submission.Version > current.Version ||
+			(submission.Version == current.Version && submission.CreatedAt.After(current.CreatedAt)) {
+			latest[submission.StudentID] = submission
+		}
+	}
+
+	response := dto.AdminAssignmentAnalyticsResponse{
+		AssignmentID:      assignment.ID,
+		Title:             assignment.Title,
+		DueDate:           assignment.DueDate,
+		Submissions:       int64(len(submissions)),
+		StudentsSubmitted: int64(len(latest)),
+		ActiveStudents:    activeCount,
+		GradeDistribution: emptyGradeDistribution(),
+		GeneratedAt:       s.clock.Now(),
+	}
+
+	percents := make([]float64, 0, len(latest))
+	for _, submission := range latest {
+		submission.Assignment = assignment
+		switch submissionTiming(submission) {
+		case submissionLate:
+			response.LateSubmissions++
+		case submissionOnTime:
+			response.OnTimeSubmissions++
+		}
+		if percent := assignment.ScorePercent(submission.Grade); percent != nil {
+			percents = append(percents, *percent)
+			response.GradeDistribution[gradeBucket(*percent)]++
+		}
+	}
+
+	if activeCount > 0 {
+		rate := float64(len(latest)) / float64(activeCount) * 100
+		if rate > 100 {
+			rate = 100
+		}
+		response.CompletionRate = models.RoundPercent(rate)
+	}
+	if len(percents) > 0 {
+		sort.Float64s(percents)
+		total := 0.0
+		for _, percent := range percents {
+			total += percent
+		}
+		average := models.RoundPercent(total / float64(len(percents)))
+		median := percents[len(percents)/2]
+		if len(percents)%2 == 0 {
+			median = (percents[len(percents)/2-1] + median) / 2
+		}
+		median = models.RoundPercent(median)
+		response.AveragePercent = &average
+		response.MedianPercent = &median
+	}
+
+	return response
+}
+
+// Export renders the analytics summary for query as a CSV or JSON report and
+// records the download as an export.generated activity.
+func (s *adminAnalyticsService) Export(ctx context.Context, actor ActivityActor, format string, query dto.AdminAnalyticsQuery) (AnalyticsExport, error) {
+	if format == "" {
+		format = AnalyticsExportCSV
+	}
+	if format != AnalyticsExportCSV && format != AnalyticsExportJSON {
+		return AnalyticsExport{}, ErrAnalyticsExportFormat
+	}
+
+	summary, err := s.GetSummary(ctx, query)
+	if err != nil {
+		return AnalyticsExport{}, err
+	}
+	summary.CacheHit = false
+
+	now := s.clock.Now().UTC()
+	report := dto.AdminAnalyticsReport{
+		ExportedAt:      now,
+		From:            query.From,
+		To:              query.To,
+		EngagementSince: engagementSince(query, summary.GeneratedAt).UTC(),
+		Analytics:       summary,
+	}
+
+	rows := analyticsReportRows(report)
+	export := AnalyticsExport{Filename: fmt.Sprintf("analytics-%s.%s", now.Format("20060102T150405Z"), format)}
+	if format == AnalyticsExportJSON {
+		export.ContentType = "application/json"
+		export.Data, err = json.MarshalIndent(report, "", "  ")
+	} else {
+		export.ContentType = "text/csv; charset=utf-8"
+		export.Data, err = analyticsReportCSV(rows)
+	}
+	if err != nil {
+		return AnalyticsExport{}, err
+	}
+
+	filters := map[string]interface{}{}
+	if query.From != nil {
+		filters["from"] = query.From.UTC().Format(time.RFC3339)
+	}
+	if query.To != nil {
+		filters["to"] = query.To.UTC().Format(time.RFC3339)
+	}
+	recordExport(ctx, s.activity, s.logger, actor, "analytics", format, len(rows)-1, filters)
+
+	return export, nil
+}
+
+func analyticsReportCSV(rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// analyticsReportRows flattens the report into section, metric, value rows
+// below a header row.
+func analyticsReportRows(report dto.AdminAnalyticsReport) [][]string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	summary := report.Analytics
+
+	rows := [][]string{
+		{"section", "metric", "value"},
+		{"report", "exported_at", formatTime(&report.ExportedAt)},
+		{"report", "generated_at", formatTime(&summary.GeneratedAt)},
+		{"report", "from", formatTime(report.From)},
+		{"report", "to", formatTime(report.To)},
+		{"report", "weekly_engagement_since", formatTime(&report.EngagementSince)},
+		{"summary", "active_students", strconv.FormatInt(summary.ActiveStudents, 10)},
+		{"summary", "on_time_submissions", strconv.FormatInt(summary.OnTimeSubmissions, 10)},
+		{"summary", "late_submissions", strconv.FormatInt(summary.LateSubmissions, 10)},
+	}
+	average := ""
+	if summary.AveragePercent != nil {
+		average = strconv.FormatFloat(*summary.AveragePercent, 'f', -1, 64)
+	}
+	rows = append(rows, []string{"summary", "average_grade_percent", average})
+	for _, bucket := range gradeBuckets {
+		rows = append(rows, []string{"grade_distribution", bucket, strconv.FormatInt(summary.GradeDistribution[bucket], 10)})
+	}
+	for _, point := range summary.WeeklyEngagement {
+		rows = append(rows, []string{"weekly_engagement", point.WeekStart.Format("2006-01-02"), strconv.FormatInt(point.Submissions, 10)})
+	}
+	return rows
+}
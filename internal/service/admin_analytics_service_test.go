@@ -2,27 +2,46 @@ package service
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
 	miniredis "github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
 )
 
 type fakeAnalyticsRepo struct {
 	activeCount int64
 	submissions []models.Submission
+	assignments []models.Assignment
 }
 
 func (f *fakeAnalyticsRepo) CountActiveStudents(ctx context.Context) (int64, error) {
 	return f.activeCount, nil
 }
 
-func (f *fakeAnalyticsRepo) ListSubmissionsWithAssignments(ctx context.Context) ([]models.Submission, error) {
-	return append([]models.Submission(nil), f.submissions...), nil
+func (f *fakeAnalyticsRepo) ListSubmissionsWithAssignments(ctx context.Context, filter repository.AnalyticsFilter) ([]models.Submission, error) {
+	result := make([]models.Submission, 0, len(f.submissions))
+	for _, submission := range f.submissions {
+		if filter.From != nil && submission.CreatedAt.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && submission.CreatedAt.After(*filter.To) {
+			continue
+		}
+		result = append(result, submission)
+	}
+	return result, nil
 }
 
 func (f *fakeAnalyticsRepo) ListSubmissionsSince(ctx context.Context, since time.Time) ([]models.Submission, error) {
@@ -35,6 +54,25 @@ func (f *fakeAnalyticsRepo) ListSubmissionsSince(ctx context.Context, since time
 	return result, nil
 }
 
+func (f *fakeAnalyticsRepo) GetAssignment(ctx context.Context, id uint) (models.Assignment, error) {
+	for _, assignment := range f.assignments {
+		if assignment.ID == id {
+			return assignment, nil
+		}
+	}
+	return models.Assignment{}, gorm.ErrRecordNotFound
+}
+
+func (f *fakeAnalyticsRepo) ListAssignmentSubmissions(ctx context.Context, assignmentID uint) ([]models.Submission, error) {
+	result := make([]models.Submission, 0)
+	for _, submission := range f.submissions {
+		if submission.AssignmentID == assignmentID {
+			result = append(result, submission)
+		}
+	}
+	return result, nil
+}
+
 func TestAdminAnalyticsServiceCaching(t *testing.T) {
 	server, err := miniredis.Run()
 	require.NoError(t, err)
@@ -74,9 +112,9 @@ func TestAdminAnalyticsServiceCaching(t *testing.T) {
 		},
 	}
 
-	svc := NewAdminAnalyticsService(repo, client, time.Minute, testLogger())
+	svc := NewAdminAnalyticsService(repo, cache.NewRedisStore(client), FixedTTL(time.Minute), nil, testLogger())
 
-	summary, err := svc.GetSummary(context.Background())
+	summary, err := svc.GetSummary(context.Background(), dto.AdminAnalyticsQuery{})
 	require.NoError(t, err)
 	require.False(t, summary.CacheHit)
 	require.Equal(t, int64(5), summary.ActiveStudents)
@@ -84,8 +122,212 @@ func TestAdminAnalyticsServiceCaching(t *testing.T) {
 	require.Equal(t, int64(1), summary.LateSubmissions)
 
 	repo.activeCount = 10
-	summaryCached, err := svc.GetSummary(context.Background())
+	summaryCached, err := svc.GetSummary(context.Background(), dto.AdminAnalyticsQuery{})
 	require.NoError(t, err)
 	require.True(t, summaryCached.CacheHit)
 	require.Equal(t, summary.ActiveStudents, summaryCached.ActiveStudents)
 }
+
+func TestAdminAnalyticsSummaryCountsAcceptedLateWork(t *testing.T) {
+	now := time.Date(2024, time.April, 10, 12, 0, 0, 0, time.UTC)
+	svc := &adminAnalyticsService{clock: clock.NewFixed(now)}
+
+	// The due date was extended after this submission arrived late, so only the flag marks it.
+	submissions := []models.Submission{
+		{ID: 1, CreatedAt: now.Add(-48 * time.Hour), Late: true, Assignment: models.Assignment{DueDate: now}},
+		{ID: 2, CreatedAt: now.Add(-48 * time.Hour), Assignment: models.Assignment{DueDate: now}},
+	}
+
+	summary := svc.buildSummary(3, submissions, dto.AdminAnalyticsQuery{})
+	require.Equal(t, int64(1), summary.OnTimeSubmissions)
+	require.Equal(t, int64(1), summary.LateSubmissions)
+}
+
+func TestAdminAnalyticsSummaryNormalizesGrades(t *testing.T) {
+	now := time.Date(2024, time.April, 10, 12, 0, 0, 0, time.UTC)
+	svc := &adminAnalyticsService{clock: clock.NewFixed(now)}
+
+	submissions := []models.Submission{
+		{ID: 1, CreatedAt: now, Grade: floatPointer(42), Assignment: models.Assignment{DueDate: now, MaxScore: 50}},
+		{ID: 2, CreatedAt: now, Grade: floatPointer(19), Assignment: models.Assignment{DueDate: now, MaxScore: 20}},
+		// Without a max score the grade cannot be normalized and is left out.
+		{ID: 3, CreatedAt: now, Grade: floatPointer(7), Assignment: models.Assignment{DueDate: now}},
+	}
+
+	summary := svc.buildSummary(3, submissions, dto.AdminAnalyticsQuery{})
+	require.Equal(t, int64(1), summary.GradeDistribution["90-100"])
+	require.Equal(t, int64(1), summary.GradeDistribution["75-89"])
+	require.Zero(t, summary.GradeDistribution["0-59"])
+	require.NotNil(t, summary.AveragePercent)
+	require.Equal(t, 89.5, *summary.AveragePercent)
+
+	require.Equal(t, 84.0, *submissions[0].Assignment.ScorePercent(submissions[0].Grade))
+	require.Nil(t, submissions[2].Assignment.ScorePercent(submissions[2].Grade))
+
+	summary = svc.buildSummary(0, submissions[2:], dto.AdminAnalyticsQuery{})
+	require.Nil(t, summary.AveragePercent)
+}
+
+func TestAdminAnalyticsExportCSV(t *testing.T) {
+	now := time.Date(2024, time.April, 10, 12, 0, 0, 0, time.UTC)
+	due := now.Add(-72 * time.Hour)
+	repo := &fakeAnalyticsRepo{
+		activeCount: 4,
+		submissions: []models.Submission{
+			{ID: 1, CreatedAt: due.Add(-time.Hour), Grade: floatPointer(95), Assignment: models.Assignment{DueDate: due, MaxScore: 100}},
+			{ID: 2, CreatedAt: due.Add(-time.Hour), Grade: floatPointer(40), Assignment: models.Assignment{DueDate: due, MaxScore: 50}},
+			{ID: 3, CreatedAt: due.Add(time.Hour), Grade: floatPointer(10), Assignment: models.Assignment{DueDate: due, MaxScore: 20}},
+		},
+	}
+	activity := &stubActivityRecorder{}
+	svc := NewAdminAnalyticsService(repo, nil, FixedTTL(time.Minute), activity, testLogger())
+	svc.(*adminAnalyticsService).clock = clock.NewFixed(now)
+
+	export, err := svc.Export(context.Background(), ActivityActor{ID: 3, Role: "teacher"}, AnalyticsExportCSV, dto.AdminAnalyticsQuery{})
+	require.NoError(t, err)
+	require.Equal(t, "analytics-20240410T120000Z.csv", export.Filename)
+	require.Equal(t, "text/csv; charset=utf-8", export.ContentType)
+
+	rows, err := csv.NewReader(strings.NewReader(string(export.Data))).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"section", "metric", "value"},
+		{"report", "exported_at", "2024-04-10T12:00:00Z"},
+		{"report", "generated_at", "2024-04-10T12:00:00Z"},
+		{"report", "from", ""},
+		{"report", "to", ""},
+		{"report", "weekly_engagement_since", "2024-02-14T12:00:00Z"},
+		{"summary", "active_students", "4"},
+		{"summary", "on_time_submissions", "2"},
+		{"summary", "late_submissions", "1"},
+		{"summary", "average_grade_percent", "75"},
+		{"grade_distribution", "90-100", "1"},
+		{"grade_distribution", "75-89", "1"},
+		{"grade_distribution", "60-74", "0"},
+		{"grade_distribution", "0-59", "1"},
+		{"weekly_engagement", "2024-04-01", "3"},
+	}, rows)
+
+	require.Len(t, activity.entries, 1)
+	require.Equal(t, "export.generated", activity.entries[0].Action)
+	require.Equal(t, uint(3), activity.entries[0].ActorID)
+	require.Equal(t, "analytics", activity.entries[0].Metadata["report"])
+	require.Equal(t, len(rows)-1, activity.entries[0].Metadata["rows"])
+}
+
+func TestAdminAnalyticsExportJSON(t *testing.T) {
+	now := time.Date(2024, time.April, 10, 12, 0, 0, 0, time.UTC)
+	svc := NewAdminAnalyticsService(&fakeAnalyticsRepo{activeCount: 2}, nil, FixedTTL(time.Minute), nil, testLogger())
+	svc.(*adminAnalyticsService).clock = clock.NewFixed(now)
+
+	export, err := svc.Export(context.Background(), ActivityActor{}, AnalyticsExportJSON, dto.AdminAnalyticsQuery{})
+	require.NoError(t, err)
+	require.Equal(t, "analytics-20240410T120000Z.json", export.Filename)
+
+	var report dto.AdminAnalyticsReport
+	require.NoError(t, json.Unmarshal(export.Data, &report))
+	require.Equal(t, now, report.ExportedAt)
+	require.Nil(t, report.From)
+	require.EqualValues(t, 2, report.Analytics.ActiveStudents)
+
+	_, err = svc.Export(context.Background(), ActivityActor{}, "xlsx", dto.AdminAnalyticsQuery{})
+	require.ErrorIs(t, err, ErrAnalyticsExportFormat)
+}
+
+func TestAdminAnalyticsSummaryDateRange(t *testing.T) {
+	now := time.Date(2024, time.June, 3, 12, 0, 0, 0, time.UTC)
+	at := func(month time.Month, day int) time.Time { return time.Date(2024, month, day, 9, 0, 0, 0, time.UTC) }
+	repo := &fakeAnalyticsRepo{
+		activeCount: 3,
+		submissions: []models.Submission{
+			{ID: 1, CreatedAt: at(time.January, 10), Assignment: models.Assignment{DueDate: at(time.January, 12)}},
+			{ID: 2, CreatedAt: at(time.January, 17), Assignment: models.Assignment{DueDate: at(time.January, 12)}},
+			{ID: 3, CreatedAt: at(time.February, 20), Assignment: models.Assignment{DueDate: at(time.March, 1)}},
+			{ID: 4, CreatedAt: at(time.May, 30), Assignment: models.Assignment{DueDate: at(time.June, 1)}},
+		},
+	}
+	store := cache.NewMemoryStore(16)
+	svc := NewAdminAnalyticsService(repo, store, FixedTTL(time.Minute), nil, testLogger())
+	svc.(*adminAnalyticsService).clock = clock.NewFixed(now)
+	ctx := context.Background()
+
+	from, to := at(time.January, 8), at(time.January, 31)
+	january, err := svc.GetSummary(ctx, dto.AdminAnalyticsQuery{From: &from, To: &to})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), january.OnTimeSubmissions)
+	require.Equal(t, int64(1), january.LateSubmissions)
+	require.Equal(t, &from, january.From)
+	// Engagement follows the range, not the trailing window from now.
+	require.Equal(t, []dto.WeeklyEngagementPoint{
+		{WeekStart: time.Date(2024, time.January, 8, 0, 0, 0, 0, time.UTC), Submissions: 1},
+		{WeekStart: time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC), Submissions: 1},
+	}, january.WeeklyEngagement)
+
+	allTime, err := svc.GetSummary(ctx, dto.AdminAnalyticsQuery{})
+	require.NoError(t, err)
+	require.False(t, allTime.CacheHit)
+	require.Equal(t, int64(3), allTime.OnTimeSubmissions)
+	require.Nil(t, allTime.From)
+	require.Len(t, allTime.WeeklyEngagement, 1)
+
+	cached, err := svc.GetSummary(ctx, dto.AdminAnalyticsQuery{From: &from, To: &to})
+	require.NoError(t, err)
+	require.True(t, cached.CacheHit)
+	require.Equal(t, int64(1), cached.LateSubmissions)
+
+	_, err = svc.GetSummary(ctx, dto.AdminAnalyticsQuery{From: &to, To: &from})
+	require.ErrorIs(t, err, ErrAnalyticsInvalidRange)
+}
+
+func TestAnalyticsSummaryCacheKey(t *testing.T) {
+	from := time.Date(2024, time.January, 8, 7, 0, 0, 0, time.FixedZone("WIB", 7*3600))
+	require.Equal(t, "analytics:summary", analyticsSummaryCacheKey(dto.AdminAnalyticsQuery{}))
+	require.Equal(t, "analytics:summary:2024-01-08T00:00:00Z:*", analyticsSummaryCacheKey(dto.AdminAnalyticsQuery{From: &from}))
+	require.Equal(t, "analytics:summary:*:2024-01-08T00:00:00Z", analyticsSummaryCacheKey(dto.AdminAnalyticsQuery{To: &from}))
+}
+
+func TestAdminAnalyticsAssignmentBreakdown(t *testing.T) {
+	due := time.Date(2024, time.April, 10, 12, 0, 0, 0, time.UTC)
+	assignment := models.Assignment{ID: 4, Title: "Sorting", MaxScore: 50, DueDate: due}
+	repo := &fakeAnalyticsRepo{
+		activeCount: 8,
+		assignments: []models.Assignment{assignment},
+		submissions: []models.Submission{
+			// Student 1 resubmitted late; only the latest version counts.
+			{ID: 1, AssignmentID: 4, StudentID: 1, Version: 1, CreatedAt: due.Add(-time.Hour), Grade: floatPointer(20)},
+			{ID: 2, AssignmentID: 4, StudentID: 1, Version: 2, CreatedAt: due.Add(time.Hour), Late: true, Grade: floatPointer(47)},
+			{ID: 3, AssignmentID: 4, StudentID: 2, Version: 1, CreatedAt: due.Add(-time.Hour), Grade: floatPointer(40)},
+			{ID: 4, AssignmentID: 4, StudentID: 3, Version: 1, CreatedAt: due.Add(-time.Hour), Grade: floatPointer(25)},
+			{ID: 5, AssignmentID: 4, StudentID: 4, Version: 1, CreatedAt: due.Add(-time.Hour)},
+			{ID: 6, AssignmentID: 9, StudentID: 5, Version: 1, CreatedAt: due, Grade: floatPointer(50)},
+		},
+	}
+
+	store := cache.NewMemoryStore(16)
+	svc := NewAdminAnalyticsService(repo, store, FixedTTL(time.Minute), nil, testLogger())
+	svc.(*adminAnalyticsService).clock = clock.NewFixed(due)
+
+	analytics, err := svc.AssignmentAnalytics(context.Background(), 4)
+	require.NoError(t, err)
+	require.False(t, analytics.CacheHit)
+	require.Equal(t, "Sorting", analytics.Title)
+	require.Equal(t, int64(5), analytics.Submissions)
+	require.Equal(t, int64(4), analytics.StudentsSubmitted)
+	require.Equal(t, 50.0, analytics.CompletionRate)
+	require.Equal(t, int64(3), analytics.OnTimeSubmissions)
+	require.Equal(t, int64(1), analytics.LateSubmissions)
+	require.Equal(t, dto.GradeDistributionResponse{"90-100": 1, "75-89": 1, "60-74": 0, "0-59": 1}, analytics.GradeDistribution)
+	require.NotNil(t, analytics.AveragePercent)
+	require.InDelta(t, 74.67, *analytics.AveragePercent, 0.001)
+	require.NotNil(t, analytics.MedianPercent)
+	require.Equal(t, 80.0, *analytics.MedianPercent)
+
+	repo.activeCount = 2
+	cached, err := svc.AssignmentAnalytics(context.Background(), 4)
+	require.NoError(t, err)
+	require.True(t, cached.CacheHit)
+	require.Equal(t, 50.0, cached.CompletionRate)
+
+	_, err = svc.AssignmentAnalytics(context.Background(), 99)
+	require.ErrorIs(t, err, ErrAssignmentNotFound)
+}
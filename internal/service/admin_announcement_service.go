@@ -3,14 +3,17 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/redis/go-redis/v9"
+	"github.com/microcosm-cc/bluemonday"
 	"github.com/rs/zerolog"
 	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -20,35 +23,51 @@ import (
 type AdminAnnouncementService interface {
 	List(ctx context.Context, req dto.AdminAnnouncementListRequest) (dto.AdminAnnouncementListResponse, error)
 	Create(ctx context.Context, payload dto.AdminAnnouncementRequest, actor ActivityActor) (dto.AdminAnnouncementResponse, error)
+	Update(ctx context.Context, id uint, payload dto.AdminAnnouncementUpdateRequest, actor ActivityActor) (dto.AdminAnnouncementResponse, error)
 }
 
 type adminAnnouncementService struct {
 	repo      repository.AnnouncementRepository
 	validator *validator.Validate
-	cache     *redis.Client
+	cache     cache.Store
 	activity  ActivityRecorder
 	logger    zerolog.Logger
+	policy    *bluemonday.Policy
+	clock     clock.Clock
 }
 
 // ErrAdminAnnouncementNotFound indicates announcement missing.
 var ErrAdminAnnouncementNotFound = errors.New("announcement not found")
 
+// ErrAnnouncementInvalidSchedule indicates unparseable dates or an end before the start.
+var ErrAnnouncementInvalidSchedule = errors.New("invalid announcement schedule")
+
 // NewAdminAnnouncementService constructs the service.
-func NewAdminAnnouncementService(repo repository.AnnouncementRepository, cache *redis.Client, validator *validator.Validate, activity ActivityRecorder, logger zerolog.Logger) AdminAnnouncementService {
+func NewAdminAnnouncementService(repo repository.AnnouncementRepository, cache cache.Store, validator *validator.Validate, activity ActivityRecorder, logger zerolog.Logger) AdminAnnouncementService {
 	return &adminAnnouncementService{
 		repo:      repo,
 		validator: validator,
 		cache:     cache,
 		activity:  activity,
 		logger:    logger.With().Str("component", "admin_announcement_service").Logger(),
+		policy:    announcementPolicy(),
+		clock:     clock.Real(),
 	}
 }
 
 func (s *adminAnnouncementService) List(ctx context.Context, req dto.AdminAnnouncementListRequest) (dto.AdminAnnouncementListResponse, error) {
+	req.Status = strings.ToLower(strings.TrimSpace(req.Status))
+	if err := s.validator.Struct(req); err != nil {
+		return dto.AdminAnnouncementListResponse{}, err
+	}
+
+	now := s.clock.Now()
 	filter := repository.AdminAnnouncementFilter{
 		Page:     normalizePage(req.Page),
 		PageSize: clampPageSize(req.PageSize),
 		Search:   strings.TrimSpace(req.Search),
+		Status:   req.Status,
+		Now:      now,
 	}
 
 	items, total, err := s.repo.ListAll(ctx, filter)
@@ -58,7 +77,7 @@ func (s *adminAnnouncementService) List(ctx context.Context, req dto.AdminAnnoun
 
 	responses := make([]dto.AdminAnnouncementResponse, 0, len(items))
 	for _, item := range items {
-		responses = append(responses, toAdminAnnouncementResponse(item))
+		responses = append(responses, toAdminAnnouncementResponse(item, now))
 	}
 
 	pagination := dto.PaginationMeta{
@@ -76,39 +95,40 @@ func (s *adminAnnouncementService) Create(ctx context.Context, payload dto.Admin
 		return dto.AdminAnnouncementResponse{}, err
 	}
 
-	startsAt, err := time.Parse(time.RFC3339, payload.StartsAt)
+	startsAt, err := parseAnnouncementTime(payload.StartsAt)
 	if err != nil {
 		return dto.AdminAnnouncementResponse{}, err
 	}
 
 	var endsAt *time.Time
 	if strings.TrimSpace(payload.EndsAt) != "" {
-		parsed, parseErr := time.Parse(time.RFC3339, payload.EndsAt)
+		parsed, parseErr := parseAnnouncementTime(payload.EndsAt)
 		if parseErr != nil {
 			return dto.AdminAnnouncementResponse{}, parseErr
 		}
 		endsAt = &parsed
 	}
+	if endsAt != nil && endsAt.Before(startsAt) {
+		return dto.AdminAnnouncementResponse{}, fmt.Errorf("%w: ends_at is before starts_at", ErrAnnouncementInvalidSchedule)
+	}
 
 	model := models.Announcement{
 		Slug:     generateContentSlug(payload.Title),
 		Title:    strings.TrimSpace(payload.Title),
-		Body:     strings.TrimSpace(payload.Body),
+		Body:     s.sanitizeBody(payload.Body),
 		StartsAt: startsAt,
 		EndsAt:   endsAt,
 		IsPinned: payload.IsPinned,
+		Audience: payload.Audience,
 	}
 
 	if err := s.repo.Create(ctx, &model); err != nil {
 		return dto.AdminAnnouncementResponse{}, err
 	}
 
-	if s.cache != nil {
-		if err := s.cache.FlushDB(ctx).Err(); err != nil {
-			s.logger.Warn().Err(err).Msg("failed to flush announcement cache")
-		}
-	}
+	invalidateAnnouncementCache(ctx, s.cache, s.logger)
 
+	now := s.clock.Now()
 	if s.activity != nil {
 		s.activity.Record(ctx, ActivityEntry{
 			ActorID:    actor.ID,
@@ -116,13 +136,109 @@ func (s *adminAnnouncementService) Create(ctx context.Context, payload dto.Admin
 			Action:     "announcement.created",
 			EntityType: "announcement",
 			EntityID:   &model.ID,
+			Metadata: map[string]interface{}{
+				"status":   model.Status(now),
+				"audience": model.Audience,
+			},
+		})
+	}
+
+	return toAdminAnnouncementResponse(model, now), nil
+}
+
+// Update patches an announcement's content or publishing window. Moving the
+// window can schedule, publish or expire the announcement immediately, so the
+// public list cache is always dropped.
+func (s *adminAnnouncementService) Update(ctx context.Context, id uint, payload dto.AdminAnnouncementUpdateRequest, actor ActivityActor) (dto.AdminAnnouncementResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.AdminAnnouncementResponse{}, err
+	}
+
+	model, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.AdminAnnouncementResponse{}, ErrAdminAnnouncementNotFound
+		}
+		return dto.AdminAnnouncementResponse{}, err
+	}
+
+	changedFields := make([]string, 0)
+	if payload.Title != nil {
+		model.Title = strings.TrimSpace(*payload.Title)
+		changedFields = append(changedFields, "title")
+	}
+	if payload.Body != nil {
+		model.Body = s.sanitizeBody(*payload.Body)
+		changedFields = append(changedFields, "body")
+	}
+	if payload.StartsAt != nil {
+		startsAt, err := parseAnnouncementTime(*payload.StartsAt)
+		if err != nil {
+			return dto.AdminAnnouncementResponse{}, err
+		}
+		model.StartsAt = startsAt
+		changedFields = append(changedFields, "starts_at")
+	}
+	if payload.EndsAt != nil {
+		model.EndsAt = nil
+		if strings.TrimSpace(*payload.EndsAt) != "" {
+			endsAt, err := parseAnnouncementTime(*payload.EndsAt)
+			if err != nil {
+				return dto.AdminAnnouncementResponse{}, err
+			}
+			model.EndsAt = &endsAt
+		}
+		changedFields = append(changedFields, "ends_at")
+	}
+	if payload.IsPinned != nil {
+		model.IsPinned = *payload.IsPinned
+		changedFields = append(changedFields, "is_pinned")
+	}
+	if payload.Audience != nil {
+		model.Audience = *payload.Audience
+		changedFields = append(changedFields, "audience")
+	}
+	if model.EndsAt != nil && model.EndsAt.Before(model.StartsAt) {
+		return dto.AdminAnnouncementResponse{}, fmt.Errorf("%w: ends_at is before starts_at", ErrAnnouncementInvalidSchedule)
+	}
+
+	if err := s.repo.Update(ctx, &model); err != nil {
+		return dto.AdminAnnouncementResponse{}, err
+	}
+
+	invalidateAnnouncementCache(ctx, s.cache, s.logger)
+
+	now := s.clock.Now()
+	if s.activity != nil && len(changedFields) > 0 {
+		s.activity.Record(ctx, ActivityEntry{
+			ActorID:    actor.ID,
+			ActorRole:  actor.Role,
+			Action:     "announcement.updated",
+			EntityType: "announcement",
+			EntityID:   &model.ID,
+			Metadata: map[string]interface{}{
+				"fields": changedFields,
+				"status": model.Status(now),
+			},
 		})
 	}
 
-	return toAdminAnnouncementResponse(model), nil
+	return toAdminAnnouncementResponse(model, now), nil
+}
+
+func (s *adminAnnouncementService) sanitizeBody(body string) string {
+	return strings.TrimSpace(s.policy.Sanitize(strings.TrimSpace(body)))
+}
+
+func parseAnnouncementTime(value string) (time.Time, error) {
+	parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q is not an RFC3339 time", ErrAnnouncementInvalidSchedule, value)
+	}
+	return parsed, nil
 }
 
-func toAdminAnnouncementResponse(model models.Announcement) dto.AdminAnnouncementResponse {
+func toAdminAnnouncementResponse(model models.Announcement, now time.Time) dto.AdminAnnouncementResponse {
 	return dto.AdminAnnouncementResponse{
 		ID:        model.ID,
 		Slug:      model.Slug,
@@ -131,6 +247,9 @@ func toAdminAnnouncementResponse(model models.Announcement) dto.AdminAnnouncemen
 		StartsAt:  model.StartsAt,
 		EndsAt:    model.EndsAt,
 		IsPinned:  model.IsPinned,
+		Audience:  models.NormalizeAudience(model.Audience),
+		Status:    model.Status(now),
 		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
 	}
 }
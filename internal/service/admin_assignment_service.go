@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
+	"fmt"
+	"io"
 	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,6 +16,7 @@ import (
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -23,56 +28,50 @@ var ErrAdminAssignmentNotFound = errors.New("admin assignment not found")
 // ErrAdminAssignmentInvalidDueDate indicates the due date is invalid.
 var ErrAdminAssignmentInvalidDueDate = errors.New("assignment due date must be in the future")
 
+// ErrAdminAssignmentImportInvalid indicates the uploaded CSV could not be parsed.
+var ErrAdminAssignmentImportInvalid = errors.New("invalid assignment import file")
+
+// ErrAdminAssignmentImportRejected indicates a strict import was rejected because of invalid rows.
+var ErrAdminAssignmentImportRejected = errors.New("assignment import rejected: one or more rows are invalid")
+
+const maxAssignmentImportRows = 500
+
 // AdminAssignmentService manages assignment CRUD for administrators.
 type AdminAssignmentService interface {
 	Create(ctx context.Context, payload dto.AdminAssignmentCreateRequest, actor ActivityActor) (dto.AdminAssignmentResponse, error)
 	Update(ctx context.Context, id uint, payload dto.AdminAssignmentUpdateRequest, actor ActivityActor) (dto.AdminAssignmentResponse, error)
 	Delete(ctx context.Context, id uint, actor ActivityActor) error
 	Get(ctx context.Context, id uint) (dto.AdminAssignmentResponse, error)
+	ImportCSV(ctx context.Context, reader io.Reader, strict bool, actor ActivityActor) (dto.AdminAssignmentImportResponse, error)
 }
 
 type adminAssignmentService struct {
-	repo      repository.AssignmentRepository
-	validator *validator.Validate
-	activity  ActivityRecorder
-	logger    zerolog.Logger
-	now       func() time.Time
+	repo       repository.AssignmentRepository
+	validator  *validator.Validate
+	sizeLimits SubmissionSizeLimits
+	activity   ActivityRecorder
+	logger     zerolog.Logger
+	clock      clock.Clock
 }
 
-// NewAdminAssignmentService constructs the admin assignment service.
-func NewAdminAssignmentService(repo repository.AssignmentRepository, validator *validator.Validate, activity ActivityRecorder, logger zerolog.Logger) AdminAssignmentService {
+// NewAdminAssignmentService constructs the admin assignment service. limits
+// bounds the submission size overrides assignments may set.
+func NewAdminAssignmentService(repo repository.AssignmentRepository, validator *validator.Validate, limits SubmissionSizeLimits, activity ActivityRecorder, logger zerolog.Logger) AdminAssignmentService {
 	return &adminAssignmentService{
-		repo:      repo,
-		validator: validator,
-		activity:  activity,
-		logger:    logger.With().Str("component", "admin_assignment_service").Logger(),
-		now:       time.Now,
+		repo:       repo,
+		validator:  validator,
+		sizeLimits: limits,
+		activity:   activity,
+		logger:     logger.With().Str("component", "admin_assignment_service").Logger(),
+		clock:      clock.Real(),
 	}
 }
 
 func (s *adminAssignmentService) Create(ctx context.Context, payload dto.AdminAssignmentCreateRequest, actor ActivityActor) (dto.AdminAssignmentResponse, error) {
-	if err := s.validator.Struct(payload); err != nil {
-		return dto.AdminAssignmentResponse{}, err
-	}
-
-	dueDate, err := time.Parse(time.RFC3339, payload.DueDate)
+	assignment, err := s.buildAssignment(payload)
 	if err != nil {
 		return dto.AdminAssignmentResponse{}, err
 	}
-	if dueDate.Before(s.now()) {
-		return dto.AdminAssignmentResponse{}, ErrAdminAssignmentInvalidDueDate
-	}
-
-	assignment := models.Assignment{
-		Title:       strings.TrimSpace(payload.Title),
-		Description: strings.TrimSpace(payload.Description),
-		DueDate:     dueDate,
-		FileURL:     strings.TrimSpace(payload.FileURL),
-		MaxScore:    payload.MaxScore,
-	}
-	if payload.Rubric != nil {
-		assignment.Rubric = jsonMapFromFloat(payload.Rubric)
-	}
 
 	if err := s.repo.Create(ctx, &assignment); err != nil {
 		return dto.AdminAssignmentResponse{}, err
@@ -125,7 +124,7 @@ func (s *adminAssignmentService) Update(ctx context.Context, id uint, payload dt
 		if err != nil {
 			return dto.AdminAssignmentResponse{}, err
 		}
-		if dueDate.Before(s.now()) {
+		if dueDate.Before(s.clock.Now()) {
 			return dto.AdminAssignmentResponse{}, ErrAdminAssignmentInvalidDueDate
 		}
 		assignment.DueDate = dueDate
@@ -143,6 +142,22 @@ func (s *adminAssignmentService) Update(ctx context.Context, id uint, payload dt
 		assignment.FileURL = strings.TrimSpace(*payload.FileURL)
 		changedFields = append(changedFields, "file_url")
 	}
+	if payload.AllowLate != nil {
+		assignment.AllowLate = *payload.AllowLate
+		changedFields = append(changedFields, "allow_late")
+	}
+	if payload.LatePenalty != nil {
+		assignment.LatePenaltyPercent = *payload.LatePenalty
+		changedFields = append(changedFields, "late_penalty_percent")
+	}
+	assignment.LatePenaltyPercent = assignment.LatePenalty()
+	if payload.MaxSubmissionMB != nil {
+		if err := s.sizeLimits.checkOverride(*payload.MaxSubmissionMB); err != nil {
+			return dto.AdminAssignmentResponse{}, err
+		}
+		assignment.MaxSubmissionMB = *payload.MaxSubmissionMB
+		changedFields = append(changedFields, "max_submission_mb")
+	}
 
 	if err := s.repo.Update(ctx, &assignment); err != nil {
 		return dto.AdminAssignmentResponse{}, err
@@ -207,6 +222,203 @@ func (s *adminAssignmentService) Get(ctx context.Context, id uint) (dto.AdminAss
 	return dto.NewAdminAssignmentResponse(assignment), nil
 }
 
+// ImportCSV creates assignments from a CSV with the columns title, description,
+// due_date and max_score. Each row is validated like Create; in strict mode any
+// invalid row rejects the whole batch, which is then written in one transaction.
+func (s *adminAssignmentService) ImportCSV(ctx context.Context, reader io.Reader, strict bool, actor ActivityActor) (dto.AdminAssignmentImportResponse, error) {
+	rows, err := parseAssignmentImportCSV(reader)
+	if err != nil {
+		return dto.AdminAssignmentImportResponse{}, err
+	}
+
+	report := dto.AdminAssignmentImportResponse{
+		Strict: strict,
+		Total:  len(rows),
+		Rows:   make([]dto.AdminAssignmentImportRowResult, 0, len(rows)),
+	}
+
+	valid := make([]*models.Assignment, 0, len(rows))
+	validIdx := make([]int, 0, len(rows))
+	for _, row := range rows {
+		result := dto.AdminAssignmentImportRowResult{Line: row.line, Title: strings.TrimSpace(row.payload.Title)}
+		if row.err != nil {
+			result.Error = row.err.Error()
+		} else if assignment, buildErr := s.buildAssignment(row.payload); buildErr != nil {
+			result.Error = buildErr.Error()
+		} else {
+			valid = append(valid, &assignment)
+			validIdx = append(validIdx, len(report.Rows))
+		}
+		if result.Error != "" {
+			report.Failed++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	if strict {
+		if report.Failed > 0 {
+			return report, ErrAdminAssignmentImportRejected
+		}
+		if err := s.repo.CreateBatch(ctx, valid); err != nil {
+			return dto.AdminAssignmentImportResponse{}, err
+		}
+		for i, assignment := range valid {
+			id := assignment.ID
+			report.Rows[validIdx[i]].AssignmentID = &id
+		}
+		report.Created = len(valid)
+	} else {
+		for i, assignment := range valid {
+			row := &report.Rows[validIdx[i]]
+			if err := s.repo.Create(ctx, assignment); err != nil {
+				s.logger.Error().Err(err).Int("line", row.Line).Msg("failed to import assignment row")
+				row.Error = "failed to create assignment"
+				report.Failed++
+				continue
+			}
+			id := assignment.ID
+			row.AssignmentID = &id
+			report.Created++
+		}
+	}
+
+	if s.activity != nil && report.Created > 0 {
+		_, _ = s.activity.Record(ctx, ActivityEntry{
+			ActorID:    actor.ID,
+			ActorRole:  actor.Role,
+			Action:     "assignment.imported",
+			EntityType: "assignment",
+			Metadata: map[string]interface{}{
+				"count":  report.Created,
+				"failed": report.Failed,
+				"total":  report.Total,
+				"strict": strict,
+			},
+		})
+	}
+
+	return report, nil
+}
+
+func (s *adminAssignmentService) buildAssignment(payload dto.AdminAssignmentCreateRequest) (models.Assignment, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return models.Assignment{}, err
+	}
+
+	dueDate, err := time.Parse(time.RFC3339, payload.DueDate)
+	if err != nil {
+		return models.Assignment{}, err
+	}
+	if dueDate.Before(s.clock.Now()) {
+		return models.Assignment{}, ErrAdminAssignmentInvalidDueDate
+	}
+	if err := s.sizeLimits.checkOverride(payload.MaxSubmissionMB); err != nil {
+		return models.Assignment{}, err
+	}
+
+	assignment := models.Assignment{
+		Title:              strings.TrimSpace(payload.Title),
+		Description:        strings.TrimSpace(payload.Description),
+		DueDate:            dueDate,
+		FileURL:            strings.TrimSpace(payload.FileURL),
+		MaxScore:           payload.MaxScore,
+		AllowLate:          payload.AllowLate,
+		LatePenaltyPercent: payload.LatePenalty,
+		MaxSubmissionMB:    payload.MaxSubmissionMB,
+	}
+	assignment.LatePenaltyPercent = assignment.LatePenalty()
+	if payload.Rubric != nil {
+		assignment.Rubric = jsonMapFromFloat(payload.Rubric)
+	}
+
+	return assignment, nil
+}
+
+type assignmentImportRow struct {
+	line    int
+	payload dto.AdminAssignmentCreateRequest
+	err     error
+}
+
+func parseAssignmentImportCSV(reader io.Reader) ([]assignmentImportRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: file is empty", ErrAdminAssignmentImportInvalid)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrAdminAssignmentImportInvalid, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for idx, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = idx
+	}
+	for _, required := range []string{"title", "due_date", "max_score"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrAdminAssignmentImportInvalid, required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		idx, ok := columns[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	rows := make([]assignmentImportRow, 0)
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrAdminAssignmentImportInvalid, err)
+		}
+
+		line, _ := csvReader.FieldPos(0)
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) >= maxAssignmentImportRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrAdminAssignmentImportInvalid, maxAssignmentImportRows)
+		}
+
+		row := assignmentImportRow{
+			line: line,
+			payload: dto.AdminAssignmentCreateRequest{
+				Title:       field(record, "title"),
+				Description: field(record, "description"),
+				DueDate:     field(record, "due_date"),
+			},
+		}
+		if raw := field(record, "max_score"); raw != "" {
+			score, parseErr := strconv.ParseFloat(raw, 64)
+			if parseErr != nil {
+				row.err = fmt.Errorf("invalid max_score %q", raw)
+			}
+			row.payload.MaxScore = score
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
 func jsonMapFromFloat(values map[string]float64) datatypes.JSONMap {
 	result := datatypes.JSONMap{}
 	for key, value := range values {
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -39,9 +41,9 @@ func setupAdminAssignmentService(t *testing.T) (*gorm.DB, AdminAssignmentService
 	activity := &stubActivityRecorder{}
 	logger := zerolog.Nop()
 
-	service := NewAdminAssignmentService(repo, validate, activity, logger)
+	service := NewAdminAssignmentService(repo, validate, SubmissionSizeLimits{AssignmentMaxMB: 100}, activity, logger)
 	if concrete, ok := service.(*adminAssignmentService); ok {
-		concrete.now = func() time.Time { return time.Date(2024, time.January, 5, 10, 0, 0, 0, time.UTC) }
+		concrete.clock = clock.NewFixed(time.Date(2024, time.January, 5, 10, 0, 0, 0, time.UTC))
 	}
 
 	return db, service, activity
@@ -97,6 +99,28 @@ func TestAdminAssignmentServiceCreateRejectsPastDueDate(t *testing.T) {
 	require.ErrorIs(t, err, ErrAdminAssignmentInvalidDueDate)
 }
 
+func TestAdminAssignmentServiceRejectsOversizedSubmissionLimit(t *testing.T) {
+	_, service, _ := setupAdminAssignmentService(t)
+	actor := ActivityActor{ID: 1, Role: "admin"}
+
+	payload := dto.AdminAssignmentCreateRequest{
+		Title:           "Portfolio",
+		DueDate:         time.Date(2024, time.January, 7, 10, 0, 0, 0, time.UTC).Format(time.RFC3339),
+		MaxScore:        100,
+		MaxSubmissionMB: 500,
+	}
+	_, err := service.Create(context.Background(), payload, actor)
+	require.ErrorIs(t, err, ErrAssignmentSizeLimitTooHigh)
+
+	payload.MaxSubmissionMB = 100
+	created, err := service.Create(context.Background(), payload, actor)
+	require.NoError(t, err)
+
+	tooLarge := 101
+	_, err = service.Update(context.Background(), created.ID, dto.AdminAssignmentUpdateRequest{MaxSubmissionMB: &tooLarge}, actor)
+	require.ErrorIs(t, err, ErrAssignmentSizeLimitTooHigh)
+}
+
 func TestAdminAssignmentServiceUpdate(t *testing.T) {
 	_, service, activity := setupAdminAssignmentService(t)
 	createPayload := dto.AdminAssignmentCreateRequest{
@@ -131,6 +155,35 @@ func TestAdminAssignmentServiceUpdate(t *testing.T) {
 	require.Contains(t, activity.entries[0].Metadata["fields"], "max_score")
 }
 
+func TestAdminAssignmentServiceLatePenaltyRequiresAllowLate(t *testing.T) {
+	_, service, _ := setupAdminAssignmentService(t)
+	actor := ActivityActor{ID: 7, Role: "teacher"}
+	created, err := service.Create(context.Background(), dto.AdminAssignmentCreateRequest{
+		Title:       "Essay",
+		DueDate:     time.Date(2024, time.January, 7, 10, 0, 0, 0, time.UTC).Format(time.RFC3339),
+		MaxScore:    100,
+		LatePenalty: 30,
+	}, actor)
+	require.NoError(t, err)
+	require.Zero(t, created.LatePenalty)
+
+	penalty := 25.0
+	updated, err := service.Update(context.Background(), created.ID, dto.AdminAssignmentUpdateRequest{LatePenalty: &penalty}, actor)
+	require.NoError(t, err)
+	require.False(t, updated.AllowLate)
+	require.Zero(t, updated.LatePenalty)
+
+	allow := true
+	updated, err = service.Update(context.Background(), created.ID, dto.AdminAssignmentUpdateRequest{AllowLate: &allow, LatePenalty: &penalty}, actor)
+	require.NoError(t, err)
+	require.Equal(t, 25.0, updated.LatePenalty)
+
+	allow = false
+	updated, err = service.Update(context.Background(), created.ID, dto.AdminAssignmentUpdateRequest{AllowLate: &allow}, actor)
+	require.NoError(t, err)
+	require.Zero(t, updated.LatePenalty)
+}
+
 func TestAdminAssignmentServiceDelete(t *testing.T) {
 	db, service, activity := setupAdminAssignmentService(t)
 	created, err := service.Create(context.Background(), dto.AdminAssignmentCreateRequest{
@@ -172,3 +225,59 @@ func TestAdminAssignmentServiceGet(t *testing.T) {
 	_, err = service.Get(context.Background(), created.ID+100)
 	require.ErrorIs(t, err, ErrAdminAssignmentNotFound)
 }
+
+const assignmentImportCSV = `title,description,due_date,max_score
+Essay,Write five paragraphs,2024-02-01T10:00:00Z,100
+No,bad,2024-02-01T10:00:00Z,50
+Quiz,,2024-02-02T10:00:00Z,abc
+Project,Build a small app,2024-03-01T10:00:00Z,80
+`
+
+func TestAdminAssignmentServiceImportCSV(t *testing.T) {
+	db, service, activity := setupAdminAssignmentService(t)
+
+	actor := ActivityActor{ID: 7, Role: "teacher"}
+	report, err := service.ImportCSV(context.Background(), strings.NewReader(assignmentImportCSV), false, actor)
+	require.NoError(t, err)
+	require.Equal(t, 4, report.Total)
+	require.Equal(t, 2, report.Created)
+	require.Equal(t, 2, report.Failed)
+	require.Len(t, report.Rows, 4)
+
+	require.Equal(t, 2, report.Rows[0].Line)
+	require.NotNil(t, report.Rows[0].AssignmentID)
+	require.Equal(t, 3, report.Rows[1].Line)
+	require.Nil(t, report.Rows[1].AssignmentID)
+	require.NotEmpty(t, report.Rows[1].Error)
+	require.Contains(t, report.Rows[2].Error, "max_score")
+	require.NotNil(t, report.Rows[3].AssignmentID)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Assignment{}).Count(&count).Error)
+	require.EqualValues(t, 2, count)
+
+	require.Len(t, activity.entries, 1)
+	require.Equal(t, "assignment.imported", activity.entries[0].Action)
+	require.Equal(t, 2, activity.entries[0].Metadata["count"])
+}
+
+func TestAdminAssignmentServiceImportCSVStrictRejectsBatch(t *testing.T) {
+	db, service, activity := setupAdminAssignmentService(t)
+
+	report, err := service.ImportCSV(context.Background(), strings.NewReader(assignmentImportCSV), true, ActivityActor{ID: 7, Role: "teacher"})
+	require.ErrorIs(t, err, ErrAdminAssignmentImportRejected)
+	require.Equal(t, 0, report.Created)
+	require.Equal(t, 2, report.Failed)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Assignment{}).Count(&count).Error)
+	require.Zero(t, count)
+	require.Empty(t, activity.entries)
+}
+
+func TestAdminAssignmentServiceImportCSVMissingColumn(t *testing.T) {
+	_, service, _ := setupAdminAssignmentService(t)
+
+	_, err := service.ImportCSV(context.Background(), strings.NewReader("title,description\nEssay,desc\n"), false, ActivityActor{ID: 1, Role: "admin"})
+	require.ErrorIs(t, err, ErrAdminAssignmentImportInvalid)
+}
@@ -3,8 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog"
 	"gorm.io/gorm"
 
@@ -13,25 +15,45 @@ import (
 	"github.com/noah-isme/gema-go-api/internal/repository"
 )
 
-// ErrAdminContactNotFound indicates submission missing.
-var ErrAdminContactNotFound = errors.New("contact submission not found")
+var (
+	// ErrAdminContactNotFound indicates submission missing.
+	ErrAdminContactNotFound = errors.New("contact submission not found")
+	// ErrContactStatusTransition indicates the requested status cannot follow the current one.
+	ErrContactStatusTransition = errors.New("contact status transition not allowed")
+)
+
+// contactStatusTransitions lists the statuses each status may move to.
+// Delivery owns queued and sent; admins triage from there, and resolved or
+// spam submissions can be reopened as read.
+var contactStatusTransitions = map[string][]string{
+	models.ContactStatusQueued:   {models.ContactStatusSent, models.ContactStatusRead, models.ContactStatusResolved, models.ContactStatusSpam},
+	models.ContactStatusSent:     {models.ContactStatusRead, models.ContactStatusResolved, models.ContactStatusSpam},
+	models.ContactStatusRead:     {models.ContactStatusResolved, models.ContactStatusSpam},
+	models.ContactStatusResolved: {models.ContactStatusRead},
+	models.ContactStatusSpam:     {models.ContactStatusRead},
+}
 
-// AdminContactService exposes admin contact read operations.
+// AdminContactService exposes the admin contact inbox.
 type AdminContactService interface {
 	List(ctx context.Context, req dto.AdminContactListRequest) (dto.AdminContactListResponse, error)
 	Get(ctx context.Context, id uint) (dto.AdminContactResponse, error)
+	UpdateStatus(ctx context.Context, id uint, payload dto.AdminContactStatusRequest, actor ActivityActor) (dto.AdminContactResponse, error)
 }
 
 type adminContactService struct {
-	repo   repository.ContactRepository
-	logger zerolog.Logger
+	repo      repository.ContactRepository
+	validator *validator.Validate
+	activity  ActivityRecorder
+	logger    zerolog.Logger
 }
 
 // NewAdminContactService constructs the contact admin service.
-func NewAdminContactService(repo repository.ContactRepository, logger zerolog.Logger) AdminContactService {
+func NewAdminContactService(repo repository.ContactRepository, validator *validator.Validate, activity ActivityRecorder, logger zerolog.Logger) AdminContactService {
 	return &adminContactService{
-		repo:   repo,
-		logger: logger.With().Str("component", "admin_contact_service").Logger(),
+		repo:      repo,
+		validator: validator,
+		activity:  activity,
+		logger:    logger.With().Str("component", "admin_contact_service").Logger(),
 	}
 }
 
@@ -54,7 +76,11 @@ func (s *adminContactService) List(ctx context.Context, req dto.AdminContactList
 
 	items := make([]dto.AdminContactResponse, 0, len(submissions))
 	for _, submission := range submissions {
-		items = append(items, toAdminContactResponse(submission))
+		item := toAdminContactResponse(submission)
+		if !req.RevealEmail {
+			item.Email = maskEmailAddress(item.Email)
+		}
+		items = append(items, item)
 	}
 
 	pagination := dto.PaginationMeta{
@@ -70,6 +96,7 @@ func (s *adminContactService) List(ctx context.Context, req dto.AdminContactList
 	}, nil
 }
 
+// Get returns a single submission with its full email so admins can reply.
 func (s *adminContactService) Get(ctx context.Context, id uint) (dto.AdminContactResponse, error) {
 	submission, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -81,16 +108,81 @@ func (s *adminContactService) Get(ctx context.Context, id uint) (dto.AdminContac
 	return toAdminContactResponse(submission), nil
 }
 
+// UpdateStatus moves a submission to a new inbox status. Setting the current
+// status again is a no-op; other moves must be listed in
+// contactStatusTransitions and are recorded in the activity log.
+func (s *adminContactService) UpdateStatus(ctx context.Context, id uint, payload dto.AdminContactStatusRequest, actor ActivityActor) (dto.AdminContactResponse, error) {
+	payload.Status = strings.ToLower(strings.TrimSpace(payload.Status))
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.AdminContactResponse{}, err
+	}
+
+	submission, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.AdminContactResponse{}, ErrAdminContactNotFound
+		}
+		return dto.AdminContactResponse{}, err
+	}
+
+	previous := submission.Status
+	if previous == payload.Status {
+		return toAdminContactResponse(submission), nil
+	}
+	if !contactTransitionAllowed(previous, payload.Status) {
+		return dto.AdminContactResponse{}, fmt.Errorf("%w: %s to %s", ErrContactStatusTransition, previous, payload.Status)
+	}
+
+	if err := s.repo.UpdateStatus(ctx, id, payload.Status); err != nil {
+		return dto.AdminContactResponse{}, err
+	}
+
+	updated, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return dto.AdminContactResponse{}, err
+	}
+
+	if s.activity != nil {
+		entityID := id
+		entry := ActivityEntry{
+			ActorID:    actor.ID,
+			ActorRole:  actor.Role,
+			Action:     "contact.status_changed",
+			EntityType: "contact",
+			EntityID:   &entityID,
+			Metadata: map[string]interface{}{
+				"from": previous,
+				"to":   payload.Status,
+			},
+		}
+		if _, err := s.activity.Record(ctx, entry); err != nil {
+			s.logger.Warn().Err(err).Uint("contact_id", id).Msg("failed to record contact activity")
+		}
+	}
+
+	return toAdminContactResponse(updated), nil
+}
+
+func contactTransitionAllowed(from, to string) bool {
+	for _, allowed := range contactStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
 func toAdminContactResponse(model models.ContactSubmission) dto.AdminContactResponse {
 	return dto.AdminContactResponse{
 		ID:          model.ID,
 		ReferenceID: model.ReferenceID,
 		Name:        model.Name,
-		Email:       maskEmailAddress(model.Email),
+		Email:       model.Email,
 		Message:     model.Message,
 		Status:      model.Status,
 		Source:      model.Source,
 		CreatedAt:   model.CreatedAt,
+		UpdatedAt:   model.UpdatedAt,
 		DeliveredAt: model.DeliveredAt,
 	}
 }
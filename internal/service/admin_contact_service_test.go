@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
@@ -35,10 +37,55 @@ func TestAdminContactServiceListMasksEmail(t *testing.T) {
 	}
 
 	repo := repository.NewContactRepository(db)
-	svc := NewAdminContactService(repo, zerolog.Nop())
+	svc := NewAdminContactService(repo, validator.New(), nil, zerolog.Nop())
 
 	result, err := svc.List(context.Background(), dto.AdminContactListRequest{PageSize: 10})
 	require.NoError(t, err)
 	require.Len(t, result.Items, 1)
 	require.Equal(t, "r***i@example.com", result.Items[0].Email)
+
+	revealed, err := svc.List(context.Background(), dto.AdminContactListRequest{PageSize: 10, RevealEmail: true})
+	require.NoError(t, err)
+	require.Equal(t, "rudi@example.com", revealed.Items[0].Email)
+}
+
+func TestAdminContactServiceUpdateStatusWorkflow(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:admin_contact_status_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.ContactSubmission{}))
+
+	submission := models.ContactSubmission{ReferenceID: "ref-status", Name: "Sari", Email: "sari@example.com", Message: "Question", Status: models.ContactStatusSent}
+	require.NoError(t, db.Create(&submission).Error)
+
+	activity := &stubActivityRecorder{}
+	svc := NewAdminContactService(repository.NewContactRepository(db), validator.New(), activity, zerolog.Nop())
+	actor := ActivityActor{ID: 7, Role: "admin"}
+	ctx := context.Background()
+
+	updated, err := svc.UpdateStatus(ctx, submission.ID, dto.AdminContactStatusRequest{Status: " Read "}, actor)
+	require.NoError(t, err)
+	require.Equal(t, models.ContactStatusRead, updated.Status)
+	require.Equal(t, "sari@example.com", updated.Email)
+
+	_, err = svc.UpdateStatus(ctx, submission.ID, dto.AdminContactStatusRequest{Status: models.ContactStatusQueued}, actor)
+	require.ErrorIs(t, err, ErrContactStatusTransition)
+
+	_, err = svc.UpdateStatus(ctx, submission.ID, dto.AdminContactStatusRequest{Status: "archived"}, actor)
+	require.Error(t, err)
+
+	_, err = svc.UpdateStatus(ctx, submission.ID, dto.AdminContactStatusRequest{Status: models.ContactStatusRead}, actor)
+	require.NoError(t, err, "repeating the current status is a no-op")
+
+	resolved, err := svc.UpdateStatus(ctx, submission.ID, dto.AdminContactStatusRequest{Status: models.ContactStatusResolved}, actor)
+	require.NoError(t, err)
+	require.Equal(t, models.ContactStatusResolved, resolved.Status)
+
+	_, err = svc.UpdateStatus(ctx, 999, dto.AdminContactStatusRequest{Status: models.ContactStatusRead}, actor)
+	require.ErrorIs(t, err, ErrAdminContactNotFound)
+
+	require.Len(t, activity.entries, 2)
+	require.Equal(t, "contact.status_changed", activity.entries[0].Action)
+	require.Equal(t, models.ContactStatusSent, activity.entries[0].Metadata["from"])
+	require.Equal(t, models.ContactStatusRead, activity.entries[0].Metadata["to"])
+	require.Equal(t, models.ContactStatusResolved, activity.entries[1].Metadata["to"])
 }
@@ -1,14 +1,20 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
+	"mime/multipart"
+	"path/filepath"
 	"strings"
 
+	"github.com/gabriel-vasile/mimetype"
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog"
 	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -18,27 +24,43 @@ import (
 type AdminGalleryService interface {
 	List(ctx context.Context, req dto.AdminGalleryListRequest) (dto.AdminGalleryListResponse, error)
 	Create(ctx context.Context, payload dto.AdminGalleryRequest, actor ActivityActor) (dto.AdminGalleryResponse, error)
+	CreateItem(ctx context.Context, payload dto.AdminGalleryUploadRequest, file *multipart.FileHeader, actor ActivityActor) (dto.AdminGalleryResponse, error)
 	Update(ctx context.Context, id uint, payload dto.AdminGalleryRequest, actor ActivityActor) (dto.AdminGalleryResponse, error)
 	Delete(ctx context.Context, id uint, actor ActivityActor) error
+	Reorder(ctx context.Context, payload dto.AdminGalleryReorderRequest, actor ActivityActor) error
 }
 
 // ErrAdminGalleryNotFound indicates gallery entry missing.
 var ErrAdminGalleryNotFound = errors.New("gallery item not found")
 
+// galleryImageTypes restricts gallery uploads to images.
+var galleryImageTypes = newMimeAllowList([]string{"image/*"}, nil)
+
 type adminGalleryService struct {
 	repo      repository.GalleryRepository
+	uploader  FileUploader
+	maxSize   sizeLimit
 	validator *validator.Validate
 	activity  ActivityRecorder
 	logger    zerolog.Logger
+	clock     clock.Clock
 }
 
-// NewAdminGalleryService constructs the gallery admin service.
-func NewAdminGalleryService(repo repository.GalleryRepository, validator *validator.Validate, activity ActivityRecorder, logger zerolog.Logger) AdminGalleryService {
+// NewAdminGalleryService constructs the gallery admin service. Uploaded images
+// larger than maxSizeMB are rejected; a non-positive value falls back to 10MB
+// like the generic upload endpoint.
+func NewAdminGalleryService(repo repository.GalleryRepository, uploader FileUploader, maxSizeMB int, validator *validator.Validate, activity ActivityRecorder, logger zerolog.Logger) AdminGalleryService {
+	if maxSizeMB <= 0 {
+		maxSizeMB = 10
+	}
 	return &adminGalleryService{
 		repo:      repo,
+		uploader:  uploader,
+		maxSize:   sizeLimit{bytes: int64(maxSizeMB) * bytesPerMB, scope: SizeLimitScopeDefault},
 		validator: validator,
 		activity:  activity,
 		logger:    logger.With().Str("component", "admin_gallery_service").Logger(),
+		clock:     clock.Real(),
 	}
 }
 
@@ -82,6 +104,78 @@ func (s *adminGalleryService) Create(ctx context.Context, payload dto.AdminGalle
 		ImagePath: strings.TrimSpace(payload.ImageURL),
 		Tags:      sanitizeTags(payload.Tags),
 	}
+	applyGalleryPlacement(&item, payload.Featured, payload.SortOrder)
+
+	if err := s.repo.Create(ctx, &item); err != nil {
+		return dto.AdminGalleryResponse{}, err
+	}
+
+	s.recordActivity(ctx, actor, "gallery.created", item.ID)
+	return toAdminGalleryResponse(item), nil
+}
+
+// CreateItem stores an uploaded image together with a generated thumbnail
+// and records its dimensions on the new gallery item.
+func (s *adminGalleryService) CreateItem(ctx context.Context, payload dto.AdminGalleryUploadRequest, file *multipart.FileHeader, actor ActivityActor) (dto.AdminGalleryResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.AdminGalleryResponse{}, err
+	}
+	if file == nil {
+		return dto.AdminGalleryResponse{}, errors.New("image is required")
+	}
+	if s.uploader == nil {
+		return dto.AdminGalleryResponse{}, errors.New("uploader not configured")
+	}
+	if err := s.maxSize.check(file.Size, ErrUploadTooLarge); err != nil {
+		return dto.AdminGalleryResponse{}, err
+	}
+
+	handle, err := file.Open()
+	if err != nil {
+		return dto.AdminGalleryResponse{}, err
+	}
+	defer handle.Close()
+
+	buf := bytes.NewBuffer(nil)
+	if _, err := io.Copy(buf, io.LimitReader(handle, s.maxSize.bytes+1)); err != nil {
+		return dto.AdminGalleryResponse{}, err
+	}
+	if err := s.maxSize.check(int64(buf.Len()), ErrUploadTooLarge); err != nil {
+		return dto.AdminGalleryResponse{}, err
+	}
+
+	if err := galleryImageTypes.check(mimetype.Detect(buf.Bytes()).String()); err != nil {
+		return dto.AdminGalleryResponse{}, err
+	}
+
+	processed, err := processGalleryImage(buf.Bytes())
+	if err != nil {
+		return dto.AdminGalleryResponse{}, err
+	}
+
+	name := sanitizeFileName(file.Filename, s.clock.Now())
+	imageURL, err := s.uploader.Upload(ctx, name, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return dto.AdminGalleryResponse{}, err
+	}
+	thumbName := strings.TrimSuffix(name, filepath.Ext(name)) + "-thumb.jpg"
+	thumbURL, err := s.uploader.Upload(ctx, thumbName, bytes.NewReader(processed.thumbnail))
+	if err != nil {
+		return dto.AdminGalleryResponse{}, err
+	}
+
+	item := models.GalleryItem{
+		Slug:          generateContentSlug(payload.Title),
+		Title:         strings.TrimSpace(payload.Title),
+		Caption:       strings.TrimSpace(payload.Caption),
+		ImagePath:     imageURL,
+		ThumbnailPath: thumbURL,
+		Width:         processed.width,
+		Height:        processed.height,
+		Tags:          sanitizeTags(payload.Tags),
+		Featured:      payload.Featured,
+		SortOrder:     payload.SortOrder,
+	}
 
 	if err := s.repo.Create(ctx, &item); err != nil {
 		return dto.AdminGalleryResponse{}, err
@@ -106,8 +200,14 @@ func (s *adminGalleryService) Update(ctx context.Context, id uint, payload dto.A
 
 	item.Title = strings.TrimSpace(payload.Title)
 	item.Caption = strings.TrimSpace(payload.Caption)
-	item.ImagePath = strings.TrimSpace(payload.ImageURL)
+	if imagePath := strings.TrimSpace(payload.ImageURL); imagePath != item.ImagePath {
+		// The thumbnail and dimensions describe the previous image.
+		item.ImagePath = imagePath
+		item.ThumbnailPath = ""
+		item.Width, item.Height = 0, 0
+	}
 	item.Tags = sanitizeTags(payload.Tags)
+	applyGalleryPlacement(&item, payload.Featured, payload.SortOrder)
 
 	if err := s.repo.Update(ctx, &item); err != nil {
 		return dto.AdminGalleryResponse{}, err
@@ -128,6 +228,44 @@ func (s *adminGalleryService) Delete(ctx context.Context, id uint, actor Activit
 	return nil
 }
 
+// Reorder sets the display order of the listed items to their position in
+// the request. Items not listed keep their current sort order.
+func (s *adminGalleryService) Reorder(ctx context.Context, payload dto.AdminGalleryReorderRequest, actor ActivityActor) error {
+	if err := s.validator.Struct(payload); err != nil {
+		return err
+	}
+
+	if err := s.repo.Reorder(ctx, payload.IDs); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAdminGalleryNotFound
+		}
+		return err
+	}
+
+	if s.activity != nil {
+		entry := ActivityEntry{
+			ActorID:    actor.ID,
+			ActorRole:  actor.Role,
+			Action:     "gallery.reordered",
+			EntityType: "gallery",
+			Metadata:   map[string]interface{}{"ids": payload.IDs},
+		}
+		if _, err := s.activity.Record(ctx, entry); err != nil {
+			s.logger.Warn().Err(err).Msg("failed to record gallery activity")
+		}
+	}
+	return nil
+}
+
+func applyGalleryPlacement(item *models.GalleryItem, featured *bool, sortOrder *int) {
+	if featured != nil {
+		item.Featured = *featured
+	}
+	if sortOrder != nil {
+		item.SortOrder = *sortOrder
+	}
+}
+
 func (s *adminGalleryService) recordActivity(ctx context.Context, actor ActivityActor, action string, id uint) {
 	if s.activity == nil {
 		return
@@ -146,13 +284,18 @@ func (s *adminGalleryService) recordActivity(ctx context.Context, actor Activity
 
 func toAdminGalleryResponse(item models.GalleryItem) dto.AdminGalleryResponse {
 	return dto.AdminGalleryResponse{
-		ID:        item.ID,
-		Slug:      item.Slug,
-		Title:     item.Title,
-		Caption:   item.Caption,
-		ImageURL:  item.ImagePath,
-		Tags:      append([]string(nil), item.Tags...),
-		CreatedAt: item.CreatedAt,
-		UpdatedAt: item.UpdatedAt,
+		ID:           item.ID,
+		Slug:         item.Slug,
+		Title:        item.Title,
+		Caption:      item.Caption,
+		ImageURL:     item.ImagePath,
+		ThumbnailURL: item.ThumbnailPath,
+		Width:        item.Width,
+		Height:       item.Height,
+		Featured:     item.Featured,
+		SortOrder:    item.SortOrder,
+		Tags:         append([]string(nil), item.Tags...),
+		CreatedAt:    item.CreatedAt,
+		UpdatedAt:    item.UpdatedAt,
 	}
 }
@@ -1,8 +1,15 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
 	"testing"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog"
@@ -22,7 +29,7 @@ func TestAdminGalleryServiceCreateAndList(t *testing.T) {
 
 	repo := repository.NewGalleryRepository(db)
 	validate := validator.New(validator.WithRequiredStructEnabled())
-	svc := NewAdminGalleryService(repo, validate, nil, zerolog.Nop())
+	svc := NewAdminGalleryService(repo, nil, 0, validate, nil, zerolog.Nop())
 
 	request := dto.AdminGalleryRequest{
 		Title:    "Showcase",
@@ -39,3 +46,50 @@ func TestAdminGalleryServiceCreateAndList(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, list.Items, 1)
 }
+
+func TestAdminGalleryServiceCreateItemProcessesImage(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:gallery_upload_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.GalleryItem{}))
+
+	uploader := &stubUploader{}
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	svc := NewAdminGalleryService(repository.NewGalleryRepository(db), uploader, 1, validate, nil, zerolog.Nop())
+
+	source := image.NewRGBA(image.Rect(0, 0, 960, 640))
+	for y := 0; y < 640; y++ {
+		for x := 0; x < 960; x++ {
+			source.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var encoded bytes.Buffer
+	require.NoError(t, png.Encode(&encoded, source))
+
+	payload := dto.AdminGalleryUploadRequest{Title: "Robotics Fair", Caption: "Finals", Tags: []string{"Robotics"}}
+	item, err := svc.CreateItem(context.Background(), payload, newTestFileHeader(t, "Robotics Fair.png", encoded.Bytes()), ActivityActor{})
+	require.NoError(t, err)
+	require.Equal(t, 2, uploader.uploads)
+	require.Equal(t, "https://example.com/robotics-fair.png", item.ImageURL)
+	require.Equal(t, "https://example.com/robotics-fair-thumb.jpg", item.ThumbnailURL)
+	require.Equal(t, 960, item.Width)
+	require.Equal(t, 640, item.Height)
+	require.Equal(t, []string{"robotics"}, item.Tags)
+
+	thumb, err := processGalleryImage(encoded.Bytes())
+	require.NoError(t, err)
+	config, format, err := image.DecodeConfig(bytes.NewReader(thumb.thumbnail))
+	require.NoError(t, err)
+	require.Equal(t, "jpeg", format)
+	require.Equal(t, galleryThumbnailWidth, config.Width)
+	require.Equal(t, 320, config.Height)
+
+	_, err = svc.CreateItem(context.Background(), payload, newTestFileHeader(t, "notes.png", []byte("plain text")), ActivityActor{})
+	var typeErr *FileTypeError
+	require.ErrorAs(t, err, &typeErr)
+
+	_, err = svc.CreateItem(context.Background(), payload, newTestFileHeader(t, "huge.png", make([]byte, 2*1024*1024)), ActivityActor{})
+	var sizeErr *SizeLimitError
+	require.ErrorAs(t, err, &sizeErr)
+	require.True(t, errors.Is(err, ErrUploadTooLarge))
+	require.Equal(t, 2, uploader.uploads)
+}
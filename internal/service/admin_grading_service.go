@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
+	"fmt"
+	"io"
 	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,9 +16,12 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/logging"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
 )
@@ -25,27 +32,73 @@ var ErrAdminSubmissionNotFound = errors.New("submission not found")
 // ErrScoreExceedsMax indicates a grading score surpasses the assignment max.
 var ErrScoreExceedsMax = errors.New("score exceeds assignment max")
 
+// ErrRubricScoresInvalid indicates rubric scores do not fit the assignment's rubric.
+var ErrRubricScoresInvalid = errors.New("invalid rubric scores")
+
+// ErrGradeReasonRequired indicates an existing grade was overridden without a reason.
+var ErrGradeReasonRequired = errors.New("reason is required when overriding an existing grade")
+
 // AdminGradingService encapsulates grading workflows for administrators and teachers.
 type AdminGradingService interface {
 	Grade(ctx context.Context, submissionID uint, payload dto.AdminGradeSubmissionRequest, actor ActivityActor) (dto.SubmissionResponse, error)
+	ExportGrades(ctx context.Context, actor ActivityActor, query dto.AdminGradeExportQuery) (GradeExport, error)
+	NextUngraded(ctx context.Context, query dto.AdminGradingQueueQuery, grader ActivityActor) (dto.AdminGradingQueueResponse, error)
+	GradeHistory(ctx context.Context, submissionID uint) ([]dto.SubmissionGradeHistoryResponse, error)
+}
+
+// ErrGradingAssignmentNotFound indicates the grading queue's assignment does not exist.
+var ErrGradingAssignmentNotFound = errors.New("assignment not found")
+
+// gradingClaimTTL is how long a grader holds a submission handed out by
+// NextUngraded before another grader may take it.
+const gradingClaimTTL = 15 * time.Minute
+
+// gradingClaimCandidates is how many claimable submissions NextUngraded
+// tries per attempt before re-reading the queue.
+const gradingClaimCandidates = 5
+
+// GradeExportCSV is the only grade export format.
+const GradeExportCSV = "csv"
+
+// gradeExportBatchSize is how many submissions a grade export loads at once.
+const gradeExportBatchSize = 500
+
+// ErrGradeExportFormat indicates the requested grade export format is not supported.
+var ErrGradeExportFormat = errors.New("unsupported grade export format")
+
+// GradeExport is a grade report that is rendered while it is written, so
+// large exports are never buffered in memory.
+type GradeExport struct {
+	Filename    string
+	ContentType string
+	stream      func(w io.Writer) error
+}
+
+// Stream writes the report to w, flushing after every batch when w supports
+// it.
+func (e GradeExport) Stream(w io.Writer) error {
+	return e.stream(w)
 }
 
 type adminGradingService struct {
 	repo      repository.AdminSubmissionRepository
 	validator *validator.Validate
 	activity  ActivityRecorder
+	dashboard DashboardCacheInvalidator
 	logger    zerolog.Logger
-	now       func() time.Time
+	clock     clock.Clock
 }
 
-// NewAdminGradingService constructs the grading service.
-func NewAdminGradingService(repo repository.AdminSubmissionRepository, validator *validator.Validate, activity ActivityRecorder, logger zerolog.Logger) AdminGradingService {
+// NewAdminGradingService constructs the grading service. A nil dashboard
+// skips dashboard cache invalidation.
+func NewAdminGradingService(repo repository.AdminSubmissionRepository, validator *validator.Validate, activity ActivityRecorder, dashboard DashboardCacheInvalidator, logger zerolog.Logger) AdminGradingService {
 	return &adminGradingService{
 		repo:      repo,
 		validator: validator,
 		activity:  activity,
+		dashboard: dashboard,
 		logger:    logger.With().Str("component", "admin_grading_service").Logger(),
-		now:       time.Now,
+		clock:     clock.Real(),
 	}
 }
 
@@ -76,12 +129,40 @@ func (s *adminGradingService) Grade(ctx context.Context, submissionID uint, payl
 		return dto.SubmissionResponse{}, err
 	}
 
+	// Grades always land on the student's most recent attempt; earlier versions stay read-only.
+	latest, err := s.repo.GetLatestVersion(ctx, submission.AssignmentID, submission.StudentID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "submission_lookup_failed")
+		return dto.SubmissionResponse{}, err
+	}
+	if err == nil && latest.ID != submission.ID {
+		logging.FromContext(ctx, s.logger).Debug().Uint("requested_id", submission.ID).Uint("submission_id", latest.ID).Msg("grading latest submission version")
+		submission = latest
+		span.SetAttributes(attribute.Int64("grading.submission_id", int64(submission.ID)))
+	}
+	span.SetAttributes(attribute.Int("grading.submission_version", submission.Version))
+
 	maxScore := submission.Assignment.MaxScore
 	if maxScore <= 0 {
 		maxScore = 100
 	}
 
-	if payload.Score > maxScore+1e-9 {
+	score := payload.Score
+	var rubricScores datatypes.JSONMap
+	if len(payload.RubricScores) > 0 {
+		total, err := rubricTotal(dto.FloatMapFromJSON(submission.Assignment.Rubric), payload.RubricScores)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "rubric_invalid")
+			return dto.SubmissionResponse{}, err
+		}
+		score = total
+		rubricScores = jsonMapFromFloat(payload.RubricScores)
+		span.SetAttributes(attribute.Bool("grading.rubric", true))
+	}
+
+	if score > maxScore+1e-9 {
 		err := ErrScoreExceedsMax
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "score_exceeds_max")
@@ -92,7 +173,8 @@ func (s *adminGradingService) Grade(ctx context.Context, submissionID uint, payl
 	currentFeedback := strings.TrimSpace(submission.Feedback)
 	currentScore := submission.Grade
 
-	isIdempotent := currentScore != nil && math.Abs(*currentScore-payload.Score) < 1e-6 && currentFeedback == payloadFeedback
+	isIdempotent := currentScore != nil && math.Abs(*currentScore-score) < 1e-6 && currentFeedback == payloadFeedback &&
+		sameRubricScores(dto.FloatMapFromJSON(submission.RubricScores), dto.FloatMapFromJSON(rubricScores))
 	if isIdempotent {
 		if submission.GradedBy != nil && *submission.GradedBy == actor.ID {
 			span.SetAttributes(attribute.Bool("grading.idempotent", true))
@@ -100,39 +182,65 @@ func (s *adminGradingService) Grade(ctx context.Context, submissionID uint, payl
 		}
 	}
 
-	grade := payload.Score
+	// Changing an existing grade is an override and must be justified for
+	// the audit trail; re-confirming the same grade is not.
+	reason := strings.TrimSpace(payload.Reason)
+	if currentScore != nil && !isIdempotent && reason == "" {
+		err := ErrGradeReasonRequired
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "reason_required")
+		return dto.SubmissionResponse{}, err
+	}
+	span.SetAttributes(attribute.Bool("grading.override", currentScore != nil))
+
+	var previousScore *float64
+	if currentScore != nil {
+		previous := *currentScore
+		previousScore = &previous
+	}
+
+	grade := score
 	submission.Grade = &grade
+	submission.RubricScores = rubricScores
 	submission.Feedback = payloadFeedback
 	submission.Status = models.SubmissionStatusGraded
-	gradedAt := s.now()
+	gradedAt := s.clock.Now()
 	submission.GradedAt = &gradedAt
 	gradedBy := actor.ID
 	submission.GradedBy = &gradedBy
+	submission.ClaimedBy = nil
+	submission.ClaimExpiresAt = nil
 
-	if err := s.repo.Update(ctx, &submission); err != nil {
+	history := models.SubmissionGradeHistory{
+		SubmissionID:  submission.ID,
+		PreviousScore: previousScore,
+		Score:         score,
+		Feedback:      payloadFeedback,
+		Reason:        reason,
+		GradedBy:      actor.ID,
+		GradedAt:      gradedAt,
+	}
+	if err := s.repo.SaveGrade(ctx, &submission, &history); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "submission_update_failed")
 		return dto.SubmissionResponse{}, err
 	}
 
-	history := models.SubmissionGradeHistory{
-		SubmissionID: submission.ID,
-		Score:        payload.Score,
-		Feedback:     payloadFeedback,
-		GradedBy:     actor.ID,
-		GradedAt:     gradedAt,
-	}
-	if err := s.repo.CreateHistory(ctx, &history); err != nil {
-		s.logger.Warn().Err(err).Uint("submission_id", submission.ID).Msg("failed to persist grading history")
-		span.RecordError(err)
+	if s.dashboard != nil {
+		s.dashboard.InvalidateStudent(ctx, submission.StudentID)
 	}
 
 	if s.activity != nil {
 		metadata := map[string]interface{}{
 			"submission_id": submission.ID,
 			"student_id":    submission.StudentID,
-			"score":         payload.Score,
+			"score":         score,
 			"assignment_id": submission.AssignmentID,
+			"version":       submission.Version,
+		}
+		if previousScore != nil {
+			metadata["previous_score"] = *previousScore
+			metadata["reason"] = reason
 		}
 		_, _ = s.activity.Record(ctx, ActivityEntry{
 			ActorID:    actor.ID,
@@ -145,9 +253,252 @@ func (s *adminGradingService) Grade(ctx context.Context, submissionID uint, payl
 	}
 
 	span.SetAttributes(
-		attribute.Float64("grading.score", payload.Score),
+		attribute.Float64("grading.score", score),
 		attribute.String("grading.status", string(submission.Status)),
 	)
 
 	return dto.NewSubmissionResponse(submission), nil
 }
+
+// rubricTotal checks that scores cover exactly the rubric's criteria, each
+// within [0, weight], and returns their sum rounded to two decimals.
+func rubricTotal(weights, scores map[string]float64) (float64, error) {
+	if len(weights) == 0 {
+		return 0, fmt.Errorf("%w: assignment has no rubric", ErrRubricScoresInvalid)
+	}
+
+	total := 0.0
+	for criterion, value := range scores {
+		weight, ok := weights[criterion]
+		if !ok {
+			return 0, fmt.Errorf("%w: unknown criterion %q", ErrRubricScoresInvalid, criterion)
+		}
+		if value < 0 || value > weight+1e-9 {
+			return 0, fmt.Errorf("%w: %q must be between 0 and %s", ErrRubricScoresInvalid, criterion, strconv.FormatFloat(weight, 'f', -1, 64))
+		}
+		total += value
+	}
+	for criterion := range weights {
+		if _, ok := scores[criterion]; !ok {
+			return 0, fmt.Errorf("%w: missing criterion %q", ErrRubricScoresInvalid, criterion)
+		}
+	}
+
+	return math.Round(total*100) / 100, nil
+}
+
+func sameRubricScores(a, b map[string]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for criterion, value := range a {
+		other, ok := b[criterion]
+		if !ok || math.Abs(value-other) >= 1e-6 {
+			return false
+		}
+	}
+	return true
+}
+
+// GradeHistory lists every grade recorded for a submission in the order it
+// was given, including overrides with their previous score and reason.
+func (s *adminGradingService) GradeHistory(ctx context.Context, submissionID uint) ([]dto.SubmissionGradeHistoryResponse, error) {
+	submission, err := s.repo.GetByID(ctx, submissionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAdminSubmissionNotFound
+		}
+		return nil, err
+	}
+
+	entries, err := s.repo.ListHistory(ctx, submission.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]dto.SubmissionGradeHistoryResponse, 0, len(entries))
+	for _, entry := range entries {
+		history = append(history, dto.NewSubmissionGradeHistoryResponse(entry, submission.Assignment))
+	}
+	return history, nil
+}
+
+// NextUngraded claims the oldest ungraded submission of an assignment for the
+// grader. A grader who already holds a live claim gets the same submission
+// back, so refreshing does not skip work; otherwise claims are taken with a
+// conditional update and two graders never receive the same submission.
+func (s *adminGradingService) NextUngraded(ctx context.Context, query dto.AdminGradingQueueQuery, grader ActivityActor) (dto.AdminGradingQueueResponse, error) {
+	response := dto.AdminGradingQueueResponse{AssignmentID: query.AssignmentID, Anonymized: query.Anonymized}
+
+	exists, err := s.repo.AssignmentExists(ctx, query.AssignmentID)
+	if err != nil {
+		return response, err
+	}
+	if !exists {
+		return response, ErrGradingAssignmentNotFound
+	}
+
+	remaining, err := s.repo.CountUngraded(ctx, query.AssignmentID)
+	if err != nil {
+		return response, err
+	}
+	response.Remaining = remaining
+	if remaining == 0 {
+		response.AllGraded = true
+		return response, nil
+	}
+
+	now := s.clock.Now()
+	claimed, err := s.repo.FindClaim(ctx, query.AssignmentID, grader.ID, now)
+	if err == nil {
+		return s.gradingQueueResponse(response, claimed, query.Anonymized), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return response, err
+	}
+
+	expiresAt := now.Add(gradingClaimTTL)
+	for attempt := 0; attempt < 3; attempt++ {
+		candidates, err := s.repo.ListClaimable(ctx, query.AssignmentID, now, gradingClaimCandidates)
+		if err != nil {
+			return response, err
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		for _, candidate := range candidates {
+			ok, err := s.repo.Claim(ctx, candidate.ID, grader.ID, now, expiresAt)
+			if err != nil {
+				return response, err
+			}
+			if !ok {
+				continue
+			}
+			submission, err := s.repo.GetByID(ctx, candidate.ID)
+			if err != nil {
+				return response, err
+			}
+			return s.gradingQueueResponse(response, submission, query.Anonymized), nil
+		}
+	}
+
+	return response, nil
+}
+
+func (s *adminGradingService) gradingQueueResponse(response dto.AdminGradingQueueResponse, submission models.Submission, anonymized bool) dto.AdminGradingQueueResponse {
+	result := dto.NewSubmissionResponse(submission)
+	if anonymized {
+		result.StudentID = 0
+		result.Student = dto.StudentLite{}
+	}
+	response.Submission = &result
+	response.ClaimExpiresAt = submission.ClaimExpiresAt
+	return response
+}
+
+// ExportGrades prepares a CSV of each student's latest submission, optionally
+// limited to one assignment. The export.generated activity is recorded once
+// the report has been streamed, with the number of rows written.
+func (s *adminGradingService) ExportGrades(ctx context.Context, actor ActivityActor, query dto.AdminGradeExportQuery) (GradeExport, error) {
+	format := strings.ToLower(strings.TrimSpace(query.Format))
+	if format == "" {
+		format = GradeExportCSV
+	}
+	if format != GradeExportCSV {
+		return GradeExport{}, ErrGradeExportFormat
+	}
+
+	name := "grades"
+	filters := map[string]interface{}{}
+	if query.AssignmentID != 0 {
+		name = fmt.Sprintf("grades-assignment-%d", query.AssignmentID)
+		filters["assignment_id"] = query.AssignmentID
+	}
+
+	export := GradeExport{
+		Filename:    fmt.Sprintf("%s-%s.%s", name, s.clock.Now().UTC().Format("20060102T150405Z"), format),
+		ContentType: "text/csv; charset=utf-8",
+	}
+	export.stream = func(w io.Writer) error {
+		rows, err := s.writeGradesCSV(ctx, w, repository.SubmissionExportFilter{AssignmentID: query.AssignmentID})
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Error().Err(err).Int("rows", rows).Msg("grade export aborted")
+			return err
+		}
+		recordExport(ctx, s.activity, s.logger, actor, "grades", format, rows, filters)
+		return nil
+	}
+	return export, nil
+}
+
+var gradeExportHeader = []string{
+	"submission_id", "assignment_id", "assignment_title", "student_id", "student_name", "student_email", "student_class",
+	"version", "status", "late", "submitted_at", "grade", "final_grade", "max_score", "grade_percent", "feedback", "graded_at",
+}
+
+func (s *adminGradingService) writeGradesCSV(ctx context.Context, w io.Writer, filter repository.SubmissionExportFilter) (int, error) {
+	flusher, _ := w.(interface{ Flush() error })
+	writer := csv.NewWriter(w)
+	flush := func() error {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+		if flusher != nil {
+			return flusher.Flush()
+		}
+		return nil
+	}
+
+	if err := writer.Write(gradeExportHeader); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	err := s.repo.EachLatestForExport(ctx, filter, gradeExportBatchSize, func(batch []models.Submission) error {
+		for _, submission := range batch {
+			if err := writer.Write(gradeExportRow(submission)); err != nil {
+				return err
+			}
+			rows++
+		}
+		return flush()
+	})
+	if err != nil {
+		return rows, err
+	}
+	return rows, flush()
+}
+
+func gradeExportRow(submission models.Submission) []string {
+	formatScore := func(score *float64) string {
+		if score == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*score, 'f', -1, 64)
+	}
+	gradedAt := ""
+	if submission.GradedAt != nil {
+		gradedAt = submission.GradedAt.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		strconv.FormatUint(uint64(submission.ID), 10),
+		strconv.FormatUint(uint64(submission.AssignmentID), 10),
+		csvCell(submission.Assignment.Title),
+		strconv.FormatUint(uint64(submission.StudentID), 10),
+		csvCell(submission.Student.Name),
+		csvCell(submission.Student.Email),
+		csvCell(submission.Student.Class),
+		strconv.Itoa(submission.Version),
+		submission.Status,
+		strconv.FormatBool(submission.Late),
+		submission.CreatedAt.UTC().Format(time.RFC3339),
+		formatScore(submission.Grade),
+		formatScore(submission.PenalizedGrade()),
+		strconv.FormatFloat(submission.Assignment.MaxScore, 'f', -1, 64),
+		formatScore(submission.Assignment.ScorePercent(submission.PenalizedGrade())),
+		csvCell(submission.Feedback),
+		gradedAt,
+	}
+}
@@ -1,36 +1,75 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
 )
 
 type fakeAdminSubmissionRepo struct {
-	submission   models.Submission
-	updateCalls  int
-	historyCalls int
+	submission models.Submission
+	latest     *models.Submission
+	saveCalls  int
 }
 
 func (f *fakeAdminSubmissionRepo) GetByID(ctx context.Context, id uint) (models.Submission, error) {
 	return f.submission, nil
 }
 
-func (f *fakeAdminSubmissionRepo) Update(ctx context.Context, submission *models.Submission) error {
-	f.updateCalls++
+func (f *fakeAdminSubmissionRepo) GetLatestVersion(ctx context.Context, assignmentID, studentID uint) (models.Submission, error) {
+	if f.latest != nil {
+		return *f.latest, nil
+	}
+	return f.submission, nil
+}
+
+func (f *fakeAdminSubmissionRepo) SaveGrade(ctx context.Context, submission *models.Submission, history *models.SubmissionGradeHistory) error {
+	f.saveCalls++
 	f.submission = *submission
 	return nil
 }
 
-func (f *fakeAdminSubmissionRepo) CreateHistory(ctx context.Context, history *models.SubmissionGradeHistory) error {
-	f.historyCalls++
-	return nil
+func (f *fakeAdminSubmissionRepo) ListHistory(ctx context.Context, submissionID uint) ([]models.SubmissionGradeHistory, error) {
+	return nil, nil
+}
+
+func (f *fakeAdminSubmissionRepo) EachLatestForExport(ctx context.Context, filter repository.SubmissionExportFilter, batchSize int, fn func([]models.Submission) error) error {
+	return fn([]models.Submission{f.submission})
+}
+
+func (f *fakeAdminSubmissionRepo) AssignmentExists(ctx context.Context, assignmentID uint) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeAdminSubmissionRepo) CountUngraded(ctx context.Context, assignmentID uint) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeAdminSubmissionRepo) FindClaim(ctx context.Context, assignmentID, graderID uint, now time.Time) (models.Submission, error) {
+	return models.Submission{}, gorm.ErrRecordNotFound
+}
+
+func (f *fakeAdminSubmissionRepo) ListClaimable(ctx context.Context, assignmentID uint, now time.Time, limit int) ([]models.Submission, error) {
+	return nil, nil
+}
+
+func (f *fakeAdminSubmissionRepo) Claim(ctx context.Context, submissionID, graderID uint, now, expiresAt time.Time) (bool, error) {
+	return false, nil
 }
 
 func TestAdminGradingServiceScoreExceedsMax(t *testing.T) {
@@ -47,13 +86,12 @@ func TestAdminGradingServiceScoreExceedsMax(t *testing.T) {
 		},
 	}
 	validate := validator.New(validator.WithRequiredStructEnabled())
-	svc := NewAdminGradingService(repo, validate, nil, testLogger())
+	svc := NewAdminGradingService(repo, validate, nil, nil, testLogger())
 
 	_, err := svc.Grade(context.Background(), 1, dto.AdminGradeSubmissionRequest{Score: 80, Feedback: "great"}, ActivityActor{ID: 10, Role: "teacher"})
 	require.Error(t, err)
 	require.ErrorIs(t, err, ErrScoreExceedsMax)
-	require.Equal(t, 0, repo.updateCalls)
-	require.Equal(t, 0, repo.historyCalls)
+	require.Equal(t, 0, repo.saveCalls)
 }
 
 func TestAdminGradingServiceIdempotent(t *testing.T) {
@@ -77,11 +115,325 @@ func TestAdminGradingServiceIdempotent(t *testing.T) {
 		},
 	}
 	validate := validator.New(validator.WithRequiredStructEnabled())
-	svc := NewAdminGradingService(repo, validate, nil, testLogger())
+	svc := NewAdminGradingService(repo, validate, nil, nil, testLogger())
 
 	result, err := svc.Grade(context.Background(), 10, dto.AdminGradeSubmissionRequest{Score: 90, Feedback: "Well done"}, ActivityActor{ID: gradedBy, Role: "teacher"})
 	require.NoError(t, err)
 	require.Equal(t, grade, *result.Grade)
-	require.Equal(t, 0, repo.updateCalls)
-	require.Equal(t, 0, repo.historyCalls)
+	require.Equal(t, 0, repo.saveCalls)
+}
+
+func TestAdminGradingServiceGradesLatestVersion(t *testing.T) {
+	repo := &fakeAdminSubmissionRepo{
+		submission: models.Submission{
+			ID:           20,
+			AssignmentID: 5,
+			StudentID:    6,
+			Version:      1,
+			Assignment:   models.Assignment{ID: 5, MaxScore: 100},
+		},
+		latest: &models.Submission{
+			ID:           21,
+			AssignmentID: 5,
+			StudentID:    6,
+			Version:      2,
+			Assignment:   models.Assignment{ID: 5, MaxScore: 100},
+		},
+	}
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	svc := NewAdminGradingService(repo, validate, nil, nil, testLogger())
+
+	result, err := svc.Grade(context.Background(), 20, dto.AdminGradeSubmissionRequest{Score: 75, Feedback: "Improved"}, ActivityActor{ID: 8, Role: "teacher"})
+	require.NoError(t, err)
+	require.Equal(t, uint(21), result.ID)
+	require.Equal(t, 2, result.Version)
+	require.NotNil(t, result.GradePercent)
+	require.Equal(t, 75.0, *result.GradePercent)
+	require.Equal(t, uint(21), repo.submission.ID)
+	require.Equal(t, 1, repo.saveCalls)
+}
+
+func TestAdminGradingServiceRubricScores(t *testing.T) {
+	repo := &fakeAdminSubmissionRepo{
+		submission: models.Submission{
+			ID:           30,
+			AssignmentID: 7,
+			StudentID:    8,
+			Assignment: models.Assignment{
+				ID:       7,
+				MaxScore: 50,
+				Rubric:   datatypes.JSONMap{"content": 30.0, "style": 20.0},
+			},
+		},
+	}
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	svc := NewAdminGradingService(repo, validate, nil, nil, testLogger())
+	teacher := ActivityActor{ID: 9, Role: "teacher"}
+
+	invalid := []map[string]float64{
+		{"content": 25},
+		{"content": 25, "style": 15, "grammar": 5},
+		{"content": 31, "style": 10},
+	}
+	for _, scores := range invalid {
+		_, err := svc.Grade(context.Background(), 30, dto.AdminGradeSubmissionRequest{RubricScores: scores}, teacher)
+		require.ErrorIs(t, err, ErrRubricScoresInvalid)
+	}
+	require.Equal(t, 0, repo.saveCalls)
+
+	result, err := svc.Grade(context.Background(), 30, dto.AdminGradeSubmissionRequest{Score: 1, RubricScores: map[string]float64{"content": 25.5, "style": 12}}, teacher)
+	require.NoError(t, err)
+	require.Equal(t, 37.5, *result.Grade)
+	require.Equal(t, 75.0, *result.GradePercent)
+	require.Equal(t, map[string]float64{"content": 25.5, "style": 12}, result.RubricScores)
+
+	_, err = svc.Grade(context.Background(), 30, dto.AdminGradeSubmissionRequest{RubricScores: map[string]float64{"content": 30, "style": 20}, Reason: "regraded"}, teacher)
+	require.NoError(t, err)
+	flat, err := svc.Grade(context.Background(), 30, dto.AdminGradeSubmissionRequest{Score: 45, Reason: "flat grade"}, teacher)
+	require.NoError(t, err)
+	require.Equal(t, 45.0, *flat.Grade)
+	require.Empty(t, flat.RubricScores)
+}
+
+func TestAdminGradingServiceOverrideRequiresReason(t *testing.T) {
+	dsn := fmt.Sprintf("file:grade_override_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Student{}, &models.Assignment{}, &models.Submission{}, &models.SubmissionGradeHistory{}))
+
+	now := time.Date(2024, time.May, 3, 9, 0, 0, 0, time.UTC)
+	student := models.Student{Name: "Ayu", Email: "ayu@example.com"}
+	require.NoError(t, db.Create(&student).Error)
+	essay := models.Assignment{Title: "Essay", MaxScore: 50, DueDate: now}
+	require.NoError(t, db.Create(&essay).Error)
+	submission := models.Submission{AssignmentID: essay.ID, StudentID: student.ID, Version: 1, Status: models.SubmissionStatusSubmitted}
+	require.NoError(t, db.Create(&submission).Error)
+
+	activity := &stubActivityRecorder{}
+	svc := NewAdminGradingService(repository.NewAdminSubmissionRepository(db), validator.New(), activity, nil, testLogger())
+	svc.(*adminGradingService).clock = clock.NewFixed(now)
+	teacher := ActivityActor{ID: 4, Role: "teacher"}
+
+	_, err = svc.Grade(context.Background(), submission.ID, dto.AdminGradeSubmissionRequest{Score: 30}, teacher)
+	require.NoError(t, err)
+
+	_, err = svc.Grade(context.Background(), submission.ID, dto.AdminGradeSubmissionRequest{Score: 40}, teacher)
+	require.ErrorIs(t, err, ErrGradeReasonRequired)
+
+	svc.(*adminGradingService).clock = clock.NewFixed(now.Add(time.Hour))
+	overrider := ActivityActor{ID: 5, Role: "admin"}
+	result, err := svc.Grade(context.Background(), submission.ID, dto.AdminGradeSubmissionRequest{Score: 40, Reason: " missed rubric item "}, overrider)
+	require.NoError(t, err)
+	require.Equal(t, 40.0, *result.Grade)
+
+	history, err := svc.GradeHistory(context.Background(), submission.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Nil(t, history[0].PreviousScore)
+	require.Equal(t, 30.0, history[0].Score)
+	require.Equal(t, uint(4), history[0].GradedBy)
+	require.NotNil(t, history[1].PreviousScore)
+	require.Equal(t, 30.0, *history[1].PreviousScore)
+	require.Equal(t, 40.0, history[1].Score)
+	require.Equal(t, 80.0, *history[1].ScorePercent)
+	require.Equal(t, "missed rubric item", history[1].Reason)
+	require.Equal(t, uint(5), history[1].GradedBy)
+
+	require.Len(t, activity.entries, 2)
+	require.Equal(t, 30.0, activity.entries[1].Metadata["previous_score"])
+	require.Equal(t, "missed rubric item", activity.entries[1].Metadata["reason"])
+
+	_, err = svc.GradeHistory(context.Background(), submission.ID+100)
+	require.ErrorIs(t, err, ErrAdminSubmissionNotFound)
+}
+
+func TestAdminGradingExportStreamsLatestVersions(t *testing.T) {
+	dsn := fmt.Sprintf("file:grade_export_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Student{}, &models.Assignment{}, &models.Submission{}, &models.SubmissionGradeHistory{}))
+
+	due := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	students := []models.Student{
+		{Name: "Ayu", Email: "ayu@example.com", Class: "XI-1"},
+		{Name: "=cmd", Email: "budi@example.com", Class: "XI-2"},
+	}
+	require.NoError(t, db.Create(&students).Error)
+	essay := models.Assignment{Title: "Essay", MaxScore: 50, DueDate: due}
+	quiz := models.Assignment{Title: "Quiz", MaxScore: 10, DueDate: due}
+	require.NoError(t, db.Create(&essay).Error)
+	require.NoError(t, db.Create(&quiz).Error)
+
+	submissions := []models.Submission{
+		{AssignmentID: essay.ID, StudentID: students[0].ID, Version: 1, Status: models.SubmissionStatusGraded, Grade: floatPointer(20), CreatedAt: due.Add(-2 * time.Hour)},
+		{AssignmentID: essay.ID, StudentID: students[0].ID, Version: 2, Status: models.SubmissionStatusGraded, Grade: floatPointer(40), Feedback: "Much better", CreatedAt: due.Add(-time.Hour)},
+		{AssignmentID: essay.ID, StudentID: students[1].ID, Version: 1, Status: models.SubmissionStatusSubmitted, Late: true, CreatedAt: due.Add(time.Hour)},
+		{AssignmentID: quiz.ID, StudentID: students[0].ID, Version: 1, Status: models.SubmissionStatusGraded, Grade: floatPointer(9), CreatedAt: due},
+	}
+	require.NoError(t, db.Create(&submissions).Error)
+
+	activity := &stubActivityRecorder{}
+	svc := NewAdminGradingService(repository.NewAdminSubmissionRepository(db), validator.New(), activity, nil, testLogger())
+	svc.(*adminGradingService).clock = clock.NewFixed(due)
+
+	_, err = svc.ExportGrades(context.Background(), ActivityActor{}, dto.AdminGradeExportQuery{Format: "xlsx"})
+	require.ErrorIs(t, err, ErrGradeExportFormat)
+
+	export, err := svc.ExportGrades(context.Background(), ActivityActor{ID: 9, Role: "teacher"}, dto.AdminGradeExportQuery{AssignmentID: essay.ID})
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("grades-assignment-%d-20240501T120000Z.csv", essay.ID), export.Filename)
+	require.Empty(t, activity.entries)
+
+	var buf bytes.Buffer
+	require.NoError(t, export.Stream(&buf))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	require.Equal(t, gradeExportHeader, rows[0])
+	require.Equal(t, []string{"Ayu", "2", "graded", "40", "50", "80", "Much better"}, []string{rows[1][4], rows[1][7], rows[1][8], rows[1][11], rows[1][13], rows[1][14], rows[1][15]})
+	require.Equal(t, []string{"'=cmd", "true", ""}, []string{rows[2][4], rows[2][9], rows[2][11]})
+
+	require.Len(t, activity.entries, 1)
+	require.Equal(t, "export.generated", activity.entries[0].Action)
+	require.Equal(t, 2, activity.entries[0].Metadata["rows"])
+	require.Equal(t, map[string]interface{}{"assignment_id": essay.ID}, activity.entries[0].Metadata["filters"])
+}
+
+func TestAdminGradingNextUngradedHandsOutDistinctSubmissions(t *testing.T) {
+	dsn := fmt.Sprintf("file:grading_queue_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Student{}, &models.Assignment{}, &models.Submission{}, &models.SubmissionGradeHistory{}))
+
+	now := time.Date(2024, time.May, 2, 9, 0, 0, 0, time.UTC)
+	students := []models.Student{
+		{Name: "Ayu", Email: "ayu@example.com"},
+		{Name: "Budi", Email: "budi@example.com"},
+		{Name: "Citra", Email: "citra@example.com"},
+	}
+	require.NoError(t, db.Create(&students).Error)
+	essay := models.Assignment{Title: "Essay", MaxScore: 100, DueDate: now}
+	require.NoError(t, db.Create(&essay).Error)
+
+	submissions := []models.Submission{
+		{AssignmentID: essay.ID, StudentID: students[0].ID, Version: 1, Status: models.SubmissionStatusSubmitted, CreatedAt: now.Add(-3 * time.Hour)},
+		{AssignmentID: essay.ID, StudentID: students[0].ID, Version: 2, Status: models.SubmissionStatusSubmitted, CreatedAt: now.Add(-time.Hour)},
+		{AssignmentID: essay.ID, StudentID: students[1].ID, Version: 1, Status: models.SubmissionStatusSubmitted, CreatedAt: now.Add(-2 * time.Hour)},
+		{AssignmentID: essay.ID, StudentID: students[2].ID, Version: 1, Status: models.SubmissionStatusGraded, Grade: floatPointer(80), CreatedAt: now.Add(-4 * time.Hour)},
+	}
+	require.NoError(t, db.Create(&submissions).Error)
+
+	svc := NewAdminGradingService(repository.NewAdminSubmissionRepository(db), validator.New(), nil, nil, testLogger())
+	svc.(*adminGradingService).clock = clock.NewFixed(now)
+	first := ActivityActor{ID: 1, Role: "teacher"}
+	second := ActivityActor{ID: 2, Role: "teacher"}
+	third := ActivityActor{ID: 3, Role: "teacher"}
+	query := dto.AdminGradingQueueQuery{AssignmentID: essay.ID}
+
+	_, err = svc.NextUngraded(context.Background(), dto.AdminGradingQueueQuery{AssignmentID: essay.ID + 100}, first)
+	require.ErrorIs(t, err, ErrGradingAssignmentNotFound)
+
+	a, err := svc.NextUngraded(context.Background(), query, first)
+	require.NoError(t, err)
+	require.NotNil(t, a.Submission)
+	require.Equal(t, submissions[2].ID, a.Submission.ID)
+	require.Equal(t, int64(2), a.Remaining)
+	require.Equal(t, now.Add(gradingClaimTTL), *a.ClaimExpiresAt)
+
+	again, err := svc.NextUngraded(context.Background(), query, first)
+	require.NoError(t, err)
+	require.Equal(t, a.Submission.ID, again.Submission.ID)
+
+	b, err := svc.NextUngraded(context.Background(), dto.AdminGradingQueueQuery{AssignmentID: essay.ID, Anonymized: true}, second)
+	require.NoError(t, err)
+	require.NotNil(t, b.Submission)
+	require.Equal(t, submissions[1].ID, b.Submission.ID)
+	require.Zero(t, b.Submission.StudentID)
+	require.Empty(t, b.Submission.Student.Name)
+
+	busy, err := svc.NextUngraded(context.Background(), query, third)
+	require.NoError(t, err)
+	require.Nil(t, busy.Submission)
+	require.False(t, busy.AllGraded)
+
+	_, err = svc.Grade(context.Background(), a.Submission.ID, dto.AdminGradeSubmissionRequest{Score: 70}, first)
+	require.NoError(t, err)
+	_, err = svc.Grade(context.Background(), b.Submission.ID, dto.AdminGradeSubmissionRequest{Score: 90}, second)
+	require.NoError(t, err)
+
+	done, err := svc.NextUngraded(context.Background(), query, third)
+	require.NoError(t, err)
+	require.True(t, done.AllGraded)
+	require.Nil(t, done.Submission)
+	require.Zero(t, done.Remaining)
+}
+
+func TestAdminGradingNextUngradedReclaimsExpiredClaims(t *testing.T) {
+	dsn := fmt.Sprintf("file:grading_queue_expiry_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Student{}, &models.Assignment{}, &models.Submission{}, &models.SubmissionGradeHistory{}))
+
+	now := time.Date(2024, time.May, 2, 9, 0, 0, 0, time.UTC)
+	student := models.Student{Name: "Ayu", Email: "ayu@example.com"}
+	require.NoError(t, db.Create(&student).Error)
+	essay := models.Assignment{Title: "Essay", MaxScore: 100, DueDate: now}
+	require.NoError(t, db.Create(&essay).Error)
+	submission := models.Submission{AssignmentID: essay.ID, StudentID: student.ID, Version: 1, Status: models.SubmissionStatusSubmitted}
+	require.NoError(t, db.Create(&submission).Error)
+
+	svc := NewAdminGradingService(repository.NewAdminSubmissionRepository(db), validator.New(), nil, nil, testLogger())
+	svc.(*adminGradingService).clock = clock.NewFixed(now)
+	query := dto.AdminGradingQueueQuery{AssignmentID: essay.ID}
+
+	claimed, err := svc.NextUngraded(context.Background(), query, ActivityActor{ID: 1, Role: "teacher"})
+	require.NoError(t, err)
+	require.Equal(t, submission.ID, claimed.Submission.ID)
+
+	svc.(*adminGradingService).clock = clock.NewFixed(now.Add(gradingClaimTTL + time.Minute))
+	reclaimed, err := svc.NextUngraded(context.Background(), query, ActivityActor{ID: 2, Role: "teacher"})
+	require.NoError(t, err)
+	require.NotNil(t, reclaimed.Submission)
+	require.Equal(t, submission.ID, reclaimed.Submission.ID)
+}
+
+func TestAdminGradingServiceGradeRollsBackWithoutHistory(t *testing.T) {
+	dsn := fmt.Sprintf("file:grading_history_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Student{}, &models.Assignment{}, &models.Submission{}, &models.SubmissionGradeHistory{}))
+
+	student := models.Student{Name: "Ayu", Email: "ayu.history@example.com"}
+	require.NoError(t, db.Create(&student).Error)
+	essay := models.Assignment{Title: "Essay", MaxScore: 100, DueDate: time.Now()}
+	require.NoError(t, db.Create(&essay).Error)
+	submission := models.Submission{AssignmentID: essay.ID, StudentID: student.ID, Version: 1, Status: models.SubmissionStatusSubmitted}
+	require.NoError(t, db.Create(&submission).Error)
+
+	svc := NewAdminGradingService(repository.NewAdminSubmissionRepository(db), validator.New(), nil, nil, testLogger())
+	teacher := ActivityActor{ID: 4, Role: "teacher"}
+
+	errHistory := errors.New("history unavailable")
+	failHistory := func(tx *gorm.DB) {
+		if _, ok := tx.Statement.Dest.(*models.SubmissionGradeHistory); ok {
+			_ = tx.AddError(errHistory)
+		}
+	}
+	require.NoError(t, db.Callback().Create().Before("gorm:create").Register("test:fail_history", failHistory))
+	_, err = svc.Grade(context.Background(), submission.ID, dto.AdminGradeSubmissionRequest{Score: 70}, teacher)
+	require.ErrorIs(t, err, errHistory)
+
+	var stored models.Submission
+	require.NoError(t, db.First(&stored, submission.ID).Error)
+	require.Nil(t, stored.Grade)
+	require.Equal(t, models.SubmissionStatusSubmitted, stored.Status)
+
+	require.NoError(t, db.Callback().Create().Remove("test:fail_history"))
+	_, err = svc.Grade(context.Background(), submission.ID, dto.AdminGradeSubmissionRequest{Score: 70}, teacher)
+	require.NoError(t, err)
+
+	var history []models.SubmissionGradeHistory
+	require.NoError(t, db.Where("submission_id = ?", submission.ID).Find(&history).Error)
+	require.Len(t, history, 1)
+	require.Equal(t, 70.0, history[0].Score)
 }
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+// ErrAdminNotificationImportInvalid indicates the uploaded recipient CSV could not be parsed.
+var ErrAdminNotificationImportInvalid = errors.New("invalid notification recipient file")
+
+// ErrAdminNotificationRecipientsRequired indicates neither or both of a CSV file and a class were given.
+var ErrAdminNotificationRecipientsRequired = errors.New("provide either a recipient CSV or a class")
+
+const maxNotificationBatchRows = 5000
+
+// AdminNotificationService sends notifications to cohorts on behalf of admins.
+type AdminNotificationService interface {
+	SendBatch(ctx context.Context, recipients io.Reader, payload dto.AdminNotificationBatchRequest, actor ActivityActor) (dto.AdminNotificationBatchResponse, error)
+}
+
+type adminNotificationService struct {
+	notifications NotificationService
+	students      repository.AdminStudentRepository
+	validator     *validator.Validate
+	activity      ActivityRecorder
+	logger        zerolog.Logger
+}
+
+// NewAdminNotificationService constructs the admin notification service.
+func NewAdminNotificationService(notifications NotificationService, students repository.AdminStudentRepository, validator *validator.Validate, activity ActivityRecorder, logger zerolog.Logger) AdminNotificationService {
+	return &adminNotificationService{
+		notifications: notifications,
+		students:      students,
+		validator:     validator,
+		activity:      activity,
+		logger:        logger.With().Str("component", "admin_notification_service").Logger(),
+	}
+}
+
+// SendBatch notifies every user listed in the recipients CSV (a user_id
+// column) or, when recipients is nil, every enrolled student in payload.Class.
+// Invalid and duplicate CSV rows are reported and skipped; the remaining users
+// are notified through PublishBulk.
+func (s *adminNotificationService) SendBatch(ctx context.Context, recipients io.Reader, payload dto.AdminNotificationBatchRequest, actor ActivityActor) (dto.AdminNotificationBatchResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.AdminNotificationBatchResponse{}, err
+	}
+
+	class := strings.TrimSpace(payload.Class)
+	if (recipients == nil) == (class == "") {
+		return dto.AdminNotificationBatchResponse{}, ErrAdminNotificationRecipientsRequired
+	}
+
+	var rows []dto.AdminNotificationBatchRowResult
+	var err error
+	if recipients != nil {
+		rows, err = parseNotificationRecipientsCSV(recipients)
+	} else {
+		rows, err = s.classRecipients(ctx, class)
+	}
+	if err != nil {
+		return dto.AdminNotificationBatchResponse{}, err
+	}
+
+	report := dto.AdminNotificationBatchResponse{Total: len(rows), Rows: rows}
+	userIDs := make([]string, 0, len(rows))
+	validIdx := make([]int, 0, len(rows))
+	for i, row := range rows {
+		if row.Error != "" {
+			report.Failed++
+			continue
+		}
+		userIDs = append(userIDs, row.UserID)
+		validIdx = append(validIdx, i)
+	}
+
+	if len(userIDs) > 0 {
+		sent, err := s.notifications.PublishBulk(ctx, dto.NotificationBulkCreateRequest{
+			UserIDs:  userIDs,
+			Type:     strings.TrimSpace(payload.Type),
+			Message:  payload.Message,
+			Priority: payload.Priority,
+		})
+		for i, notification := range sent {
+			id := notification.ID
+			report.Rows[validIdx[i]].NotificationID = &id
+		}
+		report.Delivered = len(sent)
+		if err != nil {
+			s.logger.Error().Err(err).Int("delivered", len(sent)).Int("pending", len(userIDs)-len(sent)).Msg("failed to publish notification batch")
+			if len(sent) == 0 {
+				return dto.AdminNotificationBatchResponse{}, err
+			}
+			for _, idx := range validIdx[len(sent):] {
+				report.Rows[idx].Error = "failed to deliver notification"
+				report.Failed++
+			}
+		}
+	}
+
+	if s.activity != nil && report.Delivered > 0 {
+		metadata := map[string]interface{}{
+			"type":      strings.TrimSpace(payload.Type),
+			"delivered": report.Delivered,
+			"failed":    report.Failed,
+			"total":     report.Total,
+			"priority":  notificationPriority(payload.Priority),
+		}
+		if class != "" {
+			metadata["class"] = class
+		}
+		_, _ = s.activity.Record(ctx, ActivityEntry{
+			ActorID:    actor.ID,
+			ActorRole:  actor.Role,
+			Action:     "notification.batch_sent",
+			EntityType: "notification",
+			Metadata:   metadata,
+		})
+	}
+
+	return report, nil
+}
+
+func (s *adminNotificationService) classRecipients(ctx context.Context, class string) ([]dto.AdminNotificationBatchRowResult, error) {
+	students, _, err := s.students.List(ctx, repository.AdminStudentFilter{Class: class, Sort: "id ASC"})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]dto.AdminNotificationBatchRowResult, 0, len(students))
+	for _, student := range students {
+		rows = append(rows, dto.AdminNotificationBatchRowResult{UserID: strconv.FormatUint(uint64(student.ID), 10)})
+	}
+	return rows, nil
+}
+
+func parseNotificationRecipientsCSV(reader io.Reader) ([]dto.AdminNotificationBatchRowResult, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: file is empty", ErrAdminNotificationImportInvalid)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrAdminNotificationImportInvalid, err)
+	}
+
+	column := -1
+	for idx, name := range header {
+		if strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) == "user_id" {
+			column = idx
+			break
+		}
+	}
+	if column < 0 {
+		return nil, fmt.Errorf("%w: missing column %q", ErrAdminNotificationImportInvalid, "user_id")
+	}
+
+	seen := make(map[string]int)
+	rows := make([]dto.AdminNotificationBatchRowResult, 0)
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrAdminNotificationImportInvalid, err)
+		}
+
+		line, _ := csvReader.FieldPos(0)
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) >= maxNotificationBatchRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrAdminNotificationImportInvalid, maxNotificationBatchRows)
+		}
+
+		row := dto.AdminNotificationBatchRowResult{Line: line}
+		if column < len(record) {
+			row.UserID = strings.TrimSpace(record[column])
+		}
+
+		id, parseErr := strconv.ParseUint(row.UserID, 10, 64)
+		switch {
+		case row.UserID == "":
+			row.Error = "user_id is required"
+		case parseErr != nil || id == 0:
+			row.Error = fmt.Sprintf("invalid user_id %q", row.UserID)
+		default:
+			row.UserID = strconv.FormatUint(id, 10)
+			if first, ok := seen[row.UserID]; ok {
+				row.Error = fmt.Sprintf("duplicate of line %d", first)
+			} else {
+				seen[row.UserID] = line
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+func setupAdminNotificationService(t *testing.T) (*gorm.DB, NotificationService, AdminNotificationService, *stubActivityRecorder) {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:admin_notification_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Notification{}, &models.Student{}))
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	notifications := NewNotificationService(repository.NewNotificationRepository(db), nil, nil, "", nil, validate, testLogger())
+	activity := &stubActivityRecorder{}
+	svc := NewAdminNotificationService(notifications, repository.NewAdminStudentRepository(db), validate, activity, testLogger())
+	return db, notifications, svc, activity
+}
+
+const notificationRecipientsCSV = `user_id
+7
+abc
+
+8
+7
+0
+`
+
+func TestAdminNotificationServiceSendBatchFromCSV(t *testing.T) {
+	db, notifications, svc, activity := setupAdminNotificationService(t)
+
+	stream, cleanup := notifications.Subscribe("8")
+	defer cleanup()
+
+	report, err := svc.SendBatch(context.Background(), strings.NewReader(notificationRecipientsCSV), dto.AdminNotificationBatchRequest{
+		Type:    "announcement",
+		Message: "Lab closes early on Friday",
+	}, ActivityActor{ID: 3, Role: "admin"})
+	require.NoError(t, err)
+	require.Equal(t, 5, report.Total)
+	require.Equal(t, 2, report.Delivered)
+	require.Equal(t, 3, report.Failed)
+	require.Len(t, report.Rows, 5)
+
+	require.Equal(t, 2, report.Rows[0].Line)
+	require.NotNil(t, report.Rows[0].NotificationID)
+	require.Contains(t, report.Rows[1].Error, "invalid user_id")
+	require.Equal(t, 5, report.Rows[2].Line)
+	require.NotNil(t, report.Rows[2].NotificationID)
+	require.Equal(t, "duplicate of line 2", report.Rows[3].Error)
+	require.Nil(t, report.Rows[3].NotificationID)
+	require.Contains(t, report.Rows[4].Error, "invalid user_id")
+
+	var stored []models.Notification
+	require.NoError(t, db.Order("user_id").Find(&stored).Error)
+	require.Len(t, stored, 2)
+	require.Equal(t, "7", stored[0].UserID)
+	require.Equal(t, "8", stored[1].UserID)
+
+	select {
+	case delivered := <-stream:
+		require.Equal(t, "Lab closes early on Friday", delivered.Message)
+	case <-time.After(time.Second):
+		t.Fatal("notification was not broadcast to subscriber")
+	}
+
+	require.Len(t, activity.entries, 1)
+	require.Equal(t, "notification.batch_sent", activity.entries[0].Action)
+	require.Equal(t, 2, activity.entries[0].Metadata["delivered"])
+	require.Equal(t, 3, activity.entries[0].Metadata["failed"])
+}
+
+func TestAdminNotificationServiceSendBatchByClass(t *testing.T) {
+	db, _, svc, activity := setupAdminNotificationService(t)
+
+	students := []models.Student{
+		{Name: "A", Email: "a@example.com", Class: "XI-RPL"},
+		{Name: "B", Email: "b@example.com", Class: "XI-RPL"},
+		{Name: "C", Email: "c@example.com", Class: "XII-TKJ"},
+	}
+	require.NoError(t, db.Create(&students).Error)
+
+	report, err := svc.SendBatch(context.Background(), nil, dto.AdminNotificationBatchRequest{
+		Type:    "reminder",
+		Message: "Submit your project",
+		Class:   "XI-RPL",
+	}, ActivityActor{ID: 3, Role: "teacher"})
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Delivered)
+	require.Zero(t, report.Failed)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Notification{}).Where("user_id IN ?", []string{fmt.Sprint(students[0].ID), fmt.Sprint(students[1].ID)}).Count(&count).Error)
+	require.EqualValues(t, 2, count)
+	require.Equal(t, "XI-RPL", activity.entries[0].Metadata["class"])
+}
+
+func TestAdminNotificationServiceSendBatchValidation(t *testing.T) {
+	_, _, svc, activity := setupAdminNotificationService(t)
+	ctx := context.Background()
+	actor := ActivityActor{ID: 3, Role: "admin"}
+
+	_, err := svc.SendBatch(ctx, strings.NewReader("user_id\n1\n"), dto.AdminNotificationBatchRequest{Type: "info"}, actor)
+	require.Error(t, err)
+
+	_, err = svc.SendBatch(ctx, nil, dto.AdminNotificationBatchRequest{Type: "info", Message: "hi"}, actor)
+	require.ErrorIs(t, err, ErrAdminNotificationRecipientsRequired)
+
+	_, err = svc.SendBatch(ctx, strings.NewReader("id\n1\n"), dto.AdminNotificationBatchRequest{Type: "info", Message: "hi"}, actor)
+	require.ErrorIs(t, err, ErrAdminNotificationImportInvalid)
+
+	require.Empty(t, activity.entries)
+}
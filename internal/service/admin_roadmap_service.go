@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+// AdminRoadmapService manages roadmap stages. Every mutation drops the cached
+// public roadmap pages.
+type AdminRoadmapService interface {
+	Create(ctx context.Context, payload dto.AdminRoadmapStageRequest, actor ActivityActor) (dto.RoadmapStageResponse, error)
+	Update(ctx context.Context, id uint, payload dto.AdminRoadmapStageUpdateRequest, actor ActivityActor) (dto.RoadmapStageResponse, error)
+	Delete(ctx context.Context, id uint, actor ActivityActor) error
+	Reorder(ctx context.Context, payload dto.AdminRoadmapReorderRequest, actor ActivityActor) error
+}
+
+type adminRoadmapService struct {
+	repo      repository.RoadmapStageRepository
+	cache     cache.Store
+	validator *validator.Validate
+	activity  ActivityRecorder
+	logger    zerolog.Logger
+}
+
+// NewAdminRoadmapService constructs the roadmap admin service.
+func NewAdminRoadmapService(repo repository.RoadmapStageRepository, cache cache.Store, validator *validator.Validate, activity ActivityRecorder, logger zerolog.Logger) AdminRoadmapService {
+	return &adminRoadmapService{
+		repo:      repo,
+		cache:     cache,
+		validator: validator,
+		activity:  activity,
+		logger:    logger.With().Str("component", "admin_roadmap_service").Logger(),
+	}
+}
+
+func (s *adminRoadmapService) Create(ctx context.Context, payload dto.AdminRoadmapStageRequest, actor ActivityActor) (dto.RoadmapStageResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.RoadmapStageResponse{}, err
+	}
+
+	stage := models.RoadmapStage{
+		Slug:           generateContentSlug(payload.Title),
+		Title:          strings.TrimSpace(payload.Title),
+		Description:    strings.TrimSpace(payload.Description),
+		Sequence:       payload.Sequence,
+		EstimatedHours: payload.EstimatedHours,
+		Icon:           strings.TrimSpace(payload.Icon),
+		Tags:           sanitizeTags(payload.Tags),
+		Skills:         roadmapSkills(payload.Skills),
+	}
+
+	if err := s.repo.Create(ctx, &stage); err != nil {
+		return dto.RoadmapStageResponse{}, err
+	}
+
+	invalidateRoadmapCache(ctx, s.cache, s.logger)
+	s.recordActivity(ctx, actor, "roadmap_stage.created", &stage.ID, nil)
+	return toRoadmapStageResponse(stage), nil
+}
+
+func (s *adminRoadmapService) Update(ctx context.Context, id uint, payload dto.AdminRoadmapStageUpdateRequest, actor ActivityActor) (dto.RoadmapStageResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.RoadmapStageResponse{}, err
+	}
+
+	stage, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.RoadmapStageResponse{}, ErrRoadmapStageNotFound
+		}
+		return dto.RoadmapStageResponse{}, err
+	}
+
+	changedFields := make([]string, 0)
+	if payload.Title != nil {
+		stage.Title = strings.TrimSpace(*payload.Title)
+		changedFields = append(changedFields, "title")
+	}
+	if payload.Description != nil {
+		stage.Description = strings.TrimSpace(*payload.Description)
+		changedFields = append(changedFields, "description")
+	}
+	if payload.Sequence != nil {
+		stage.Sequence = *payload.Sequence
+		changedFields = append(changedFields, "sequence")
+	}
+	if payload.EstimatedHours != nil {
+		stage.EstimatedHours = *payload.EstimatedHours
+		changedFields = append(changedFields, "estimated_hours")
+	}
+	if payload.Icon != nil {
+		stage.Icon = strings.TrimSpace(*payload.Icon)
+		changedFields = append(changedFields, "icon")
+	}
+	if payload.Tags != nil {
+		stage.Tags = sanitizeTags(*payload.Tags)
+		changedFields = append(changedFields, "tags")
+	}
+	if payload.Skills != nil {
+		stage.Skills = roadmapSkills(*payload.Skills)
+		changedFields = append(changedFields, "skills")
+	}
+
+	if err := s.repo.Update(ctx, &stage); err != nil {
+		return dto.RoadmapStageResponse{}, err
+	}
+
+	invalidateRoadmapCache(ctx, s.cache, s.logger)
+	if len(changedFields) > 0 {
+		s.recordActivity(ctx, actor, "roadmap_stage.updated", &stage.ID, map[string]interface{}{"fields": changedFields})
+	}
+	return toRoadmapStageResponse(stage), nil
+}
+
+// Delete removes the stage and the progress students recorded on it.
+func (s *adminRoadmapService) Delete(ctx context.Context, id uint, actor ActivityActor) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoadmapStageNotFound
+		}
+		return err
+	}
+
+	invalidateRoadmapCache(ctx, s.cache, s.logger)
+	s.recordActivity(ctx, actor, "roadmap_stage.deleted", &id, nil)
+	return nil
+}
+
+// Reorder sets the sequence of the listed stages to their position in the
+// request. Stages not listed keep their current sequence.
+func (s *adminRoadmapService) Reorder(ctx context.Context, payload dto.AdminRoadmapReorderRequest, actor ActivityActor) error {
+	if err := s.validator.Struct(payload); err != nil {
+		return err
+	}
+
+	if err := s.repo.Reorder(ctx, payload.IDs); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoadmapStageNotFound
+		}
+		return err
+	}
+
+	invalidateRoadmapCache(ctx, s.cache, s.logger)
+	s.recordActivity(ctx, actor, "roadmap_stage.reordered", nil, map[string]interface{}{"ids": payload.IDs})
+	return nil
+}
+
+func (s *adminRoadmapService) recordActivity(ctx context.Context, actor ActivityActor, action string, id *uint, metadata map[string]interface{}) {
+	if s.activity == nil {
+		return
+	}
+	entry := ActivityEntry{
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+		Action:     action,
+		EntityType: "roadmap_stage",
+		EntityID:   id,
+		Metadata:   metadata,
+	}
+	if _, err := s.activity.Record(ctx, entry); err != nil {
+		s.logger.Warn().Err(err).Msg("failed to record roadmap activity")
+	}
+}
+
+func roadmapSkills(skills map[string]string) datatypes.JSONMap {
+	result := make(datatypes.JSONMap, len(skills))
+	for key, value := range skills {
+		if key = strings.TrimSpace(key); key != "" {
+			result[key] = strings.TrimSpace(value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+func TestAdminRoadmapServiceMutationsInvalidateCache(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:admin_roadmap_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.RoadmapStage{}, &models.RoadmapProgress{}))
+
+	store := cache.NewMemoryStore(10)
+	repo := repository.NewRoadmapStageRepository(db)
+	progress := repository.NewRoadmapProgressRepository(db)
+	recorder := &stubActivityRecorder{}
+	admin := NewAdminRoadmapService(repo, store, validator.New(validator.WithRequiredStructEnabled()), recorder, zerolog.Nop())
+	public := NewRoadmapService(repo, progress, store, time.Minute, zerolog.Nop())
+	ctx := context.Background()
+	actor := ActivityActor{ID: 1, Role: "admin"}
+	req := dto.RoadmapStageListRequest{PageSize: 10}
+
+	first, err := admin.Create(ctx, dto.AdminRoadmapStageRequest{Title: "Foundations", Sequence: 1, Skills: map[string]string{"html": "basic"}}, actor)
+	require.NoError(t, err)
+	require.Equal(t, "basic", first.Skills["html"])
+	second, err := admin.Create(ctx, dto.AdminRoadmapStageRequest{Title: "Frontend", Sequence: 2}, actor)
+	require.NoError(t, err)
+
+	_, err = public.ListStages(ctx, req)
+	require.NoError(t, err)
+	cached, err := public.ListStages(ctx, req)
+	require.NoError(t, err)
+	require.True(t, cached.CacheHit)
+
+	title := "Web Foundations"
+	updated, err := admin.Update(ctx, first.ID, dto.AdminRoadmapStageUpdateRequest{Title: &title}, actor)
+	require.NoError(t, err)
+	require.Equal(t, "Web Foundations", updated.Title)
+	require.Equal(t, 1, updated.Sequence, "absent fields are kept")
+
+	result, err := public.ListStages(ctx, req)
+	require.NoError(t, err)
+	require.False(t, result.CacheHit, "updates drop cached pages")
+	require.Equal(t, "Web Foundations", result.Items[0].Title)
+
+	require.NoError(t, admin.Reorder(ctx, dto.AdminRoadmapReorderRequest{IDs: []uint{second.ID, first.ID}}, actor))
+	result, err = public.ListStages(ctx, req)
+	require.NoError(t, err)
+	require.False(t, result.CacheHit)
+	require.Equal(t, second.ID, result.Items[0].ID)
+	require.ErrorIs(t, admin.Reorder(ctx, dto.AdminRoadmapReorderRequest{IDs: []uint{second.ID, 999}}, actor), ErrRoadmapStageNotFound)
+
+	_, err = public.MarkStageComplete(ctx, 5, first.ID)
+	require.NoError(t, err)
+	require.NoError(t, admin.Delete(ctx, first.ID, actor))
+	require.ErrorIs(t, admin.Delete(ctx, first.ID, actor), ErrRoadmapStageNotFound)
+
+	var remaining int64
+	require.NoError(t, db.Model(&models.RoadmapProgress{}).Where("stage_id = ?", first.ID).Count(&remaining).Error)
+	require.Zero(t, remaining, "progress on deleted stages is removed")
+
+	result, err = public.ListStages(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+
+	actions := make([]string, 0, len(recorder.entries))
+	for _, entry := range recorder.entries {
+		actions = append(actions, entry.Action)
+	}
+	require.Equal(t, []string{"roadmap_stage.created", "roadmap_stage.created", "roadmap_stage.updated", "roadmap_stage.reordered", "roadmap_stage.deleted"}, actions)
+}
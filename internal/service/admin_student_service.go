@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
+	"fmt"
+	"io"
 	"math"
 	"strings"
 
@@ -19,12 +22,18 @@ import (
 // ErrAdminStudentNotFound indicates the student was not found for admin operations.
 var ErrAdminStudentNotFound = errors.New("admin student not found")
 
+// ErrAdminStudentImportInvalid indicates the uploaded student CSV could not be parsed.
+var ErrAdminStudentImportInvalid = errors.New("invalid student import file")
+
+const maxStudentImportRows = 2000
+
 // AdminStudentService orchestrates admin student management use cases.
 type AdminStudentService interface {
 	List(ctx context.Context, req dto.AdminStudentListRequest) (dto.AdminStudentListResponse, error)
 	Get(ctx context.Context, id uint) (dto.AdminStudentResponse, error)
 	Update(ctx context.Context, id uint, payload dto.AdminStudentUpdateRequest, actor ActivityActor) (dto.AdminStudentResponse, error)
 	Delete(ctx context.Context, id uint, actor ActivityActor) error
+	Import(ctx context.Context, reader io.Reader, actor ActivityActor) (dto.AdminStudentImportResponse, error)
 }
 
 type adminStudentService struct {
@@ -103,7 +112,7 @@ func (s *adminStudentService) Update(ctx context.Context, id uint, payload dto.A
 		changedFields = append(changedFields, "name")
 	}
 	if payload.Email != nil {
-		updates["email"] = strings.TrimSpace(*payload.Email)
+		updates["email"] = models.NormalizeEmail(*payload.Email)
 		changedFields = append(changedFields, "email")
 	}
 	if payload.Class != nil {
@@ -194,6 +203,171 @@ func (s *adminStudentService) Delete(ctx context.Context, id uint, actor Activit
 	return nil
 }
 
+// Import upserts students from a CSV with the columns name, email and class,
+// matching existing students by email regardless of case. Invalid and
+// duplicate rows are reported by line and skipped; the valid rows are
+// written in one transaction, so either all of them are saved or none are.
+func (s *adminStudentService) Import(ctx context.Context, reader io.Reader, actor ActivityActor) (dto.AdminStudentImportResponse, error) {
+	rows, err := parseStudentImportCSV(reader)
+	if err != nil {
+		return dto.AdminStudentImportResponse{}, err
+	}
+
+	report := dto.AdminStudentImportResponse{
+		Total: len(rows),
+		Rows:  make([]dto.AdminStudentImportRowResult, 0, len(rows)),
+	}
+
+	seen := make(map[string]int, len(rows))
+	valid := make([]*models.Student, 0, len(rows))
+	validIdx := make([]int, 0, len(rows))
+	for _, row := range rows {
+		result := dto.AdminStudentImportRowResult{Line: row.line, Email: row.record.Email}
+		if err := s.validator.Struct(row.record); err != nil {
+			result.Error = studentImportError(err)
+		} else if first, ok := seen[models.NormalizeEmail(row.record.Email)]; ok {
+			result.Error = fmt.Sprintf("duplicate of line %d", first)
+		} else {
+			seen[models.NormalizeEmail(row.record.Email)] = row.line
+			valid = append(valid, &models.Student{
+				Name:   row.record.Name,
+				Email:  row.record.Email,
+				Class:  row.record.Class,
+				Status: models.StudentStatusActive,
+			})
+			validIdx = append(validIdx, len(report.Rows))
+		}
+		if result.Error != "" {
+			report.Failed++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	existing, err := s.repo.UpsertByEmail(ctx, valid)
+	if err != nil {
+		return dto.AdminStudentImportResponse{}, err
+	}
+	for i, student := range valid {
+		row := &report.Rows[validIdx[i]]
+		if existing[student.Email] {
+			row.Action = "updated"
+			report.Updated++
+		} else {
+			row.Action = "created"
+			report.Created++
+		}
+	}
+
+	if s.activity != nil && len(valid) > 0 {
+		_, _ = s.activity.Record(ctx, ActivityEntry{
+			ActorID:    actor.ID,
+			ActorRole:  actor.Role,
+			Action:     "student.imported",
+			EntityType: "student",
+			Metadata: map[string]interface{}{
+				"created": report.Created,
+				"updated": report.Updated,
+				"failed":  report.Failed,
+				"total":   report.Total,
+			},
+		})
+	}
+
+	return report, nil
+}
+
+type studentImportRow struct {
+	line   int
+	record dto.AdminStudentImportRecord
+}
+
+func parseStudentImportCSV(reader io.Reader) ([]studentImportRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: file is empty", ErrAdminStudentImportInvalid)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrAdminStudentImportInvalid, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for idx, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = idx
+	}
+	for _, required := range []string{"name", "email"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrAdminStudentImportInvalid, required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		idx, ok := columns[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	rows := make([]studentImportRow, 0)
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrAdminStudentImportInvalid, err)
+		}
+
+		line, _ := csvReader.FieldPos(0)
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) >= maxStudentImportRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrAdminStudentImportInvalid, maxStudentImportRows)
+		}
+
+		rows = append(rows, studentImportRow{
+			line: line,
+			record: dto.AdminStudentImportRecord{
+				Name:  field(record, "name"),
+				Email: field(record, "email"),
+				Class: field(record, "class"),
+			},
+		})
+	}
+
+	return rows, nil
+}
+
+// studentImportError turns validation failures into per-row messages an admin
+// can act on.
+func studentImportError(err error) string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err.Error()
+	}
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		field := strings.ToLower(fieldErr.Field())
+		switch fieldErr.Tag() {
+		case "required":
+			messages = append(messages, field+" is required")
+		case "email":
+			messages = append(messages, fmt.Sprintf("invalid email %q", fieldErr.Value()))
+		case "max":
+			messages = append(messages, fmt.Sprintf("%s exceeds %s characters", field, fieldErr.Param()))
+		default:
+			messages = append(messages, "invalid "+field)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
 func jsonMapFromBool(flags map[string]bool) datatypes.JSONMap {
 	data := datatypes.JSONMap{}
 	for key, value := range flags {
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+func TestAdminStudentImportUpsertsByEmail(t *testing.T) {
+	dsn := fmt.Sprintf("file:student_import_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Student{}))
+
+	existing := models.Student{Name: "Ayu", Email: "ayu@example.com", Class: "X-1", Status: models.StudentStatusActive, Notes: "keep"}
+	require.NoError(t, db.Create(&existing).Error)
+
+	activity := &stubActivityRecorder{}
+	svc := NewAdminStudentService(repository.NewAdminStudentRepository(db), validator.New(), activity, testLogger())
+
+	csvData := strings.Join([]string{
+		"name,email,class",
+		"Ayu Lestari,ayu@example.com,XI-1",
+		"Budi,budi@example.com,XI-1",
+		",citra@example.com,XI-2",
+		"Dewi,not-an-email,XI-2",
+		"",
+		"Budi Again,BUDI@example.com,XI-3",
+		"Eka,eka@example.com,",
+	}, "\n")
+
+	report, err := svc.Import(context.Background(), strings.NewReader(csvData), ActivityActor{ID: 1, Role: "admin"})
+	require.NoError(t, err)
+	require.Equal(t, 6, report.Total)
+	require.Equal(t, 2, report.Created)
+	require.Equal(t, 1, report.Updated)
+	require.Equal(t, 3, report.Failed)
+
+	require.Equal(t, "updated", report.Rows[0].Action)
+	require.Equal(t, "created", report.Rows[1].Action)
+	require.Equal(t, 4, report.Rows[2].Line)
+	require.Equal(t, "name is required", report.Rows[2].Error)
+	require.Equal(t, `invalid email "not-an-email"`, report.Rows[3].Error)
+	require.Equal(t, 7, report.Rows[4].Line)
+	require.Equal(t, "duplicate of line 3", report.Rows[4].Error)
+	require.Equal(t, "created", report.Rows[5].Action)
+
+	var ayu models.Student
+	require.NoError(t, db.Where("email = ?", "ayu@example.com").First(&ayu).Error)
+	require.Equal(t, existing.ID, ayu.ID)
+	require.Equal(t, "Ayu Lestari", ayu.Name)
+	require.Equal(t, "XI-1", ayu.Class)
+	require.Equal(t, "keep", ayu.Notes)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Student{}).Count(&count).Error)
+	require.Equal(t, int64(3), count)
+
+	require.Len(t, activity.entries, 1)
+	require.Equal(t, "student.imported", activity.entries[0].Action)
+	require.Equal(t, 2, activity.entries[0].Metadata["created"])
+	require.Equal(t, 1, activity.entries[0].Metadata["updated"])
+}
+
+func TestAdminStudentImportRejectsMalformedFile(t *testing.T) {
+	svc := NewAdminStudentService(nil, validator.New(), nil, testLogger())
+
+	_, err := svc.Import(context.Background(), strings.NewReader(""), ActivityActor{})
+	require.ErrorIs(t, err, ErrAdminStudentImportInvalid)
+
+	_, err = svc.Import(context.Background(), strings.NewReader("name,class\nAyu,X-1\n"), ActivityActor{})
+	require.ErrorIs(t, err, ErrAdminStudentImportInvalid)
+}
@@ -3,20 +3,20 @@ package service
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"math"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/microcosm-cc/bluemonday"
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/observability"
@@ -25,38 +25,45 @@ import (
 
 // AnnouncementService exposes public announcement operations.
 type AnnouncementService interface {
-	ListActive(ctx context.Context, page, pageSize int) (dto.AnnouncementListResponse, error)
+	ListActive(ctx context.Context, page, pageSize int, viewer dto.AnnouncementViewer) (dto.AnnouncementListResponse, error)
 	Seed(ctx context.Context, items []models.Announcement) (int64, error)
 }
 
 type announcementService struct {
 	repo   repository.AnnouncementRepository
-	cache  *redis.Client
-	ttl    time.Duration
+	cache  cache.Store
+	ttl    TTLSource
 	logger zerolog.Logger
 	policy *bluemonday.Policy
 	tracer trace.Tracer
+	clock  clock.Clock
 }
 
 // NewAnnouncementService constructs the announcement service.
-func NewAnnouncementService(repo repository.AnnouncementRepository, cache *redis.Client, ttl time.Duration, logger zerolog.Logger) AnnouncementService {
-	if ttl <= 0 {
-		ttl = 5 * time.Minute
-	}
-	policy := bluemonday.UGCPolicy()
-	policy.AllowElements("p", "strong", "em", "a", "ul", "ol", "li", "br")
-	policy.AllowAttrs("href", "title", "target").OnElements("a")
+func NewAnnouncementService(repo repository.AnnouncementRepository, cache cache.Store, ttl TTLSource, logger zerolog.Logger) AnnouncementService {
 	return &announcementService{
 		repo:   repo,
 		cache:  cache,
 		ttl:    ttl,
 		logger: logger.With().Str("component", "announcement_service").Logger(),
-		policy: policy,
+		policy: announcementPolicy(),
 		tracer: otel.Tracer("github.com/noah-isme/gema-go-api/internal/service/announcement"),
+		clock:  clock.Real(),
 	}
 }
 
-func (s *announcementService) ListActive(ctx context.Context, page, pageSize int) (dto.AnnouncementListResponse, error) {
+// announcementPolicy is the HTML allowed in announcement bodies, applied when
+// admins write them and again when they are served.
+func announcementPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	policy.AllowElements("p", "strong", "em", "a", "ul", "ol", "li", "br")
+	policy.AllowAttrs("href", "title", "target").OnElements("a")
+	return policy
+}
+
+// ListActive returns the announcements visible to viewer: those for everyone
+// plus those targeted at the viewer's class or role.
+func (s *announcementService) ListActive(ctx context.Context, page, pageSize int, viewer dto.AnnouncementViewer) (dto.AnnouncementListResponse, error) {
 	ctx, span := s.tracer.Start(ctx, "announcements.fetch", trace.WithAttributes(
 		attribute.Int("announcements.page", maxInt(page, 1)),
 		attribute.Int("announcements.page_size", clampPageSize(pageSize)),
@@ -74,12 +81,13 @@ func (s *announcementService) ListActive(ctx context.Context, page, pageSize int
 	span.SetAttributes(
 		attribute.Int("announcements.normalized_page", page),
 		attribute.Int("announcements.normalized_page_size", pageSize),
+		attribute.Bool("announcements.targeted", len(viewer.Audience()) > 0),
 	)
 
 	cacheKey := ""
 	if s.cache != nil {
-		cacheKey = fmt.Sprintf("announcements:active:v1:%d:%d", page, pageSize)
-		if cached, err := s.cache.Get(ctx, cacheKey).Result(); err == nil && cached != "" {
+		cacheKey = announcementListCacheKey(viewer, page, pageSize)
+		if cached, err := s.cache.Get(ctx, cacheKey); err == nil && cached != "" {
 			var response dto.AnnouncementListResponse
 			if err := json.Unmarshal([]byte(cached), &response); err == nil {
 				response.CacheHit = true
@@ -93,7 +101,8 @@ func (s *announcementService) ListActive(ctx context.Context, page, pageSize int
 		}
 	}
 
-	items, total, err := s.repo.ListActive(ctx, repository.AnnouncementFilter{Page: page, PageSize: pageSize})
+	now := s.clock.Now()
+	items, total, err := s.repo.ListActive(ctx, repository.AnnouncementFilter{Page: page, PageSize: pageSize, Now: now, Audience: viewer.Audience()})
 	if err != nil {
 		observability.AnnouncementsRequests().WithLabelValues("error").Inc()
 		span.RecordError(err)
@@ -134,13 +143,9 @@ func (s *announcementService) ListActive(ctx context.Context, page, pageSize int
 
 	response := dto.AnnouncementListResponse{Items: responses, Pagination: pagination}
 
-	if cacheKey != "" && s.cache != nil {
-		if payload, err := json.Marshal(response); err == nil {
-			if err := s.cache.Set(ctx, cacheKey, payload, s.ttl).Err(); err != nil {
-				s.logger.Warn().Err(err).Msg("failed to cache announcements")
-				span.RecordError(err)
-			}
-		} else {
+	if cacheKey != "" {
+		if err := cache.Write(ctx, s.cache, "announcements", cacheKey, response, s.cacheTTL(ctx, now, items)); err != nil {
+			s.logger.Warn().Err(err).Msg("failed to cache announcements")
 			span.RecordError(err)
 		}
 	}
@@ -152,15 +157,41 @@ func (s *announcementService) ListActive(ctx context.Context, page, pageSize int
 	return response, nil
 }
 
+// cacheTTL shortens the list TTL so a cached page never outlives the next
+// announcement expiring or a scheduled one going live.
+func (s *announcementService) cacheTTL(ctx context.Context, now time.Time, items []models.Announcement) time.Duration {
+	ttl := s.ttl()
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	limit := func(at time.Time) {
+		// The window is inclusive, so the page changes just after at.
+		if remaining := at.Sub(now) + time.Second; remaining < ttl {
+			ttl = remaining
+		}
+	}
+	for _, item := range items {
+		if item.EndsAt != nil {
+			limit(*item.EndsAt)
+		}
+	}
+	next, err := s.repo.NextStart(ctx, now)
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("failed to look up next scheduled announcement")
+	} else if next != nil {
+		limit(*next)
+	}
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return ttl
+}
+
 func (s *announcementService) Seed(ctx context.Context, items []models.Announcement) (int64, error) {
 	affected, err := s.repo.UpsertBatch(ctx, items)
 	if err != nil {
 		return 0, err
 	}
-	if s.cache != nil {
-		if err := s.cache.FlushDB(ctx).Err(); err != nil {
-			s.logger.Warn().Err(err).Msg("failed to flush announcements cache")
-		}
-	}
+	invalidateAnnouncementCache(ctx, s.cache, s.logger)
 	return affected, nil
 }
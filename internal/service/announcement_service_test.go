@@ -2,13 +2,19 @@ package service
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/validator/v10"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
 )
@@ -26,6 +32,57 @@ func (a *announcementRepoStub) UpsertBatch(ctx context.Context, items []models.A
 	return int64(len(items)), nil
 }
 
+func (a *announcementRepoStub) ExistingSlugs(ctx context.Context, slugs []string) ([]string, error) {
+	var existing []string
+	for _, item := range a.items {
+		for _, slug := range slugs {
+			if item.Slug == slug {
+				existing = append(existing, slug)
+				break
+			}
+		}
+	}
+	return existing, nil
+}
+
+func (a *announcementRepoStub) ListAll(ctx context.Context, filter repository.AdminAnnouncementFilter) ([]models.Announcement, int64, error) {
+	return a.items, int64(len(a.items)), nil
+}
+
+func (a *announcementRepoStub) Create(ctx context.Context, announcement *models.Announcement) error {
+	announcement.ID = uint(len(a.items) + 1)
+	a.items = append(a.items, *announcement)
+	return nil
+}
+
+func (a *announcementRepoStub) GetByID(ctx context.Context, id uint) (models.Announcement, error) {
+	for _, item := range a.items {
+		if item.ID == id {
+			return item, nil
+		}
+	}
+	return models.Announcement{}, gorm.ErrRecordNotFound
+}
+
+func (a *announcementRepoStub) Update(ctx context.Context, announcement *models.Announcement) error {
+	for i, item := range a.items {
+		if item.ID == announcement.ID {
+			a.items[i] = *announcement
+		}
+	}
+	return nil
+}
+
+func (a *announcementRepoStub) NextStart(ctx context.Context, after time.Time) (*time.Time, error) {
+	var next *time.Time
+	for i, item := range a.items {
+		if item.StartsAt.After(after) && (next == nil || item.StartsAt.Before(*next)) {
+			next = &a.items[i].StartsAt
+		}
+	}
+	return next, nil
+}
+
 func TestAnnouncementServiceCachingAndSanitize(t *testing.T) {
 	server, err := miniredis.Run()
 	require.NoError(t, err)
@@ -43,9 +100,9 @@ func TestAnnouncementServiceCachingAndSanitize(t *testing.T) {
 		IsPinned: false,
 	}}}
 
-	svc := NewAnnouncementService(repo, redisClient, time.Minute, testLogger())
+	svc := NewAnnouncementService(repo, cache.NewRedisStore(redisClient), FixedTTL(time.Minute), testLogger())
 
-	resp, err := svc.ListActive(context.Background(), 1, 10)
+	resp, err := svc.ListActive(context.Background(), 1, 10, dto.AnnouncementViewer{})
 	require.NoError(t, err)
 	require.False(t, resp.CacheHit)
 	require.Len(t, resp.Items, 1)
@@ -53,7 +110,7 @@ func TestAnnouncementServiceCachingAndSanitize(t *testing.T) {
 	require.Equal(t, "<p>Safe</p>", resp.Items[0].Body)
 
 	repo.items = nil
-	cached, err := svc.ListActive(context.Background(), 1, 10)
+	cached, err := svc.ListActive(context.Background(), 1, 10, dto.AnnouncementViewer{})
 	require.NoError(t, err)
 	require.True(t, cached.CacheHit)
 	require.Len(t, cached.Items, 1)
@@ -65,10 +122,123 @@ func TestAnnouncementServicePinnedOrdering(t *testing.T) {
 		{ID: 2, Title: "Pinned", Body: "ok", StartsAt: time.Now().Add(-48 * time.Hour), IsPinned: true},
 	}}
 
-	svc := NewAnnouncementService(repo, nil, time.Minute, testLogger())
+	svc := NewAnnouncementService(repo, nil, FixedTTL(time.Minute), testLogger())
 
-	resp, err := svc.ListActive(context.Background(), 1, 10)
+	resp, err := svc.ListActive(context.Background(), 1, 10, dto.AnnouncementViewer{})
 	require.NoError(t, err)
 	require.Len(t, resp.Items, 2)
 	require.Equal(t, "Pinned", resp.Items[0].Title)
 }
+
+func TestAdminAnnouncementCreateInvalidatesCachedLists(t *testing.T) {
+	ctx := context.Background()
+	store := cache.NewMemoryStore(16)
+	repo := &announcementRepoStub{items: []models.Announcement{{ID: 1, Title: "Hello", Body: "ok", StartsAt: time.Now().Add(-time.Hour)}}}
+	public := NewAnnouncementService(repo, store, FixedTTL(time.Minute), testLogger())
+	admin := NewAdminAnnouncementService(repo, store, validator.New(), nil, testLogger())
+
+	for _, pageSize := range []int{10, 20} {
+		_, err := public.ListActive(ctx, 1, pageSize, dto.AnnouncementViewer{})
+		require.NoError(t, err)
+		cached, err := store.Get(ctx, announcementListCacheKey(dto.AnnouncementViewer{}, 1, pageSize))
+		require.NoError(t, err)
+		require.NotEmpty(t, cached)
+	}
+
+	_, err := admin.Create(ctx, dto.AdminAnnouncementRequest{
+		Title:    "Libur",
+		Body:     "Sekolah libur pada hari Jumat.",
+		StartsAt: time.Now().Add(-time.Minute).Format(time.RFC3339),
+	}, ActivityActor{ID: 1, Role: "admin"})
+	require.NoError(t, err)
+
+	for _, pageSize := range []int{10, 20} {
+		cached, _ := store.Get(ctx, announcementListCacheKey(dto.AnnouncementViewer{}, 1, pageSize))
+		require.Empty(t, cached)
+	}
+
+	resp, err := public.ListActive(ctx, 1, 10, dto.AnnouncementViewer{})
+	require.NoError(t, err)
+	require.False(t, resp.CacheHit)
+	require.Len(t, resp.Items, 2)
+}
+
+func TestAdminAnnouncementScheduleAndUpdate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, time.June, 1, 8, 0, 0, 0, time.UTC)
+	store := cache.NewMemoryStore(16)
+	repo := &announcementRepoStub{}
+	svc := NewAdminAnnouncementService(repo, store, validator.New(), nil, testLogger())
+	svc.(*adminAnnouncementService).clock = clock.NewFixed(now)
+	actor := ActivityActor{ID: 1, Role: "admin"}
+
+	_, err := svc.Create(ctx, dto.AdminAnnouncementRequest{
+		Title:    "Ujian",
+		Body:     "Ujian tengah semester dimulai Senin.",
+		StartsAt: now.Add(2 * time.Hour).Format(time.RFC3339),
+		EndsAt:   now.Add(time.Hour).Format(time.RFC3339),
+	}, actor)
+	require.ErrorIs(t, err, ErrAnnouncementInvalidSchedule)
+
+	created, err := svc.Create(ctx, dto.AdminAnnouncementRequest{
+		Title:    "Ujian",
+		Body:     "<script>alert(1)</script><p>Ujian tengah semester dimulai Senin.</p>",
+		StartsAt: now.Add(2 * time.Hour).Format(time.RFC3339),
+		EndsAt:   now.Add(24 * time.Hour).Format(time.RFC3339),
+	}, actor)
+	require.NoError(t, err)
+	require.Equal(t, models.AnnouncementStatusScheduled, created.Status)
+	require.Equal(t, "<p>Ujian tengah semester dimulai Senin.</p>", created.Body)
+
+	require.NoError(t, store.Set(ctx, announcementListCacheKey(dto.AnnouncementViewer{}, 1, 10), []byte("{}"), time.Minute))
+	startsAt := now.Add(-time.Hour).Format(time.RFC3339)
+	updated, err := svc.Update(ctx, created.ID, dto.AdminAnnouncementUpdateRequest{StartsAt: &startsAt}, actor)
+	require.NoError(t, err)
+	require.Equal(t, models.AnnouncementStatusActive, updated.Status)
+	cached, _ := store.Get(ctx, announcementListCacheKey(dto.AnnouncementViewer{}, 1, 10))
+	require.Empty(t, cached)
+
+	endsAt := now.Add(-time.Minute).Format(time.RFC3339)
+	expired, err := svc.Update(ctx, created.ID, dto.AdminAnnouncementUpdateRequest{EndsAt: &endsAt}, actor)
+	require.NoError(t, err)
+	require.Equal(t, models.AnnouncementStatusExpired, expired.Status)
+
+	cleared := ""
+	reopened, err := svc.Update(ctx, created.ID, dto.AdminAnnouncementUpdateRequest{EndsAt: &cleared}, actor)
+	require.NoError(t, err)
+	require.Nil(t, reopened.EndsAt)
+	require.Equal(t, models.AnnouncementStatusActive, reopened.Status)
+
+	_, err = svc.Update(ctx, created.ID+10, dto.AdminAnnouncementUpdateRequest{}, actor)
+	require.ErrorIs(t, err, ErrAdminAnnouncementNotFound)
+
+	_, err = svc.List(ctx, dto.AdminAnnouncementListRequest{Status: "archived"})
+	require.Error(t, err)
+}
+
+func TestAnnouncementCacheTTLStopsAtNextTransition(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 8, 0, 0, 0, time.UTC)
+	endsAt := now.Add(20 * time.Second)
+	repo := &announcementRepoStub{items: []models.Announcement{
+		{ID: 1, Title: "Ending", StartsAt: now.Add(-time.Hour), EndsAt: &endsAt},
+		{ID: 2, Title: "Later", StartsAt: now.Add(10 * time.Second)},
+	}}
+	svc := NewAnnouncementService(repo, nil, FixedTTL(time.Minute), testLogger()).(*announcementService)
+
+	require.Equal(t, 11*time.Second, svc.cacheTTL(context.Background(), now, repo.items[:1]))
+
+	repo.items = repo.items[:1]
+	require.Equal(t, 21*time.Second, svc.cacheTTL(context.Background(), now, repo.items))
+	require.Equal(t, time.Minute, svc.cacheTTL(context.Background(), now, nil))
+}
+
+func TestAnnouncementListCacheKeySeparatesAudiences(t *testing.T) {
+	anonymous := announcementListCacheKey(dto.AnnouncementViewer{}, 1, 10)
+	student := announcementListCacheKey(dto.AnnouncementViewer{Role: "student", Class: "XI-1"}, 1, 10)
+	other := announcementListCacheKey(dto.AnnouncementViewer{Role: "student", Class: "XI-2"}, 1, 10)
+
+	require.NotEqual(t, anonymous, student)
+	require.NotEqual(t, student, other)
+	require.Equal(t, student, announcementListCacheKey(dto.AnnouncementViewer{Role: " Student", Class: "xi-1 "}, 1, 10))
+	require.True(t, strings.HasPrefix(student, announcementsCachePrefix))
+}
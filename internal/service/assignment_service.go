@@ -14,7 +14,9 @@ import (
 	"github.com/rs/zerolog"
 	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/logging"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
 )
@@ -41,7 +43,7 @@ type assignmentService struct {
 	validator *validator.Validate
 	uploader  FileUploader
 	logger    zerolog.Logger
-	now       func() time.Time
+	clock     clock.Clock
 }
 
 // NewAssignmentService builds a new assignment service.
@@ -51,7 +53,7 @@ func NewAssignmentService(repo repository.AssignmentRepository, validate *valida
 		validator: validate,
 		uploader:  uploader,
 		logger:    logger.With().Str("component", "assignment_service").Logger(),
-		now:       time.Now,
+		clock:     clock.Real(),
 	}
 }
 
@@ -115,7 +117,7 @@ func (s *assignmentService) Create(ctx context.Context, payload dto.AssignmentCr
 		return dto.AssignmentResponse{}, fmt.Errorf("invalid due date: %w", err)
 	}
 
-	if !dueDate.After(s.now()) {
+	if !dueDate.After(s.clock.Now()) {
 		return dto.AssignmentResponse{}, fmt.Errorf("due date must be in the future")
 	}
 
@@ -137,7 +139,7 @@ func (s *assignmentService) Create(ctx context.Context, payload dto.AssignmentCr
 		return dto.AssignmentResponse{}, err
 	}
 
-	s.logger.Info().Uint("assignment_id", assignment.ID).Msg("assignment created")
+	logging.FromContext(ctx, s.logger).Info().Uint("assignment_id", assignment.ID).Msg("assignment created")
 
 	return dto.NewAssignmentResponse(assignment), nil
 }
@@ -170,7 +172,7 @@ func (s *assignmentService) Update(ctx context.Context, id uint, payload dto.Ass
 			return dto.AssignmentResponse{}, fmt.Errorf("invalid due date: %w", err)
 		}
 
-		if !dueDate.After(s.now()) {
+		if !dueDate.After(s.clock.Now()) {
 			return dto.AssignmentResponse{}, fmt.Errorf("due date must be in the future")
 		}
 
@@ -189,7 +191,7 @@ func (s *assignmentService) Update(ctx context.Context, id uint, payload dto.Ass
 		return dto.AssignmentResponse{}, err
 	}
 
-	s.logger.Info().Uint("assignment_id", assignment.ID).Msg("assignment updated")
+	logging.FromContext(ctx, s.logger).Info().Uint("assignment_id", assignment.ID).Msg("assignment updated")
 
 	return dto.NewAssignmentResponse(assignment), nil
 }
@@ -202,7 +204,7 @@ func (s *assignmentService) Delete(ctx context.Context, id uint) error {
 		return err
 	}
 
-	s.logger.Info().Uint("assignment_id", id).Msg("assignment deleted")
+	logging.FromContext(ctx, s.logger).Info().Uint("assignment_id", id).Msg("assignment deleted")
 	return nil
 }
 
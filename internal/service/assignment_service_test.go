@@ -95,6 +95,15 @@ func (m *memoryAssignmentRepo) Create(ctx context.Context, assignment *models.As
 	return nil
 }
 
+func (m *memoryAssignmentRepo) CreateBatch(ctx context.Context, assignments []*models.Assignment) error {
+	for _, assignment := range assignments {
+		if err := m.Create(ctx, assignment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *memoryAssignmentRepo) Update(ctx context.Context, assignment *models.Assignment) error {
 	if _, ok := m.assignments[assignment.ID]; !ok {
 		return gorm.ErrRecordNotFound
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/logging"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+var (
+	// ErrInvalidRefreshToken indicates a refresh token that is malformed,
+	// expired or not signed with a refresh secret.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenRevoked indicates a refresh token that was already
+	// rotated or revoked. Replaying one suggests it was stolen.
+	ErrRefreshTokenRevoked = errors.New("refresh token has been revoked")
+	// ErrRefreshAccountInactive indicates a refresh token whose account was
+	// deleted, deactivated or given another role since the token was issued.
+	ErrRefreshAccountInactive = errors.New("account is no longer allowed to refresh this session")
+	// ErrTokenNotRevocable indicates an access token without an ID or expiry,
+	// which cannot be denylisted.
+	ErrTokenNotRevocable = errors.New("token cannot be revoked")
+)
+
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+	// refreshFamilyClaim carries the ID shared by every refresh token rotated
+	// from the same login, so a replay can revoke all of them.
+	refreshFamilyClaim = "fam"
+)
+
+// AuthAccount is the current state of the account behind a token.
+type AuthAccount struct {
+	Role   string
+	Class  string
+	Active bool
+}
+
+// AuthAccountLookup reloads the account a refresh token was issued to, so a
+// refresh reflects deletions and role changes made since the token was
+// signed. It returns gorm.ErrRecordNotFound for unknown accounts.
+type AuthAccountLookup interface {
+	LookupAccount(ctx context.Context, userID uint, role string) (AuthAccount, error)
+}
+
+type studentAccountLookup struct {
+	students repository.StudentRepository
+}
+
+// NewStudentAccountLookup resolves student tokens against the students table.
+// Staff accounts are not stored here, so tokens for other roles keep the
+// claims they were issued with.
+func NewStudentAccountLookup(students repository.StudentRepository) AuthAccountLookup {
+	return &studentAccountLookup{students: students}
+}
+
+func (l *studentAccountLookup) LookupAccount(ctx context.Context, userID uint, role string) (AuthAccount, error) {
+	if role != "student" {
+		return AuthAccount{Role: role, Active: true}, nil
+	}
+	student, err := l.students.GetByID(ctx, userID)
+	if err != nil {
+		return AuthAccount{}, err
+	}
+	return AuthAccount{Role: "student", Class: student.Class, Active: student.IsActive()}, nil
+}
+
+// AuthTokenConfig holds the signing secrets and lifetimes of issued tokens.
+// Access tokens are signed with AccessSecret, which JWTProtected verifies.
+// Refresh tokens are signed with RefreshSecret; PreviousRefreshSecret is still
+// accepted so refresh tokens survive a secret rotation.
+type AuthTokenConfig struct {
+	AccessSecret          string
+	RefreshSecret         string
+	PreviousRefreshSecret string
+	AccessTTL             time.Duration
+	RefreshTTL            time.Duration
+}
+
+// AuthService renews sessions by rotating refresh tokens and ends them by
+// revoking tokens.
+type AuthService interface {
+	Refresh(ctx context.Context, req dto.AuthRefreshRequest) (dto.AuthTokenResponse, error)
+	Logout(ctx context.Context, req dto.AuthLogoutRequest) error
+}
+
+type authService struct {
+	config         AuthTokenConfig
+	accounts       AuthAccountLookup
+	refreshRevoked *TokenDenylist
+	familyRevoked  *TokenDenylist
+	accessRevoked  *TokenDenylist
+	validator      *validator.Validate
+	logger         zerolog.Logger
+	clock          clock.Clock
+}
+
+// NewAuthService constructs the token refresh and logout service. Rotated
+// refresh tokens are denylisted in redisClient. Revoked access tokens go to
+// accessDenylist, which must be the one JWTProtected checks; nil creates one
+// on redisClient. accounts reloads the account on every refresh; nil keeps the
+// claims of the presented token.
+func NewAuthService(config AuthTokenConfig, accounts AuthAccountLookup, redisClient *redis.Client, accessDenylist *TokenDenylist, validator *validator.Validate, logger zerolog.Logger) AuthService {
+	if config.AccessTTL <= 0 {
+		config.AccessTTL = defaultAccessTokenTTL
+	}
+	if config.RefreshTTL <= 0 {
+		config.RefreshTTL = defaultRefreshTokenTTL
+	}
+	if accessDenylist == nil {
+		accessDenylist = NewTokenDenylist(redisClient, AccessTokenDenylistPrefix)
+	}
+	return &authService{
+		config:         config,
+		accounts:       accounts,
+		refreshRevoked: NewTokenDenylist(redisClient, refreshTokenDenylistPrefix),
+		familyRevoked:  NewTokenDenylist(redisClient, refreshFamilyDenylistPrefix),
+		accessRevoked:  accessDenylist,
+		validator:      validator,
+		logger:         logger.With().Str("component", "auth_service").Logger(),
+		clock:          clock.Real(),
+	}
+}
+
+// Refresh verifies the refresh token, reloads its account and issues a new
+// access and refresh token for the same subject. Each refresh token can be
+// used once: it is revoked once its replacement is signed, and presenting it
+// again revokes every token rotated from the same login.
+func (s *authService) Refresh(ctx context.Context, req dto.AuthRefreshRequest) (dto.AuthTokenResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return dto.AuthTokenResponse{}, err
+	}
+
+	claims, jti, expiresAt, err := s.parseRefreshToken(strings.TrimSpace(req.RefreshToken))
+	if err != nil {
+		return dto.AuthTokenResponse{}, err
+	}
+
+	// Tokens from before families were tracked start one at their own ID.
+	family, _ := claims[refreshFamilyClaim].(string)
+	if family == "" {
+		family = jti
+	}
+	familyRevoked, err := s.familyRevoked.IsRevoked(ctx, family)
+	if err != nil {
+		return dto.AuthTokenResponse{}, fmt.Errorf("check refresh token family: %w", err)
+	}
+	if familyRevoked {
+		return dto.AuthTokenResponse{}, ErrRefreshTokenRevoked
+	}
+
+	identity, err := s.reloadIdentity(ctx, claims)
+	if err != nil {
+		return dto.AuthTokenResponse{}, err
+	}
+	identity[refreshFamilyClaim] = family
+
+	tokens, err := s.issue(identity)
+	if err != nil {
+		return dto.AuthTokenResponse{}, err
+	}
+
+	now := s.clock.Now()
+	revoked, err := s.refreshRevoked.Revoke(ctx, jti, expiresAt.Sub(now))
+	if err != nil {
+		return dto.AuthTokenResponse{}, fmt.Errorf("revoke refresh token: %w", err)
+	}
+	if !revoked {
+		// Either the client or whoever copied the token has already used it,
+		// and there is no telling which. Ending the whole family logs both out.
+		// Tokens issued from now on expire within RefreshTTL.
+		if _, err := s.familyRevoked.Revoke(ctx, family, s.config.RefreshTTL); err != nil {
+			return dto.AuthTokenResponse{}, fmt.Errorf("revoke refresh token family: %w", err)
+		}
+		logging.FromContext(ctx, s.logger).Warn().Str("jti", jti).Str("family", family).Interface("sub", claims["sub"]).Msg("refresh token reused; family revoked")
+		return dto.AuthTokenResponse{}, ErrRefreshTokenRevoked
+	}
+
+	return tokens, nil
+}
+
+// reloadIdentity returns the identity claims for the replacement tokens,
+// refreshed from the account's current state. A deleted or deactivated
+// account, or one whose role changed, must log in again.
+func (s *authService) reloadIdentity(ctx context.Context, claims jwt.MapClaims) (jwt.MapClaims, error) {
+	identity := jwt.MapClaims{}
+	for key, value := range claims {
+		identity[key] = value
+	}
+	if s.accounts == nil {
+		return identity, nil
+	}
+
+	userID, ok := tokenSubject(claims)
+	if !ok {
+		return nil, ErrInvalidRefreshToken
+	}
+	role, _ := claims["role"].(string)
+	role = strings.ToLower(strings.TrimSpace(role))
+
+	account, err := s.accounts.LookupAccount(ctx, userID, role)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRefreshAccountInactive
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load refresh token account: %w", err)
+	}
+	if !account.Active || !strings.EqualFold(account.Role, role) {
+		return nil, ErrRefreshAccountInactive
+	}
+	if account.Class != "" {
+		identity["class"] = account.Class
+	}
+	return identity, nil
+}
+
+// tokenSubject reads the numeric user ID from the claims JWTProtected takes it
+// from.
+func tokenSubject(claims jwt.MapClaims) (uint, bool) {
+	for _, key := range []string{"sub", "user_id", "id"} {
+		switch value := claims[key].(type) {
+		case string:
+			if parsed, err := strconv.ParseUint(value, 10, 64); err == nil {
+				return uint(parsed), true
+			}
+		case float64:
+			if value >= 0 {
+				return uint(value), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Logout revokes the caller's access token until it expires and, when one is
+// given, the refresh token of the same session.
+func (s *authService) Logout(ctx context.Context, req dto.AuthLogoutRequest) error {
+	if req.TokenID == "" || req.ExpiresAt.IsZero() {
+		return ErrTokenNotRevocable
+	}
+
+	var refreshID string
+	var refreshExpiresAt time.Time
+	if token := strings.TrimSpace(req.RefreshToken); token != "" {
+		_, jti, expiresAt, err := s.parseRefreshToken(token)
+		if err != nil {
+			return err
+		}
+		refreshID, refreshExpiresAt = jti, expiresAt
+	}
+
+	now := s.clock.Now()
+	if _, err := s.accessRevoked.Revoke(ctx, req.TokenID, req.ExpiresAt.Sub(now)); err != nil {
+		return fmt.Errorf("revoke access token: %w", err)
+	}
+	if refreshID != "" {
+		if _, err := s.refreshRevoked.Revoke(ctx, refreshID, refreshExpiresAt.Sub(now)); err != nil {
+			return fmt.Errorf("revoke refresh token: %w", err)
+		}
+	}
+
+	logging.FromContext(ctx, s.logger).Info().Str("jti", req.TokenID).Bool("refresh_revoked", refreshID != "").Msg("session logged out")
+	return nil
+}
+
+// parseRefreshToken accepts tokens signed with the current or previous
+// refresh secret that are typed as refresh tokens and carry an ID and expiry.
+func (s *authService) parseRefreshToken(token string) (jwt.MapClaims, string, time.Time, error) {
+	claims, err := s.verifyRefreshToken(token)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+
+	jti, _ := claims["jti"].(string)
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil || jti == "" {
+		return nil, "", time.Time{}, ErrInvalidRefreshToken
+	}
+	return claims, jti, expiresAt.Time, nil
+}
+
+func (s *authService) verifyRefreshToken(token string) (jwt.MapClaims, error) {
+	for _, secret := range []string{s.config.RefreshSecret, s.config.PreviousRefreshSecret} {
+		if secret == "" {
+			continue
+		}
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+			return []byte(secret), nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired(), jwt.WithTimeFunc(s.clock.Now))
+		if err != nil {
+			continue
+		}
+		if typ, _ := claims["typ"].(string); typ != tokenTypeRefresh {
+			return nil, ErrInvalidRefreshToken
+		}
+		return claims, nil
+	}
+	return nil, ErrInvalidRefreshToken
+}
+
+// issue signs a new token pair carrying the identity claims of the refresh
+// token it replaces.
+func (s *authService) issue(identity jwt.MapClaims) (dto.AuthTokenResponse, error) {
+	now := s.clock.Now()
+	accessExpiresAt := now.Add(s.config.AccessTTL)
+	refreshExpiresAt := now.Add(s.config.RefreshTTL)
+
+	access, err := s.sign(identity, tokenTypeAccess, now, accessExpiresAt, s.config.AccessSecret)
+	if err != nil {
+		return dto.AuthTokenResponse{}, err
+	}
+	refresh, err := s.sign(identity, tokenTypeRefresh, now, refreshExpiresAt, s.config.RefreshSecret)
+	if err != nil {
+		return dto.AuthTokenResponse{}, err
+	}
+
+	return dto.AuthTokenResponse{
+		AccessToken:           access,
+		TokenType:             "Bearer",
+		ExpiresIn:             int64(s.config.AccessTTL / time.Second),
+		AccessTokenExpiresAt:  accessExpiresAt.UTC(),
+		RefreshToken:          refresh,
+		RefreshTokenExpiresAt: refreshExpiresAt.UTC(),
+	}, nil
+}
+
+func (s *authService) sign(identity jwt.MapClaims, tokenType string, issuedAt, expiresAt time.Time, secret string) (string, error) {
+	claims := jwt.MapClaims{
+		"jti": uuid.NewString(),
+		"typ": tokenType,
+		"iat": issuedAt.Unix(),
+		"exp": expiresAt.Unix(),
+	}
+	for _, key := range []string{"sub", "user_id", "id", "role", "roles", "class", refreshFamilyClaim} {
+		if value, ok := identity[key]; ok {
+			claims[key] = value
+		}
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+)
+
+const (
+	testAccessSecret  = "access-secret"
+	testRefreshSecret = "refresh-secret"
+)
+
+func signTestRefreshToken(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestAuthServiceRefreshRotatesTokens(t *testing.T) {
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	defer server.Close()
+
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+
+	svc := NewAuthService(AuthTokenConfig{
+		AccessSecret:          testAccessSecret,
+		RefreshSecret:         testRefreshSecret,
+		PreviousRefreshSecret: "old-refresh-secret",
+		AccessTTL:             10 * time.Minute,
+		RefreshTTL:            time.Hour,
+	}, nil, redisClient, nil, validator.New(), testLogger())
+
+	refreshToken := signTestRefreshToken(t, "old-refresh-secret", jwt.MapClaims{
+		"sub":   "42",
+		"role":  "student",
+		"class": "XI-A",
+		"typ":   "refresh",
+		"jti":   "original",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+
+	resp, err := svc.Refresh(context.Background(), dto.AuthRefreshRequest{RefreshToken: refreshToken})
+	require.NoError(t, err)
+	require.Equal(t, "Bearer", resp.TokenType)
+	require.Equal(t, int64(600), resp.ExpiresIn)
+	require.WithinDuration(t, time.Now().Add(10*time.Minute), resp.AccessTokenExpiresAt, 5*time.Second)
+	require.True(t, server.Exists("auth:refresh:revoked:original"))
+
+	access := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(resp.AccessToken, access, func(*jwt.Token) (interface{}, error) {
+		return []byte(testAccessSecret), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "42", access["sub"])
+	require.Equal(t, "student", access["role"])
+	require.Equal(t, "XI-A", access["class"])
+	require.Equal(t, "access", access["typ"])
+
+	rotated, err := svc.Refresh(context.Background(), dto.AuthRefreshRequest{RefreshToken: resp.RefreshToken})
+	require.NoError(t, err)
+	require.NotEqual(t, resp.RefreshToken, rotated.RefreshToken)
+
+	// Replaying a rotated token revokes every token of its login, including
+	// the latest one.
+	_, err = svc.Refresh(context.Background(), dto.AuthRefreshRequest{RefreshToken: refreshToken})
+	require.ErrorIs(t, err, ErrRefreshTokenRevoked)
+	require.True(t, server.Exists("auth:refresh:family:revoked:original"))
+
+	_, err = svc.Refresh(context.Background(), dto.AuthRefreshRequest{RefreshToken: rotated.RefreshToken})
+	require.ErrorIs(t, err, ErrRefreshTokenRevoked)
+}
+
+type stubAuthAccounts struct {
+	accounts map[uint]AuthAccount
+	err      error
+}
+
+func (s *stubAuthAccounts) LookupAccount(_ context.Context, userID uint, _ string) (AuthAccount, error) {
+	if s.err != nil {
+		return AuthAccount{}, s.err
+	}
+	account, ok := s.accounts[userID]
+	if !ok {
+		return AuthAccount{}, gorm.ErrRecordNotFound
+	}
+	return account, nil
+}
+
+func TestAuthServiceRefreshReloadsAccount(t *testing.T) {
+	accounts := &stubAuthAccounts{accounts: map[uint]AuthAccount{
+		42: {Role: "student", Class: "XII-B", Active: true},
+		43: {Role: "student", Active: false},
+		44: {Role: "teacher", Active: true},
+	}}
+	svc := NewAuthService(AuthTokenConfig{
+		AccessSecret:  testAccessSecret,
+		RefreshSecret: testRefreshSecret,
+	}, accounts, nil, nil, validator.New(), testLogger())
+
+	token := func(sub, jti string) string {
+		return signTestRefreshToken(t, testRefreshSecret, jwt.MapClaims{
+			"sub": sub, "role": "student", "class": "XI-A", "typ": "refresh", "jti": jti, "exp": time.Now().Add(time.Hour).Unix(),
+		})
+	}
+
+	// A failed lookup leaves the token unused, so the client can retry it.
+	accounts.err = errors.New("database unavailable")
+	retried := token("42", "retry")
+	_, err := svc.Refresh(context.Background(), dto.AuthRefreshRequest{RefreshToken: retried})
+	require.Error(t, err)
+	accounts.err = nil
+
+	resp, err := svc.Refresh(context.Background(), dto.AuthRefreshRequest{RefreshToken: retried})
+	require.NoError(t, err)
+	access := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(resp.AccessToken, access, func(*jwt.Token) (interface{}, error) {
+		return []byte(testAccessSecret), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "XII-B", access["class"], "claims follow the account's current state")
+
+	for name, sub := range map[string]string{"inactive": "43", "role changed": "44", "deleted": "45"} {
+		_, err := svc.Refresh(context.Background(), dto.AuthRefreshRequest{RefreshToken: token(sub, "token-"+sub)})
+		require.ErrorIs(t, err, ErrRefreshAccountInactive, name)
+	}
+}
+
+func TestAuthServiceRefreshRejectsInvalidTokens(t *testing.T) {
+	svc := NewAuthService(AuthTokenConfig{
+		AccessSecret:  testAccessSecret,
+		RefreshSecret: testRefreshSecret,
+	}, nil, nil, nil, validator.New(), testLogger())
+
+	cases := map[string]string{
+		"access token": signTestRefreshToken(t, testAccessSecret, jwt.MapClaims{
+			"sub": "1", "typ": "access", "jti": "a", "exp": time.Now().Add(time.Hour).Unix(),
+		}),
+		"wrong type": signTestRefreshToken(t, testRefreshSecret, jwt.MapClaims{
+			"sub": "1", "typ": "access", "jti": "b", "exp": time.Now().Add(time.Hour).Unix(),
+		}),
+		"expired": signTestRefreshToken(t, testRefreshSecret, jwt.MapClaims{
+			"sub": "1", "typ": "refresh", "jti": "c", "exp": time.Now().Add(-time.Minute).Unix(),
+		}),
+		"no expiry": signTestRefreshToken(t, testRefreshSecret, jwt.MapClaims{
+			"sub": "1", "typ": "refresh", "jti": "d",
+		}),
+		"no id": signTestRefreshToken(t, testRefreshSecret, jwt.MapClaims{
+			"sub": "1", "typ": "refresh", "exp": time.Now().Add(time.Hour).Unix(),
+		}),
+		"garbage": "not-a-token",
+	}
+
+	for name, token := range cases {
+		_, err := svc.Refresh(context.Background(), dto.AuthRefreshRequest{RefreshToken: token})
+		require.ErrorIs(t, err, ErrInvalidRefreshToken, name)
+	}
+
+	_, err := svc.Refresh(context.Background(), dto.AuthRefreshRequest{})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestAuthServiceLogoutRevokesSessionTokens(t *testing.T) {
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	defer server.Close()
+
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+
+	denylist := NewTokenDenylist(redisClient, AccessTokenDenylistPrefix)
+	svc := NewAuthService(AuthTokenConfig{
+		AccessSecret:  testAccessSecret,
+		RefreshSecret: testRefreshSecret,
+	}, nil, redisClient, denylist, validator.New(), testLogger())
+
+	refreshToken := signTestRefreshToken(t, testRefreshSecret, jwt.MapClaims{
+		"sub": "42", "typ": "refresh", "jti": "session-refresh", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	err = svc.Logout(context.Background(), dto.AuthLogoutRequest{TokenID: "session-access"})
+	require.ErrorIs(t, err, ErrTokenNotRevocable)
+
+	err = svc.Logout(context.Background(), dto.AuthLogoutRequest{
+		TokenID:      "session-access",
+		ExpiresAt:    time.Now().Add(10 * time.Minute),
+		RefreshToken: refreshToken,
+	})
+	require.NoError(t, err)
+
+	revoked, err := denylist.IsRevoked(context.Background(), "session-access")
+	require.NoError(t, err)
+	require.True(t, revoked)
+	ttl := server.TTL(AccessTokenDenylistPrefix + "session-access")
+	require.True(t, ttl > 9*time.Minute && ttl <= 10*time.Minute, ttl)
+
+	_, err = svc.Refresh(context.Background(), dto.AuthRefreshRequest{RefreshToken: refreshToken})
+	require.ErrorIs(t, err, ErrRefreshTokenRevoked)
+}
@@ -0,0 +1,12 @@
+package service
+
+import "time"
+
+// TTLSource reports a cache TTL each time an entry is written, so a
+// configuration reload applies from the next write on.
+type TTLSource func() time.Duration
+
+// FixedTTL returns a TTLSource that always reports ttl.
+func FixedTTL(ttl time.Duration) TTLSource {
+	return func() time.Duration { return ttl }
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -13,13 +14,15 @@ import (
 	"github.com/gofiber/websocket/v2"
 	"github.com/google/uuid"
 	"github.com/microcosm-cc/bluemonday"
-	"github.com/nats-io/nats.go"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/middleware"
 	"github.com/noah-isme/gema-go-api/internal/models"
@@ -28,13 +31,42 @@ import (
 )
 
 const (
-	chatRedisTTL       = 30 * time.Minute
-	chatSendBufferSize = 32
+	chatRedisTTL          = 30 * time.Minute
+	chatSendBufferSize    = 32
+	chatControlDisconnect = "disconnect"
+	// chatControlLeaveRoom closes a user's connections to one room, after
+	// they were removed from it.
+	chatControlLeaveRoom = "leave_room"
+	// chatDrainWriteTimeout bounds each write while flushing a client on shutdown.
+	chatDrainWriteTimeout = 2 * time.Second
+	chatShutdownNotice    = "server is shutting down, please reconnect"
+	// MaxChatRoomSummaries caps how many rooms a single summary request may cover.
+	MaxChatRoomSummaries = 50
+	// MaxChatRoomMembers caps the size of a group room, creator included.
+	MaxChatRoomMembers  = 50
+	chatGroupRoomPrefix = "group:"
 )
 
 // ErrChatNotAuthorised indicates the sender attempted to post into a room they do not control.
 var ErrChatNotAuthorised = errors.New("sender not authorised for room")
 
+// ErrChatModerationForbidden indicates the caller may not moderate chat users.
+var ErrChatModerationForbidden = errors.New("chat moderation requires teacher or admin role")
+
+// ErrChatTooManyRooms indicates a summary request exceeded MaxChatRoomSummaries.
+var ErrChatTooManyRooms = errors.New("too many rooms requested")
+
+var (
+	// ErrChatRoomNotFound indicates the group room does not exist.
+	ErrChatRoomNotFound = errors.New("chat room not found")
+	// ErrChatRoomForbidden indicates the caller may not manage the room's members.
+	ErrChatRoomForbidden = errors.New("only the room creator, teachers or admins may manage members")
+	// ErrChatRoomMemberNotFound indicates the user is not a member of the room.
+	ErrChatRoomMemberNotFound = errors.New("user is not a member of the room")
+	// ErrChatRoomFull indicates the room would exceed MaxChatRoomMembers.
+	ErrChatRoomFull = errors.New("chat room member limit reached")
+)
+
 // ChatConnectionOptions wraps metadata extracted during the HTTP upgrade.
 type ChatConnectionOptions struct {
 	UserID        string
@@ -47,8 +79,15 @@ type ChatConnectionOptions struct {
 // ChatService manages websocket chat connections and message delivery.
 type ChatService interface {
 	ServeConnection(conn *websocket.Conn, opts ChatConnectionOptions)
-	History(ctx context.Context, query dto.ChatHistoryQuery) ([]dto.ChatMessageResponse, error)
+	History(ctx context.Context, userID, role string, query dto.ChatHistoryQuery) ([]dto.ChatMessageResponse, error)
+	RoomSummaries(ctx context.Context, userID, role string, roomIDs []string) ([]dto.ChatRoomSummary, error)
+	MarkRoomRead(ctx context.Context, userID, roomID string) error
+	Disconnect(ctx context.Context, actor ActivityActor, userID string, payload dto.ChatDisconnectRequest) (dto.ChatDisconnectResponse, error)
+	CreateRoom(ctx context.Context, actor ActivityActor, payload dto.ChatRoomCreateRequest) (dto.ChatRoomResponse, error)
+	AddRoomMembers(ctx context.Context, actor ActivityActor, roomID string, payload dto.ChatRoomMembersRequest) (dto.ChatRoomResponse, error)
+	RemoveRoomMember(ctx context.Context, actor ActivityActor, roomID, userID string) (dto.ChatRoomResponse, error)
 	Start(ctx context.Context)
+	Shutdown(ctx context.Context) error
 }
 
 type chatService struct {
@@ -56,21 +95,30 @@ type chatService struct {
 	redis       *redis.Client
 	redisStream string
 	redisCache  string
-	nats        *nats.Conn
+	streamKey   string
+	streams     ChatStreamConfig
+	lastCache   cache.Store
+	nats        *NATSRelay
 	natsSubject string
 	validator   *validator.Validate
+	activity    ActivityRecorder
 	logger      zerolog.Logger
 	tracer      trace.Tracer
 	sanitizer   *bluemonday.Policy
 	hub         *chatHub
 	nodeID      string
+	clock       clock.Clock
+
+	blockMu sync.Mutex
+	blocked map[string]time.Time
 }
 
 // chatHub keeps track of active websocket clients and handles broadcasting.
 type chatHub struct {
-	mu    sync.RWMutex
-	rooms map[string]map[*chatClient]struct{}
-	log   zerolog.Logger
+	mu       sync.RWMutex
+	rooms    map[string]map[*chatClient]struct{}
+	draining bool
+	log      zerolog.Logger
 }
 
 type chatClient struct {
@@ -79,7 +127,9 @@ type chatClient struct {
 	options       ChatConnectionOptions
 	service       *chatService
 	closed        chan struct{}
+	draining      chan struct{}
 	once          sync.Once
+	drainOnce     sync.Once
 	lastHeartbeat time.Time
 	baseCtx       context.Context
 }
@@ -87,12 +137,27 @@ type chatClient struct {
 type chatEvent struct {
 	Source   string                  `json:"source"`
 	Message  dto.ChatMessageResponse `json:"message"`
+	Control  *chatControl            `json:"control,omitempty"`
 	SentAt   time.Time               `json:"sent_at"`
 	Metadata map[string]string       `json:"metadata,omitempty"`
+	// TraceContext carries the publishing span as W3C trace headers.
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+}
+
+// chatControl instructs every node to act on a user's connections.
+type chatControl struct {
+	Action       string     `json:"action"`
+	UserID       string     `json:"user_id"`
+	RoomID       string     `json:"room_id,omitempty"`
+	Reason       string     `json:"reason,omitempty"`
+	BlockedUntil *time.Time `json:"blocked_until,omitempty"`
 }
 
-// NewChatService creates a websocket chat service instance.
-func NewChatService(repo repository.ChatRepository, redisClient *redis.Client, channelBase string, natsConn *nats.Conn, validate *validator.Validate, logger zerolog.Logger) ChatService {
+// NewChatService creates a websocket chat service instance. lastMessages keeps
+// each room's latest message for room summaries, which read it back through
+// redisClient, so it should be backed by the same Redis. streams replaces the
+// Redis pub/sub fan-out with a stream when enabled.
+func NewChatService(repo repository.ChatRepository, redisClient *redis.Client, lastMessages cache.Store, channelBase string, streams ChatStreamConfig, natsRelay *NATSRelay, validate *validator.Validate, activity ActivityRecorder, logger zerolog.Logger) ChatService {
 	sanitizer := bluemonday.UGCPolicy()
 	sanitizer.AllowElements("br")
 
@@ -104,12 +169,14 @@ func NewChatService(repo repository.ChatRepository, redisClient *redis.Client, c
 	tracer := otel.Tracer("github.com/noah-isme/gema-go-api/internal/service/chat")
 
 	streamChannel := ""
+	streamKey := ""
 	cachePrefix := ""
 	natsSubject := ""
 	if channelBase != "" {
 		streamChannel = channelBase + ":chat"
+		streamKey = channelBase + ":chat:stream"
 		cachePrefix = channelBase + ":chat:last"
-		natsSubject = strings.ReplaceAll(channelBase, ":", ".") + ".chat"
+		natsSubject = natsSubjectBase(channelBase) + ".chat"
 	}
 
 	return &chatService{
@@ -117,20 +184,30 @@ func NewChatService(repo repository.ChatRepository, redisClient *redis.Client, c
 		redis:       redisClient,
 		redisStream: streamChannel,
 		redisCache:  cachePrefix,
-		nats:        natsConn,
+		streamKey:   streamKey,
+		streams:     streams,
+		lastCache:   lastMessages,
+		nats:        natsRelay,
 		natsSubject: natsSubject,
 		validator:   validate,
+		activity:    activity,
 		logger:      logger.With().Str("component", "chat_service").Logger(),
 		tracer:      tracer,
 		sanitizer:   sanitizer,
 		hub:         hub,
 		nodeID:      uuid.NewString(),
+		clock:       clock.Real(),
+		blocked:     make(map[string]time.Time),
 	}
 }
 
 func (s *chatService) Start(ctx context.Context) {
 	if s.redis != nil && s.redisStream != "" {
-		go s.consumeRedis(ctx)
+		if s.streams.Enabled {
+			go s.consumeStream(ctx)
+		} else {
+			go s.consumeRedis(ctx)
+		}
 	}
 	if s.nats != nil && s.natsSubject != "" {
 		go s.consumeNATS(ctx)
@@ -143,16 +220,40 @@ func (s *chatService) ServeConnection(conn *websocket.Conn, opts ChatConnectionO
 		baseCtx = context.Background()
 	}
 
+	if until, blocked := s.blockedUntil(opts.UserID); blocked {
+		reason := fmt.Sprintf("disconnected by moderator until %s", until.UTC().Format(time.RFC3339))
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason))
+		_ = conn.Close()
+		return
+	}
+
+	allowed, err := s.canAccessRoom(baseCtx, opts.RoomID, opts.UserID, opts.Role)
+	if err != nil || !allowed {
+		code, reason := websocket.ClosePolicyViolation, "not a member of this room"
+		if err != nil {
+			s.logger.Error().Err(err).Str("room_id", opts.RoomID).Msg("failed to check chat room membership")
+			code, reason = websocket.CloseInternalServerErr, "failed to check room membership"
+		}
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+		_ = conn.Close()
+		return
+	}
+
 	client := &chatClient{
-		conn:    conn,
-		send:    make(chan dto.ChatMessageResponse, chatSendBufferSize),
-		options: opts,
-		service: s,
-		closed:  make(chan struct{}),
-		baseCtx: baseCtx,
+		conn:     conn,
+		send:     make(chan dto.ChatMessageResponse, chatSendBufferSize),
+		options:  opts,
+		service:  s,
+		closed:   make(chan struct{}),
+		draining: make(chan struct{}),
+		baseCtx:  baseCtx,
 	}
 
-	s.hub.register(client)
+	if !s.hub.register(client) {
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseServiceRestart, chatShutdownNotice))
+		_ = conn.Close()
+		return
+	}
 	observability.ChatConnectionsTotal().Inc()
 
 	if last := s.fetchLastMessage(baseCtx, opts.RoomID); last != nil {
@@ -167,10 +268,56 @@ func (s *chatService) ServeConnection(conn *websocket.Conn, opts ChatConnectionO
 	client.reader()
 }
 
-func (s *chatService) History(ctx context.Context, query dto.ChatHistoryQuery) ([]dto.ChatMessageResponse, error) {
+// Shutdown stops accepting connections and drains the connected clients:
+// each gets a system message asking it to reconnect, its queued messages are
+// flushed and the socket is closed with a service restart code. Clients still
+// open when ctx ends are closed outright.
+func (s *chatService) Shutdown(ctx context.Context) error {
+	clients := s.hub.drain()
+	for _, client := range clients {
+		notice := dto.ChatMessageResponse{
+			RoomID:    client.options.RoomID,
+			SenderID:  "system",
+			Content:   chatShutdownNotice,
+			Type:      "system",
+			CreatedAt: s.clock.Now().UTC(),
+		}
+		select {
+		case client.send <- notice:
+		default:
+		}
+		client.drainOnce.Do(func() { close(client.draining) })
+	}
+
+	for i, client := range clients {
+		select {
+		case <-client.closed:
+		case <-ctx.Done():
+			for _, remaining := range clients[i:] {
+				remaining.close()
+			}
+			s.logger.Warn().Int("connections", len(clients)-i).Msg("chat drain timed out; closing remaining connections")
+			return ctx.Err()
+		}
+	}
+
+	s.logger.Info().Int("connections", len(clients)).Msg("chat connections drained")
+	return nil
+}
+
+// History returns a room's messages, newest first. Group room history is
+// limited to the room's members, teachers and admins.
+func (s *chatService) History(ctx context.Context, userID, role string, query dto.ChatHistoryQuery) ([]dto.ChatMessageResponse, error) {
 	if err := s.validator.Struct(query); err != nil {
 		return nil, err
 	}
+	allowed, err := s.canAccessRoom(ctx, query.RoomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrChatNotAuthorised
+	}
 
 	before := time.Time{}
 	if query.Before != nil {
@@ -185,6 +332,353 @@ func (s *chatService) History(ctx context.Context, query dto.ChatHistoryQuery) (
 	return dto.NewChatMessageResponseSlice(messages), nil
 }
 
+// RoomSummaries returns the last message and unread count for each requested room in one pass.
+// Last messages come from the Redis cache where possible and fall back to a single batched query.
+// Group rooms the user may not read are left out.
+func (s *chatService) RoomSummaries(ctx context.Context, userID, role string, roomIDs []string) ([]dto.ChatRoomSummary, error) {
+	rooms := make([]string, 0, len(roomIDs))
+	seen := make(map[string]struct{}, len(roomIDs))
+	for _, roomID := range roomIDs {
+		roomID = strings.TrimSpace(roomID)
+		if roomID == "" {
+			continue
+		}
+		if _, ok := seen[roomID]; ok {
+			continue
+		}
+		seen[roomID] = struct{}{}
+		rooms = append(rooms, roomID)
+	}
+	if len(rooms) > MaxChatRoomSummaries {
+		return nil, ErrChatTooManyRooms
+	}
+	readable := rooms[:0]
+	for _, roomID := range rooms {
+		allowed, err := s.canAccessRoom(ctx, roomID, userID, role)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			readable = append(readable, roomID)
+		}
+	}
+	rooms = readable
+	if len(rooms) == 0 {
+		return []dto.ChatRoomSummary{}, nil
+	}
+
+	lastMessages := s.fetchLastMessages(ctx, rooms)
+	missing := make([]string, 0)
+	for _, roomID := range rooms {
+		if _, ok := lastMessages[roomID]; !ok {
+			missing = append(missing, roomID)
+		}
+	}
+	if len(missing) > 0 {
+		latest, err := s.repo.LatestByRooms(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, message := range latest {
+			lastMessages[message.RoomID] = dto.NewChatMessageResponse(message)
+		}
+	}
+
+	unread, err := s.repo.CountUnreadByRooms(ctx, userID, rooms)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]dto.ChatRoomSummary, 0, len(rooms))
+	for _, roomID := range rooms {
+		summary := dto.ChatRoomSummary{RoomID: roomID, UnreadCount: unread[roomID]}
+		if message, ok := lastMessages[roomID]; ok {
+			summary.LastMessage = &message
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// MarkRoomRead records that the user has read everything in the room up to now.
+func (s *chatService) MarkRoomRead(ctx context.Context, userID, roomID string) error {
+	roomID = strings.TrimSpace(roomID)
+	if userID == "" || roomID == "" {
+		return fmt.Errorf("user id and room id are required")
+	}
+	return s.repo.MarkRead(ctx, userID, roomID, s.clock.Now())
+}
+
+func (s *chatService) Disconnect(ctx context.Context, actor ActivityActor, userID string, payload dto.ChatDisconnectRequest) (dto.ChatDisconnectResponse, error) {
+	if !IsStaffRole(actor.Role) {
+		return dto.ChatDisconnectResponse{}, ErrChatModerationForbidden
+	}
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.ChatDisconnectResponse{}, err
+	}
+
+	userID = strings.TrimSpace(userID)
+	control := chatControl{
+		Action: chatControlDisconnect,
+		UserID: userID,
+		Reason: strings.TrimSpace(payload.Reason),
+	}
+	if payload.CooldownSeconds > 0 {
+		until := s.clock.Now().UTC().Add(time.Duration(payload.CooldownSeconds) * time.Second)
+		control.BlockedUntil = &until
+	}
+
+	closed := s.applyControl(control)
+
+	event := chatEvent{
+		Source:  s.nodeID,
+		Control: &control,
+		SentAt:  s.clock.Now().UTC(),
+	}
+	if err := s.publishEvent(ctx, event); err != nil {
+		s.logger.Warn().Err(err).Str("user_id", userID).Msg("failed to broadcast chat disconnect")
+	}
+
+	s.recordDisconnect(ctx, actor, control, closed)
+
+	return dto.ChatDisconnectResponse{
+		UserID:            userID,
+		ClosedConnections: closed,
+		BlockedUntil:      control.BlockedUntil,
+	}, nil
+}
+
+// CreateRoom registers a group room with the creator and the listed members.
+// Room IDs are generated so a group can never take over an existing room.
+func (s *chatService) CreateRoom(ctx context.Context, actor ActivityActor, payload dto.ChatRoomCreateRequest) (dto.ChatRoomResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.ChatRoomResponse{}, err
+	}
+
+	creator := chatActorID(actor)
+	memberIDs := uniqueChatMembers(append([]string{creator}, payload.Members...))
+	if len(memberIDs) > MaxChatRoomMembers {
+		return dto.ChatRoomResponse{}, ErrChatRoomFull
+	}
+
+	room := models.ChatRoom{
+		RoomID:    chatGroupRoomPrefix + uuid.NewString(),
+		Name:      strings.TrimSpace(payload.Name),
+		CreatedBy: creator,
+	}
+	for _, userID := range memberIDs {
+		room.Members = append(room.Members, models.ChatRoomMember{UserID: userID, AddedBy: creator})
+	}
+	if err := s.repo.CreateRoom(ctx, &room); err != nil {
+		return dto.ChatRoomResponse{}, err
+	}
+
+	s.recordRoomActivity(ctx, actor, "chat.room_created", room, map[string]interface{}{"members": memberIDs})
+	return dto.NewChatRoomResponse(room), nil
+}
+
+// AddRoomMembers adds users to a group room. Users already in the room are
+// left as they are.
+func (s *chatService) AddRoomMembers(ctx context.Context, actor ActivityActor, roomID string, payload dto.ChatRoomMembersRequest) (dto.ChatRoomResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.ChatRoomResponse{}, err
+	}
+
+	room, err := s.manageableRoom(ctx, actor, roomID)
+	if err != nil {
+		return dto.ChatRoomResponse{}, err
+	}
+
+	existing := make(map[string]struct{}, len(room.Members))
+	for _, member := range room.Members {
+		existing[member.UserID] = struct{}{}
+	}
+	added := make([]models.ChatRoomMember, 0, len(payload.Members))
+	addedIDs := make([]string, 0, len(payload.Members))
+	for _, userID := range uniqueChatMembers(payload.Members) {
+		if _, ok := existing[userID]; ok {
+			continue
+		}
+		added = append(added, models.ChatRoomMember{RoomID: room.RoomID, UserID: userID, AddedBy: chatActorID(actor)})
+		addedIDs = append(addedIDs, userID)
+	}
+	if len(room.Members)+len(added) > MaxChatRoomMembers {
+		return dto.ChatRoomResponse{}, ErrChatRoomFull
+	}
+	if err := s.repo.AddRoomMembers(ctx, added); err != nil {
+		return dto.ChatRoomResponse{}, err
+	}
+
+	if len(addedIDs) > 0 {
+		s.recordRoomActivity(ctx, actor, "chat.room_members_added", room, map[string]interface{}{"members": addedIDs})
+	}
+	return s.roomResponse(ctx, room.RoomID)
+}
+
+// RemoveRoomMember removes a user from a group room.
+func (s *chatService) RemoveRoomMember(ctx context.Context, actor ActivityActor, roomID, userID string) (dto.ChatRoomResponse, error) {
+	room, err := s.manageableRoom(ctx, actor, roomID)
+	if err != nil {
+		return dto.ChatRoomResponse{}, err
+	}
+
+	userID = strings.TrimSpace(userID)
+	if err := s.repo.RemoveRoomMember(ctx, room.RoomID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.ChatRoomResponse{}, ErrChatRoomMemberNotFound
+		}
+		return dto.ChatRoomResponse{}, err
+	}
+
+	// The removed member's open sockets would otherwise keep receiving the
+	// room's messages, on this node and every other one.
+	control := chatControl{Action: chatControlLeaveRoom, UserID: userID, RoomID: room.RoomID}
+	s.applyControl(control)
+	if err := s.publishEvent(ctx, chatEvent{Source: s.nodeID, Control: &control, SentAt: s.clock.Now().UTC()}); err != nil {
+		s.logger.Warn().Err(err).Str("room_id", room.RoomID).Msg("failed to broadcast chat room removal")
+	}
+
+	s.recordRoomActivity(ctx, actor, "chat.room_member_removed", room, map[string]interface{}{"user_id": userID})
+	return s.roomResponse(ctx, room.RoomID)
+}
+
+// manageableRoom loads a group room the actor may manage: teachers and admins
+// manage every room, other users only the rooms they created.
+func (s *chatService) manageableRoom(ctx context.Context, actor ActivityActor, roomID string) (models.ChatRoom, error) {
+	room, err := s.repo.GetRoom(ctx, strings.TrimSpace(roomID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ChatRoom{}, ErrChatRoomNotFound
+		}
+		return models.ChatRoom{}, err
+	}
+
+	if IsStaffRole(actor.Role) {
+		return room, nil
+	}
+	if room.CreatedBy != chatActorID(actor) {
+		return models.ChatRoom{}, ErrChatRoomForbidden
+	}
+	return room, nil
+}
+
+func (s *chatService) roomResponse(ctx context.Context, roomID string) (dto.ChatRoomResponse, error) {
+	room, err := s.repo.GetRoom(ctx, roomID)
+	if err != nil {
+		return dto.ChatRoomResponse{}, err
+	}
+	return dto.NewChatRoomResponse(room), nil
+}
+
+func (s *chatService) recordRoomActivity(ctx context.Context, actor ActivityActor, action string, room models.ChatRoom, metadata map[string]interface{}) {
+	if s.activity == nil {
+		return
+	}
+	metadata["room_id"] = room.RoomID
+	entry := ActivityEntry{
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+		Action:     action,
+		EntityType: "chat_room",
+		EntityID:   &room.ID,
+		Metadata:   metadata,
+	}
+	if _, err := s.activity.Record(ctx, entry); err != nil {
+		s.logger.Warn().Err(err).Str("action", action).Msg("failed to record chat room activity")
+	}
+}
+
+// chatActorID converts an authenticated actor to the string user ID chat uses.
+func chatActorID(actor ActivityActor) string {
+	return strconv.FormatUint(uint64(actor.ID), 10)
+}
+
+func uniqueChatMembers(userIDs []string) []string {
+	seen := make(map[string]struct{}, len(userIDs))
+	members := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		userID = strings.TrimSpace(userID)
+		if userID == "" {
+			continue
+		}
+		if _, ok := seen[userID]; ok {
+			continue
+		}
+		seen[userID] = struct{}{}
+		members = append(members, userID)
+	}
+	return members
+}
+
+func (s *chatService) recordDisconnect(ctx context.Context, actor ActivityActor, control chatControl, closed int) {
+	if s.activity == nil {
+		return
+	}
+	metadata := map[string]interface{}{
+		"user_id":            control.UserID,
+		"reason":             control.Reason,
+		"closed_connections": closed,
+	}
+	if control.BlockedUntil != nil {
+		metadata["blocked_until"] = control.BlockedUntil.Format(time.RFC3339)
+	}
+	entry := ActivityEntry{
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+		Action:     "chat.user_disconnected",
+		EntityType: "chat_user",
+		Metadata:   metadata,
+	}
+	if _, err := s.activity.Record(ctx, entry); err != nil {
+		s.logger.Warn().Err(err).Msg("failed to record chat disconnect activity")
+	}
+}
+
+// applyControl executes a control instruction against this node's clients.
+func (s *chatService) applyControl(control chatControl) int {
+	if control.UserID == "" {
+		return 0
+	}
+	switch control.Action {
+	case chatControlDisconnect:
+	case chatControlLeaveRoom:
+		if control.RoomID == "" {
+			return 0
+		}
+		return s.hub.disconnectUser(control.UserID, control.RoomID, "removed from room")
+	default:
+		return 0
+	}
+
+	if control.BlockedUntil != nil {
+		s.blockMu.Lock()
+		s.blocked[control.UserID] = *control.BlockedUntil
+		s.blockMu.Unlock()
+	}
+
+	reason := control.Reason
+	if reason == "" {
+		reason = "disconnected by moderator"
+	}
+	return s.hub.disconnectUser(control.UserID, "", reason)
+}
+
+func (s *chatService) blockedUntil(userID string) (time.Time, bool) {
+	s.blockMu.Lock()
+	defer s.blockMu.Unlock()
+
+	until, ok := s.blocked[userID]
+	if !ok {
+		return time.Time{}, false
+	}
+	if !s.clock.Now().Before(until) {
+		delete(s.blocked, userID)
+		return time.Time{}, false
+	}
+	return until, true
+}
+
 func (s *chatService) processSend(ctx context.Context, client *chatClient, correlation string, payload dto.ChatSendRequest) (dto.ChatMessageResponse, error) {
 	if payload.RoomID == "" {
 		payload.RoomID = client.options.RoomID
@@ -197,7 +691,7 @@ func (s *chatService) processSend(ctx context.Context, client *chatClient, corre
 		return dto.ChatMessageResponse{}, err
 	}
 
-	if err := s.authorise(client, payload); err != nil {
+	if err := s.authorise(ctx, client, payload); err != nil {
 		return dto.ChatMessageResponse{}, err
 	}
 
@@ -248,11 +742,43 @@ func (s *chatService) processSend(ctx context.Context, client *chatClient, corre
 	return response, nil
 }
 
-func (s *chatService) authorise(client *chatClient, payload dto.ChatSendRequest) error {
+// canAccessRoom reports whether the user may join, read or summarise roomID.
+// Group rooms are limited to their members, teachers and admins; other rooms
+// are readable by everyone, with posting governed by authorise.
+func (s *chatService) canAccessRoom(ctx context.Context, roomID, userID, role string) (bool, error) {
+	if !strings.HasPrefix(roomID, chatGroupRoomPrefix) || IsStaffRole(role) {
+		return true, nil
+	}
+	if s.repo == nil {
+		return false, nil
+	}
+	_, member, err := s.repo.RoomMembership(ctx, roomID, userID)
+	return member, err
+}
+
+// authorise decides whether the client may post into payload.RoomID. Group
+// rooms accept their listed members plus teachers and admins; other rooms fall
+// back to the role and room-name rules.
+func (s *chatService) authorise(ctx context.Context, client *chatClient, payload dto.ChatSendRequest) error {
 	role := strings.ToLower(client.options.Role)
-	switch role {
-	case "admin", "teacher":
+	if s.repo != nil {
+		registered, member, err := s.repo.RoomMembership(ctx, payload.RoomID, client.options.UserID)
+		if err != nil {
+			return err
+		}
+		if registered {
+			if member || IsStaffRole(role) {
+				return nil
+			}
+			return ErrChatNotAuthorised
+		}
+	}
+
+	if IsStaffRole(role) {
 		return nil
+	}
+
+	switch role {
 	case "student":
 		if strings.Contains(payload.RoomID, client.options.UserID) {
 			return nil
@@ -270,18 +796,12 @@ func (s *chatService) authorise(client *chatClient, payload dto.ChatSendRequest)
 }
 
 func (s *chatService) cacheLastMessage(ctx context.Context, message dto.ChatMessageResponse) {
-	if s.redis == nil || s.redisCache == "" {
-		return
-	}
-
-	payload, err := json.Marshal(message)
-	if err != nil {
-		s.logger.Warn().Err(err).Msg("failed to marshal chat message for cache")
+	if s.redisCache == "" {
 		return
 	}
 
 	key := fmt.Sprintf("%s:%s", s.redisCache, message.RoomID)
-	if err := s.redis.Set(ctx, key, payload, chatRedisTTL).Err(); err != nil {
+	if err := cache.Write(ctx, s.lastCache, "chat", key, message, chatRedisTTL); err != nil {
 		s.logger.Warn().Err(err).Msg("failed to cache chat message")
 	}
 }
@@ -306,6 +826,39 @@ func (s *chatService) fetchLastMessage(ctx context.Context, roomID string) *dto.
 	return &message
 }
 
+func (s *chatService) fetchLastMessages(ctx context.Context, roomIDs []string) map[string]dto.ChatMessageResponse {
+	messages := make(map[string]dto.ChatMessageResponse, len(roomIDs))
+	if s.redis == nil || s.redisCache == "" {
+		return messages
+	}
+
+	keys := make([]string, 0, len(roomIDs))
+	for _, roomID := range roomIDs {
+		keys = append(keys, fmt.Sprintf("%s:%s", s.redisCache, roomID))
+	}
+
+	values, err := s.redis.MGet(ctx, keys...).Result()
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("failed to read cached chat messages")
+		return messages
+	}
+
+	for idx, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var message dto.ChatMessageResponse
+		if err := json.Unmarshal([]byte(raw), &message); err != nil {
+			s.logger.Warn().Err(err).Msg("failed to unmarshal cached chat message")
+			continue
+		}
+		messages[roomIDs[idx]] = message
+	}
+
+	return messages
+}
+
 func (s *chatService) broadcast(message dto.ChatMessageResponse) {
 	s.hub.broadcast(message.RoomID, message)
 }
@@ -314,22 +867,30 @@ func (s *chatService) publish(ctx context.Context, message dto.ChatMessageRespon
 	event := chatEvent{
 		Source:  s.nodeID,
 		Message: message,
-		SentAt:  time.Now().UTC(),
+		SentAt:  s.clock.Now().UTC(),
 	}
+	return s.publishEvent(ctx, event)
+}
 
+func (s *chatService) publishEvent(ctx context.Context, event chatEvent) error {
+	event.TraceContext = injectTraceContext(ctx)
 	payload, err := json.Marshal(event)
 	if err != nil {
 		return err
 	}
 
 	if s.redis != nil && s.redisStream != "" {
-		if err := s.redis.Publish(ctx, s.redisStream, payload).Err(); err != nil {
+		if s.streams.Enabled {
+			if err := s.appendStream(ctx, payload); err != nil {
+				return err
+			}
+		} else if err := s.redis.Publish(ctx, s.redisStream, payload).Err(); err != nil {
 			return err
 		}
 	}
 
 	if s.nats != nil && s.natsSubject != "" {
-		if err := s.nats.Publish(s.natsSubject, payload); err != nil {
+		if err := s.nats.publish(ctx, s.natsSubject, payload); err != nil {
 			return err
 		}
 	}
@@ -356,30 +917,33 @@ func (s *chatService) consumeRedis(ctx context.Context) {
 }
 
 func (s *chatService) consumeNATS(ctx context.Context) {
-	sub, err := s.nats.QueueSubscribe(s.natsSubject, "gema-chat", func(msg *nats.Msg) {
-		s.handleEvent(msg.Data)
-	})
-	if err != nil {
+	if err := s.nats.subscribe(ctx, s.natsSubject, "gema-chat", s.handleEvent, s.logger); err != nil {
 		s.logger.Error().Err(err).Msg("failed to subscribe to nats chat subject")
-		return
 	}
-	go func() {
-		<-ctx.Done()
-		if err := sub.Drain(); err != nil {
-			s.logger.Warn().Err(err).Msg("failed to drain chat nats subscription")
-		}
-	}()
 }
 
-func (s *chatService) handleEvent(data []byte) {
+// handleEvent applies a relayed event locally. It only fails for payloads
+// that cannot be decoded.
+func (s *chatService) handleEvent(data []byte) error {
 	var event chatEvent
 	if err := json.Unmarshal(data, &event); err != nil {
 		s.logger.Warn().Err(err).Msg("invalid chat event")
-		return
+		return err
 	}
 
+	// This node broadcast its own events when it published them.
 	if event.Source == s.nodeID {
-		return
+		return nil
+	}
+
+	if event.Control != nil {
+		_, span := startEventSpan(s.tracer, "chat.control", event.TraceContext,
+			attribute.String("chat.control_action", event.Control.Action),
+			attribute.String("chat.source_node", event.Source),
+		)
+		defer span.End()
+		s.applyControl(*event.Control)
+		return nil
 	}
 
 	messageType := event.Message.Type
@@ -387,14 +951,28 @@ func (s *chatService) handleEvent(data []byte) {
 		messageType = "text"
 	}
 
+	_, span := startEventSpan(s.tracer, "chat.deliver", event.TraceContext,
+		attribute.String("chat.room_id", event.Message.RoomID),
+		attribute.String("chat.type", messageType),
+		attribute.String("chat.source_node", event.Source),
+	)
+	defer span.End()
+
 	observability.ChatMessagesSent().WithLabelValues(messageType).Inc()
 	s.broadcast(event.Message)
+	return nil
 }
 
-func (h *chatHub) register(client *chatClient) {
+// register adds the client to its room. It reports false once the hub is
+// draining for shutdown.
+func (h *chatHub) register(client *chatClient) bool {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	if h.draining {
+		return false
+	}
+
 	room := client.options.RoomID
 	if room == "" {
 		room = "default"
@@ -406,6 +984,22 @@ func (h *chatHub) register(client *chatClient) {
 	client.options.RoomID = room
 	h.rooms[room][client] = struct{}{}
 	h.log.Debug().Str("room_id", room).Str("user_id", client.options.UserID).Msg("chat client connected")
+	return true
+}
+
+// drain stops further registrations and returns the connected clients.
+func (h *chatHub) drain() []*chatClient {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.draining = true
+	var clients []*chatClient
+	for _, room := range h.rooms {
+		for client := range room {
+			clients = append(clients, client)
+		}
+	}
+	return clients
 }
 
 func (h *chatHub) unregister(client *chatClient) {
@@ -436,6 +1030,34 @@ func (h *chatHub) broadcast(roomID string, message dto.ChatMessageResponse) {
 	}
 }
 
+// disconnectUser closes the local connections owned by userID, in roomID or
+// in every room when roomID is empty, and reports how many were closed.
+func (h *chatHub) disconnectUser(userID, roomID, reason string) int {
+	h.mu.RLock()
+	var targets []*chatClient
+	for room, clients := range h.rooms {
+		if roomID != "" && room != roomID {
+			continue
+		}
+		for client := range clients {
+			if client.options.UserID == userID {
+				targets = append(targets, client)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range targets {
+		deadline := time.Now().Add(time.Second)
+		_ = client.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason), deadline)
+		client.close()
+	}
+	if len(targets) > 0 {
+		h.log.Info().Str("user_id", userID).Str("reason", reason).Int("connections", len(targets)).Msg("chat user disconnected")
+	}
+	return len(targets)
+}
+
 func (c *chatClient) reader() {
 	defer c.close()
 
@@ -459,7 +1081,12 @@ func (c *chatClient) reader() {
 		response, err := c.service.processSend(connCtx, c, correlation, payload)
 		if err != nil {
 			observability.RealtimeErrorsTotal().WithLabelValues("chat", "process").Inc()
-			c.service.logger.Warn().Err(err).Msg("failed to process chat message")
+			c.service.logger.Warn().
+				Err(err).
+				Str("user_id", c.options.UserID).
+				Str("room_id", payload.RoomID).
+				Str("content", observability.LogContent(payload.Content)).
+				Msg("failed to process chat message")
 			continue
 		}
 
@@ -497,12 +1124,33 @@ func (c *chatClient) writer() {
 				c.service.logger.Debug().Err(err).Msg("chat ping failed")
 				return
 			}
+		case <-c.draining:
+			c.flush()
+			return
 		case <-c.closed:
 			return
 		}
 	}
 }
 
+// flush writes the messages still queued for the client, then closes the
+// socket with a service restart code so the client reconnects elsewhere.
+func (c *chatClient) flush() {
+	for {
+		select {
+		case message := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(chatDrainWriteTimeout))
+			if err := c.conn.WriteJSON(message); err != nil {
+				return
+			}
+		default:
+			deadline := time.Now().Add(chatDrainWriteTimeout)
+			_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseServiceRestart, chatShutdownNotice), deadline)
+			return
+		}
+	}
+}
+
 func (c *chatClient) close() {
 	c.once.Do(func() {
 		close(c.closed)
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	fiberws "github.com/gofiber/websocket/v2"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+func TestChatServiceRoomSummaries(t *testing.T) {
+	dsn := fmt.Sprintf("file:chat_summaries_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.ChatMessage{}, &models.ChatReadReceipt{}))
+
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	defer server.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+
+	base := time.Date(2024, time.June, 1, 9, 0, 0, 0, time.UTC)
+	messages := []models.ChatMessage{
+		{SenderID: "2", RoomID: "room-alpha", Content: "first", Type: "text", CreatedAt: base},
+		{SenderID: "1", RoomID: "room-alpha", Content: "mine", Type: "text", CreatedAt: base.Add(time.Minute)},
+		{SenderID: "2", RoomID: "room-alpha", Content: "after read", Type: "text", CreatedAt: base.Add(3 * time.Minute)},
+		{SenderID: "3", RoomID: "room-alpha", Content: "latest", Type: "text", CreatedAt: base.Add(4 * time.Minute)},
+		{SenderID: "3", RoomID: "room-beta", Content: "beta only", Type: "text", CreatedAt: base},
+	}
+	for i := range messages {
+		require.NoError(t, db.Create(&messages[i]).Error)
+	}
+
+	svc := NewChatService(repository.NewChatRepository(db), redisClient, cache.NewRedisStore(redisClient), "gema", ChatStreamConfig{}, nil, validator.New(), nil, testLogger())
+	concrete := svc.(*chatService)
+	concrete.clock = clock.NewFixed(base.Add(2 * time.Minute))
+	concrete.cacheLastMessage(context.Background(), dto.NewChatMessageResponse(messages[3]))
+
+	ctx := context.Background()
+	require.NoError(t, svc.MarkRoomRead(ctx, "1", "room-alpha"))
+
+	summaries, err := svc.RoomSummaries(ctx, "1", "student", []string{"room-alpha", " room-empty ", "room-beta", "room-alpha"})
+	require.NoError(t, err)
+	require.Len(t, summaries, 3)
+
+	require.Equal(t, "room-alpha", summaries[0].RoomID)
+	require.NotNil(t, summaries[0].LastMessage)
+	require.Equal(t, "latest", summaries[0].LastMessage.Content)
+	require.EqualValues(t, 2, summaries[0].UnreadCount)
+
+	require.Equal(t, "room-empty", summaries[1].RoomID)
+	require.Nil(t, summaries[1].LastMessage)
+	require.Zero(t, summaries[1].UnreadCount)
+
+	require.Equal(t, "room-beta", summaries[2].RoomID)
+	require.NotNil(t, summaries[2].LastMessage)
+	require.Equal(t, "beta only", summaries[2].LastMessage.Content)
+	require.EqualValues(t, 1, summaries[2].UnreadCount)
+
+	tooMany := make([]string, MaxChatRoomSummaries+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("room-%d", i)
+	}
+	_, err = svc.RoomSummaries(ctx, "1", "student", tooMany)
+	require.ErrorIs(t, err, ErrChatTooManyRooms)
+}
+
+func TestChatServiceStreamCatchesUpAfterReaderGap(t *testing.T) {
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	defer server.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+
+	streams := ChatStreamConfig{Enabled: true, MaxLen: 50, MaxAge: time.Hour}
+	sender := NewChatService(nil, redisClient, nil, "gema", streams, nil, validator.New(), nil, testLogger()).(*chatService)
+	receiver := NewChatService(nil, redisClient, nil, "gema", streams, nil, validator.New(), nil, testLogger()).(*chatService)
+
+	// The receiver's group exists but nothing reads it, as while its
+	// connection to Redis is down.
+	ctx := context.Background()
+	group := receiver.streamGroup()
+	require.NoError(t, receiver.createStreamGroup(ctx, group))
+
+	until := time.Now().Add(time.Hour).UTC()
+	require.NoError(t, sender.publishEvent(ctx, chatEvent{
+		Source:  sender.nodeID,
+		Control: &chatControl{Action: chatControlDisconnect, UserID: "7", BlockedUntil: &until},
+	}))
+
+	consumeCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		receiver.consumeStream(consumeCtx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		_, blocked := receiver.blockedUntil("7")
+		return blocked
+	}, 2*time.Second, 10*time.Millisecond, "the event published during the gap is delivered")
+	require.Eventually(t, func() bool {
+		pending, err := redisClient.XPending(ctx, "gema:chat:stream", group).Result()
+		return err == nil && pending.Count == 0
+	}, 2*time.Second, 10*time.Millisecond, "delivered entries are acked")
+
+	cancel()
+	<-done
+	groups, err := redisClient.XInfoGroups(ctx, "gema:chat:stream").Result()
+	require.NoError(t, err)
+	require.Empty(t, groups, "the node removes its group on shutdown")
+}
+
+func TestChatServiceDisconnectClosesOnlyTargetUser(t *testing.T) {
+	activity := &stubActivityRecorder{}
+	svc := NewChatService(nil, nil, nil, "", ChatStreamConfig{}, nil, validator.New(), activity, testLogger())
+	concrete := svc.(*chatService)
+	fixed := clock.NewFixed(time.Date(2024, time.June, 1, 9, 0, 0, 0, time.UTC))
+	concrete.clock = fixed
+
+	app := fiber.New()
+	app.Get("/ws", fiberws.New(func(conn *fiberws.Conn) {
+		svc.ServeConnection(conn, ChatConnectionOptions{UserID: conn.Query("user"), RoomID: "room-1"})
+	}))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(listener) }()
+	defer func() { _ = app.Shutdown() }()
+
+	dial := func(user string) *websocket.Conn {
+		conn, resp, err := websocket.DefaultDialer.Dial("ws://"+listener.Addr().String()+"/ws?user="+user, nil)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return conn
+	}
+	connectedCount := func(user string) int {
+		concrete.hub.mu.RLock()
+		defer concrete.hub.mu.RUnlock()
+		count := 0
+		for client := range concrete.hub.rooms["room-1"] {
+			if client.options.UserID == user {
+				count++
+			}
+		}
+		return count
+	}
+
+	target1 := dial("7")
+	target2 := dial("7")
+	bystander := dial("8")
+	defer bystander.Close()
+	require.Eventually(t, func() bool { return connectedCount("7") == 2 && connectedCount("8") == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	_, err = svc.Disconnect(context.Background(), ActivityActor{ID: 1, Role: "student"}, "7", dto.ChatDisconnectRequest{})
+	require.ErrorIs(t, err, ErrChatModerationForbidden)
+
+	result, err := svc.Disconnect(context.Background(), ActivityActor{ID: 1, Role: "teacher"}, "7", dto.ChatDisconnectRequest{Reason: "spam", CooldownSeconds: 60})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.ClosedConnections)
+	require.NotNil(t, result.BlockedUntil)
+
+	for _, conn := range []*websocket.Conn{target1, target2} {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		_, _, readErr := conn.ReadMessage()
+		require.True(t, websocket.IsCloseError(readErr, websocket.ClosePolicyViolation), "unexpected error: %v", readErr)
+		_ = conn.Close()
+	}
+	require.Zero(t, connectedCount("7"))
+	require.Equal(t, 1, connectedCount("8"))
+
+	require.Len(t, activity.entries, 1)
+	require.Equal(t, "chat.user_disconnected", activity.entries[0].Action)
+	require.Equal(t, "spam", activity.entries[0].Metadata["reason"])
+
+	blocked := dial("7")
+	require.NoError(t, blocked.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, _, err = blocked.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "unexpected error: %v", err)
+	_ = blocked.Close()
+
+	fixed.Advance(time.Minute)
+	rejoined := dial("7")
+	defer rejoined.Close()
+	require.Eventually(t, func() bool { return connectedCount("7") == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	// A control event from another node closes local connections too.
+	event, err := json.Marshal(chatEvent{Source: "other-node", Control: &chatControl{Action: chatControlDisconnect, UserID: "7"}})
+	require.NoError(t, err)
+	concrete.handleEvent(event)
+	require.Zero(t, connectedCount("7"))
+	require.Equal(t, 1, connectedCount("8"))
+}
+
+func TestChatServiceGroupRoomMembership(t *testing.T) {
+	dsn := fmt.Sprintf("file:chat_rooms_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.ChatMessage{}, &models.ChatReadReceipt{}, &models.ChatRoom{}, &models.ChatRoomMember{}))
+
+	activity := &stubActivityRecorder{}
+	svc := NewChatService(repository.NewChatRepository(db), nil, nil, "", ChatStreamConfig{}, nil, validator.New(), activity, testLogger())
+	concrete := svc.(*chatService)
+	ctx := context.Background()
+
+	creator := ActivityActor{ID: 7, Role: "student"}
+	room, err := svc.CreateRoom(ctx, creator, dto.ChatRoomCreateRequest{Name: "Study group", Members: []string{"21", "22", "21"}})
+	require.NoError(t, err)
+	require.Contains(t, room.RoomID, "group:")
+	require.Equal(t, "7", room.CreatedBy)
+	require.ElementsMatch(t, []string{"7", "21", "22"}, room.Members)
+
+	send := func(userID, role string) error {
+		client := &chatClient{options: ChatConnectionOptions{UserID: userID, Role: role}}
+		return concrete.authorise(ctx, client, dto.ChatSendRequest{RoomID: room.RoomID, Content: "hello", Type: "text"})
+	}
+	require.NoError(t, send("21", "student"))
+	require.NoError(t, send("7", "student"))
+	require.NoError(t, send("99", "teacher"))
+	require.ErrorIs(t, send("30", "student"), ErrChatNotAuthorised)
+	// A room name containing the user ID no longer grants access to group rooms.
+	require.ErrorIs(t, send(room.RoomID, "student"), ErrChatNotAuthorised)
+
+	_, err = svc.AddRoomMembers(ctx, ActivityActor{ID: 21, Role: "student"}, room.RoomID, dto.ChatRoomMembersRequest{Members: []string{"30"}})
+	require.ErrorIs(t, err, ErrChatRoomForbidden)
+
+	room, err = svc.AddRoomMembers(ctx, creator, room.RoomID, dto.ChatRoomMembersRequest{Members: []string{"30", "22"}})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"7", "21", "22", "30"}, room.Members)
+	require.NoError(t, send("30", "student"))
+
+	room, err = svc.RemoveRoomMember(ctx, ActivityActor{ID: 99, Role: "teacher"}, room.RoomID, "21")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"7", "22", "30"}, room.Members)
+	require.ErrorIs(t, send("21", "student"), ErrChatNotAuthorised)
+
+	history := dto.ChatHistoryQuery{RoomID: room.RoomID}
+	_, err = svc.History(ctx, "21", "student", history)
+	require.ErrorIs(t, err, ErrChatNotAuthorised, "removed members lose the room's history")
+	_, err = svc.History(ctx, "22", "student", history)
+	require.NoError(t, err)
+	_, err = svc.History(ctx, "99", "teacher", history)
+	require.NoError(t, err)
+
+	summaries, err := svc.RoomSummaries(ctx, "21", "student", []string{room.RoomID, "room-open"})
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	require.Equal(t, "room-open", summaries[0].RoomID)
+
+	_, err = svc.RemoveRoomMember(ctx, creator, room.RoomID, "21")
+	require.ErrorIs(t, err, ErrChatRoomMemberNotFound)
+	_, err = svc.AddRoomMembers(ctx, creator, "group:missing", dto.ChatRoomMembersRequest{Members: []string{"30"}})
+	require.ErrorIs(t, err, ErrChatRoomNotFound)
+
+	require.Len(t, activity.entries, 3)
+	require.Equal(t, "chat.room_created", activity.entries[0].Action)
+	require.Equal(t, []string{"30"}, activity.entries[1].Metadata["members"])
+}
+
+func TestChatServiceGroupRoomConnectionsRequireMembership(t *testing.T) {
+	dsn := fmt.Sprintf("file:chat_room_sockets_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.ChatMessage{}, &models.ChatRoom{}, &models.ChatRoomMember{}))
+
+	svc := NewChatService(repository.NewChatRepository(db), nil, nil, "", ChatStreamConfig{}, nil, validator.New(), nil, testLogger())
+	concrete := svc.(*chatService)
+	creator := ActivityActor{ID: 7, Role: "student"}
+	room, err := svc.CreateRoom(context.Background(), creator, dto.ChatRoomCreateRequest{Name: "Study group", Members: []string{"21"}})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Get("/ws", fiberws.New(func(conn *fiberws.Conn) {
+		svc.ServeConnection(conn, ChatConnectionOptions{UserID: conn.Query("user"), Role: "student", RoomID: room.RoomID})
+	}))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(listener) }()
+	defer func() { _ = app.Shutdown() }()
+
+	dial := func(user string) *websocket.Conn {
+		conn, resp, err := websocket.DefaultDialer.Dial("ws://"+listener.Addr().String()+"/ws?user="+user, nil)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return conn
+	}
+	expectPolicyClose := func(conn *websocket.Conn) {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		_, _, readErr := conn.ReadMessage()
+		require.True(t, websocket.IsCloseError(readErr, websocket.ClosePolicyViolation), "unexpected error: %v", readErr)
+		_ = conn.Close()
+	}
+	connected := func(user string) bool {
+		concrete.hub.mu.RLock()
+		defer concrete.hub.mu.RUnlock()
+		for client := range concrete.hub.rooms[room.RoomID] {
+			if client.options.UserID == user {
+				return true
+			}
+		}
+		return false
+	}
+
+	expectPolicyClose(dial("30"))
+
+	member := dial("21")
+	require.Eventually(t, func() bool { return connected("21") }, 2*time.Second, 10*time.Millisecond)
+
+	_, err = svc.RemoveRoomMember(context.Background(), creator, room.RoomID, "21")
+	require.NoError(t, err)
+	expectPolicyClose(member)
+	require.False(t, connected("21"))
+}
+
+func TestChatServiceUnregisteredRoomsKeepRoleRules(t *testing.T) {
+	dsn := fmt.Sprintf("file:chat_rooms_legacy_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.ChatRoom{}, &models.ChatRoomMember{}))
+
+	svc := NewChatService(repository.NewChatRepository(db), nil, nil, "", ChatStreamConfig{}, nil, validator.New(), nil, testLogger())
+	concrete := svc.(*chatService)
+	client := &chatClient{options: ChatConnectionOptions{UserID: "5", Role: "student"}}
+
+	require.NoError(t, concrete.authorise(context.Background(), client, dto.ChatSendRequest{RoomID: "dm:5:9"}))
+	require.ErrorIs(t, concrete.authorise(context.Background(), client, dto.ChatSendRequest{RoomID: "dm:8:9"}), ErrChatNotAuthorised)
+}
+
+func TestChatServiceShutdownDrainsClients(t *testing.T) {
+	svc := NewChatService(nil, nil, nil, "", ChatStreamConfig{}, nil, validator.New(), nil, testLogger())
+	concrete := svc.(*chatService)
+
+	app := fiber.New()
+	app.Get("/ws", fiberws.New(func(conn *fiberws.Conn) {
+		svc.ServeConnection(conn, ChatConnectionOptions{UserID: conn.Query("user"), RoomID: "room-1"})
+	}))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(listener) }()
+	defer func() { _ = app.Shutdown() }()
+
+	dial := func(user string) *websocket.Conn {
+		conn, resp, err := websocket.DefaultDialer.Dial("ws://"+listener.Addr().String()+"/ws?user="+user, nil)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return conn
+	}
+
+	clients := []*websocket.Conn{dial("7"), dial("8")}
+	require.Eventually(t, func() bool {
+		concrete.hub.mu.RLock()
+		defer concrete.hub.mu.RUnlock()
+		return len(concrete.hub.rooms["room-1"]) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, svc.Shutdown(ctx))
+
+	for _, conn := range clients {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		var notice dto.ChatMessageResponse
+		require.NoError(t, conn.ReadJSON(&notice))
+		require.Equal(t, "system", notice.Type)
+		require.Equal(t, "room-1", notice.RoomID)
+
+		_, _, err := conn.ReadMessage()
+		require.True(t, websocket.IsCloseError(err, websocket.CloseServiceRestart), "unexpected error: %v", err)
+		_ = conn.Close()
+	}
+
+	late := dial("9")
+	require.NoError(t, late.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, _, err = late.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseServiceRestart), "unexpected error: %v", err)
+	_ = late.Close()
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultChatStreamMaxLen = 10000
+	chatStreamField         = "event"
+	chatStreamBatch         = 100
+	chatStreamBlock         = 2 * time.Second
+	chatStreamRetryDelay    = time.Second
+)
+
+// ChatStreamConfig switches cross-node chat fan-out from Redis pub/sub to a
+// Redis stream read through consumer groups, so a node whose Redis
+// connection drops picks up where it left off instead of missing messages.
+type ChatStreamConfig struct {
+	Enabled bool
+	// MaxLen caps the stream length, approximately. Defaults to 10000.
+	MaxLen int64
+	// MaxAge trims entries older than this, approximately. Zero keeps them
+	// until MaxLen pushes them out.
+	MaxAge time.Duration
+}
+
+// streamGroup is this node's consumer group. Every node needs every message
+// to reach its own sockets, so each reads through a group of its own, with
+// the node ID as the consumer name, rather than sharing one group that would
+// split messages between nodes.
+func (s *chatService) streamGroup() string {
+	return "chat:" + s.nodeID
+}
+
+func (s *chatService) appendStream(ctx context.Context, payload []byte) error {
+	maxLen := s.streams.MaxLen
+	if maxLen <= 0 {
+		maxLen = defaultChatStreamMaxLen
+	}
+
+	// XADD takes a single trim strategy, so age trimming is a separate XTRIM.
+	_, err := s.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: s.streamKey,
+			MaxLen: maxLen,
+			Approx: true,
+			Values: map[string]interface{}{chatStreamField: payload},
+		})
+		if s.streams.MaxAge > 0 {
+			cutoff := s.clock.Now().Add(-s.streams.MaxAge).UnixMilli()
+			pipe.XTrimMinIDApprox(ctx, s.streamKey, fmt.Sprintf("%d-0", cutoff), 0)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *chatService) consumeStream(ctx context.Context) {
+	group := s.streamGroup()
+	if err := s.createStreamGroup(ctx, group); err != nil {
+		s.logger.Error().Err(err).Str("group", group).Msg("failed to create chat stream consumer group")
+		return
+	}
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), chatStreamBlock)
+		defer cancel()
+		if err := s.redis.XGroupDestroy(cleanupCtx, s.streamKey, group).Err(); err != nil {
+			s.logger.Warn().Err(err).Str("group", group).Msg("failed to remove chat stream consumer group")
+		}
+	}()
+
+	for {
+		streams, err := s.redis.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: s.nodeID,
+			Streams:  []string{s.streamKey, ">"},
+			Count:    chatStreamBatch,
+			Block:    chatStreamBlock,
+		}).Result()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			s.logger.Warn().Err(err).Msg("chat stream read failed; retrying")
+			// The stream may have been deleted along with the group.
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				_ = s.createStreamGroup(ctx, group)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(chatStreamRetryDelay):
+			}
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				payload, _ := msg.Values[chatStreamField].(string)
+				// Undecodable entries are acked too; redelivery cannot fix them.
+				s.handleEvent([]byte(payload))
+				if err := s.redis.XAck(ctx, s.streamKey, group, msg.ID).Err(); err != nil {
+					s.logger.Warn().Err(err).Str("entry_id", msg.ID).Msg("failed to ack chat stream entry")
+				}
+			}
+		}
+	}
+}
+
+// createStreamGroup starts the group at the stream's end: history before the
+// node started is served from the database.
+func (s *chatService) createStreamGroup(ctx context.Context, group string) error {
+	err := s.redis.XGroupCreateMkStream(ctx, s.streamKey, group, "$").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
@@ -2,19 +2,30 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/featureflags"
+	"github.com/noah-isme/gema-go-api/internal/logging"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
 	"github.com/noah-isme/gema-go-api/pkg/ai"
@@ -25,7 +36,11 @@ import (
 type CodingSubmissionService interface {
 	Submit(ctx context.Context, studentID uint, payload dto.CodingSubmissionRequest) (dto.CodingSubmissionResponse, error)
 	Get(ctx context.Context, id uint, viewerID uint, role string) (dto.CodingSubmissionResponse, error)
-	Evaluate(ctx context.Context, id uint, evaluatorID uint, role string) (dto.CodingEvaluationResponse, error)
+	List(ctx context.Context, filter dto.CodingSubmissionFilter, viewerID uint, role string) (dto.CodingSubmissionListResponse, error)
+	Evaluate(ctx context.Context, id uint, evaluatorID uint, role string, force bool) (dto.CodingEvaluationResponse, error)
+	Log(ctx context.Context, id uint, role string) (dto.SubmissionDownloadResponse, error)
+	Stream(ctx context.Context, studentID uint, payload dto.CodingSubmissionRequest, emit func(dto.CodingStreamMessage)) (dto.CodingStreamMessage, error)
+	LanguageStats(ctx context.Context) ([]dto.CodingLanguageStats, error)
 }
 
 // ErrCodingSubmissionNotFound indicates the submission cannot be located.
@@ -37,21 +52,118 @@ var ErrCodingSubmissionForbidden = errors.New("forbidden")
 // ErrUnsupportedLanguage indicates the requested language is not allowed.
 var ErrUnsupportedLanguage = errors.New("unsupported language")
 
+// ErrLanguageRuntimeUnavailable indicates the executor refused the language's container image.
+var ErrLanguageRuntimeUnavailable = errors.New("language runtime unavailable")
+
+// ErrExecutorBusy indicates every code runner is in use and the wait queue is full.
+var ErrExecutorBusy = errors.New("code runners are busy")
+
+// ErrStreamingUnavailable indicates the configured executor cannot stream output.
+var ErrStreamingUnavailable = errors.New("streaming execution unavailable")
+
+// ErrCodingSubmissionLogNotFound indicates no full execution log was stored
+// for the submission.
+var ErrCodingSubmissionLogNotFound = errors.New("no full log stored for this submission")
+
 // ErrEvaluatorUnavailable indicates the AI evaluator is not configured.
 var ErrEvaluatorUnavailable = errors.New("evaluator unavailable")
 
-// CodingSubmissionConfig describes execution configuration knobs.
+// Sandbox defaults applied when CodingSubmissionConfig leaves a limit unset.
+const (
+	defaultCodingPidsLimit   = 64
+	defaultCodingDiskQuotaMB = 64
+)
+
+// defaultCodingInlineOutputBytes caps the output and error stored on a
+// submission row when CodingSubmissionConfig leaves InlineOutputBytes unset.
+const defaultCodingInlineOutputBytes = 64 << 10
+
+// outputLimitNote is recorded with a run's errors when its output exceeded the
+// executor's output limit and was cut short.
+const outputLimitNote = "output truncated: the program printed more than the output limit allows"
+
+// codingLogURLTTL bounds how long a signed execution log URL works.
+const codingLogURLTTL = 5 * time.Minute
+
+// evaluationCacheProvider is recorded as the provider of evaluations served
+// from the evaluation cache.
+const evaluationCacheProvider = "cache"
+
+// CodingSubmissionConfig describes execution configuration knobs. PidsLimit
+// defaults to 64 processes and DiskQuotaMB to a 64 MB scratch tmpfs; CPUQuota
+// (with CPUPeriod, in microseconds) replaces the CPUShares weight when set.
+// Evaluator results are reused from EvaluationCache for EvaluationCacheTTL;
+// a nil cache or a non-positive TTL disables reuse. Output and errors longer
+// than InlineOutputBytes (64 KiB by default) are truncated on the row; when
+// LogStorage is set the full log is stored there first. MaxOutputBytes caps
+// the output the executor reads back from each run; zero leaves the
+// executor's own limit in place.
 type CodingSubmissionConfig struct {
-	ExecutionTimeout time.Duration
-	MemoryLimitMB    int
-	CPUShares        int
-	WorkspaceRoot    string
+	ExecutionTimeout   time.Duration
+	MemoryLimitMB      int
+	CPUShares          int
+	CPUQuota           int
+	CPUPeriod          int
+	PidsLimit          int
+	DiskQuotaMB        int
+	MaxOutputBytes     int64
+	WorkspaceRoot      string
+	EvaluationCache    cache.Store
+	EvaluationCacheTTL time.Duration
+	InlineOutputBytes  int
+	LogStorage         FileStorage
 }
 
 type languageConfig struct {
 	Image    string
 	FileName string
-	Command  []string
+	// CompileCmd, when set, builds the program before Command runs so
+	// compiler errors are reported apart from runtime failures. Runs only get
+	// a read-only workspace, so the build writes its output to the capped
+	// /tmp first and copies it into the workspace once it succeeds.
+	CompileCmd []string
+	Command    []string
+}
+
+var defaultCodingLanguages = map[string]languageConfig{
+	"python": {
+		Image:    "python:3.11-alpine",
+		FileName: "main.py",
+		Command:  []string{"python", "main.py"},
+	},
+	"javascript": {
+		Image:    "node:20-alpine",
+		FileName: "main.js",
+		Command:  []string{"node", "main.js"},
+	},
+	"go": {
+		Image:      "golang:1.22-alpine",
+		FileName:   "main.go",
+		CompileCmd: []string{"sh", "-c", "go build -o /tmp/main main.go && cp /tmp/main main"},
+		Command:    []string{"./main"},
+	},
+	"java": {
+		Image:      "eclipse-temurin:21-jdk-alpine",
+		FileName:   "Main.java",
+		CompileCmd: []string{"sh", "-c", "javac -d /tmp/classes Main.java && cp -R /tmp/classes/. ."},
+		Command:    []string{"java", "-cp", ".", "Main"},
+	},
+	"cpp": {
+		Image:      "gcc:13",
+		FileName:   "main.cpp",
+		CompileCmd: []string{"sh", "-c", "g++ -std=c++17 -O2 -o /tmp/main main.cpp && cp /tmp/main main"},
+		Command:    []string{"./main"},
+	},
+}
+
+// CodingLanguageImages returns the container images used by the supported languages.
+func CodingLanguageImages() []string {
+	images := make([]string, 0, len(defaultCodingLanguages))
+	for _, lang := range defaultCodingLanguages {
+		images = append(images, lang.Image)
+	}
+	sort.Strings(images)
+	return images
 }
 
 type codingSubmissionService struct {
@@ -63,6 +175,7 @@ type codingSubmissionService struct {
 	logger      zerolog.Logger
 	config      CodingSubmissionConfig
 	languages   map[string]languageConfig
+	clock       clock.Clock
 }
 
 // NewCodingSubmissionService constructs a new coding submission service.
@@ -70,6 +183,15 @@ func NewCodingSubmissionService(submissionRepo repository.CodingSubmissionReposi
 	if cfg.WorkspaceRoot == "" {
 		cfg.WorkspaceRoot = os.TempDir()
 	}
+	if cfg.PidsLimit <= 0 {
+		cfg.PidsLimit = defaultCodingPidsLimit
+	}
+	if cfg.DiskQuotaMB <= 0 {
+		cfg.DiskQuotaMB = defaultCodingDiskQuotaMB
+	}
+	if cfg.InlineOutputBytes <= 0 {
+		cfg.InlineOutputBytes = defaultCodingInlineOutputBytes
+	}
 
 	service := &codingSubmissionService{
 		submissions: submissionRepo,
@@ -79,104 +201,473 @@ func NewCodingSubmissionService(submissionRepo repository.CodingSubmissionReposi
 		validator:   validate,
 		logger:      logger.With().Str("component", "coding_submission_service").Logger(),
 		config:      cfg,
-		languages: map[string]languageConfig{
-			"python": {
-				Image:    "python:3.11-alpine",
-				FileName: "main.py",
-				Command:  []string{"python", "main.py"},
-			},
-			"javascript": {
-				Image:    "node:20-alpine",
-				FileName: "main.js",
-				Command:  []string{"node", "main.js"},
-			},
-			"go": {
-				Image:    "golang:1.22-alpine",
-				FileName: "main.go",
-				Command:  []string{"sh", "-c", "go run main.go"},
-			},
-		},
+		languages:   defaultCodingLanguages,
+		clock:       clock.Real(),
 	}
 
 	return service
 }
 
 func (s *codingSubmissionService) Submit(ctx context.Context, studentID uint, payload dto.CodingSubmissionRequest) (dto.CodingSubmissionResponse, error) {
-	if err := s.validator.Struct(payload); err != nil {
+	if featureflags.Enabled(ctx, featureflags.AsyncCodingExecution) {
+		submission, err := s.submitAsync(ctx, studentID, payload)
+		if err != nil {
+			return dto.CodingSubmissionResponse{}, err
+		}
+		return dto.NewCodingSubmissionResponse(submission, true, false), nil
+	}
+
+	submission, _, err := s.submit(ctx, studentID, payload, nil)
+	if err != nil {
 		return dto.CodingSubmissionResponse{}, err
 	}
+	return dto.NewCodingSubmissionResponse(submission, true, false), nil
+}
+
+// Stream runs the submission once against its stdin, forwarding output chunks
+// to emit while the program runs, and stores it exactly as Submit would. For
+// tasks with test cases the streamed run is interactive only; the stored
+// result still comes from the regular graded runs, whose output is never
+// streamed so hidden cases stay hidden. The returned message is the final
+// result frame.
+func (s *codingSubmissionService) Stream(ctx context.Context, studentID uint, payload dto.CodingSubmissionRequest, emit func(dto.CodingStreamMessage)) (dto.CodingStreamMessage, error) {
+	if _, ok := s.executor.(dockerexec.StreamingExecutor); !ok {
+		return dto.CodingStreamMessage{}, ErrStreamingUnavailable
+	}
+
+	onChunk := func(chunk dockerexec.ExecutionChunk) {
+		emit(dto.CodingStreamMessage{Type: dto.CodingStreamOutput, Stream: chunk.Stream, Data: string(chunk.Data)})
+	}
+
+	submission, run, err := s.submit(ctx, studentID, payload, onChunk)
+	if err != nil {
+		return dto.CodingStreamMessage{}, err
+	}
+
+	response := dto.NewCodingSubmissionResponse(submission, true, false)
+	exitCode := run.ExitCode
+	return dto.CodingStreamMessage{
+		Type:       dto.CodingStreamResult,
+		ExitCode:   &exitCode,
+		TimedOut:   run.TimedOut,
+		Truncated:  run.Truncated,
+		DurationMs: run.Duration.Milliseconds(),
+		CPUTimeMs:  int64(run.CPUUsageNanosec / uint64(time.Millisecond)),
+		MemoryKB:   run.MemoryUsageBytes / 1024,
+		Submission: &response,
+	}, nil
+}
+
+// codingRun is a validated submission ready to execute.
+type codingRun struct {
+	lang       languageConfig
+	task       models.CodingTask
+	env        []string
+	submission models.CodingSubmission
+}
+
+// submit validates, executes and stores a submission. When onChunk is set the
+// student's own run is streamed through the executor and its result returned.
+func (s *codingSubmissionService) submit(ctx context.Context, studentID uint, payload dto.CodingSubmissionRequest, onChunk func(dockerexec.ExecutionChunk)) (models.CodingSubmission, dockerexec.ExecutionResult, error) {
+	run, err := s.prepare(ctx, studentID, payload)
+	if err != nil {
+		return models.CodingSubmission{}, dockerexec.ExecutionResult{}, err
+	}
+
+	submission := run.submission
+	result, err := s.execute(ctx, run, &submission, onChunk)
+	if err != nil {
+		return models.CodingSubmission{}, result, err
+	}
+
+	s.capOutput(ctx, &submission)
+	if err := s.submissions.Create(ctx, &submission); err != nil {
+		return models.CodingSubmission{}, result, err
+	}
+
+	submission.Task = run.task
+	return submission, result, nil
+}
+
+// submitAsync stores the submission as pending and executes it in the
+// background, for requests carrying the async coding execution flag. The
+// caller polls the submission for the result. Failures that submit would
+// return, such as busy code runners, mark the submission failed instead.
+func (s *codingSubmissionService) submitAsync(ctx context.Context, studentID uint, payload dto.CodingSubmissionRequest) (models.CodingSubmission, error) {
+	run, err := s.prepare(ctx, studentID, payload)
+	if err != nil {
+		return models.CodingSubmission{}, err
+	}
+
+	submission := run.submission
+	submission.Status = models.CodingSubmissionStatusPending
+	if err := s.submissions.Create(ctx, &submission); err != nil {
+		return models.CodingSubmission{}, err
+	}
+
+	// The request context ends, and fiber recycles it, once the response is
+	// sent; only the correlation ID carries over to the background run.
+	background := logging.WithCorrelationID(context.Background(), logging.CorrelationID(ctx))
+	go s.finishAsync(background, run, submission)
+
+	submission.Task = run.task
+	return submission, nil
+}
+
+func (s *codingSubmissionService) finishAsync(ctx context.Context, run codingRun, submission models.CodingSubmission) {
+	if _, err := s.execute(ctx, run, &submission, nil); err != nil {
+		submission.Status = models.CodingSubmissionStatusFailed
+		submission.Error = err.Error()
+	}
+
+	s.capOutput(ctx, &submission)
+	if err := s.submissions.Update(ctx, &submission); err != nil {
+		logging.FromContext(ctx, s.logger).Error().Err(err).Uint("submission_id", submission.ID).Msg("failed to store async coding run")
+	}
+}
+
+// prepare validates the payload and resolves its language and task.
+func (s *codingSubmissionService) prepare(ctx context.Context, studentID uint, payload dto.CodingSubmissionRequest) (codingRun, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return codingRun{}, err
+	}
 
 	language := strings.ToLower(strings.TrimSpace(payload.Language))
 	langCfg, ok := s.languages[language]
 	if !ok {
-		return dto.CodingSubmissionResponse{}, ErrUnsupportedLanguage
+		return codingRun{}, ErrUnsupportedLanguage
 	}
 
 	task, err := s.tasks.GetByID(ctx, payload.TaskID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return dto.CodingSubmissionResponse{}, ErrCodingTaskNotFound
+			return codingRun{}, ErrCodingTaskNotFound
 		}
-		return dto.CodingSubmissionResponse{}, err
+		return codingRun{}, err
+	}
+	if !task.Active {
+		return codingRun{}, ErrCodingTaskInactive
 	}
 
+	env, envNames := executionEnv(task.EnvVars())
+	return codingRun{
+		lang: langCfg,
+		task: task,
+		env:  env,
+		submission: models.CodingSubmission{
+			TaskID:    payload.TaskID,
+			StudentID: studentID,
+			Language:  language,
+			Source:    payload.Source,
+			Stdin:     payload.Stdin,
+			EnvNames:  strings.Join(envNames, ","),
+		},
+	}, nil
+}
+
+// execute builds and runs the program in a fresh workspace, recording the
+// outcome on submission. Errors are reserved for runs that could not take
+// place at all.
+func (s *codingSubmissionService) execute(ctx context.Context, run codingRun, submission *models.CodingSubmission, onChunk func(dockerexec.ExecutionChunk)) (dockerexec.ExecutionResult, error) {
+	langCfg, task, env := run.lang, run.task, run.env
+
 	workspace, err := os.MkdirTemp(s.config.WorkspaceRoot, "submission-")
 	if err != nil {
-		return dto.CodingSubmissionResponse{}, fmt.Errorf("create workspace: %w", err)
+		return dockerexec.ExecutionResult{}, fmt.Errorf("create workspace: %w", err)
 	}
 	defer os.RemoveAll(workspace)
 
 	filePath := filepath.Join(workspace, langCfg.FileName)
-	if err := os.WriteFile(filePath, []byte(payload.Source), 0600); err != nil {
-		return dto.CodingSubmissionResponse{}, fmt.Errorf("write source: %w", err)
+	if err := os.WriteFile(filePath, []byte(submission.Source), 0600); err != nil {
+		return dockerexec.ExecutionResult{}, fmt.Errorf("write source: %w", err)
+	}
+
+	var result dockerexec.ExecutionResult
+	var execErr error
+	compiled := true
+	if len(langCfg.CompileCmd) > 0 {
+		compiled, result, err = s.compile(ctx, langCfg, workspace, submission)
+		if err != nil {
+			return result, err
+		}
+	}
+	if !compiled {
+		return result, nil
+	}
+
+	if onChunk != nil {
+		streamer := s.executor.(dockerexec.StreamingExecutor)
+		result, execErr = streamer.RunStream(ctx, s.executionRequest(langCfg, workspace, submission.Stdin, env), onChunk)
+		if err := rejectedExecution(execErr); err != nil {
+			return result, err
+		}
+	}
+
+	if len(task.TestCases) > 0 {
+		return result, s.gradeTestCases(ctx, langCfg, workspace, task, env, submission)
+	}
+
+	if onChunk == nil {
+		result, execErr = s.executor.Run(ctx, s.executionRequest(langCfg, workspace, submission.Stdin, env))
+		if err := rejectedExecution(execErr); err != nil {
+			return result, err
+		}
 	}
 
-	req := dockerexec.ExecutionRequest{
+	submission.Output = result.Stdout
+	submission.Error = combineErrors(result.Stderr, execErr)
+	submission.CPUTimeMs = result.Duration.Milliseconds()
+	submission.MemoryKB = result.MemoryUsageBytes / 1024
+	submission.Status = executionStatus(result, execErr)
+	if submission.Status == models.CodingSubmissionStatusFailed && submission.Error == "" {
+		submission.Error = fmt.Sprintf("process exited with code %d", result.ExitCode)
+	}
+	submission.Error = noteTruncation(submission.Error, result)
+
+	if submission.Status == models.CodingSubmissionStatusCompleted && task.ExpectedOutput != "" {
+		comparison := compareOutput(task, result.Stdout)
+		submission.Comparison = comparison.Mode
+		submission.Matched = &comparison.Matched
+		submission.OutputDiff = comparison.Diff
+	}
+	return result, nil
+}
+
+// capOutput truncates output and errors longer than the inline limit. The full
+// text is stored as a log first when LogStorage is configured; a failed upload
+// is logged and the submission is kept with truncated output only.
+func (s *codingSubmissionService) capOutput(ctx context.Context, submission *models.CodingSubmission) {
+	limit := s.config.InlineOutputBytes
+	if len(submission.Output) <= limit && len(submission.Error) <= limit {
+		return
+	}
+
+	if s.config.LogStorage != nil {
+		var log strings.Builder
+		log.WriteString("== stdout ==\n")
+		log.WriteString(submission.Output)
+		log.WriteString("\n== stderr ==\n")
+		log.WriteString(submission.Error)
+
+		name := fmt.Sprintf("coding-log-task%d-student%d-%d.log", submission.TaskID, submission.StudentID, s.clock.Now().UnixNano())
+		url, err := s.config.LogStorage.Upload(ctx, name, strings.NewReader(log.String()))
+		if err != nil {
+			s.logger.Warn().Err(err).Uint("task_id", submission.TaskID).Msg("failed to store full execution log")
+		} else {
+			submission.LogURL = url
+		}
+	}
+
+	submission.Output = truncateOutput(submission.Output, limit)
+	submission.Error = truncateOutput(submission.Error, limit)
+}
+
+// truncateOutput cuts text to at most limit bytes, on a rune boundary, and
+// appends a marker saying how much was dropped.
+func truncateOutput(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + fmt.Sprintf("\n... [truncated %d bytes]", len(text)-cut)
+}
+
+// Log returns a URL for the full execution log of a submission whose output
+// was truncated. Only teachers and admins may fetch it. When the log storage
+// can sign downloads the URL expires after codingLogURLTTL.
+func (s *codingSubmissionService) Log(ctx context.Context, id uint, role string) (dto.SubmissionDownloadResponse, error) {
+	if !IsStaffRole(role) {
+		return dto.SubmissionDownloadResponse{}, ErrCodingSubmissionForbidden
+	}
+
+	submission, err := s.submissions.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.SubmissionDownloadResponse{}, ErrCodingSubmissionNotFound
+		}
+		return dto.SubmissionDownloadResponse{}, err
+	}
+	if submission.LogURL == "" {
+		return dto.SubmissionDownloadResponse{}, ErrCodingSubmissionLogNotFound
+	}
+
+	signer, ok := s.config.LogStorage.(DownloadSigner)
+	if !ok {
+		return dto.SubmissionDownloadResponse{URL: submission.LogURL}, nil
+	}
+
+	now := s.clock.Now()
+	expiresAt := now.Add(codingLogURLTTL)
+	signed, err := signer.SignDownload(submission.LogURL, now, expiresAt)
+	if err != nil {
+		s.logger.Warn().Err(err).Uint("submission_id", id).Msg("execution log not signable; returning stored url")
+		return dto.SubmissionDownloadResponse{URL: submission.LogURL}, nil
+	}
+	return dto.SubmissionDownloadResponse{URL: signed, ExpiresAt: &expiresAt}, nil
+}
+
+// sandboxEnv points home directories and build caches at /tmp, the only
+// writable path in a container with a read-only root filesystem, so the
+// toolchains' own writes count against the scratch quota too.
+var sandboxEnv = []string{"HOME=/tmp", "XDG_CACHE_HOME=/tmp/.cache", "GOCACHE=/tmp/.cache/go-build"}
+
+// executionRequest builds the request to run the program. env holds the
+// task's validated KEY=value pairs; the build step runs without them.
+func (s *codingSubmissionService) executionRequest(langCfg languageConfig, workspace, stdin string, env []string) dockerexec.ExecutionRequest {
+	return dockerexec.ExecutionRequest{
 		Image:           langCfg.Image,
 		Cmd:             langCfg.Command,
+		Env:             append(append([]string(nil), sandboxEnv...), env...),
+		Stdin:           []byte(stdin),
 		Timeout:         s.config.ExecutionTimeout,
 		Workspace:       workspace,
 		WorkingDir:      "/workspace",
 		MemoryLimitMB:   int64(s.config.MemoryLimitMB),
 		CPUShares:       int64(s.config.CPUShares),
+		CPUQuota:        int64(s.config.CPUQuota),
+		CPUPeriod:       int64(s.config.CPUPeriod),
+		PidsLimit:       int64(s.config.PidsLimit),
+		DiskQuotaMB:     int64(s.config.DiskQuotaMB),
+		MaxOutputBytes:  s.config.MaxOutputBytes,
 		NetworkDisabled: true,
-		ReadOnlyFS:      false,
+		ReadOnlyFS:      true,
 	}
+}
 
-	result, execErr := s.executor.Run(ctx, req)
+// compile runs the language's build step in the workspace and reports
+// whether the program may run. A failed build marks the submission as a
+// compile error carrying the compiler message; only infrastructure errors are
+// returned.
+func (s *codingSubmissionService) compile(ctx context.Context, langCfg languageConfig, workspace string, submission *models.CodingSubmission) (bool, dockerexec.ExecutionResult, error) {
+	req := s.executionRequest(langCfg, workspace, "", nil)
+	req.Cmd = langCfg.CompileCmd
+	req.WritableWorkspace = true
 
-	submission := models.CodingSubmission{
-		TaskID:    payload.TaskID,
-		StudentID: studentID,
-		Language:  language,
-		Source:    payload.Source,
-		Output:    result.Stdout,
-		Error:     combineErrors(result.Stderr, execErr),
-		CPUTimeMs: result.Duration.Milliseconds(),
-		MemoryKB:  result.MemoryUsageBytes / 1024,
+	result, execErr := s.executor.Run(ctx, req)
+	if err := rejectedExecution(execErr); err != nil {
+		return false, result, err
 	}
 
-	switch {
-	case execErr != nil && result.TimedOut:
+	switch executionStatus(result, execErr) {
+	case models.CodingSubmissionStatusCompleted:
+		// Successful builds may still print warnings worth showing.
+		submission.CompileOutput = result.Stdout + result.Stderr
+		return true, result, nil
+	case models.CodingSubmissionStatusTimeout:
 		submission.Status = models.CodingSubmissionStatusTimeout
-	case execErr != nil:
-		submission.Status = models.CodingSubmissionStatusFailed
-	case result.ExitCode != 0:
-		submission.Status = models.CodingSubmissionStatusFailed
-		if submission.Error == "" {
-			submission.Error = fmt.Sprintf("process exited with code %d", result.ExitCode)
-		}
 	default:
-		submission.Status = models.CodingSubmissionStatusCompleted
+		submission.Status = models.CodingSubmissionStatusCompileError
 	}
 
-	if err := s.submissions.Create(ctx, &submission); err != nil {
-		return dto.CodingSubmissionResponse{}, err
+	submission.CompileOutput = result.Stdout
+	submission.CompileError = combineErrors(result.Stderr, execErr)
+	if submission.CompileError == "" {
+		submission.CompileError = fmt.Sprintf("compiler exited with code %d", result.ExitCode)
 	}
+	return false, result, nil
+}
+
+// gradeTestCases runs the submission once per test case, comparing trimmed
+// stdout against each expected output, and scores the weighted pass ratio.
+func (s *codingSubmissionService) gradeTestCases(ctx context.Context, langCfg languageConfig, workspace string, task models.CodingTask, env []string, submission *models.CodingSubmission) error {
+	results := make([]models.CodingTestCaseResult, 0, len(task.TestCases))
+	var totalWeight, passedWeight float64
+	submission.Status = models.CodingSubmissionStatusCompleted
+
+	for _, testCase := range task.TestCases {
+		result, execErr := s.executor.Run(ctx, s.executionRequest(langCfg, workspace, testCase.Input, env))
+		if err := rejectedExecution(execErr); err != nil {
+			return err
+		}
 
-	submission.Task = task
-	response := dto.NewCodingSubmissionResponse(submission, true)
-	return response, nil
+		status := executionStatus(result, execErr)
+		caseResult := models.CodingTestCaseResult{
+			TestCaseID:   testCase.ID,
+			Hidden:       testCase.Hidden,
+			Weight:       testCase.EffectiveWeight(),
+			ActualOutput: result.Stdout,
+			Error:        combineErrors(result.Stderr, execErr),
+			TimedOut:     result.TimedOut,
+			CPUTimeMs:    result.Duration.Milliseconds(),
+		}
+		if status == models.CodingSubmissionStatusFailed && caseResult.Error == "" {
+			caseResult.Error = fmt.Sprintf("process exited with code %d", result.ExitCode)
+		}
+		caseResult.Error = noteTruncation(caseResult.Error, result)
+		caseResult.Passed = status == models.CodingSubmissionStatusCompleted && trimOutput(result.Stdout) == trimOutput(testCase.ExpectedOutput)
+		results = append(results, caseResult)
+
+		totalWeight += caseResult.Weight
+		if caseResult.Passed {
+			passedWeight += caseResult.Weight
+		}
+
+		submission.CPUTimeMs += caseResult.CPUTimeMs
+		if memory := result.MemoryUsageBytes / 1024; memory > submission.MemoryKB {
+			submission.MemoryKB = memory
+		}
+
+		if status != models.CodingSubmissionStatusCompleted && submission.Status != models.CodingSubmissionStatusTimeout {
+			submission.Status = status
+		}
+	}
+
+	if visible := reportableResult(results); visible != nil {
+		submission.Output = visible.ActualOutput
+		submission.Error = visible.Error
+	} else if submission.Status != models.CodingSubmissionStatusCompleted {
+		submission.Error = "a hidden test case failed to run"
+	}
+
+	score := math.Round(passedWeight/totalWeight*10000) / 100
+	submission.Score = &score
+	submission.SetTestResults(results)
+	return nil
+}
+
+// reportableResult picks the visible case whose run best explains the
+// submission: the first one with an error, otherwise the first visible case.
+func reportableResult(results []models.CodingTestCaseResult) *models.CodingTestCaseResult {
+	var first *models.CodingTestCaseResult
+	for i := range results {
+		if results[i].Hidden {
+			continue
+		}
+		if results[i].Error != "" {
+			return &results[i]
+		}
+		if first == nil {
+			first = &results[i]
+		}
+	}
+	return first
+}
+
+// rejectedExecution maps executor errors that mean the program never ran to
+// service errors; nil means the run happened and execErr describes its outcome.
+func rejectedExecution(execErr error) error {
+	switch {
+	case errors.Is(execErr, dockerexec.ErrImageNotAllowed):
+		return fmt.Errorf("%w: %v", ErrLanguageRuntimeUnavailable, execErr)
+	case errors.Is(execErr, dockerexec.ErrExecutorBusy):
+		return fmt.Errorf("%w: %v", ErrExecutorBusy, execErr)
+	default:
+		return nil
+	}
+}
+
+func executionStatus(result dockerexec.ExecutionResult, execErr error) string {
+	switch {
+	case execErr != nil && result.TimedOut:
+		return models.CodingSubmissionStatusTimeout
+	case execErr != nil, result.ExitCode != 0:
+		return models.CodingSubmissionStatusFailed
+	default:
+		return models.CodingSubmissionStatusCompleted
+	}
 }
 
 func (s *codingSubmissionService) Get(ctx context.Context, id uint, viewerID uint, role string) (dto.CodingSubmissionResponse, error) {
@@ -193,11 +684,70 @@ func (s *codingSubmissionService) Get(ctx context.Context, id uint, viewerID uin
 		submission.Source = ""
 	}
 
-	return dto.NewCodingSubmissionResponse(submission, includeSource), nil
+	includeHidden := IsStaffRole(role)
+	return dto.NewCodingSubmissionResponse(submission, includeSource, includeHidden), nil
 }
 
-func (s *codingSubmissionService) Evaluate(ctx context.Context, id uint, evaluatorID uint, role string) (dto.CodingEvaluationResponse, error) {
-	if !s.canEvaluate(role) {
+// List returns submissions matching filter, newest first. Students only see
+// their own submissions; staff may filter across students.
+func (s *codingSubmissionService) List(ctx context.Context, filter dto.CodingSubmissionFilter, viewerID uint, role string) (dto.CodingSubmissionListResponse, error) {
+	studentID := filter.StudentID
+	if !IsStaffRole(role) {
+		if viewerID == 0 {
+			return dto.CodingSubmissionListResponse{}, ErrCodingSubmissionForbidden
+		}
+		studentID = viewerID
+	}
+
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	submissions, total, err := s.submissions.List(ctx, repository.CodingSubmissionQuery{
+		StudentID: studentID,
+		TaskID:    filter.TaskID,
+		Status:    strings.ToLower(strings.TrimSpace(filter.Status)),
+		Language:  strings.TrimSpace(filter.Language),
+		Offset:    (page - 1) * pageSize,
+		Limit:     pageSize,
+	})
+	if err != nil {
+		return dto.CodingSubmissionListResponse{}, err
+	}
+
+	includeHidden := IsStaffRole(role)
+	items := make([]dto.CodingSubmissionResponse, 0, len(submissions))
+	for _, submission := range submissions {
+		includeSource := s.canViewSource(viewerID, role, submission)
+		if !includeSource {
+			submission.Source = ""
+		}
+		items = append(items, dto.NewCodingSubmissionResponse(submission, includeSource, includeHidden))
+	}
+
+	return dto.CodingSubmissionListResponse{
+		Items: items,
+		Pagination: dto.Pagination{
+			Page:       page,
+			PageSize:   pageSize,
+			TotalItems: int(total),
+		},
+	}, nil
+}
+
+// Evaluate grades the submission with the configured evaluator. Identical
+// submissions to the same task reuse a cached result unless force is set; the
+// evaluation is still recorded, with "cache" as its provider.
+func (s *codingSubmissionService) Evaluate(ctx context.Context, id uint, evaluatorID uint, role string, force bool) (dto.CodingEvaluationResponse, error) {
+	if !IsStaffRole(role) {
 		return dto.CodingEvaluationResponse{}, ErrCodingSubmissionForbidden
 	}
 	if s.evaluator == nil {
@@ -213,18 +763,42 @@ func (s *codingSubmissionService) Evaluate(ctx context.Context, id uint, evaluat
 	}
 
 	task := submission.Task
+	provider := s.providerName()
+	cacheKey := s.evaluationCacheKey(task, submission)
 
-	result, err := s.evaluator.Evaluate(ctx, ai.EvaluationInput{
-		TaskTitle:        task.Title,
-		Prompt:           task.Prompt,
-		StarterCode:      task.StarterCode,
-		Language:         submission.Language,
-		SubmissionSource: submission.Source,
-		SubmissionOutput: submission.Output,
-		ExpectedOutput:   task.ExpectedOutput,
-	})
-	if err != nil {
-		return dto.CodingEvaluationResponse{}, err
+	result, cached := ai.EvaluationResult{}, false
+	if !force {
+		result, cached = s.cachedEvaluation(ctx, cacheKey)
+	}
+	if cached {
+		provider = evaluationCacheProvider
+	} else {
+		result, err = s.evaluator.Evaluate(ctx, ai.EvaluationInput{
+			TaskTitle:        task.Title,
+			Prompt:           task.Prompt,
+			StarterCode:      task.StarterCode,
+			Language:         submission.Language,
+			SubmissionSource: submission.Source,
+			SubmissionOutput: submission.Output,
+			ExpectedOutput:   task.ExpectedOutput,
+		})
+		if err != nil {
+			return dto.CodingEvaluationResponse{}, err
+		}
+		s.storeEvaluation(ctx, cacheKey, result)
+	}
+
+	details := result.Details
+	if task.ExpectedOutput != "" {
+		comparison := compareOutput(task, submission.Output)
+		if details == nil {
+			details = make(map[string]interface{})
+		}
+		details["output_comparison"] = map[string]interface{}{
+			"mode":    comparison.Mode,
+			"matched": comparison.Matched,
+			"diff":    comparison.Diff,
+		}
 	}
 
 	evaluation := models.CodingEvaluation{
@@ -232,8 +806,8 @@ func (s *codingSubmissionService) Evaluate(ctx context.Context, id uint, evaluat
 		Score:        result.Score,
 		Verdict:      result.Verdict,
 		Feedback:     result.Feedback,
-		Provider:     s.providerName(),
-		Details:      datatypes.JSONMap(result.Details),
+		Provider:     provider,
+		Details:      datatypes.JSONMap(details),
 		Raw:          datatypes.JSONMap(result.Raw),
 	}
 
@@ -243,23 +817,111 @@ func (s *codingSubmissionService) Evaluate(ctx context.Context, id uint, evaluat
 
 	submission.Status = models.CodingSubmissionStatusEvaluated
 	if err := s.submissions.Update(ctx, &submission); err != nil {
-		s.logger.Error().Err(err).Uint("submission_id", submission.ID).Msg("failed to update submission status")
+		logging.FromContext(ctx, s.logger).Error().Err(err).Uint("submission_id", submission.ID).Msg("failed to update submission status")
 	}
 
 	return dto.NewCodingEvaluationResponse(evaluation), nil
 }
 
+// evaluationCacheKey hashes everything the evaluator sees, along with the
+// provider and its current model settings so switching providers or
+// reloading the model or temperature starts afresh. The task ID and environment
+// keep tasks that share a prompt apart, and editing the task's expected
+// output or environment invalidates its cached results.
+func (s *codingSubmissionService) evaluationCacheKey(task models.CodingTask, submission models.CodingSubmission) string {
+	env, _ := json.Marshal(task.Env)
+	var settings ai.ModelSettings
+	if reporter, ok := s.evaluator.(modelSettingsReporter); ok {
+		settings = reporter.CurrentSettings()
+	}
+	hash := sha256.New()
+	for _, part := range []string{
+		s.providerName(),
+		settings.Model,
+		strconv.FormatFloat(float64(settings.Temperature), 'g', -1, 32),
+		strconv.FormatUint(uint64(task.ID), 10),
+		task.Title,
+		task.Prompt,
+		task.StarterCode,
+		task.ExpectedOutput,
+		string(env),
+		submission.Language,
+		submission.Source,
+		submission.Output,
+	} {
+		fmt.Fprintf(hash, "%d:%s", len(part), part)
+	}
+	return "coding:evaluation:v2:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+func (s *codingSubmissionService) cachedEvaluation(ctx context.Context, key string) (ai.EvaluationResult, bool) {
+	if s.config.EvaluationCache == nil || s.config.EvaluationCacheTTL <= 0 {
+		return ai.EvaluationResult{}, false
+	}
+	payload, err := s.config.EvaluationCache.Get(ctx, key)
+	if err != nil {
+		return ai.EvaluationResult{}, false
+	}
+
+	var result ai.EvaluationResult
+	if err := json.Unmarshal([]byte(payload), &result); err != nil {
+		logging.FromContext(ctx, s.logger).Warn().Err(err).Msg("failed to decode cached evaluation")
+		return ai.EvaluationResult{}, false
+	}
+	return result, true
+}
+
+func (s *codingSubmissionService) storeEvaluation(ctx context.Context, key string, result ai.EvaluationResult) {
+	if s.config.EvaluationCacheTTL <= 0 {
+		return
+	}
+	if err := cache.Write(ctx, s.config.EvaluationCache, "coding_evaluation", key, result, s.config.EvaluationCacheTTL); err != nil {
+		logging.FromContext(ctx, s.logger).Warn().Err(err).Msg("failed to store evaluation cache")
+	}
+}
+
+func (s *codingSubmissionService) LanguageStats(ctx context.Context) ([]dto.CodingLanguageStats, error) {
+	rows, err := s.submissions.LanguageStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]dto.CodingLanguageStats, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, dto.CodingLanguageStats{
+			Language:     row.Language,
+			Total:        row.Total,
+			Succeeded:    row.Succeeded,
+			Failed:       row.Failed,
+			TimedOut:     row.TimedOut,
+			SuccessRate:  percentage(row.Succeeded, row.Total),
+			FailureRate:  percentage(row.Failed, row.Total),
+			TimeoutRate:  percentage(row.TimedOut, row.Total),
+			AvgCPUTimeMs: math.Round(row.AvgCPUTimeMs*100) / 100,
+			AvgMemoryKB:  math.Round(row.AvgMemoryKB*100) / 100,
+		})
+	}
+	return stats, nil
+}
+
+func percentage(count, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(count)/float64(total)*10000) / 100
+}
+
 func (s *codingSubmissionService) canViewSource(viewerID uint, role string, submission models.CodingSubmission) bool {
 	if viewerID != 0 && viewerID == submission.StudentID {
 		return true
 	}
-	role = strings.ToLower(role)
-	return role == "teacher" || role == "admin"
+	return IsStaffRole(role)
 }
 
-func (s *codingSubmissionService) canEvaluate(role string) bool {
-	role = strings.ToLower(strings.TrimSpace(role))
-	return role == "teacher" || role == "admin"
+// modelSettingsReporter is implemented by evaluators whose model settings
+// can be reloaded at runtime.
+type modelSettingsReporter interface {
+	CurrentSettings() ai.ModelSettings
 }
 
 func (s *codingSubmissionService) providerName() string {
@@ -268,12 +930,26 @@ func (s *codingSubmissionService) providerName() string {
 		return "openai"
 	case *ai.AnthropicEvaluator:
 		return "anthropic"
+	case *ai.RuleBasedEvaluator:
+		return "rulebased"
 	default:
 		_ = s
 		return "unknown"
 	}
 }
 
+// noteTruncation appends outputLimitNote to a run's errors when the executor
+// stopped reading its output at the output limit.
+func noteTruncation(errs string, result dockerexec.ExecutionResult) string {
+	if !result.Truncated {
+		return errs
+	}
+	if errs == "" {
+		return outputLimitNote
+	}
+	return errs + "\n" + outputLimitNote
+}
+
 func combineErrors(stderr string, execErr error) string {
 	if execErr == nil {
 		return strings.TrimSpace(stderr)
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,9 +14,13 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
 	"gorm.io/datatypes"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/featureflags"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
 	"github.com/noah-isme/gema-go-api/pkg/ai"
@@ -61,6 +68,10 @@ func (s *stubSubmissionRepo) GetByID(ctx context.Context, id uint) (models.Codin
 	return s.stored, nil
 }
 
+func (s *stubSubmissionRepo) List(ctx context.Context, query repository.CodingSubmissionQuery) ([]models.CodingSubmission, int64, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
 func (s *stubSubmissionRepo) SaveEvaluation(ctx context.Context, evaluation *models.CodingEvaluation) error {
 	if s.err != nil {
 		return s.err
@@ -70,6 +81,10 @@ func (s *stubSubmissionRepo) SaveEvaluation(ctx context.Context, evaluation *mod
 	return nil
 }
 
+func (s *stubSubmissionRepo) LanguageStats(ctx context.Context) ([]repository.CodingLanguageStats, error) {
+	return nil, s.err
+}
+
 type stubTaskRepo struct {
 	task models.CodingTask
 	err  error
@@ -89,6 +104,18 @@ func (s *stubTaskRepo) GetByID(ctx context.Context, id uint) (models.CodingTask,
 	return s.task, nil
 }
 
+func (s *stubTaskRepo) BestScores(ctx context.Context, studentID uint, taskIDs []uint) (map[uint]float64, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *stubTaskRepo) SetEnv(ctx context.Context, id uint, env map[string]string) (models.CodingTask, error) {
+	return models.CodingTask{}, errors.New("not implemented")
+}
+
+func (s *stubTaskRepo) SetActive(ctx context.Context, id uint, active bool) (models.CodingTask, error) {
+	return models.CodingTask{}, errors.New("not implemented")
+}
+
 type stubExecutor struct {
 	result dockerexec.ExecutionResult
 	err    error
@@ -110,6 +137,16 @@ func (s stubEvaluator) Evaluate(ctx context.Context, input ai.EvaluationInput) (
 	return s.result, nil
 }
 
+type countingEvaluator struct {
+	stubEvaluator
+	calls int
+}
+
+func (c *countingEvaluator) Evaluate(ctx context.Context, input ai.EvaluationInput) (ai.EvaluationResult, error) {
+	c.calls++
+	return c.stubEvaluator.Evaluate(ctx, input)
+}
+
 func TestCodingSubmissionServiceRejectsUnsupportedLanguage(t *testing.T) {
 	svc := NewCodingSubmissionService(&stubSubmissionRepo{}, &stubTaskRepo{}, stubExecutor{}, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
 
@@ -120,7 +157,7 @@ func TestCodingSubmissionServiceRejectsUnsupportedLanguage(t *testing.T) {
 
 func TestCodingSubmissionServiceHandlesTimeout(t *testing.T) {
 	repo := &stubSubmissionRepo{}
-	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "FizzBuzz"}}
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "FizzBuzz", Active: true}}
 	exec := stubExecutor{result: dockerexec.ExecutionResult{Stdout: "", Stderr: "", Duration: time.Second, TimedOut: true}, err: fmt.Errorf("timeout")}
 	validate := validator.New(validator.WithRequiredStructEnabled())
 	svc := NewCodingSubmissionService(repo, taskRepo, exec, nil, validate, zerolog.Nop(), CodingSubmissionConfig{ExecutionTimeout: time.Second})
@@ -131,13 +168,65 @@ func TestCodingSubmissionServiceHandlesTimeout(t *testing.T) {
 	require.Equal(t, repo.created.ID, resp.ID)
 }
 
+func TestCodingSubmissionServiceStoresFullLogForLongOutput(t *testing.T) {
+	repo := &stubSubmissionRepo{}
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Spam", Active: true}}
+	exec := stubExecutor{result: dockerexec.ExecutionResult{Stdout: strings.Repeat("é", 40), Stderr: "warn", Duration: time.Second}}
+	storage := &storageStub{}
+	svc := NewCodingSubmissionService(repo, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{InlineOutputBytes: 51, LogStorage: storage})
+	ranAt := time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC)
+	svc.(*codingSubmissionService).clock = clock.NewFixed(ranAt)
+
+	resp, err := svc.Submit(context.Background(), 10, dto.CodingSubmissionRequest{TaskID: 1, Language: "python", Source: "print('é' * 40)"})
+	require.NoError(t, err)
+	require.True(t, resp.HasFullLog)
+	require.Equal(t, strings.Repeat("é", 25)+"\n... [truncated 30 bytes]", repo.created.Output)
+	require.Equal(t, "warn", repo.created.Error)
+	require.Contains(t, repo.created.LogURL, fmt.Sprintf("https://cdn.example.com/coding-log-task1-student10-%d.log", ranAt.UnixNano()))
+	require.Contains(t, storage.uploaded.String(), strings.Repeat("é", 40)+"\n== stderr ==\nwarn")
+
+	_, err = svc.Log(context.Background(), 1, "student")
+	require.ErrorIs(t, err, ErrCodingSubmissionForbidden)
+
+	log, err := svc.Log(context.Background(), 1, "teacher")
+	require.NoError(t, err)
+	require.Equal(t, repo.created.LogURL, log.URL)
+	require.Nil(t, log.ExpiresAt)
+
+	repo.stored.LogURL = ""
+	_, err = svc.Log(context.Background(), 1, "admin")
+	require.ErrorIs(t, err, ErrCodingSubmissionLogNotFound)
+}
+
+func TestCodingSubmissionServiceNotesTruncatedOutput(t *testing.T) {
+	repo := &stubSubmissionRepo{}
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Spam", Active: true}}
+	exec := stubExecutor{result: dockerexec.ExecutionResult{Stdout: "yyyy", ExitCode: 1, Truncated: true}}
+	svc := NewCodingSubmissionService(repo, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	_, err := svc.Submit(context.Background(), 10, dto.CodingSubmissionRequest{TaskID: 1, Language: "python", Source: "while True: print('y')"})
+	require.NoError(t, err)
+	require.Equal(t, models.CodingSubmissionStatusFailed, repo.created.Status)
+	require.Equal(t, "process exited with code 1\n"+outputLimitNote, repo.created.Error)
+}
+
+func TestCodingSubmissionServiceRejectsInactiveTask(t *testing.T) {
+	repo := &stubSubmissionRepo{}
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "FizzBuzz", Active: false}}
+	svc := NewCodingSubmissionService(repo, taskRepo, stubExecutor{}, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	_, err := svc.Submit(context.Background(), 10, dto.CodingSubmissionRequest{TaskID: 1, Language: "python", Source: "print('hi')"})
+	require.ErrorIs(t, err, ErrCodingTaskInactive)
+	require.Nil(t, repo.created)
+}
+
 func TestCodingSubmissionServiceEvaluateStoresResult(t *testing.T) {
 	submissionRepo := &stubSubmissionRepo{stored: models.CodingSubmission{ID: 5, TaskID: 1, StudentID: 2, Language: "python", Source: "print('hi')", Task: models.CodingTask{ID: 1, Title: "Fizz", Prompt: "prompt"}}}
 	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Fizz", Prompt: "prompt"}}
 	evaluator := stubEvaluator{result: ai.EvaluationResult{Score: 0.9, Feedback: "Great", Verdict: "pass", Details: map[string]interface{}{"correctness": 1}}}
 	svc := NewCodingSubmissionService(submissionRepo, taskRepo, stubExecutor{}, evaluator, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
 
-	eval, err := svc.Evaluate(context.Background(), 5, 1, "teacher")
+	eval, err := svc.Evaluate(context.Background(), 5, 1, "teacher", false)
 	require.NoError(t, err)
 	require.NotNil(t, submissionRepo.evaluation)
 	require.InDelta(t, 0.9, submissionRepo.evaluation.Score, 0.001)
@@ -145,12 +234,637 @@ func TestCodingSubmissionServiceEvaluateStoresResult(t *testing.T) {
 	require.Equal(t, "pass", eval.Verdict)
 }
 
+func TestCodingSubmissionServiceEvaluateWithRuleBasedEvaluator(t *testing.T) {
+	task := models.CodingTask{ID: 1, Title: "Count", ExpectedOutput: "1\n2\n3\n"}
+	submissionRepo := &stubSubmissionRepo{stored: models.CodingSubmission{ID: 6, TaskID: 1, StudentID: 2, Language: "python", Output: "1\n2\n3", Task: task}}
+	svc := NewCodingSubmissionService(submissionRepo, &stubTaskRepo{task: task}, stubExecutor{}, ai.NewRuleBasedEvaluator(), validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	eval, err := svc.Evaluate(context.Background(), 6, 1, "teacher", false)
+	require.NoError(t, err)
+	require.Equal(t, "pass", eval.Verdict)
+	require.Equal(t, 1.0, submissionRepo.evaluation.Score)
+	require.Equal(t, "rulebased", submissionRepo.evaluation.Provider)
+	require.Equal(t, ai.RuleMatchTrimmed, submissionRepo.evaluation.Details["match"])
+	require.NotNil(t, submissionRepo.evaluation.Details["output_comparison"])
+}
+
+func TestCodingSubmissionServiceEvaluateUsesCache(t *testing.T) {
+	task := models.CodingTask{ID: 1, Title: "Fizz", Prompt: "prompt"}
+	submission := models.CodingSubmission{ID: 5, TaskID: 1, StudentID: 2, Language: "python", Source: "print('hi')", Output: "hi", Task: task}
+	submissionRepo := &stubSubmissionRepo{stored: submission}
+	evaluator := &countingEvaluator{stubEvaluator: stubEvaluator{result: ai.EvaluationResult{Score: 0.8, Feedback: "Good", Verdict: "pass", Raw: map[string]interface{}{"id": "resp-1"}}}}
+	svc := NewCodingSubmissionService(submissionRepo, &stubTaskRepo{task: task}, stubExecutor{}, evaluator, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{
+		EvaluationCache:    cache.NewMemoryStore(10),
+		EvaluationCacheTTL: time.Hour,
+	})
+	ctx := context.Background()
+
+	_, err := svc.Evaluate(ctx, 5, 1, "teacher", false)
+	require.NoError(t, err)
+	require.Equal(t, 1, evaluator.calls)
+	require.Equal(t, "unknown", submissionRepo.evaluation.Provider)
+
+	// A resubmission of the same source by another student hits the cache.
+	submission.ID, submission.StudentID = 6, 3
+	submissionRepo.stored = submission
+	eval, err := svc.Evaluate(ctx, 6, 1, "teacher", false)
+	require.NoError(t, err)
+	require.Equal(t, 1, evaluator.calls)
+	require.Equal(t, "cache", eval.Provider)
+	require.Equal(t, uint(6), submissionRepo.evaluation.SubmissionID)
+	require.InDelta(t, 0.8, submissionRepo.evaluation.Score, 0.001)
+	require.Equal(t, "resp-1", submissionRepo.evaluation.Raw["id"])
+
+	_, err = svc.Evaluate(ctx, 6, 1, "teacher", true)
+	require.NoError(t, err)
+	require.Equal(t, 2, evaluator.calls)
+	require.Equal(t, "unknown", submissionRepo.evaluation.Provider)
+
+	submission.Output = "hello"
+	submissionRepo.stored = submission
+	_, err = svc.Evaluate(ctx, 6, 1, "teacher", false)
+	require.NoError(t, err)
+	require.Equal(t, 3, evaluator.calls)
+
+	// The same source for another task, or after the task changed, is graded
+	// afresh.
+	for _, changed := range []models.CodingTask{
+		{ID: 2, Title: "Fizz", Prompt: "prompt"},
+		{ID: 1, Title: "Fizz", Prompt: "prompt", ExpectedOutput: "hello"},
+		{ID: 1, Title: "Fizz", Prompt: "prompt", ExpectedOutput: "hello", Env: datatypes.JSONMap{"MODE": "strict"}},
+	} {
+		calls := evaluator.calls
+		submission.Task = changed
+		submissionRepo.stored = submission
+		_, err = svc.Evaluate(ctx, 6, 1, "teacher", false)
+		require.NoError(t, err)
+		require.Equal(t, calls+1, evaluator.calls)
+	}
+}
+
+// reloadableEvaluator reports model settings like the AI evaluators do.
+type reloadableEvaluator struct {
+	*countingEvaluator
+	settings ai.ModelSettings
+}
+
+func (e *reloadableEvaluator) CurrentSettings() ai.ModelSettings {
+	return e.settings
+}
+
+func TestCodingSubmissionServiceEvaluateCacheFollowsModelSettings(t *testing.T) {
+	task := models.CodingTask{ID: 1, Title: "Fizz", Prompt: "prompt"}
+	submissionRepo := &stubSubmissionRepo{stored: models.CodingSubmission{ID: 5, TaskID: 1, StudentID: 2, Language: "python", Source: "print('hi')", Output: "hi", Task: task}}
+	evaluator := &reloadableEvaluator{
+		countingEvaluator: &countingEvaluator{stubEvaluator: stubEvaluator{result: ai.EvaluationResult{Score: 0.8, Verdict: "pass"}}},
+		settings:          ai.ModelSettings{Model: "gpt-4o-mini", Temperature: 0.2},
+	}
+	svc := NewCodingSubmissionService(submissionRepo, &stubTaskRepo{task: task}, stubExecutor{}, evaluator, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{
+		EvaluationCache:    cache.NewMemoryStore(10),
+		EvaluationCacheTTL: time.Hour,
+	})
+	ctx := context.Background()
+
+	for _, settings := range []ai.ModelSettings{
+		{Model: "gpt-4o-mini", Temperature: 0.2},
+		{Model: "gpt-4o", Temperature: 0.2},
+		{Model: "gpt-4o", Temperature: 0.7},
+	} {
+		calls := evaluator.calls
+		evaluator.settings = settings
+		_, err := svc.Evaluate(ctx, 5, 1, "teacher", false)
+		require.NoError(t, err)
+		require.Equal(t, calls+1, evaluator.calls, "reloaded settings %+v are graded afresh", settings)
+	}
+
+	_, err := svc.Evaluate(ctx, 5, 1, "teacher", false)
+	require.NoError(t, err)
+	require.Equal(t, 3, evaluator.calls, "unchanged settings hit the cache")
+}
+
 func TestCodingSubmissionServiceEvaluateRequiresEvaluator(t *testing.T) {
 	submissionRepo := &stubSubmissionRepo{stored: models.CodingSubmission{ID: 5, TaskID: 1, StudentID: 2, Language: "python", Source: "print('hi')", Task: models.CodingTask{ID: 1, Title: "Fizz"}}}
 	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Fizz"}}
 	svc := NewCodingSubmissionService(submissionRepo, taskRepo, stubExecutor{}, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
 
-	_, err := svc.Evaluate(context.Background(), 5, 1, "teacher")
+	_, err := svc.Evaluate(context.Background(), 5, 1, "teacher", false)
 	require.Error(t, err)
 	require.True(t, errors.Is(err, ErrEvaluatorUnavailable))
 }
+
+func TestCompareOutputModes(t *testing.T) {
+	cases := []struct {
+		name      string
+		task      models.CodingTask
+		actual    string
+		matched   bool
+		wantMode  string
+		diffMatch string
+	}{
+		{name: "exact match", task: models.CodingTask{ExpectedOutput: "Fizz\nBuzz\n"}, actual: "Fizz\nBuzz\n", matched: true, wantMode: models.OutputComparisonExact},
+		{name: "exact rejects trailing space", task: models.CodingTask{ExpectedOutput: "Fizz\nBuzz"}, actual: "Fizz \nBuzz", wantMode: models.OutputComparisonExact, diffMatch: `- "Fizz"`},
+		{name: "unknown mode falls back to exact", task: models.CodingTask{ExpectedOutput: "42", Comparison: "fuzzy"}, actual: "42\n", wantMode: models.OutputComparisonExact},
+		{name: "trimmed ignores line endings and trailing blanks", task: models.CodingTask{ExpectedOutput: "Fizz\nBuzz", Comparison: "trimmed"}, actual: "Fizz  \r\nBuzz\r\n\r\n", matched: true, wantMode: models.OutputComparisonTrimmed},
+		{name: "trimmed keeps inner spacing", task: models.CodingTask{ExpectedOutput: "a b", Comparison: "trimmed"}, actual: "a  b", wantMode: models.OutputComparisonTrimmed, diffMatch: "@@ line 1 @@"},
+		{name: "whitespace collapses runs and newlines", task: models.CodingTask{ExpectedOutput: "1 2 3\n4", Comparison: "whitespace"}, actual: "1  2\t3 4\n", matched: true, wantMode: models.OutputComparisonWhitespace},
+		{name: "whitespace still compares tokens", task: models.CodingTask{ExpectedOutput: "1 2 3", Comparison: "whitespace"}, actual: "1 2 4", wantMode: models.OutputComparisonWhitespace, diffMatch: `+ "1 2 4"`},
+		{name: "numeric within default tolerance", task: models.CodingTask{ExpectedOutput: "area: 3.14159", Comparison: "numeric"}, actual: "area: 3.1415900001\n", matched: true, wantMode: models.OutputComparisonNumeric},
+		{name: "numeric within custom tolerance", task: models.CodingTask{ExpectedOutput: "0.333", Comparison: "numeric", Tolerance: 0.001}, actual: "0.3333333", matched: true, wantMode: models.OutputComparisonNumeric},
+		{name: "numeric outside tolerance", task: models.CodingTask{ExpectedOutput: "0.333", Comparison: "numeric", Tolerance: 0.0001}, actual: "0.334", wantMode: models.OutputComparisonNumeric, diffMatch: `- "0.333"`},
+		{name: "numeric requires matching words", task: models.CodingTask{ExpectedOutput: "total 10", Comparison: "numeric"}, actual: "sum 10", wantMode: models.OutputComparisonNumeric},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := compareOutput(tc.task, tc.actual)
+			require.Equal(t, tc.wantMode, result.Mode)
+			require.Equal(t, tc.matched, result.Matched)
+			if tc.matched {
+				require.Empty(t, result.Diff)
+				return
+			}
+			require.NotEmpty(t, result.Diff)
+			if tc.diffMatch != "" {
+				require.Contains(t, result.Diff, tc.diffMatch)
+			}
+		})
+	}
+}
+
+func TestCodingSubmissionServiceReportsOutputComparison(t *testing.T) {
+	repo := &stubSubmissionRepo{}
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Sum", Active: true, ExpectedOutput: "15", Comparison: models.OutputComparisonTrimmed}}
+	exec := stubExecutor{result: dockerexec.ExecutionResult{Stdout: "15\n"}}
+	svc := NewCodingSubmissionService(repo, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	resp, err := svc.Submit(context.Background(), 10, dto.CodingSubmissionRequest{TaskID: 1, Language: "python", Source: "print(15)"})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Comparison)
+	require.Equal(t, models.OutputComparisonTrimmed, resp.Comparison.Mode)
+	require.True(t, resp.Comparison.Matched)
+	require.NotNil(t, repo.created.Matched)
+	require.True(t, *repo.created.Matched)
+}
+
+func TestCodingSubmissionServiceSurfacesRejectedImage(t *testing.T) {
+	repo := &stubSubmissionRepo{}
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "FizzBuzz", Active: true}}
+	exec := stubExecutor{err: fmt.Errorf("%w: python:3.11-alpine", dockerexec.ErrImageNotAllowed)}
+	svc := NewCodingSubmissionService(repo, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	_, err := svc.Submit(context.Background(), 10, dto.CodingSubmissionRequest{TaskID: 1, Language: "python", Source: "print('hi')"})
+	require.ErrorIs(t, err, ErrLanguageRuntimeUnavailable)
+	require.Nil(t, repo.created)
+}
+
+func TestCodingSubmissionServiceReportsBusyExecutor(t *testing.T) {
+	repo := &stubSubmissionRepo{}
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Hello", Active: true}}
+	svc := NewCodingSubmissionService(repo, taskRepo, stubExecutor{err: dockerexec.ErrExecutorBusy}, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	_, err := svc.Submit(context.Background(), 10, dto.CodingSubmissionRequest{TaskID: 1, Language: "python", Source: "print('hi')"})
+	require.ErrorIs(t, err, ErrExecutorBusy)
+	require.Nil(t, repo.created)
+}
+
+type recordingExecutor struct {
+	last   dockerexec.ExecutionRequest
+	result dockerexec.ExecutionResult
+}
+
+func (r *recordingExecutor) Run(ctx context.Context, req dockerexec.ExecutionRequest) (dockerexec.ExecutionResult, error) {
+	r.last = req
+	return r.result, nil
+}
+
+func TestCodingSubmissionServicePassesStdin(t *testing.T) {
+	repo := &stubSubmissionRepo{}
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Sum", Active: true}}
+	exec := &recordingExecutor{result: dockerexec.ExecutionResult{Stdout: "5\n"}}
+	svc := NewCodingSubmissionService(repo, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	resp, err := svc.Submit(context.Background(), 10, dto.CodingSubmissionRequest{
+		TaskID:   1,
+		Language: "python",
+		Source:   "a, b = map(int, input().split())\nprint(a + b)",
+		Stdin:    "2 3\n",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []byte("2 3\n"), exec.last.Stdin)
+	require.Equal(t, "2 3\n", repo.created.Stdin)
+	require.Equal(t, "2 3\n", resp.Stdin)
+}
+
+func TestCodingSubmissionServiceAppliesSandboxLimits(t *testing.T) {
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Loop", Active: true}}
+	request := dto.CodingSubmissionRequest{TaskID: 1, Language: "python", Source: "print('hi')"}
+
+	exec := &recordingExecutor{}
+	svc := NewCodingSubmissionService(&stubSubmissionRepo{}, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+	_, err := svc.Submit(context.Background(), 10, request)
+	require.NoError(t, err)
+	require.Equal(t, int64(defaultCodingPidsLimit), exec.last.PidsLimit)
+	require.Equal(t, int64(defaultCodingDiskQuotaMB), exec.last.DiskQuotaMB)
+	require.Zero(t, exec.last.CPUQuota)
+	require.True(t, exec.last.ReadOnlyFS, "only the /tmp scratch mount may be writable")
+	require.Contains(t, exec.last.Env, "HOME=/tmp")
+
+	exec = &recordingExecutor{}
+	svc = NewCodingSubmissionService(&stubSubmissionRepo{}, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{
+		PidsLimit:   16,
+		DiskQuotaMB: 8,
+		CPUQuota:    50000,
+		CPUPeriod:   100000,
+	})
+	_, err = svc.Submit(context.Background(), 10, request)
+	require.NoError(t, err)
+	require.Equal(t, int64(16), exec.last.PidsLimit)
+	require.Equal(t, int64(8), exec.last.DiskQuotaMB)
+	require.Equal(t, int64(50000), exec.last.CPUQuota)
+	require.Equal(t, int64(100000), exec.last.CPUPeriod)
+}
+
+func TestCodingSubmissionServiceInjectsTaskEnv(t *testing.T) {
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Dice", Active: true, Env: datatypes.JSONMap{
+		"SEED":       "42",
+		"ROUNDS":     3,
+		"PATH":       "/tmp/evil",
+		"LD_PRELOAD": "/tmp/evil.so",
+	}}}
+
+	exec := &recordingExecutor{}
+	svc := NewCodingSubmissionService(&stubSubmissionRepo{}, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+	resp, err := svc.Submit(context.Background(), 10, dto.CodingSubmissionRequest{TaskID: 1, Language: "python", Source: "import os"})
+	require.NoError(t, err)
+	require.Equal(t, append(append([]string(nil), sandboxEnv...), "ROUNDS=3", "SEED=42"), exec.last.Env)
+	require.Equal(t, []string{"ROUNDS", "SEED"}, resp.EnvVars)
+}
+
+type scriptedExecutor struct {
+	outputs map[string]dockerexec.ExecutionResult
+	runs    int
+}
+
+func (s *scriptedExecutor) Run(ctx context.Context, req dockerexec.ExecutionRequest) (dockerexec.ExecutionResult, error) {
+	s.runs++
+	return s.outputs[string(req.Stdin)], nil
+}
+
+func TestCodingSubmissionServiceGradesTestCases(t *testing.T) {
+	repo := &stubSubmissionRepo{}
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Sum", Active: true, TestCases: []models.CodingTaskTestCase{
+		{ID: 11, Input: "1 2\n", ExpectedOutput: "3", Weight: 1},
+		{ID: 12, Input: "5 5\n", ExpectedOutput: "10", Weight: 3, Hidden: true},
+		{ID: 13, Input: "2 2\n", ExpectedOutput: "4\n"},
+	}}}
+	exec := &scriptedExecutor{outputs: map[string]dockerexec.ExecutionResult{
+		"1 2\n": {Stdout: "3\n"},
+		"5 5\n": {Stdout: "55\n"},
+		"2 2\n": {Stdout: "4  \r\n"},
+	}}
+	svc := NewCodingSubmissionService(repo, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	resp, err := svc.Submit(context.Background(), 10, dto.CodingSubmissionRequest{TaskID: 1, Language: "python", Source: "print(sum(map(int, input().split())))"})
+	require.NoError(t, err)
+	require.Equal(t, 3, exec.runs)
+	require.Equal(t, models.CodingSubmissionStatusCompleted, resp.Status)
+	require.NotNil(t, resp.Score)
+	require.InDelta(t, 40.0, *resp.Score, 0.001)
+	require.NotNil(t, repo.created.Score)
+	require.Len(t, repo.created.TestResultList(), 3)
+
+	require.Len(t, resp.TestResults, 3)
+	require.True(t, resp.TestResults[0].Passed)
+	require.Equal(t, "3\n", resp.TestResults[0].ActualOutput)
+	require.False(t, resp.TestResults[1].Passed)
+	require.True(t, resp.TestResults[1].Hidden)
+	require.Empty(t, resp.TestResults[1].ActualOutput)
+	require.True(t, resp.TestResults[2].Passed)
+	require.Equal(t, "3\n", resp.Output)
+
+	staffView := dto.NewCodingSubmissionResponse(*repo.created, true, true)
+	require.Equal(t, "55\n", staffView.TestResults[1].ActualOutput)
+}
+
+type streamingExecutor struct {
+	chunks []dockerexec.ExecutionChunk
+	result dockerexec.ExecutionResult
+	runs   int
+}
+
+func (s *streamingExecutor) Run(ctx context.Context, req dockerexec.ExecutionRequest) (dockerexec.ExecutionResult, error) {
+	s.runs++
+	return s.result, nil
+}
+
+func (s *streamingExecutor) RunStream(ctx context.Context, req dockerexec.ExecutionRequest, onChunk func(dockerexec.ExecutionChunk)) (dockerexec.ExecutionResult, error) {
+	for _, chunk := range s.chunks {
+		onChunk(chunk)
+	}
+	return s.result, nil
+}
+
+func TestCodingSubmissionServiceStreamsOutput(t *testing.T) {
+	repo := &stubSubmissionRepo{}
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Count", Active: true, ExpectedOutput: "1\n2\n"}}
+	exec := &streamingExecutor{
+		chunks: []dockerexec.ExecutionChunk{
+			{Stream: dockerexec.StreamStdout, Data: []byte("1\n")},
+			{Stream: dockerexec.StreamStderr, Data: []byte("tick\n")},
+			{Stream: dockerexec.StreamStdout, Data: []byte("2\n")},
+		},
+		result: dockerexec.ExecutionResult{Stdout: "1\n2\n", Stderr: "tick\n", Duration: 1500 * time.Millisecond, MemoryUsageBytes: 4 << 20, CPUUsageNanosec: uint64(30 * time.Millisecond)},
+	}
+	svc := NewCodingSubmissionService(repo, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	var frames []dto.CodingStreamMessage
+	final, err := svc.Stream(context.Background(), 10, dto.CodingSubmissionRequest{TaskID: 1, Language: "python", Source: "print(1)\nprint(2)"}, func(message dto.CodingStreamMessage) {
+		frames = append(frames, message)
+	})
+	require.NoError(t, err)
+	require.Zero(t, exec.runs, "streamed run should not be repeated")
+
+	require.Len(t, frames, 3)
+	require.Equal(t, dto.CodingStreamMessage{Type: dto.CodingStreamOutput, Stream: "stdout", Data: "1\n"}, frames[0])
+	require.Equal(t, "stderr", frames[1].Stream)
+
+	require.Equal(t, dto.CodingStreamResult, final.Type)
+	require.NotNil(t, final.ExitCode)
+	require.Zero(t, *final.ExitCode)
+	require.Equal(t, int64(1500), final.DurationMs)
+	require.Equal(t, int64(30), final.CPUTimeMs)
+	require.Equal(t, int64(4096), final.MemoryKB)
+	require.NotNil(t, final.Submission)
+	require.Equal(t, models.CodingSubmissionStatusCompleted, final.Submission.Status)
+	require.NotNil(t, final.Submission.Comparison)
+	require.True(t, final.Submission.Comparison.Matched)
+	require.Equal(t, "1\n2\n", repo.created.Output)
+}
+
+func TestCodingSubmissionServiceStreamRequiresStreamingExecutor(t *testing.T) {
+	svc := NewCodingSubmissionService(&stubSubmissionRepo{}, &stubTaskRepo{}, stubExecutor{}, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	_, err := svc.Stream(context.Background(), 10, dto.CodingSubmissionRequest{TaskID: 1, Language: "python", Source: "print(1)"}, func(dto.CodingStreamMessage) {})
+	require.ErrorIs(t, err, ErrStreamingUnavailable)
+}
+
+func TestCodingSubmissionServiceLanguageStats(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:coding_language_stats_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.CodingTask{}, &models.CodingTaskTestCase{}, &models.CodingSubmission{}, &models.CodingEvaluation{}))
+
+	task := models.CodingTask{Title: "Echo", Prompt: "Echo input", Language: "python", Difficulty: "easy", Active: true}
+	require.NoError(t, db.Create(&task).Error)
+
+	submissions := []models.CodingSubmission{
+		{Language: "python", Status: models.CodingSubmissionStatusCompleted, CPUTimeMs: 100, MemoryKB: 1000},
+		{Language: "python", Status: models.CodingSubmissionStatusEvaluated, CPUTimeMs: 200, MemoryKB: 2000},
+		{Language: "python", Status: models.CodingSubmissionStatusFailed, Error: "SyntaxError", CPUTimeMs: 50, MemoryKB: 500},
+		{Language: "python", Status: models.CodingSubmissionStatusTimeout, Error: "execution timed out", CPUTimeMs: 650, MemoryKB: 4500},
+		{Language: "go", Status: models.CodingSubmissionStatusEvaluated, Error: "process exited with code 2", CPUTimeMs: 300, MemoryKB: 3000},
+		{Language: "go", Status: models.CodingSubmissionStatusCompleted, CPUTimeMs: 500, MemoryKB: 5000},
+		{Language: "go", Status: models.CodingSubmissionStatusCompileError, CompileError: "undefined: fmt", CPUTimeMs: 100, MemoryKB: 1000},
+	}
+	for i := range submissions {
+		submissions[i].TaskID = task.ID
+		submissions[i].StudentID = 7
+		require.NoError(t, db.Create(&submissions[i]).Error)
+	}
+
+	svc := NewCodingSubmissionService(repository.NewCodingSubmissionRepository(db), repository.NewCodingTaskRepository(db), stubExecutor{}, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	stats, err := svc.LanguageStats(context.Background())
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+
+	goStats := stats[0]
+	require.Equal(t, "go", goStats.Language)
+	require.Equal(t, int64(3), goStats.Total)
+	require.Equal(t, int64(1), goStats.Succeeded)
+	require.Equal(t, int64(2), goStats.Failed)
+	require.Equal(t, 33.33, goStats.SuccessRate)
+	require.Equal(t, 66.67, goStats.FailureRate)
+	require.Equal(t, 0.0, goStats.TimeoutRate)
+	require.InDelta(t, 300.0, goStats.AvgCPUTimeMs, 0.001)
+	require.InDelta(t, 3000.0, goStats.AvgMemoryKB, 0.001)
+
+	python := stats[1]
+	require.Equal(t, "python", python.Language)
+	require.Equal(t, int64(4), python.Total)
+	require.Equal(t, int64(2), python.Succeeded)
+	require.Equal(t, int64(1), python.Failed)
+	require.Equal(t, int64(1), python.TimedOut)
+	require.Equal(t, 50.0, python.SuccessRate)
+	require.Equal(t, 25.0, python.FailureRate)
+	require.Equal(t, 25.0, python.TimeoutRate)
+	require.InDelta(t, 250.0, python.AvgCPUTimeMs, 0.001)
+	require.InDelta(t, 2000.0, python.AvgMemoryKB, 0.001)
+}
+
+func TestCodingSubmissionServiceListScopesStudents(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:coding_submission_list_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.CodingTask{}, &models.CodingTaskTestCase{}, &models.CodingSubmission{}, &models.CodingEvaluation{}))
+
+	first := models.CodingTask{Title: "Echo", Prompt: "Echo input", Language: "python", Difficulty: "easy", Active: true}
+	second := models.CodingTask{Title: "Sum", Prompt: "Add numbers", Language: "go", Difficulty: "easy", Active: true}
+	require.NoError(t, db.Create(&first).Error)
+	require.NoError(t, db.Create(&second).Error)
+
+	submissions := []models.CodingSubmission{
+		{TaskID: first.ID, StudentID: 7, Language: "python", Source: "print(1)", Status: models.CodingSubmissionStatusCompleted},
+		{TaskID: first.ID, StudentID: 8, Language: "python", Source: "print(2)", Status: models.CodingSubmissionStatusFailed},
+		{TaskID: second.ID, StudentID: 7, Language: "go", Source: "package main", Status: models.CodingSubmissionStatusFailed},
+	}
+	for i := range submissions {
+		require.NoError(t, db.Create(&submissions[i]).Error)
+	}
+
+	svc := NewCodingSubmissionService(repository.NewCodingSubmissionRepository(db), repository.NewCodingTaskRepository(db), stubExecutor{}, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+	ctx := context.Background()
+
+	own, err := svc.List(ctx, dto.CodingSubmissionFilter{StudentID: 8}, 7, "student")
+	require.NoError(t, err)
+	require.Equal(t, 2, own.Pagination.TotalItems)
+	for _, item := range own.Items {
+		require.Equal(t, uint(7), item.StudentID)
+		require.NotEmpty(t, item.Source)
+	}
+
+	failed, err := svc.List(ctx, dto.CodingSubmissionFilter{Status: models.CodingSubmissionStatusFailed}, 7, "student")
+	require.NoError(t, err)
+	require.Len(t, failed.Items, 1)
+	require.Equal(t, "go", failed.Items[0].Language)
+
+	byTask, err := svc.List(ctx, dto.CodingSubmissionFilter{TaskID: first.ID, PageSize: 1}, 1, "teacher")
+	require.NoError(t, err)
+	require.Equal(t, 2, byTask.Pagination.TotalItems)
+	require.Len(t, byTask.Items, 1)
+	require.Equal(t, uint(8), byTask.Items[0].StudentID)
+	require.Equal(t, "print(2)", byTask.Items[0].Source)
+
+	_, err = svc.List(ctx, dto.CodingSubmissionFilter{}, 0, "student")
+	require.ErrorIs(t, err, ErrCodingSubmissionForbidden)
+}
+
+type compileExecutor struct {
+	build dockerexec.ExecutionResult
+	run   dockerexec.ExecutionResult
+	cmds  [][]string
+}
+
+func (c *compileExecutor) Run(ctx context.Context, req dockerexec.ExecutionRequest) (dockerexec.ExecutionResult, error) {
+	c.cmds = append(c.cmds, req.Cmd)
+	if req.WritableWorkspace {
+		return c.build, nil
+	}
+	return c.run, nil
+}
+
+func TestCodingSubmissionServiceReportsCompileErrors(t *testing.T) {
+	repo := &stubSubmissionRepo{}
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Hello", Active: true}}
+	exec := &compileExecutor{build: dockerexec.ExecutionResult{ExitCode: 1, Stderr: "./main.go:3:2: undefined: fmt\n"}}
+	svc := NewCodingSubmissionService(repo, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	resp, err := svc.Submit(context.Background(), 10, dto.CodingSubmissionRequest{TaskID: 1, Language: "go", Source: "package main\nfunc main() { fmt.Println(1) }"})
+	require.NoError(t, err)
+	require.Len(t, exec.cmds, 1, "program must not run after a failed build")
+	require.Equal(t, models.CodingSubmissionStatusCompileError, resp.Status)
+	require.Equal(t, "./main.go:3:2: undefined: fmt", resp.CompileError)
+	require.Empty(t, resp.Error)
+	require.Equal(t, models.CodingSubmissionStatusCompileError, repo.created.Status)
+}
+
+func TestCodingSubmissionServiceRunsCompiledBinary(t *testing.T) {
+	repo := &stubSubmissionRepo{}
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Hello", Active: true}}
+	exec := &compileExecutor{
+		build: dockerexec.ExecutionResult{},
+		run:   dockerexec.ExecutionResult{ExitCode: 3, Stderr: "panic: boom\n"},
+	}
+	svc := NewCodingSubmissionService(repo, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	resp, err := svc.Submit(context.Background(), 10, dto.CodingSubmissionRequest{TaskID: 1, Language: "go", Source: "package main\nfunc main() { panic(\"boom\") }"})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"sh", "-c", "go build -o /tmp/main main.go && cp /tmp/main main"}, {"./main"}}, exec.cmds)
+	require.Equal(t, models.CodingSubmissionStatusFailed, resp.Status)
+	require.Equal(t, "panic: boom", resp.Error)
+	require.Empty(t, resp.CompileError)
+}
+
+// workspaceExecutor checks the source file is where the build expects it and
+// echoes the quoted string the trivial program prints.
+type workspaceExecutor struct {
+	t        *testing.T
+	fileName string
+	image    string
+	cmds     [][]string
+}
+
+func (w *workspaceExecutor) Run(ctx context.Context, req dockerexec.ExecutionRequest) (dockerexec.ExecutionResult, error) {
+	w.cmds = append(w.cmds, req.Cmd)
+	require.Equal(w.t, w.image, req.Image)
+	require.Equal(w.t, "/workspace", req.WorkingDir)
+
+	source, err := os.ReadFile(filepath.Join(req.Workspace, w.fileName))
+	require.NoError(w.t, err)
+	// Only the build step may write to the workspace.
+	require.Equal(w.t, len(w.cmds) == 1, req.WritableWorkspace)
+	if len(w.cmds) == 1 {
+		return dockerexec.ExecutionResult{}, nil
+	}
+
+	text := string(source)
+	start := strings.Index(text, `"`)
+	end := strings.LastIndex(text, `"`)
+	return dockerexec.ExecutionResult{Stdout: text[start+1:end] + "\n"}, nil
+}
+
+func TestCodingSubmissionServiceCompiledLanguages(t *testing.T) {
+	cases := []struct {
+		language string
+		fileName string
+		image    string
+		source   string
+		cmds     [][]string
+	}{
+		{
+			language: "java",
+			fileName: "Main.java",
+			image:    "eclipse-temurin:21-jdk-alpine",
+			source:   "public class Main { public static void main(String[] args) { System.out.println(\"hello java\"); } }",
+			cmds:     [][]string{{"sh", "-c", "javac -d /tmp/classes Main.java && cp -R /tmp/classes/. ."}, {"java", "-cp", ".", "Main"}},
+		},
+		{
+			language: "cpp",
+			fileName: "main.cpp",
+			image:    "gcc:13",
+			source:   "#include <iostream>\nint main() { std::cout << \"hello cpp\" << std::endl; }",
+			cmds:     [][]string{{"sh", "-c", "g++ -std=c++17 -O2 -o /tmp/main main.cpp && cp /tmp/main main"}, {"./main"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.language, func(t *testing.T) {
+			repo := &stubSubmissionRepo{}
+			taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Hello", Active: true, ExpectedOutput: "hello " + tc.language + "\n"}}
+			exec := &workspaceExecutor{t: t, fileName: tc.fileName, image: tc.image}
+			svc := NewCodingSubmissionService(repo, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+			resp, err := svc.Submit(context.Background(), 10, dto.CodingSubmissionRequest{TaskID: 1, Language: strings.ToUpper(tc.language), Source: tc.source})
+			require.NoError(t, err)
+			require.Equal(t, tc.cmds, exec.cmds)
+			require.Equal(t, tc.language, resp.Language)
+			require.Equal(t, models.CodingSubmissionStatusCompleted, resp.Status)
+			require.Equal(t, "hello "+tc.language+"\n", resp.Output)
+			require.NotNil(t, resp.Comparison)
+			require.True(t, resp.Comparison.Matched)
+		})
+	}
+}
+
+// asyncSubmissionRepo hands background updates to the test.
+type asyncSubmissionRepo struct {
+	stubSubmissionRepo
+	updates chan models.CodingSubmission
+}
+
+func (a *asyncSubmissionRepo) Update(ctx context.Context, submission *models.CodingSubmission) error {
+	a.updates <- *submission
+	return nil
+}
+
+// gatedExecutor holds every run until release is closed.
+type gatedExecutor struct {
+	release chan struct{}
+	result  dockerexec.ExecutionResult
+}
+
+func (g *gatedExecutor) Run(ctx context.Context, req dockerexec.ExecutionRequest) (dockerexec.ExecutionResult, error) {
+	<-g.release
+	return g.result, nil
+}
+
+func TestCodingSubmissionServiceRunsFlaggedSubmissionsInBackground(t *testing.T) {
+	repo := &asyncSubmissionRepo{updates: make(chan models.CodingSubmission, 1)}
+	taskRepo := &stubTaskRepo{task: models.CodingTask{ID: 1, Title: "Hello", Active: true}}
+	exec := &gatedExecutor{release: make(chan struct{}), result: dockerexec.ExecutionResult{Stdout: "hello\n"}}
+	svc := NewCodingSubmissionService(repo, taskRepo, exec, nil, validator.New(validator.WithRequiredStructEnabled()), zerolog.Nop(), CodingSubmissionConfig{})
+
+	ctx := featureflags.WithFlags(context.Background(), featureflags.Set{featureflags.AsyncCodingExecution: {}})
+	resp, err := svc.Submit(ctx, 10, dto.CodingSubmissionRequest{TaskID: 1, Language: "python", Source: "print('hello')"})
+	require.NoError(t, err)
+	require.Equal(t, models.CodingSubmissionStatusPending, resp.Status)
+	require.Equal(t, models.CodingSubmissionStatusPending, repo.created.Status)
+
+	close(exec.release)
+	select {
+	case updated := <-repo.updates:
+		require.Equal(t, resp.ID, updated.ID)
+		require.Equal(t, models.CodingSubmissionStatusCompleted, updated.Status)
+		require.Equal(t, "hello\n", updated.Output)
+	case <-time.After(5 * time.Second):
+		t.Fatal("background run was not stored")
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Limits on the environment a coding task may inject into its runs.
+const (
+	maxTaskEnvVars       = 16
+	maxTaskEnvNameLen    = 64
+	maxTaskEnvValueBytes = 1024
+	maxTaskEnvBytes      = 8 << 10
+)
+
+// ErrInvalidCodingTaskEnv indicates a task environment variable was rejected.
+var ErrInvalidCodingTaskEnv = errors.New("invalid task environment")
+
+// taskEnvNamePattern is the allowlist for task variable names: upper-case
+// letters, digits and underscores, starting with a letter.
+var taskEnvNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// reservedTaskEnvNames change how the runtime finds programs, libraries or
+// startup code, or who it runs as, so tasks may not set them.
+var reservedTaskEnvNames = map[string]struct{}{
+	"PATH": {}, "HOME": {}, "USER": {}, "SHELL": {}, "HOSTNAME": {}, "PWD": {}, "TMPDIR": {},
+	"IFS": {}, "ENV": {}, "BASH_ENV": {}, "PS4": {},
+	"CLASSPATH": {}, "JAVA_HOME": {}, "JAVA_TOOL_OPTIONS": {}, "JDK_JAVA_OPTIONS": {}, "_JAVA_OPTIONS": {},
+	"GOPATH": {}, "GOROOT": {}, "GOFLAGS": {}, "GOCACHE": {}, "GOTOOLCHAIN": {}, "GODEBUG": {}, "GOMAXPROCS": {},
+}
+
+// reservedTaskEnvPrefixes cover the dynamic loader and tool families whose
+// variables are reserved as a group.
+var reservedTaskEnvPrefixes = []string{"LD_", "DYLD_", "DOCKER_", "GEMA_", "PYTHON", "NODE_", "NPM_", "CGO_"}
+
+// validateTaskEnv checks a task environment against the name allowlist and
+// the count and size limits.
+func validateTaskEnv(env map[string]string) error {
+	if len(env) > maxTaskEnvVars {
+		return fmt.Errorf("%w: at most %d variables are allowed", ErrInvalidCodingTaskEnv, maxTaskEnvVars)
+	}
+
+	total := 0
+	for _, name := range sortedEnvNames(env) {
+		value := env[name]
+		if err := validateTaskEnvVar(name, value); err != nil {
+			return err
+		}
+		total += len(name) + len(value)
+	}
+	if total > maxTaskEnvBytes {
+		return fmt.Errorf("%w: variables exceed %d bytes in total", ErrInvalidCodingTaskEnv, maxTaskEnvBytes)
+	}
+	return nil
+}
+
+func validateTaskEnvVar(name, value string) error {
+	if len(name) > maxTaskEnvNameLen || !taskEnvNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q is not a valid name; use upper-case letters, digits and underscores", ErrInvalidCodingTaskEnv, name)
+	}
+	if _, reserved := reservedTaskEnvNames[name]; reserved {
+		return fmt.Errorf("%w: %s is reserved", ErrInvalidCodingTaskEnv, name)
+	}
+	for _, prefix := range reservedTaskEnvPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return fmt.Errorf("%w: %s is reserved", ErrInvalidCodingTaskEnv, name)
+		}
+	}
+	if len(value) > maxTaskEnvValueBytes {
+		return fmt.Errorf("%w: %s exceeds %d bytes", ErrInvalidCodingTaskEnv, name, maxTaskEnvValueBytes)
+	}
+	if strings.ContainsRune(value, 0) {
+		return fmt.Errorf("%w: %s contains a NUL byte", ErrInvalidCodingTaskEnv, name)
+	}
+	return nil
+}
+
+// executionEnv converts a task environment into KEY=value pairs for the
+// executor, skipping variables that fail validation and stopping at the count
+// and size limits. Tasks saved through SetEnv always pass in full; the checks
+// guard against rows edited directly in the database. It also returns the
+// names of the variables passed.
+func executionEnv(env map[string]string) ([]string, []string) {
+	if len(env) == 0 {
+		return nil, nil
+	}
+
+	pairs := make([]string, 0, len(env))
+	names := make([]string, 0, len(env))
+	total := 0
+	for _, name := range sortedEnvNames(env) {
+		value := env[name]
+		if validateTaskEnvVar(name, value) != nil {
+			continue
+		}
+		if len(pairs) == maxTaskEnvVars || total+len(name)+len(value) > maxTaskEnvBytes {
+			break
+		}
+		total += len(name) + len(value)
+		pairs = append(pairs, name+"="+value)
+		names = append(names, name)
+	}
+	return pairs, names
+}
+
+func sortedEnvNames(env map[string]string) []string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -16,10 +16,18 @@ import (
 // ErrCodingTaskNotFound indicates the requested coding task does not exist.
 var ErrCodingTaskNotFound = errors.New("coding task not found")
 
+// ErrInvalidCodingTaskSort indicates an unsupported task list sort order.
+var ErrInvalidCodingTaskSort = errors.New("sort must be newest or popular")
+
+// ErrCodingTaskInactive indicates the coding task no longer accepts submissions.
+var ErrCodingTaskInactive = errors.New("coding task is inactive")
+
 // CodingTaskService exposes use cases related to coding tasks.
 type CodingTaskService interface {
 	List(ctx context.Context, filter dto.CodingTaskFilter) (dto.CodingTaskListResponse, error)
 	Get(ctx context.Context, id uint) (dto.CodingTaskDetailResponse, error)
+	SetActive(ctx context.Context, id uint, active bool) (dto.CodingTaskDetailResponse, error)
+	SetEnv(ctx context.Context, id uint, env map[string]string) (dto.CodingTaskDetailResponse, error)
 }
 
 type codingTaskService struct {
@@ -48,12 +56,23 @@ func (s *codingTaskService) List(ctx context.Context, filter dto.CodingTaskFilte
 		pageSize = 100
 	}
 
+	sort := strings.ToLower(strings.TrimSpace(filter.Sort))
+	switch sort {
+	case "":
+		sort = repository.CodingTaskSortNewest
+	case repository.CodingTaskSortNewest, repository.CodingTaskSortPopular:
+	default:
+		return dto.CodingTaskListResponse{}, ErrInvalidCodingTaskSort
+	}
+
 	tags := normaliseTags(filter.Tags)
 	query := repository.CodingTaskQuery{
 		Language:   strings.ToLower(strings.TrimSpace(filter.Language)),
 		Difficulty: strings.ToLower(strings.TrimSpace(filter.Difficulty)),
 		Tags:       tags,
 		Search:     strings.TrimSpace(filter.Search),
+		ActiveOnly: !filter.IncludeInactive,
+		Sort:       sort,
 		Offset:     (page - 1) * pageSize,
 		Limit:      pageSize,
 	}
@@ -74,7 +93,32 @@ func (s *codingTaskService) List(ctx context.Context, filter dto.CodingTaskFilte
 		TotalItems: int(total),
 	}
 
-	return dto.NewCodingTaskListResponse(sanitised, pagination), nil
+	response := dto.NewCodingTaskListResponse(sanitised, pagination)
+	response.Filters = dto.CodingTaskAppliedFilters{
+		Language:   query.Language,
+		Difficulty: query.Difficulty,
+		Tags:       query.Tags,
+		Search:     query.Search,
+		Sort:       query.Sort,
+	}
+
+	if filter.StudentID != 0 && len(tasks) > 0 {
+		taskIDs := make([]uint, 0, len(tasks))
+		for _, task := range tasks {
+			taskIDs = append(taskIDs, task.ID)
+		}
+		scores, err := s.repo.BestScores(ctx, filter.StudentID, taskIDs)
+		if err != nil {
+			return dto.CodingTaskListResponse{}, err
+		}
+		for i := range response.Items {
+			if score, ok := scores[response.Items[i].ID]; ok {
+				response.Items[i].BestScore = &score
+			}
+		}
+	}
+
+	return response, nil
 }
 
 func (s *codingTaskService) Get(ctx context.Context, id uint) (dto.CodingTaskDetailResponse, error) {
@@ -89,6 +133,38 @@ func (s *codingTaskService) Get(ctx context.Context, id uint) (dto.CodingTaskDet
 	return dto.NewCodingTaskDetail(sanitiseTask(task)), nil
 }
 
+func (s *codingTaskService) SetActive(ctx context.Context, id uint, active bool) (dto.CodingTaskDetailResponse, error) {
+	task, err := s.repo.SetActive(ctx, id, active)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.CodingTaskDetailResponse{}, ErrCodingTaskNotFound
+		}
+		return dto.CodingTaskDetailResponse{}, err
+	}
+
+	s.logger.Info().Uint("task_id", id).Bool("active", active).Msg("coding task availability updated")
+	return dto.NewCodingTaskDetail(sanitiseTask(task)), nil
+}
+
+// SetEnv replaces the task's environment variables after checking them
+// against the name allowlist and size limits.
+func (s *codingTaskService) SetEnv(ctx context.Context, id uint, env map[string]string) (dto.CodingTaskDetailResponse, error) {
+	if err := validateTaskEnv(env); err != nil {
+		return dto.CodingTaskDetailResponse{}, err
+	}
+
+	task, err := s.repo.SetEnv(ctx, id, env)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.CodingTaskDetailResponse{}, ErrCodingTaskNotFound
+		}
+		return dto.CodingTaskDetailResponse{}, err
+	}
+
+	s.logger.Info().Uint("task_id", id).Strs("env_vars", task.EnvNames()).Msg("coding task environment updated")
+	return dto.NewCodingTaskDetail(sanitiseTask(task)), nil
+}
+
 func normaliseTags(tags []string) []string {
 	if len(tags) == 0 {
 		return nil
@@ -3,10 +3,14 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
 	"github.com/noah-isme/gema-go-api/internal/dto"
@@ -40,6 +44,24 @@ func (s *stubCodingTaskRepo) GetByID(ctx context.Context, id uint) (models.Codin
 	return models.CodingTask{}, gorm.ErrRecordNotFound
 }
 
+func (s *stubCodingTaskRepo) BestScores(ctx context.Context, studentID uint, taskIDs []uint) (map[uint]float64, error) {
+	return map[uint]float64{}, s.err
+}
+
+func (s *stubCodingTaskRepo) SetEnv(ctx context.Context, id uint, env map[string]string) (models.CodingTask, error) {
+	return models.CodingTask{}, errors.New("not implemented")
+}
+
+func (s *stubCodingTaskRepo) SetActive(ctx context.Context, id uint, active bool) (models.CodingTask, error) {
+	for i := range s.tasks {
+		if s.tasks[i].ID == id {
+			s.tasks[i].Active = active
+			return s.tasks[i], nil
+		}
+	}
+	return models.CodingTask{}, gorm.ErrRecordNotFound
+}
+
 func TestCodingTaskServiceListAppliesDefaults(t *testing.T) {
 	repo := &stubCodingTaskRepo{tasks: []models.CodingTask{{ID: 1, Title: "FizzBuzz", Prompt: "  prompt  "}}}
 	svc := NewCodingTaskService(repo, zerolog.Nop())
@@ -60,3 +82,115 @@ func TestCodingTaskServiceGetNotFound(t *testing.T) {
 	require.Error(t, err)
 	require.True(t, errors.Is(err, ErrCodingTaskNotFound))
 }
+
+func TestCodingTaskServiceListHidesInactiveFromStudents(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:coding_tasks_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.CodingTask{}, &models.CodingTaskTestCase{}))
+
+	repo := repository.NewCodingTaskRepository(db)
+	svc := NewCodingTaskService(repo, zerolog.Nop())
+
+	active := models.CodingTask{Title: "FizzBuzz", Prompt: "p", Language: "python", Difficulty: "easy"}
+	retired := models.CodingTask{Title: "Legacy", Prompt: "p", Language: "python", Difficulty: "easy"}
+	require.NoError(t, db.Create(&active).Error)
+	require.NoError(t, db.Create(&retired).Error)
+
+	updated, err := svc.SetActive(context.Background(), retired.ID, false)
+	require.NoError(t, err)
+	require.False(t, updated.Active)
+
+	studentView, err := svc.List(context.Background(), dto.CodingTaskFilter{})
+	require.NoError(t, err)
+	require.Len(t, studentView.Items, 1)
+	require.Equal(t, active.ID, studentView.Items[0].ID)
+	require.Equal(t, 1, studentView.Pagination.TotalItems)
+
+	staffView, err := svc.List(context.Background(), dto.CodingTaskFilter{IncludeInactive: true})
+	require.NoError(t, err)
+	require.Len(t, staffView.Items, 2)
+
+	detail, err := svc.Get(context.Background(), retired.ID)
+	require.NoError(t, err)
+	require.False(t, detail.Active)
+
+	_, err = svc.SetActive(context.Background(), 999, true)
+	require.ErrorIs(t, err, ErrCodingTaskNotFound)
+}
+
+func TestCodingTaskServiceListSortsByPopularityWithBestScores(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:coding_tasks_popular_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.CodingTask{}, &models.CodingTaskTestCase{}, &models.CodingSubmission{}, &models.CodingEvaluation{}))
+
+	quiet := models.CodingTask{Title: "Quiet", Prompt: "p", Language: "python", Difficulty: "easy", Active: true}
+	busy := models.CodingTask{Title: "Busy", Prompt: "p", Language: "python", Difficulty: "easy", Active: true}
+	require.NoError(t, db.Create(&busy).Error)
+	require.NoError(t, db.Create(&quiet).Error)
+
+	for _, studentID := range []uint{7, 7, 8} {
+		submission := models.CodingSubmission{TaskID: busy.ID, StudentID: studentID, Language: "python", Status: models.CodingSubmissionStatusEvaluated}
+		require.NoError(t, db.Create(&submission).Error)
+		score := 40.0 + float64(submission.ID)*10
+		require.NoError(t, db.Create(&models.CodingEvaluation{SubmissionID: submission.ID, Score: score}).Error)
+	}
+
+	svc := NewCodingTaskService(repository.NewCodingTaskRepository(db), zerolog.Nop())
+
+	newest, err := svc.List(context.Background(), dto.CodingTaskFilter{})
+	require.NoError(t, err)
+	require.Equal(t, "newest", newest.Filters.Sort)
+	require.Nil(t, newest.Items[0].BestScore)
+
+	popular, err := svc.List(context.Background(), dto.CodingTaskFilter{Sort: "Popular", Language: " Python ", StudentID: 7})
+	require.NoError(t, err)
+	require.Len(t, popular.Items, 2)
+	require.Equal(t, busy.ID, popular.Items[0].ID)
+	require.Equal(t, "popular", popular.Filters.Sort)
+	require.Equal(t, "python", popular.Filters.Language)
+	require.NotNil(t, popular.Items[0].BestScore)
+	require.Equal(t, 60.0, *popular.Items[0].BestScore)
+	require.Nil(t, popular.Items[1].BestScore)
+
+	_, err = svc.List(context.Background(), dto.CodingTaskFilter{Sort: "oldest"})
+	require.ErrorIs(t, err, ErrInvalidCodingTaskSort)
+}
+
+func TestCodingTaskServiceSetEnvValidatesVariables(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:coding_tasks_env_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.CodingTask{}, &models.CodingTaskTestCase{}))
+
+	task := models.CodingTask{Title: "Dice", Prompt: "p", Language: "python", Difficulty: "easy", Active: true}
+	require.NoError(t, db.Create(&task).Error)
+	svc := NewCodingTaskService(repository.NewCodingTaskRepository(db), zerolog.Nop())
+
+	for _, env := range []map[string]string{
+		{"PATH": "/tmp"},
+		{"LD_PRELOAD": "/tmp/x.so"},
+		{"PYTHONSTARTUP": "/tmp/x.py"},
+		{"seed": "1"},
+		{"SEED": strings.Repeat("9", maxTaskEnvValueBytes+1)},
+	} {
+		_, err := svc.SetEnv(context.Background(), task.ID, env)
+		require.ErrorIs(t, err, ErrInvalidCodingTaskEnv, "env %v", env)
+	}
+
+	tooMany := make(map[string]string)
+	for i := 0; i <= maxTaskEnvVars; i++ {
+		tooMany[fmt.Sprintf("VAR_%d", i)] = "x"
+	}
+	_, err = svc.SetEnv(context.Background(), task.ID, tooMany)
+	require.ErrorIs(t, err, ErrInvalidCodingTaskEnv)
+
+	updated, err := svc.SetEnv(context.Background(), task.ID, map[string]string{"SEED": "42", "ROUNDS": "3"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"ROUNDS", "SEED"}, updated.EnvVars)
+
+	stored, err := repository.NewCodingTaskRepository(db).GetByID(context.Background(), task.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"SEED": "42", "ROUNDS": "3"}, stored.EnvVars())
+
+	_, err = svc.SetEnv(context.Background(), 999, map[string]string{"SEED": "1"})
+	require.ErrorIs(t, err, ErrCodingTaskNotFound)
+}
@@ -2,10 +2,26 @@ package service
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/rs/zerolog"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/observability"
 )
 
 // LogContactDelivery is a basic provider that logs submissions.
@@ -18,8 +34,247 @@ func NewLogContactDelivery(logger zerolog.Logger) *LogContactDelivery {
 	return &LogContactDelivery{logger: logger.With().Str("component", "contact_delivery").Logger()}
 }
 
-// Deliver logs the submission and returns nil to indicate success.
+// Deliver logs the submission and returns nil to indicate success. The message
+// is logged under the observability.LogContent policy; the sender's address is
+// left out.
 func (l *LogContactDelivery) Deliver(ctx context.Context, submission models.ContactSubmission) error {
-	l.logger.Info().Str("reference_id", submission.ReferenceID).Msg("contact submission delivered to inbox")
+	l.logger.Info().
+		Str("reference_id", submission.ReferenceID).
+		Str("content", observability.LogContent(submission.Message)).
+		Msg("contact submission delivered to inbox")
 	return nil
 }
+
+const (
+	defaultContactDeliveryRetryDelay = 500 * time.Millisecond
+	maxContactDeliveryRetryDelay     = 10 * time.Second
+	defaultSMTPTimeout               = 15 * time.Second
+)
+
+// SMTPConfig configures SMTPContactDelivery. Username and Password are
+// optional; when set, PLAIN auth is used, which net/smtp only sends over TLS
+// or to localhost.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	Timeout  time.Duration
+}
+
+// SMTPContactDelivery emails contact submissions to the staff inbox.
+type SMTPContactDelivery struct {
+	cfg    SMTPConfig
+	dial   func(ctx context.Context, network, addr string) (net.Conn, error)
+	logger zerolog.Logger
+	clock  clock.Clock
+}
+
+// NewSMTPContactDelivery constructs an SMTP provider.
+func NewSMTPContactDelivery(cfg SMTPConfig, logger zerolog.Logger) *SMTPContactDelivery {
+	if cfg.Port <= 0 {
+		cfg.Port = 587
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultSMTPTimeout
+	}
+	return &SMTPContactDelivery{
+		cfg:    cfg,
+		dial:   (&net.Dialer{Timeout: cfg.Timeout}).DialContext,
+		logger: logger.With().Str("component", "contact_delivery").Logger(),
+		clock:  clock.Real(),
+	}
+}
+
+// Deliver sends the submission with the submitter as Reply-To. The whole
+// exchange shares one connection deadline of the configured timeout, or ctx's
+// deadline when sooner, and cancelling ctx aborts it.
+func (d *SMTPContactDelivery) Deliver(ctx context.Context, submission models.ContactSubmission) error {
+	if err := d.send(ctx, d.message(submission)); err != nil {
+		return fmt.Errorf("smtp delivery: %w", err)
+	}
+	d.logger.Info().
+		Str("reference_id", submission.ReferenceID).
+		Msg("contact submission emailed to inbox")
+	return nil
+}
+
+// send runs the same conversation as smtp.SendMail over a connection it owns,
+// so a stalled server cannot outlive the call.
+func (d *SMTPContactDelivery) send(ctx context.Context, msg []byte) error {
+	deadline := time.Now().Add(d.cfg.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	conn, err := d.dial(ctx, "tcp", net.JoinHostPort(d.cfg.Host, strconv.Itoa(d.cfg.Port)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	err = d.converse(conn, msg)
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+func (d *SMTPContactDelivery) converse(conn net.Conn, msg []byte) error {
+	client, err := smtp.NewClient(conn, d.cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Hello("localhost"); err != nil {
+		return err
+	}
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: d.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if d.cfg.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("server doesn't support AUTH")
+		}
+		if err := client.Auth(smtp.PlainAuth("", d.cfg.Username, d.cfg.Password, d.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(d.cfg.From); err != nil {
+		return err
+	}
+	for _, recipient := range d.cfg.To {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(msg); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (d *SMTPContactDelivery) message(submission models.ContactSubmission) []byte {
+	name := stripHeaderBreaks(submission.Name)
+	headers := []string{
+		"From: " + d.cfg.From,
+		"To: " + strings.Join(d.cfg.To, ", "),
+		"Reply-To: " + (&mail.Address{Name: name, Address: stripHeaderBreaks(submission.Email)}).String(),
+		"Subject: " + mime.QEncoding.Encode("utf-8", "Contact form: "+name),
+		"Date: " + d.clock.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Name: %s\r\n", name)
+	fmt.Fprintf(&body, "Email: %s\r\n", stripHeaderBreaks(submission.Email))
+	if submission.Source != "" {
+		fmt.Fprintf(&body, "Source: %s\r\n", stripHeaderBreaks(submission.Source))
+	}
+	fmt.Fprintf(&body, "Reference: %s\r\n\r\n", submission.ReferenceID)
+	body.WriteString(strings.ReplaceAll(strings.ReplaceAll(submission.Message, "\r\n", "\n"), "\n", "\r\n"))
+	body.WriteString("\r\n")
+
+	return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + body.String())
+}
+
+// stripHeaderBreaks keeps user input on a single header line.
+func stripHeaderBreaks(value string) string {
+	return strings.TrimSpace(strings.NewReplacer("\r", " ", "\n", " ").Replace(value))
+}
+
+// ContactDeliveryRetry bounds how often a transient delivery failure is
+// retried. Delays grow exponentially from BaseDelay (500ms when unset) with
+// jitter, capped at ten seconds.
+type ContactDeliveryRetry struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+}
+
+// WithContactDeliveryRetry wraps delivery so transient failures such as
+// network errors or SMTP 4xx replies are retried. Zero retries returns
+// delivery as is.
+func WithContactDeliveryRetry(delivery ContactDelivery, retry ContactDeliveryRetry) ContactDelivery {
+	if delivery == nil || retry.MaxRetries <= 0 {
+		return delivery
+	}
+	if retry.BaseDelay <= 0 {
+		retry.BaseDelay = defaultContactDeliveryRetryDelay
+	}
+	return &retryContactDelivery{next: delivery, retry: retry}
+}
+
+type retryContactDelivery struct {
+	next  ContactDelivery
+	retry ContactDeliveryRetry
+}
+
+func (d *retryContactDelivery) Deliver(ctx context.Context, submission models.ContactSubmission) error {
+	for attempt := 0; ; attempt++ {
+		err := d.next.Deliver(ctx, submission)
+		if err == nil || attempt >= d.retry.MaxRetries || !isTransientDeliveryError(err) {
+			return err
+		}
+
+		timer := time.NewTimer(d.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (d *retryContactDelivery) backoff(attempt int) time.Duration {
+	ceiling := d.retry.BaseDelay << attempt
+	if ceiling <= 0 || ceiling > maxContactDeliveryRetryDelay {
+		ceiling = maxContactDeliveryRetryDelay
+	}
+	return ceiling/2 + time.Duration(rand.Int63n(int64(ceiling/2)+1))
+}
+
+// isTransientDeliveryError reports whether a failed delivery is worth
+// repeating: network failures, timeouts and SMTP 4xx replies. Permanent 5xx
+// replies and cancelled requests are not.
+func isTransientDeliveryError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 400 && protoErr.Code < 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE)
+}
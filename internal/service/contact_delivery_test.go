@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/models"
+)
+
+func TestSMTPContactDeliverySendsMessage(t *testing.T) {
+	delivery := NewSMTPContactDelivery(SMTPConfig{
+		Host:     "localhost",
+		Username: "mailer",
+		Password: "secret",
+		From:     "noreply@gema.dev",
+		To:       []string{"staff@gema.dev", "ops@gema.dev"},
+	}, testLogger())
+	delivery.clock = clock.NewFixed(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC))
+
+	var gotAddr string
+	server := &fakeSMTPServer{}
+	delivery.dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		gotAddr = addr
+		return server.serve(t), nil
+	}
+
+	err := delivery.Deliver(context.Background(), models.ContactSubmission{
+		ReferenceID: "ref-1",
+		Name:        "Budi\r\nBcc: victim@example.com",
+		Email:       "budi@example.com",
+		Message:     "Line one\nLine two",
+		Source:      "landing",
+	})
+	require.NoError(t, err)
+	<-server.done
+
+	require.Equal(t, "localhost:587", gotAddr)
+	require.Contains(t, server.commands, "AUTH PLAIN AG1haWxlcgBzZWNyZXQ=")
+	require.Contains(t, server.commands, "MAIL FROM:<noreply@gema.dev>")
+	require.Contains(t, server.commands, "RCPT TO:<staff@gema.dev>")
+	require.Contains(t, server.commands, "RCPT TO:<ops@gema.dev>")
+	require.Equal(t, "QUIT", server.commands[len(server.commands)-1])
+
+	// textproto's dot reader turns the CRLF line endings into LF.
+	headers, body, found := strings.Cut(server.data, "\n\n")
+	require.True(t, found)
+	require.Contains(t, headers, "To: staff@gema.dev, ops@gema.dev")
+	require.Contains(t, headers, "<budi@example.com>")
+	require.Contains(t, headers, "Date: Wed, 01 May 2024 09:00:00 +0000")
+	require.NotContains(t, headers, "\nBcc:", "user input must not inject headers")
+	require.Contains(t, body, "Reference: ref-1")
+	require.Contains(t, body, "Line one\nLine two")
+}
+
+func TestSMTPContactDeliveryTimesOutStalledServer(t *testing.T) {
+	delivery := NewSMTPContactDelivery(SMTPConfig{
+		Host:    "smtp.example.com",
+		From:    "noreply@gema.dev",
+		To:      []string{"staff@gema.dev"},
+		Timeout: 50 * time.Millisecond,
+	}, testLogger())
+
+	client, server := net.Pipe()
+	defer server.Close()
+	delivery.dial = func(context.Context, string, string) (net.Conn, error) {
+		return client, nil
+	}
+
+	started := time.Now()
+	err := delivery.Deliver(context.Background(), models.ContactSubmission{ReferenceID: "ref-1"})
+	require.Error(t, err)
+	require.True(t, isTransientDeliveryError(err))
+	require.Less(t, time.Since(started), 2*time.Second)
+
+	_, err = client.Write([]byte("x"))
+	require.Error(t, err, "the connection is closed once Deliver returns")
+}
+
+// fakeSMTPServer answers one SMTP conversation over an in-memory connection
+// and records the commands and message it received.
+type fakeSMTPServer struct {
+	commands []string
+	data     string
+	done     chan struct{}
+}
+
+func (s *fakeSMTPServer) serve(t *testing.T) net.Conn {
+	client, server := net.Pipe()
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		defer server.Close()
+		conn := textproto.NewConn(server)
+		reply := func(line string) {
+			require.NoError(t, conn.PrintfLine("%s", line))
+		}
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := conn.ReadLine()
+			if err != nil {
+				return
+			}
+			s.commands = append(s.commands, line)
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case strings.HasPrefix(line, "AUTH"):
+				reply("235 authenticated")
+			case line == "DATA":
+				reply("354 go ahead")
+				data, err := conn.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				s.data = string(data)
+				reply("250 queued")
+			case line == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return client
+}
+
+func TestContactDeliveryRetryRetriesTransientFailures(t *testing.T) {
+	calls := 0
+	flaky := contactDeliveryFunc(func(context.Context, models.ContactSubmission) error {
+		calls++
+		if calls < 3 {
+			return &textproto.Error{Code: 421, Msg: "try again later"}
+		}
+		return nil
+	})
+
+	delivery := WithContactDeliveryRetry(flaky, ContactDeliveryRetry{MaxRetries: 2, BaseDelay: time.Millisecond})
+	require.NoError(t, delivery.Deliver(context.Background(), models.ContactSubmission{}))
+	require.Equal(t, 3, calls)
+
+	calls = 0
+	rejected := contactDeliveryFunc(func(context.Context, models.ContactSubmission) error {
+		calls++
+		return &textproto.Error{Code: 550, Msg: "mailbox unavailable"}
+	})
+	delivery = WithContactDeliveryRetry(rejected, ContactDeliveryRetry{MaxRetries: 2, BaseDelay: time.Millisecond})
+	require.Error(t, delivery.Deliver(context.Background(), models.ContactSubmission{}))
+	require.Equal(t, 1, calls, "permanent failures are not retried")
+
+	require.False(t, isTransientDeliveryError(errors.New("bad config")))
+	require.True(t, isTransientDeliveryError(context.DeadlineExceeded))
+}
+
+type contactDeliveryFunc func(context.Context, models.ContactSubmission) error
+
+func (f contactDeliveryFunc) Deliver(ctx context.Context, submission models.ContactSubmission) error {
+	return f(ctx, submission)
+}
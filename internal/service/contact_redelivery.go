@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/observability"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+const (
+	defaultContactRedeliveryInterval = 5 * time.Minute
+	contactRedeliveryBatchSize       = 50
+	// contactRedeliveryClaimTTL bounds how long a crashed replica holds its
+	// claimed submissions before another one retries them.
+	contactRedeliveryClaimTTL = 15 * time.Minute
+)
+
+// ContactRedeliveryConfig controls the queued contact redelivery job. A zero
+// After disables it.
+type ContactRedeliveryConfig struct {
+	After    time.Duration
+	Interval time.Duration
+}
+
+// ContactRedeliverer periodically retries delivery of contact submissions
+// left queued by a failed send.
+type ContactRedeliverer interface {
+	Start(ctx context.Context)
+	RedeliverQueued(ctx context.Context) (int, error)
+}
+
+type contactRedeliverer struct {
+	repo     repository.ContactRepository
+	delivery ContactDelivery
+	cfg      ContactRedeliveryConfig
+	logger   zerolog.Logger
+	clock    clock.Clock
+	nodeID   string
+}
+
+// NewContactRedeliverer constructs the redelivery job.
+func NewContactRedeliverer(repo repository.ContactRepository, delivery ContactDelivery, cfg ContactRedeliveryConfig, logger zerolog.Logger) ContactRedeliverer {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultContactRedeliveryInterval
+	}
+
+	return &contactRedeliverer{
+		repo:     repo,
+		delivery: delivery,
+		cfg:      cfg,
+		logger:   logger.With().Str("component", "contact_redeliverer").Logger(),
+		clock:    clock.Real(),
+		nodeID:   uuid.NewString(),
+	}
+}
+
+// Start runs RedeliverQueued every interval until ctx is cancelled.
+func (j *contactRedeliverer) Start(ctx context.Context) {
+	if j.cfg.After <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(j.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := j.RedeliverQueued(ctx); err != nil && ctx.Err() == nil {
+				j.logger.Error().Err(err).Msg("failed to redeliver queued contact submissions")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RedeliverQueued retries submissions queued for longer than After and
+// returns how many were delivered. Submissions are claimed first so replicas
+// running the job do not send the same one twice. A failed attempt touches the
+// submission so it waits another After before the next try instead of
+// blocking the batch.
+func (j *contactRedeliverer) RedeliverQueued(ctx context.Context) (int, error) {
+	if j.cfg.After <= 0 {
+		return 0, nil
+	}
+
+	now := j.clock.Now()
+	until := now.Add(contactRedeliveryClaimTTL)
+	submissions, err := j.repo.ClaimQueued(ctx, j.nodeID, now.Add(-j.cfg.After), now, until, contactRedeliveryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, submission := range submissions {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+		// Once the lease runs out another replica may hold the rest.
+		if !j.clock.Now().Before(until) {
+			break
+		}
+
+		status := models.ContactStatusSent
+		if err := j.delivery.Deliver(ctx, submission); err != nil {
+			j.logger.Warn().Err(err).Str("reference_id", submission.ReferenceID).Msg("contact redelivery failed")
+			status = models.ContactStatusQueued
+		}
+
+		if err := j.repo.UpdateStatus(ctx, submission.ID, status); err != nil {
+			return delivered, err
+		}
+		if status == models.ContactStatusSent {
+			delivered++
+			observability.ContactSubmissions().WithLabelValues("redelivered").Inc()
+		}
+	}
+
+	return delivered, nil
+}
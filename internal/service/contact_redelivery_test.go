@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+func TestContactRedelivererRetriesStaleQueuedSubmissions(t *testing.T) {
+	dsn := fmt.Sprintf("file:contact_redelivery_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.ContactSubmission{}))
+
+	old := time.Now().Add(-time.Hour)
+	stale := models.ContactSubmission{ReferenceID: "stale", Name: "A", Email: "a@example.com", Message: "Hi", Status: models.ContactStatusQueued, CreatedAt: old, UpdatedAt: old}
+	failing := models.ContactSubmission{ReferenceID: "failing", Name: "B", Email: "b@example.com", Message: "Hi", Status: models.ContactStatusQueued, CreatedAt: old, UpdatedAt: old}
+	fresh := models.ContactSubmission{ReferenceID: "fresh", Name: "C", Email: "c@example.com", Message: "Hi", Status: models.ContactStatusQueued}
+	sent := models.ContactSubmission{ReferenceID: "sent", Name: "D", Email: "d@example.com", Message: "Hi", Status: models.ContactStatusSent, CreatedAt: old, UpdatedAt: old}
+	for _, submission := range []*models.ContactSubmission{&stale, &failing, &fresh, &sent} {
+		require.NoError(t, db.Create(submission).Error)
+	}
+
+	var attempted []string
+	delivery := contactDeliveryFunc(func(_ context.Context, submission models.ContactSubmission) error {
+		attempted = append(attempted, submission.ReferenceID)
+		if submission.ReferenceID == "failing" {
+			return errors.New("smtp down")
+		}
+		return nil
+	})
+
+	repo := repository.NewContactRepository(db)
+	job := NewContactRedeliverer(repo, delivery, ContactRedeliveryConfig{After: 10 * time.Minute}, testLogger())
+
+	delivered, err := job.RedeliverQueued(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+	require.ElementsMatch(t, []string{"stale", "failing"}, attempted)
+
+	got, err := repo.GetByID(context.Background(), stale.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.ContactStatusSent, got.Status)
+
+	got, err = repo.GetByID(context.Background(), failing.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.ContactStatusQueued, got.Status)
+	require.True(t, got.UpdatedAt.After(old), "failed attempts wait another window")
+
+	attempted = nil
+	delivered, err = job.RedeliverQueued(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, delivered)
+	require.Empty(t, attempted)
+}
+
+func TestContactRedelivererSkipsSubmissionsClaimedByAnotherReplica(t *testing.T) {
+	dsn := fmt.Sprintf("file:contact_redelivery_claim_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.ContactSubmission{}))
+
+	old := time.Now().Add(-time.Hour)
+	queued := models.ContactSubmission{ReferenceID: "queued", Name: "A", Email: "a@example.com", Message: "Hi", Status: models.ContactStatusQueued, CreatedAt: old, UpdatedAt: old}
+	require.NoError(t, db.Create(&queued).Error)
+
+	repo := repository.NewContactRepository(db)
+	now := time.Now()
+	claimed, err := repo.ClaimQueued(context.Background(), "other-replica", now.Add(-10*time.Minute), now, now.Add(contactRedeliveryClaimTTL), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	var attempted []string
+	delivery := contactDeliveryFunc(func(_ context.Context, submission models.ContactSubmission) error {
+		attempted = append(attempted, submission.ReferenceID)
+		return nil
+	})
+	job := NewContactRedeliverer(repo, delivery, ContactRedeliveryConfig{After: 10 * time.Minute}, testLogger()).(*contactRedeliverer)
+
+	delivered, err := job.RedeliverQueued(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, delivered)
+	require.Empty(t, attempted, "a live claim keeps other replicas away")
+
+	// A replica that crashed mid-batch gives its submissions up once the lease ends.
+	job.clock = clock.NewFixed(now.Add(contactRedeliveryClaimTTL + time.Second))
+	delivered, err = job.RedeliverQueued(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+	require.Equal(t, []string{"queued"}, attempted)
+
+	got, err := repo.GetByID(context.Background(), queued.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.ContactStatusSent, got.Status)
+	require.Nil(t, got.ClaimedUntil)
+}
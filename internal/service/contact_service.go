@@ -18,7 +18,9 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/logging"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/observability"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -29,8 +31,27 @@ var (
 	ErrContactSpam = errors.New("contact submission flagged as spam")
 	// ErrContactDuplicate indicates a submission with the same checksum exists recently.
 	ErrContactDuplicate = errors.New("duplicate contact submission")
+	// ErrContactRateLimited indicates the sender exceeded the per-email submission limit.
+	ErrContactRateLimited = errors.New("too many contact submissions from this email")
 )
 
+const (
+	defaultContactDedupeTTL  = 5 * time.Minute
+	defaultContactRateLimit  = 3
+	defaultContactRateWindow = time.Hour
+)
+
+// ContactLimits bounds contact form abuse. Identical submissions within
+// DedupeTTL are rejected as duplicates, and each email may submit at most
+// PerEmail messages per PerEmailWindow. Zero values use the defaults (5
+// minutes, 3 per hour); a negative PerEmail disables the per-email limit.
+// Both checks need Redis and are skipped without it.
+type ContactLimits struct {
+	DedupeTTL      time.Duration
+	PerEmail       int
+	PerEmailWindow time.Duration
+}
+
 // ContactDelivery defines a transport to deliver contact messages.
 type ContactDelivery interface {
 	Deliver(ctx context.Context, submission models.ContactSubmission) error
@@ -46,22 +67,36 @@ type contactService struct {
 	cache     *redis.Client
 	validator *validator.Validate
 	delivery  ContactDelivery
+	activity  ActivityRecorder
 	logger    zerolog.Logger
-	dedupeTTL time.Duration
+	limits    ContactLimits
 	tracer    trace.Tracer
+	clock     clock.Clock
 }
 
-// NewContactService constructs a contact submission service.
-func NewContactService(repo repository.ContactRepository, cache *redis.Client, validator *validator.Validate, delivery ContactDelivery, logger zerolog.Logger) ContactService {
-	ttl := 5 * time.Minute
+// NewContactService constructs a contact submission service. Stored
+// submissions are recorded as contact.submitted activity when activity is not
+// nil.
+func NewContactService(repo repository.ContactRepository, cache *redis.Client, validator *validator.Validate, delivery ContactDelivery, limits ContactLimits, activity ActivityRecorder, logger zerolog.Logger) ContactService {
+	if limits.DedupeTTL <= 0 {
+		limits.DedupeTTL = defaultContactDedupeTTL
+	}
+	if limits.PerEmail == 0 {
+		limits.PerEmail = defaultContactRateLimit
+	}
+	if limits.PerEmailWindow <= 0 {
+		limits.PerEmailWindow = defaultContactRateWindow
+	}
 	return &contactService{
 		repo:      repo,
 		cache:     cache,
 		validator: validator,
 		delivery:  delivery,
+		activity:  activity,
 		logger:    logger.With().Str("component", "contact_service").Logger(),
-		dedupeTTL: ttl,
+		limits:    limits,
 		tracer:    otel.Tracer("github.com/noah-isme/gema-go-api/internal/service/contact"),
+		clock:     clock.Real(),
 	}
 }
 
@@ -75,6 +110,7 @@ func (s *contactService) Submit(ctx context.Context, req dto.ContactRequest) (dt
 		return dto.ContactResponse{}, ErrContactSpam
 	}
 
+	req.Email = normalizeContactEmail(req.Email)
 	if err := s.validator.Struct(req); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "validation failed")
@@ -86,7 +122,7 @@ func (s *contactService) Submit(ctx context.Context, req dto.ContactRequest) (dt
 
 	if s.cache != nil {
 		key := fmt.Sprintf("contact:dedupe:%s", checksum)
-		ok, err := s.cache.SetNX(ctx, key, 1, s.dedupeTTL).Result()
+		ok, err := s.cache.SetNX(ctx, key, 1, s.limits.DedupeTTL).Result()
 		if err != nil {
 			span.RecordError(err)
 			return dto.ContactResponse{}, err
@@ -96,17 +132,29 @@ func (s *contactService) Submit(ctx context.Context, req dto.ContactRequest) (dt
 			observability.ContactSubmissions().WithLabelValues("duplicate").Inc()
 			return dto.ContactResponse{}, ErrContactDuplicate
 		}
+
+		if err := s.checkEmailRate(ctx, req.Email); err != nil {
+			span.RecordError(err)
+			if errors.Is(err, ErrContactRateLimited) {
+				span.SetStatus(codes.Error, "rate limited")
+				observability.ContactSubmissions().WithLabelValues("rate_limited").Inc()
+			}
+			return dto.ContactResponse{}, err
+		}
 	}
 
 	referenceID := uuid.New().String()
+	now := s.clock.Now()
 	submission := models.ContactSubmission{
 		ReferenceID: referenceID,
 		Name:        strings.TrimSpace(req.Name),
-		Email:       strings.ToLower(strings.TrimSpace(req.Email)),
+		Email:       req.Email,
 		Message:     strings.TrimSpace(req.Message),
 		Source:      strings.TrimSpace(req.Source),
-		Status:      "queued",
+		Status:      models.ContactStatusQueued,
 		Checksum:    checksum,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}
 
 	if err := s.repo.Create(ctx, &submission); err != nil {
@@ -116,28 +164,78 @@ func (s *contactService) Submit(ctx context.Context, req dto.ContactRequest) (dt
 		return dto.ContactResponse{}, err
 	}
 
+	if s.activity != nil {
+		// Only the reference is recorded; staff read the message itself
+		// through the admin contact endpoints.
+		_, _ = s.activity.Record(ctx, ActivityEntry{
+			ActorRole:  "guest",
+			Action:     "contact.submitted",
+			EntityType: "contact_submission",
+			EntityID:   &submission.ID,
+			Metadata: map[string]interface{}{
+				"reference_id": submission.ReferenceID,
+				"source":       submission.Source,
+			},
+		})
+	}
+
 	deliveryErr := s.delivery.Deliver(ctx, submission)
 	if deliveryErr != nil {
 		span.RecordError(deliveryErr)
-		s.logger.Warn().Err(deliveryErr).Str("reference_id", referenceID).Msg("contact delivery failed")
-		observability.ContactSubmissions().WithLabelValues("queued").Inc()
-		return dto.ContactResponse{ReferenceID: referenceID, Status: "queued"}, nil
+		logging.FromContext(ctx, s.logger).Warn().Err(deliveryErr).Str("reference_id", referenceID).Msg("contact delivery failed")
+		observability.ContactSubmissions().WithLabelValues(models.ContactStatusQueued).Inc()
+		return dto.ContactResponse{ReferenceID: referenceID, Status: models.ContactStatusQueued}, nil
 	}
 
-	if err := s.repo.UpdateStatus(ctx, submission.ID, "sent"); err != nil {
+	if err := s.repo.UpdateStatus(ctx, submission.ID, models.ContactStatusSent); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "status update failed")
 		observability.ContactSubmissions().WithLabelValues("error").Inc()
 		return dto.ContactResponse{}, err
 	}
 
-	observability.ContactSubmissions().WithLabelValues("sent").Inc()
+	observability.ContactSubmissions().WithLabelValues(models.ContactStatusSent).Inc()
 
-	maskedEmail := maskEmailAddress(submission.Email)
-	s.logger.Info().Str("reference_id", referenceID).Str("email", maskedEmail).Msg("contact submission processed")
+	logging.FromContext(ctx, s.logger).Info().Str("reference_id", referenceID).Msg("contact submission processed")
 	span.SetStatus(codes.Ok, "delivered")
 
-	return dto.ContactResponse{ReferenceID: referenceID, Status: "sent"}, nil
+	return dto.ContactResponse{ReferenceID: referenceID, Status: models.ContactStatusSent}, nil
+}
+
+// contactRateScript counts a submission in a fixed window, setting the
+// window's expiry in the same step so a failure between the two can never
+// leave a counter that does not expire.
+var contactRateScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// normalizeContactEmail trims and lowercases an address so the rate limit,
+// the duplicate check and the stored submission agree on the sender.
+func normalizeContactEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// checkEmailRate counts the submission against its sender in a fixed window
+// keyed by the hash of the normalized email, so raw addresses never reach
+// Redis.
+func (s *contactService) checkEmailRate(ctx context.Context, email string) error {
+	if s.limits.PerEmail < 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf("contact:rate:%s", computeChecksum(normalizeContactEmail(email)))
+	count, err := contactRateScript.Run(ctx, s.cache, []string{key}, s.limits.PerEmailWindow.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if count > int64(s.limits.PerEmail) {
+		return ErrContactRateLimited
+	}
+	return nil
 }
 
 func computeChecksum(parts ...string) string {
@@ -1,17 +1,26 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	miniredis "github.com/alicebob/miniredis/v2"
 	"github.com/go-playground/validator/v10"
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/observability"
+	"github.com/noah-isme/gema-go-api/internal/repository"
 )
 
 type contactRepoStub struct {
@@ -29,6 +38,18 @@ func (c *contactRepoStub) UpdateStatus(ctx context.Context, id uint, status stri
 	return nil
 }
 
+func (c *contactRepoStub) List(ctx context.Context, filter repository.AdminContactFilter) ([]models.ContactSubmission, int64, error) {
+	return []models.ContactSubmission{c.created}, 1, nil
+}
+
+func (c *contactRepoStub) GetByID(ctx context.Context, id uint) (models.ContactSubmission, error) {
+	return c.created, nil
+}
+
+func (c *contactRepoStub) ClaimQueued(ctx context.Context, owner string, updatedBefore, now, until time.Time, limit int) ([]models.ContactSubmission, error) {
+	return nil, nil
+}
+
 type failingDelivery struct{}
 
 func (f failingDelivery) Deliver(ctx context.Context, submission models.ContactSubmission) error {
@@ -45,7 +66,7 @@ func TestContactServiceDuplicate(t *testing.T) {
 
 	repo := &contactRepoStub{}
 	delivery := NewLogContactDelivery(testLogger())
-	svc := NewContactService(repo, redisClient, validator.New(), delivery, testLogger())
+	svc := NewContactService(repo, redisClient, validator.New(), delivery, ContactLimits{}, nil, testLogger())
 
 	payload := dto.ContactRequest{Name: "User", Email: "user@example.com", Message: "Hello world"}
 	_, err = svc.Submit(context.Background(), payload)
@@ -55,9 +76,44 @@ func TestContactServiceDuplicate(t *testing.T) {
 	require.ErrorIs(t, err, ErrContactDuplicate)
 }
 
+func TestContactServiceRateLimitsPerEmail(t *testing.T) {
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	defer server.Close()
+
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+
+	svc := NewContactService(&contactRepoStub{}, redisClient, validator.New(), NewLogContactDelivery(testLogger()), ContactLimits{PerEmail: 2, PerEmailWindow: time.Hour}, nil, testLogger())
+
+	for i := 0; i < 2; i++ {
+		_, err = svc.Submit(context.Background(), dto.ContactRequest{Name: "User", Email: "user@example.com", Message: fmt.Sprintf("Follow-up number %d", i)})
+		require.NoError(t, err)
+	}
+
+	_, err = svc.Submit(context.Background(), dto.ContactRequest{Name: "User", Email: " USER@example.com ", Message: "One more message"})
+	require.ErrorIs(t, err, ErrContactRateLimited)
+	for _, key := range server.Keys() {
+		if strings.HasPrefix(key, "contact:rate:") {
+			require.Positive(t, server.TTL(key), "the window always expires")
+		}
+	}
+
+	_, err = svc.Submit(context.Background(), dto.ContactRequest{Name: "Other", Email: "other@example.com", Message: "Different sender"})
+	require.NoError(t, err)
+
+	for _, key := range server.Keys() {
+		require.NotContains(t, key, "example.com", "emails are hashed in rate limit keys")
+	}
+
+	server.FastForward(time.Hour)
+	_, err = svc.Submit(context.Background(), dto.ContactRequest{Name: "User", Email: "user@example.com", Message: "Next hour message"})
+	require.NoError(t, err)
+}
+
 func TestContactServiceDeliveryFailure(t *testing.T) {
 	repo := &contactRepoStub{}
-	svc := NewContactService(repo, nil, validator.New(), failingDelivery{}, testLogger())
+	svc := NewContactService(repo, nil, validator.New(), failingDelivery{}, ContactLimits{}, nil, testLogger())
 
 	payload := dto.ContactRequest{Name: "User", Email: "user@example.com", Message: "Hello world"}
 	resp, err := svc.Submit(context.Background(), payload)
@@ -66,14 +122,14 @@ func TestContactServiceDeliveryFailure(t *testing.T) {
 }
 
 func TestContactServiceSpam(t *testing.T) {
-	svc := NewContactService(&contactRepoStub{}, nil, validator.New(), NewLogContactDelivery(testLogger()), testLogger())
+	svc := NewContactService(&contactRepoStub{}, nil, validator.New(), NewLogContactDelivery(testLogger()), ContactLimits{}, nil, testLogger())
 	_, err := svc.Submit(context.Background(), dto.ContactRequest{Name: "User", Email: "user@example.com", Message: "Hello", Honeypot: "x"})
 	require.ErrorIs(t, err, ErrContactSpam)
 }
 
 func TestContactServiceSuccess(t *testing.T) {
 	repo := &contactRepoStub{}
-	svc := NewContactService(repo, nil, validator.New(), NewLogContactDelivery(testLogger()), testLogger())
+	svc := NewContactService(repo, nil, validator.New(), NewLogContactDelivery(testLogger()), ContactLimits{}, nil, testLogger())
 
 	payload := dto.ContactRequest{Name: "User", Email: "user@example.com", Message: "Hello world"}
 	resp, err := svc.Submit(context.Background(), payload)
@@ -82,3 +138,42 @@ func TestContactServiceSuccess(t *testing.T) {
 	require.Equal(t, "sent", repo.status)
 	require.NotEmpty(t, repo.created.ReferenceID)
 }
+
+func TestContactServiceStampsSubmissionWithClock(t *testing.T) {
+	repo := &contactRepoStub{}
+	svc := NewContactService(repo, nil, validator.New(), NewLogContactDelivery(testLogger()), ContactLimits{}, nil, testLogger())
+	fixed := time.Date(2024, time.February, 29, 23, 59, 0, 0, time.UTC)
+	svc.(*contactService).clock = clock.NewFixed(fixed)
+
+	_, err := svc.Submit(context.Background(), dto.ContactRequest{Name: "User", Email: "user@example.com", Message: "Hello world"})
+	require.NoError(t, err)
+	require.Equal(t, fixed, repo.created.CreatedAt)
+	require.Equal(t, fixed, repo.created.UpdatedAt)
+}
+
+func TestLogContactDeliveryTruncatesAndRedactsMessage(t *testing.T) {
+	t.Cleanup(func() { observability.SetLogContentPolicy(observability.LogContentPolicy{Redact: true}) })
+
+	submission := models.ContactSubmission{
+		ReferenceID: "ref-1",
+		Email:       "student@example.com",
+		Message:     "Please reach me at private.person@example.org\nabout " + strings.Repeat("the group project ", 20),
+	}
+
+	var buf bytes.Buffer
+	observability.SetLogContentPolicy(observability.LogContentPolicy{MaxLength: 40})
+	require.NoError(t, NewLogContactDelivery(zerolog.New(&buf)).Deliver(context.Background(), submission))
+
+	var entry map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.NotContains(t, entry, "email")
+	require.NotContains(t, buf.String(), "student")
+	require.Equal(t, fmt.Sprintf("Please reach me at [email] about the gro… [%d chars]", len(submission.Message)), entry["content"])
+	require.NotContains(t, buf.String(), "private.person")
+
+	buf.Reset()
+	observability.SetLogContentPolicy(observability.LogContentPolicy{Redact: true})
+	require.NoError(t, NewLogContactDelivery(zerolog.New(&buf)).Deliver(context.Background(), submission))
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, fmt.Sprintf("[redacted %d chars]", len(submission.Message)), entry["content"])
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/dto"
+)
+
+// Cache keys for public content. Readers build keys with the helpers below and
+// writers invalidate through the matching prefix, so a key format change
+// cannot leave mutations clearing the wrong entries.
+const (
+	announcementsCachePrefix = "announcements:"
+	roadmapCachePrefix       = "roadmap:"
+)
+
+// announcementListCacheKey names one cached page of active announcements for
+// an audience, so targeted announcements are never served from another
+// audience's page. List pages shift whenever an announcement is added or
+// changed, so mutations drop every page with invalidateAnnouncementCache.
+func announcementListCacheKey(viewer dto.AnnouncementViewer, page, pageSize int) string {
+	return fmt.Sprintf("%sactive:v2:%s:%d:%d", announcementsCachePrefix, announcementAudienceKey(viewer), page, pageSize)
+}
+
+// announcementAudienceKey is the cache dimension for a viewer; viewers with no
+// class or role share the "all" pages.
+func announcementAudienceKey(viewer dto.AnnouncementViewer) string {
+	audience := viewer.Audience()
+	if len(audience) == 0 {
+		return "all"
+	}
+	return url.QueryEscape(strings.Join(audience, ","))
+}
+
+// invalidateAnnouncementCache drops every cached announcement list. Failures
+// are logged; the entries expire on their own TTL.
+func invalidateAnnouncementCache(ctx context.Context, store cache.Store, logger zerolog.Logger) {
+	if store == nil {
+		return
+	}
+	if err := store.DeletePrefix(ctx, announcementsCachePrefix); err != nil {
+		logger.Warn().Err(err).Msg("failed to flush announcement cache")
+	}
+}
+
+// invalidateRoadmapCache drops every cached roadmap page after a stage
+// changes. Failures are logged; the entries expire on their own TTL.
+func invalidateRoadmapCache(ctx context.Context, store cache.Store, logger zerolog.Logger) {
+	if store == nil {
+		return
+	}
+	if err := store.DeletePrefix(ctx, roadmapCachePrefix); err != nil {
+		logger.Warn().Err(err).Msg("failed to flush roadmap cache")
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+const (
+	defaultDiscussionAutoCloseInterval = time.Hour
+	discussionAutoCloseBatchSize       = 100
+)
+
+// DiscussionAutoCloseConfig controls the stale thread job. A zero
+// InactiveAfter disables it.
+type DiscussionAutoCloseConfig struct {
+	InactiveAfter time.Duration
+	Interval      time.Duration
+	// Action is either "lock" (the default) or "archive".
+	Action       string
+	NotifyAuthor bool
+}
+
+// DiscussionAutoCloser locks or archives discussion threads without recent activity.
+type DiscussionAutoCloser interface {
+	Start(ctx context.Context)
+	CloseStale(ctx context.Context) (int, error)
+}
+
+type discussionAutoCloser struct {
+	repo          repository.DiscussionRepository
+	notifications NotificationPublisher
+	cfg           DiscussionAutoCloseConfig
+	status        string
+	logger        zerolog.Logger
+	clock         clock.Clock
+}
+
+// NewDiscussionAutoCloser constructs the stale thread job.
+func NewDiscussionAutoCloser(repo repository.DiscussionRepository, notifications NotificationPublisher, cfg DiscussionAutoCloseConfig, logger zerolog.Logger) DiscussionAutoCloser {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultDiscussionAutoCloseInterval
+	}
+
+	status := models.DiscussionThreadLocked
+	if strings.EqualFold(strings.TrimSpace(cfg.Action), "archive") {
+		status = models.DiscussionThreadArchived
+	}
+
+	return &discussionAutoCloser{
+		repo:          repo,
+		notifications: notifications,
+		cfg:           cfg,
+		status:        status,
+		logger:        logger.With().Str("component", "discussion_autoclose").Logger(),
+		clock:         clock.Real(),
+	}
+}
+
+// Start runs CloseStale every interval until ctx is cancelled.
+func (j *discussionAutoCloser) Start(ctx context.Context) {
+	if j.cfg.InactiveAfter <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(j.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := j.CloseStale(ctx); err != nil && ctx.Err() == nil {
+				j.logger.Error().Err(err).Msg("failed to close stale discussion threads")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// CloseStale closes every open, unpinned thread whose last activity is older
+// than the configured window and returns how many were closed.
+func (j *discussionAutoCloser) CloseStale(ctx context.Context) (int, error) {
+	if j.cfg.InactiveAfter <= 0 {
+		return 0, nil
+	}
+
+	now := j.clock.Now()
+	cutoff := now.Add(-j.cfg.InactiveAfter)
+
+	closed := 0
+	for {
+		threads, err := j.repo.ListStaleThreads(ctx, cutoff, discussionAutoCloseBatchSize)
+		if err != nil {
+			return closed, err
+		}
+
+		batchClosed := 0
+		for _, thread := range threads {
+			ok, err := j.repo.CloseStaleThread(ctx, thread.ID, j.status, cutoff, now)
+			if err != nil {
+				return closed + batchClosed, err
+			}
+			if !ok {
+				continue
+			}
+			batchClosed++
+			j.notifyAuthor(ctx, thread)
+		}
+
+		closed += batchClosed
+		if len(threads) < discussionAutoCloseBatchSize || batchClosed == 0 {
+			break
+		}
+	}
+
+	if closed > 0 {
+		j.logger.Info().Int("closed", closed).Str("status", j.status).Dur("inactive_after", j.cfg.InactiveAfter).Msg("closed stale discussion threads")
+	}
+
+	return closed, nil
+}
+
+func (j *discussionAutoCloser) notifyAuthor(ctx context.Context, thread models.DiscussionThread) {
+	if !j.cfg.NotifyAuthor || j.notifications == nil || thread.AuthorID == "" {
+		return
+	}
+
+	payload := dto.NotificationCreateRequest{
+		UserID:  thread.AuthorID,
+		Type:    "discussion_closed",
+		Message: fmt.Sprintf("Your thread '%s' was %s after a period of inactivity", thread.Title, j.status),
+	}
+	if _, err := j.notifications.Publish(ctx, payload); err != nil {
+		j.logger.Warn().Err(err).Uint("thread_id", thread.ID).Msg("failed to notify thread author")
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+func setupDiscussionAutoClose(t *testing.T) (*gorm.DB, repository.DiscussionRepository) {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:discussion_autoclose_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.DiscussionThread{}, &models.DiscussionReply{}))
+
+	return db, repository.NewDiscussionRepository(db)
+}
+
+func TestDiscussionAutoCloserLocksStaleThreads(t *testing.T) {
+	db, repo := setupDiscussionAutoClose(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	stale := models.DiscussionThread{Title: "Old question", AuthorID: "7", CreatedAt: now.AddDate(0, 0, -60), UpdatedAt: now.AddDate(0, 0, -40)}
+	recent := models.DiscussionThread{Title: "Fresh question", AuthorID: "8", CreatedAt: now.AddDate(0, 0, -60), UpdatedAt: now.Add(-time.Hour)}
+	pinned := models.DiscussionThread{Title: "Course rules", AuthorID: "1", Pinned: true, CreatedAt: now.AddDate(0, 0, -90), UpdatedAt: now.AddDate(0, 0, -90)}
+	require.NoError(t, db.Create(&stale).Error)
+	require.NoError(t, db.Create(&recent).Error)
+	require.NoError(t, db.Create(&pinned).Error)
+
+	notifications := &stubNotificationPublisher{}
+	closer := NewDiscussionAutoCloser(repo, notifications, DiscussionAutoCloseConfig{
+		InactiveAfter: 30 * 24 * time.Hour,
+		NotifyAuthor:  true,
+	}, testLogger())
+
+	closed, err := closer.CloseStale(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, closed)
+
+	got, err := repo.GetThread(ctx, stale.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.DiscussionThreadLocked, got.Status)
+	require.NotNil(t, got.ClosedAt)
+	require.WithinDuration(t, stale.UpdatedAt, got.UpdatedAt, time.Second)
+
+	got, err = repo.GetThread(ctx, recent.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.DiscussionThreadOpen, got.Status)
+	require.Nil(t, got.ClosedAt)
+
+	got, err = repo.GetThread(ctx, pinned.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.DiscussionThreadOpen, got.Status)
+
+	require.Len(t, notifications.calls, 1)
+	require.Equal(t, "7", notifications.calls[0].UserID)
+	require.Equal(t, "discussion_closed", notifications.calls[0].Type)
+
+	svc := NewDiscussionService(repo, nil, validator.New(validator.WithRequiredStructEnabled()), testLogger())
+	_, err = svc.CreateReply(ctx, "9", "student", dto.DiscussionReplyCreateRequest{ThreadID: stale.ID, Content: "Any update?"})
+	require.ErrorIs(t, err, ErrDiscussionThreadClosed)
+
+	closed, err = closer.CloseStale(ctx)
+	require.NoError(t, err)
+	require.Zero(t, closed)
+}
+
+func TestDiscussionAutoCloserArchivesStaleThreads(t *testing.T) {
+	db, repo := setupDiscussionAutoClose(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	stale := models.DiscussionThread{Title: "Old question", AuthorID: "7", UpdatedAt: now.AddDate(0, 0, -10)}
+	recent := models.DiscussionThread{Title: "Fresh question", AuthorID: "8", UpdatedAt: now}
+	require.NoError(t, db.Create(&stale).Error)
+	require.NoError(t, db.Create(&recent).Error)
+
+	notifications := &stubNotificationPublisher{}
+	closer := NewDiscussionAutoCloser(repo, notifications, DiscussionAutoCloseConfig{
+		InactiveAfter: 7 * 24 * time.Hour,
+		Action:        "archive",
+	}, testLogger())
+
+	closed, err := closer.CloseStale(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, closed)
+	require.Empty(t, notifications.calls)
+
+	threads, err := repo.ListThreads(ctx, 20, 0)
+	require.NoError(t, err)
+	require.Len(t, threads, 1)
+	require.Equal(t, recent.ID, threads[0].ID)
+}
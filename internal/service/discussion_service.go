@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
-	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/microcosm-cc/bluemonday"
@@ -16,14 +15,20 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"gorm.io/datatypes"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/logging"
 	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/observability"
 	"github.com/noah-isme/gema-go-api/internal/repository"
 )
 
 // ErrDiscussionForbidden indicates the user attempted an operation they are not allowed to perform.
 var ErrDiscussionForbidden = errors.New("insufficient permissions for discussion operation")
 
+// ErrDiscussionThreadClosed indicates a reply was posted to a locked or archived thread.
+var ErrDiscussionThreadClosed = errors.New("discussion thread is closed")
+
 // NotificationPublisher exposes the subset of notification service needed by discussions.
 type NotificationPublisher interface {
 	Publish(ctx context.Context, payload dto.NotificationCreateRequest) (dto.NotificationResponse, error)
@@ -37,6 +42,7 @@ type DiscussionService interface {
 	UpdateThread(ctx context.Context, id uint, authorID, role string, payload dto.DiscussionThreadUpdateRequest) (dto.DiscussionThreadResponse, error)
 	DeleteThread(ctx context.Context, id uint, authorID, role string) error
 	ListReplies(ctx context.Context, threadID uint, limit, offset int) ([]dto.DiscussionReplyResponse, error)
+	ListRepliesAfter(ctx context.Context, threadID uint, cursor string, limit int) ([]dto.DiscussionReplyResponse, string, error)
 	CreateReply(ctx context.Context, authorID, role string, payload dto.DiscussionReplyCreateRequest) (dto.DiscussionReplyResponse, error)
 }
 
@@ -48,7 +54,7 @@ type discussionService struct {
 	tracer         trace.Tracer
 	sanitizer      *bluemonday.Policy
 	mentionPattern *regexp.Regexp
-	now            func() time.Time
+	clock          clock.Clock
 }
 
 // NewDiscussionService constructs a discussion service.
@@ -64,7 +70,7 @@ func NewDiscussionService(repo repository.DiscussionRepository, notifications No
 		tracer:         otel.Tracer("github.com/noah-isme/gema-go-api/internal/service/discussion"),
 		sanitizer:      policy,
 		mentionPattern: regexp.MustCompile(`@([a-zA-Z0-9_\-:]+)`),
-		now:            time.Now,
+		clock:          clock.Real(),
 	}
 }
 
@@ -123,7 +129,11 @@ func (s *discussionService) CreateThread(ctx context.Context, authorID, role str
 		return dto.DiscussionThreadResponse{}, err
 	}
 
-	s.logger.Info().Uint("thread_id", thread.ID).Str("author_id", authorID).Msg("discussion thread created")
+	logging.FromContext(ctx, s.logger).Info().
+		Uint("thread_id", thread.ID).
+		Str("author_id", authorID).
+		Str("title", observability.LogContent(thread.Title)).
+		Msg("discussion thread created")
 
 	return dto.NewDiscussionThreadResponse(thread), nil
 }
@@ -150,6 +160,24 @@ func (s *discussionService) UpdateThread(ctx context.Context, id uint, authorID,
 		thread.Title = sanitized
 	}
 
+	if payload.Pinned != nil || payload.Status != nil {
+		if !IsStaffRole(role) {
+			return dto.DiscussionThreadResponse{}, ErrDiscussionForbidden
+		}
+		if payload.Pinned != nil {
+			thread.Pinned = *payload.Pinned
+		}
+		if payload.Status != nil && *payload.Status != thread.Status {
+			thread.Status = *payload.Status
+			if thread.Status == models.DiscussionThreadOpen {
+				thread.ClosedAt = nil
+			} else {
+				closedAt := s.clock.Now()
+				thread.ClosedAt = &closedAt
+			}
+		}
+	}
+
 	if err := s.repo.UpdateThread(ctx, &thread); err != nil {
 		return dto.DiscussionThreadResponse{}, err
 	}
@@ -178,6 +206,17 @@ func (s *discussionService) ListReplies(ctx context.Context, threadID uint, limi
 	return dto.NewDiscussionReplyResponseSlice(replies), nil
 }
 
+// ListRepliesAfter pages a thread's replies oldest first, resuming after
+// cursor ("" for the first page). It returns the cursor of the next page, or
+// "" on the last one.
+func (s *discussionService) ListRepliesAfter(ctx context.Context, threadID uint, cursor string, limit int) ([]dto.DiscussionReplyResponse, string, error) {
+	replies, next, err := s.repo.ListRepliesAfter(ctx, threadID, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	return dto.NewDiscussionReplyResponseSlice(replies), next, nil
+}
+
 func (s *discussionService) CreateReply(ctx context.Context, authorID, role string, payload dto.DiscussionReplyCreateRequest) (dto.DiscussionReplyResponse, error) {
 	if err := s.validator.Struct(payload); err != nil {
 		return dto.DiscussionReplyResponse{}, err
@@ -192,6 +231,9 @@ func (s *discussionService) CreateReply(ctx context.Context, authorID, role stri
 	if err != nil {
 		return dto.DiscussionReplyResponse{}, err
 	}
+	if thread.Status != "" && thread.Status != models.DiscussionThreadOpen {
+		return dto.DiscussionReplyResponse{}, ErrDiscussionThreadClosed
+	}
 
 	reply := models.DiscussionReply{
 		ThreadID: payload.ThreadID,
@@ -209,11 +251,10 @@ func (s *discussionService) CreateReply(ctx context.Context, authorID, role stri
 }
 
 func (s *discussionService) authorizeMutation(ownerID, actorID, role string) error {
-	role = strings.ToLower(strings.TrimSpace(role))
 	if actorID == ownerID {
 		return nil
 	}
-	if role == "admin" || role == "teacher" {
+	if IsStaffRole(role) {
 		return nil
 	}
 	return ErrDiscussionForbidden
@@ -245,7 +286,7 @@ func (s *discussionService) dispatchNotifications(ctx context.Context, thread mo
 			Message: message,
 		}
 		if _, err := s.notifications.Publish(ctx, payload); err != nil {
-			s.logger.Warn().Err(err).Str("user_id", userID).Msg("failed to publish discussion notification")
+			logging.FromContext(ctx, s.logger).Warn().Err(err).Str("user_id", userID).Msg("failed to publish discussion notification")
 		}
 	}
 }
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog"
@@ -55,6 +56,18 @@ func (s *stubDiscussionRepo) ListReplies(ctx context.Context, threadID uint, lim
 	return s.replies, nil
 }
 
+func (s *stubDiscussionRepo) ListRepliesAfter(ctx context.Context, threadID uint, cursor string, limit int) ([]models.DiscussionReply, string, error) {
+	return s.replies, "", nil
+}
+
+func (s *stubDiscussionRepo) ListStaleThreads(ctx context.Context, inactiveSince time.Time, limit int) ([]models.DiscussionThread, error) {
+	return nil, nil
+}
+
+func (s *stubDiscussionRepo) CloseStaleThread(ctx context.Context, id uint, status string, inactiveSince, closedAt time.Time) (bool, error) {
+	return false, nil
+}
+
 type stubNotificationPublisher struct {
 	calls []dto.NotificationCreateRequest
 }
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// exportGeneratedAction is the activity action recorded for every admin
+// download, with the report name, format, row count and filters as metadata.
+const exportGeneratedAction = "export.generated"
+
+func recordExport(ctx context.Context, activity ActivityRecorder, logger zerolog.Logger, actor ActivityActor, report, format string, rows int, filters map[string]interface{}) {
+	if activity == nil {
+		return
+	}
+	entry := ActivityEntry{
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+		Action:     exportGeneratedAction,
+		EntityType: "export",
+		Metadata: map[string]interface{}{
+			"report":  report,
+			"format":  format,
+			"rows":    rows,
+			"filters": filters,
+		},
+	}
+	if _, err := activity.Record(ctx, entry); err != nil {
+		logger.Warn().Err(err).Str("report", report).Msg("failed to record export activity")
+	}
+}
+
+// csvCell neutralises values a spreadsheet would evaluate as a formula.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
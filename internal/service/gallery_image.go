@@ -0,0 +1,114 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // register GIF decoding for gallery uploads
+	"image/jpeg"
+	_ "image/png" // register PNG decoding for gallery uploads
+)
+
+// ErrGalleryImageUnreadable indicates an upload that looks like an image but
+// cannot be decoded, or whose dimensions are out of bounds.
+var ErrGalleryImageUnreadable = errors.New("image could not be processed")
+
+const (
+	// galleryThumbnailWidth is the widest a generated thumbnail gets; narrower
+	// images keep their size.
+	galleryThumbnailWidth = 480
+	// maxImagePixels bounds decoded images so a small, highly compressed
+	// file cannot expand into an enormous bitmap.
+	maxImagePixels          = 40_000_000
+	galleryThumbnailQuality = 82
+)
+
+// galleryImage is a decoded upload with its JPEG thumbnail.
+type galleryImage struct {
+	width     int
+	height    int
+	thumbnail []byte
+}
+
+// processGalleryImage checks the image dimensions before decoding and
+// renders a thumbnail no wider than galleryThumbnailWidth. Transparent areas
+// are flattened onto white because thumbnails are JPEG.
+func processGalleryImage(payload []byte) (galleryImage, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(payload))
+	if err != nil {
+		return galleryImage{}, ErrGalleryImageUnreadable
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return galleryImage{}, ErrGalleryImageUnreadable
+	}
+
+	source, _, err := image.Decode(bytes.NewReader(payload))
+	if err != nil {
+		return galleryImage{}, ErrGalleryImageUnreadable
+	}
+
+	width, height := config.Width, config.Height
+	thumbWidth, thumbHeight := width, height
+	if width > galleryThumbnailWidth {
+		thumbWidth = galleryThumbnailWidth
+		thumbHeight = max(1, height*galleryThumbnailWidth/width)
+	}
+
+	thumb := image.NewRGBA(image.Rect(0, 0, thumbWidth, thumbHeight))
+	draw.Draw(thumb, thumb.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	scaleBox(thumb, source)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: galleryThumbnailQuality}); err != nil {
+		return galleryImage{}, err
+	}
+
+	return galleryImage{width: width, height: height, thumbnail: buf.Bytes()}, nil
+}
+
+// scaleBox downsamples src into dst by averaging the source pixels each
+// destination pixel covers, compositing over what dst already holds.
+func scaleBox(dst *image.RGBA, src image.Image) {
+	sb := src.Bounds()
+	db := dst.Bounds()
+	for y := 0; y < db.Dy(); y++ {
+		y0 := sb.Min.Y + y*sb.Dy()/db.Dy()
+		y1 := max(y0+1, sb.Min.Y+(y+1)*sb.Dy()/db.Dy())
+		for x := 0; x < db.Dx(); x++ {
+			x0 := sb.Min.X + x*sb.Dx()/db.Dx()
+			x1 := max(x0+1, sb.Min.X+(x+1)*sb.Dx()/db.Dx())
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+
+			// Source colours are alpha-premultiplied, so blending over the
+			// background is dst*(1-alpha) + src.
+			bg := dst.RGBAAt(db.Min.X+x, db.Min.Y+y)
+			alpha := a / n
+			blend := func(sum uint64, background uint8) uint8 {
+				value := sum/n + uint64(background)*257*(0xffff-alpha)/0xffff
+				if value > 0xffff {
+					value = 0xffff
+				}
+				return uint8(value >> 8)
+			}
+			dst.SetRGBA(db.Min.X+x, db.Min.Y+y, color.RGBA{
+				R: blend(r, bg.R),
+				G: blend(g, bg.G),
+				B: blend(b, bg.B),
+				A: 0xff,
+			})
+		}
+	}
+}
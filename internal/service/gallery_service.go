@@ -17,7 +17,7 @@ import (
 
 // GalleryService exposes read operations for the public gallery.
 type GalleryService interface {
-	List(ctx context.Context, tags []string, search string, page, pageSize int) (dto.GalleryListResponse, error)
+	List(ctx context.Context, tags []string, search string, featuredOnly bool, page, pageSize int) (dto.GalleryListResponse, error)
 	Seed(ctx context.Context, items repository.GalleryFilter, upsert func(ctx context.Context) error) error
 }
 
@@ -36,7 +36,9 @@ func NewGalleryService(repo repository.GalleryRepository, cdnBase string, logger
 	}
 }
 
-func (s *galleryService) List(ctx context.Context, tags []string, search string, page, pageSize int) (dto.GalleryListResponse, error) {
+// List returns featured items first, then items by their curated sort order,
+// then the rest newest first.
+func (s *galleryService) List(ctx context.Context, tags []string, search string, featuredOnly bool, page, pageSize int) (dto.GalleryListResponse, error) {
 	start := time.Now()
 	defer func() {
 		observability.GalleryLatency().Observe(time.Since(start).Seconds())
@@ -45,7 +47,7 @@ func (s *galleryService) List(ctx context.Context, tags []string, search string,
 	page = maxInt(page, 1)
 	pageSize = clampPageSize(pageSize)
 
-	filter := repository.GalleryFilter{Tags: tags, Search: search, Page: page, PageSize: pageSize}
+	filter := repository.GalleryFilter{Tags: tags, Search: search, FeaturedOnly: featuredOnly, Page: page, PageSize: pageSize}
 	items, total, err := s.repo.List(ctx, filter)
 	if err != nil {
 		observability.GalleryRequests().WithLabelValues("error").Inc()
@@ -55,12 +57,16 @@ func (s *galleryService) List(ctx context.Context, tags []string, search string,
 	responses := make([]dto.GalleryItemResponse, 0, len(items))
 	for _, item := range items {
 		responses = append(responses, dto.GalleryItemResponse{
-			ID:        item.ID,
-			Title:     item.Title,
-			Caption:   item.Caption,
-			ImageURL:  s.normalizeURL(item.ImagePath),
-			Tags:      append([]string(nil), item.Tags...),
-			CreatedAt: item.CreatedAt,
+			ID:           item.ID,
+			Title:        item.Title,
+			Caption:      item.Caption,
+			ImageURL:     s.normalizeURL(item.ImagePath),
+			ThumbnailURL: s.normalizeURL(item.ThumbnailPath),
+			Width:        item.Width,
+			Height:       item.Height,
+			Featured:     item.Featured,
+			Tags:         append([]string(nil), item.Tags...),
+			CreatedAt:    item.CreatedAt,
 		})
 	}
 
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -24,6 +25,45 @@ func (g *galleryRepoStub) UpsertBatch(ctx context.Context, items []models.Galler
 	return int64(len(items)), nil
 }
 
+func (g *galleryRepoStub) ExistingSlugs(ctx context.Context, slugs []string) ([]string, error) {
+	var existing []string
+	for _, item := range g.items {
+		for _, slug := range slugs {
+			if item.Slug == slug {
+				existing = append(existing, slug)
+				break
+			}
+		}
+	}
+	return existing, nil
+}
+
+func (g *galleryRepoStub) GetByID(ctx context.Context, id uint) (models.GalleryItem, error) {
+	for _, item := range g.items {
+		if item.ID == id {
+			return item, nil
+		}
+	}
+	return models.GalleryItem{}, gorm.ErrRecordNotFound
+}
+
+func (g *galleryRepoStub) Create(ctx context.Context, item *models.GalleryItem) error {
+	g.items = append(g.items, *item)
+	return nil
+}
+
+func (g *galleryRepoStub) Update(ctx context.Context, item *models.GalleryItem) error {
+	return nil
+}
+
+func (g *galleryRepoStub) Delete(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (g *galleryRepoStub) Reorder(ctx context.Context, ids []uint) error {
+	return nil
+}
+
 func TestGalleryServiceList(t *testing.T) {
 	repo := &galleryRepoStub{items: []models.GalleryItem{
 		{ID: 1, Title: "Sunrise", Caption: "Morning", ImagePath: "sunrise.jpg", Tags: []string{"nature", "sun"}, CreatedAt: time.Now()},
@@ -31,7 +71,7 @@ func TestGalleryServiceList(t *testing.T) {
 
 	svc := NewGalleryService(repo, "https://cdn.example.com/assets", testLogger())
 
-	resp, err := svc.List(context.Background(), []string{"nature"}, "sun", 1, 10)
+	resp, err := svc.List(context.Background(), []string{"nature"}, "sun", false, 1, 10)
 	require.NoError(t, err)
 	require.Len(t, resp.Items, 1)
 	require.Equal(t, "https://cdn.example.com/assets/sunrise.jpg", resp.Items[0].ImageURL)
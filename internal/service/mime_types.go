@@ -0,0 +1,142 @@
+package service
+
+import (
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+)
+
+// DefaultSubmissionMimeTypes lists the file types accepted for assignment submissions.
+var DefaultSubmissionMimeTypes = []string{"application/pdf", "application/zip", "text/plain"}
+
+// DefaultUploadMimeTypes lists the file types accepted by the generic upload endpoint.
+var DefaultUploadMimeTypes = []string{"image/*", "application/pdf", "application/zip"}
+
+// FileTypeError reports a rejected file together with the types the endpoint permits.
+type FileTypeError struct {
+	Detected string
+	Allowed  []string
+}
+
+func (e *FileTypeError) Error() string {
+	return fmt.Sprintf("file type %s not allowed; permitted types: %s", e.Detected, strings.Join(e.Allowed, ", "))
+}
+
+// Unwrap lets callers match the error against ErrUploadTypeNotAllowed.
+func (e *FileTypeError) Unwrap() error {
+	return ErrUploadTypeNotAllowed
+}
+
+// mimeAllowList validates detected MIME types against a normalised allowlist.
+type mimeAllowList struct {
+	allowed map[string]struct{}
+	display []string
+}
+
+func newMimeAllowList(types []string, defaults []string) mimeAllowList {
+	if len(types) == 0 {
+		types = defaults
+	}
+
+	list := mimeAllowList{allowed: make(map[string]struct{}, len(types))}
+	for _, t := range types {
+		normalized := normalizeMime(t)
+		if normalized == "" {
+			continue
+		}
+		if _, ok := list.allowed[normalized]; ok {
+			continue
+		}
+		list.allowed[normalized] = struct{}{}
+		list.display = append(list.display, displayMime(normalized))
+	}
+	return list
+}
+
+// check returns a FileTypeError when the detected type is not permitted.
+func (l mimeAllowList) check(detected string) error {
+	normalized := normalizeMime(detected)
+	if _, ok := l.allowed[normalized]; ok {
+		return nil
+	}
+	return &FileTypeError{Detected: displayMime(normalized), Allowed: append([]string(nil), l.display...)}
+}
+
+// sanitizableDisplay lists the permitted types with image/* narrowed to the
+// image types server uploads can strip of metadata.
+func (l mimeAllowList) sanitizableDisplay() []string {
+	display := make([]string, 0, len(l.display)+len(sanitizableImageTypes))
+	for _, entry := range l.display {
+		if entry == displayMime("image") {
+			display = append(display, sanitizableImageTypes...)
+			continue
+		}
+		display = append(display, entry)
+	}
+	return display
+}
+
+// normalizeMime lowercases the type, drops parameters and folds aliases so
+// that upload and submission paths compare MIME types the same way. Every
+// image subtype (and the image/* wildcard) collapses to "image".
+func normalizeMime(m string) string {
+	lower := strings.ToLower(strings.TrimSpace(m))
+	if idx := strings.Index(lower, ";"); idx >= 0 {
+		lower = strings.TrimSpace(lower[:idx])
+	}
+	if strings.HasPrefix(lower, "image/") {
+		return "image"
+	}
+	switch lower {
+	case "application/zip", "application/x-zip-compressed":
+		return "application/zip"
+	default:
+		return lower
+	}
+}
+
+func displayMime(normalized string) string {
+	if normalized == "image" {
+		return "image/*"
+	}
+	return normalized
+}
+
+// imageFormats are the image formats Cloudinary stores that the image/*
+// wildcard stands for.
+var imageFormats = []string{"avif", "bmp", "gif", "heic", "jpeg", "jpg", "png", "tif", "tiff", "webp"}
+
+// formats lists the file formats, as Cloudinary names them, that the
+// allowlist permits, for restricting signed direct uploads. It returns nil
+// when a permitted type has no known extension, since a format list would
+// shut that type out.
+func (l mimeAllowList) formats() []string {
+	seen := make(map[string]struct{})
+	for normalized := range l.allowed {
+		var extensions []string
+		switch normalized {
+		case "image":
+			extensions = imageFormats
+		case "application/zip":
+			extensions = []string{"zip"}
+		case "text/plain":
+			extensions = []string{"txt"}
+		default:
+			extensions, _ = mime.ExtensionsByType(normalized)
+		}
+		if len(extensions) == 0 {
+			return nil
+		}
+		for _, ext := range extensions {
+			seen[strings.TrimPrefix(ext, ".")] = struct{}{}
+		}
+	}
+
+	formats := make([]string, 0, len(seen))
+	for format := range seen {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
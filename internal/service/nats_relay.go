@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultJetStreamStream = "GEMA_EVENTS"
+	defaultJetStreamMaxAge = 24 * time.Hour
+	jetStreamAckWait       = 30 * time.Second
+)
+
+// JetStreamConfig configures the stream chat and notification events are
+// persisted to when the relay runs on JetStream.
+type JetStreamConfig struct {
+	// Stream is created on startup when missing. Defaults to GEMA_EVENTS.
+	Stream string
+	// MaxAge bounds how long unacknowledged events are retained. Defaults to
+	// 24h.
+	MaxAge time.Duration
+	// Node names this node's durable consumers. It must be stable across
+	// restarts and unique per replica.
+	Node string
+}
+
+// NATSRelay carries chat and notification events between API nodes over
+// NATS. Core NATS (the default) only reaches subscribers that are connected
+// when an event is published. With JetStream, events are stored in a stream
+// and delivered through durable consumers that survive restarts, so a node
+// coming back up receives what was published while it was away. Every node
+// has its own consumers, since every node must see every event.
+type NATSRelay struct {
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+	stream  string
+	subject string
+	node    string
+	maxAge  time.Duration
+}
+
+// NewNATSRelay relays events over core NATS.
+func NewNATSRelay(conn *nats.Conn) *NATSRelay {
+	return &NATSRelay{conn: conn}
+}
+
+// NewJetStreamRelay relays events over JetStream, creating the stream for
+// channelBase's subjects if it does not exist yet. An existing stream is used
+// as is.
+func NewJetStreamRelay(conn *nats.Conn, channelBase string, cfg JetStreamConfig) (*NATSRelay, error) {
+	if cfg.Stream == "" {
+		cfg.Stream = defaultJetStreamStream
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = defaultJetStreamMaxAge
+	}
+	if strings.TrimSpace(cfg.Node) == "" {
+		return nil, errors.New("jetstream relay needs a node name")
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("jetstream context: %w", err)
+	}
+
+	subject := natsSubjectBase(channelBase) + ".>"
+	if _, err := js.StreamInfo(cfg.Stream); err != nil {
+		if !errors.Is(err, nats.ErrStreamNotFound) {
+			return nil, fmt.Errorf("look up stream %s: %w", cfg.Stream, err)
+		}
+		if _, err := js.AddStream(&nats.StreamConfig{
+			Name:     cfg.Stream,
+			Subjects: []string{subject},
+			Storage:  nats.FileStorage,
+			MaxAge:   cfg.MaxAge,
+		}); err != nil {
+			return nil, fmt.Errorf("create stream %s: %w", cfg.Stream, err)
+		}
+	}
+
+	return &NATSRelay{conn: conn, js: js, stream: cfg.Stream, subject: subject, node: cfg.Node, maxAge: cfg.MaxAge}, nil
+}
+
+// natsSubjectBase maps a Redis-style channel base such as gema:events onto a
+// NATS subject prefix.
+func natsSubjectBase(channelBase string) string {
+	return strings.ReplaceAll(channelBase, ":", ".")
+}
+
+func (r *NATSRelay) publish(ctx context.Context, subject string, payload []byte) error {
+	if r.js != nil {
+		_, err := r.js.Publish(subject, payload, nats.Context(ctx))
+		return err
+	}
+	return r.conn.Publish(subject, payload)
+}
+
+// subscribe delivers every event on subject to handle until ctx is done.
+// Under JetStream the events come from this node's durable consumer for
+// name, and a message is acked only once handle returns nil, which handle
+// must only do after the event has reached this node's clients. A message
+// handle rejects is terminated rather than redelivered, since it will never
+// parse.
+func (r *NATSRelay) subscribe(ctx context.Context, subject, name string, handle func([]byte) error, logger zerolog.Logger) error {
+	var (
+		sub *nats.Subscription
+		err error
+	)
+	if r.js != nil {
+		durable := r.consumerName(name)
+		if err := r.ensureConsumer(subject, durable); err != nil {
+			return err
+		}
+		sub, err = r.js.Subscribe(subject, func(msg *nats.Msg) {
+			if err := handle(msg.Data); err != nil {
+				_ = msg.Term()
+				return
+			}
+			if err := msg.Ack(); err != nil {
+				logger.Warn().Err(err).Str("subject", msg.Subject).Msg("failed to ack jetstream message")
+			}
+		}, nats.Bind(r.stream, durable), nats.ManualAck())
+	} else {
+		sub, err = r.conn.Subscribe(subject, func(msg *nats.Msg) {
+			_ = handle(msg.Data)
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := sub.Drain(); err != nil {
+			logger.Warn().Err(err).Str("subject", subject).Msg("failed to drain nats subscription")
+		}
+	}()
+	return nil
+}
+
+// consumerName derives this node's durable consumer name for name, such as
+// gema-chat-api-0. Characters NATS does not allow in consumer names are
+// replaced.
+func (r *NATSRelay) consumerName(name string) string {
+	node := strings.Map(func(c rune) rune {
+		switch {
+		case c == '.' || c == '*' || c == '>' || c == '/' || c == '\\':
+			return '_'
+		case unicode.IsSpace(c):
+			return '_'
+		}
+		return c
+	}, r.node)
+	return name + "-" + node
+}
+
+// ensureConsumer creates the durable push consumer. It is created here rather
+// than by the subscription because nats.go deletes consumers it created
+// itself when the subscription drains, which would lose the backlog on every
+// deploy. Consumers of nodes that stay away longer than the stream keeps
+// events are removed by the server, as there is nothing left to catch up on.
+func (r *NATSRelay) ensureConsumer(subject, durable string) error {
+	_, err := r.js.ConsumerInfo(r.stream, durable)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, nats.ErrConsumerNotFound) {
+		return fmt.Errorf("look up consumer %s: %w", durable, err)
+	}
+
+	_, err = r.js.AddConsumer(r.stream, &nats.ConsumerConfig{
+		Durable:           durable,
+		DeliverSubject:    fmt.Sprintf("_GEMA_DELIVER.%s.%s", r.stream, durable),
+		FilterSubject:     subject,
+		DeliverPolicy:     nats.DeliverNewPolicy,
+		AckPolicy:         nats.AckExplicitPolicy,
+		AckWait:           jetStreamAckWait,
+		InactiveThreshold: r.maxAge,
+	})
+	if err != nil && !errors.Is(err, nats.ErrConsumerNameAlreadyInUse) {
+		return fmt.Errorf("create consumer %s: %w", durable, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNATSRelayConsumerNameIsPerNode(t *testing.T) {
+	relay := &NATSRelay{node: "api-0.gema svc"}
+	require.Equal(t, "gema-chat-api-0_gema_svc", relay.consumerName("gema-chat"))
+
+	other := &NATSRelay{node: "api-1"}
+	require.NotEqual(t, relay.consumerName("gema-chat"), other.consumerName("gema-chat"))
+}
+
+// jetStreamTestRelays connects to a local JetStream-enabled NATS server and
+// returns a relay per node sharing a fresh stream. The test is skipped when no
+// server is reachable.
+func jetStreamTestRelays(t *testing.T, nodes ...string) (string, []*NATSRelay) {
+	t.Helper()
+	conn, err := nats.Connect(nats.DefaultURL, nats.Timeout(time.Second))
+	if err != nil {
+		t.Skipf("nats server unavailable: %v", err)
+	}
+	t.Cleanup(conn.Close)
+	js, err := conn.JetStream()
+	require.NoError(t, err)
+	if _, err := js.AccountInfo(); err != nil {
+		t.Skipf("jetstream unavailable: %v", err)
+	}
+
+	id := strings.ReplaceAll(uuid.NewString(), "-", "")
+	stream := "GEMA_TEST_" + id
+	channelBase := "gema:test:" + id
+	t.Cleanup(func() { _ = js.DeleteStream(stream) })
+
+	relays := make([]*NATSRelay, 0, len(nodes))
+	for _, node := range nodes {
+		relay, err := NewJetStreamRelay(conn, channelBase, JetStreamConfig{Stream: stream, MaxAge: time.Minute, Node: node})
+		require.NoError(t, err)
+		relays = append(relays, relay)
+	}
+	return natsSubjectBase(channelBase) + ".chat", relays
+}
+
+func TestNATSRelayJetStreamDeliversEveryEventToEveryNode(t *testing.T) {
+	subject, relays := jetStreamTestRelays(t, "node-a", "node-b")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan string, 4)
+	for _, relay := range relays {
+		node := relay.node
+		require.NoError(t, relay.subscribe(ctx, subject, "gema-chat", func(payload []byte) error {
+			received <- node + ":" + string(payload)
+			return nil
+		}, zerolog.Nop()))
+	}
+
+	require.NoError(t, relays[0].publish(ctx, subject, []byte("hello")))
+
+	got := map[string]bool{}
+	for len(got) < 2 {
+		select {
+		case value := <-received:
+			got[value] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected both nodes to receive the event, got %v", got)
+		}
+	}
+	require.True(t, got["node-a:hello"])
+	require.True(t, got["node-b:hello"])
+}
+
+func TestNATSRelayJetStreamKeepsEventsForReturningNodes(t *testing.T) {
+	subject, relays := jetStreamTestRelays(t, "node-a")
+	relay := relays[0]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, relay.subscribe(ctx, subject, "gema-chat", func([]byte) error { return nil }, zerolog.Nop()))
+	cancel()
+	durable := relay.consumerName("gema-chat")
+	require.Eventually(t, func() bool {
+		info, err := relay.js.ConsumerInfo(relay.stream, durable)
+		return err == nil && !info.PushBound
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// An event published while the node is away waits in its consumer.
+	require.NoError(t, relay.publish(context.Background(), subject, []byte("missed")))
+	require.Eventually(t, func() bool {
+		info, err := relay.js.ConsumerInfo(relay.stream, durable)
+		return err == nil && info.NumPending+uint64(info.NumAckPending) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan string, 1)
+	require.NoError(t, relay.subscribe(ctx, subject, "gema-chat", func(payload []byte) error {
+		received <- string(payload)
+		return nil
+	}, zerolog.Nop()))
+	select {
+	case payload := <-received:
+		require.Equal(t, "missed", payload)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the missed event after resubscribing")
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/models"
+)
+
+const (
+	notificationOutboxTopic = "notifications"
+	outboxDispatchInterval  = time.Second
+	outboxBatchSize         = 100
+	// outboxClaimTTL bounds how long a crashed dispatcher holds its claimed
+	// events before another node picks them up.
+	outboxClaimTTL       = 30 * time.Second
+	outboxRetention      = 24 * time.Hour
+	outboxPruneInterval  = time.Hour
+	outboxErrorMaxLength = 512
+)
+
+// useOutbox reports whether notifications go through the outbox: only when
+// there is an outbox and another node to relay events to.
+func (s *notificationService) useOutbox() bool {
+	if s.outbox == nil {
+		return false
+	}
+	return (s.redis != nil && s.redisStream != "") || (s.nats != nil && s.natsSubject != "")
+}
+
+// storeWithOutbox inserts notifications together with the cross-node events
+// of the unmuted ones, then wakes the dispatcher.
+func (s *notificationService) storeWithOutbox(ctx context.Context, notifications []*models.Notification, muted map[string]bool) error {
+	err := s.repo.CreateWithOutbox(ctx, notifications, notificationBulkBatchSize, func(notification *models.Notification) (*models.OutboxEvent, error) {
+		if muted[notification.UserID] {
+			return nil, nil
+		}
+		payload, err := s.eventPayload(ctx, dto.NewNotificationResponse(*notification))
+		if err != nil {
+			return nil, err
+		}
+		return &models.OutboxEvent{
+			Topic:     notificationOutboxTopic,
+			Payload:   string(payload),
+			CreatedAt: notification.CreatedAt,
+		}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case s.outboxWake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// dispatchOutbox publishes pending outbox events as they are written and
+// every interval, to pick up events left behind by a failed publish or a
+// crashed node, until ctx is cancelled.
+func (s *notificationService) dispatchOutbox(ctx context.Context) {
+	ticker := time.NewTicker(outboxDispatchInterval)
+	defer ticker.Stop()
+
+	var lastPrune time.Time
+	for {
+		if _, err := s.flushOutbox(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error().Err(err).Msg("failed to dispatch notification outbox")
+		}
+
+		if now := s.clock.Now(); now.Sub(lastPrune) >= outboxPruneInterval {
+			lastPrune = now
+			if _, err := s.outbox.DeleteSentBefore(ctx, now.Add(-outboxRetention)); err != nil && ctx.Err() == nil {
+				s.logger.Warn().Err(err).Msg("failed to prune notification outbox")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.outboxWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// flushOutbox publishes claimed events until none are pending and returns how
+// many were sent. An event that fails to publish stays pending for the next
+// round.
+func (s *notificationService) flushOutbox(ctx context.Context) (int, error) {
+	sent := 0
+	for {
+		now := s.clock.Now()
+		events, err := s.outbox.Claim(ctx, notificationOutboxTopic, s.nodeID, now, now.Add(outboxClaimTTL), outboxBatchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		published := make([]uint, 0, len(events))
+		failed := false
+		for _, event := range events {
+			if err := s.publishPayload(ctx, []byte(event.Payload)); err != nil {
+				failed = true
+				s.logger.Warn().Err(err).Uint("outbox_id", event.ID).Msg("failed to publish notification outbox event")
+				if err := s.outbox.RecordFailure(ctx, event.ID, truncateOutboxError(err.Error())); err != nil {
+					s.logger.Warn().Err(err).Uint("outbox_id", event.ID).Msg("failed to record notification outbox failure")
+				}
+				continue
+			}
+			published = append(published, event.ID)
+		}
+
+		if err := s.outbox.MarkSent(ctx, published, s.clock.Now()); err != nil {
+			return sent, err
+		}
+		sent += len(published)
+
+		// A failing broker is retried on the next tick rather than in a loop.
+		if failed || len(events) < outboxBatchSize {
+			return sent, nil
+		}
+	}
+}
+
+func truncateOutboxError(message string) string {
+	if len(message) <= outboxErrorMaxLength {
+		return message
+	}
+	return message[:outboxErrorMaxLength]
+}
@@ -11,35 +11,53 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/microcosm-cc/bluemonday"
-	"github.com/nats-io/nats.go"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/observability"
 	"github.com/noah-isme/gema-go-api/internal/repository"
 )
 
-const notificationBufferSize = 16
+const (
+	notificationBufferSize    = 16
+	notificationBulkBatchSize = 200
+)
 
 // NotificationService publishes and streams notifications to end users via SSE.
 type NotificationService interface {
 	Publish(ctx context.Context, payload dto.NotificationCreateRequest) (dto.NotificationResponse, error)
+	PublishBulk(ctx context.Context, payload dto.NotificationBulkCreateRequest) ([]dto.NotificationResponse, error)
 	List(ctx context.Context, userID string, limit, offset int) ([]dto.NotificationResponse, error)
+	ListAfter(ctx context.Context, userID, cursor string, limit int) ([]dto.NotificationResponse, string, error)
 	MarkRead(ctx context.Context, id uint, userID string) (dto.NotificationResponse, error)
 	Subscribe(userID string) (<-chan dto.NotificationResponse, func())
 	Start(ctx context.Context)
+	ListMutes(ctx context.Context, userID string) (dto.NotificationMuteResponse, error)
+	Mute(ctx context.Context, userID, notificationType string) (dto.NotificationMuteResponse, error)
+	Unmute(ctx context.Context, userID, notificationType string) (dto.NotificationMuteResponse, error)
+	Shutdown(ctx context.Context) error
 }
 
+// ErrInvalidCursor is returned when a list is resumed from a cursor this API
+// did not issue.
+var ErrInvalidCursor = repository.ErrInvalidCursor
+
+// ErrNotificationTypeInvalid indicates a mute targets an empty or overlong notification type.
+var ErrNotificationTypeInvalid = errors.New("notification type must be 1-64 characters")
+
 type notificationService struct {
 	repo        repository.NotificationRepository
+	outbox      repository.OutboxRepository
+	outboxWake  chan struct{}
 	redis       *redis.Client
 	redisStream string
-	nats        *nats.Conn
+	nats        *NATSRelay
 	natsSubject string
 	validator   *validator.Validate
 	logger      zerolog.Logger
@@ -47,33 +65,43 @@ type notificationService struct {
 	sanitizer   *bluemonday.Policy
 	broker      *notificationBroker
 	nodeID      string
+	clock       clock.Clock
 }
 
 type notificationEvent struct {
 	Source       string                   `json:"source"`
 	Notification dto.NotificationResponse `json:"notification"`
 	SentAt       time.Time                `json:"sent_at"`
+	// TraceContext carries the publishing span as W3C trace headers.
+	TraceContext map[string]string `json:"trace_context,omitempty"`
 }
 
 type notificationBroker struct {
 	mu          sync.RWMutex
 	subscribers map[string]map[chan dto.NotificationResponse]struct{}
+	draining    bool
+	// active counts subscriptions whose streams have not been cleaned up yet.
+	active sync.WaitGroup
 }
 
-// NewNotificationService constructs a notification service.
-func NewNotificationService(repo repository.NotificationRepository, redisClient *redis.Client, channelBase string, natsConn *nats.Conn, validate *validator.Validate, logger zerolog.Logger) NotificationService {
+// NewNotificationService constructs a notification service. With an outbox,
+// cross-node events are written alongside the notifications and published by
+// a dispatcher Start runs; without one they are published straight away.
+func NewNotificationService(repo repository.NotificationRepository, outbox repository.OutboxRepository, redisClient *redis.Client, channelBase string, natsRelay *NATSRelay, validate *validator.Validate, logger zerolog.Logger) NotificationService {
 	stream := ""
 	subject := ""
 	if channelBase != "" {
 		stream = channelBase + ":notifications"
-		subject = strings.ReplaceAll(channelBase, ":", ".") + ".notifications"
+		subject = natsSubjectBase(channelBase) + ".notifications"
 	}
 
 	return &notificationService{
 		repo:        repo,
+		outbox:      outbox,
+		outboxWake:  make(chan struct{}, 1),
 		redis:       redisClient,
 		redisStream: stream,
-		nats:        natsConn,
+		nats:        natsRelay,
 		natsSubject: subject,
 		validator:   validate,
 		logger:      logger.With().Str("component", "notification_service").Logger(),
@@ -83,6 +111,7 @@ func NewNotificationService(repo repository.NotificationRepository, redisClient
 			subscribers: make(map[string]map[chan dto.NotificationResponse]struct{}),
 		},
 		nodeID: uuid.NewString(),
+		clock:  clock.Real(),
 	}
 }
 
@@ -93,6 +122,9 @@ func (s *notificationService) Start(ctx context.Context) {
 	if s.nats != nil && s.natsSubject != "" {
 		go s.consumeNATS(ctx)
 	}
+	if s.useOutbox() {
+		go s.dispatchOutbox(ctx)
+	}
 }
 
 func (s *notificationService) Publish(ctx context.Context, payload dto.NotificationCreateRequest) (dto.NotificationResponse, error) {
@@ -113,21 +145,39 @@ func (s *notificationService) Publish(ctx context.Context, payload dto.Notificat
 	spanCtx, span := s.tracer.Start(ctx, "notifications.publish", trace.WithAttributes(attrs...))
 	defer span.End()
 
+	now := s.clock.Now()
 	model := models.Notification{
-		UserID:  payload.UserID,
-		Type:    payload.Type,
-		Message: cleanMessage,
+		UserID:    payload.UserID,
+		Type:      payload.Type,
+		Message:   cleanMessage,
+		Priority:  notificationPriority(payload.Priority),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	muted, err := s.mutedRecipients(spanCtx, model.Type, model.Priority, []string{model.UserID})
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("failed to load notification mutes")
 	}
 
-	if err := s.repo.Create(spanCtx, &model); err != nil {
+	if s.useOutbox() {
+		err = s.storeWithOutbox(spanCtx, []*models.Notification{&model}, muted)
+	} else {
+		err = s.repo.Create(spanCtx, &model)
+	}
+	if err != nil {
 		span.RecordError(err)
 		return dto.NotificationResponse{}, err
 	}
 
 	response := dto.NewNotificationResponse(model)
-	s.broadcast(response)
-	if err := s.publish(spanCtx, response); err != nil {
-		s.logger.Warn().Err(err).Msg("failed to publish notification to broker")
+	if !muted[model.UserID] {
+		s.broadcast(response)
+		if !s.useOutbox() {
+			if err := s.publish(spanCtx, response); err != nil {
+				s.logger.Warn().Err(err).Msg("failed to publish notification to broker")
+			}
+		}
 	}
 
 	observability.NotificationsPublishedTotal().WithLabelValues(response.Type).Inc()
@@ -135,6 +185,94 @@ func (s *notificationService) Publish(ctx context.Context, payload dto.Notificat
 	return response, nil
 }
 
+// PublishBulk stores one notification per user in batches and broadcasts each
+// batch once it has been written.
+func (s *notificationService) PublishBulk(ctx context.Context, payload dto.NotificationBulkCreateRequest) ([]dto.NotificationResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	cleanMessage := strings.TrimSpace(s.sanitizer.Sanitize(payload.Message))
+	if cleanMessage == "" {
+		return nil, errors.New("notification message empty after sanitization")
+	}
+
+	spanCtx, span := s.tracer.Start(ctx, "notifications.publish_bulk", trace.WithAttributes(
+		attribute.String("notification.type", payload.Type),
+		attribute.Int("notification.recipients", len(payload.UserIDs)),
+	))
+	defer span.End()
+
+	priority := notificationPriority(payload.Priority)
+	responses := make([]dto.NotificationResponse, 0, len(payload.UserIDs))
+	for start := 0; start < len(payload.UserIDs); start += notificationBulkBatchSize {
+		end := min(start+notificationBulkBatchSize, len(payload.UserIDs))
+
+		now := s.clock.Now()
+		batch := make([]*models.Notification, 0, end-start)
+		for _, userID := range payload.UserIDs[start:end] {
+			batch = append(batch, &models.Notification{
+				UserID:    userID,
+				Type:      payload.Type,
+				Message:   cleanMessage,
+				Priority:  priority,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+		}
+
+		muted, err := s.mutedRecipients(spanCtx, payload.Type, priority, payload.UserIDs[start:end])
+		if err != nil {
+			s.logger.Warn().Err(err).Msg("failed to load notification mutes")
+		}
+
+		if s.useOutbox() {
+			err = s.storeWithOutbox(spanCtx, batch, muted)
+		} else {
+			err = s.repo.CreateBatch(spanCtx, batch, notificationBulkBatchSize)
+		}
+		if err != nil {
+			span.RecordError(err)
+			return responses, err
+		}
+
+		for _, model := range batch {
+			response := dto.NewNotificationResponse(*model)
+			if !muted[model.UserID] {
+				s.broadcast(response)
+				if !s.useOutbox() {
+					if err := s.publish(spanCtx, response); err != nil {
+						s.logger.Warn().Err(err).Str("user_id", response.UserID).Msg("failed to publish notification to broker")
+					}
+				}
+			}
+			responses = append(responses, response)
+		}
+		observability.NotificationsPublishedTotal().WithLabelValues(payload.Type).Add(float64(len(batch)))
+	}
+
+	return responses, nil
+}
+
+// mutedRecipients reports which recipients have muted notificationType. Muted
+// notifications are stored but neither streamed nor listed; high priority
+// notifications ignore mutes.
+func (s *notificationService) mutedRecipients(ctx context.Context, notificationType, priority string, userIDs []string) (map[string]bool, error) {
+	if priority == models.NotificationPriorityHigh {
+		return nil, nil
+	}
+	return s.repo.MutedUsers(ctx, notificationType, userIDs)
+}
+
+func notificationPriority(priority string) string {
+	if priority == "" {
+		return models.NotificationPriorityNormal
+	}
+	return priority
+}
+
+// List returns the user's notifications, high priority first and newest first
+// within each priority. Types the user muted are left out unless high priority.
 func (s *notificationService) List(ctx context.Context, userID string, limit, offset int) ([]dto.NotificationResponse, error) {
 	if strings.TrimSpace(userID) == "" {
 		return nil, errors.New("user id is required")
@@ -148,6 +286,22 @@ func (s *notificationService) List(ctx context.Context, userID string, limit, of
 	return dto.NewNotificationResponseSlice(notifications), nil
 }
 
+// ListAfter pages the user's notifications newest first, resuming after
+// cursor ("" for the first page). Unlike List it does not rank by priority.
+// It returns the cursor of the next page, or "" on the last one.
+func (s *notificationService) ListAfter(ctx context.Context, userID, cursor string, limit int) ([]dto.NotificationResponse, string, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, "", errors.New("user id is required")
+	}
+
+	notifications, next, err := s.repo.ListByUserAfter(ctx, userID, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return dto.NewNotificationResponseSlice(notifications), next, nil
+}
+
 func (s *notificationService) MarkRead(ctx context.Context, id uint, userID string) (dto.NotificationResponse, error) {
 	attrs := []attribute.KeyValue{
 		attribute.String("notification.user_id", userID),
@@ -164,36 +318,118 @@ func (s *notificationService) MarkRead(ctx context.Context, id uint, userID stri
 	return dto.NewNotificationResponse(notification), nil
 }
 
+func (s *notificationService) ListMutes(ctx context.Context, userID string) (dto.NotificationMuteResponse, error) {
+	if strings.TrimSpace(userID) == "" {
+		return dto.NotificationMuteResponse{}, errors.New("user id is required")
+	}
+
+	types, err := s.repo.ListMutedTypes(ctx, userID)
+	if err != nil {
+		return dto.NotificationMuteResponse{}, err
+	}
+	return dto.NotificationMuteResponse{Types: types}, nil
+}
+
+// Mute stops notifications of the given type from reaching the user, except
+// high priority ones. Muting an already muted type is a no-op.
+func (s *notificationService) Mute(ctx context.Context, userID, notificationType string) (dto.NotificationMuteResponse, error) {
+	notificationType, err := notificationMuteType(userID, notificationType)
+	if err != nil {
+		return dto.NotificationMuteResponse{}, err
+	}
+	if err := s.repo.Mute(ctx, userID, notificationType); err != nil {
+		return dto.NotificationMuteResponse{}, err
+	}
+	return s.ListMutes(ctx, userID)
+}
+
+func (s *notificationService) Unmute(ctx context.Context, userID, notificationType string) (dto.NotificationMuteResponse, error) {
+	notificationType, err := notificationMuteType(userID, notificationType)
+	if err != nil {
+		return dto.NotificationMuteResponse{}, err
+	}
+	if err := s.repo.Unmute(ctx, userID, notificationType); err != nil {
+		return dto.NotificationMuteResponse{}, err
+	}
+	return s.ListMutes(ctx, userID)
+}
+
+func notificationMuteType(userID, notificationType string) (string, error) {
+	if strings.TrimSpace(userID) == "" {
+		return "", errors.New("user id is required")
+	}
+	notificationType = strings.TrimSpace(notificationType)
+	if notificationType == "" || len(notificationType) > 64 {
+		return "", ErrNotificationTypeInvalid
+	}
+	return notificationType, nil
+}
+
+// Subscribe streams the user's notifications. The channel is closed when the
+// service drains for shutdown; during shutdown it is returned closed.
 func (s *notificationService) Subscribe(userID string) (<-chan dto.NotificationResponse, func()) {
 	channel := make(chan dto.NotificationResponse, notificationBufferSize)
 
-	s.broker.subscribe(userID, channel)
+	if !s.broker.subscribe(userID, channel) {
+		close(channel)
+		return channel, func() {}
+	}
 	observability.SSEClientsActive().Inc()
 
 	cleanup := func() {
 		s.broker.unsubscribe(userID, channel)
 		observability.SSEClientsActive().Dec()
+		s.broker.active.Done()
 	}
 
 	return channel, cleanup
 }
 
+// Shutdown stops accepting subscribers and closes every subscription so the
+// SSE streams end and clients reconnect to another instance. It waits, until
+// ctx ends, for the streams to finish writing.
+func (s *notificationService) Shutdown(ctx context.Context) error {
+	subscribers := s.broker.drain()
+
+	done := make(chan struct{})
+	go func() {
+		s.broker.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.logger.Info().Int("subscribers", subscribers).Msg("notification streams drained")
+		return nil
+	case <-ctx.Done():
+		s.logger.Warn().Int("subscribers", subscribers).Msg("notification stream drain timed out")
+		return ctx.Err()
+	}
+}
+
 func (s *notificationService) broadcast(notification dto.NotificationResponse) {
 	s.broker.broadcast(notification.UserID, notification)
 }
 
 func (s *notificationService) publish(ctx context.Context, notification dto.NotificationResponse) error {
-	event := notificationEvent{
-		Source:       s.nodeID,
-		Notification: notification,
-		SentAt:       time.Now().UTC(),
-	}
-
-	payload, err := json.Marshal(event)
+	payload, err := s.eventPayload(ctx, notification)
 	if err != nil {
 		return err
 	}
+	return s.publishPayload(ctx, payload)
+}
+
+func (s *notificationService) eventPayload(ctx context.Context, notification dto.NotificationResponse) ([]byte, error) {
+	return json.Marshal(notificationEvent{
+		Source:       s.nodeID,
+		Notification: notification,
+		SentAt:       s.clock.Now().UTC(),
+		TraceContext: injectTraceContext(ctx),
+	})
+}
 
+// publishPayload relays an encoded event to the other nodes.
+func (s *notificationService) publishPayload(ctx context.Context, payload []byte) error {
 	if s.redis != nil && s.redisStream != "" {
 		if err := s.redis.Publish(ctx, s.redisStream, payload).Err(); err != nil {
 			return err
@@ -201,7 +437,7 @@ func (s *notificationService) publish(ctx context.Context, notification dto.Noti
 	}
 
 	if s.nats != nil && s.natsSubject != "" {
-		if err := s.nats.Publish(s.natsSubject, payload); err != nil {
+		if err := s.nats.publish(ctx, s.natsSubject, payload); err != nil {
 			return err
 		}
 	}
@@ -227,31 +463,23 @@ func (s *notificationService) consumeRedis(ctx context.Context) {
 }
 
 func (s *notificationService) consumeNATS(ctx context.Context) {
-	sub, err := s.nats.QueueSubscribe(s.natsSubject, "gema-notifications", func(msg *nats.Msg) {
-		s.handleEvent(msg.Data)
-	})
-	if err != nil {
+	if err := s.nats.subscribe(ctx, s.natsSubject, "gema-notifications", s.handleEvent, s.logger); err != nil {
 		s.logger.Error().Err(err).Msg("failed to subscribe to nats notifications subject")
-		return
 	}
-
-	go func() {
-		<-ctx.Done()
-		if err := sub.Drain(); err != nil {
-			s.logger.Warn().Err(err).Msg("failed to drain notification nats subscription")
-		}
-	}()
 }
 
-func (s *notificationService) handleEvent(payload []byte) {
+// handleEvent broadcasts a relayed event to local subscribers. It only fails
+// for payloads that cannot be decoded.
+func (s *notificationService) handleEvent(payload []byte) error {
 	var event notificationEvent
 	if err := json.Unmarshal(payload, &event); err != nil {
 		s.logger.Warn().Err(err).Msg("invalid notification event payload")
-		return
+		return err
 	}
 
+	// This node broadcast its own events when it published them.
 	if event.Source == s.nodeID {
-		return
+		return nil
 	}
 
 	notification := event.Notification
@@ -259,18 +487,34 @@ func (s *notificationService) handleEvent(payload []byte) {
 		notification.Type = "generic"
 	}
 
+	_, span := startEventSpan(s.tracer, "notifications.deliver", event.TraceContext,
+		attribute.String("notification.user_id", notification.UserID),
+		attribute.String("notification.type", notification.Type),
+		attribute.String("notification.source_node", event.Source),
+	)
+	defer span.End()
+
 	observability.NotificationsPublishedTotal().WithLabelValues(notification.Type).Inc()
 	s.broadcast(notification)
+	return nil
 }
 
-func (b *notificationBroker) subscribe(userID string, ch chan dto.NotificationResponse) {
+// subscribe registers ch for userID. It reports false once the broker is
+// draining for shutdown.
+func (b *notificationBroker) subscribe(userID string, ch chan dto.NotificationResponse) bool {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	if b.draining {
+		return false
+	}
+	b.active.Add(1)
+
 	if _, exists := b.subscribers[userID]; !exists {
 		b.subscribers[userID] = make(map[chan dto.NotificationResponse]struct{})
 	}
 	b.subscribers[userID][ch] = struct{}{}
+	return true
 }
 
 func (b *notificationBroker) unsubscribe(userID string, ch chan dto.NotificationResponse) {
@@ -278,14 +522,35 @@ func (b *notificationBroker) unsubscribe(userID string, ch chan dto.Notification
 	defer b.mu.Unlock()
 
 	if subscribers, ok := b.subscribers[userID]; ok {
-		delete(subscribers, ch)
-		close(ch)
+		// drain may already have closed and removed the channel.
+		if _, subscribed := subscribers[ch]; subscribed {
+			delete(subscribers, ch)
+			close(ch)
+		}
 		if len(subscribers) == 0 {
 			delete(b.subscribers, userID)
 		}
 	}
 }
 
+// drain stops further subscriptions and closes every subscriber channel. It
+// returns how many were closed.
+func (b *notificationBroker) drain() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.draining = true
+	closed := 0
+	for userID, subscribers := range b.subscribers {
+		for ch := range subscribers {
+			close(ch)
+			closed++
+		}
+		delete(b.subscribers, userID)
+	}
+	return closed
+}
+
 func (b *notificationBroker) broadcast(userID string, notification dto.NotificationResponse) {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
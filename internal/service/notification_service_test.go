@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+func TestNotificationServicePublishUsesClock(t *testing.T) {
+	dsn := fmt.Sprintf("file:notification_clock_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Notification{}))
+
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	defer server.Close()
+
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+
+	ctx := context.Background()
+	sub := redisClient.Subscribe(ctx, "gema:notifications")
+	defer sub.Close()
+	_, err = sub.Receive(ctx)
+	require.NoError(t, err)
+
+	fixed := time.Date(2024, time.May, 2, 8, 30, 0, 0, time.UTC)
+	svc := NewNotificationService(repository.NewNotificationRepository(db), nil, redisClient, "gema", nil, validator.New(), testLogger())
+	svc.(*notificationService).clock = clock.NewFixed(fixed)
+
+	response, err := svc.Publish(ctx, dto.NotificationCreateRequest{UserID: "7", Type: "info", Message: "Grades released"})
+	require.NoError(t, err)
+	require.True(t, fixed.Equal(response.CreatedAt))
+
+	var stored models.Notification
+	require.NoError(t, db.First(&stored, response.ID).Error)
+	require.True(t, fixed.Equal(stored.CreatedAt.UTC()))
+
+	select {
+	case msg := <-sub.Channel():
+		var event notificationEvent
+		require.NoError(t, json.Unmarshal([]byte(msg.Payload), &event))
+		require.True(t, fixed.Equal(event.SentAt))
+	case <-time.After(time.Second):
+		t.Fatal("notification event was not published")
+	}
+}
+
+func TestNotificationServiceOutboxPublishesAfterCommit(t *testing.T) {
+	dsn := fmt.Sprintf("file:notification_outbox_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Notification{}, &models.NotificationMute{}, &models.OutboxEvent{}))
+
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	defer server.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer redisClient.Close()
+
+	ctx := context.Background()
+	sub := redisClient.Subscribe(ctx, "gema:notifications")
+	defer sub.Close()
+	_, err = sub.Receive(ctx)
+	require.NoError(t, err)
+
+	outbox := repository.NewOutboxRepository(db)
+	svc := NewNotificationService(repository.NewNotificationRepository(db), outbox, redisClient, "gema", nil, validator.New(), testLogger())
+	concrete := svc.(*notificationService)
+
+	response, err := svc.Publish(ctx, dto.NotificationCreateRequest{UserID: "7", Type: "info", Message: "Grades released"})
+	require.NoError(t, err)
+
+	// Nothing is published until the dispatcher runs, as after a crash.
+	var events []models.OutboxEvent
+	require.NoError(t, db.Find(&events).Error)
+	require.Len(t, events, 1)
+	require.Nil(t, events[0].SentAt)
+	select {
+	case <-sub.Channel():
+		t.Fatal("notification event was published before dispatch")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Any node's dispatcher publishes pending events, not just the writer's.
+	other := NewNotificationService(repository.NewNotificationRepository(db), outbox, redisClient, "gema", nil, validator.New(), testLogger()).(*notificationService)
+	sent, err := other.flushOutbox(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, sent)
+
+	select {
+	case msg := <-sub.Channel():
+		var event notificationEvent
+		require.NoError(t, json.Unmarshal([]byte(msg.Payload), &event))
+		require.Equal(t, response.ID, event.Notification.ID)
+		require.Equal(t, concrete.nodeID, event.Source)
+	case <-time.After(time.Second):
+		t.Fatal("outbox event was not published")
+	}
+
+	require.NoError(t, db.Find(&events).Error)
+	require.NotNil(t, events[0].SentAt)
+	sent, err = concrete.flushOutbox(ctx)
+	require.NoError(t, err)
+	require.Zero(t, sent, "sent events are not published again")
+}
+
+func TestNotificationServiceListsHighPriorityFirst(t *testing.T) {
+	dsn := fmt.Sprintf("file:notification_priority_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Notification{}, &models.NotificationMute{}))
+
+	ctx := context.Background()
+	base := time.Date(2024, time.May, 2, 8, 0, 0, 0, time.UTC)
+	fixed := clock.NewFixed(base)
+	svc := NewNotificationService(repository.NewNotificationRepository(db), nil, nil, "", nil, validator.New(), testLogger())
+	svc.(*notificationService).clock = fixed
+
+	publish := func(message, priority string, at time.Duration) dto.NotificationResponse {
+		fixed.Set(base.Add(at))
+		response, err := svc.Publish(ctx, dto.NotificationCreateRequest{UserID: "7", Type: "info", Message: message, Priority: priority})
+		require.NoError(t, err)
+		return response
+	}
+
+	oldUrgent := publish("Server maintenance", models.NotificationPriorityHigh, 0)
+	publish("Weekly digest", models.NotificationPriorityLow, time.Minute)
+	latest := publish("New reply", "", 2*time.Minute)
+	urgent := publish("Exam moved", models.NotificationPriorityHigh, 3*time.Minute)
+	require.Equal(t, models.NotificationPriorityNormal, latest.Priority)
+
+	_, err = svc.Publish(ctx, dto.NotificationCreateRequest{UserID: "7", Type: "info", Message: "Nope", Priority: "urgent"})
+	require.Error(t, err)
+
+	listed, err := svc.List(ctx, "7", 10, 0)
+	require.NoError(t, err)
+	messages := make([]string, 0, len(listed))
+	for _, item := range listed {
+		messages = append(messages, item.Message)
+	}
+	require.Equal(t, []string{urgent.Message, oldUrgent.Message, latest.Message, "Weekly digest"}, messages)
+	require.Equal(t, models.NotificationPriorityHigh, listed[0].Priority)
+}
+
+func TestNotificationServiceListAfterPagesByCursor(t *testing.T) {
+	dsn := fmt.Sprintf("file:notification_cursor_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Notification{}, &models.NotificationMute{}))
+
+	ctx := context.Background()
+	base := time.Date(2024, time.May, 2, 8, 0, 0, 0, time.UTC)
+	fixed := clock.NewFixed(base)
+	svc := NewNotificationService(repository.NewNotificationRepository(db), nil, nil, "", nil, validator.New(), testLogger())
+	svc.(*notificationService).clock = fixed
+
+	for i := 0; i < 5; i++ {
+		fixed.Set(base.Add(time.Duration(i) * time.Minute))
+		_, err := svc.Publish(ctx, dto.NotificationCreateRequest{UserID: "7", Type: "info", Message: fmt.Sprintf("message %d", i)})
+		require.NoError(t, err)
+	}
+
+	var messages []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5)
+		page, next, err := svc.ListAfter(ctx, "7", cursor, 2)
+		require.NoError(t, err)
+		for _, item := range page {
+			messages = append(messages, item.Message)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	require.Equal(t, []string{"message 4", "message 3", "message 2", "message 1", "message 0"}, messages)
+
+	_, _, err = svc.ListAfter(ctx, "7", "not-a-cursor", 2)
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestNotificationServiceHighPriorityBypassesMute(t *testing.T) {
+	dsn := fmt.Sprintf("file:notification_mute_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Notification{}, &models.NotificationMute{}))
+
+	ctx := context.Background()
+	svc := NewNotificationService(repository.NewNotificationRepository(db), nil, nil, "", nil, validator.New(), testLogger())
+
+	_, err = svc.Mute(ctx, "7", " ")
+	require.ErrorIs(t, err, ErrNotificationTypeInvalid)
+
+	mutes, err := svc.Mute(ctx, "7", "discussion")
+	require.NoError(t, err)
+	require.Equal(t, []string{"discussion"}, mutes.Types)
+	_, err = svc.Mute(ctx, "7", "discussion")
+	require.NoError(t, err)
+
+	stream, cleanup := svc.Subscribe("7")
+	defer cleanup()
+
+	_, err = svc.Publish(ctx, dto.NotificationCreateRequest{UserID: "7", Type: "discussion", Message: "New reply"})
+	require.NoError(t, err)
+	_, err = svc.PublishBulk(ctx, dto.NotificationBulkCreateRequest{UserIDs: []string{"7", "8"}, Type: "discussion", Message: "Thread closed"})
+	require.NoError(t, err)
+	critical, err := svc.Publish(ctx, dto.NotificationCreateRequest{UserID: "7", Type: "discussion", Message: "Thread removed", Priority: models.NotificationPriorityHigh})
+	require.NoError(t, err)
+
+	select {
+	case delivered := <-stream:
+		require.Equal(t, critical.ID, delivered.ID)
+	case <-time.After(time.Second):
+		t.Fatal("critical notification was not streamed")
+	}
+	require.Empty(t, stream)
+
+	listed, err := svc.List(ctx, "7", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.Equal(t, critical.ID, listed[0].ID)
+
+	others, err := svc.List(ctx, "8", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, others, 1)
+
+	mutes, err = svc.Unmute(ctx, "7", "discussion")
+	require.NoError(t, err)
+	require.Empty(t, mutes.Types)
+	listed, err = svc.List(ctx, "7", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 3)
+}
+
+func TestNotificationServiceShutdownClosesStreams(t *testing.T) {
+	svc := NewNotificationService(nil, nil, nil, "", nil, validator.New(), testLogger())
+
+	stream, cleanup := svc.Subscribe("7")
+	go func() {
+		for range stream {
+		}
+		cleanup()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, svc.Shutdown(ctx))
+
+	late, lateCleanup := svc.Subscribe("8")
+	defer lateCleanup()
+	_, open := <-late
+	require.False(t, open)
+}
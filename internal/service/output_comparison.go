@@ -0,0 +1,136 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/noah-isme/gema-go-api/internal/models"
+)
+
+const (
+	defaultNumericTolerance = 1e-6
+	maxOutputDiffLines      = 20
+)
+
+// outputComparison captures the outcome of checking program output against a task's expected output.
+type outputComparison struct {
+	Mode    string
+	Matched bool
+	Diff    string
+}
+
+// compareOutput checks actual against the task's expected output using the
+// task's comparison mode. Line endings are normalised for every mode except exact.
+func compareOutput(task models.CodingTask, actual string) outputComparison {
+	mode := task.ComparisonMode()
+	expected := task.ExpectedOutput
+
+	var matched bool
+	switch mode {
+	case models.OutputComparisonTrimmed:
+		expected, actual = trimOutput(expected), trimOutput(actual)
+		matched = expected == actual
+	case models.OutputComparisonWhitespace:
+		expected, actual = normaliseLineEndings(expected), normaliseLineEndings(actual)
+		matched = strings.Join(strings.Fields(expected), " ") == strings.Join(strings.Fields(actual), " ")
+	case models.OutputComparisonNumeric:
+		expected, actual = trimOutput(expected), trimOutput(actual)
+		tolerance := task.Tolerance
+		if tolerance <= 0 {
+			tolerance = defaultNumericTolerance
+		}
+		matched = numericTokensMatch(expected, actual, tolerance)
+	default:
+		matched = expected == actual
+	}
+
+	result := outputComparison{Mode: mode, Matched: matched}
+	if !matched {
+		result.Diff = diffOutput(expected, actual)
+	}
+	return result
+}
+
+func normaliseLineEndings(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.ReplaceAll(value, "\r", "\n")
+}
+
+// trimOutput drops trailing spaces on every line plus leading and trailing blank lines.
+func trimOutput(value string) string {
+	lines := strings.Split(normaliseLineEndings(value), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+func numericTokensMatch(expected, actual string, tolerance float64) bool {
+	expectedTokens := strings.Fields(expected)
+	actualTokens := strings.Fields(actual)
+	if len(expectedTokens) != len(actualTokens) {
+		return false
+	}
+
+	for i := range expectedTokens {
+		want, wantErr := strconv.ParseFloat(expectedTokens[i], 64)
+		got, gotErr := strconv.ParseFloat(actualTokens[i], 64)
+		if wantErr == nil && gotErr == nil {
+			if math.Abs(want-got) > tolerance {
+				return false
+			}
+			continue
+		}
+		if expectedTokens[i] != actualTokens[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// diffOutput renders a line-by-line diff of the first mismatching lines.
+func diffOutput(expected, actual string) string {
+	expectedLines := strings.Split(expected, "\n")
+	actualLines := strings.Split(actual, "\n")
+
+	total := len(expectedLines)
+	if len(actualLines) > total {
+		total = len(actualLines)
+	}
+
+	var builder strings.Builder
+	reported := 0
+	for i := 0; i < total; i++ {
+		var want, got string
+		hasWant, hasGot := i < len(expectedLines), i < len(actualLines)
+		if hasWant {
+			want = expectedLines[i]
+		}
+		if hasGot {
+			got = actualLines[i]
+		}
+		if hasWant && hasGot && want == got {
+			continue
+		}
+
+		if reported == maxOutputDiffLines {
+			builder.WriteString("...\n")
+			break
+		}
+		fmt.Fprintf(&builder, "@@ line %d @@\n", i+1)
+		if hasWant {
+			fmt.Fprintf(&builder, "- %q\n", want)
+		}
+		if hasGot {
+			fmt.Fprintf(&builder, "+ %q\n", got)
+		}
+		reported++
+	}
+
+	if builder.Len() == 0 {
+		return "outputs differ"
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
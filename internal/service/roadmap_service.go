@@ -3,46 +3,118 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
+	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/observability"
 	"github.com/noah-isme/gema-go-api/internal/repository"
 )
 
-// RoadmapService exposes read operations for roadmap stages.
+// ErrRoadmapStageNotFound indicates the roadmap stage does not exist.
+var ErrRoadmapStageNotFound = errors.New("roadmap stage not found")
+
+// RoadmapService exposes roadmap stages and students' progress through them.
 type RoadmapService interface {
 	ListStages(ctx context.Context, req dto.RoadmapStageListRequest) (dto.RoadmapStageListResult, error)
+	MarkStageComplete(ctx context.Context, studentID, stageID uint) (dto.RoadmapProgressResponse, error)
 }
 
 type roadmapService struct {
-	repo   repository.RoadmapStageRepository
-	cache  *redis.Client
-	ttl    time.Duration
-	logger zerolog.Logger
+	repo     repository.RoadmapStageRepository
+	progress repository.RoadmapProgressRepository
+	cache    cache.Store
+	ttl      time.Duration
+	logger   zerolog.Logger
+	clock    clock.Clock
 }
 
-// NewRoadmapService constructs the roadmap service.
-func NewRoadmapService(repo repository.RoadmapStageRepository, cache *redis.Client, ttl time.Duration, logger zerolog.Logger) RoadmapService {
+// NewRoadmapService constructs the roadmap service. Without a progress
+// repository stages are listed without completion status.
+func NewRoadmapService(repo repository.RoadmapStageRepository, progress repository.RoadmapProgressRepository, cache cache.Store, ttl time.Duration, logger zerolog.Logger) RoadmapService {
 	if ttl <= 0 {
 		ttl = 2 * time.Minute
 	}
 	return &roadmapService{
-		repo:   repo,
-		cache:  cache,
-		ttl:    ttl,
-		logger: logger.With().Str("component", "roadmap_service").Logger(),
+		repo:     repo,
+		progress: progress,
+		cache:    cache,
+		ttl:      ttl,
+		logger:   logger.With().Str("component", "roadmap_service").Logger(),
+		clock:    clock.Real(),
 	}
 }
 
+// ListStages returns a page of stages. Pages are cached for every caller
+// alike; a student's completion status is added afterwards.
 func (s *roadmapService) ListStages(ctx context.Context, req dto.RoadmapStageListRequest) (dto.RoadmapStageListResult, error) {
+	result, err := s.listStages(ctx, req)
+	if err != nil {
+		return dto.RoadmapStageListResult{}, err
+	}
+	if req.StudentID == 0 || s.progress == nil {
+		return result, nil
+	}
+	if err := s.applyProgress(ctx, req.StudentID, result.Items); err != nil {
+		return dto.RoadmapStageListResult{}, err
+	}
+	return result, nil
+}
+
+// MarkStageComplete records that the student completed the stage. Marking a
+// completed stage again is a no-op that returns the original completion.
+func (s *roadmapService) MarkStageComplete(ctx context.Context, studentID, stageID uint) (dto.RoadmapProgressResponse, error) {
+	if s.progress == nil {
+		return dto.RoadmapProgressResponse{}, errors.New("roadmap progress is not configured")
+	}
+	if _, err := s.repo.GetByID(ctx, stageID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.RoadmapProgressResponse{}, ErrRoadmapStageNotFound
+		}
+		return dto.RoadmapProgressResponse{}, err
+	}
+
+	progress, err := s.progress.MarkComplete(ctx, studentID, stageID, s.clock.Now())
+	if err != nil {
+		return dto.RoadmapProgressResponse{}, err
+	}
+	return dto.RoadmapProgressResponse{StageID: progress.StageID, CompletedAt: progress.CompletedAt}, nil
+}
+
+func (s *roadmapService) applyProgress(ctx context.Context, studentID uint, items []dto.RoadmapStageResponse) error {
+	stageIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		stageIDs = append(stageIDs, item.ID)
+	}
+	progress, err := s.progress.ListByStudent(ctx, studentID, stageIDs)
+	if err != nil {
+		return err
+	}
+
+	completedAt := make(map[uint]time.Time, len(progress))
+	for _, entry := range progress {
+		completedAt[entry.StageID] = entry.CompletedAt
+	}
+	for i := range items {
+		at, ok := completedAt[items[i].ID]
+		items[i].Completed = &ok
+		if ok {
+			items[i].CompletedAt = &at
+		}
+	}
+	return nil
+}
+
+func (s *roadmapService) listStages(ctx context.Context, req dto.RoadmapStageListRequest) (dto.RoadmapStageListResult, error) {
 	start := time.Now()
 	defer func() {
 		observability.RoadmapLatency().Observe(time.Since(start).Seconds())
@@ -105,7 +177,7 @@ func (s *roadmapService) fetchCache(ctx context.Context, filter repository.Roadm
 		return dto.RoadmapStageListResult{}, false
 	}
 	key := s.cacheKey(filter)
-	payload, err := s.cache.Get(ctx, key).Result()
+	payload, err := s.cache.Get(ctx, key)
 	if err != nil {
 		return dto.RoadmapStageListResult{}, false
 	}
@@ -119,16 +191,7 @@ func (s *roadmapService) fetchCache(ctx context.Context, filter repository.Roadm
 }
 
 func (s *roadmapService) writeCache(ctx context.Context, filter repository.RoadmapStageFilter, result dto.RoadmapStageListResult) {
-	if s.cache == nil {
-		return
-	}
-	key := s.cacheKey(filter)
-	payload, err := json.Marshal(result)
-	if err != nil {
-		s.logger.Warn().Err(err).Msg("failed to encode roadmap cache")
-		return
-	}
-	if err := s.cache.Set(ctx, key, payload, s.ttl).Err(); err != nil {
+	if err := cache.Write(ctx, s.cache, "roadmap", s.cacheKey(filter), result, s.ttl); err != nil {
 		s.logger.Warn().Err(err).Msg("failed to store roadmap cache")
 	}
 }
@@ -136,7 +199,7 @@ func (s *roadmapService) writeCache(ctx context.Context, filter repository.Roadm
 func (s *roadmapService) cacheKey(filter repository.RoadmapStageFilter) string {
 	tags := strings.Join(filter.Tags, ",")
 	return strings.Join([]string{
-		"roadmap:v1",
+		roadmapCachePrefix + "v1",
 		filter.Sort,
 		filter.Search,
 		tags,
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"syscall"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -38,7 +41,7 @@ func TestRoadmapServiceListStagesCachesResult(t *testing.T) {
 	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
 
 	repo := repository.NewRoadmapStageRepository(db)
-	service := NewRoadmapService(repo, redisClient, time.Minute, zerolog.Nop())
+	service := NewRoadmapService(repo, nil, cache.NewRedisStore(redisClient), time.Minute, zerolog.Nop())
 
 	req := dto.RoadmapStageListRequest{Tags: []string{"core"}, PageSize: 10}
 	result, err := service.ListStages(context.Background(), req)
@@ -50,3 +53,89 @@ func TestRoadmapServiceListStagesCachesResult(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, resultCached.CacheHit)
 }
+
+type flakyCacheStore struct {
+	cache.Store
+	failures int
+	writes   int
+}
+
+func (f *flakyCacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	f.writes++
+	if f.writes <= f.failures {
+		return syscall.ECONNRESET
+	}
+	return f.Store.Set(ctx, key, value, ttl)
+}
+
+func TestRoadmapServiceRetriesFlakyCacheWrites(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:roadmap_flaky_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.RoadmapStage{}))
+	require.NoError(t, db.Create(&models.RoadmapStage{Slug: "foundations", Title: "Foundations", Sequence: 1, Tags: []string{"core"}}).Error)
+
+	repo := repository.NewRoadmapStageRepository(db)
+	req := dto.RoadmapStageListRequest{PageSize: 10}
+	retry := cache.WriteRetry{MaxRetries: 2, BaseDelay: time.Millisecond}
+
+	flaky := &flakyCacheStore{Store: cache.NewMemoryStore(10), failures: 2}
+	service := NewRoadmapService(repo, nil, cache.WithWriteRetry(flaky, retry), time.Minute, zerolog.Nop())
+
+	result, err := service.ListStages(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, result.CacheHit)
+	require.Equal(t, 3, flaky.writes)
+
+	cached, err := service.ListStages(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, cached.CacheHit)
+
+	// A cache that never recovers still serves the request from the database.
+	broken := &flakyCacheStore{Store: cache.NewMemoryStore(10), failures: 100}
+	service = NewRoadmapService(repo, nil, cache.WithWriteRetry(broken, retry), time.Minute, zerolog.Nop())
+	for i := 0; i < 2; i++ {
+		result, err = service.ListStages(context.Background(), req)
+		require.NoError(t, err)
+		require.False(t, result.CacheHit)
+		require.Len(t, result.Items, 1)
+	}
+	require.Equal(t, 6, broken.writes)
+}
+
+func TestRoadmapServiceStudentProgress(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:roadmap_progress_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.RoadmapStage{}, &models.RoadmapProgress{}))
+	first := models.RoadmapStage{Slug: "foundations", Title: "Foundations", Sequence: 1}
+	second := models.RoadmapStage{Slug: "frontend", Title: "Frontend", Sequence: 2}
+	require.NoError(t, db.Create(&first).Error)
+	require.NoError(t, db.Create(&second).Error)
+
+	store := cache.NewMemoryStore(10)
+	service := NewRoadmapService(repository.NewRoadmapStageRepository(db), repository.NewRoadmapProgressRepository(db), store, time.Minute, zerolog.Nop())
+	ctx := context.Background()
+
+	completed, err := service.MarkStageComplete(ctx, 42, first.ID)
+	require.NoError(t, err)
+	again, err := service.MarkStageComplete(ctx, 42, first.ID)
+	require.NoError(t, err)
+	require.True(t, completed.CompletedAt.Equal(again.CompletedAt), "completing twice keeps the first completion")
+
+	_, err = service.MarkStageComplete(ctx, 42, 999)
+	require.ErrorIs(t, err, ErrRoadmapStageNotFound)
+
+	anonymous, err := service.ListStages(ctx, dto.RoadmapStageListRequest{PageSize: 10})
+	require.NoError(t, err)
+	require.Nil(t, anonymous.Items[0].Completed)
+
+	student, err := service.ListStages(ctx, dto.RoadmapStageListRequest{PageSize: 10, StudentID: 42})
+	require.NoError(t, err)
+	require.True(t, student.CacheHit, "progress is applied on top of the shared cached page")
+	require.True(t, *student.Items[0].Completed)
+	require.NotNil(t, student.Items[0].CompletedAt)
+	require.False(t, *student.Items[1].Completed)
+
+	other, err := service.ListStages(ctx, dto.RoadmapStageListRequest{PageSize: 10, StudentID: 7})
+	require.NoError(t, err)
+	require.False(t, *other.Items[0].Completed)
+}
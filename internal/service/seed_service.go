@@ -8,8 +8,11 @@ import (
 
 	"github.com/rs/zerolog"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
+	"github.com/noah-isme/gema-go-api/internal/seed"
 )
 
 var (
@@ -19,10 +22,18 @@ var (
 	ErrSeedUnauthorized = errors.New("invalid seed token")
 )
 
-// SeedService orchestrates content seeding operations.
+const (
+	seedContentAnnouncements = "announcements"
+	seedContentGallery       = "gallery"
+)
+
+// SeedService orchestrates content seeding operations. Seeding upserts by
+// slug, so repeated runs update the same rows instead of duplicating them.
+// Empty item lists fall back to the content bundled in package seed.
 type SeedService interface {
-	SeedAnnouncements(ctx context.Context, token string, items []models.Announcement) (int64, error)
-	SeedGallery(ctx context.Context, token string, items []models.GalleryItem) (int64, error)
+	SeedAnnouncements(ctx context.Context, token string, items []models.Announcement, dryRun bool) (dto.SeedResult, error)
+	SeedGallery(ctx context.Context, token string, items []models.GalleryItem, dryRun bool) (dto.SeedResult, error)
+	Status(ctx context.Context) (dto.SeedStatus, error)
 }
 
 type seedService struct {
@@ -31,6 +42,7 @@ type seedService struct {
 	enabled          bool
 	token            string
 	logger           zerolog.Logger
+	clock            clock.Clock
 }
 
 // NewSeedService constructs a seeding service.
@@ -41,39 +53,163 @@ func NewSeedService(announcementRepo repository.AnnouncementRepository, galleryR
 		enabled:          enabled,
 		token:            token,
 		logger:           logger.With().Str("component", "seed_service").Logger(),
+		clock:            clock.Real(),
 	}
 }
 
-func (s *seedService) SeedAnnouncements(ctx context.Context, token string, items []models.Announcement) (int64, error) {
-	if !s.enabled {
-		return 0, ErrSeedDisabled
+func (s *seedService) SeedAnnouncements(ctx context.Context, token string, items []models.Announcement, dryRun bool) (dto.SeedResult, error) {
+	result := dto.SeedResult{ContentType: seedContentAnnouncements, DryRun: dryRun}
+	if err := s.authorize(token); err != nil {
+		return result, err
 	}
-	if !s.validateToken(token) {
-		return 0, ErrSeedUnauthorized
+	if len(items) == 0 {
+		bundled, err := seed.Announcements()
+		if err != nil {
+			return result, err
+		}
+		items = bundled
+	}
+
+	normalized := normalizeAnnouncements(items, s.clock.Now())
+	slugs := make([]string, len(normalized))
+	for i, item := range normalized {
+		slugs[i] = item.Slug
 	}
-	normalized := normalizeAnnouncements(items)
-	affected, err := s.announcementRepo.UpsertBatch(ctx, normalized)
+	existing, err := s.announcementRepo.ExistingSlugs(ctx, slugs)
 	if err != nil {
-		return 0, err
+		return result, err
 	}
-	s.logger.Info().Int64("affected", affected).Msg("announcements seeded")
-	return affected, nil
+	result.Created, result.Updated = countSeedChanges(slugs, existing)
+
+	if !dryRun {
+		result.Affected, err = s.announcementRepo.UpsertBatch(ctx, normalized)
+		if err != nil {
+			return result, err
+		}
+	}
+	s.logResult(result)
+	return result, nil
 }
 
-func (s *seedService) SeedGallery(ctx context.Context, token string, items []models.GalleryItem) (int64, error) {
-	if !s.enabled {
-		return 0, ErrSeedDisabled
+func (s *seedService) SeedGallery(ctx context.Context, token string, items []models.GalleryItem, dryRun bool) (dto.SeedResult, error) {
+	result := dto.SeedResult{ContentType: seedContentGallery, DryRun: dryRun}
+	if err := s.authorize(token); err != nil {
+		return result, err
 	}
-	if !s.validateToken(token) {
-		return 0, ErrSeedUnauthorized
+	if len(items) == 0 {
+		bundled, err := seed.Gallery()
+		if err != nil {
+			return result, err
+		}
+		items = bundled
 	}
+
 	normalized := normalizeGallery(items)
-	affected, err := s.galleryRepo.UpsertBatch(ctx, normalized)
+	slugs := make([]string, len(normalized))
+	for i, item := range normalized {
+		slugs[i] = item.Slug
+	}
+	existing, err := s.galleryRepo.ExistingSlugs(ctx, slugs)
+	if err != nil {
+		return result, err
+	}
+	result.Created, result.Updated = countSeedChanges(slugs, existing)
+
+	if !dryRun {
+		result.Affected, err = s.galleryRepo.UpsertBatch(ctx, normalized)
+		if err != nil {
+			return result, err
+		}
+	}
+	s.logResult(result)
+	return result, nil
+}
+
+// Status reports how much of the bundled content is stored. The system counts
+// as seeded once every bundled slug exists.
+func (s *seedService) Status(ctx context.Context) (dto.SeedStatus, error) {
+	announcements, err := seed.Announcements()
+	if err != nil {
+		return dto.SeedStatus{}, err
+	}
+	gallery, err := seed.Gallery()
+	if err != nil {
+		return dto.SeedStatus{}, err
+	}
+
+	announcementSlugs := make([]string, len(announcements))
+	for i, item := range announcements {
+		announcementSlugs[i] = item.Slug
+	}
+	gallerySlugs := make([]string, len(gallery))
+	for i, item := range gallery {
+		gallerySlugs[i] = item.Slug
+	}
+
+	presentAnnouncements, err := s.announcementRepo.ExistingSlugs(ctx, announcementSlugs)
+	if err != nil {
+		return dto.SeedStatus{}, err
+	}
+	presentGallery, err := s.galleryRepo.ExistingSlugs(ctx, gallerySlugs)
 	if err != nil {
-		return 0, err
+		return dto.SeedStatus{}, err
+	}
+
+	status := dto.SeedStatus{
+		Seeded: true,
+		ContentTypes: []dto.SeedContentStatus{
+			{ContentType: seedContentAnnouncements, Expected: len(announcementSlugs), Present: len(presentAnnouncements)},
+			{ContentType: seedContentGallery, Expected: len(gallerySlugs), Present: len(presentGallery)},
+		},
+	}
+	for _, content := range status.ContentTypes {
+		if content.Present < content.Expected {
+			status.Seeded = false
+		}
+	}
+	return status, nil
+}
+
+func (s *seedService) authorize(token string) error {
+	if !s.enabled {
+		return ErrSeedDisabled
+	}
+	if !s.validateToken(token) {
+		return ErrSeedUnauthorized
+	}
+	return nil
+}
+
+func (s *seedService) logResult(result dto.SeedResult) {
+	s.logger.Info().
+		Str("content_type", result.ContentType).
+		Int("created", result.Created).
+		Int("updated", result.Updated).
+		Int64("affected", result.Affected).
+		Bool("dry_run", result.DryRun).
+		Msg("content seeded")
+}
+
+// countSeedChanges splits the distinct slugs of a batch into new ones and
+// ones already stored.
+func countSeedChanges(slugs, existing []string) (created, updated int) {
+	stored := make(map[string]struct{}, len(existing))
+	for _, slug := range existing {
+		stored[slug] = struct{}{}
+	}
+	seen := make(map[string]struct{}, len(slugs))
+	for _, slug := range slugs {
+		if _, ok := seen[slug]; ok {
+			continue
+		}
+		seen[slug] = struct{}{}
+		if _, ok := stored[slug]; ok {
+			updated++
+		} else {
+			created++
+		}
 	}
-	s.logger.Info().Int64("affected", affected).Msg("gallery seeded")
-	return affected, nil
+	return created, updated
 }
 
 func (s *seedService) validateToken(token string) bool {
@@ -95,8 +231,7 @@ func subtleConstantTimeCompare(a, b string) bool {
 	return mismatch == 0
 }
 
-func normalizeAnnouncements(items []models.Announcement) []models.Announcement {
-	now := time.Now()
+func normalizeAnnouncements(items []models.Announcement, now time.Time) []models.Announcement {
 	for i := range items {
 		if items[i].StartsAt.IsZero() {
 			items[i].StartsAt = now
@@ -8,44 +8,56 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/noah-isme/gema-go-api/internal/models"
-	"github.com/noah-isme/gema-go-api/internal/repository"
 )
 
-type seedAnnRepo struct {
-	items []models.Announcement
-}
+func TestSeedServiceTokenGuard(t *testing.T) {
+	annRepo := &announcementRepoStub{}
+	galRepo := &galleryRepoStub{}
+	svc := NewSeedService(annRepo, galRepo, true, "secret", testLogger())
 
-func (s *seedAnnRepo) ListActive(ctx context.Context, filter repository.AnnouncementFilter) ([]models.Announcement, int64, error) {
-	return nil, 0, nil
-}
+	_, err := svc.SeedAnnouncements(context.Background(), "wrong", []models.Announcement{{Title: "Test"}}, false)
+	require.ErrorIs(t, err, ErrSeedUnauthorized)
 
-func (s *seedAnnRepo) UpsertBatch(ctx context.Context, items []models.Announcement) (int64, error) {
-	s.items = items
-	return int64(len(items)), nil
+	result, err := svc.SeedAnnouncements(context.Background(), "secret", []models.Announcement{{Title: "Test", StartsAt: time.Now()}}, false)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Affected)
+	require.Equal(t, 1, result.Created)
 }
 
-type seedGalleryRepo struct {
-	items []models.GalleryItem
-}
+func TestSeedServiceBundledContentIsIdempotent(t *testing.T) {
+	annRepo := &announcementRepoStub{}
+	galRepo := &galleryRepoStub{}
+	svc := NewSeedService(annRepo, galRepo, true, "secret", testLogger())
+	ctx := context.Background()
 
-func (s *seedGalleryRepo) List(ctx context.Context, filter repository.GalleryFilter) ([]models.GalleryItem, int64, error) {
-	return nil, 0, nil
-}
+	status, err := svc.Status(ctx)
+	require.NoError(t, err)
+	require.False(t, status.Seeded)
 
-func (s *seedGalleryRepo) UpsertBatch(ctx context.Context, items []models.GalleryItem) (int64, error) {
-	s.items = items
-	return int64(len(items)), nil
-}
+	preview, err := svc.SeedGallery(ctx, "secret", nil, true)
+	require.NoError(t, err)
+	require.True(t, preview.DryRun)
+	require.Positive(t, preview.Created)
+	require.Zero(t, preview.Affected)
+	require.Empty(t, galRepo.items)
 
-func TestSeedServiceTokenGuard(t *testing.T) {
-	annRepo := &seedAnnRepo{}
-	galRepo := &seedGalleryRepo{}
-	svc := NewSeedService(annRepo, galRepo, true, "secret", testLogger())
+	first, err := svc.SeedAnnouncements(ctx, "secret", nil, false)
+	require.NoError(t, err)
+	require.Positive(t, first.Created)
+	require.Zero(t, first.Updated)
 
-	_, err := svc.SeedAnnouncements(context.Background(), "wrong", []models.Announcement{{Title: "Test"}})
-	require.ErrorIs(t, err, ErrSeedUnauthorized)
+	second, err := svc.SeedAnnouncements(ctx, "secret", nil, false)
+	require.NoError(t, err)
+	require.Zero(t, second.Created)
+	require.Equal(t, first.Created, second.Updated)
+
+	_, err = svc.SeedGallery(ctx, "secret", nil, false)
+	require.NoError(t, err)
 
-	affected, err := svc.SeedAnnouncements(context.Background(), "secret", []models.Announcement{{Title: "Test", StartsAt: time.Now()}})
+	status, err = svc.Status(ctx)
 	require.NoError(t, err)
-	require.Equal(t, int64(1), affected)
+	require.True(t, status.Seeded)
+	for _, content := range status.ContentTypes {
+		require.Equal(t, content.Expected, content.Present, content.ContentType)
+	}
 }
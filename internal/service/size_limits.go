@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const bytesPerMB int64 = 1024 * 1024
+
+// Scopes reported by SizeLimitError for the limit that was applied.
+const (
+	SizeLimitScopeAssignment = "assignment"
+	SizeLimitScopeRole       = "role"
+	SizeLimitScopeDefault    = "default"
+)
+
+// ErrAssignmentSizeLimitTooHigh indicates an assignment's size limit override
+// exceeds the largest one the server accepts.
+var ErrAssignmentSizeLimitTooHigh = errors.New("assignment size limit exceeds the server maximum")
+
+// SubmissionSizeLimits configures the maximum accepted submission size. A
+// per-assignment override wins over a per-role override, which wins over
+// DefaultMB. AssignmentMaxMB bounds the overrides assignments may set; zero
+// leaves them unbounded.
+type SubmissionSizeLimits struct {
+	DefaultMB       int
+	RoleMB          map[string]int
+	AssignmentMaxMB int
+}
+
+// SizeLimitError reports a rejected file together with the limit that applied.
+type SizeLimitError struct {
+	Size  int64
+	Limit int64
+	Scope string
+	base  error
+}
+
+func (e *SizeLimitError) Error() string {
+	return fmt.Sprintf("file is %s, exceeding the %s %s limit", formatMB(e.Size), formatMB(e.Limit), e.Scope)
+}
+
+// Unwrap lets callers match the error against the service's "too large" sentinel.
+func (e *SizeLimitError) Unwrap() error {
+	return e.base
+}
+
+// sizeLimit is the limit resolved for a single submission.
+type sizeLimit struct {
+	bytes int64
+	scope string
+}
+
+func (l SubmissionSizeLimits) resolve(assignmentMB int, role string) sizeLimit {
+	if assignmentMB > 0 {
+		return sizeLimit{bytes: int64(assignmentMB) * bytesPerMB, scope: SizeLimitScopeAssignment}
+	}
+	if mb, ok := l.RoleMB[strings.ToLower(strings.TrimSpace(role))]; ok && mb > 0 {
+		return sizeLimit{bytes: int64(mb) * bytesPerMB, scope: SizeLimitScopeRole}
+	}
+	return sizeLimit{bytes: int64(l.DefaultMB) * bytesPerMB, scope: SizeLimitScopeDefault}
+}
+
+// ceiling returns the largest size any uploader could have submitted for an
+// assignment, used when re-reading a stored archive.
+func (l SubmissionSizeLimits) ceiling(assignmentMB int) int64 {
+	limit := l.resolve(assignmentMB, "").bytes
+	if assignmentMB > 0 {
+		return limit
+	}
+	for _, mb := range l.RoleMB {
+		if bytes := int64(mb) * bytesPerMB; bytes > limit {
+			limit = bytes
+		}
+	}
+	return limit
+}
+
+// checkOverride rejects an assignment override above AssignmentMaxMB, which
+// the HTTP server's body limit would turn away before it is applied.
+func (l SubmissionSizeLimits) checkOverride(assignmentMB int) error {
+	if l.AssignmentMaxMB > 0 && assignmentMB > l.AssignmentMaxMB {
+		return fmt.Errorf("%w of %d MB", ErrAssignmentSizeLimitTooHigh, l.AssignmentMaxMB)
+	}
+	return nil
+}
+
+// check returns a SizeLimitError wrapping base when size exceeds the limit.
+func (l sizeLimit) check(size int64, base error) error {
+	if size <= l.bytes {
+		return nil
+	}
+	return &SizeLimitError{Size: size, Limit: l.bytes, Scope: l.scope, base: base}
+}
+
+func formatMB(size int64) string {
+	if size%bytesPerMB == 0 {
+		return fmt.Sprintf("%d MB", size/bytesPerMB)
+	}
+	return fmt.Sprintf("%.1f MB", float64(size)/float64(bytesPerMB))
+}
@@ -3,45 +3,108 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/logging"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/observability"
 	"github.com/noah-isme/gema-go-api/internal/repository"
 )
 
+// Supported orderings and groupings for the dashboard pending list.
+const (
+	DashboardSortDueDate     = "due_date"
+	DashboardSortDueDateDesc = "-due_date"
+	DashboardSortTitle       = "title"
+	DashboardSortUpdatedAt   = "updated_at"
+
+	DashboardGroupNone         = "none"
+	DashboardGroupOverdueFirst = "overdue_first"
+)
+
+// Bounds for the upcoming deadlines window, in days.
+const (
+	DashboardDefaultDueWithinDays = 7
+	DashboardMaxDueWithinDays     = 30
+)
+
+// ErrDashboardQueryInvalid indicates an unsupported sort or group option.
+var ErrDashboardQueryInvalid = errors.New("invalid dashboard query")
+
 // StudentDashboardService produces aggregated dashboard metrics.
 type StudentDashboardService interface {
-	GetDashboard(ctx context.Context, studentID uint) (dto.StudentDashboardResponse, bool, error)
+	GetDashboard(ctx context.Context, studentID uint, query dto.StudentDashboardQuery) (dto.StudentDashboardResponse, bool, error)
 }
 
 type studentDashboardService struct {
 	assignments repository.AssignmentRepository
 	submissions repository.SubmissionRepository
-	cache       *redis.Client
-	cacheTTL    time.Duration
+	cache       cache.Store
+	cacheTTL    TTLSource
 	logger      zerolog.Logger
-	now         func() time.Time
+	clock       clock.Clock
+}
+
+// DashboardCacheInvalidator drops a student's cached dashboard after their
+// submissions change, so the next request is rebuilt instead of waiting out
+// the cache TTL.
+type DashboardCacheInvalidator interface {
+	InvalidateStudent(ctx context.Context, studentID uint)
+}
+
+type dashboardCacheInvalidator struct {
+	cache  cache.Store
+	logger zerolog.Logger
+}
+
+// NewDashboardCacheInvalidator returns an invalidator for the dashboard cache
+// in store. A nil store yields an invalidator that does nothing.
+func NewDashboardCacheInvalidator(store cache.Store, logger zerolog.Logger) DashboardCacheInvalidator {
+	return &dashboardCacheInvalidator{
+		cache:  store,
+		logger: logger.With().Str("component", "dashboard_cache_invalidator").Logger(),
+	}
+}
+
+// InvalidateStudent deletes the default dashboard key and every sort, group
+// and deadline window variant for the student. Failures are logged; the
+// entries expire on their own TTL.
+func (i *dashboardCacheInvalidator) InvalidateStudent(ctx context.Context, studentID uint) {
+	if i.cache == nil {
+		return
+	}
+	key := dashboardCacheBaseKey(studentID)
+	if err := i.cache.Delete(ctx, key); err != nil {
+		i.logger.Warn().Err(err).Uint("student_id", studentID).Msg("failed to invalidate dashboard cache")
+		return
+	}
+	if err := i.cache.DeletePrefix(ctx, key+":"); err != nil {
+		i.logger.Warn().Err(err).Uint("student_id", studentID).Msg("failed to invalidate dashboard cache")
+	}
 }
 
 // NewStudentDashboardService builds the dashboard aggregator.
-func NewStudentDashboardService(assignments repository.AssignmentRepository, submissions repository.SubmissionRepository, cache *redis.Client, ttl time.Duration, logger zerolog.Logger) StudentDashboardService {
+func NewStudentDashboardService(assignments repository.AssignmentRepository, submissions repository.SubmissionRepository, cache cache.Store, ttl TTLSource, logger zerolog.Logger) StudentDashboardService {
 	return &studentDashboardService{
 		assignments: assignments,
 		submissions: submissions,
 		cache:       cache,
 		cacheTTL:    ttl,
 		logger:      logger.With().Str("component", "student_dashboard_service").Logger(),
-		now:         time.Now,
+		clock:       clock.Real(),
 	}
 }
 
-func (s *studentDashboardService) GetDashboard(ctx context.Context, studentID uint) (response dto.StudentDashboardResponse, cacheHit bool, err error) {
+func (s *studentDashboardService) GetDashboard(ctx context.Context, studentID uint, query dto.StudentDashboardQuery) (response dto.StudentDashboardResponse, cacheHit bool, err error) {
 	start := time.Now()
 	defer func() {
 		observability.DashboardLatency().Observe(time.Since(start).Seconds())
@@ -54,18 +117,23 @@ func (s *studentDashboardService) GetDashboard(ctx context.Context, studentID ui
 		observability.DashboardRequests().WithLabelValues(result).Inc()
 	}()
 
-	cacheKey := fmt.Sprintf("dashboard:student:%d", studentID)
+	query, err = normalizeDashboardQuery(query)
+	if err != nil {
+		return dto.StudentDashboardResponse{}, false, err
+	}
+
+	cacheKey := dashboardCacheKey(studentID, query)
 
 	if s.cache != nil {
-		if cached, err := s.cache.Get(ctx, cacheKey).Result(); err == nil {
+		if cached, err := s.cache.Get(ctx, cacheKey); err == nil {
 			var response dto.StudentDashboardResponse
 			if unmarshalErr := json.Unmarshal([]byte(cached), &response); unmarshalErr == nil {
-				s.logger.Debug().Uint("student_id", studentID).Msg("dashboard cache hit")
+				logging.FromContext(ctx, s.logger).Debug().Uint("student_id", studentID).Msg("dashboard cache hit")
 				cacheHit = true
 				return response, true, nil
 			}
-		} else if err != redis.Nil {
-			s.logger.Warn().Err(err).Msg("failed to read dashboard cache")
+		} else if !errors.Is(err, cache.ErrMiss) {
+			logging.FromContext(ctx, s.logger).Warn().Err(err).Msg("failed to read dashboard cache")
 		}
 	}
 
@@ -80,25 +148,20 @@ func (s *studentDashboardService) GetDashboard(ctx context.Context, studentID ui
 		return dto.StudentDashboardResponse{}, false, err
 	}
 
-	response := s.buildResponse(assignments, submissions)
+	response = s.buildResponse(assignments, submissions, query)
 
-	if s.cache != nil {
-		payload, err := json.Marshal(response)
-		if err == nil {
-			if err := s.cache.Set(ctx, cacheKey, payload, s.cacheTTL).Err(); err != nil {
-				s.logger.Warn().Err(err).Msg("failed to store dashboard cache")
-			}
-		}
+	if err := cache.Write(ctx, s.cache, "dashboard", cacheKey, response, s.cacheTTL()); err != nil {
+		logging.FromContext(ctx, s.logger).Warn().Err(err).Msg("failed to store dashboard cache")
 	}
 
 	return response, false, nil
 }
 
-func (s *studentDashboardService) buildResponse(assignments []models.Assignment, submissions []models.Submission) dto.StudentDashboardResponse {
-	now := s.now()
+func (s *studentDashboardService) buildResponse(assignments []models.Assignment, submissions []models.Submission, query dto.StudentDashboardQuery) dto.StudentDashboardResponse {
+	now := s.clock.Now()
 	submissionByAssignment := map[uint]models.Submission{}
 	for _, submission := range submissions {
-		if _, exists := submissionByAssignment[submission.AssignmentID]; !exists {
+		if current, exists := submissionByAssignment[submission.AssignmentID]; !exists || submission.Version > current.Version {
 			submissionByAssignment[submission.AssignmentID] = submission
 		}
 	}
@@ -107,6 +170,8 @@ func (s *studentDashboardService) buildResponse(assignments []models.Assignment,
 	progress := make([]dto.AssignmentProgress, 0, len(assignments))
 	var gradeTotal float64
 	var gradedCount int
+	var percentTotal float64
+	var percentCount int
 
 	for _, assignment := range assignments {
 		summary.TotalAssignments++
@@ -128,13 +193,20 @@ func (s *studentDashboardService) buildResponse(assignments []models.Assignment,
 			summary.Submitted++
 
 			switch submission.Status {
+			case models.SubmissionStatusLate:
+				status = models.SubmissionStatusLate
+				summary.Pending++
 			case models.SubmissionStatusGraded:
 				status = models.SubmissionStatusGraded
 				summary.Graded++
-				if submission.Grade != nil {
-					gradeTotal += *submission.Grade
+				if final := submission.PenalizedGrade(); final != nil {
+					gradeTotal += *final
 					gradedCount++
-					grade = submission.Grade
+					grade = final
+					if percent := assignment.ScorePercent(final); percent != nil {
+						percentTotal += *percent
+						percentCount++
+					}
 				}
 			default:
 				status = models.SubmissionStatusSubmitted
@@ -147,7 +219,7 @@ func (s *studentDashboardService) buildResponse(assignments []models.Assignment,
 			}
 		}
 
-		if submitted && assignmentOverdue && submission.Status != models.SubmissionStatusGraded {
+		if submitted && assignmentOverdue && !submission.Late && submission.Status != models.SubmissionStatusGraded {
 			summary.Overdue++
 		}
 
@@ -160,15 +232,20 @@ func (s *studentDashboardService) buildResponse(assignments []models.Assignment,
 			SubmissionID:  submissionID,
 			SubmissionURL: submissionURL,
 			Grade:         grade,
+			GradePercent:  assignment.ScorePercent(grade),
 			Feedback:      feedback,
 			UpdatedAt:     updatedAt,
-			Overdue:       assignmentOverdue && (status != models.SubmissionStatusGraded),
+			Overdue:       assignmentOverdue && status != models.SubmissionStatusGraded && status != models.SubmissionStatusLate,
 		})
 	}
 
 	if gradedCount > 0 {
 		summary.AverageGrade = gradeTotal / float64(gradedCount)
 	}
+	if percentCount > 0 {
+		average := models.RoundPercent(percentTotal / float64(percentCount))
+		summary.AveragePercent = &average
+	}
 
 	if summary.TotalAssignments > 0 {
 		summary.CompletionRate = (float64(summary.Graded) / float64(summary.TotalAssignments)) * 100
@@ -180,6 +257,19 @@ func (s *studentDashboardService) buildResponse(assignments []models.Assignment,
 			pendingAssignments = append(pendingAssignments, item)
 		}
 	}
+	orderPending(pendingAssignments, query)
+
+	// Upcoming deadlines only list work not yet handed in, soonest first.
+	horizon := now.AddDate(0, 0, query.DueWithinDays)
+	upcoming := make([]dto.AssignmentProgress, 0)
+	for _, item := range progress {
+		if item.Status == "pending" && item.DueDate.After(now) && !item.DueDate.After(horizon) {
+			upcoming = append(upcoming, item)
+		}
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].DueDate.Before(upcoming[j].DueDate)
+	})
 
 	activities := make([]dto.SubmissionActivity, 0, min(5, len(submissions)))
 	for idx, submission := range submissions {
@@ -192,6 +282,7 @@ func (s *studentDashboardService) buildResponse(assignments []models.Assignment,
 			AssignmentName: submission.Assignment.Title,
 			Status:         submission.Status,
 			Grade:          submission.Grade,
+			GradePercent:   submission.Assignment.ScorePercent(submission.Grade),
 			Feedback:       submission.Feedback,
 			CreatedAt:      submission.CreatedAt,
 			UpdatedAt:      submission.UpdatedAt,
@@ -201,10 +292,140 @@ func (s *studentDashboardService) buildResponse(assignments []models.Assignment,
 	return dto.StudentDashboardResponse{
 		Summary:           summary,
 		Pending:           pendingAssignments,
+		UpcomingDeadlines: upcoming,
+		Streak:            submissionStreak(submissions, now),
 		RecentSubmissions: activities,
 	}
 }
 
+// submissionStreak counts consecutive days with a submission in the clock's
+// time zone. A streak without a submission today still counts while
+// yesterday had one, so it does not reset at midnight.
+func submissionStreak(submissions []models.Submission, now time.Time) dto.SubmissionStreak {
+	loc := now.Location()
+	day := func(t time.Time) time.Time {
+		y, m, d := t.In(loc).Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+
+	days := make(map[time.Time]struct{}, len(submissions))
+	for _, submission := range submissions {
+		days[day(submission.CreatedAt)] = struct{}{}
+	}
+
+	var streak dto.SubmissionStreak
+	if len(days) == 0 {
+		return streak
+	}
+
+	cursor := day(now)
+	if _, ok := days[cursor]; !ok {
+		cursor = cursor.AddDate(0, 0, -1)
+	}
+	for {
+		if _, ok := days[cursor]; !ok {
+			break
+		}
+		streak.CurrentDays++
+		cursor = cursor.AddDate(0, 0, -1)
+	}
+
+	ordered := make([]time.Time, 0, len(days))
+	for d := range days {
+		ordered = append(ordered, d)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Before(ordered[j]) })
+
+	run := 0
+	for i, d := range ordered {
+		if i > 0 && ordered[i-1].AddDate(0, 0, 1).Equal(d) {
+			run++
+		} else {
+			run = 1
+		}
+		streak.LongestDays = max(streak.LongestDays, run)
+	}
+
+	return streak
+}
+
+// normalizeDashboardQuery applies defaults and rejects unknown options.
+func normalizeDashboardQuery(query dto.StudentDashboardQuery) (dto.StudentDashboardQuery, error) {
+	query.Sort = strings.ToLower(strings.TrimSpace(query.Sort))
+	query.Group = strings.ToLower(strings.TrimSpace(query.Group))
+
+	switch query.Sort {
+	case "":
+		query.Sort = DashboardSortDueDate
+	case DashboardSortDueDate, DashboardSortDueDateDesc, DashboardSortTitle, DashboardSortUpdatedAt:
+	default:
+		return query, fmt.Errorf("%w: unsupported sort %q", ErrDashboardQueryInvalid, query.Sort)
+	}
+
+	switch query.Group {
+	case "":
+		query.Group = DashboardGroupNone
+	case DashboardGroupNone, DashboardGroupOverdueFirst:
+	default:
+		return query, fmt.Errorf("%w: unsupported group %q", ErrDashboardQueryInvalid, query.Group)
+	}
+
+	switch {
+	case query.DueWithinDays == 0:
+		query.DueWithinDays = DashboardDefaultDueWithinDays
+	case query.DueWithinDays < 0 || query.DueWithinDays > DashboardMaxDueWithinDays:
+		return query, fmt.Errorf("%w: due_within_days must be between 1 and %d", ErrDashboardQueryInvalid, DashboardMaxDueWithinDays)
+	}
+
+	return query, nil
+}
+
+// dashboardCacheBaseKey is the student's default dashboard key; every other
+// variant extends it with a ":" suffix.
+func dashboardCacheBaseKey(studentID uint) string {
+	return fmt.Sprintf("dashboard:student:%d", studentID)
+}
+
+// dashboardCacheKey keeps the historical key for the default ordering so
+// existing cache entries stay valid, and suffixes any other ordering or
+// deadline window.
+func dashboardCacheKey(studentID uint, query dto.StudentDashboardQuery) string {
+	key := dashboardCacheBaseKey(studentID)
+	defaultWindow := query.DueWithinDays == DashboardDefaultDueWithinDays
+	if query.Sort == DashboardSortDueDate && query.Group == DashboardGroupNone && defaultWindow {
+		return key
+	}
+	key = fmt.Sprintf("%s:sort=%s:group=%s", key, query.Sort, query.Group)
+	if !defaultWindow {
+		key = fmt.Sprintf("%s:due=%d", key, query.DueWithinDays)
+	}
+	return key
+}
+
+// orderPending sorts the pending list in place. Assignments arrive ordered by
+// due date, so a stable sort keeps that as the tie-breaker.
+func orderPending(items []dto.AssignmentProgress, query dto.StudentDashboardQuery) {
+	less := func(a, b dto.AssignmentProgress) bool {
+		switch query.Sort {
+		case DashboardSortDueDateDesc:
+			return a.DueDate.After(b.DueDate)
+		case DashboardSortTitle:
+			return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+		case DashboardSortUpdatedAt:
+			return a.UpdatedAt.After(b.UpdatedAt)
+		default:
+			return a.DueDate.Before(b.DueDate)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if query.Group == DashboardGroupOverdueFirst && items[i].Overdue != items[j].Overdue {
+			return items[i].Overdue
+		}
+		return less(items[i], items[j])
+	})
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
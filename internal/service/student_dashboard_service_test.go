@@ -13,6 +13,8 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -36,7 +38,7 @@ func TestStudentDashboardServiceAggregationAndCaching(t *testing.T) {
 	now := time.Now().UTC()
 	assignments := []models.Assignment{
 		{Title: "Assignment 1", Description: "A1", DueDate: now.Add(48 * time.Hour)},
-		{Title: "Assignment 2", Description: "A2", DueDate: now.Add(24 * time.Hour)},
+		{Title: "Assignment 2", Description: "A2", DueDate: now.Add(24 * time.Hour), MaxScore: 120},
 		{Title: "Assignment 3", Description: "A3", DueDate: now.Add(-24 * time.Hour)},
 	}
 	for i := range assignments {
@@ -70,10 +72,10 @@ func TestStudentDashboardServiceAggregationAndCaching(t *testing.T) {
 	assignmentRepo := repository.NewAssignmentRepository(db)
 	submissionRepo := repository.NewSubmissionRepository(db)
 
-	svc := NewStudentDashboardService(assignmentRepo, submissionRepo, redisClient, time.Minute, zerolog.Nop())
+	svc := NewStudentDashboardService(assignmentRepo, submissionRepo, cache.NewRedisStore(redisClient), FixedTTL(time.Minute), zerolog.Nop())
 
 	ctx := context.Background()
-	first, hit, err := svc.GetDashboard(ctx, studentID)
+	first, hit, err := svc.GetDashboard(ctx, studentID, dto.StudentDashboardQuery{})
 	require.NoError(t, err)
 	require.False(t, hit)
 	require.Equal(t, 3, first.Summary.TotalAssignments)
@@ -82,14 +84,23 @@ func TestStudentDashboardServiceAggregationAndCaching(t *testing.T) {
 	require.Equal(t, 2, first.Summary.Pending)
 	require.Equal(t, 1, first.Summary.Overdue)
 	require.InDelta(t, 90.0, first.Summary.AverageGrade, 0.01)
+	require.NotNil(t, first.Summary.AveragePercent)
+	require.Equal(t, 75.0, *first.Summary.AveragePercent)
 	require.InDelta(t, 33.33, first.Summary.CompletionRate, 0.5)
 	require.Len(t, first.Pending, 2)
 	require.Len(t, first.RecentSubmissions, 2)
+	for _, activity := range first.RecentSubmissions {
+		if activity.Grade != nil {
+			require.Equal(t, 75.0, *activity.GradePercent)
+		} else {
+			require.Nil(t, activity.GradePercent)
+		}
+	}
 
 	// Modify database to ensure cached response is returned unchanged.
 	require.NoError(t, db.Model(&assignments[0]).Update("title", "Changed Title").Error)
 
-	second, hit2, err := svc.GetDashboard(ctx, studentID)
+	second, hit2, err := svc.GetDashboard(ctx, studentID, dto.StudentDashboardQuery{})
 	require.NoError(t, err)
 	require.True(t, hit2)
 	require.Equal(t, first, second)
@@ -113,7 +124,7 @@ func TestStudentDashboardCacheHit(t *testing.T) {
 	assignmentRepo := repository.NewAssignmentRepository(db)
 	submissionRepo := repository.NewSubmissionRepository(db)
 
-	svc := NewStudentDashboardService(assignmentRepo, submissionRepo, redisClient, time.Minute, zerolog.Nop())
+	svc := NewStudentDashboardService(assignmentRepo, submissionRepo, cache.NewRedisStore(redisClient), FixedTTL(time.Minute), zerolog.Nop())
 
 	studentID := uint(10)
 	ctx := context.Background()
@@ -126,8 +137,108 @@ func TestStudentDashboardCacheHit(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, redisClient.Set(ctx, "dashboard:student:10", payload, time.Minute).Err())
 
-	response, hit, err := svc.GetDashboard(ctx, studentID)
+	response, hit, err := svc.GetDashboard(ctx, studentID, dto.StudentDashboardQuery{})
 	require.NoError(t, err)
 	require.Equal(t, cached, response)
 	require.True(t, hit)
 }
+
+func TestStudentDashboardUsesLatestSubmissionVersion(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	svc := &studentDashboardService{clock: clock.NewFixed(now)}
+
+	assignments := []models.Assignment{{ID: 1, Title: "Essay", DueDate: now.Add(24 * time.Hour)}}
+	submissions := []models.Submission{
+		{ID: 10, AssignmentID: 1, Version: 1, FileURL: "https://example.com/v1", Status: models.SubmissionStatusGraded, Grade: floatPointer(70), UpdatedAt: now},
+		{ID: 11, AssignmentID: 1, Version: 2, FileURL: "https://example.com/v2", Status: models.SubmissionStatusSubmitted, UpdatedAt: now},
+	}
+
+	response := svc.buildResponse(assignments, submissions, dto.StudentDashboardQuery{Sort: DashboardSortDueDate})
+	require.Len(t, response.Pending, 1)
+	require.NotNil(t, response.Pending[0].SubmissionID)
+	require.Equal(t, uint(11), *response.Pending[0].SubmissionID)
+	require.Equal(t, "https://example.com/v2", response.Pending[0].SubmissionURL)
+	require.Equal(t, 0, response.Summary.Graded)
+}
+
+func TestStudentDashboardOverdueFirstGrouping(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	svc := &studentDashboardService{clock: clock.NewFixed(now)}
+
+	assignments := []models.Assignment{
+		{ID: 1, Title: "Upcoming soon", DueDate: now.Add(2 * time.Hour)},
+		{ID: 2, Title: "Upcoming later", DueDate: now.Add(48 * time.Hour)},
+		{ID: 3, Title: "Overdue", DueDate: now.Add(-24 * time.Hour)},
+	}
+
+	query, err := normalizeDashboardQuery(dto.StudentDashboardQuery{Group: "overdue_first", Sort: "-due_date"})
+	require.NoError(t, err)
+
+	response := svc.buildResponse(assignments, nil, query)
+	require.Len(t, response.Pending, 3)
+	require.True(t, response.Pending[0].Overdue)
+	require.Equal(t, uint(3), response.Pending[0].AssignmentID)
+	require.Equal(t, []uint{2, 1}, []uint{response.Pending[1].AssignmentID, response.Pending[2].AssignmentID})
+
+	ungroupedQuery, err := normalizeDashboardQuery(dto.StudentDashboardQuery{Sort: "-due_date"})
+	require.NoError(t, err)
+	ungrouped := svc.buildResponse(assignments, nil, ungroupedQuery)
+	require.Equal(t, uint(3), ungrouped.Pending[2].AssignmentID)
+
+	_, err = normalizeDashboardQuery(dto.StudentDashboardQuery{Sort: "priority"})
+	require.ErrorIs(t, err, ErrDashboardQueryInvalid)
+	require.Equal(t, "dashboard:student:5", dashboardCacheKey(5, mustDashboardQuery(t, dto.StudentDashboardQuery{})))
+	require.Equal(t, "dashboard:student:5:sort=-due_date:group=overdue_first", dashboardCacheKey(5, query))
+	require.Equal(t, "dashboard:student:5:sort=due_date:group=none:due=3", dashboardCacheKey(5, mustDashboardQuery(t, dto.StudentDashboardQuery{DueWithinDays: 3})))
+
+	_, err = normalizeDashboardQuery(dto.StudentDashboardQuery{DueWithinDays: 31})
+	require.ErrorIs(t, err, ErrDashboardQueryInvalid)
+}
+
+func TestStudentDashboardUpcomingDeadlines(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	svc := &studentDashboardService{clock: clock.NewFixed(now)}
+
+	assignments := []models.Assignment{
+		{ID: 1, Title: "Next week", DueDate: now.AddDate(0, 0, 6)},
+		{ID: 2, Title: "Tomorrow", DueDate: now.Add(24 * time.Hour)},
+		{ID: 3, Title: "Overdue", DueDate: now.Add(-time.Hour)},
+		{ID: 4, Title: "Next month", DueDate: now.AddDate(0, 1, 0)},
+		{ID: 5, Title: "Already submitted", DueDate: now.Add(2 * time.Hour)},
+	}
+	submissions := []models.Submission{{ID: 10, AssignmentID: 5, Version: 1, Status: models.SubmissionStatusSubmitted, CreatedAt: now}}
+
+	response := svc.buildResponse(assignments, submissions, mustDashboardQuery(t, dto.StudentDashboardQuery{}))
+	require.Len(t, response.UpcomingDeadlines, 2)
+	require.Equal(t, []uint{2, 1}, []uint{response.UpcomingDeadlines[0].AssignmentID, response.UpcomingDeadlines[1].AssignmentID})
+
+	narrow := svc.buildResponse(assignments, submissions, mustDashboardQuery(t, dto.StudentDashboardQuery{DueWithinDays: 3}))
+	require.Len(t, narrow.UpcomingDeadlines, 1)
+	require.Equal(t, uint(2), narrow.UpcomingDeadlines[0].AssignmentID)
+}
+
+func TestSubmissionStreak(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 9, 0, 0, 0, time.UTC)
+	at := func(daysAgo int) models.Submission {
+		return models.Submission{CreatedAt: now.AddDate(0, 0, -daysAgo)}
+	}
+
+	require.Equal(t, dto.SubmissionStreak{}, submissionStreak(nil, now))
+
+	// Yesterday and the two days before, plus an older four-day run.
+	submissions := []models.Submission{at(1), at(1), at(2), at(3), at(6), at(7), at(8), at(9)}
+	require.Equal(t, dto.SubmissionStreak{CurrentDays: 3, LongestDays: 4}, submissionStreak(submissions, now))
+
+	withToday := append(submissions, at(0))
+	require.Equal(t, dto.SubmissionStreak{CurrentDays: 4, LongestDays: 4}, submissionStreak(withToday, now))
+
+	lapsed := []models.Submission{at(2), at(3)}
+	require.Equal(t, dto.SubmissionStreak{CurrentDays: 0, LongestDays: 2}, submissionStreak(lapsed, now))
+}
+
+func mustDashboardQuery(t *testing.T, query dto.StudentDashboardQuery) dto.StudentDashboardQuery {
+	t.Helper()
+	normalized, err := normalizeDashboardQuery(query)
+	require.NoError(t, err)
+	return normalized
+}
@@ -1,10 +1,14 @@
 package service
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"sort"
 	"strings"
 	"time"
 
@@ -13,19 +17,56 @@ import (
 	"github.com/rs/zerolog"
 	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/logging"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
 )
 
+const (
+	similarityAnalysisTimeout = 30 * time.Second
+	similarityMaxContentBytes = 512 * 1024
+	// similarityMaxArchiveEntries bounds how many files of a ZIP submission
+	// are read for fingerprinting.
+	similarityMaxArchiveEntries = 200
+	// submissionVersionAttempts bounds how often an upload that lost a race
+	// for its version number picks a new one.
+	submissionVersionAttempts = 3
+)
+
 // ErrSubmissionNotFound indicates a submission could not be found.
 var ErrSubmissionNotFound = errors.New("submission not found")
 
+// ErrSubmissionPastDue indicates the assignment deadline passed and late work is not accepted.
+var ErrSubmissionPastDue = errors.New("assignment is past due")
+
+// ErrSubmissionTooLarge indicates the uploaded file exceeds the applicable size limit.
+var ErrSubmissionTooLarge = errors.New("submission exceeds the size limit")
+
+// ErrSubmissionGraded indicates a graded submission can no longer be withdrawn.
+var ErrSubmissionGraded = errors.New("submission has already been graded")
+
+// ErrSubmissionSuperseded indicates an older version was targeted where only the latest is allowed.
+var ErrSubmissionSuperseded = errors.New("only the latest submission version can be withdrawn")
+
 // SubmissionService orchestrates submission workflows.
 type SubmissionService interface {
-	List(ctx context.Context, filter dto.SubmissionFilter) ([]dto.SubmissionResponse, error)
+	List(ctx context.Context, filter dto.SubmissionFilter, viewerID uint, role string) ([]dto.SubmissionResponse, error)
 	Create(ctx context.Context, payload dto.SubmissionCreateRequest, file *multipart.FileHeader) (dto.SubmissionResponse, error)
 	Update(ctx context.Context, id uint, payload dto.SubmissionUpdateRequest) (dto.SubmissionResponse, error)
+	ListVersions(ctx context.Context, id, viewerID uint, role string) ([]dto.SubmissionResponse, error)
+	Withdraw(ctx context.Context, submissionID, studentID uint) error
+	Download(ctx context.Context, id, viewerID uint, role string) (dto.SubmissionDownloadResponse, error)
+}
+
+// submissionDownloadTTL bounds how long a signed submission download URL works.
+const submissionDownloadTTL = 5 * time.Minute
+
+// DownloadSigner is implemented by uploaders that can issue short-lived
+// download URLs for the files they stored.
+type DownloadSigner interface {
+	SignDownload(assetURL string, now, expiresAt time.Time) (string, error)
 }
 
 type submissionService struct {
@@ -33,31 +74,53 @@ type submissionService struct {
 	assignments repository.AssignmentRepository
 	validator   *validator.Validate
 	uploader    FileUploader
+	similarity  SubmissionSimilarityService
+	fileTypes   mimeAllowList
+	sizeLimits  SubmissionSizeLimits
+	activity    ActivityRecorder
+	dashboard   DashboardCacheInvalidator
 	logger      zerolog.Logger
-	now         func() time.Time
+	clock       clock.Clock
 }
 
-// NewSubmissionService constructs a SubmissionService instance.
-func NewSubmissionService(subRepo repository.SubmissionRepository, assignmentRepo repository.AssignmentRepository, validate *validator.Validate, uploader FileUploader, logger zerolog.Logger) SubmissionService {
+// NewSubmissionService constructs a SubmissionService instance. An empty
+// allowedMimeTypes falls back to DefaultSubmissionMimeTypes and a zero
+// limits.DefaultMB to 10 MB. A nil dashboard skips dashboard cache
+// invalidation.
+func NewSubmissionService(subRepo repository.SubmissionRepository, assignmentRepo repository.AssignmentRepository, validate *validator.Validate, uploader FileUploader, similarity SubmissionSimilarityService, allowedMimeTypes []string, limits SubmissionSizeLimits, activity ActivityRecorder, dashboard DashboardCacheInvalidator, logger zerolog.Logger) SubmissionService {
+	if limits.DefaultMB <= 0 {
+		limits.DefaultMB = 10
+	}
 	return &submissionService{
 		submissions: subRepo,
 		assignments: assignmentRepo,
 		validator:   validate,
 		uploader:    uploader,
+		similarity:  similarity,
+		fileTypes:   newMimeAllowList(allowedMimeTypes, DefaultSubmissionMimeTypes),
+		sizeLimits:  limits,
+		activity:    activity,
+		dashboard:   dashboard,
 		logger:      logger.With().Str("component", "submission_service").Logger(),
-		now:         time.Now,
+		clock:       clock.Real(),
 	}
 }
 
-func (s *submissionService) List(ctx context.Context, filter dto.SubmissionFilter) ([]dto.SubmissionResponse, error) {
+// List returns the latest version of each matching submission. Students only
+// see their own submissions; staff may filter across students.
+func (s *submissionService) List(ctx context.Context, filter dto.SubmissionFilter, viewerID uint, role string) ([]dto.SubmissionResponse, error) {
 	if err := s.validator.Struct(filter); err != nil {
 		return nil, err
 	}
+	if !IsStaffRole(role) {
+		filter.StudentID = &viewerID
+	}
 
 	repoFilter := repository.SubmissionFilter{
 		AssignmentID: filter.AssignmentID,
 		StudentID:    filter.StudentID,
 		Status:       filter.Status,
+		LatestOnly:   true,
 	}
 
 	submissions, err := s.submissions.List(ctx, repoFilter)
@@ -65,7 +128,7 @@ func (s *submissionService) List(ctx context.Context, filter dto.SubmissionFilte
 		return nil, err
 	}
 
-	return dto.NewSubmissionResponseSlice(submissions), nil
+	return visibleSubmissions(submissions, viewerID), nil
 }
 
 func (s *submissionService) Create(ctx context.Context, payload dto.SubmissionCreateRequest, file *multipart.FileHeader) (dto.SubmissionResponse, error) {
@@ -85,14 +148,22 @@ func (s *submissionService) Create(ctx context.Context, payload dto.SubmissionCr
 		return dto.SubmissionResponse{}, err
 	}
 
-	if assignment.IsPastDue(s.now()) {
-		return dto.SubmissionResponse{}, fmt.Errorf("assignment is past due")
+	late := assignment.IsPastDue(s.clock.Now())
+	if late && !assignment.AllowLate {
+		return dto.SubmissionResponse{}, ErrSubmissionPastDue
+	}
+
+	limit := s.sizeLimits.resolve(assignment.MaxSubmissionMB, payload.Role)
+	if err := limit.check(file.Size, ErrSubmissionTooLarge); err != nil {
+		return dto.SubmissionResponse{}, err
 	}
 
-	if err := validateFileType(file); err != nil {
+	if err := s.validateFileType(file); err != nil {
 		return dto.SubmissionResponse{}, err
 	}
 
+	content := readSimilarityContent(file)
+
 	reader, err := file.Open()
 	if err != nil {
 		return dto.SubmissionResponse{}, fmt.Errorf("failed to open file: %w", err)
@@ -110,8 +181,13 @@ func (s *submissionService) Create(ctx context.Context, payload dto.SubmissionCr
 		FileURL:      uploadURL,
 		Status:       models.SubmissionStatusSubmitted,
 	}
+	if late {
+		submission.Status = models.SubmissionStatusLate
+		submission.Late = true
+		submission.LatePenaltyPercent = assignment.LatePenalty()
+	}
 
-	if err := s.submissions.Create(ctx, &submission); err != nil {
+	if err := s.createVersion(ctx, &submission); err != nil {
 		return dto.SubmissionResponse{}, err
 	}
 
@@ -121,11 +197,40 @@ func (s *submissionService) Create(ctx context.Context, payload dto.SubmissionCr
 		return dto.SubmissionResponse{}, err
 	}
 
-	s.logger.Info().Uint("submission_id", created.ID).Msg("submission created")
+	logging.FromContext(ctx, s.logger).Info().Uint("submission_id", created.ID).Int("version", created.Version).Msg("submission created")
+	s.invalidateDashboard(ctx, created.StudentID)
+
+	if s.similarity != nil && len(content) > 0 {
+		// fiber recycles the request context once the response is sent, so the
+		// analysis runs on a fresh one carrying only the correlation ID.
+		background := logging.WithCorrelationID(context.Background(), logging.CorrelationID(ctx))
+		go s.analyzeSimilarity(background, created, content)
+	}
 
 	return dto.NewSubmissionResponse(created), nil
 }
 
+// createVersion stores submission as the student's next version for the
+// assignment. Two uploads racing for the same number are settled by the
+// unique version index: the loser reads the new latest version and retries.
+func (s *submissionService) createVersion(ctx context.Context, submission *models.Submission) error {
+	for attempt := 1; ; attempt++ {
+		submission.Version = 1
+		previous, err := s.submissions.GetByAssignmentAndStudent(ctx, submission.AssignmentID, submission.StudentID)
+		switch {
+		case err == nil:
+			submission.Version = max(previous.Version, 1) + 1
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		err = s.submissions.Create(ctx, submission)
+		if !errors.Is(err, repository.ErrSubmissionVersionTaken) || attempt == submissionVersionAttempts {
+			return err
+		}
+	}
+}
+
 func (s *submissionService) Update(ctx context.Context, id uint, payload dto.SubmissionUpdateRequest) (dto.SubmissionResponse, error) {
 	if err := s.validator.Struct(payload); err != nil {
 		return dto.SubmissionResponse{}, err
@@ -167,29 +272,267 @@ func (s *submissionService) Update(ctx context.Context, id uint, payload dto.Sub
 		return dto.SubmissionResponse{}, err
 	}
 
-	s.logger.Info().Uint("submission_id", submission.ID).Msg("submission updated")
+	logging.FromContext(ctx, s.logger).Info().Uint("submission_id", submission.ID).Msg("submission updated")
+	s.invalidateDashboard(ctx, updated.StudentID)
 
 	return dto.NewSubmissionResponse(updated), nil
 }
 
-func validateFileType(file *multipart.FileHeader) error {
+// ListVersions returns every live version of the submission's student and
+// assignment, newest first, when the viewer owns it or is staff.
+func (s *submissionService) ListVersions(ctx context.Context, id, viewerID uint, role string) ([]dto.SubmissionResponse, error) {
+	submission, err := s.submissions.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSubmissionNotFound
+		}
+		return nil, err
+	}
+	if !canViewSubmission(viewerID, role, submission) {
+		return nil, ErrSubmissionNotFound
+	}
+
+	versions, err := s.submissions.ListVersions(ctx, submission.AssignmentID, submission.StudentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return visibleSubmissions(versions, viewerID), nil
+}
+
+// Download returns a URL for the submission file when the viewer owns the
+// submission or is a teacher or admin. Anyone else gets ErrSubmissionNotFound,
+// so submission IDs cannot be probed. When the uploader can sign downloads the
+// URL expires after submissionDownloadTTL.
+func (s *submissionService) Download(ctx context.Context, id, viewerID uint, role string) (dto.SubmissionDownloadResponse, error) {
+	submission, err := s.submissions.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.SubmissionDownloadResponse{}, ErrSubmissionNotFound
+		}
+		return dto.SubmissionDownloadResponse{}, err
+	}
+	if !canViewSubmission(viewerID, role, submission) || submission.FileURL == "" {
+		return dto.SubmissionDownloadResponse{}, ErrSubmissionNotFound
+	}
+
+	signer, ok := s.uploader.(DownloadSigner)
+	if !ok {
+		return dto.SubmissionDownloadResponse{URL: submission.FileURL}, nil
+	}
+
+	now := s.clock.Now()
+	expiresAt := now.Add(submissionDownloadTTL)
+	signed, err := signer.SignDownload(submission.FileURL, now, expiresAt)
+	if err != nil {
+		// Files stored before the current uploader cannot be signed.
+		s.logger.Warn().Err(err).Uint("submission_id", id).Msg("submission file not signable; returning stored url")
+		return dto.SubmissionDownloadResponse{URL: submission.FileURL}, nil
+	}
+	return dto.SubmissionDownloadResponse{URL: signed, ExpiresAt: &expiresAt}, nil
+}
+
+func canViewSubmission(viewerID uint, role string, submission models.Submission) bool {
+	if viewerID != 0 && viewerID == submission.StudentID {
+		return true
+	}
+	return IsStaffRole(role)
+}
+
+// visibleSubmissions converts submissions to responses as viewerID may see
+// them.
+func visibleSubmissions(submissions []models.Submission, viewerID uint) []dto.SubmissionResponse {
+	responses := dto.NewSubmissionResponseSlice(submissions)
+	for i := range responses {
+		responses[i] = responses[i].VisibleTo(viewerID)
+	}
+	return responses
+}
+
+// Withdraw lets a student retract their latest ungraded submission before the
+// assignment is due. The version is soft-deleted, so the previous version (if
+// any) becomes current again and the next upload reuses its version number.
+func (s *submissionService) Withdraw(ctx context.Context, submissionID, studentID uint) error {
+	submission, err := s.submissions.GetByID(ctx, submissionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSubmissionNotFound
+		}
+		return err
+	}
+
+	// Other students' submissions are reported as missing rather than forbidden.
+	if submission.StudentID != studentID {
+		return ErrSubmissionNotFound
+	}
+	if submission.IsGraded() || submission.Grade != nil {
+		return ErrSubmissionGraded
+	}
+	if submission.Assignment.IsPastDue(s.clock.Now()) {
+		return ErrSubmissionPastDue
+	}
+
+	latest, err := s.submissions.GetByAssignmentAndStudent(ctx, submission.AssignmentID, studentID)
+	if err != nil {
+		return err
+	}
+	if latest.ID != submission.ID {
+		return ErrSubmissionSuperseded
+	}
+
+	if err := s.submissions.Delete(ctx, submission.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSubmissionNotFound
+		}
+		return err
+	}
+
+	logging.FromContext(ctx, s.logger).Info().Uint("submission_id", submission.ID).Int("version", submission.Version).Msg("submission withdrawn")
+	s.invalidateDashboard(ctx, studentID)
+
+	if s.activity != nil {
+		_, _ = s.activity.Record(ctx, ActivityEntry{
+			ActorID:    studentID,
+			ActorRole:  "student",
+			Action:     "submission.withdrawn",
+			EntityType: "submission",
+			EntityID:   &submission.ID,
+			Metadata: map[string]interface{}{
+				"assignment_id": submission.AssignmentID,
+				"version":       submission.Version,
+			},
+		})
+	}
+
+	return nil
+}
+
+// invalidateDashboard clears the student's cached dashboard after one of
+// their submissions changed.
+func (s *submissionService) invalidateDashboard(ctx context.Context, studentID uint) {
+	if s.dashboard != nil {
+		s.dashboard.InvalidateStudent(ctx, studentID)
+	}
+}
+
+func (s *submissionService) analyzeSimilarity(ctx context.Context, submission models.Submission, content []byte) {
+	ctx, cancel := context.WithTimeout(ctx, similarityAnalysisTimeout)
+	defer cancel()
+
+	if err := s.similarity.Analyze(ctx, submission, content); err != nil {
+		logging.FromContext(ctx, s.logger).Warn().Err(err).Uint("submission_id", submission.ID).Msg("similarity analysis failed")
+	}
+}
+
+// readSimilarityContent returns the text to fingerprint for an upload: the
+// body of a plain-text file, or the text files inside a ZIP archive. PDFs and
+// binary files are not fingerprinted, so their similarity reports stay
+// unavailable.
+func readSimilarityContent(file *multipart.FileHeader) []byte {
+	if file.Size <= 0 {
+		return nil
+	}
+
 	reader, err := file.Open()
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return nil
 	}
 	defer reader.Close()
 
 	mime, err := mimetype.DetectReader(reader)
 	if err != nil {
-		return fmt.Errorf("failed to detect file type: %w", err)
+		return nil
+	}
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil
 	}
 
-	allowed := []string{"application/pdf", "application/zip", "application/x-zip-compressed", "text/plain"}
-	for _, a := range allowed {
-		if mime.Is(a) {
+	switch {
+	case mime.Is("text/plain"):
+		if file.Size > similarityMaxContentBytes {
 			return nil
 		}
+		content, err := io.ReadAll(io.LimitReader(reader, similarityMaxContentBytes))
+		if err != nil {
+			return nil
+		}
+		return content
+	case mime.Is("application/zip"):
+		return readArchiveText(reader, file.Size)
+	default:
+		return nil
+	}
+}
+
+// readArchiveText concatenates the text files of a ZIP archive in name order,
+// up to similarityMaxContentBytes in total. Entries that are not text, or too
+// large to fingerprint on their own, are skipped.
+func readArchiveText(reader io.ReaderAt, size int64) []byte {
+	archive, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil
+	}
+
+	entries := make([]*zip.File, 0, len(archive.File))
+	for _, entry := range archive.File {
+		if !entry.FileInfo().IsDir() && entry.UncompressedSize64 <= similarityMaxContentBytes {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	if len(entries) > similarityMaxArchiveEntries {
+		entries = entries[:similarityMaxArchiveEntries]
+	}
+
+	var content bytes.Buffer
+	for _, entry := range entries {
+		remaining := similarityMaxContentBytes - content.Len()
+		if remaining <= 0 {
+			break
+		}
+		body, err := readArchiveEntry(entry, int64(remaining))
+		if err != nil || !isTextMime(mimetype.Detect(body)) {
+			continue
+		}
+		content.Write(body)
+		content.WriteByte('\n')
+	}
+	return content.Bytes()
+}
+
+func readArchiveEntry(entry *zip.File, limit int64) ([]byte, error) {
+	body, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	// The header's size is not trusted; the limit also bounds what a forged
+	// entry can decompress to.
+	return io.ReadAll(io.LimitReader(body, limit))
+}
+
+// isTextMime reports whether mime is plain text or a text format derived from
+// it, such as source code or HTML.
+func isTextMime(mime *mimetype.MIME) bool {
+	for ; mime != nil; mime = mime.Parent() {
+		if mime.Is("text/plain") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *submissionService) validateFileType(file *multipart.FileHeader) error {
+	reader, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer reader.Close()
+
+	mime, err := mimetype.DetectReader(reader)
+	if err != nil {
+		return fmt.Errorf("failed to detect file type: %w", err)
 	}
 
-	return fmt.Errorf("unsupported file type: %s", mime.String())
+	return s.fileTypes.check(mime.String())
 }
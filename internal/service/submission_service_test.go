@@ -0,0 +1,281 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/cache"
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+type withdrawFixture struct {
+	db         *gorm.DB
+	svc        SubmissionService
+	dashboard  StudentDashboardService
+	cache      *cache.MemoryStore
+	activity   *stubActivityRecorder
+	now        time.Time
+	assignment models.Assignment
+	student    models.Student
+}
+
+func setupSubmissionWithdraw(t *testing.T) withdrawFixture {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:submission_withdraw_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Student{}, &models.Assignment{}, &models.Submission{}))
+
+	now := time.Date(2024, time.May, 6, 9, 0, 0, 0, time.UTC)
+	student := models.Student{Name: "Jane", Email: "jane@example.com"}
+	require.NoError(t, db.Create(&student).Error)
+	assignment := models.Assignment{Title: "Lab Report", DueDate: now.Add(48 * time.Hour)}
+	require.NoError(t, db.Create(&assignment).Error)
+
+	submissionRepo := repository.NewSubmissionRepository(db)
+	assignmentRepo := repository.NewAssignmentRepository(db)
+	activity := &stubActivityRecorder{}
+	store := cache.NewMemoryStore(100)
+	svc := NewSubmissionService(submissionRepo, assignmentRepo, validator.New(validator.WithRequiredStructEnabled()), nil, nil, nil, SubmissionSizeLimits{}, activity, NewDashboardCacheInvalidator(store, testLogger()), testLogger())
+	svc.(*submissionService).clock = clock.NewFixed(now)
+
+	return withdrawFixture{
+		db:         db,
+		svc:        svc,
+		dashboard:  NewStudentDashboardService(assignmentRepo, submissionRepo, store, FixedTTL(time.Minute), testLogger()),
+		cache:      store,
+		activity:   activity,
+		now:        now,
+		assignment: assignment,
+		student:    student,
+	}
+}
+
+func (f withdrawFixture) submit(t *testing.T, version int) models.Submission {
+	t.Helper()
+	submission := models.Submission{
+		AssignmentID: f.assignment.ID,
+		StudentID:    f.student.ID,
+		Version:      version,
+		FileURL:      fmt.Sprintf("https://files.test/v%d.zip", version),
+		Status:       models.SubmissionStatusSubmitted,
+	}
+	require.NoError(t, f.db.Create(&submission).Error)
+	return submission
+}
+
+func TestSubmissionServiceWithdraw(t *testing.T) {
+	f := setupSubmissionWithdraw(t)
+	ctx := context.Background()
+	submission := f.submit(t, 1)
+
+	require.NoError(t, f.svc.Withdraw(ctx, submission.ID, f.student.ID))
+
+	require.Len(t, f.activity.entries, 1)
+	require.Equal(t, "submission.withdrawn", f.activity.entries[0].Action)
+	require.Equal(t, submission.ID, *f.activity.entries[0].EntityID)
+
+	var count int64
+	require.NoError(t, f.db.Model(&models.Submission{}).Count(&count).Error)
+	require.Zero(t, count)
+	require.NoError(t, f.db.Unscoped().Model(&models.Submission{}).Count(&count).Error)
+	require.EqualValues(t, 1, count)
+
+	dashboard, _, err := f.dashboard.GetDashboard(ctx, f.student.ID, dto.StudentDashboardQuery{})
+	require.NoError(t, err)
+	require.Zero(t, dashboard.Summary.Submitted)
+	require.Len(t, dashboard.Pending, 1)
+	require.Equal(t, "pending", dashboard.Pending[0].Status)
+	require.Nil(t, dashboard.Pending[0].SubmissionID)
+	require.Empty(t, dashboard.RecentSubmissions)
+
+	require.ErrorIs(t, f.svc.Withdraw(ctx, submission.ID, f.student.ID), ErrSubmissionNotFound)
+}
+
+func TestSubmissionServiceWithdrawRestoresPreviousVersion(t *testing.T) {
+	f := setupSubmissionWithdraw(t)
+	ctx := context.Background()
+	first := f.submit(t, 1)
+	second := f.submit(t, 2)
+
+	require.ErrorIs(t, f.svc.Withdraw(ctx, first.ID, f.student.ID), ErrSubmissionSuperseded)
+	require.ErrorIs(t, f.svc.Withdraw(ctx, second.ID, f.student.ID+1), ErrSubmissionNotFound)
+	require.NoError(t, f.svc.Withdraw(ctx, second.ID, f.student.ID))
+
+	listed, err := f.svc.List(ctx, dto.SubmissionFilter{StudentID: &f.student.ID}, f.student.ID, "student")
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.Equal(t, first.ID, listed[0].ID)
+	require.Equal(t, 1, listed[0].Version)
+}
+
+func TestSubmissionServiceWithdrawRejectsGradedOrPastDue(t *testing.T) {
+	f := setupSubmissionWithdraw(t)
+	ctx := context.Background()
+
+	graded := f.submit(t, 1)
+	grade := 80.0
+	require.NoError(t, f.db.Model(&graded).Updates(map[string]interface{}{"status": models.SubmissionStatusGraded, "grade": grade}).Error)
+	require.ErrorIs(t, f.svc.Withdraw(ctx, graded.ID, f.student.ID), ErrSubmissionGraded)
+
+	pastDue := models.Assignment{Title: "Closed", DueDate: f.now.Add(-time.Hour)}
+	require.NoError(t, f.db.Create(&pastDue).Error)
+	late := models.Submission{AssignmentID: pastDue.ID, StudentID: f.student.ID, Version: 1, Status: models.SubmissionStatusSubmitted}
+	require.NoError(t, f.db.Create(&late).Error)
+	require.ErrorIs(t, f.svc.Withdraw(ctx, late.ID, f.student.ID), ErrSubmissionPastDue)
+
+	require.Empty(t, f.activity.entries)
+}
+
+func TestSubmissionServiceUpdateInvalidatesDashboardCache(t *testing.T) {
+	f := setupSubmissionWithdraw(t)
+	ctx := context.Background()
+	submission := f.submit(t, 1)
+
+	byTitle := dto.StudentDashboardQuery{Sort: DashboardSortTitle}
+	_, _, err := f.dashboard.GetDashboard(ctx, f.student.ID, dto.StudentDashboardQuery{})
+	require.NoError(t, err)
+	_, _, err = f.dashboard.GetDashboard(ctx, f.student.ID, byTitle)
+	require.NoError(t, err)
+
+	defaultKey := fmt.Sprintf("dashboard:student:%d", f.student.ID)
+	_, err = f.cache.Get(ctx, defaultKey)
+	require.NoError(t, err)
+
+	otherKey := fmt.Sprintf("dashboard:student:%d", f.student.ID+10)
+	require.NoError(t, f.cache.Set(ctx, otherKey, []byte("{}"), time.Minute))
+
+	grade := 88.0
+	_, err = f.svc.Update(ctx, submission.ID, dto.SubmissionUpdateRequest{Grade: &grade})
+	require.NoError(t, err)
+
+	_, err = f.cache.Get(ctx, defaultKey)
+	require.ErrorIs(t, err, cache.ErrMiss)
+	_, err = f.cache.Get(ctx, dashboardCacheKey(f.student.ID, mustNormalizeDashboardQuery(t, byTitle)))
+	require.ErrorIs(t, err, cache.ErrMiss)
+	_, err = f.cache.Get(ctx, otherKey)
+	require.NoError(t, err)
+
+	dashboard, hit, err := f.dashboard.GetDashboard(ctx, f.student.ID, dto.StudentDashboardQuery{})
+	require.NoError(t, err)
+	require.False(t, hit)
+	require.Equal(t, 1, dashboard.Summary.Graded)
+}
+
+type signingUploader struct{}
+
+func (signingUploader) Upload(context.Context, string, io.Reader) (string, error) {
+	return "", nil
+}
+
+func (signingUploader) SignDownload(assetURL string, _, expiresAt time.Time) (string, error) {
+	return fmt.Sprintf("%s?expires=%d", assetURL, expiresAt.Unix()), nil
+}
+
+func TestSubmissionServiceDownloadChecksOwnership(t *testing.T) {
+	f := setupSubmissionWithdraw(t)
+	submission := f.submit(t, 1)
+	ctx := context.Background()
+
+	// Without a signing uploader the stored URL is returned as is.
+	download, err := f.svc.Download(ctx, submission.ID, f.student.ID, "student")
+	require.NoError(t, err)
+	require.Equal(t, submission.FileURL, download.URL)
+	require.Nil(t, download.ExpiresAt)
+
+	f.svc.(*submissionService).uploader = signingUploader{}
+	download, err = f.svc.Download(ctx, submission.ID, 0, "teacher")
+	require.NoError(t, err)
+	require.NotNil(t, download.ExpiresAt)
+	require.Equal(t, f.now.Add(submissionDownloadTTL), *download.ExpiresAt)
+	require.Equal(t, fmt.Sprintf("%s?expires=%d", submission.FileURL, download.ExpiresAt.Unix()), download.URL)
+
+	// Another student cannot tell the submission apart from a missing one.
+	_, err = f.svc.Download(ctx, submission.ID, f.student.ID+1, "student")
+	require.ErrorIs(t, err, ErrSubmissionNotFound)
+	_, err = f.svc.Download(ctx, submission.ID+100, f.student.ID, "student")
+	require.ErrorIs(t, err, ErrSubmissionNotFound)
+}
+
+func mustNormalizeDashboardQuery(t *testing.T, query dto.StudentDashboardQuery) dto.StudentDashboardQuery {
+	t.Helper()
+	query, err := normalizeDashboardQuery(query)
+	require.NoError(t, err)
+	return query
+}
+
+// racingSubmissionRepo stores a competing upload just before the first
+// Create, as if another request had computed the same version number.
+type racingSubmissionRepo struct {
+	repository.SubmissionRepository
+	raced bool
+}
+
+func (r *racingSubmissionRepo) Create(ctx context.Context, submission *models.Submission) error {
+	if !r.raced {
+		r.raced = true
+		rival := *submission
+		rival.FileURL = "https://files.test/rival.zip"
+		if err := r.SubmissionRepository.Create(ctx, &rival); err != nil {
+			return err
+		}
+	}
+	return r.SubmissionRepository.Create(ctx, submission)
+}
+
+func TestSubmissionServiceCreateRetriesTakenVersion(t *testing.T) {
+	f := setupSubmissionWithdraw(t)
+	require.NoError(t, repository.EnsureSubmissionVersionIndex(context.Background(), f.db))
+	f.submit(t, 1)
+
+	svc := f.svc.(*submissionService)
+	repo := &racingSubmissionRepo{SubmissionRepository: svc.submissions}
+	svc.submissions = repo
+
+	submission := models.Submission{
+		AssignmentID: f.assignment.ID,
+		StudentID:    f.student.ID,
+		FileURL:      "https://files.test/mine.zip",
+		Status:       models.SubmissionStatusSubmitted,
+	}
+	require.NoError(t, svc.createVersion(context.Background(), &submission))
+	require.True(t, repo.raced)
+	require.Equal(t, 3, submission.Version, "the rival took version 2")
+}
+
+func TestReadSimilarityContentExtractsArchiveText(t *testing.T) {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for name, body := range map[string][]byte{
+		"src/b.py":   []byte("print('second file')\n"),
+		"src/a.txt":  []byte("first file\n"),
+		"img/logo":   {0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0, 0, 0x0d},
+		"empty-dir/": nil,
+	} {
+		entry, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = entry.Write(body)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	content := readSimilarityContent(newTestFileHeader(t, "project.zip", archive.Bytes()))
+	require.Equal(t, "first file\n\nprint('second file')\n\n", string(content))
+
+	require.Equal(t, "plain essay", string(readSimilarityContent(newTestFileHeader(t, "essay.txt", []byte("plain essay")))))
+	require.Nil(t, readSimilarityContent(newTestFileHeader(t, "essay.pdf", []byte("%PDF-1.4\n%binary"))), "PDFs are not fingerprinted")
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+const (
+	similarityShingleSize    = 5
+	similaritySketchSize     = 256
+	similarityCandidateLimit = 200
+	// DefaultSimilarityThreshold is the minimum score reported as a match when callers do not override it.
+	DefaultSimilarityThreshold = 0.5
+)
+
+// ErrSimilarityUnavailable indicates the submission has no fingerprint, e.g. a binary upload or pending analysis.
+var ErrSimilarityUnavailable = errors.New("similarity data unavailable for submission")
+
+// SubmissionSimilarityService fingerprints submission content and ranks similar uploads.
+type SubmissionSimilarityService interface {
+	Analyze(ctx context.Context, submission models.Submission, content []byte) error
+	Matches(ctx context.Context, submissionID uint, threshold float64) (dto.SubmissionSimilarityResponse, error)
+}
+
+type submissionSimilarityService struct {
+	repo   repository.SubmissionFingerprintRepository
+	logger zerolog.Logger
+}
+
+// NewSubmissionSimilarityService constructs the similarity checker.
+func NewSubmissionSimilarityService(repo repository.SubmissionFingerprintRepository, logger zerolog.Logger) SubmissionSimilarityService {
+	return &submissionSimilarityService{
+		repo:   repo,
+		logger: logger.With().Str("component", "submission_similarity_service").Logger(),
+	}
+}
+
+// Analyze stores the fingerprint for the submission and compares it against other
+// submissions for the same assignment, raising the similarity score on both sides.
+func (s *submissionSimilarityService) Analyze(ctx context.Context, submission models.Submission, content []byte) error {
+	hashes := fingerprintContent(content)
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	fingerprint := models.SubmissionFingerprint{
+		SubmissionID: submission.ID,
+		AssignmentID: submission.AssignmentID,
+		StudentID:    submission.StudentID,
+		Hashes:       encodeFingerprint(hashes),
+		ShingleCount: len(hashes),
+	}
+	if err := s.repo.Save(ctx, &fingerprint); err != nil {
+		return err
+	}
+
+	candidates, err := s.repo.ListByAssignment(ctx, submission.AssignmentID, submission.ID, similarityCandidateLimit)
+	if err != nil {
+		return err
+	}
+
+	var bestScore float64
+	var bestMatch uint
+	for _, candidate := range candidates {
+		if candidate.StudentID == submission.StudentID {
+			continue
+		}
+		score := sketchSimilarity(hashes, decodeFingerprint(candidate.Hashes))
+		if score <= 0 {
+			continue
+		}
+		if err := s.repo.RaiseSimilarity(ctx, candidate.SubmissionID, score, submission.ID); err != nil {
+			s.logger.Warn().Err(err).Uint("submission_id", candidate.SubmissionID).Msg("failed to update similarity for candidate")
+		}
+		if score > bestScore {
+			bestScore = score
+			bestMatch = candidate.SubmissionID
+		}
+	}
+
+	if bestMatch == 0 {
+		return nil
+	}
+
+	return s.repo.RaiseSimilarity(ctx, submission.ID, bestScore, bestMatch)
+}
+
+func (s *submissionSimilarityService) Matches(ctx context.Context, submissionID uint, threshold float64) (dto.SubmissionSimilarityResponse, error) {
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultSimilarityThreshold
+	}
+
+	fingerprint, err := s.repo.GetBySubmission(ctx, submissionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.SubmissionSimilarityResponse{}, ErrSimilarityUnavailable
+		}
+		return dto.SubmissionSimilarityResponse{}, err
+	}
+
+	candidates, err := s.repo.ListByAssignment(ctx, fingerprint.AssignmentID, submissionID, similarityCandidateLimit)
+	if err != nil {
+		return dto.SubmissionSimilarityResponse{}, err
+	}
+
+	hashes := decodeFingerprint(fingerprint.Hashes)
+	matches := make([]dto.SubmissionSimilarityMatch, 0)
+	for _, candidate := range candidates {
+		if candidate.StudentID == fingerprint.StudentID {
+			continue
+		}
+		score := sketchSimilarity(hashes, decodeFingerprint(candidate.Hashes))
+		if score < threshold {
+			continue
+		}
+		matches = append(matches, dto.SubmissionSimilarityMatch{
+			SubmissionID: candidate.SubmissionID,
+			StudentID:    candidate.StudentID,
+			Similarity:   math.Round(score*1000) / 1000,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Similarity > matches[j].Similarity
+	})
+
+	return dto.SubmissionSimilarityResponse{
+		SubmissionID: submissionID,
+		AssignmentID: fingerprint.AssignmentID,
+		Threshold:    threshold,
+		Matches:      matches,
+	}, nil
+}
+
+// fingerprintContent returns a bottom-k sketch of hashed word shingles. Tokens are
+// lower-cased and split on anything that is not a letter or digit so formatting
+// and punctuation changes do not hide copied work.
+func fingerprintContent(content []byte) []uint32 {
+	tokens := strings.FieldsFunc(strings.ToLower(string(content)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	size := similarityShingleSize
+	if len(tokens) < size {
+		size = len(tokens)
+	}
+
+	set := make(map[uint32]struct{})
+	for i := 0; i+size <= len(tokens); i++ {
+		hasher := fnv.New32a()
+		_, _ = hasher.Write([]byte(strings.Join(tokens[i:i+size], " ")))
+		set[hasher.Sum32()] = struct{}{}
+	}
+
+	hashes := make([]uint32, 0, len(set))
+	for hash := range set {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	if len(hashes) > similaritySketchSize {
+		hashes = hashes[:similaritySketchSize]
+	}
+	return hashes
+}
+
+// sketchSimilarity estimates the Jaccard index of two sorted bottom-k sketches.
+func sketchSimilarity(a, b []uint32) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	i, j, seen, shared := 0, 0, 0, 0
+	for seen < similaritySketchSize && (i < len(a) || j < len(b)) {
+		switch {
+		case j >= len(b) || (i < len(a) && a[i] < b[j]):
+			i++
+		case i >= len(a) || b[j] < a[i]:
+			j++
+		default:
+			shared++
+			i++
+			j++
+		}
+		seen++
+	}
+
+	return float64(shared) / float64(seen)
+}
+
+func encodeFingerprint(hashes []uint32) string {
+	parts := make([]string, len(hashes))
+	for i, hash := range hashes {
+		parts[i] = strconv.FormatUint(uint64(hash), 36)
+	}
+	return strings.Join(parts, " ")
+}
+
+func decodeFingerprint(encoded string) []uint32 {
+	fields := strings.Fields(encoded)
+	hashes := make([]uint32, 0, len(fields))
+	for _, field := range fields {
+		value, err := strconv.ParseUint(field, 36, 32)
+		if err != nil {
+			continue
+		}
+		hashes = append(hashes, uint32(value))
+	}
+	return hashes
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+const similarityEssay = `The mitochondria is the powerhouse of the cell. It converts nutrients
+into adenosine triphosphate through cellular respiration, which the cell then uses as
+its primary source of chemical energy for growth, movement and repair.`
+
+func TestSubmissionSimilarityServiceFlagsCopiedWork(t *testing.T) {
+	dsn := fmt.Sprintf("file:similarity_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Student{}, &models.Assignment{}, &models.Submission{}, &models.SubmissionFingerprint{}))
+
+	assignment := models.Assignment{Title: "Biology essay", DueDate: time.Now().Add(time.Hour)}
+	require.NoError(t, db.Create(&assignment).Error)
+
+	submissions := make([]models.Submission, 3)
+	for i := range submissions {
+		student := models.Student{Name: fmt.Sprintf("Student %d", i), Email: fmt.Sprintf("similarity-%d@example.com", i)}
+		require.NoError(t, db.Create(&student).Error)
+		submissions[i] = models.Submission{AssignmentID: assignment.ID, StudentID: student.ID, Status: models.SubmissionStatusSubmitted}
+		require.NoError(t, db.Create(&submissions[i]).Error)
+	}
+
+	svc := NewSubmissionSimilarityService(repository.NewSubmissionFingerprintRepository(db), testLogger())
+	ctx := context.Background()
+
+	require.NoError(t, svc.Analyze(ctx, submissions[0], []byte(similarityEssay)))
+	// Same text with different casing and punctuation should still match.
+	require.NoError(t, svc.Analyze(ctx, submissions[1], []byte("THE MITOCHONDRIA -- is the powerhouse of the cell!!\n"+similarityEssay[48:])))
+	require.NoError(t, svc.Analyze(ctx, submissions[2], []byte("Photosynthesis happens in chloroplasts where light energy is captured by chlorophyll pigments.")))
+
+	report, err := svc.Matches(ctx, submissions[1].ID, 0)
+	require.NoError(t, err)
+	require.Equal(t, DefaultSimilarityThreshold, report.Threshold)
+	require.Len(t, report.Matches, 1)
+	require.Equal(t, submissions[0].ID, report.Matches[0].SubmissionID)
+	require.Greater(t, report.Matches[0].Similarity, 0.9)
+
+	var original, copied, unrelated models.Submission
+	require.NoError(t, db.First(&original, submissions[0].ID).Error)
+	require.NoError(t, db.First(&copied, submissions[1].ID).Error)
+	require.NoError(t, db.First(&unrelated, submissions[2].ID).Error)
+	require.NotNil(t, copied.SimilarityMatchID)
+	require.Equal(t, submissions[0].ID, *copied.SimilarityMatchID)
+	require.NotNil(t, original.SimilarityMatchID)
+	require.Equal(t, submissions[1].ID, *original.SimilarityMatchID)
+	require.Nil(t, unrelated.SimilarityScore)
+
+	_, err = svc.Matches(ctx, 9999, 0.5)
+	require.ErrorIs(t, err, ErrSimilarityUnavailable)
+}
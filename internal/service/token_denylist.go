@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/noah-isme/gema-go-api/internal/clock"
+)
+
+// AccessTokenDenylistPrefix namespaces revoked access token IDs in Redis.
+const AccessTokenDenylistPrefix = "auth:access:revoked:"
+
+const (
+	refreshTokenDenylistPrefix  = "auth:refresh:revoked:"
+	refreshFamilyDenylistPrefix = "auth:refresh:family:revoked:"
+)
+
+// TokenDenylist records JWT IDs revoked before their token expires. Entries
+// live in Redis, so every instance sees them, until the token would have
+// expired anyway. Without Redis they are kept per instance.
+type TokenDenylist struct {
+	redis  *redis.Client
+	prefix string
+	clock  clock.Clock
+
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+// NewTokenDenylist constructs a denylist storing its keys under prefix.
+func NewTokenDenylist(redisClient *redis.Client, prefix string) *TokenDenylist {
+	return &TokenDenylist{
+		redis:   redisClient,
+		prefix:  prefix,
+		clock:   clock.Real(),
+		entries: make(map[string]time.Time),
+	}
+}
+
+// Revoke denylists jti for ttl. It reports false when jti was already
+// denylisted, which lets single-use tokens detect a replay.
+func (d *TokenDenylist) Revoke(ctx context.Context, jti string, ttl time.Duration) (bool, error) {
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	if d.redis != nil {
+		return d.redis.SetNX(ctx, d.prefix+jti, 1, ttl).Result()
+	}
+
+	now := d.clock.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for id, until := range d.entries {
+		if !now.Before(until) {
+			delete(d.entries, id)
+		}
+	}
+	if _, ok := d.entries[jti]; ok {
+		return false, nil
+	}
+	d.entries[jti] = now.Add(ttl)
+	return true, nil
+}
+
+// IsRevoked reports whether jti is denylisted.
+func (d *TokenDenylist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	if d.redis != nil {
+		count, err := d.redis.Exists(ctx, d.prefix+jti).Result()
+		return count > 0, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	until, ok := d.entries[jti]
+	return ok && d.clock.Now().Before(until), nil
+}
@@ -4,13 +4,13 @@ import (
 	"context"
 	"errors"
 	"strings"
-	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -31,29 +31,37 @@ type TutorialContentService interface {
 	GetProject(ctx context.Context, id uint) (dto.TutorialProjectResponse, error)
 	CreateArticle(ctx context.Context, payload dto.TutorialArticleCreateRequest) (dto.TutorialArticleResponse, error)
 	CreateProject(ctx context.Context, payload dto.TutorialProjectCreateRequest) (dto.TutorialProjectResponse, error)
+	UpdateArticle(ctx context.Context, id uint, payload dto.TutorialArticleUpdateRequest, actor ActivityActor) (dto.TutorialArticleResponse, error)
+	UpdateProject(ctx context.Context, id uint, payload dto.TutorialProjectUpdateRequest, actor ActivityActor) (dto.TutorialProjectResponse, error)
+	DeleteArticle(ctx context.Context, id uint, actor ActivityActor) error
+	DeleteProject(ctx context.Context, id uint, actor ActivityActor) error
 }
 
 type tutorialContentService struct {
 	articles  repository.TutorialArticleRepository
 	projects  repository.TutorialProjectRepository
 	validator *validator.Validate
+	activity  ActivityRecorder
 	logger    zerolog.Logger
-	now       func() time.Time
+	clock     clock.Clock
 }
 
-// NewTutorialContentService constructs the tutorial content service.
+// NewTutorialContentService constructs the tutorial content service. Updates
+// and deletes are recorded with activity when it is not nil.
 func NewTutorialContentService(
 	articleRepo repository.TutorialArticleRepository,
 	projectRepo repository.TutorialProjectRepository,
 	validate *validator.Validate,
+	activity ActivityRecorder,
 	logger zerolog.Logger,
 ) TutorialContentService {
 	return &tutorialContentService{
 		articles:  articleRepo,
 		projects:  projectRepo,
 		validator: validate,
+		activity:  activity,
 		logger:    logger.With().Str("component", "tutorial_content_service").Logger(),
-		now:       time.Now,
+		clock:     clock.Real(),
 	}
 }
 
@@ -144,7 +152,7 @@ func (s *tutorialContentService) CreateArticle(ctx context.Context, payload dto.
 		return dto.TutorialArticleResponse{}, err
 	}
 
-	now := s.now()
+	now := s.clock.Now()
 	article := models.TutorialArticle{
 		Slug:           generateContentSlug(payload.Title),
 		Title:          strings.TrimSpace(payload.Title),
@@ -193,17 +201,205 @@ func (s *tutorialContentService) CreateProject(ctx context.Context, payload dto.
 	return dto.NewTutorialProjectResponse(project), nil
 }
 
+// UpdateArticle patches an article. Publishing it stamps PublishedAt unless it
+// was published before; moving it out of published clears the stamp.
+func (s *tutorialContentService) UpdateArticle(ctx context.Context, id uint, payload dto.TutorialArticleUpdateRequest, actor ActivityActor) (dto.TutorialArticleResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.TutorialArticleResponse{}, err
+	}
+
+	article, err := s.articles.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.TutorialArticleResponse{}, ErrTutorialArticleNotFound
+		}
+		return dto.TutorialArticleResponse{}, err
+	}
+
+	changedFields := make([]string, 0)
+	if payload.Title != nil {
+		article.Title = strings.TrimSpace(*payload.Title)
+		changedFields = append(changedFields, "title")
+	}
+	if payload.Summary != nil {
+		article.Summary = strings.TrimSpace(*payload.Summary)
+		changedFields = append(changedFields, "summary")
+	}
+	if payload.Content != nil {
+		article.Content = strings.TrimSpace(*payload.Content)
+		changedFields = append(changedFields, "content")
+	}
+	if payload.Tags != nil {
+		article.Tags = sanitizeTags(*payload.Tags)
+		changedFields = append(changedFields, "tags")
+	}
+	if payload.ThumbnailURL != nil {
+		article.ThumbnailURL = strings.TrimSpace(*payload.ThumbnailURL)
+		changedFields = append(changedFields, "thumbnail_url")
+	}
+	if payload.Author != nil {
+		article.Author = strings.TrimSpace(*payload.Author)
+		changedFields = append(changedFields, "author")
+	}
+	if payload.ReadingMinutes != nil {
+		article.ReadingMinutes = normalizeReadingMinutes(*payload.ReadingMinutes)
+		changedFields = append(changedFields, "reading_minutes")
+	}
+	if payload.Status != nil {
+		article.Status = strings.ToLower(strings.TrimSpace(*payload.Status))
+		if article.Status != "published" {
+			article.PublishedAt = nil
+		} else if article.PublishedAt == nil {
+			now := s.clock.Now()
+			article.PublishedAt = &now
+		}
+		changedFields = append(changedFields, "status")
+	}
+	if payload.Reslug {
+		article.Slug = generateContentSlug(article.Title)
+		changedFields = append(changedFields, "slug")
+	}
+
+	if err := s.articles.Update(ctx, &article); err != nil {
+		return dto.TutorialArticleResponse{}, err
+	}
+
+	if len(changedFields) > 0 {
+		s.recordActivity(ctx, actor, "tutorial_article.updated", "tutorial_article", article.ID, map[string]interface{}{
+			"fields": changedFields,
+			"status": article.Status,
+		})
+	}
+
+	return dto.NewTutorialArticleResponse(article), nil
+}
+
+// UpdateProject patches a project.
+func (s *tutorialContentService) UpdateProject(ctx context.Context, id uint, payload dto.TutorialProjectUpdateRequest, actor ActivityActor) (dto.TutorialProjectResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.TutorialProjectResponse{}, err
+	}
+
+	project, err := s.projects.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.TutorialProjectResponse{}, ErrTutorialProjectNotFound
+		}
+		return dto.TutorialProjectResponse{}, err
+	}
+
+	changedFields := make([]string, 0)
+	if payload.Title != nil {
+		project.Title = strings.TrimSpace(*payload.Title)
+		changedFields = append(changedFields, "title")
+	}
+	if payload.Summary != nil {
+		project.Summary = strings.TrimSpace(*payload.Summary)
+		changedFields = append(changedFields, "summary")
+	}
+	if payload.Content != nil {
+		project.Content = strings.TrimSpace(*payload.Content)
+		changedFields = append(changedFields, "content")
+	}
+	if payload.Difficulty != nil {
+		project.Difficulty = strings.ToLower(strings.TrimSpace(*payload.Difficulty))
+		changedFields = append(changedFields, "difficulty")
+	}
+	if payload.EstimatedHours != nil {
+		project.EstimatedHours = normalizeEstimatedHours(*payload.EstimatedHours)
+		changedFields = append(changedFields, "estimated_hours")
+	}
+	if payload.Tags != nil {
+		project.Tags = sanitizeTags(*payload.Tags)
+		changedFields = append(changedFields, "tags")
+	}
+	if payload.RepoURL != nil {
+		project.RepoURL = strings.TrimSpace(*payload.RepoURL)
+		changedFields = append(changedFields, "repo_url")
+	}
+	if payload.PreviewURL != nil {
+		project.PreviewURL = strings.TrimSpace(*payload.PreviewURL)
+		changedFields = append(changedFields, "preview_url")
+	}
+	if payload.Status != nil {
+		project.Status = strings.ToLower(strings.TrimSpace(*payload.Status))
+		changedFields = append(changedFields, "status")
+	}
+	if payload.Reslug {
+		project.Slug = generateContentSlug(project.Title)
+		changedFields = append(changedFields, "slug")
+	}
+
+	if err := s.projects.Update(ctx, &project); err != nil {
+		return dto.TutorialProjectResponse{}, err
+	}
+
+	if len(changedFields) > 0 {
+		s.recordActivity(ctx, actor, "tutorial_project.updated", "tutorial_project", project.ID, map[string]interface{}{
+			"fields": changedFields,
+			"status": project.Status,
+		})
+	}
+
+	return dto.NewTutorialProjectResponse(project), nil
+}
+
+// DeleteArticle soft-deletes an article; the row is kept for restoring.
+func (s *tutorialContentService) DeleteArticle(ctx context.Context, id uint, actor ActivityActor) error {
+	if err := s.articles.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTutorialArticleNotFound
+		}
+		return err
+	}
+	s.recordActivity(ctx, actor, "tutorial_article.deleted", "tutorial_article", id, nil)
+	return nil
+}
+
+// DeleteProject soft-deletes a project; the row is kept for restoring.
+func (s *tutorialContentService) DeleteProject(ctx context.Context, id uint, actor ActivityActor) error {
+	if err := s.projects.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTutorialProjectNotFound
+		}
+		return err
+	}
+	s.recordActivity(ctx, actor, "tutorial_project.deleted", "tutorial_project", id, nil)
+	return nil
+}
+
+func (s *tutorialContentService) recordActivity(ctx context.Context, actor ActivityActor, action, entityType string, id uint, metadata map[string]interface{}) {
+	if s.activity == nil {
+		return
+	}
+	entry := ActivityEntry{
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   &id,
+		Metadata:   metadata,
+	}
+	if _, err := s.activity.Record(ctx, entry); err != nil {
+		s.logger.Warn().Err(err).Str("action", action).Msg("failed to record tutorial activity")
+	}
+}
+
 func (s *tutorialContentService) buildFilter(req dto.TutorialContentListRequest) repository.TutorialContentFilter {
 	page := normalizePage(req.Page)
 	pageSize := clampPageSize(req.PageSize)
 	tags := sanitizeTags(req.Tags)
+	search := strings.TrimSpace(req.Search)
 	sort := strings.ToLower(strings.TrimSpace(req.Sort))
 	if sort == "" {
 		sort = "recent"
+		if search != "" {
+			sort = "relevance"
+		}
 	}
 
 	return repository.TutorialContentFilter{
-		Search:   strings.TrimSpace(req.Search),
+		Search:   search,
 		Tags:     tags,
 		Sort:     sort,
 		Page:     page,
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/require"
@@ -24,7 +26,7 @@ func TestTutorialContentServiceCreateAndListArticles(t *testing.T) {
 	articleRepo := repository.NewTutorialArticleRepository(db)
 	projectRepo := repository.NewTutorialProjectRepository(db)
 
-	svc := NewTutorialContentService(articleRepo, projectRepo, validate, testLogger())
+	svc := NewTutorialContentService(articleRepo, projectRepo, validate, nil, testLogger())
 
 	payload := dto.TutorialArticleCreateRequest{
 		Title:          "Deep Learning Basics",
@@ -62,7 +64,7 @@ func TestTutorialContentServiceCreateProject(t *testing.T) {
 	articleRepo := repository.NewTutorialArticleRepository(db)
 	projectRepo := repository.NewTutorialProjectRepository(db)
 
-	svc := NewTutorialContentService(articleRepo, projectRepo, validate, testLogger())
+	svc := NewTutorialContentService(articleRepo, projectRepo, validate, nil, testLogger())
 	payload := dto.TutorialProjectCreateRequest{
 		Title:          "Weather App",
 		Summary:        "Build a weather dashboard",
@@ -87,3 +89,53 @@ func TestTutorialContentServiceCreateProject(t *testing.T) {
 	require.True(t, strings.HasPrefix(list.Items[0].Slug, "weather-app"))
 	require.Equal(t, int64(1), list.Pagination.TotalItems)
 }
+
+func TestTutorialContentServiceUpdateAndDeleteArticle(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:tutorial_update_%d?mode=memory&cache=shared", time.Now().UnixNano())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.TutorialArticle{}, &models.TutorialProject{}))
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	recorder := &stubActivityRecorder{}
+	svc := NewTutorialContentService(repository.NewTutorialArticleRepository(db), repository.NewTutorialProjectRepository(db), validate, recorder, testLogger())
+	ctx := context.Background()
+	actor := ActivityActor{ID: 7, Role: "teacher"}
+
+	created, err := svc.CreateArticle(ctx, dto.TutorialArticleCreateRequest{
+		Title:   "Draft Article",
+		Content: "Draft content long enough to pass validation.",
+		Status:  "draft",
+	})
+	require.NoError(t, err)
+	require.Nil(t, created.PublishedAt)
+
+	title, published := "Published Article", "published"
+	updated, err := svc.UpdateArticle(ctx, created.ID, dto.TutorialArticleUpdateRequest{Title: &title, Status: &published, Reslug: true}, actor)
+	require.NoError(t, err)
+	require.Equal(t, "Published Article", updated.Title)
+	require.Equal(t, "Draft content long enough to pass validation.", updated.Content, "absent fields are kept")
+	require.True(t, strings.HasPrefix(updated.Slug, "published-article-"))
+	require.NotNil(t, updated.PublishedAt)
+
+	archived := "archived"
+	updated, err = svc.UpdateArticle(ctx, created.ID, dto.TutorialArticleUpdateRequest{Status: &archived}, actor)
+	require.NoError(t, err)
+	require.Nil(t, updated.PublishedAt)
+
+	_, err = svc.UpdateArticle(ctx, 999, dto.TutorialArticleUpdateRequest{Title: &title}, actor)
+	require.ErrorIs(t, err, ErrTutorialArticleNotFound)
+
+	require.NoError(t, svc.DeleteArticle(ctx, created.ID, actor))
+	_, err = svc.GetArticle(ctx, created.ID)
+	require.ErrorIs(t, err, ErrTutorialArticleNotFound)
+	require.ErrorIs(t, svc.DeleteArticle(ctx, created.ID, actor), ErrTutorialArticleNotFound)
+
+	var deleted models.TutorialArticle
+	require.NoError(t, db.Unscoped().First(&deleted, created.ID).Error)
+	require.True(t, deleted.DeletedAt.Valid, "articles are soft-deleted")
+
+	require.Len(t, recorder.entries, 3)
+	require.Equal(t, "tutorial_article.updated", recorder.entries[0].Action)
+	require.Equal(t, "tutorial_article.deleted", recorder.entries[2].Action)
+	require.Equal(t, uint(7), recorder.entries[2].ActorID)
+}
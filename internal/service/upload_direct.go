@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/observability"
+	cloud "github.com/noah-isme/gema-go-api/pkg/cloudinary"
+)
+
+var (
+	// ErrDirectUploadDisabled indicates signed direct uploads are not configured.
+	ErrDirectUploadDisabled = errors.New("direct uploads are not available")
+	// ErrDirectUploadInvalid indicates a confirmation that does not describe
+	// an asset Cloudinary stored for a signature this API issued.
+	ErrDirectUploadInvalid = errors.New("upload confirmation could not be verified")
+	// ErrDirectUploadNotOwned indicates a confirmation for an upload signed
+	// for another user.
+	ErrDirectUploadNotOwned = errors.New("upload was signed for another user")
+)
+
+// SizeLimitScopeDirect is reported by SizeLimitError for direct uploads.
+const SizeLimitScopeDirect = "direct"
+
+// directUploadConfirmWindow bounds how long after Cloudinary stored an asset
+// it can still be confirmed. It matches the lifetime Cloudinary gives upload
+// signatures.
+const directUploadConfirmWindow = time.Hour
+
+// directUploadImageTransformation is signed into direct uploads when images
+// are allowed. Any incoming transformation makes Cloudinary store a
+// re-encoded image without EXIF and other metadata, as server uploads are
+// stored; a_exif applies the EXIF orientation first. Cloudinary does not
+// transform raw files.
+const directUploadImageTransformation = "a_exif"
+
+// DirectUploadSigner signs browser uploads that go straight to Cloudinary,
+// verifies the upload response the browser hands back and looks up what
+// Cloudinary stored.
+type DirectUploadSigner interface {
+	SignUpload(publicID string, constraints cloud.UploadConstraints, now time.Time) cloud.SignedUpload
+	VerifyUploadSignature(publicID, version, signature string) bool
+	Asset(ctx context.Context, resourceType, publicID string) (cloud.Asset, error)
+	Folder() string
+}
+
+// DirectUploadConfig enables signed direct uploads for files too large to
+// proxy through the API. A nil Signer disables them; MaxMB defaults to 100.
+type DirectUploadConfig struct {
+	Signer DirectUploadSigner
+	MaxMB  int
+}
+
+func (s *uploadService) SignDirect(ctx context.Context, req dto.UploadSignRequest, userID *uint) (dto.UploadSignResponse, error) {
+	_, span := s.tracer.Start(ctx, "upload.sign_direct")
+	defer span.End()
+
+	if s.signer == nil {
+		return dto.UploadSignResponse{}, ErrDirectUploadDisabled
+	}
+	if err := s.validator.Struct(req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return dto.UploadSignResponse{}, err
+	}
+
+	if err := s.directMax.check(req.SizeBytes, ErrUploadTooLarge); err != nil {
+		observability.UploadRejected().WithLabelValues("size").Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "payload too large")
+		return dto.UploadSignResponse{}, err
+	}
+
+	// The browser only declares a name here, so the extension is the best
+	// early hint; the stored format is checked again on confirmation.
+	fileType := mimeFromExtension(filepath.Ext(req.FileName))
+	if err := s.types.check(fileType); err != nil {
+		observability.UploadRejected().WithLabelValues("type").Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "type not allowed")
+		return dto.UploadSignResponse{}, err
+	}
+
+	now := s.clock.Now()
+	name := sanitizeFileName(req.FileName, now)
+	publicID := fmt.Sprintf("%s%s-%s", directUploadOwnerPrefix(userID), strings.TrimSuffix(name, filepath.Ext(name)), strings.ReplaceAll(uuid.NewString(), "-", "")[:12])
+	constraints := cloud.UploadConstraints{
+		AllowedFormats: s.types.formats(),
+		MaxFileSize:    s.directMax.bytes,
+	}
+	if s.types.check("image") == nil {
+		constraints.Transformation = directUploadImageTransformation
+	}
+	signed := s.signer.SignUpload(publicID, constraints, now)
+	span.SetAttributes(attribute.String("upload.public_id", publicID))
+
+	return dto.UploadSignResponse{
+		UploadURL:      signed.UploadURL,
+		CloudName:      signed.CloudName,
+		APIKey:         signed.APIKey,
+		Timestamp:      signed.Timestamp,
+		Folder:         signed.Folder,
+		PublicID:       signed.PublicID,
+		AllowedFormats: signed.AllowedFormats,
+		MaxFileSize:    signed.MaxFileSize,
+		Transformation: signed.Transformation,
+		Signature:      signed.Signature,
+		ExpiresAt:      time.Unix(signed.Timestamp, 0).Add(directUploadConfirmWindow).UTC(),
+		MaxBytes:       s.directMax.bytes,
+	}, nil
+}
+
+func (s *uploadService) ConfirmDirect(ctx context.Context, req dto.UploadConfirmRequest, userID *uint) (dto.UploadResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "upload.confirm_direct")
+	defer span.End()
+
+	if s.signer == nil {
+		return dto.UploadResponse{}, ErrDirectUploadDisabled
+	}
+	if err := s.validator.Struct(req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "validation failed")
+		return dto.UploadResponse{}, err
+	}
+	span.SetAttributes(attribute.String("upload.public_id", req.PublicID))
+
+	if err := s.verifyDirect(req, userID); err != nil {
+		observability.UploadRejected().WithLabelValues("signature").Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "verification failed")
+		return dto.UploadResponse{}, err
+	}
+
+	asset, err := s.signer.Asset(ctx, req.ResourceType, req.PublicID)
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, cloud.ErrAssetNotFound) {
+			observability.UploadRejected().WithLabelValues("signature").Inc()
+			span.SetStatus(codes.Error, "asset not found")
+			return dto.UploadResponse{}, ErrDirectUploadInvalid
+		}
+		span.SetStatus(codes.Error, "asset lookup failed")
+		return dto.UploadResponse{}, err
+	}
+	// A later upload under the same public ID replaces the asset, so only
+	// the version the signature covers may be recorded.
+	if asset.Version != req.Version {
+		observability.UploadRejected().WithLabelValues("signature").Inc()
+		span.SetStatus(codes.Error, "asset version changed")
+		return dto.UploadResponse{}, ErrDirectUploadInvalid
+	}
+
+	if err := s.directMax.check(asset.Bytes, ErrUploadTooLarge); err != nil {
+		observability.UploadRejected().WithLabelValues("size").Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "payload too large")
+		return dto.UploadResponse{}, err
+	}
+
+	format := strings.ToLower(strings.TrimSpace(asset.Format))
+	fileType := mimeFromExtension("." + format)
+	if fileType == "" && asset.ResourceType == "image" {
+		fileType = "image"
+	}
+	if err := s.types.check(fileType); err != nil {
+		observability.UploadRejected().WithLabelValues("type").Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "type not allowed")
+		return dto.UploadResponse{}, err
+	}
+
+	name := req.OriginalFilename
+	if name == "" {
+		name = req.PublicID[strings.LastIndex(req.PublicID, "/")+1:]
+	}
+	if format != "" {
+		name = strings.TrimSuffix(name, filepath.Ext(name)) + "." + format
+	}
+
+	checksum := models.UploadChecksum(models.UploadChecksumMD5, asset.Etag)
+	if existing, ok := s.findDuplicate(ctx, userID, checksum); ok {
+		observability.UploadRequests().WithLabelValues(fileType).Inc()
+		span.SetAttributes(attribute.Bool("upload.deduplicated", true))
+		span.SetStatus(codes.Ok, "deduplicated")
+		return newUploadResponse(existing), nil
+	}
+
+	record, err := s.store(ctx, models.UploadRecord{
+		UserID:    userID,
+		FileName:  sanitizeFileName(name, s.clock.Now()),
+		URL:       asset.SecureURL,
+		MimeType:  fileType,
+		SizeBytes: asset.Bytes,
+		Checksum:  checksum,
+		Width:     asset.Width,
+		Height:    asset.Height,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "persistence failed")
+		return dto.UploadResponse{}, err
+	}
+
+	observability.UploadRequests().WithLabelValues(fileType).Inc()
+	span.SetStatus(codes.Ok, "confirmed")
+
+	return newUploadResponse(record), nil
+}
+
+// verifyDirect checks that Cloudinary signed the upload response, that the
+// asset lives in the folder this API signs for, that it was signed for the
+// confirming user, and that it was uploaded recently.
+func (s *uploadService) verifyDirect(req dto.UploadConfirmRequest, userID *uint) error {
+	version := strconv.FormatInt(req.Version, 10)
+	if !s.signer.VerifyUploadSignature(req.PublicID, version, req.Signature) {
+		return ErrDirectUploadInvalid
+	}
+
+	uploadedAt := time.Unix(req.Version, 0)
+	if s.clock.Now().Sub(uploadedAt) > directUploadConfirmWindow {
+		return ErrDirectUploadInvalid
+	}
+
+	name := req.PublicID
+	if folder := strings.Trim(s.signer.Folder(), "/"); folder != "" {
+		if !strings.HasPrefix(name, folder+"/") {
+			return ErrDirectUploadInvalid
+		}
+		name = strings.TrimPrefix(name, folder+"/")
+	}
+	if !strings.HasPrefix(name, directUploadOwnerPrefix(userID)) {
+		return ErrDirectUploadNotOwned
+	}
+
+	return nil
+}
+
+// directUploadOwnerPrefix ties signed public IDs to the requesting user so a
+// confirmation cannot claim someone else's upload.
+func directUploadOwnerPrefix(userID *uint) string {
+	if userID == nil {
+		return "anon-"
+	}
+	return fmt.Sprintf("u%d-", *userID)
+}
+
+// mimeFromExtension maps a file extension to a normalized MIME type. ZIP is
+// listed explicitly because Go's built-in table lacks it when the host has no
+// mime.types file.
+func mimeFromExtension(ext string) string {
+	ext = strings.ToLower(ext)
+	if ext == ".zip" {
+		return "application/zip"
+	}
+	return normalizeMime(mime.TypeByExtension(ext))
+}
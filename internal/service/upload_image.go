@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// uploadJPEGQuality keeps re-encoded photos visually identical to the upload.
+const uploadJPEGQuality = 90
+
+// sanitizableImageTypes are the image types server uploads accept, since they
+// are the ones whose metadata can be removed.
+var sanitizableImageTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+// errImageFormatUnsupported indicates an image in a format whose metadata
+// cannot be removed, such as WebP, HEIC or TIFF.
+var errImageFormatUnsupported = errors.New("image format not supported")
+
+// uploadImage is an image upload with its metadata removed.
+type uploadImage struct {
+	data   []byte
+	width  int
+	height int
+}
+
+// sanitizeUploadImage decodes an image upload and re-encodes JPEG and PNG
+// files in their own format, which drops EXIF and other metadata (camera
+// details, GPS location). A JPEG's EXIF orientation is applied to the pixels
+// first so the photo keeps displaying upright. GIFs are only checked so
+// animations survive. Formats the standard library cannot decode, such as
+// WebP, would keep their metadata and are rejected with
+// errImageFormatUnsupported. A file that claims a decodable format but fails
+// to decode is rejected as ErrUploadScanFailed.
+func sanitizeUploadImage(payload []byte) (uploadImage, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(payload))
+	if errors.Is(err, image.ErrFormat) {
+		return uploadImage{}, errImageFormatUnsupported
+	}
+	if err != nil || config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return uploadImage{}, ErrUploadScanFailed
+	}
+
+	result := uploadImage{data: payload, width: config.Width, height: config.Height}
+	if format == "gif" {
+		if _, err := gif.DecodeAll(bytes.NewReader(payload)); err != nil {
+			return uploadImage{}, ErrUploadScanFailed
+		}
+		return result, nil
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(payload))
+	if err != nil {
+		return uploadImage{}, ErrUploadScanFailed
+	}
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		decoded = orientImage(decoded, jpegOrientation(payload))
+		err = jpeg.Encode(&buf, decoded, &jpeg.Options{Quality: uploadJPEGQuality})
+	} else {
+		err = png.Encode(&buf, decoded)
+	}
+	if err != nil {
+		return uploadImage{}, err
+	}
+
+	bounds := decoded.Bounds()
+	result.data, result.width, result.height = buf.Bytes(), bounds.Dx(), bounds.Dy()
+	return result, nil
+}
+
+// jpegOrientation returns the EXIF Orientation tag (1-8) of a JPEG file, or 1
+// when it has none.
+func jpegOrientation(payload []byte) int {
+	const orientationTag = 0x0112
+
+	if len(payload) < 4 || payload[0] != 0xFF || payload[1] != 0xD8 {
+		return 1
+	}
+	for offset := 2; offset+4 <= len(payload); {
+		if payload[offset] != 0xFF {
+			return 1
+		}
+		marker := payload[offset+1]
+		// Start of scan or end of image: metadata segments come before.
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(payload[offset+2:]))
+		end := offset + 2 + length
+		if length < 2 || end > len(payload) {
+			return 1
+		}
+		segment := payload[offset+4 : end]
+		offset = end
+
+		if marker != 0xE1 || !bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			continue
+		}
+		tiff := segment[6:]
+		if len(tiff) < 8 {
+			return 1
+		}
+		var order binary.ByteOrder
+		switch string(tiff[:2]) {
+		case "II":
+			order = binary.LittleEndian
+		case "MM":
+			order = binary.BigEndian
+		default:
+			return 1
+		}
+		ifd := int(order.Uint32(tiff[4:]))
+		if ifd+2 > len(tiff) {
+			return 1
+		}
+		entries := int(order.Uint16(tiff[ifd:]))
+		for i := 0; i < entries; i++ {
+			entry := ifd + 2 + i*12
+			if entry+12 > len(tiff) {
+				return 1
+			}
+			if order.Uint16(tiff[entry:]) != orientationTag {
+				continue
+			}
+			if value := int(order.Uint16(tiff[entry+8:])); value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+		return 1
+	}
+	return 1
+}
+
+// orientImage turns src as the EXIF orientation says it should be displayed.
+func orientImage(src image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	bounds := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], rgba.Pix[rgba.PixOffset(x, y):rgba.PixOffset(x, y)+4])
+		}
+	}
+	return dst
+}
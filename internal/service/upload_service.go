@@ -15,13 +15,17 @@ import (
 	"time"
 
 	"github.com/gabriel-vasile/mimetype"
+	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/logging"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/observability"
 	"github.com/noah-isme/gema-go-api/internal/repository"
@@ -34,37 +38,64 @@ var (
 	ErrUploadTypeNotAllowed = errors.New("file type not allowed")
 	// ErrUploadScanFailed indicates validation of the file failed.
 	ErrUploadScanFailed = errors.New("file scanning failed")
+	// ErrUploadNotFound indicates the upload does not exist or belongs to
+	// another user.
+	ErrUploadNotFound = errors.New("upload not found")
 )
 
 // FileStorage abstracts upload destinations.
 type FileStorage interface {
 	Upload(ctx context.Context, name string, reader io.Reader) (string, error)
+	// Delete removes the object behind a URL returned by Upload.
+	Delete(ctx context.Context, url string) error
 }
 
-// UploadService handles validation and persistence of uploads.
+// UploadService handles validation and persistence of uploads. Small files
+// are proxied through Upload; larger ones go straight to Cloudinary with
+// SignDirect and are recorded with ConfirmDirect.
 type UploadService interface {
 	Upload(ctx context.Context, file *multipart.FileHeader, userID *uint) (dto.UploadResponse, error)
+	SignDirect(ctx context.Context, req dto.UploadSignRequest, userID *uint) (dto.UploadSignResponse, error)
+	ConfirmDirect(ctx context.Context, req dto.UploadConfirmRequest, userID *uint) (dto.UploadResponse, error)
+	// ListByUser and Delete are scoped to userID; a nil userID (admins)
+	// covers every user's uploads.
+	ListByUser(ctx context.Context, userID *uint, page, pageSize int) (dto.UploadListResponse, error)
+	Delete(ctx context.Context, id uint, userID *uint) error
 }
 
 type uploadService struct {
-	storage FileStorage
-	repo    repository.UploadRepository
-	logger  zerolog.Logger
-	maxSize int64
-	tracer  trace.Tracer
+	storage   FileStorage
+	repo      repository.UploadRepository
+	signer    DirectUploadSigner
+	validator *validator.Validate
+	logger    zerolog.Logger
+	maxSize   int64
+	directMax sizeLimit
+	types     mimeAllowList
+	tracer    trace.Tracer
+	clock     clock.Clock
 }
 
-// NewUploadService constructs an upload service.
-func NewUploadService(storage FileStorage, repo repository.UploadRepository, maxSizeMB int, logger zerolog.Logger) UploadService {
+// NewUploadService constructs an upload service. An empty allowedMimeTypes
+// falls back to DefaultUploadMimeTypes.
+func NewUploadService(storage FileStorage, repo repository.UploadRepository, maxSizeMB int, allowedMimeTypes []string, direct DirectUploadConfig, validator *validator.Validate, logger zerolog.Logger) UploadService {
 	if maxSizeMB <= 0 {
 		maxSizeMB = 10
 	}
+	if direct.MaxMB <= 0 {
+		direct.MaxMB = 100
+	}
 	return &uploadService{
-		storage: storage,
-		repo:    repo,
-		logger:  logger.With().Str("component", "upload_service").Logger(),
-		maxSize: int64(maxSizeMB) * 1024 * 1024,
-		tracer:  otel.Tracer("github.com/noah-isme/gema-go-api/internal/service/upload"),
+		storage:   storage,
+		repo:      repo,
+		signer:    direct.Signer,
+		validator: validator,
+		logger:    logger.With().Str("component", "upload_service").Logger(),
+		maxSize:   int64(maxSizeMB) * 1024 * 1024,
+		directMax: sizeLimit{bytes: int64(direct.MaxMB) * bytesPerMB, scope: SizeLimitScopeDirect},
+		types:     newMimeAllowList(allowedMimeTypes, DefaultUploadMimeTypes),
+		tracer:    otel.Tracer("github.com/noah-isme/gema-go-api/internal/service/upload"),
+		clock:     clock.Real(),
 	}
 }
 
@@ -125,11 +156,11 @@ func (s *uploadService) Upload(ctx context.Context, file *multipart.FileHeader,
 	mime := mimetype.Detect(buf.Bytes())
 	fileType := normalizeMime(mime.String())
 	span.SetAttributes(attribute.String("upload.detected_mime", fileType))
-	if !isAllowedType(fileType) {
+	if err := s.types.check(fileType); err != nil {
 		observability.UploadRejected().WithLabelValues("type").Inc()
-		span.RecordError(ErrUploadTypeNotAllowed)
+		span.RecordError(err)
 		span.SetStatus(codes.Error, "type not allowed")
-		return dto.UploadResponse{}, ErrUploadTypeNotAllowed
+		return dto.UploadResponse{}, err
 	}
 
 	if err := s.scan(buf.Bytes(), fileType); err != nil {
@@ -139,8 +170,39 @@ func (s *uploadService) Upload(ctx context.Context, file *multipart.FileHeader,
 		return dto.UploadResponse{}, err
 	}
 
-	checksum := sha256.Sum256(buf.Bytes())
-	sanitizedName := sanitizeFileName(file.Filename)
+	// Images are stored re-encoded without metadata, so the checksum and size
+	// below describe the stored file.
+	var width, height int
+	if fileType == "image" {
+		processed, err := sanitizeUploadImage(buf.Bytes())
+		if errors.Is(err, errImageFormatUnsupported) {
+			err = &FileTypeError{Detected: mime.String(), Allowed: s.types.sanitizableDisplay()}
+			observability.UploadRejected().WithLabelValues("type").Inc()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "type not allowed")
+			return dto.UploadResponse{}, err
+		}
+		if err != nil {
+			observability.UploadRejected().WithLabelValues("scan").Inc()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "image processing failed")
+			return dto.UploadResponse{}, err
+		}
+		buf = bytes.NewBuffer(processed.data)
+		width, height = processed.width, processed.height
+		span.SetAttributes(attribute.Int("upload.image_width", width), attribute.Int("upload.image_height", height))
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	checksum := models.UploadChecksum(models.UploadChecksumSHA256, hex.EncodeToString(sum[:]))
+	if existing, ok := s.findDuplicate(ctx, userID, checksum); ok {
+		observability.UploadRequests().WithLabelValues(fileType).Inc()
+		span.SetAttributes(attribute.Bool("upload.deduplicated", true))
+		span.SetStatus(codes.Ok, "deduplicated")
+		return newUploadResponse(existing), nil
+	}
+
+	sanitizedName := sanitizeFileName(file.Filename, s.clock.Now())
 	span.SetAttributes(
 		attribute.String("upload.sanitized_name", sanitizedName),
 		attribute.Int64("upload.size_bytes", int64(buf.Len())),
@@ -159,14 +221,17 @@ func (s *uploadService) Upload(ctx context.Context, file *multipart.FileHeader,
 		URL:       url,
 		MimeType:  fileType,
 		SizeBytes: int64(buf.Len()),
-		Checksum:  hex.EncodeToString(checksum[:]),
+		Checksum:  checksum,
+		Width:     width,
+		Height:    height,
 	}
 	if userID != nil {
 		record.UserID = userID
 		span.SetAttributes(attribute.Int("upload.user_id", int(*userID)))
 	}
 
-	if err := s.repo.Create(ctx, &record); err != nil {
+	record, err = s.store(ctx, record)
+	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "persistence failed")
 		return dto.UploadResponse{}, err
@@ -175,13 +240,102 @@ func (s *uploadService) Upload(ctx context.Context, file *multipart.FileHeader,
 	observability.UploadRequests().WithLabelValues(fileType).Inc()
 	span.SetStatus(codes.Ok, "stored")
 
+	return newUploadResponse(record), nil
+}
+
+// findDuplicate looks for an earlier upload of the same content by the same
+// user. Anonymous uploads are never deduplicated, and a failed lookup only
+// costs a redundant upload.
+func (s *uploadService) findDuplicate(ctx context.Context, userID *uint, checksum string) (models.UploadRecord, bool) {
+	if userID == nil || checksum == "" {
+		return models.UploadRecord{}, false
+	}
+
+	existing, err := s.repo.FindByChecksum(ctx, *userID, checksum)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logging.FromContext(ctx, s.logger).Warn().Err(err).Uint("user_id", *userID).Msg("upload checksum lookup failed")
+		}
+		return models.UploadRecord{}, false
+	}
+	return existing, true
+}
+
+// store persists record. When a concurrent identical upload by the same user
+// won the unique checksum index, that record is returned instead.
+func (s *uploadService) store(ctx context.Context, record models.UploadRecord) (models.UploadRecord, error) {
+	if err := s.repo.Create(ctx, &record); err != nil {
+		if existing, ok := s.findDuplicate(ctx, record.UserID, record.Checksum); ok {
+			return existing, nil
+		}
+		return models.UploadRecord{}, err
+	}
+	return record, nil
+}
+
+func (s *uploadService) ListByUser(ctx context.Context, userID *uint, page, pageSize int) (dto.UploadListResponse, error) {
+	page = maxInt(page, 1)
+	pageSize = clampPageSize(pageSize)
+
+	records, total, err := s.repo.List(ctx, repository.UploadFilter{UserID: userID, Page: page, PageSize: pageSize})
+	if err != nil {
+		return dto.UploadListResponse{}, err
+	}
+
+	items := make([]dto.UploadResponse, 0, len(records))
+	for _, record := range records {
+		items = append(items, newUploadResponse(record))
+	}
+
+	return dto.UploadListResponse{
+		Items: items,
+		Pagination: dto.PaginationMeta{
+			Page:       page,
+			PageSize:   pageSize,
+			TotalItems: total,
+			TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+		},
+	}, nil
+}
+
+// Delete removes the stored file before soft-deleting the record, so a
+// storage failure leaves the record in place for a retry.
+func (s *uploadService) Delete(ctx context.Context, id uint, userID *uint) error {
+	record, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUploadNotFound
+		}
+		return err
+	}
+	if userID != nil && (record.UserID == nil || *record.UserID != *userID) {
+		return ErrUploadNotFound
+	}
+
+	if err := s.storage.Delete(ctx, record.URL); err != nil {
+		return fmt.Errorf("delete stored file: %w", err)
+	}
+	if err := s.repo.Delete(ctx, record.ID); err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx, s.logger).Info().Uint("upload_id", record.ID).Msg("upload deleted")
+	return nil
+}
+
+func newUploadResponse(record models.UploadRecord) dto.UploadResponse {
 	return dto.UploadResponse{
-		URL:       url,
+		ID:        record.ID,
+		UserID:    record.UserID,
+		URL:       record.URL,
 		SizeBytes: record.SizeBytes,
 		MimeType:  record.MimeType,
 		Checksum:  record.Checksum,
 		FileName:  record.FileName,
-	}, nil
+		Width:     record.Width,
+		Height:    record.Height,
+		CreatedAt: record.CreatedAt,
+	}
 }
 
 func (s *uploadService) scan(payload []byte, mime string) error {
@@ -201,7 +355,7 @@ func (s *uploadService) scan(payload []byte, mime string) error {
 	return nil
 }
 
-func sanitizeFileName(name string) string {
+func sanitizeFileName(name string, now time.Time) string {
 	base := strings.TrimSuffix(name, filepath.Ext(name))
 	base = strings.ToLower(base)
 	base = strings.Map(func(r rune) rune {
@@ -215,7 +369,7 @@ func sanitizeFileName(name string) string {
 	}, base)
 	base = strings.Trim(base, "-")
 	if base == "" {
-		base = fmt.Sprintf("upload-%d", time.Now().Unix())
+		base = fmt.Sprintf("upload-%d", now.Unix())
 	}
 	ext := strings.ToLower(filepath.Ext(name))
 	if ext == "" {
@@ -223,30 +377,3 @@ func sanitizeFileName(name string) string {
 	}
 	return base + ext
 }
-
-func normalizeMime(m string) string {
-	lower := strings.ToLower(strings.TrimSpace(m))
-	if strings.HasPrefix(lower, "image/") {
-		return "image"
-	}
-	switch lower {
-	case "application/pdf":
-		return "application/pdf"
-	case "application/zip", "application/x-zip-compressed":
-		return "application/zip"
-	default:
-		return lower
-	}
-}
-
-func isAllowedType(m string) bool {
-	if m == "image" {
-		return true
-	}
-	switch m {
-	case "application/pdf", "application/zip":
-		return true
-	default:
-		return false
-	}
-}
@@ -3,18 +3,38 @@ package service
 import (
 	"bytes"
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
 	"io"
 	"mime/multipart"
 	"net/textproto"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+	cloud "github.com/noah-isme/gema-go-api/pkg/cloudinary"
 )
 
 type storageStub struct {
 	uploaded bytes.Buffer
+	deleted  []string
+}
+
+func (s *storageStub) Delete(ctx context.Context, url string) error {
+	s.deleted = append(s.deleted, url)
+	return nil
 }
 
 func (s *storageStub) Upload(ctx context.Context, name string, reader io.Reader) (string, error) {
@@ -27,18 +47,59 @@ func (s *storageStub) Upload(ctx context.Context, name string, reader io.Reader)
 }
 
 type uploadRepoStub struct {
-	record models.UploadRecord
+	record  models.UploadRecord
+	records []models.UploadRecord
 }
 
 func (u *uploadRepoStub) Create(ctx context.Context, record *models.UploadRecord) error {
+	record.ID = uint(len(u.records) + 1)
 	u.record = *record
+	u.records = append(u.records, *record)
 	return nil
 }
 
+func (u *uploadRepoStub) FindByChecksum(ctx context.Context, userID uint, checksum string) (models.UploadRecord, error) {
+	for _, record := range u.records {
+		if record.UserID != nil && *record.UserID == userID && record.Checksum == checksum {
+			return record, nil
+		}
+	}
+	return models.UploadRecord{}, gorm.ErrRecordNotFound
+}
+
+func (u *uploadRepoStub) List(ctx context.Context, filter repository.UploadFilter) ([]models.UploadRecord, int64, error) {
+	var matched []models.UploadRecord
+	for _, record := range u.records {
+		if filter.UserID == nil || (record.UserID != nil && *record.UserID == *filter.UserID) {
+			matched = append(matched, record)
+		}
+	}
+	return matched, int64(len(matched)), nil
+}
+
+func (u *uploadRepoStub) GetByID(ctx context.Context, id uint) (models.UploadRecord, error) {
+	for _, record := range u.records {
+		if record.ID == id {
+			return record, nil
+		}
+	}
+	return models.UploadRecord{}, gorm.ErrRecordNotFound
+}
+
+func (u *uploadRepoStub) Delete(ctx context.Context, id uint) error {
+	for i, record := range u.records {
+		if record.ID == id {
+			u.records = append(u.records[:i], u.records[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
 func TestUploadServiceRejectsSize(t *testing.T) {
 	storage := &storageStub{}
 	repo := &uploadRepoStub{}
-	svc := NewUploadService(storage, repo, 1, testLogger())
+	svc := NewUploadService(storage, repo, 1, nil, DirectUploadConfig{}, validator.New(), testLogger())
 
 	file := buildFileHeader(t, "file.pdf", bytes.Repeat([]byte("a"), 2*1024*1024))
 
@@ -49,7 +110,7 @@ func TestUploadServiceRejectsSize(t *testing.T) {
 func TestUploadServiceTypeValidation(t *testing.T) {
 	storage := &storageStub{}
 	repo := &uploadRepoStub{}
-	svc := NewUploadService(storage, repo, 5, testLogger())
+	svc := NewUploadService(storage, repo, 5, nil, DirectUploadConfig{}, validator.New(), testLogger())
 
 	file := buildFileHeader(t, "file.txt", []byte("plain text"))
 	_, err := svc.Upload(context.Background(), file, nil)
@@ -59,15 +120,335 @@ func TestUploadServiceTypeValidation(t *testing.T) {
 func TestUploadServiceSuccess(t *testing.T) {
 	storage := &storageStub{}
 	repo := &uploadRepoStub{}
-	svc := NewUploadService(storage, repo, 5, testLogger())
+	svc := NewUploadService(storage, repo, 5, nil, DirectUploadConfig{}, validator.New(), testLogger())
 
-	pngHeader := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}
-	file := buildFileHeader(t, "image.png", pngHeader)
+	file := buildFileHeader(t, "image.png", encodeTestPNG(t, 4, 3))
 
 	resp, err := svc.Upload(context.Background(), file, nil)
 	require.NoError(t, err)
 	require.Contains(t, resp.URL, "image")
 	require.Equal(t, repo.record.MimeType, "image")
+	require.Equal(t, 4, resp.Width)
+	require.Equal(t, 3, resp.Height)
+	require.Equal(t, 4, repo.record.Width)
+}
+
+func TestUploadServiceStripsImageMetadata(t *testing.T) {
+	storage := &storageStub{}
+	repo := &uploadRepoStub{}
+	svc := NewUploadService(storage, repo, 5, nil, DirectUploadConfig{}, validator.New(), testLogger())
+
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 8, 6)), nil))
+	// Insert an APP1 EXIF segment right after the SOI marker.
+	exif := append([]byte("Exif\x00\x00"), []byte("GPSLatitude=-6.2")...)
+	segment := append([]byte{0xFF, 0xE1, 0x00, byte(len(exif) + 2)}, exif...)
+	photo := append(append(append([]byte{}, encoded.Bytes()[:2]...), segment...), encoded.Bytes()[2:]...)
+
+	resp, err := svc.Upload(context.Background(), buildFileHeader(t, "photo.jpg", photo), nil)
+	require.NoError(t, err)
+	require.Equal(t, 8, resp.Width)
+	require.Equal(t, 6, resp.Height)
+	require.NotContains(t, storage.uploaded.String(), "GPSLatitude")
+	require.Equal(t, int64(storage.uploaded.Len()), repo.record.SizeBytes)
+}
+
+func TestUploadServiceAppliesJPEGOrientation(t *testing.T) {
+	storage := &storageStub{}
+	svc := NewUploadService(storage, &uploadRepoStub{}, 5, nil, DirectUploadConfig{}, validator.New(), testLogger())
+
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 8, 6)), nil))
+	// A little-endian EXIF block whose only IFD0 entry is Orientation = 6
+	// (rotate 90° clockwise).
+	tiff := []byte{'I', 'I', 0x2A, 0x00, 0x08, 0x00, 0x00, 0x00, 0x01, 0x00, 0x12, 0x01, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	exif := append([]byte("Exif\x00\x00"), tiff...)
+	segment := append([]byte{0xFF, 0xE1, 0x00, byte(len(exif) + 2)}, exif...)
+	photo := append(append(append([]byte{}, encoded.Bytes()[:2]...), segment...), encoded.Bytes()[2:]...)
+	require.Equal(t, 6, jpegOrientation(photo))
+
+	resp, err := svc.Upload(context.Background(), buildFileHeader(t, "photo.jpg", photo), nil)
+	require.NoError(t, err)
+	require.Equal(t, 6, resp.Width)
+	require.Equal(t, 8, resp.Height)
+	stored, err := jpeg.DecodeConfig(bytes.NewReader(storage.uploaded.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, 6, stored.Width, "the rotation is baked into the pixels")
+	require.Equal(t, 1, jpegOrientation(storage.uploaded.Bytes()))
+}
+
+func TestOrientImageRotatesPixels(t *testing.T) {
+	red, blue := color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+
+	clockwise := orientImage(src, 6)
+	require.Equal(t, image.Rect(0, 0, 1, 2), clockwise.Bounds())
+	require.Equal(t, red, clockwise.At(0, 0))
+	require.Equal(t, blue, clockwise.At(0, 1))
+
+	counterClockwise := orientImage(src, 8)
+	require.Equal(t, blue, counterClockwise.At(0, 0))
+	require.Equal(t, red, counterClockwise.At(0, 1))
+
+	mirrored := orientImage(src, 2)
+	require.Equal(t, blue, mirrored.At(0, 0))
+	require.Same(t, src, orientImage(src, 1).(*image.RGBA))
+}
+
+func TestUploadServiceRejectsImagesItCannotStrip(t *testing.T) {
+	storage := &storageStub{}
+	svc := NewUploadService(storage, &uploadRepoStub{}, 5, nil, DirectUploadConfig{}, validator.New(), testLogger())
+
+	webp := append([]byte("RIFF\x1a\x00\x00\x00WEBPVP8 "), bytes.Repeat([]byte{0}, 18)...)
+	_, err := svc.Upload(context.Background(), buildFileHeader(t, "photo.webp", webp), nil)
+	require.ErrorIs(t, err, ErrUploadTypeNotAllowed)
+	var typeErr *FileTypeError
+	require.ErrorAs(t, err, &typeErr)
+	require.Equal(t, "image/webp", typeErr.Detected)
+	require.Equal(t, []string{"image/jpeg", "image/png", "image/gif", "application/pdf", "application/zip"}, typeErr.Allowed)
+	require.Zero(t, storage.uploaded.Len(), "metadata-bearing files are never stored")
+}
+
+func TestUploadServiceRejectsUndecodableImage(t *testing.T) {
+	svc := NewUploadService(&storageStub{}, &uploadRepoStub{}, 5, nil, DirectUploadConfig{}, validator.New(), testLogger())
+
+	truncated := encodeTestPNG(t, 4, 4)[:40]
+	_, err := svc.Upload(context.Background(), buildFileHeader(t, "image.png", truncated), nil)
+	require.ErrorIs(t, err, ErrUploadScanFailed)
+}
+
+func TestUploadServiceDeduplicatesByChecksum(t *testing.T) {
+	storage := &storageStub{}
+	repo := &uploadRepoStub{}
+	svc := NewUploadService(storage, repo, 5, nil, DirectUploadConfig{}, validator.New(), testLogger())
+
+	payload := encodeTestPNG(t, 2, 2)
+	owner := uint(3)
+
+	first, err := svc.Upload(context.Background(), buildFileHeader(t, "image.png", payload), &owner)
+	require.NoError(t, err)
+
+	storage.uploaded.Reset()
+	again, err := svc.Upload(context.Background(), buildFileHeader(t, "renamed.png", payload), &owner)
+	require.NoError(t, err)
+	require.Equal(t, first, again)
+	require.Zero(t, storage.uploaded.Len(), "identical content must not be uploaded twice")
+	require.Len(t, repo.records, 1)
+
+	other := uint(4)
+	_, err = svc.Upload(context.Background(), buildFileHeader(t, "image.png", payload), &other)
+	require.NoError(t, err)
+	require.Len(t, repo.records, 2, "another user's upload is never reused")
+
+	_, err = svc.Upload(context.Background(), buildFileHeader(t, "image.png", payload), nil)
+	require.NoError(t, err)
+	require.Len(t, repo.records, 3, "anonymous uploads are not deduplicated")
+}
+
+func TestUploadServiceListAndDeleteAreScopedToOwner(t *testing.T) {
+	storage := &storageStub{}
+	repo := &uploadRepoStub{}
+	svc := NewUploadService(storage, repo, 5, nil, DirectUploadConfig{}, validator.New(), testLogger())
+
+	owner, other := uint(3), uint(4)
+	mine, err := svc.Upload(context.Background(), buildFileHeader(t, "notes.pdf", []byte("%PDF-1.4 mine")), &owner)
+	require.NoError(t, err)
+	theirs, err := svc.Upload(context.Background(), buildFileHeader(t, "theirs.pdf", []byte("%PDF-1.4 theirs")), &other)
+	require.NoError(t, err)
+
+	list, err := svc.ListByUser(context.Background(), &owner, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	require.Equal(t, mine.ID, list.Items[0].ID)
+	require.Equal(t, int64(1), list.Pagination.TotalItems)
+	require.Equal(t, 1, list.Pagination.TotalPages)
+
+	all, err := svc.ListByUser(context.Background(), nil, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, all.Items, 2)
+	require.Equal(t, 20, all.Pagination.PageSize)
+
+	require.ErrorIs(t, svc.Delete(context.Background(), theirs.ID, &owner), ErrUploadNotFound)
+	require.Empty(t, storage.deleted)
+
+	require.NoError(t, svc.Delete(context.Background(), mine.ID, &owner))
+	require.Equal(t, []string{mine.URL}, storage.deleted)
+	require.ErrorIs(t, svc.Delete(context.Background(), mine.ID, &owner), ErrUploadNotFound)
+
+	require.NoError(t, svc.Delete(context.Background(), theirs.ID, nil), "admins may delete any upload")
+	require.Empty(t, repo.records)
+}
+
+func TestUploadServiceCustomAllowList(t *testing.T) {
+	storage := &storageStub{}
+	repo := &uploadRepoStub{}
+	svc := NewUploadService(storage, repo, 5, []string{"text/plain", "application/x-zip-compressed"}, DirectUploadConfig{}, validator.New(), testLogger())
+
+	_, err := svc.Upload(context.Background(), buildFileHeader(t, "notes.txt", []byte("plain text")), nil)
+	require.NoError(t, err)
+
+	pngHeader := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}
+	_, err = svc.Upload(context.Background(), buildFileHeader(t, "image.png", pngHeader), nil)
+	require.ErrorIs(t, err, ErrUploadTypeNotAllowed)
+
+	var typeErr *FileTypeError
+	require.ErrorAs(t, err, &typeErr)
+	require.Equal(t, "image/*", typeErr.Detected)
+	require.Equal(t, []string{"text/plain", "application/zip"}, typeErr.Allowed)
+}
+
+// assetSigner signs like Cloudinary but serves asset lookups from memory.
+type assetSigner struct {
+	*cloud.Service
+	assets map[string]cloud.Asset
+}
+
+func (s *assetSigner) Asset(_ context.Context, _ string, publicID string) (cloud.Asset, error) {
+	asset, ok := s.assets[publicID]
+	if !ok {
+		return cloud.Asset{}, cloud.ErrAssetNotFound
+	}
+	return asset, nil
+}
+
+func newDirectUploadService(t *testing.T, repo *uploadRepoStub, now time.Time, assets ...cloud.Asset) *uploadService {
+	t.Helper()
+	service, err := cloud.New(cloud.Config{CloudName: "demo", APIKey: "key", APISecret: "secret", Folder: "gema/uploads"}, testLogger())
+	require.NoError(t, err)
+	signer := &assetSigner{Service: service, assets: map[string]cloud.Asset{}}
+	for _, asset := range assets {
+		signer.assets[asset.PublicID] = asset
+	}
+
+	svc := NewUploadService(&storageStub{}, repo, 5, nil, DirectUploadConfig{Signer: signer, MaxMB: 50}, validator.New(), testLogger()).(*uploadService)
+	svc.clock = clock.NewFixed(now)
+	return svc
+}
+
+func cloudinaryResponseSignature(publicID, version string) string {
+	sum := sha1.Sum([]byte("public_id=" + publicID + "&version=" + version + "secret"))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUploadServiceSignDirect(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	svc := newDirectUploadService(t, &uploadRepoStub{}, now)
+	userID := uint(7)
+
+	signed, err := svc.SignDirect(context.Background(), dto.UploadSignRequest{FileName: "Final Report.pdf", SizeBytes: 40 * 1024 * 1024}, &userID)
+	require.NoError(t, err)
+	require.Equal(t, "https://api.cloudinary.com/v1_1/demo/auto/upload", signed.UploadURL)
+	require.Equal(t, "key", signed.APIKey)
+	require.Equal(t, "gema/uploads", signed.Folder)
+	require.Equal(t, now.Unix(), signed.Timestamp)
+	require.Regexp(t, `^u7-final-report-[0-9a-f]{12}$`, signed.PublicID)
+	require.Len(t, signed.Signature, 40)
+	require.Equal(t, now.Add(time.Hour), signed.ExpiresAt)
+	require.Equal(t, int64(50*1024*1024), signed.MaxFileSize)
+	require.Contains(t, strings.Split(signed.AllowedFormats, ","), "pdf")
+	require.Contains(t, strings.Split(signed.AllowedFormats, ","), "zip")
+	require.NotContains(t, strings.Split(signed.AllowedFormats, ","), "txt")
+	require.Equal(t, "a_exif", signed.Transformation, "images are stored without their metadata")
+
+	_, err = svc.SignDirect(context.Background(), dto.UploadSignRequest{FileName: "huge.zip", SizeBytes: 60 * 1024 * 1024}, &userID)
+	var sizeErr *SizeLimitError
+	require.ErrorAs(t, err, &sizeErr)
+	require.Equal(t, SizeLimitScopeDirect, sizeErr.Scope)
+
+	_, err = svc.SignDirect(context.Background(), dto.UploadSignRequest{FileName: "notes.txt", SizeBytes: 10}, &userID)
+	require.ErrorIs(t, err, ErrUploadTypeNotAllowed)
+}
+
+func TestUploadServiceConfirmDirect(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	repo := &uploadRepoStub{}
+	publicID := "gema/uploads/u7-final-report-0123456789ab"
+	version := "1714557590"
+	asset := cloud.Asset{
+		PublicID:     publicID,
+		Version:      1714557590,
+		ResourceType: "image",
+		Format:       "pdf",
+		Bytes:        40 * 1024 * 1024,
+		Etag:         "D41D8CD98F00B204E9800998ECF8427E",
+		SecureURL:    "https://res.cloudinary.com/demo/image/upload/v" + version + "/" + publicID + ".pdf",
+	}
+	svc := newDirectUploadService(t, repo, now, asset)
+	userID := uint(7)
+
+	req := dto.UploadConfirmRequest{
+		PublicID:         publicID,
+		Version:          1714557590,
+		Signature:        cloudinaryResponseSignature(publicID, version),
+		ResourceType:     "image",
+		OriginalFilename: "Final Report",
+	}
+
+	resp, err := svc.ConfirmDirect(context.Background(), req, &userID)
+	require.NoError(t, err)
+	require.Equal(t, asset.SecureURL, resp.URL)
+	require.Equal(t, "application/pdf", resp.MimeType)
+	require.Equal(t, "final-report.pdf", resp.FileName)
+	require.Equal(t, asset.Bytes, repo.record.SizeBytes)
+	require.Equal(t, "md5:d41d8cd98f00b204e9800998ecf8427e", repo.record.Checksum)
+	require.Equal(t, userID, *repo.record.UserID)
+
+	otherUser := uint(8)
+	_, err = svc.ConfirmDirect(context.Background(), req, &otherUser)
+	require.ErrorIs(t, err, ErrDirectUploadNotOwned)
+
+	tampered := req
+	tampered.Signature = cloudinaryResponseSignature(publicID, "1714557591")
+	_, err = svc.ConfirmDirect(context.Background(), tampered, &userID)
+	require.ErrorIs(t, err, ErrDirectUploadInvalid)
+
+	stale := newDirectUploadService(t, repo, now.Add(2*time.Hour), asset)
+	_, err = stale.ConfirmDirect(context.Background(), req, &userID)
+	require.ErrorIs(t, err, ErrDirectUploadInvalid)
+
+	// The size and type come from Cloudinary, not from the confirmation.
+	oversized := asset
+	oversized.Bytes = 60 * 1024 * 1024
+	_, err = newDirectUploadService(t, repo, now, oversized).ConfirmDirect(context.Background(), req, &userID)
+	var sizeErr *SizeLimitError
+	require.ErrorAs(t, err, &sizeErr)
+
+	executable := asset
+	executable.Format = "exe"
+	executable.ResourceType = "raw"
+	_, err = newDirectUploadService(t, repo, now, executable).ConfirmDirect(context.Background(), req, &userID)
+	require.ErrorIs(t, err, ErrUploadTypeNotAllowed)
+
+	replaced := asset
+	replaced.Version = 1714557599
+	_, err = newDirectUploadService(t, repo, now, replaced).ConfirmDirect(context.Background(), req, &userID)
+	require.ErrorIs(t, err, ErrDirectUploadInvalid)
+
+	_, err = newDirectUploadService(t, repo, now).ConfirmDirect(context.Background(), req, &userID)
+	require.ErrorIs(t, err, ErrDirectUploadInvalid)
+}
+
+func TestUploadServiceDirectDisabled(t *testing.T) {
+	svc := NewUploadService(&storageStub{}, &uploadRepoStub{}, 5, nil, DirectUploadConfig{}, validator.New(), testLogger())
+
+	_, err := svc.SignDirect(context.Background(), dto.UploadSignRequest{FileName: "report.pdf", SizeBytes: 10}, nil)
+	require.ErrorIs(t, err, ErrDirectUploadDisabled)
+}
+
+func TestNormalizeMime(t *testing.T) {
+	require.Equal(t, "image", normalizeMime("image/*"))
+	require.Equal(t, "image", normalizeMime(" IMAGE/PNG "))
+	require.Equal(t, "text/plain", normalizeMime("text/plain; charset=utf-8"))
+	require.Equal(t, "application/zip", normalizeMime("application/x-zip-compressed"))
+}
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
 }
 
 func buildFileHeader(t *testing.T, filename string, content []byte) *multipart.FileHeader {
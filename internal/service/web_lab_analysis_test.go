@@ -0,0 +1,139 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditHTML(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  string
+		penalty  float64
+		findings []string
+	}{
+		{
+			name:    "clean page",
+			content: `<html lang="id"><head><title>Profil</title></head><body><img src="a.png" alt="Logo"><img src="b.png" alt=""/></body></html>`,
+		},
+		{
+			name:     "images without alt",
+			content:  `<html lang="id"><head><title>Profil</title></head><body><img src="a.png"><IMG SRC="b.png" data-alt="x" /></body></html>`,
+			penalty:  2 * htmlMissingAltPenalty,
+			findings: []string{"index.html: 2 tag <img> tanpa atribut alt"},
+		},
+		{
+			name:     "missing alt is capped",
+			content:  `<html lang="id"><head><title>Profil</title></head><body><img src="a"><img src="b"><img src="c"><img src="d"></body></html>`,
+			penalty:  htmlMissingAltMaxPenalty,
+			findings: []string{"index.html: 4 tag <img> tanpa atribut alt"},
+		},
+		{
+			name:     "inline styles",
+			content:  `<html lang="id"><head><title>Profil</title><style>p{}</style></head><body><p style="color:red">a</p><div class="x" style='margin:0'>b</div></body></html>`,
+			penalty:  2 * htmlInlineStylePenalty,
+			findings: []string{"index.html: 2 atribut style inline, pindahkan ke berkas CSS"},
+		},
+		{
+			name:     "inline styles are capped",
+			content:  `<html lang="id"><head><title>Profil</title></head><body><p style="a"></p><p style="a"></p><p style="a"></p><p style="a"></p><p style="a"></p><p style="a"></p></body></html>`,
+			penalty:  htmlInlineStyleMaxPenalty,
+			findings: []string{"index.html: 6 atribut style inline, pindahkan ke berkas CSS"},
+		},
+		{
+			name:     "inline onclick handlers",
+			content:  `<html lang="id"><head><title>Profil</title></head><body><button onclick="go()">Go</button><a href="#" ONCLICK = "back()">Back</a></body></html>`,
+			penalty:  2 * htmlInlineHandlerPenalty,
+			findings: []string{"index.html: 2 handler onclick inline, gunakan addEventListener di JavaScript"},
+		},
+		{
+			name:     "missing title",
+			content:  `<html lang="id"><head></head><body>Hai</body></html>`,
+			penalty:  htmlMissingTitlePenalty,
+			findings: []string{"index.html: tag <title> tidak ditemukan atau kosong"},
+		},
+		{
+			name:     "empty title",
+			content:  `<html lang="id"><head><title>  </title></head><body>Hai</body></html>`,
+			penalty:  htmlMissingTitlePenalty,
+			findings: []string{"index.html: tag <title> tidak ditemukan atau kosong"},
+		},
+		{
+			name:     "missing lang",
+			content:  `<html><head><title>Profil</title></head><body>Hai</body></html>`,
+			penalty:  htmlMissingLangPenalty,
+			findings: []string{"index.html: atribut lang pada tag <html> tidak ditemukan"},
+		},
+		{
+			name:     "empty lang",
+			content:  `<html lang=""><head><title>Profil</title></head><body>Hai</body></html>`,
+			penalty:  htmlMissingLangPenalty,
+			findings: []string{"index.html: atribut lang pada tag <html> tidak ditemukan"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			findings, penalty := auditHTML("index.html", tc.content)
+			require.Equal(t, tc.findings, findings)
+			require.InDelta(t, tc.penalty, penalty, 1e-9)
+		})
+	}
+}
+
+func TestAnalyzeWebArchiveAppliesHTMLAudit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for name, content := range map[string]string{
+		"index.html": `<html><head><title>Beranda</title></head><body><img src="logo.png"><p style="color:red" onclick="hi()">Hai</p></body></html>`,
+		"style.css":  "body { margin: 0; }",
+		"app.js":     "console.log('ok')",
+	} {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	analysis, err := analyzeWebArchive(buf.Bytes(), nil, WebArchiveLimits{})
+	require.NoError(t, err)
+
+	want := 100 - htmlMissingAltPenalty - htmlInlineStylePenalty - htmlInlineHandlerPenalty - htmlMissingLangPenalty
+	require.InDelta(t, want, analysis.score, 1e-9)
+	require.Contains(t, analysis.feedback, "index.html: 1 tag <img> tanpa atribut alt")
+	require.Contains(t, analysis.feedback, "index.html: atribut lang pada tag <html> tidak ditemukan")
+	require.Contains(t, analysis.feedback, "Perkiraan skor Lighthouse: 85/100")
+}
+
+func TestAnalyzeWebArchiveEnforcesArchiveLimits(t *testing.T) {
+	files := map[string]string{
+		"index.html": `<html lang="id"><head><title>Beranda</title></head><body>Hai</body></html>`,
+		"style.css":  "body { margin: 0; }",
+		"app.js":     "console.log('ok')",
+	}
+
+	_, err := analyzeWebArchive(zipArchive(t, files), nil, WebArchiveLimits{MaxEntries: 2})
+	require.ErrorIs(t, err, ErrWebSubmissionTooManyFiles)
+	require.ErrorContains(t, err, "3 entries, the limit is 2")
+
+	_, err = analyzeWebArchive(zipArchive(t, files), nil, WebArchiveLimits{MaxEntries: 3})
+	require.NoError(t, err)
+
+	files["bundle.js"] = strings.Repeat("a", int(bytesPerMB)+1)
+	_, err = analyzeWebArchive(zipArchive(t, files), nil, WebArchiveLimits{MaxFileMB: 1})
+	require.ErrorIs(t, err, ErrWebSubmissionFileTooLarge)
+	require.ErrorContains(t, err, "bundle.js expands past the 1 MB limit")
+}
+
+func TestWebArchiveLimitsForArchive(t *testing.T) {
+	limits := WebArchiveLimits{}.forArchive(10 * bytesPerMB)
+	require.Equal(t, WebArchiveLimits{MaxEntries: defaultWebArchiveEntries, MaxFileMB: 10}, limits)
+
+	limits = WebArchiveLimits{MaxEntries: 50, MaxFileMB: 2}.forArchive(10 * bytesPerMB)
+	require.Equal(t, WebArchiveLimits{MaxEntries: 50, MaxFileMB: 2}, limits)
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+// archiveHost stores uploads in memory and serves them back over HTTP, standing
+// in for the CDN holding submitted archives.
+type archiveHost struct {
+	mu     sync.Mutex
+	files  map[string][]byte
+	server *httptest.Server
+}
+
+func newArchiveHost(t *testing.T) *archiveHost {
+	t.Helper()
+	host := &archiveHost{files: map[string][]byte{}}
+	host.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host.mu.Lock()
+		data, ok := host.files[strings.TrimPrefix(r.URL.Path, "/")]
+		host.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(host.server.Close)
+	return host
+}
+
+func (h *archiveHost) put(name string, data []byte) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.files[name] = data
+	return h.server.URL + "/" + name
+}
+
+func (h *archiveHost) Upload(_ context.Context, name string, reader io.Reader) (string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return h.put(name, data), nil
+}
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func setupWebLabRegrade(t *testing.T) (*gorm.DB, WebLabService, *archiveHost, *stubActivityRecorder, models.WebSubmission) {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:web_regrade_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Student{}, &models.WebAssignment{}, &models.WebSubmission{}))
+
+	student := models.Student{Name: "Sari", Email: "sari@example.com"}
+	require.NoError(t, db.Create(&student).Error)
+	assignment := models.WebAssignment{Title: "Portofolio"}
+	require.NoError(t, db.Create(&assignment).Error)
+
+	host := newArchiveHost(t)
+	activity := &stubActivityRecorder{}
+	svc := NewWebLabService(
+		repository.NewWebAssignmentRepository(db),
+		repository.NewWebSubmissionRepository(db),
+		repository.NewStudentRepository(db),
+		validator.New(validator.WithRequiredStructEnabled()),
+		host,
+		SubmissionSizeLimits{},
+		WebArchiveLimits{},
+		activity,
+		testLogger(),
+	)
+
+	archive := zipArchive(t, map[string]string{
+		"index.html": `<html lang="id"><head><title>Portofolio</title></head><body>Hai</body></html>`,
+		"style.css":  "body { margin: 0; }",
+		"app.js":     "console.log('hai')",
+	})
+	score := 100.0
+	submission := models.WebSubmission{
+		AssignmentID: assignment.ID,
+		StudentID:    student.ID,
+		ZipURL:       host.put("submission.zip", archive),
+		Status:       models.WebSubmissionStatusValidated,
+		Feedback:     "ok",
+		Score:        &score,
+		Checksum:     archiveChecksum(archive),
+	}
+	require.NoError(t, db.Create(&submission).Error)
+
+	return db, svc, host, activity, submission
+}
+
+func TestWebLabServiceRegradeAppliesCurrentReference(t *testing.T) {
+	db, svc, host, activity, submission := setupWebLabRegrade(t)
+
+	reference := zipArchive(t, map[string]string{"index.html": "", "about.html": "", "style.css": "", "app.js": ""})
+	require.NoError(t, db.Model(&models.WebAssignment{}).Where("id = ?", submission.AssignmentID).
+		Update("reference_zip_url", host.put("reference.zip", reference)).Error)
+
+	result, err := svc.Regrade(context.Background(), submission.ID, ActivityActor{ID: 9, Role: "teacher"})
+	require.NoError(t, err)
+	require.Equal(t, 100.0, *result.PreviousScore)
+	require.Equal(t, 100-referenceMissingPenalty, *result.Submission.Score)
+	require.Contains(t, result.Submission.Feedback, "Berkas referensi tidak ditemukan: about.html")
+
+	var stored models.WebSubmission
+	require.NoError(t, db.First(&stored, submission.ID).Error)
+	require.Equal(t, 100-referenceMissingPenalty, *stored.Score)
+
+	require.Len(t, activity.entries, 1)
+	entry := activity.entries[0]
+	require.Equal(t, "web_submission.regraded", entry.Action)
+	require.Equal(t, uint(9), entry.ActorID)
+	require.Equal(t, 100.0, *entry.Metadata["previous_score"].(*float64))
+	require.Equal(t, 100-referenceMissingPenalty, entry.Metadata["score"])
+}
+
+func TestWebLabServiceRegradeRejectsChangedOrMissingArchive(t *testing.T) {
+	db, svc, host, activity, submission := setupWebLabRegrade(t)
+	ctx := context.Background()
+
+	host.put("submission.zip", zipArchive(t, map[string]string{"index.html": "<html></html>"}))
+	_, err := svc.Regrade(ctx, submission.ID, ActivityActor{})
+	require.ErrorIs(t, err, ErrWebSubmissionArchiveChanged)
+
+	require.NoError(t, db.Model(&submission).Update("zip_url", host.server.URL+"/missing.zip").Error)
+	_, err = svc.Regrade(ctx, submission.ID, ActivityActor{})
+	require.ErrorIs(t, err, ErrWebSubmissionArchiveUnavailable)
+
+	_, err = svc.Regrade(ctx, submission.ID+100, ActivityActor{})
+	require.ErrorIs(t, err, ErrWebSubmissionNotFound)
+
+	var stored models.WebSubmission
+	require.NoError(t, db.First(&stored, submission.ID).Error)
+	require.Equal(t, 100.0, *stored.Score)
+	require.Empty(t, activity.entries)
+}
+
+func TestWebLabServiceRegradeBackfillsChecksum(t *testing.T) {
+	db, svc, _, _, submission := setupWebLabRegrade(t)
+	checksum := submission.Checksum
+	require.NoError(t, db.Model(&submission).Update("checksum", "").Error)
+
+	_, err := svc.Regrade(context.Background(), submission.ID, ActivityActor{})
+	require.NoError(t, err)
+
+	var stored models.WebSubmission
+	require.NoError(t, db.First(&stored, submission.ID).Error)
+	require.Equal(t, checksum, stored.Checksum)
+}
@@ -4,14 +4,21 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"math"
 	"mime/multipart"
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/go-playground/validator/v10"
@@ -19,11 +26,45 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/logging"
 	"github.com/noah-isme/gema-go-api/internal/models"
 	"github.com/noah-isme/gema-go-api/internal/repository"
 )
 
-const maxWebSubmissionBytes int64 = 10 * 1024 * 1024
+const (
+	maxWebSubmissionBytes   int64 = 10 * 1024 * 1024
+	referenceFetchTimeout         = 15 * time.Second
+	referenceMissingPenalty       = 10.0
+	referenceMovedPenalty         = 5.0
+	referenceMaxPenalty           = 50.0
+)
+
+// HTML audit weights deducted from the score for each HTML file. Counted
+// findings cost their weight per occurrence up to the matching cap, so one
+// noisy page cannot zero the score on its own: an <img> without alt costs 5
+// (max 15), an element with an inline style 2 (max 10) and an inline onclick
+// handler 3 (max 9). A missing or empty <title> and an <html> tag without lang
+// cost 5 each.
+const (
+	htmlMissingAltPenalty       = 5.0
+	htmlMissingAltMaxPenalty    = 15.0
+	htmlInlineStylePenalty      = 2.0
+	htmlInlineStyleMaxPenalty   = 10.0
+	htmlInlineHandlerPenalty    = 3.0
+	htmlInlineHandlerMaxPenalty = 9.0
+	htmlMissingTitlePenalty     = 5.0
+	htmlMissingLangPenalty      = 5.0
+)
+
+var (
+	htmlImgTagPattern    = regexp.MustCompile(`(?is)<img\b[^>]*>`)
+	htmlAltAttrPattern   = regexp.MustCompile(`(?is)\salt(\s*=|[\s/>])`)
+	htmlStyleAttrPattern = regexp.MustCompile(`(?is)<[a-z][^>]*?\sstyle\s*=`)
+	htmlOnclickPattern   = regexp.MustCompile(`(?is)<[a-z][^>]*?\sonclick\s*=`)
+	htmlTitlePattern     = regexp.MustCompile(`(?is)<title\b[^>]*>\s*[^<\s]`)
+	htmlTagPattern       = regexp.MustCompile(`(?is)<html\b[^>]*>`)
+	htmlLangAttrPattern  = regexp.MustCompile(`(?is)\slang\s*=\s*["']?[a-z]`)
+)
 
 var (
 	// ErrWebAssignmentNotFound indicates the assignment does not exist.
@@ -34,19 +75,55 @@ var (
 	ErrWebSubmissionFileRequired = errors.New("submission file is required")
 	// ErrWebSubmissionUnsupportedType is returned when the upload is not a valid ZIP file.
 	ErrWebSubmissionUnsupportedType = errors.New("submission file must be a ZIP archive")
-	// ErrWebSubmissionTooLarge is returned when the upload exceeds the 10 MB limit.
-	ErrWebSubmissionTooLarge = errors.New("submission exceeds the 10 MB limit")
+	// ErrWebSubmissionTooLarge is returned when the upload exceeds the applicable size limit.
+	ErrWebSubmissionTooLarge = errors.New("submission exceeds the size limit")
 	// ErrWebSubmissionInvalidArchive signals that the zip archive could not be read.
 	ErrWebSubmissionInvalidArchive = errors.New("submission archive is invalid or corrupted")
 	// ErrWebSubmissionDangerousFile indicates the archive contains disallowed content.
 	ErrWebSubmissionDangerousFile = errors.New("submission archive contains disallowed files")
+	// ErrWebSubmissionTooManyFiles is returned when the archive holds more entries than allowed.
+	ErrWebSubmissionTooManyFiles = errors.New("submission archive contains too many files")
+	// ErrWebSubmissionFileTooLarge is returned when a file inside the archive expands past the per-file limit.
+	ErrWebSubmissionFileTooLarge = errors.New("submission archive contains a file that is too large")
+	// ErrWebSubmissionNotFound indicates the submission does not exist.
+	ErrWebSubmissionNotFound = errors.New("web submission not found")
+	// ErrWebSubmissionArchiveUnavailable indicates the stored archive could not be downloaded.
+	ErrWebSubmissionArchiveUnavailable = errors.New("submission archive is unavailable")
+	// ErrWebSubmissionArchiveChanged indicates the stored archive no longer matches the upload.
+	ErrWebSubmissionArchiveChanged = errors.New("submission archive does not match the original upload")
 )
 
+// WebArchiveLimits bounds what analyzeWebArchive is willing to unpack, so a
+// small zip holding thousands of entries or a highly compressed file cannot
+// exhaust the analyzer. MaxEntries defaults to 500; without MaxFileMB no file
+// may expand past the size limit the archive itself was held to.
+type WebArchiveLimits struct {
+	MaxEntries int
+	MaxFileMB  int
+}
+
+const defaultWebArchiveEntries = 500
+
+// forArchive fills in the defaults for an archive accepted under archiveBytes.
+func (l WebArchiveLimits) forArchive(archiveBytes int64) WebArchiveLimits {
+	if l.MaxEntries <= 0 {
+		l.MaxEntries = defaultWebArchiveEntries
+	}
+	if l.MaxFileMB <= 0 {
+		l.MaxFileMB = int((archiveBytes + bytesPerMB - 1) / bytesPerMB)
+	}
+	return l
+}
+
 // WebLabService orchestrates assignment retrieval and submission validation for the web lab.
 type WebLabService interface {
-	ListAssignments(ctx context.Context) ([]dto.WebAssignmentResponse, error)
-	GetAssignment(ctx context.Context, id uint) (dto.WebAssignmentResponse, error)
+	ListAssignments(ctx context.Context, role string) ([]dto.WebAssignmentResponse, error)
+	GetAssignment(ctx context.Context, id uint, role string) (dto.WebAssignmentResponse, error)
+	CreateAssignment(ctx context.Context, payload dto.WebAssignmentCreateRequest, actor ActivityActor) (dto.WebAssignmentResponse, error)
+	UpdateAssignment(ctx context.Context, id uint, payload dto.WebAssignmentUpdateRequest, actor ActivityActor) (dto.WebAssignmentResponse, error)
+	UploadReference(ctx context.Context, id uint, file *multipart.FileHeader, actor ActivityActor) (dto.WebAssignmentResponse, error)
 	CreateSubmission(ctx context.Context, payload dto.WebSubmissionCreateRequest, file *multipart.FileHeader) (dto.WebSubmissionResponse, error)
+	Regrade(ctx context.Context, submissionID uint, actor ActivityActor) (dto.WebSubmissionRegradeResponse, error)
 }
 
 type webLabService struct {
@@ -55,38 +132,59 @@ type webLabService struct {
 	students    repository.StudentRepository
 	validator   *validator.Validate
 	uploader    FileUploader
+	sizeLimits  SubmissionSizeLimits
+	archive     WebArchiveLimits
+	httpClient  *http.Client
+	activity    ActivityRecorder
 	logger      zerolog.Logger
 }
 
-// NewWebLabService constructs a WebLabService implementation.
+// NewWebLabService constructs a WebLabService implementation. A zero
+// limits.DefaultMB falls back to 10 MB; see WebArchiveLimits for the archive
+// defaults.
 func NewWebLabService(
 	assignmentRepo repository.WebAssignmentRepository,
 	submissionRepo repository.WebSubmissionRepository,
 	studentRepo repository.StudentRepository,
 	validate *validator.Validate,
 	uploader FileUploader,
+	limits SubmissionSizeLimits,
+	archive WebArchiveLimits,
+	activity ActivityRecorder,
 	logger zerolog.Logger,
 ) WebLabService {
+	if limits.DefaultMB <= 0 {
+		limits.DefaultMB = int(maxWebSubmissionBytes / bytesPerMB)
+	}
+
 	return &webLabService{
 		assignments: assignmentRepo,
 		submissions: submissionRepo,
 		students:    studentRepo,
 		validator:   validate,
 		uploader:    uploader,
+		sizeLimits:  limits,
+		archive:     archive,
+		httpClient:  &http.Client{Timeout: referenceFetchTimeout},
+		activity:    activity,
 		logger:      logger.With().Str("component", "web_lab_service").Logger(),
 	}
 }
 
-func (s *webLabService) ListAssignments(ctx context.Context) ([]dto.WebAssignmentResponse, error) {
+func (s *webLabService) ListAssignments(ctx context.Context, role string) ([]dto.WebAssignmentResponse, error) {
 	assignments, err := s.assignments.List(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return dto.NewWebAssignmentResponseSlice(assignments), nil
+	responses := dto.NewWebAssignmentResponseSlice(assignments)
+	for i := range responses {
+		responses[i].Limits = s.submissionLimits(assignments[i], role)
+	}
+	return responses, nil
 }
 
-func (s *webLabService) GetAssignment(ctx context.Context, id uint) (dto.WebAssignmentResponse, error) {
+func (s *webLabService) GetAssignment(ctx context.Context, id uint, role string) (dto.WebAssignmentResponse, error) {
 	assignment, err := s.assignments.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -95,7 +193,186 @@ func (s *webLabService) GetAssignment(ctx context.Context, id uint) (dto.WebAssi
 		return dto.WebAssignmentResponse{}, err
 	}
 
-	return dto.NewWebAssignmentResponse(assignment), nil
+	response := dto.NewWebAssignmentResponse(assignment)
+	response.Limits = s.submissionLimits(assignment, role)
+	return response, nil
+}
+
+func (s *webLabService) CreateAssignment(ctx context.Context, payload dto.WebAssignmentCreateRequest, actor ActivityActor) (dto.WebAssignmentResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.WebAssignmentResponse{}, err
+	}
+	if err := s.sizeLimits.checkOverride(payload.MaxSubmissionMB); err != nil {
+		return dto.WebAssignmentResponse{}, err
+	}
+
+	assignment := models.WebAssignment{
+		Title:           strings.TrimSpace(payload.Title),
+		Requirements:    strings.TrimSpace(payload.Requirements),
+		Rubric:          strings.TrimSpace(payload.Rubric),
+		MaxSubmissionMB: payload.MaxSubmissionMB,
+		ReferenceZipURL: strings.TrimSpace(payload.ReferenceZipURL),
+	}
+	assignment.SetAssets(payload.Assets)
+	if err := s.assignments.Create(ctx, &assignment); err != nil {
+		return dto.WebAssignmentResponse{}, err
+	}
+
+	s.recordAssignmentActivity(ctx, actor, "web_assignment.created", assignment, nil)
+
+	response := dto.NewWebAssignmentResponse(assignment)
+	response.Limits = s.submissionLimits(assignment, actor.Role)
+	return response, nil
+}
+
+func (s *webLabService) UpdateAssignment(ctx context.Context, id uint, payload dto.WebAssignmentUpdateRequest, actor ActivityActor) (dto.WebAssignmentResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.WebAssignmentResponse{}, err
+	}
+
+	assignment, err := s.assignments.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.WebAssignmentResponse{}, ErrWebAssignmentNotFound
+		}
+		return dto.WebAssignmentResponse{}, err
+	}
+
+	changedFields := make([]string, 0)
+	if payload.Title != nil {
+		assignment.Title = strings.TrimSpace(*payload.Title)
+		changedFields = append(changedFields, "title")
+	}
+	if payload.Requirements != nil {
+		assignment.Requirements = strings.TrimSpace(*payload.Requirements)
+		changedFields = append(changedFields, "requirements")
+	}
+	if payload.Assets != nil {
+		assignment.SetAssets(payload.Assets)
+		changedFields = append(changedFields, "assets")
+	}
+	if payload.Rubric != nil {
+		assignment.Rubric = strings.TrimSpace(*payload.Rubric)
+		changedFields = append(changedFields, "rubric")
+	}
+	if payload.MaxSubmissionMB != nil {
+		if err := s.sizeLimits.checkOverride(*payload.MaxSubmissionMB); err != nil {
+			return dto.WebAssignmentResponse{}, err
+		}
+		assignment.MaxSubmissionMB = *payload.MaxSubmissionMB
+		changedFields = append(changedFields, "max_submission_mb")
+	}
+	if payload.ReferenceZipURL != nil {
+		reference := strings.TrimSpace(*payload.ReferenceZipURL)
+		if reference != "" {
+			if err := s.validator.Var(reference, "url,startswith=http"); err != nil {
+				return dto.WebAssignmentResponse{}, err
+			}
+		}
+		assignment.ReferenceZipURL = reference
+		changedFields = append(changedFields, "reference_zip_url")
+	}
+
+	if err := s.assignments.Update(ctx, &assignment); err != nil {
+		return dto.WebAssignmentResponse{}, err
+	}
+
+	if len(changedFields) > 0 {
+		s.recordAssignmentActivity(ctx, actor, "web_assignment.updated", assignment, map[string]interface{}{"fields": changedFields})
+	}
+
+	response := dto.NewWebAssignmentResponse(assignment)
+	response.Limits = s.submissionLimits(assignment, actor.Role)
+	return response, nil
+}
+
+// UploadReference stores a reference solution archive for an assignment.
+// Submissions are compared against its file list, so the archive must be a
+// readable zip no larger than the biggest submission the assignment accepts.
+func (s *webLabService) UploadReference(ctx context.Context, id uint, file *multipart.FileHeader, actor ActivityActor) (dto.WebAssignmentResponse, error) {
+	if file == nil {
+		return dto.WebAssignmentResponse{}, ErrWebSubmissionFileRequired
+	}
+
+	assignment, err := s.assignments.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.WebAssignmentResponse{}, ErrWebAssignmentNotFound
+		}
+		return dto.WebAssignmentResponse{}, err
+	}
+
+	// referenceFiles downloads the archive with the ceiling as its limit.
+	limit := s.sizeLimits.resolve(assignment.MaxSubmissionMB, "")
+	limit.bytes = s.sizeLimits.ceiling(assignment.MaxSubmissionMB)
+	if err := limit.check(file.Size, ErrWebSubmissionTooLarge); err != nil {
+		return dto.WebAssignmentResponse{}, err
+	}
+
+	data, err := readMultipartFile(file, limit)
+	if err != nil {
+		return dto.WebAssignmentResponse{}, err
+	}
+	if err := ensureZipArchive(file.Filename, data); err != nil {
+		return dto.WebAssignmentResponse{}, err
+	}
+	files, err := archiveFileList(data)
+	if err != nil {
+		return dto.WebAssignmentResponse{}, err
+	}
+	if len(files) == 0 {
+		return dto.WebAssignmentResponse{}, ErrWebSubmissionInvalidArchive
+	}
+
+	uploadURL, err := s.uploader.Upload(ctx, file.Filename, bytes.NewReader(data))
+	if err != nil {
+		return dto.WebAssignmentResponse{}, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	assignment.ReferenceZipURL = uploadURL
+	if err := s.assignments.Update(ctx, &assignment); err != nil {
+		return dto.WebAssignmentResponse{}, err
+	}
+
+	s.recordAssignmentActivity(ctx, actor, "web_assignment.updated", assignment, map[string]interface{}{
+		"fields":          []string{"reference_zip_url"},
+		"reference_files": len(files),
+	})
+
+	response := dto.NewWebAssignmentResponse(assignment)
+	response.Limits = s.submissionLimits(assignment, actor.Role)
+	return response, nil
+}
+
+func (s *webLabService) recordAssignmentActivity(ctx context.Context, actor ActivityActor, action string, assignment models.WebAssignment, metadata map[string]interface{}) {
+	if s.activity == nil {
+		return
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["max_submission_mb"] = assignment.MaxSubmissionMB
+	_, _ = s.activity.Record(ctx, ActivityEntry{
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+		Action:     action,
+		EntityType: "web_assignment",
+		EntityID:   &assignment.ID,
+		Metadata:   metadata,
+	})
+}
+
+// submissionLimits reports the limits an uploader with role faces, so clients
+// can reject an archive before uploading it.
+func (s *webLabService) submissionLimits(assignment models.WebAssignment, role string) *dto.WebSubmissionLimits {
+	limit := s.sizeLimits.resolve(assignment.MaxSubmissionMB, role)
+	archive := s.archive.forArchive(limit.bytes)
+	return &dto.WebSubmissionLimits{
+		MaxSizeMB:  int(limit.bytes / bytesPerMB),
+		Scope:      limit.scope,
+		MaxEntries: archive.MaxEntries,
+		MaxFileMB:  archive.MaxFileMB,
+	}
 }
 
 func (s *webLabService) CreateSubmission(ctx context.Context, payload dto.WebSubmissionCreateRequest, file *multipart.FileHeader) (dto.WebSubmissionResponse, error) {
@@ -122,11 +399,12 @@ func (s *webLabService) CreateSubmission(ctx context.Context, payload dto.WebSub
 		return dto.WebSubmissionResponse{}, err
 	}
 
-	if file.Size > maxWebSubmissionBytes {
-		return dto.WebSubmissionResponse{}, ErrWebSubmissionTooLarge
+	limit := s.sizeLimits.resolve(assignment.MaxSubmissionMB, payload.Role)
+	if err := limit.check(file.Size, ErrWebSubmissionTooLarge); err != nil {
+		return dto.WebSubmissionResponse{}, err
 	}
 
-	data, err := readMultipartFile(file)
+	data, err := readMultipartFile(file, limit)
 	if err != nil {
 		return dto.WebSubmissionResponse{}, err
 	}
@@ -135,7 +413,7 @@ func (s *webLabService) CreateSubmission(ctx context.Context, payload dto.WebSub
 		return dto.WebSubmissionResponse{}, err
 	}
 
-	analysis, err := analyzeWebArchive(data)
+	analysis, err := analyzeWebArchive(data, s.referenceFiles(ctx, assignment), s.archive.forArchive(limit.bytes))
 	if err != nil {
 		return dto.WebSubmissionResponse{}, err
 	}
@@ -153,6 +431,7 @@ func (s *webLabService) CreateSubmission(ctx context.Context, payload dto.WebSub
 		Status:       models.WebSubmissionStatusValidated,
 		Feedback:     analysis.feedback,
 		Score:        &score,
+		Checksum:     archiveChecksum(data),
 	}
 
 	if err := s.submissions.Create(ctx, &submission); err != nil {
@@ -167,7 +446,7 @@ func (s *webLabService) CreateSubmission(ctx context.Context, payload dto.WebSub
 	// Ensure associations are populated for response consistency.
 	stored.Assignment = assignment
 
-	s.logger.Info().
+	logging.FromContext(ctx, s.logger).Info().
 		Uint("submission_id", stored.ID).
 		Uint("assignment_id", stored.AssignmentID).
 		Uint("student_id", stored.StudentID).
@@ -176,25 +455,246 @@ func (s *webLabService) CreateSubmission(ctx context.Context, payload dto.WebSub
 	return dto.NewWebSubmissionResponse(stored), nil
 }
 
+// Regrade downloads the stored archive again, re-runs the analysis against the
+// current heuristics and reference solution, and saves the new score and
+// feedback. Archives uploaded with a checksum must still match it; older
+// submissions without one have it filled in.
+func (s *webLabService) Regrade(ctx context.Context, submissionID uint, actor ActivityActor) (dto.WebSubmissionRegradeResponse, error) {
+	submission, err := s.submissions.GetByID(ctx, submissionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.WebSubmissionRegradeResponse{}, ErrWebSubmissionNotFound
+		}
+		return dto.WebSubmissionRegradeResponse{}, err
+	}
+
+	ceiling := s.sizeLimits.ceiling(submission.Assignment.MaxSubmissionMB)
+	data, err := s.downloadArchive(ctx, submission.ZipURL, ceiling)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Warn().Err(err).Uint("submission_id", submission.ID).Msg("failed to download submission archive")
+		return dto.WebSubmissionRegradeResponse{}, ErrWebSubmissionArchiveUnavailable
+	}
+
+	checksum := archiveChecksum(data)
+	if submission.Checksum != "" && submission.Checksum != checksum {
+		return dto.WebSubmissionRegradeResponse{}, ErrWebSubmissionArchiveChanged
+	}
+
+	analysis, err := analyzeWebArchive(data, s.referenceFiles(ctx, submission.Assignment), s.archive.forArchive(ceiling))
+	if err != nil {
+		return dto.WebSubmissionRegradeResponse{}, err
+	}
+
+	previous := submission.Score
+	score := math.Round(analysis.score*100) / 100
+	submission.Score = &score
+	submission.Feedback = analysis.feedback
+	submission.Checksum = checksum
+	if err := s.submissions.Update(ctx, &submission); err != nil {
+		return dto.WebSubmissionRegradeResponse{}, err
+	}
+
+	if s.activity != nil {
+		_, _ = s.activity.Record(ctx, ActivityEntry{
+			ActorID:    actor.ID,
+			ActorRole:  actor.Role,
+			Action:     "web_submission.regraded",
+			EntityType: "web_submission",
+			EntityID:   &submission.ID,
+			Metadata: map[string]interface{}{
+				"assignment_id":  submission.AssignmentID,
+				"student_id":     submission.StudentID,
+				"previous_score": previous,
+				"score":          score,
+			},
+		})
+	}
+
+	logging.FromContext(ctx, s.logger).Info().
+		Uint("submission_id", submission.ID).
+		Interface("previous_score", previous).
+		Float64("score", score).
+		Msg("web lab submission regraded")
+
+	return dto.WebSubmissionRegradeResponse{
+		Submission:    dto.NewWebSubmissionResponse(submission),
+		PreviousScore: previous,
+	}, nil
+}
+
+func archiveChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 type archiveAnalysis struct {
 	score    float64
 	feedback string
 }
 
-func readMultipartFile(file *multipart.FileHeader) ([]byte, error) {
+// referenceFiles downloads the assignment's reference solution and lists its
+// files. A missing or unreadable reference disables the comparison rather
+// than failing the submission.
+func (s *webLabService) referenceFiles(ctx context.Context, assignment models.WebAssignment) []string {
+	url := strings.TrimSpace(assignment.ReferenceZipURL)
+	if url == "" {
+		return nil
+	}
+
+	logger := s.logger.With().Uint("assignment_id", assignment.ID).Logger()
+
+	data, err := s.downloadArchive(ctx, url, s.sizeLimits.ceiling(assignment.MaxSubmissionMB))
+	if err != nil {
+		logger.Warn().Err(err).Msg("failed to download reference archive")
+		return nil
+	}
+
+	files, err := archiveFileList(data)
+	if err != nil {
+		logger.Warn().Err(err).Msg("reference archive is invalid")
+		return nil
+	}
+	return files
+}
+
+// downloadArchive fetches an archive of at most limit bytes.
+func (s *webLabService) downloadArchive(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSpace(url), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download rejected with status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrWebSubmissionTooLarge
+	}
+	return data, nil
+}
+
+// archiveFileList returns the normalised paths of every file in the archive.
+func archiveFileList(data []byte) ([]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrWebSubmissionInvalidArchive
+	}
+
+	files := make([]string, 0, len(archive.File))
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		files = append(files, filepath.ToSlash(filepath.Clean(file.Name)))
+	}
+	return stripCommonRoot(files), nil
+}
+
+// stripCommonRoot removes a single top-level folder shared by every path, as
+// produced when zipping a project directory instead of its contents.
+func stripCommonRoot(files []string) []string {
+	if len(files) == 0 {
+		return files
+	}
+
+	root, _, ok := strings.Cut(files[0], "/")
+	if !ok {
+		return files
+	}
+	prefix := root + "/"
+	for _, file := range files[1:] {
+		if !strings.HasPrefix(file, prefix) {
+			return files
+		}
+	}
+
+	stripped := make([]string, len(files))
+	for i, file := range files {
+		stripped[i] = strings.TrimPrefix(file, prefix)
+	}
+	return stripped
+}
+
+// compareWithReference reports files missing from, moved within, or added to
+// the submission relative to the reference solution, plus the score penalty.
+func compareWithReference(submitted, reference []string) ([]string, float64) {
+	present := make(map[string]struct{}, len(submitted))
+	byName := make(map[string][]string)
+	for _, file := range submitted {
+		present[strings.ToLower(file)] = struct{}{}
+		base := strings.ToLower(path.Base(file))
+		byName[base] = append(byName[base], file)
+	}
+
+	expected := make(map[string]struct{}, len(reference))
+	matchedElsewhere := make(map[string]struct{})
+	var findings []string
+	penalty := 0.0
+
+	sorted := append([]string(nil), reference...)
+	sort.Strings(sorted)
+	for _, file := range sorted {
+		key := strings.ToLower(file)
+		expected[key] = struct{}{}
+		if _, ok := present[key]; ok {
+			continue
+		}
+
+		if candidates := byName[strings.ToLower(path.Base(file))]; len(candidates) > 0 {
+			matchedElsewhere[strings.ToLower(candidates[0])] = struct{}{}
+			findings = append(findings, fmt.Sprintf("Struktur berbeda: %s seharusnya berada di %s", candidates[0], file))
+			penalty += referenceMovedPenalty
+			continue
+		}
+
+		findings = append(findings, fmt.Sprintf("Berkas referensi tidak ditemukan: %s", file))
+		penalty += referenceMissingPenalty
+	}
+
+	var extras []string
+	for _, file := range submitted {
+		key := strings.ToLower(file)
+		if _, ok := expected[key]; ok {
+			continue
+		}
+		if _, ok := matchedElsewhere[key]; ok {
+			continue
+		}
+		extras = append(extras, file)
+	}
+	sort.Strings(extras)
+	for _, file := range extras {
+		findings = append(findings, fmt.Sprintf("Berkas tambahan di luar referensi: %s", file))
+	}
+
+	return findings, math.Min(penalty, referenceMaxPenalty)
+}
+
+func readMultipartFile(file *multipart.FileHeader, limit sizeLimit) ([]byte, error) {
 	src, err := file.Open()
 	if err != nil {
 		return nil, fmt.Errorf("failed to open submission: %w", err)
 	}
 	defer src.Close()
 
-	data, err := io.ReadAll(io.LimitReader(src, maxWebSubmissionBytes+1))
+	data, err := io.ReadAll(io.LimitReader(src, limit.bytes+1))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read submission: %w", err)
 	}
 
-	if int64(len(data)) > maxWebSubmissionBytes {
-		return nil, ErrWebSubmissionTooLarge
+	if err := limit.check(int64(len(data)), ErrWebSubmissionTooLarge); err != nil {
+		return nil, err
 	}
 
 	if len(data) == 0 {
@@ -217,7 +717,8 @@ func ensureZipArchive(filename string, data []byte) error {
 	return nil
 }
 
-func analyzeWebArchive(data []byte) (archiveAnalysis, error) {
+func analyzeWebArchive(data []byte, reference []string, limits WebArchiveLimits) (archiveAnalysis, error) {
+	limits = limits.forArchive(maxWebSubmissionBytes)
 	readerAt := bytes.NewReader(data)
 	archive, err := zip.NewReader(readerAt, int64(len(data)))
 	if err != nil {
@@ -227,9 +728,15 @@ func analyzeWebArchive(data []byte) (archiveAnalysis, error) {
 	if len(archive.File) == 0 {
 		return archiveAnalysis{}, ErrWebSubmissionInvalidArchive
 	}
+	if len(archive.File) > limits.MaxEntries {
+		return archiveAnalysis{}, fmt.Errorf("%w: %d entries, the limit is %d", ErrWebSubmissionTooManyFiles, len(archive.File), limits.MaxEntries)
+	}
+	maxFileBytes := int64(limits.MaxFileMB) * bytesPerMB
 
 	var htmlFiles, cssFiles, jsFiles int
-	var issues []string
+	var issues, audit []string
+	var auditPenalty float64
+	var submitted []string
 
 	for _, file := range archive.File {
 		if err := validateZipEntry(file); err != nil {
@@ -240,16 +747,27 @@ func analyzeWebArchive(data []byte) (archiveAnalysis, error) {
 			continue
 		}
 
-		content, err := readZipFile(file)
+		if file.UncompressedSize64 > uint64(maxFileBytes) {
+			return archiveAnalysis{}, fileTooLargeError(file.Name, maxFileBytes)
+		}
+
+		content, err := readZipFile(file, maxFileBytes)
+		if errors.Is(err, ErrWebSubmissionFileTooLarge) {
+			return archiveAnalysis{}, fileTooLargeError(file.Name, maxFileBytes)
+		}
 		if err != nil {
 			return archiveAnalysis{}, ErrWebSubmissionInvalidArchive
 		}
+		submitted = append(submitted, filepath.ToSlash(filepath.Clean(file.Name)))
 
 		lower := strings.ToLower(file.Name)
 		switch {
 		case strings.HasSuffix(lower, ".html") || strings.HasSuffix(lower, ".htm"):
 			htmlFiles++
 			issues = append(issues, lintHTML(file.Name, content)...)
+			findings, penalty := auditHTML(file.Name, content)
+			audit = append(audit, findings...)
+			auditPenalty += penalty
 		case strings.HasSuffix(lower, ".css"):
 			cssFiles++
 			issues = append(issues, lintCSS(file.Name, content)...)
@@ -281,6 +799,15 @@ func analyzeWebArchive(data []byte) (archiveAnalysis, error) {
 		feedback = append(feedback, issues...)
 	}
 
+	score -= auditPenalty
+	feedback = append(feedback, audit...)
+
+	if len(reference) > 0 {
+		findings, penalty := compareWithReference(stripCommonRoot(submitted), reference)
+		score -= penalty
+		feedback = append(feedback, findings...)
+	}
+
 	score = math.Max(score, 0)
 
 	if len(feedback) == 0 {
@@ -310,21 +837,30 @@ func validateZipEntry(file *zip.File) error {
 	return nil
 }
 
-func readZipFile(file *zip.File) (string, error) {
+// readZipFile reads at most limit bytes from file, so an entry whose header
+// understates its size still cannot expand without bound.
+func readZipFile(file *zip.File, limit int64) (string, error) {
 	reader, err := file.Open()
 	if err != nil {
 		return "", err
 	}
 	defer reader.Close()
 
-	data, err := io.ReadAll(reader)
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
 	if err != nil {
 		return "", err
 	}
+	if int64(len(data)) > limit {
+		return "", ErrWebSubmissionFileTooLarge
+	}
 
 	return string(data), nil
 }
 
+func fileTooLargeError(name string, limit int64) error {
+	return fmt.Errorf("%w: %s expands past the %s limit", ErrWebSubmissionFileTooLarge, name, formatMB(limit))
+}
+
 func lintHTML(name, content string) []string {
 	lower := strings.ToLower(content)
 	var issues []string
@@ -340,6 +876,46 @@ func lintHTML(name, content string) []string {
 	return issues
 }
 
+// auditHTML applies the accessibility and code-quality heuristics to one HTML
+// file, returning its findings and the score penalty they carry.
+func auditHTML(name, content string) ([]string, float64) {
+	var findings []string
+	penalty := 0.0
+
+	missingAlt := 0
+	for _, tag := range htmlImgTagPattern.FindAllString(content, -1) {
+		if !htmlAltAttrPattern.MatchString(tag) {
+			missingAlt++
+		}
+	}
+	if missingAlt > 0 {
+		findings = append(findings, fmt.Sprintf("%s: %d tag <img> tanpa atribut alt", name, missingAlt))
+		penalty += math.Min(float64(missingAlt)*htmlMissingAltPenalty, htmlMissingAltMaxPenalty)
+	}
+
+	if styles := len(htmlStyleAttrPattern.FindAllStringIndex(content, -1)); styles > 0 {
+		findings = append(findings, fmt.Sprintf("%s: %d atribut style inline, pindahkan ke berkas CSS", name, styles))
+		penalty += math.Min(float64(styles)*htmlInlineStylePenalty, htmlInlineStyleMaxPenalty)
+	}
+
+	if handlers := len(htmlOnclickPattern.FindAllStringIndex(content, -1)); handlers > 0 {
+		findings = append(findings, fmt.Sprintf("%s: %d handler onclick inline, gunakan addEventListener di JavaScript", name, handlers))
+		penalty += math.Min(float64(handlers)*htmlInlineHandlerPenalty, htmlInlineHandlerMaxPenalty)
+	}
+
+	if !htmlTitlePattern.MatchString(content) {
+		findings = append(findings, fmt.Sprintf("%s: tag <title> tidak ditemukan atau kosong", name))
+		penalty += htmlMissingTitlePenalty
+	}
+
+	if tag := htmlTagPattern.FindString(content); tag != "" && !htmlLangAttrPattern.MatchString(tag) {
+		findings = append(findings, fmt.Sprintf("%s: atribut lang pada tag <html> tidak ditemukan", name))
+		penalty += htmlMissingLangPenalty
+	}
+
+	return findings, penalty
+}
+
 func lintCSS(name, content string) []string {
 	trimmed := strings.TrimSpace(content)
 	if trimmed == "" {
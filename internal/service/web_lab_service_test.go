@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
@@ -33,6 +34,11 @@ func (u *webLabTestUploader) Upload(_ context.Context, name string, _ io.Reader)
 
 func setupWebLabService(t *testing.T) (service.WebLabService, *gorm.DB, models.Student, models.WebAssignment) {
 	t.Helper()
+	return setupWebLabServiceWithLimits(t, service.SubmissionSizeLimits{})
+}
+
+func setupWebLabServiceWithLimits(t *testing.T, limits service.SubmissionSizeLimits) (service.WebLabService, *gorm.DB, models.Student, models.WebAssignment) {
+	t.Helper()
 
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	require.NoError(t, err)
@@ -55,6 +61,9 @@ func setupWebLabService(t *testing.T) (service.WebLabService, *gorm.DB, models.S
 		repository.NewStudentRepository(db),
 		validate,
 		uploader,
+		limits,
+		service.WebArchiveLimits{},
+		nil,
 		logger,
 	)
 
@@ -113,6 +122,115 @@ func TestWebLabService_CreateSubmission_TooLarge(t *testing.T) {
 
 	_, err = svc.CreateSubmission(context.Background(), payload, file)
 	require.ErrorIs(t, err, service.ErrWebSubmissionTooLarge)
+
+	var sizeErr *service.SizeLimitError
+	require.ErrorAs(t, err, &sizeErr)
+	require.Equal(t, service.SizeLimitScopeDefault, sizeErr.Scope)
+	require.Equal(t, int64(10*1024*1024), sizeErr.Limit)
+	require.Contains(t, err.Error(), "10 MB default limit")
+}
+
+func TestWebLabService_GetAssignment_ReportsLimits(t *testing.T) {
+	svc, _, _, assignment := setupWebLabServiceWithLimits(t, service.SubmissionSizeLimits{RoleMB: map[string]int{"teacher": 25}})
+
+	response, err := svc.GetAssignment(context.Background(), assignment.ID, "student")
+	require.NoError(t, err)
+	require.Equal(t, &dto.WebSubmissionLimits{MaxSizeMB: 10, Scope: service.SizeLimitScopeDefault, MaxEntries: 500, MaxFileMB: 10}, response.Limits)
+
+	listed, err := svc.ListAssignments(context.Background(), "teacher")
+	require.NoError(t, err)
+	require.NotEmpty(t, listed)
+	require.Equal(t, &dto.WebSubmissionLimits{MaxSizeMB: 25, Scope: service.SizeLimitScopeRole, MaxEntries: 500, MaxFileMB: 25}, listed[0].Limits)
+}
+
+func TestWebLabService_CreateSubmission_TooManyFiles(t *testing.T) {
+	svc, _, student, assignment := setupWebLabService(t)
+
+	entries := make([]zipEntry, 0, 501)
+	for i := 0; i <= 500; i++ {
+		entries = append(entries, zipEntry{Name: fmt.Sprintf("assets/%d.txt", i), Content: []byte("x")})
+	}
+	file := fileHeaderFromBytes(t, "submission.zip", buildZip(t, entries))
+	payload := dto.WebSubmissionCreateRequest{AssignmentID: assignment.ID, StudentID: student.ID}
+
+	_, err := svc.CreateSubmission(context.Background(), payload, file)
+	require.ErrorIs(t, err, service.ErrWebSubmissionTooManyFiles)
+	require.Contains(t, err.Error(), "501 entries, the limit is 500")
+}
+
+func TestWebLabService_CreateSubmission_AssignmentLimitOverride(t *testing.T) {
+	svc, db, student, assignment := setupWebLabServiceWithLimits(t, service.SubmissionSizeLimits{RoleMB: map[string]int{"teacher": 1}})
+
+	large := make([]byte, 11*1024*1024)
+	_, err := rand.Read(large)
+	require.NoError(t, err)
+	zipBytes := buildZip(t, []zipEntry{
+		{Name: "index.html", Content: []byte("<html><body>Hello</body></html>")},
+		{Name: "large.bin", Content: large, Method: zip.Store},
+	})
+
+	require.NoError(t, db.Model(&assignment).Update("max_submission_mb", 20).Error)
+
+	// The assignment override wins over both the role and the default limit.
+	payload := dto.WebSubmissionCreateRequest{AssignmentID: assignment.ID, StudentID: student.ID, Role: "teacher"}
+	_, err = svc.CreateSubmission(context.Background(), payload, fileHeaderFromBytes(t, "submission.zip", zipBytes))
+	require.NoError(t, err)
+}
+
+func TestWebLabService_AssignmentCreateAndUpdate(t *testing.T) {
+	svc, _, _, _ := setupWebLabServiceWithLimits(t, service.SubmissionSizeLimits{AssignmentMaxMB: 50})
+	actor := service.ActivityActor{ID: 1, Role: "teacher"}
+
+	created, err := svc.CreateAssignment(context.Background(), dto.WebAssignmentCreateRequest{
+		Title:           "Portfolio",
+		Requirements:    "Build a portfolio page",
+		Assets:          []string{"assets/brief.pdf"},
+		MaxSubmissionMB: 25,
+	}, actor)
+	require.NoError(t, err)
+	require.Equal(t, []string{"assets/brief.pdf"}, created.Assets)
+	require.NotNil(t, created.Limits)
+	require.Equal(t, 25, created.Limits.MaxSizeMB)
+	require.Equal(t, service.SizeLimitScopeAssignment, created.Limits.Scope)
+
+	_, err = svc.CreateAssignment(context.Background(), dto.WebAssignmentCreateRequest{Title: "Too big", MaxSubmissionMB: 51}, actor)
+	require.ErrorIs(t, err, service.ErrAssignmentSizeLimitTooHigh)
+
+	limit := 40
+	title := "Portfolio v2"
+	updated, err := svc.UpdateAssignment(context.Background(), created.ID, dto.WebAssignmentUpdateRequest{Title: &title, MaxSubmissionMB: &limit}, actor)
+	require.NoError(t, err)
+	require.Equal(t, title, updated.Title)
+	require.Equal(t, 40, updated.Limits.MaxSizeMB)
+
+	fetched, err := svc.GetAssignment(context.Background(), created.ID, "student")
+	require.NoError(t, err)
+	require.Equal(t, 40, fetched.Limits.MaxSizeMB)
+	require.Equal(t, []string{"assets/brief.pdf"}, fetched.Assets)
+
+	limit = 51
+	_, err = svc.UpdateAssignment(context.Background(), created.ID, dto.WebAssignmentUpdateRequest{MaxSubmissionMB: &limit}, actor)
+	require.ErrorIs(t, err, service.ErrAssignmentSizeLimitTooHigh)
+
+	_, err = svc.UpdateAssignment(context.Background(), 9999, dto.WebAssignmentUpdateRequest{Title: &title}, actor)
+	require.ErrorIs(t, err, service.ErrWebAssignmentNotFound)
+}
+
+func TestWebLabService_CreateSubmission_RoleLimit(t *testing.T) {
+	svc, _, student, assignment := setupWebLabServiceWithLimits(t, service.SubmissionSizeLimits{RoleMB: map[string]int{"teacher": 1}})
+
+	large := make([]byte, 2*1024*1024)
+	_, err := rand.Read(large)
+	require.NoError(t, err)
+	zipBytes := buildZip(t, []zipEntry{{Name: "large.bin", Content: large, Method: zip.Store}})
+
+	payload := dto.WebSubmissionCreateRequest{AssignmentID: assignment.ID, StudentID: student.ID, Role: "teacher"}
+	_, err = svc.CreateSubmission(context.Background(), payload, fileHeaderFromBytes(t, "submission.zip", zipBytes))
+
+	var sizeErr *service.SizeLimitError
+	require.ErrorAs(t, err, &sizeErr)
+	require.Equal(t, service.SizeLimitScopeRole, sizeErr.Scope)
+	require.Contains(t, err.Error(), "1 MB role limit")
 }
 
 func TestWebLabService_CreateSubmission_DangerousExecutable(t *testing.T) {
@@ -144,6 +262,100 @@ type zipEntry struct {
 	Mode    os.FileMode
 }
 
+func TestWebLabService_AssignmentReferenceURL(t *testing.T) {
+	svc, db, _, _ := setupWebLabService(t)
+	actor := service.ActivityActor{ID: 1, Role: "teacher"}
+
+	created, err := svc.CreateAssignment(context.Background(), dto.WebAssignmentCreateRequest{
+		Title:           "Portfolio",
+		ReferenceZipURL: "https://cdn.example.com/reference.zip",
+	}, actor)
+	require.NoError(t, err)
+	require.True(t, created.HasReference)
+
+	_, err = svc.CreateAssignment(context.Background(), dto.WebAssignmentCreateRequest{Title: "Portfolio", ReferenceZipURL: "ftp://example.com/ref.zip"}, actor)
+	require.Error(t, err)
+
+	invalid := "not a url"
+	_, err = svc.UpdateAssignment(context.Background(), created.ID, dto.WebAssignmentUpdateRequest{ReferenceZipURL: &invalid}, actor)
+	require.Error(t, err)
+
+	cleared := ""
+	updated, err := svc.UpdateAssignment(context.Background(), created.ID, dto.WebAssignmentUpdateRequest{ReferenceZipURL: &cleared}, actor)
+	require.NoError(t, err)
+	require.False(t, updated.HasReference)
+
+	var stored models.WebAssignment
+	require.NoError(t, db.First(&stored, created.ID).Error)
+	require.Empty(t, stored.ReferenceZipURL)
+}
+
+func TestWebLabService_UploadReference(t *testing.T) {
+	svc, db, _, assignment := setupWebLabService(t)
+	actor := service.ActivityActor{ID: 1, Role: "teacher"}
+
+	reference := buildZip(t, []zipEntry{
+		{Name: "solution/index.html", Content: []byte("<html><head></head><body>Ref</body></html>")},
+		{Name: "solution/css/style.css", Content: []byte("body { margin: 0; }")},
+	})
+	resp, err := svc.UploadReference(context.Background(), assignment.ID, fileHeaderFromBytes(t, "reference.zip", reference), actor)
+	require.NoError(t, err)
+	require.True(t, resp.HasReference)
+	require.NotNil(t, resp.Limits)
+
+	var stored models.WebAssignment
+	require.NoError(t, db.First(&stored, assignment.ID).Error)
+	require.Equal(t, "https://cdn.example.com/reference.zip", stored.ReferenceZipURL)
+
+	_, err = svc.UploadReference(context.Background(), assignment.ID, fileHeaderFromBytes(t, "reference.txt", []byte("not a zip")), actor)
+	require.ErrorIs(t, err, service.ErrWebSubmissionUnsupportedType)
+
+	empty := buildZip(t, []zipEntry{{Name: "solution/", Mode: os.ModeDir | 0o755}})
+	_, err = svc.UploadReference(context.Background(), assignment.ID, fileHeaderFromBytes(t, "reference.zip", empty), actor)
+	require.ErrorIs(t, err, service.ErrWebSubmissionInvalidArchive)
+
+	_, err = svc.UploadReference(context.Background(), 9999, fileHeaderFromBytes(t, "reference.zip", reference), actor)
+	require.ErrorIs(t, err, service.ErrWebAssignmentNotFound)
+
+	_, err = svc.UploadReference(context.Background(), assignment.ID, nil, actor)
+	require.ErrorIs(t, err, service.ErrWebSubmissionFileRequired)
+}
+
+func TestWebLabService_CreateSubmission_ComparesAgainstReference(t *testing.T) {
+	svc, db, student, assignment := setupWebLabService(t)
+
+	reference := buildZip(t, []zipEntry{
+		{Name: "solution/index.html", Content: []byte("<html><head></head><body>Ref</body></html>")},
+		{Name: "solution/about.html", Content: []byte("<html><head></head><body>About</body></html>")},
+		{Name: "solution/css/style.css", Content: []byte("body { margin: 0; }")},
+		{Name: "solution/js/app.js", Content: []byte("console.log('ref')")},
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(reference)
+	}))
+	defer server.Close()
+
+	require.NoError(t, db.Model(&assignment).Update("reference_zip_url", server.URL+"/reference.zip").Error)
+
+	zipBytes := buildZip(t, []zipEntry{
+		{Name: "index.html", Content: []byte("<html lang=\"id\"><head><title>Hello</title></head><body>Hello</body></html>")},
+		{Name: "style.css", Content: []byte("body { color: black; }")},
+		{Name: "js/app.js", Content: []byte("console.log('ok')")},
+		{Name: "notes.txt", Content: []byte("draft")},
+	})
+	file := fileHeaderFromBytes(t, "submission.zip", zipBytes)
+
+	payload := dto.WebSubmissionCreateRequest{AssignmentID: assignment.ID, StudentID: student.ID}
+	resp, err := svc.CreateSubmission(context.Background(), payload, file)
+	require.NoError(t, err)
+	require.Contains(t, resp.Feedback, "Berkas referensi tidak ditemukan: about.html")
+	require.Contains(t, resp.Feedback, "Struktur berbeda: style.css seharusnya berada di css/style.css")
+	require.Contains(t, resp.Feedback, "Berkas tambahan di luar referensi: notes.txt")
+	require.NotContains(t, resp.Feedback, "js/app.js")
+	require.NotNil(t, resp.Score)
+	require.InDelta(t, 85.0, *resp.Score, 0.001)
+}
+
 func buildZip(t *testing.T, entries []zipEntry) []byte {
 	t.Helper()
 
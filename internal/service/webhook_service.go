@@ -0,0 +1,530 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/clock"
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/logging"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+// Webhook event types subscriptions can register for.
+const (
+	WebhookEventSubmissionGraded  = "submission.graded"
+	WebhookEventAssignmentCreated = "assignment.created"
+	WebhookEventContactSubmitted  = "contact.submitted"
+)
+
+const (
+	// WebhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the
+	// request body keyed with the subscription secret.
+	WebhookSignatureHeader = "X-Gema-Signature"
+	// WebhookEventHeader names the event type of a delivery.
+	WebhookEventHeader = "X-Gema-Event"
+	// WebhookDeliveryHeader carries the delivery ID, which is stable across
+	// retries so receivers can drop duplicates.
+	WebhookDeliveryHeader = "X-Gema-Delivery"
+
+	webhookOutboxTopic        = "webhooks"
+	webhookBatchSize          = 32
+	defaultWebhookWorkers     = 4
+	defaultWebhookMaxAttempts = 5
+	defaultWebhookRetryDelay  = time.Second
+	maxWebhookRetryDelay      = time.Minute
+	defaultWebhookTimeout     = 10 * time.Second
+	maxWebhookErrorLength     = 512
+)
+
+var webhookEvents = map[string]struct{}{
+	WebhookEventSubmissionGraded:  {},
+	WebhookEventAssignmentCreated: {},
+	WebhookEventContactSubmitted:  {},
+}
+
+// ErrWebhookSubscriptionNotFound indicates the subscription does not exist.
+var ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+
+// WebhookEvent is an event offered to webhook subscribers.
+type WebhookEvent struct {
+	Type       string
+	ActorID    uint
+	ActorRole  string
+	EntityType string
+	EntityID   *uint
+	Data       map[string]interface{}
+	OccurredAt time.Time
+}
+
+// WebhookDispatcher queues events for delivery to their subscribers.
+// Dispatch never waits on delivery; events without subscribers are dropped.
+type WebhookDispatcher interface {
+	Dispatch(ctx context.Context, event WebhookEvent)
+}
+
+// WebhookService manages webhook subscriptions and delivers events to them
+// in the background.
+type WebhookService interface {
+	WebhookDispatcher
+	ListSubscriptions(ctx context.Context) ([]dto.WebhookSubscriptionResponse, error)
+	CreateSubscription(ctx context.Context, payload dto.WebhookSubscriptionRequest, actor ActivityActor) (dto.WebhookSubscriptionResponse, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+	ListDeadLetters(ctx context.Context, page, pageSize int) (dto.WebhookDeadLetterListResponse, error)
+	// Start runs the dispatcher until Shutdown or ctx ends.
+	Start(ctx context.Context)
+	// Shutdown stops the dispatcher and, until ctx ends, delivers what is
+	// due. Call it after the HTTP server has stopped so events recorded by the
+	// last requests go out too; anything left waits in the outbox.
+	Shutdown(ctx context.Context) error
+}
+
+// WebhookConfig tunes webhook delivery. Workers bounds concurrent requests.
+// Failed deliveries are retried with exponential backoff from RetryDelay,
+// capped at a minute, until MaxAttempts is reached; they are then written to
+// the dead-letter log.
+type WebhookConfig struct {
+	Workers     int
+	MaxAttempts int
+	RetryDelay  time.Duration
+	Timeout     time.Duration
+}
+
+// webhookPayload is the JSON body POSTed to subscribers.
+type webhookPayload struct {
+	ID            string                 `json:"id"`
+	Event         string                 `json:"event"`
+	OccurredAt    time.Time              `json:"occurred_at"`
+	CorrelationID string                 `json:"correlation_id,omitempty"`
+	Actor         webhookActor           `json:"actor"`
+	EntityType    string                 `json:"entity_type"`
+	EntityID      *uint                  `json:"entity_id,omitempty"`
+	Data          map[string]interface{} `json:"data"`
+}
+
+type webhookActor struct {
+	ID   uint   `json:"id"`
+	Role string `json:"role"`
+}
+
+// webhookDelivery is the outbox payload of one event for one subscription.
+type webhookDelivery struct {
+	SubscriptionID uint            `json:"subscription_id"`
+	DeliveryID     string          `json:"delivery_id"`
+	Body           json.RawMessage `json:"body"`
+}
+
+type webhookService struct {
+	repo      repository.WebhookRepository
+	outbox    repository.OutboxRepository
+	validator *validator.Validate
+	client    *http.Client
+	cfg       WebhookConfig
+	logger    zerolog.Logger
+	clock     clock.Clock
+	nodeID    string
+
+	wake     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	running  sync.WaitGroup
+}
+
+// NewWebhookService constructs the webhook service. Dispatched events are
+// stored in the outbox; call Start to begin delivering them.
+func NewWebhookService(repo repository.WebhookRepository, outbox repository.OutboxRepository, validator *validator.Validate, cfg WebhookConfig, logger zerolog.Logger) WebhookService {
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWebhookWorkers
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultWebhookMaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultWebhookRetryDelay
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultWebhookTimeout
+	}
+
+	return &webhookService{
+		repo:      repo,
+		outbox:    outbox,
+		validator: validator,
+		client:    &http.Client{Timeout: cfg.Timeout},
+		cfg:       cfg,
+		logger:    logger.With().Str("component", "webhook_service").Logger(),
+		clock:     clock.Real(),
+		nodeID:    uuid.NewString(),
+		wake:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+	}
+}
+
+func (s *webhookService) ListSubscriptions(ctx context.Context) ([]dto.WebhookSubscriptionResponse, error) {
+	subscriptions, err := s.repo.ListSubscriptions(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.WebhookSubscriptionResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		items = append(items, dto.NewWebhookSubscriptionResponse(subscription))
+	}
+	return items, nil
+}
+
+func (s *webhookService) CreateSubscription(ctx context.Context, payload dto.WebhookSubscriptionRequest, actor ActivityActor) (dto.WebhookSubscriptionResponse, error) {
+	payload.EventType = strings.TrimSpace(payload.EventType)
+	payload.URL = strings.TrimSpace(payload.URL)
+	if err := s.validator.Struct(payload); err != nil {
+		return dto.WebhookSubscriptionResponse{}, err
+	}
+
+	subscription := models.WebhookSubscription{
+		EventType: payload.EventType,
+		URL:       payload.URL,
+		Secret:    payload.Secret,
+		CreatedBy: actor.ID,
+	}
+	if err := s.repo.CreateSubscription(ctx, &subscription); err != nil {
+		return dto.WebhookSubscriptionResponse{}, err
+	}
+	return dto.NewWebhookSubscriptionResponse(subscription), nil
+}
+
+func (s *webhookService) DeleteSubscription(ctx context.Context, id uint) error {
+	if err := s.repo.DeleteSubscription(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWebhookSubscriptionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *webhookService) ListDeadLetters(ctx context.Context, page, pageSize int) (dto.WebhookDeadLetterListResponse, error) {
+	page = normalizePage(page)
+	pageSize = clampPageSize(pageSize)
+
+	letters, total, err := s.repo.ListDeadLetters(ctx, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return dto.WebhookDeadLetterListResponse{}, err
+	}
+
+	items := make([]dto.WebhookDeadLetterResponse, 0, len(letters))
+	for _, letter := range letters {
+		items = append(items, dto.NewWebhookDeadLetterResponse(letter))
+	}
+	return dto.WebhookDeadLetterListResponse{
+		Items: items,
+		Pagination: dto.PaginationMeta{
+			Page:       page,
+			PageSize:   pageSize,
+			TotalItems: total,
+			TotalPages: calculateTotalPages(total, pageSize),
+		},
+	}, nil
+}
+
+// Dispatch stores one delivery of event per subscription in the outbox and
+// wakes the dispatcher, so deliveries survive a restart and each subscription
+// is retried on its own schedule. Failures are logged rather than returned so
+// webhooks never fail the caller.
+func (s *webhookService) Dispatch(ctx context.Context, event WebhookEvent) {
+	if _, ok := webhookEvents[event.Type]; !ok {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = s.clock.Now()
+	}
+	correlationID := logging.CorrelationID(ctx)
+	logger := logging.FromContext(ctx, s.logger).With().Str("event", event.Type).Logger()
+	// The event is recorded even if the request is cancelled meanwhile.
+	ctx = context.WithoutCancel(ctx)
+
+	subscriptions, err := s.repo.ListSubscriptions(ctx, event.Type)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to load webhook subscriptions")
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	events := make([]models.OutboxEvent, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		deliveryID := uuid.NewString()
+		body, err := json.Marshal(webhookPayload{
+			ID:            deliveryID,
+			Event:         event.Type,
+			OccurredAt:    event.OccurredAt.UTC(),
+			CorrelationID: correlationID,
+			Actor:         webhookActor{ID: event.ActorID, Role: event.ActorRole},
+			EntityType:    event.EntityType,
+			EntityID:      event.EntityID,
+			Data:          event.Data,
+		})
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to encode webhook payload")
+			return
+		}
+		payload, err := json.Marshal(webhookDelivery{SubscriptionID: subscription.ID, DeliveryID: deliveryID, Body: body})
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to encode webhook payload")
+			return
+		}
+		events = append(events, models.OutboxEvent{Topic: webhookOutboxTopic, Payload: string(payload)})
+	}
+
+	if err := s.outbox.Create(ctx, events); err != nil {
+		logger.Error().Err(err).Msg("failed to queue webhook deliveries")
+		return
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *webhookService) Start(ctx context.Context) {
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+
+		ticker := time.NewTicker(outboxDispatchInterval)
+		defer ticker.Stop()
+
+		var lastPrune time.Time
+		for {
+			if err := s.flush(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error().Err(err).Msg("failed to dispatch webhook outbox")
+			}
+
+			if now := s.clock.Now(); now.Sub(lastPrune) >= outboxPruneInterval {
+				lastPrune = now
+				if _, err := s.outbox.DeleteSentBefore(ctx, now.Add(-outboxRetention)); err != nil && ctx.Err() == nil {
+					s.logger.Warn().Err(err).Msg("failed to prune webhook outbox")
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.stop:
+				return
+			case <-s.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *webhookService) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return s.flush(ctx)
+}
+
+// flush delivers claimed deliveries until none are due. Each claimed
+// delivery gets one attempt, Workers at a time; a failure is rescheduled with
+// backoff instead of retried in place, so a failing subscriber does not hold
+// up the others.
+func (s *webhookService) flush(ctx context.Context) error {
+	// The lease covers a batch whose every request runs into the timeout.
+	rounds := (webhookBatchSize + s.cfg.Workers - 1) / s.cfg.Workers
+	lease := time.Duration(rounds)*s.cfg.Timeout + outboxClaimTTL
+
+	for {
+		now := s.clock.Now()
+		events, err := s.outbox.Claim(ctx, webhookOutboxTopic, s.nodeID, now, now.Add(lease), webhookBatchSize)
+		if err != nil {
+			return err
+		}
+
+		var (
+			mu      sync.Mutex
+			settled []uint
+			wg      sync.WaitGroup
+		)
+		slots := make(chan struct{}, s.cfg.Workers)
+		for _, event := range events {
+			slots <- struct{}{}
+			wg.Add(1)
+			go func(event models.OutboxEvent) {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+				if s.deliver(ctx, event) {
+					mu.Lock()
+					settled = append(settled, event.ID)
+					mu.Unlock()
+				}
+			}(event)
+		}
+		wg.Wait()
+
+		if err := s.outbox.MarkSent(context.WithoutCancel(ctx), settled, s.clock.Now()); err != nil {
+			return err
+		}
+		if len(events) < webhookBatchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// deliver makes one attempt at a claimed delivery and reports whether it is
+// settled: accepted, dead-lettered or for a deleted subscription. Otherwise
+// the delivery is rescheduled, or left to its lease when even that fails.
+func (s *webhookService) deliver(ctx context.Context, event models.OutboxEvent) bool {
+	var delivery webhookDelivery
+	if err := json.Unmarshal([]byte(event.Payload), &delivery); err != nil {
+		s.logger.Error().Err(err).Uint("outbox_id", event.ID).Msg("dropping malformed webhook delivery")
+		return true
+	}
+	logger := s.logger.With().
+		Uint("subscription_id", delivery.SubscriptionID).
+		Str("delivery_id", delivery.DeliveryID).
+		Logger()
+
+	subscription, err := s.repo.GetSubscription(ctx, delivery.SubscriptionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true
+	}
+	if err != nil {
+		logger.Warn().Err(err).Msg("failed to load webhook subscription")
+		return false
+	}
+
+	err = s.post(ctx, subscription, delivery.DeliveryID, delivery.Body)
+	if err == nil {
+		return true
+	}
+
+	// Failures are recorded even when shutdown cancelled the attempt.
+	persistCtx := context.WithoutCancel(ctx)
+	attempts := event.Attempts + 1
+	var statusErr *webhookStatusError
+	permanent := errors.As(err, &statusErr) && !statusErr.retryable()
+	if ctx.Err() == nil && (permanent || attempts >= s.cfg.MaxAttempts) {
+		logger.Warn().Err(err).Int("attempts", attempts).Msg("webhook delivery failed")
+		letter := models.WebhookDeadLetter{
+			SubscriptionID: subscription.ID,
+			DeliveryID:     delivery.DeliveryID,
+			EventType:      subscription.EventType,
+			URL:            subscription.URL,
+			Payload:        datatypes.JSON(delivery.Body),
+			Attempts:       attempts,
+			LastError:      truncateWebhookError(err.Error()),
+		}
+		if err := s.repo.CreateDeadLetter(persistCtx, &letter); err != nil {
+			logger.Error().Err(err).Msg("failed to record webhook dead letter")
+			return false
+		}
+		return true
+	}
+
+	// An attempt cut short by shutdown is retried by the next dispatcher.
+	retryAt := s.clock.Now()
+	if ctx.Err() == nil {
+		retryAt = retryAt.Add(s.backoff(attempts))
+	}
+	if err := s.outbox.Reschedule(persistCtx, event.ID, truncateWebhookError(err.Error()), retryAt); err != nil {
+		logger.Error().Err(err).Msg("failed to reschedule webhook delivery")
+	}
+	return false
+}
+
+func (s *webhookService) post(ctx context.Context, subscription models.WebhookSubscription, deliveryID string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gema-webhooks/1")
+	req.Header.Set(WebhookEventHeader, subscription.EventType)
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(subscription.Secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &webhookStatusError{status: resp.StatusCode}
+	}
+	return nil
+}
+
+func (s *webhookService) backoff(attempt int) time.Duration {
+	ceiling := s.cfg.RetryDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > maxWebhookRetryDelay {
+		ceiling = maxWebhookRetryDelay
+	}
+	return ceiling/2 + time.Duration(rand.Int63n(int64(ceiling/2)+1))
+}
+
+// SignWebhookPayload returns the X-Gema-Signature value for body, which
+// receivers recompute with the shared secret to authenticate a delivery.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookStatusError reports a delivery the receiver answered with a non-2xx
+// status.
+type webhookStatusError struct {
+	status int
+}
+
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("subscriber responded with status %d", e.status)
+}
+
+// retryable reports whether the receiver may accept the delivery later:
+// server errors, timeouts and rate limiting are retried, other client errors
+// are not.
+func (e *webhookStatusError) retryable() bool {
+	return e.status >= 500 || e.status == http.StatusRequestTimeout || e.status == http.StatusTooManyRequests
+}
+
+func truncateWebhookError(message string) string {
+	if len(message) <= maxWebhookErrorLength {
+		return message
+	}
+	return message[:maxWebhookErrorLength]
+}
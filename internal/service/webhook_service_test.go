@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/logging"
+	"github.com/noah-isme/gema-go-api/internal/models"
+	"github.com/noah-isme/gema-go-api/internal/repository"
+)
+
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := http.StatusNoContent
+	if attempt := len(r.bodies); attempt < len(r.statuses) {
+		status = r.statuses[attempt]
+	}
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+	w.WriteHeader(status)
+}
+
+func (r *webhookReceiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.bodies)
+}
+
+func setupWebhookService(t *testing.T) (*gorm.DB, WebhookService) {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:webhooks_%d?mode=memory&cache=shared", time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.WebhookSubscription{}, &models.WebhookDeadLetter{}, &models.OutboxEvent{}))
+
+	svc := NewWebhookService(repository.NewWebhookRepository(db), repository.NewOutboxRepository(db), validator.New(), WebhookConfig{MaxAttempts: 3, RetryDelay: time.Millisecond}, testLogger())
+	return db, svc
+}
+
+func TestWebhookServiceDeliversSignedEventsWithRetry(t *testing.T) {
+	_, svc := setupWebhookService(t)
+	receiver := &webhookReceiver{statuses: []int{http.StatusServiceUnavailable}}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	_, err := svc.CreateSubscription(ctx, dto.WebhookSubscriptionRequest{
+		EventType: WebhookEventSubmissionGraded,
+		URL:       server.URL,
+		Secret:    "0123456789abcdef",
+	}, ActivityActor{ID: 1, Role: "admin"})
+	require.NoError(t, err)
+
+	svc.Start(ctx)
+	entityID := uint(42)
+	svc.Dispatch(logging.WithCorrelationID(ctx, "req-123"), WebhookEvent{
+		Type:       WebhookEventSubmissionGraded,
+		ActorID:    1,
+		ActorRole:  "teacher",
+		EntityType: "submission",
+		EntityID:   &entityID,
+		Data:       map[string]interface{}{"score": 88.5},
+	})
+	svc.Dispatch(ctx, WebhookEvent{Type: "student.updated"})
+	require.Eventually(t, func() bool { return receiver.count() == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, svc.Shutdown(ctx))
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	require.Len(t, receiver.bodies, 2, "the 503 is retried and unsubscribed events are not sent")
+	require.Equal(t, receiver.bodies[0], receiver.bodies[1])
+	require.Equal(t, receiver.headers[0].Get(WebhookDeliveryHeader), receiver.headers[1].Get(WebhookDeliveryHeader), "retries keep the delivery ID")
+
+	body := receiver.bodies[1]
+	require.Equal(t, SignWebhookPayload("0123456789abcdef", body), receiver.headers[1].Get(WebhookSignatureHeader))
+	require.Equal(t, WebhookEventSubmissionGraded, receiver.headers[1].Get(WebhookEventHeader))
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &payload))
+	require.Equal(t, "submission.graded", payload["event"])
+	require.Equal(t, "req-123", payload["correlation_id"])
+	require.Equal(t, receiver.headers[1].Get(WebhookDeliveryHeader), payload["id"])
+	require.EqualValues(t, 42, payload["entity_id"])
+	require.Equal(t, 88.5, payload["data"].(map[string]interface{})["score"])
+}
+
+func TestWebhookServiceDeadLettersFailedDeliveries(t *testing.T) {
+	db, svc := setupWebhookService(t)
+	receiver := &webhookReceiver{statuses: []int{http.StatusGone, http.StatusGone, http.StatusGone}}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	subscription, err := svc.CreateSubscription(ctx, dto.WebhookSubscriptionRequest{
+		EventType: WebhookEventAssignmentCreated,
+		URL:       server.URL,
+		Secret:    "0123456789abcdef",
+	}, ActivityActor{ID: 1, Role: "admin"})
+	require.NoError(t, err)
+
+	svc.Start(ctx)
+	svc.Dispatch(ctx, WebhookEvent{Type: WebhookEventAssignmentCreated, EntityType: "assignment"})
+	var letters dto.WebhookDeadLetterListResponse
+	require.Eventually(t, func() bool {
+		letters, err = svc.ListDeadLetters(ctx, 1, 10)
+		return err == nil && len(letters.Items) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, svc.Shutdown(ctx))
+
+	receiver.mu.Lock()
+	require.Len(t, receiver.bodies, 1, "client errors other than 408 and 429 are not retried")
+	receiver.mu.Unlock()
+
+	letter := letters.Items[0]
+	require.Equal(t, subscription.ID, letter.SubscriptionID)
+	require.Equal(t, 1, letter.Attempts)
+	require.Contains(t, letter.LastError, "410")
+	require.JSONEq(t, string(receiver.bodies[0]), string(letter.Payload))
+
+	require.NoError(t, svc.DeleteSubscription(ctx, subscription.ID))
+	require.ErrorIs(t, svc.DeleteSubscription(ctx, subscription.ID), ErrWebhookSubscriptionNotFound)
+	var remaining int64
+	require.NoError(t, db.Model(&models.WebhookSubscription{}).Count(&remaining).Error)
+	require.Zero(t, remaining)
+}
+
+func TestWebhookServiceKeepsFailingSubscribersApart(t *testing.T) {
+	_, svc := setupWebhookService(t)
+	failing := &webhookReceiver{statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable}}
+	healthy := &webhookReceiver{}
+	for _, receiver := range []*webhookReceiver{failing, healthy} {
+		server := httptest.NewServer(receiver)
+		t.Cleanup(server.Close)
+		_, err := svc.CreateSubscription(context.Background(), dto.WebhookSubscriptionRequest{
+			EventType: WebhookEventContactSubmitted,
+			URL:       server.URL,
+			Secret:    "0123456789abcdef",
+		}, ActivityActor{ID: 1, Role: "admin"})
+		require.NoError(t, err)
+	}
+	svc.(*webhookService).cfg.RetryDelay = time.Hour
+
+	ctx := context.Background()
+	svc.Start(ctx)
+	svc.Dispatch(ctx, WebhookEvent{Type: WebhookEventContactSubmitted, EntityType: "contact"})
+	svc.Dispatch(ctx, WebhookEvent{Type: WebhookEventContactSubmitted, EntityType: "contact"})
+
+	require.Eventually(t, func() bool { return healthy.count() == 2 }, 5*time.Second, 10*time.Millisecond,
+		"a subscriber waiting out its backoff does not hold up the others")
+	require.NoError(t, svc.Shutdown(ctx))
+	require.Equal(t, 2, failing.count(), "each failed delivery waits for its retry")
+}
+
+func TestWebhookServiceShutdownDeliversEventsRecordedWhileStopping(t *testing.T) {
+	db, svc := setupWebhookService(t)
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	_, err := svc.CreateSubscription(ctx, dto.WebhookSubscriptionRequest{
+		EventType: WebhookEventSubmissionGraded,
+		URL:       server.URL,
+		Secret:    "0123456789abcdef",
+	}, ActivityActor{ID: 1, Role: "admin"})
+	require.NoError(t, err)
+
+	// The background context is already cancelled when the last requests
+	// record their events.
+	background, cancel := context.WithCancel(ctx)
+	svc.Start(background)
+	cancel()
+	svc.Dispatch(ctx, WebhookEvent{Type: WebhookEventSubmissionGraded, EntityType: "submission"})
+
+	require.NoError(t, svc.Shutdown(ctx))
+	require.Equal(t, 1, receiver.count())
+
+	var pending int64
+	require.NoError(t, db.Model(&models.OutboxEvent{}).Where("sent_at IS NULL").Count(&pending).Error)
+	require.Zero(t, pending)
+}
+
+func TestWebhookServiceRejectsInvalidSubscriptions(t *testing.T) {
+	_, svc := setupWebhookService(t)
+
+	_, err := svc.CreateSubscription(context.Background(), dto.WebhookSubscriptionRequest{
+		EventType: "student.updated",
+		URL:       "ftp://example.com/hook",
+		Secret:    "short",
+	}, ActivityActor{ID: 1, Role: "admin"})
+	var validationErrs validator.ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+	require.Len(t, validationErrs, 3)
+}
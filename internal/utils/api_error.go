@@ -0,0 +1,66 @@
+package utils
+
+import "github.com/gofiber/fiber/v2"
+
+// Error codes shared by every handler. Domain specific codes such as
+// ASSIGNMENT_NOT_FOUND are assigned where the handler maps its errors.
+const (
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeInvalidRequest   = "INVALID_REQUEST"
+	CodeInternal         = "INTERNAL_ERROR"
+)
+
+// APIError is an error response with a stable, machine-readable code that
+// clients can branch on instead of parsing the message.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	// Fields lists the invalid request fields.
+	Fields []FieldError
+	// Details carries extra structured context, such as size limits.
+	Details interface{}
+}
+
+// Error implements the error interface.
+func (e APIError) Error() string {
+	return e.Message
+}
+
+// SendAPIError writes apiErr in the standard error envelope. A missing status
+// defaults to 500 and a missing code to INTERNAL_ERROR.
+func SendAPIError(c *fiber.Ctx, apiErr APIError) error {
+	if apiErr.Status == 0 {
+		apiErr.Status = fiber.StatusInternalServerError
+	}
+	if apiErr.Code == "" {
+		apiErr.Code = CodeInternal
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = "error"
+	}
+
+	return c.Status(apiErr.Status).JSON(APIResponse{
+		Success: false,
+		Code:    apiErr.Code,
+		Message: apiErr.Message,
+		Details: apiErr.Details,
+		Fields:  apiErr.Fields,
+	})
+}
+
+// ValidationAPIError converts validator failures into a 400 VALIDATION_FAILED
+// error listing every invalid field. It returns false for other errors.
+func ValidationAPIError(err error) (APIError, bool) {
+	fields, ok := ValidationFieldErrors(err)
+	if !ok {
+		return APIError{}, false
+	}
+
+	return APIError{
+		Status:  fiber.StatusBadRequest,
+		Code:    CodeValidationFailed,
+		Message: "validation failed",
+		Fields:  fields,
+	}, true
+}
@@ -4,9 +4,13 @@ import "github.com/gofiber/fiber/v2"
 
 // APIResponse describes the common structure for API responses.
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Message string      `json:"message"`
+	Success bool         `json:"success"`
+	Code    string       `json:"code,omitempty"`
+	Data    interface{}  `json:"data,omitempty"`
+	Message string       `json:"message"`
+	Meta    interface{}  `json:"meta,omitempty"`
+	Details interface{}  `json:"details,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"`
 }
 
 // SendSuccess sends a successful JSON response with a message.
@@ -45,3 +49,33 @@ func SendError(c *fiber.Ctx, status int, message string) error {
 		Message: message,
 	})
 }
+
+// OK sends a 200 success envelope including optional metadata such as pagination.
+func OK(c *fiber.Ctx, data interface{}, message string, meta interface{}) error {
+	if message == "" {
+		message = "success"
+	}
+
+	return c.Status(fiber.StatusOK).JSON(APIResponse{
+		Success: true,
+		Data:    data,
+		Message: message,
+		Meta:    meta,
+	})
+}
+
+// Fail sends an error envelope with optional structured details.
+func Fail(c *fiber.Ctx, status int, message string, details interface{}) error {
+	if message == "" {
+		message = "error"
+	}
+	if status == 0 {
+		status = fiber.StatusInternalServerError
+	}
+
+	return c.Status(status).JSON(APIResponse{
+		Success: false,
+		Message: message,
+		Details: details,
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/require"
 
@@ -61,6 +62,54 @@ func TestFailIncludesDetails(t *testing.T) {
 	require.Nil(t, payload.Data)
 }
 
+func TestSendAPIErrorIncludesCodeAndFields(t *testing.T) {
+	type request struct {
+		AssignmentID uint   `validate:"required"`
+		Title        string `validate:"min=3"`
+	}
+	validationErr := validator.New().Struct(request{Title: "ab"})
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		apiErr, ok := utils.ValidationAPIError(validationErr)
+		require.True(t, ok)
+		return utils.SendAPIError(c, apiErr)
+	})
+	app.Get("/internal", func(c *fiber.Ctx) error {
+		return utils.SendAPIError(c, utils.APIError{Message: "internal server error"})
+	})
+
+	resp := performRequest(t, app, http.MethodGet, "/")
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var payload struct {
+		Success bool               `json:"success"`
+		Code    string             `json:"code"`
+		Message string             `json:"message"`
+		Fields  []utils.FieldError `json:"fields"`
+	}
+	decode(t, resp, &payload)
+
+	require.False(t, payload.Success)
+	require.Equal(t, utils.CodeValidationFailed, payload.Code)
+	require.Equal(t, "validation failed", payload.Message)
+	require.Equal(t, []utils.FieldError{
+		{Field: "assignment_id", Tag: "required", Message: "assignment_id is required"},
+		{Field: "title", Tag: "min", Message: "title must be at least 3 characters"},
+	}, payload.Fields)
+
+	resp = performRequest(t, app, http.MethodGet, "/internal")
+	require.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+	payload.Fields = nil
+	decode(t, resp, &payload)
+	require.Equal(t, utils.CodeInternal, payload.Code)
+	require.Nil(t, payload.Fields)
+
+	_, ok := utils.ValidationAPIError(fiber.ErrBadRequest)
+	require.False(t, ok)
+}
+
 func performRequest(t *testing.T, app *fiber.App, method, path string) *http.Response {
 	t.Helper()
 	req := httptest.NewRequest(method, path, nil)
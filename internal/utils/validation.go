@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes one invalid request field in terms a form can show
+// next to the input: the JSON field name, the rule it broke and a readable
+// message.
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
+}
+
+// NewValidator returns the validator shared by services and handlers. It
+// reports fields by their JSON names so validation errors match the request
+// body the client sent.
+func NewValidator() *validator.Validate {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(jsonFieldName)
+	return validate
+}
+
+// ValidationFieldErrors lists the invalid fields in a validator error. It
+// returns false when err does not come from the validator.
+func ValidationFieldErrors(err error) ([]FieldError, bool) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil, false
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		name := fieldName(fieldErr)
+		fields = append(fields, FieldError{
+			Field:   name,
+			Tag:     fieldErr.Tag(),
+			Message: name + " " + ruleMessage(fieldErr),
+		})
+	}
+	return fields, true
+}
+
+// fieldName is the field's JSON name. Validators built without NewValidator
+// report Go names, which are converted to the snake_case the DTOs use.
+func fieldName(fieldErr validator.FieldError) string {
+	name := fieldErr.Field()
+	if name == fieldErr.StructField() {
+		return snakeCase(name)
+	}
+	return name
+}
+
+// ruleMessage phrases a broken validation rule for display after the field
+// name.
+func ruleMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	unit := ""
+	switch fieldErr.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fieldErr.Tag() {
+	case "required", "required_if", "required_with", "required_without":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", param, unit)
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", param, unit)
+	case "len":
+		return fmt.Sprintf("must be exactly %s%s", param, unit)
+	case "gt":
+		return "must be greater than " + param
+	case "gte":
+		return "must be greater than or equal to " + param
+	case "lt":
+		return "must be less than " + param
+	case "lte":
+		return "must be less than or equal to " + param
+	case "datetime":
+		return "must be a date-time in the format " + param
+	case "hexcolor":
+		return "must be a hex color"
+	default:
+		return "is invalid"
+	}
+}
+
+// jsonFieldName names a struct field by its json tag, falling back to the Go
+// name for untagged fields and skipping fields excluded from JSON.
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// snakeCase turns Go field names like AssignmentID into assignment_id, which
+// matches the JSON names used by the DTOs.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,38 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/noah-isme/gema-go-api/internal/dto"
+	"github.com/noah-isme/gema-go-api/internal/utils"
+)
+
+func TestValidationFieldErrorsUseJSONNames(t *testing.T) {
+	err := utils.NewValidator().Struct(dto.AdminAssignmentCreateRequest{
+		Title:       "",
+		DueDate:     "next friday",
+		MaxScore:    100,
+		FileURL:     "not a url",
+		LatePenalty: 150,
+	})
+
+	fields, ok := utils.ValidationFieldErrors(err)
+	require.True(t, ok)
+
+	byField := make(map[string]utils.FieldError, len(fields))
+	for _, field := range fields {
+		byField[field.Field] = field
+	}
+
+	require.Equal(t, utils.FieldError{Field: "title", Tag: "required", Message: "title is required"}, byField["title"])
+	require.Equal(t, "datetime", byField["due_date"].Tag)
+	require.Equal(t, "due_date must be a date-time in the format 2006-01-02T15:04:05Z07:00", byField["due_date"].Message)
+	require.Equal(t, "file_url must be a valid URL", byField["file_url"].Message)
+	require.Equal(t, "late_penalty_percent must be less than or equal to 100", byField["late_penalty_percent"].Message)
+	require.Len(t, fields, 4)
+
+	_, ok = utils.ValidationFieldErrors(nil)
+	require.False(t, ok)
+}
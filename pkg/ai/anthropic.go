@@ -1,28 +1,233 @@
 package ai
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	anthropicDefaultBaseURL = "https://api.anthropic.com"
+	anthropicAPIVersion     = "2023-06-01"
 )
 
-// AnthropicConfig placeholder for anthropic integration configuration.
+// AnthropicConfig defines configuration options for the Anthropic evaluator.
 type AnthropicConfig struct {
-	APIKey string
-	Model  string
+	APIKey      string
+	Model       string
+	MaxTokens   int
+	Temperature float32
+	// Settings, when set, is read on every evaluation and overrides Model
+	// and Temperature, so they can be tuned without rebuilding the
+	// evaluator. An empty model falls back to Model.
+	Settings func() ModelSettings
+	// MaxRetries bounds retries of rate limit, timeout and 5xx failures;
+	// RetryBaseDelay seeds the exponential backoff (default 500ms).
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	// BaseURL overrides the Messages API host, mainly for tests.
+	BaseURL    string
+	HTTPClient *http.Client
+	// Prices maps model names to token prices for the cost metric.
+	Prices map[string]TokenPrice
+	Logger zerolog.Logger
 }
 
-// AnthropicEvaluator is a stub implementation that can be expanded once the SDK is available.
-type AnthropicEvaluator struct{}
+// AnthropicEvaluator implements Evaluator against the Anthropic Messages API.
+type AnthropicEvaluator struct {
+	client *http.Client
+	cfg    AnthropicConfig
+	retry  retryPolicy
+	tracer trace.Tracer
+	logger zerolog.Logger
+}
 
-// NewAnthropicEvaluator constructs a new stub evaluator.
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float32            `json:"temperature"`
+	System      string             `json:"system"`
+	Messages    []anthropicMessage `json:"messages"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      anthropicUsage `json:"usage"`
+	Error      *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// NewAnthropicEvaluator builds a new evaluator using the provided configuration.
 func NewAnthropicEvaluator(cfg AnthropicConfig) (*AnthropicEvaluator, error) {
 	if cfg.APIKey == "" {
 		return nil, fmt.Errorf("anthropic api key is required")
 	}
-	return &AnthropicEvaluator{}, nil
+
+	if cfg.Model == "" {
+		cfg.Model = "claude-3-5-haiku-latest"
+	}
+
+	if cfg.MaxTokens == 0 {
+		cfg.MaxTokens = 512
+	}
+
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = anthropicDefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	logger := cfg.Logger
+	if logger.GetLevel() == zerolog.Disabled {
+		logger = zerolog.Nop()
+	}
+
+	return &AnthropicEvaluator{
+		client: client,
+		cfg:    cfg,
+		retry:  newRetryPolicy(cfg.MaxRetries, cfg.RetryBaseDelay, logger),
+		tracer: otel.Tracer("github.com/noah-isme/gema-go-api/pkg/ai/anthropic"),
+		logger: logger,
+	}, nil
+}
+
+// CurrentSettings returns the model settings the next Evaluate call uses.
+func (a *AnthropicEvaluator) CurrentSettings() ModelSettings {
+	return resolveSettings(ModelSettings{Model: a.cfg.Model, Temperature: a.cfg.Temperature}, a.cfg.Settings)
+}
+
+// Evaluate sends the evaluation request to Anthropic and parses the JSON
+// object embedded in the text response.
+func (a *AnthropicEvaluator) Evaluate(parent context.Context, input EvaluationInput) (EvaluationResult, error) {
+	settings := a.CurrentSettings()
+	ctx, span := a.tracer.Start(parent, "anthropic.evaluate", trace.WithAttributes(
+		attribute.String("model", settings.Model),
+	))
+	defer span.End()
+
+	fail := func(err error) (EvaluationResult, error) {
+		aiFailures.WithLabelValues(settings.Model).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return EvaluationResult{}, err
+	}
+
+	request := anthropicRequest{
+		Model:       settings.Model,
+		MaxTokens:   a.cfg.MaxTokens,
+		Temperature: settings.Temperature,
+		System:      evaluatorSystemPrompt() + " Reply with the JSON object only.",
+		Messages: []anthropicMessage{
+			{Role: "user", Content: buildUserPrompt(input)},
+		},
+	}
+
+	start := time.Now()
+	var resp anthropicResponse
+	err := a.retry.do(ctx, settings.Model, func(ctx context.Context) error {
+		var sendErr error
+		resp, sendErr = a.send(ctx, request)
+		return sendErr
+	})
+	aiDuration.WithLabelValues(settings.Model).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return fail(fmt.Errorf("anthropic evaluate: %w", err))
+	}
+	recordUsage(settings.Model, resp.Usage.InputTokens, resp.Usage.OutputTokens, a.cfg.Prices)
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return fail(fmt.Errorf("no text content returned from anthropic"))
+	}
+
+	result, err := parseEvaluationResponse(extractJSONObject(text.String()))
+	if err != nil {
+		a.logger.Debug().Err(err).Str("stop_reason", resp.StopReason).Msg("unparseable anthropic evaluation")
+		return fail(err)
+	}
+
+	result.Raw = map[string]interface{}{
+		"usage": resp.Usage,
+	}
+
+	return result, nil
 }
 
-// Evaluate is not yet implemented for Anthropic models.
-func (a *AnthropicEvaluator) Evaluate(ctx context.Context, input EvaluationInput) (EvaluationResult, error) {
-	return EvaluationResult{}, fmt.Errorf("anthropic evaluator not implemented")
+func (a *AnthropicEvaluator) send(ctx context.Context, payload anthropicRequest) (anthropicResponse, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return anthropicResponse{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.BaseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return anthropicResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", a.cfg.APIKey)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	httpResp, err := a.client.Do(req)
+	if err != nil {
+		return anthropicResponse{}, err
+	}
+	defer httpResp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(httpResp.Body, 1<<20))
+	if err != nil {
+		return anthropicResponse{}, err
+	}
+
+	var resp anthropicResponse
+	if httpResp.StatusCode >= http.StatusBadRequest {
+		// Gateways answer with HTML or plain text too; the status alone still
+		// decides whether the call is retried.
+		apiErr := &providerError{StatusCode: httpResp.StatusCode}
+		if json.Unmarshal(raw, &resp) == nil && resp.Error != nil {
+			apiErr.Type = resp.Error.Type
+			apiErr.Message = resp.Error.Message
+		}
+		return anthropicResponse{}, apiErr
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return anthropicResponse{}, fmt.Errorf("decode response (status %d): %w", httpResp.StatusCode, err)
+	}
+
+	return resp, nil
 }
@@ -0,0 +1,112 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const cannedEvaluation = `{"score": 1.4, "verdict": "pass", "feedback": "Handles {edge} cases \"well\"", "details": {"correctness": 0.9}}`
+
+func TestAnthropicEvaluatorParsesFencedJSON(t *testing.T) {
+	var received anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/messages", r.URL.Path)
+		require.Equal(t, "test-key", r.Header.Get("x-api-key"))
+		require.Equal(t, anthropicAPIVersion, r.Header.Get("anthropic-version"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		text := "Here is my review:\n```json\n" + cannedEvaluation + "\n```\nLet me know if you need more."
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"content":     []map[string]string{{"type": "text", "text": text}},
+			"stop_reason": "end_turn",
+			"usage":       map[string]int{"input_tokens": 120, "output_tokens": 40},
+		})
+	}))
+	defer server.Close()
+
+	evaluator, err := NewAnthropicEvaluator(AnthropicConfig{APIKey: "test-key", BaseURL: server.URL, MaxTokens: 256, Temperature: 0.2})
+	require.NoError(t, err)
+	promptTokens := aiTokens.WithLabelValues("claude-3-5-haiku-latest", tokenKindPrompt)
+	promptBefore := testutil.ToFloat64(promptTokens)
+
+	input := EvaluationInput{TaskTitle: "Sum", Language: "python", SubmissionSource: "print(1)"}
+	result, err := evaluator.Evaluate(context.Background(), input)
+	require.NoError(t, err)
+
+	require.Equal(t, 256, received.MaxTokens)
+	require.InDelta(t, 0.2, received.Temperature, 1e-6)
+	require.Equal(t, "claude-3-5-haiku-latest", received.Model)
+	require.Len(t, received.Messages, 1)
+	require.Equal(t, buildUserPrompt(input), received.Messages[0].Content)
+
+	// The OpenAI evaluator parses the bare JSON object; both must agree.
+	expected, err := parseEvaluationResponse(cannedEvaluation)
+	require.NoError(t, err)
+	require.Equal(t, expected.Score, result.Score)
+	require.Equal(t, 1.0, result.Score)
+	require.Equal(t, expected.Verdict, result.Verdict)
+	require.Equal(t, expected.Feedback, result.Feedback)
+	require.Equal(t, expected.Details, result.Details)
+	require.NotNil(t, result.Raw["usage"])
+	require.Equal(t, promptBefore+120, testutil.ToFloat64(promptTokens))
+}
+
+func TestAnthropicEvaluatorReadsSettingsOnEveryCall(t *testing.T) {
+	var received anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": cannedEvaluation}},
+		})
+	}))
+	defer server.Close()
+
+	current := ModelSettings{Temperature: 0.4}
+	evaluator, err := NewAnthropicEvaluator(AnthropicConfig{
+		APIKey:   "test-key",
+		BaseURL:  server.URL,
+		Settings: func() ModelSettings { return current },
+	})
+	require.NoError(t, err)
+
+	_, err = evaluator.Evaluate(context.Background(), EvaluationInput{TaskTitle: "Sum"})
+	require.NoError(t, err)
+	require.Equal(t, "claude-3-5-haiku-latest", received.Model, "an empty model falls back to the configured one")
+	require.InDelta(t, 0.4, received.Temperature, 1e-6)
+
+	current = ModelSettings{Model: "claude-3-5-sonnet-latest", Temperature: 0.1}
+	_, err = evaluator.Evaluate(context.Background(), EvaluationInput{TaskTitle: "Sum"})
+	require.NoError(t, err)
+	require.Equal(t, "claude-3-5-sonnet-latest", received.Model)
+	require.InDelta(t, 0.1, received.Temperature, 1e-6)
+}
+
+func TestAnthropicEvaluatorReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`))
+	}))
+	defer server.Close()
+
+	evaluator, err := NewAnthropicEvaluator(AnthropicConfig{APIKey: "test-key", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	_, err = evaluator.Evaluate(context.Background(), EvaluationInput{})
+	require.ErrorContains(t, err, "rate_limit_error")
+}
+
+func TestExtractJSONObject(t *testing.T) {
+	require.Equal(t, `{"a":1}`, extractJSONObject(`{"a":1}`))
+	require.Equal(t, `{"a":1}`, extractJSONObject("```\n{\"a\":1}\n```"))
+	require.Equal(t, `{"a":{"b":"}"}}`, extractJSONObject(`Result: {"a":{"b":"}"}} done`))
+	require.Equal(t, "no json here", extractJSONObject("  no json here "))
+
+	_, err := parseEvaluationResponse(extractJSONObject("I cannot grade this."))
+	require.Error(t, err)
+}
@@ -39,13 +39,24 @@ type OpenAIConfig struct {
 	Model       string
 	MaxTokens   int
 	Temperature float32
-	Logger      zerolog.Logger
+	// Settings, when set, is read on every evaluation and overrides Model
+	// and Temperature, so they can be tuned without rebuilding the
+	// evaluator. An empty model falls back to Model.
+	Settings func() ModelSettings
+	// MaxRetries bounds retries of rate limit, timeout and 5xx failures;
+	// RetryBaseDelay seeds the exponential backoff (default 500ms).
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	// Prices maps model names to token prices for the cost metric.
+	Prices map[string]TokenPrice
+	Logger zerolog.Logger
 }
 
 // OpenAIEvaluator implements Evaluator against the OpenAI chat completion API.
 type OpenAIEvaluator struct {
 	client *openai.Client
 	cfg    OpenAIConfig
+	retry  retryPolicy
 	tracer trace.Tracer
 	logger zerolog.Logger
 }
@@ -76,23 +87,30 @@ func NewOpenAIEvaluator(cfg OpenAIConfig) (*OpenAIEvaluator, error) {
 	return &OpenAIEvaluator{
 		client: client,
 		cfg:    cfg,
+		retry:  newRetryPolicy(cfg.MaxRetries, cfg.RetryBaseDelay, logger),
 		tracer: tracer,
 		logger: logger,
 	}, nil
 }
 
+// CurrentSettings returns the model settings the next Evaluate call uses.
+func (e *OpenAIEvaluator) CurrentSettings() ModelSettings {
+	return resolveSettings(ModelSettings{Model: e.cfg.Model, Temperature: e.cfg.Temperature}, e.cfg.Settings)
+}
+
 // Evaluate sends the evaluation request to OpenAI and parses the response.
 func (e *OpenAIEvaluator) Evaluate(parent context.Context, input EvaluationInput) (EvaluationResult, error) {
+	settings := e.CurrentSettings()
 	ctx, span := e.tracer.Start(parent, "openai.evaluate", trace.WithAttributes(
-		attribute.String("model", e.cfg.Model),
+		attribute.String("model", settings.Model),
 	))
 	defer span.End()
 
 	start := time.Now()
 	request := openai.ChatCompletionRequest{
-		Model:       e.cfg.Model,
+		Model:       settings.Model,
 		MaxTokens:   e.cfg.MaxTokens,
-		Temperature: e.cfg.Temperature,
+		Temperature: settings.Temperature,
 		Messages: []openai.ChatCompletionMessage{
 			{
 				Role:    openai.ChatMessageRoleSystem,
@@ -106,19 +124,25 @@ func (e *OpenAIEvaluator) Evaluate(parent context.Context, input EvaluationInput
 		ResponseFormat: &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject},
 	}
 
-	resp, err := e.client.CreateChatCompletion(ctx, request)
+	var resp openai.ChatCompletionResponse
+	err := e.retry.do(ctx, settings.Model, func(ctx context.Context) error {
+		var callErr error
+		resp, callErr = e.client.CreateChatCompletion(ctx, request)
+		return callErr
+	})
 	duration := time.Since(start)
-	aiDuration.WithLabelValues(e.cfg.Model).Observe(duration.Seconds())
+	aiDuration.WithLabelValues(settings.Model).Observe(duration.Seconds())
 	if err != nil {
-		aiFailures.WithLabelValues(e.cfg.Model).Inc()
+		aiFailures.WithLabelValues(settings.Model).Inc()
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return EvaluationResult{}, fmt.Errorf("openai evaluate: %w", err)
 	}
+	recordUsage(settings.Model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, e.cfg.Prices)
 
 	if len(resp.Choices) == 0 {
 		err := fmt.Errorf("no choices returned from openai")
-		aiFailures.WithLabelValues(e.cfg.Model).Inc()
+		aiFailures.WithLabelValues(settings.Model).Inc()
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return EvaluationResult{}, err
@@ -127,7 +151,7 @@ func (e *OpenAIEvaluator) Evaluate(parent context.Context, input EvaluationInput
 	content := strings.TrimSpace(resp.Choices[0].Message.Content)
 	result, err := parseEvaluationResponse(content)
 	if err != nil {
-		aiFailures.WithLabelValues(e.cfg.Model).Inc()
+		aiFailures.WithLabelValues(settings.Model).Inc()
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return EvaluationResult{}, err
@@ -196,3 +220,53 @@ func parseEvaluationResponse(content string) (EvaluationResult, error) {
 		Details:  data.Details,
 	}, nil
 }
+
+// extractJSONObject returns the first balanced JSON object in content, looking
+// inside a Markdown code fence when one is present. Models without a strict
+// JSON mode often wrap the object in prose or fences. When no object is found
+// the trimmed content is returned so parsing reports the failure.
+func extractJSONObject(content string) string {
+	text := strings.TrimSpace(content)
+	if start := strings.Index(text, "```"); start >= 0 {
+		fenced := text[start+3:]
+		if newline := strings.IndexByte(fenced, '\n'); newline >= 0 {
+			fenced = fenced[newline+1:]
+		}
+		if end := strings.Index(fenced, "```"); end >= 0 {
+			fenced = fenced[:end]
+		}
+		if strings.Contains(fenced, "{") {
+			text = fenced
+		}
+	}
+
+	start := strings.IndexByte(text, '{')
+	if start < 0 {
+		return text
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(text); i++ {
+		ch := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && ch == '\\':
+			escaped = true
+		case ch == '"':
+			inString = !inString
+		case inString:
+		case ch == '{':
+			depth++
+		case ch == '}':
+			depth--
+			if depth == 0 {
+				return text[start : i+1]
+			}
+		}
+	}
+
+	return text[start:]
+}
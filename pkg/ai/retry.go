@@ -0,0 +1,130 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+const (
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	maxRetryDelay         = 10 * time.Second
+)
+
+var aiRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gema",
+	Subsystem: "ai",
+	Name:      "evaluation_retries_total",
+	Help:      "Number of AI evaluation requests retried after a transient failure",
+}, []string{"model"})
+
+// retryPolicy retries transient provider failures with exponential backoff
+// and full jitter.
+type retryPolicy struct {
+	maxRetries int
+	baseDelay  time.Duration
+	logger     zerolog.Logger
+}
+
+func newRetryPolicy(maxRetries int, baseDelay time.Duration, logger zerolog.Logger) retryPolicy {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if baseDelay <= 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+	return retryPolicy{maxRetries: maxRetries, baseDelay: baseDelay, logger: logger}
+}
+
+// do calls fn until it succeeds, fails with a non-retryable error, runs out of
+// attempts, or the next backoff would outlive the context deadline.
+func (p retryPolicy) do(ctx context.Context, model string, fn func(context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || attempt >= p.maxRetries || ctx.Err() != nil || !isRetryableError(err) {
+			return err
+		}
+
+		delay := p.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+
+		aiRetries.WithLabelValues(model).Inc()
+		p.logger.Warn().Err(err).Int("attempt", attempt+1).Dur("backoff", delay).Msg("retrying ai evaluation")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.baseDelay << attempt
+	if ceiling <= 0 || ceiling > maxRetryDelay {
+		ceiling = maxRetryDelay
+	}
+	return ceiling/2 + time.Duration(rand.Int63n(int64(ceiling/2)+1))
+}
+
+// providerError is an HTTP error returned by an AI provider.
+type providerError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *providerError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("status %d: %s: %s", e.StatusCode, e.Type, e.Message)
+}
+
+// isRetryableError reports whether err is a rate limit, timeout or server
+// error. Authentication and validation failures are never retried.
+func isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var provider *providerError
+	if errors.As(err, &provider) {
+		return retryableStatus(provider.StatusCode)
+	}
+
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return retryableStatus(apiErr.HTTPStatusCode)
+	}
+
+	var requestErr *openai.RequestError
+	if errors.As(err, &requestErr) {
+		return retryableStatus(requestErr.HTTPStatusCode)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+func retryableStatus(status int) bool {
+	return status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
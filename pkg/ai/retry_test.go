@@ -0,0 +1,105 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/require"
+)
+
+func flakyAnthropicServer(t *testing.T, failures int32, status int) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"try again"}}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": cannedEvaluation}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestAnthropicEvaluatorRetriesTransientFailures(t *testing.T) {
+	server, calls := flakyAnthropicServer(t, 2, 529)
+
+	evaluator, err := NewAnthropicEvaluator(AnthropicConfig{APIKey: "test-key", BaseURL: server.URL, MaxRetries: 3, RetryBaseDelay: time.Millisecond})
+	require.NoError(t, err)
+
+	result, err := evaluator.Evaluate(context.Background(), EvaluationInput{})
+	require.NoError(t, err)
+	require.Equal(t, "pass", result.Verdict)
+	require.EqualValues(t, 3, atomic.LoadInt32(calls))
+}
+
+func TestAnthropicEvaluatorRetriesNonJSONServerErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("<html><body>502 Bad Gateway</body></html>"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": cannedEvaluation}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	evaluator, err := NewAnthropicEvaluator(AnthropicConfig{APIKey: "test-key", BaseURL: server.URL, MaxRetries: 3, RetryBaseDelay: time.Millisecond})
+	require.NoError(t, err)
+
+	result, err := evaluator.Evaluate(context.Background(), EvaluationInput{})
+	require.NoError(t, err)
+	require.Equal(t, "pass", result.Verdict)
+	require.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestAnthropicEvaluatorFailsFastOnAuthErrors(t *testing.T) {
+	server, calls := flakyAnthropicServer(t, 5, http.StatusUnauthorized)
+
+	evaluator, err := NewAnthropicEvaluator(AnthropicConfig{APIKey: "test-key", BaseURL: server.URL, MaxRetries: 3, RetryBaseDelay: time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = evaluator.Evaluate(context.Background(), EvaluationInput{})
+	require.Error(t, err)
+	require.EqualValues(t, 1, atomic.LoadInt32(calls))
+}
+
+func TestAnthropicEvaluatorStopsRetryingAtDeadline(t *testing.T) {
+	server, calls := flakyAnthropicServer(t, 5, http.StatusTooManyRequests)
+
+	evaluator, err := NewAnthropicEvaluator(AnthropicConfig{APIKey: "test-key", BaseURL: server.URL, MaxRetries: 3, RetryBaseDelay: time.Minute})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err = evaluator.Evaluate(ctx, EvaluationInput{})
+	require.ErrorContains(t, err, "status 429")
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+	require.EqualValues(t, 1, atomic.LoadInt32(calls))
+}
+
+func TestIsRetryableError(t *testing.T) {
+	require.True(t, isRetryableError(&openai.APIError{HTTPStatusCode: http.StatusTooManyRequests}))
+	require.True(t, isRetryableError(&openai.RequestError{HTTPStatusCode: http.StatusBadGateway}))
+	require.False(t, isRetryableError(&openai.APIError{HTTPStatusCode: http.StatusUnauthorized}))
+	require.False(t, isRetryableError(&providerError{StatusCode: http.StatusBadRequest}))
+	require.False(t, isRetryableError(context.Canceled))
+	require.False(t, isRetryableError(errors.New("parse evaluation json")))
+}
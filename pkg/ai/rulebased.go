@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Output match levels reported by the rule-based evaluator, strictest first.
+const (
+	RuleMatchExact      = "exact"
+	RuleMatchTrimmed    = "trimmed"
+	RuleMatchWhitespace = "whitespace"
+	RuleMatchNone       = "none"
+)
+
+// RuleBasedEvaluator grades a submission by comparing its output with the
+// expected output. It makes no network calls, so it suits tests, air-gapped
+// installs and output-only tasks.
+type RuleBasedEvaluator struct{}
+
+// NewRuleBasedEvaluator builds an offline evaluator.
+func NewRuleBasedEvaluator() *RuleBasedEvaluator {
+	return &RuleBasedEvaluator{}
+}
+
+// Evaluate scores the output: 1 for an exact or trimmed match, 0.9 when only
+// whitespace differs, and otherwise half the share of expected lines matched.
+// Without an expected output the submission is left for manual review.
+func (r *RuleBasedEvaluator) Evaluate(ctx context.Context, input EvaluationInput) (EvaluationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return EvaluationResult{}, err
+	}
+
+	if strings.TrimSpace(input.ExpectedOutput) == "" {
+		return EvaluationResult{
+			Verdict:  "review",
+			Feedback: "This task has no expected output, so it needs manual review.",
+			Details:  map[string]interface{}{"match": RuleMatchNone},
+		}, nil
+	}
+
+	expected, actual := input.ExpectedOutput, input.SubmissionOutput
+	switch {
+	case expected == actual:
+		return ruleResult(1, "pass", RuleMatchExact, "Output matches the expected output exactly."), nil
+	case trimLines(expected) == trimLines(actual):
+		return ruleResult(1, "pass", RuleMatchTrimmed, "Output matches the expected output apart from trailing whitespace."), nil
+	case strings.Join(strings.Fields(expected), " ") == strings.Join(strings.Fields(actual), " "):
+		return ruleResult(0.9, "pass", RuleMatchWhitespace, "Output has the right content but the spacing or line breaks differ."), nil
+	}
+
+	expectedLines := strings.Split(trimLines(expected), "\n")
+	actualLines := strings.Split(trimLines(actual), "\n")
+	matched := 0
+	for i, line := range expectedLines {
+		if i < len(actualLines) && actualLines[i] == line {
+			matched++
+		}
+	}
+
+	result := ruleResult(0.5*float64(matched)/float64(len(expectedLines)), "fail", RuleMatchNone,
+		fmt.Sprintf("Output does not match the expected output: %d of %d lines are correct.", matched, len(expectedLines)))
+	result.Details["matched_lines"] = matched
+	result.Details["expected_lines"] = len(expectedLines)
+	if strings.TrimSpace(actual) == "" {
+		result.Feedback = "The program produced no output."
+	}
+	return result, nil
+}
+
+func ruleResult(score float64, verdict, match, feedback string) EvaluationResult {
+	return EvaluationResult{
+		Score:    score,
+		Verdict:  verdict,
+		Feedback: feedback,
+		Details:  map[string]interface{}{"match": match},
+	}
+}
+
+// trimLines normalises line endings, drops trailing spaces on every line and
+// strips leading and trailing blank lines.
+func trimLines(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	lines := strings.Split(value, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
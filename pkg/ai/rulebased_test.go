@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleBasedEvaluator(t *testing.T) {
+	evaluator := NewRuleBasedEvaluator()
+	ctx := context.Background()
+
+	cases := []struct {
+		name     string
+		expected string
+		actual   string
+		score    float64
+		verdict  string
+		match    string
+	}{
+		{name: "exact", expected: "1\n2\n", actual: "1\n2\n", score: 1, verdict: "pass", match: RuleMatchExact},
+		{name: "trimmed", expected: "1\n2", actual: "1  \r\n2\n\n", score: 1, verdict: "pass", match: RuleMatchTrimmed},
+		{name: "whitespace", expected: "1 2 3", actual: "1\n2\n3", score: 0.9, verdict: "pass", match: RuleMatchWhitespace},
+		{name: "partial", expected: "a\nb\nc\nd", actual: "a\nb\nx", score: 0.25, verdict: "fail", match: RuleMatchNone},
+		{name: "empty", expected: "a", actual: "", score: 0, verdict: "fail", match: RuleMatchNone},
+		{name: "no expected output", expected: " ", actual: "a", score: 0, verdict: "review", match: RuleMatchNone},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := evaluator.Evaluate(ctx, EvaluationInput{ExpectedOutput: tc.expected, SubmissionOutput: tc.actual})
+			require.NoError(t, err)
+			require.InDelta(t, tc.score, result.Score, 1e-9)
+			require.Equal(t, tc.verdict, result.Verdict)
+			require.Equal(t, tc.match, result.Details["match"])
+			require.NotEmpty(t, result.Feedback)
+		})
+	}
+}
@@ -27,3 +27,22 @@ type EvaluationResult struct {
 type Evaluator interface {
 	Evaluate(ctx context.Context, input EvaluationInput) (EvaluationResult, error)
 }
+
+// ModelSettings are the generation settings an evaluator reads on every call.
+type ModelSettings struct {
+	Model       string
+	Temperature float32
+}
+
+// resolveSettings returns the settings reported by source, falling back to
+// the configured ones when source is nil or reports no model.
+func resolveSettings(configured ModelSettings, source func() ModelSettings) ModelSettings {
+	if source == nil {
+		return configured
+	}
+	current := source()
+	if current.Model == "" {
+		current.Model = configured.Model
+	}
+	return current
+}
@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	tokenKindPrompt     = "prompt"
+	tokenKindCompletion = "completion"
+)
+
+var (
+	aiTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gema",
+		Subsystem: "ai",
+		Name:      "tokens_total",
+		Help:      "Tokens consumed by AI evaluations, by model and kind (prompt or completion)",
+	}, []string{"model", "kind"})
+
+	aiCost = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gema",
+		Subsystem: "ai",
+		Name:      "cost_usd_total",
+		Help:      "Estimated AI evaluation spend in USD, from the configured token prices",
+	}, []string{"model"})
+)
+
+// TokenPrice is what a model charges in USD per million tokens.
+type TokenPrice struct {
+	Prompt     float64
+	Completion float64
+}
+
+// recordUsage counts the tokens a provider reported for one evaluation and,
+// when the model has a price, the estimated cost.
+func recordUsage(model string, promptTokens, completionTokens int, prices map[string]TokenPrice) {
+	if promptTokens > 0 {
+		aiTokens.WithLabelValues(model, tokenKindPrompt).Add(float64(promptTokens))
+	}
+	if completionTokens > 0 {
+		aiTokens.WithLabelValues(model, tokenKindCompletion).Add(float64(completionTokens))
+	}
+
+	price, ok := prices[model]
+	if !ok {
+		return
+	}
+	if cost := (float64(promptTokens)*price.Prompt + float64(completionTokens)*price.Completion) / 1e6; cost > 0 {
+		aiCost.WithLabelValues(model).Add(cost)
+	}
+}
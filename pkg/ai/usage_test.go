@@ -0,0 +1,21 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordUsageCountsTokensAndCost(t *testing.T) {
+	prompt := aiTokens.WithLabelValues("usage-test-model", tokenKindPrompt)
+	completion := aiTokens.WithLabelValues("usage-test-model", tokenKindCompletion)
+	cost := aiCost.WithLabelValues("usage-test-model")
+
+	recordUsage("usage-test-model", 1000, 200, map[string]TokenPrice{"usage-test-model": {Prompt: 0.15, Completion: 0.6}})
+	recordUsage("usage-test-model", 500, 0, nil)
+
+	require.Equal(t, 1500.0, testutil.ToFloat64(prompt))
+	require.Equal(t, 200.0, testutil.ToFloat64(completion))
+	require.InDelta(t, 0.00027, testutil.ToFloat64(cost), 1e-12)
+}
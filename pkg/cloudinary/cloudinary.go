@@ -2,13 +2,22 @@ package cloudinary
 
 import (
 	"context"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/rs/zerolog"
 )
@@ -23,9 +32,15 @@ type Config struct {
 
 // Service implements the FileUploader interface using Cloudinary.
 type Service struct {
-	client *cloudinary.Cloudinary
-	folder string
-	logger zerolog.Logger
+	client    *cloudinary.Cloudinary
+	cloudName string
+	apiKey    string
+	apiSecret string
+	folder    string
+	// deliveryType is "upload" for public assets and "authenticated" for
+	// assets only reachable through signed URLs.
+	deliveryType api.DeliveryType
+	logger       zerolog.Logger
 }
 
 // New constructs a Cloudinary service instance.
@@ -40,12 +55,25 @@ func New(cfg Config, logger zerolog.Logger) (*Service, error) {
 	}
 
 	return &Service{
-		client: cld,
-		folder: cfg.Folder,
-		logger: logger.With().Str("component", "cloudinary").Logger(),
+		client:       cld,
+		cloudName:    cfg.CloudName,
+		apiKey:       cfg.APIKey,
+		apiSecret:    cfg.APISecret,
+		folder:       cfg.Folder,
+		deliveryType: api.Upload,
+		logger:       logger.With().Str("component", "cloudinary").Logger(),
 	}, nil
 }
 
+// Private returns a service that stores uploads as authenticated assets.
+// Their delivery URLs are refused without a signature, so the files are only
+// reachable through SignDownload.
+func (s *Service) Private() *Service {
+	private := *s
+	private.deliveryType = api.Authenticated
+	return &private
+}
+
 // Upload sends the file to Cloudinary and returns a secure URL.
 func (s *Service) Upload(ctx context.Context, name string, reader io.Reader) (string, error) {
 	folder := strings.Trim(s.folder, "/")
@@ -55,6 +83,7 @@ func (s *Service) Upload(ctx context.Context, name string, reader io.Reader) (st
 		Folder:       folder,
 		PublicID:     publicID,
 		ResourceType: "auto",
+		Type:         s.deliveryType,
 	}
 
 	result, err := s.client.Upload.Upload(ctx, reader, params)
@@ -67,6 +96,253 @@ func (s *Service) Upload(ctx context.Context, name string, reader io.Reader) (st
 	return result.SecureURL, nil
 }
 
+// Delete removes the asset behind a delivery URL returned by Upload. Assets
+// that are already gone count as deleted.
+func (s *Service) Delete(ctx context.Context, assetURL string) error {
+	resourceType, deliveryType, publicID, err := s.parseAssetURL(assetURL)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.client.Upload.Destroy(ctx, uploader.DestroyParams{PublicID: publicID, Type: deliveryType, ResourceType: resourceType})
+	if err != nil {
+		return fmt.Errorf("failed to delete asset: %w", err)
+	}
+	if result.Error.Message != "" {
+		return fmt.Errorf("failed to delete asset: %s", result.Error.Message)
+	}
+	if result.Result != "ok" && result.Result != "not found" {
+		return fmt.Errorf("failed to delete asset: %s", result.Result)
+	}
+
+	s.logger.Info().Str("public_id", publicID).Str("result", result.Result).Msg("file deleted from cloudinary")
+
+	return nil
+}
+
+// parseAssetURL extracts the resource type, delivery type and public ID from
+// a delivery URL of the form
+// <prefix><resource_type>/<type>/[s--<signature>--/]v<version>/<public_id>.<format>,
+// where type is upload or authenticated. Raw assets keep their extension as
+// part of the public ID.
+func (s *Service) parseAssetURL(assetURL string) (string, string, string, error) {
+	rest, ok := strings.CutPrefix(assetURL, s.AssetURLPrefix())
+	if !ok {
+		return "", "", "", fmt.Errorf("%q is not an asset of cloud %s", assetURL, s.cloudName)
+	}
+
+	parts := strings.SplitN(rest, "/", 3)
+	if len(parts) != 3 || (parts[1] != string(api.Upload) && parts[1] != api.Authenticated) {
+		return "", "", "", fmt.Errorf("%q is not an uploaded asset URL", assetURL)
+	}
+	resourceType, deliveryType, path := parts[0], parts[1], parts[2]
+
+	if signature, remainder, found := strings.Cut(path, "/"); found && isSignatureSegment(signature) {
+		path = remainder
+	}
+	if version, remainder, found := strings.Cut(path, "/"); found && isVersionSegment(version) {
+		path = remainder
+	}
+	if resourceType != "raw" {
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	publicID, err := url.PathUnescape(path)
+	if err != nil || publicID == "" {
+		return "", "", "", fmt.Errorf("%q is not an uploaded asset URL", assetURL)
+	}
+
+	return resourceType, deliveryType, publicID, nil
+}
+
+// isSignatureSegment matches the s--<signature>-- component Cloudinary adds
+// to signed delivery URLs.
+func isSignatureSegment(segment string) bool {
+	return len(segment) > 5 && strings.HasPrefix(segment, "s--") && strings.HasSuffix(segment, "--")
+}
+
+func isVersionSegment(segment string) bool {
+	if len(segment) < 2 || segment[0] != 'v' {
+		return false
+	}
+	_, err := strconv.ParseUint(segment[1:], 10, 64)
+	return err == nil
+}
+
+// UploadConstraints restricts what a signed upload may store. Transformation
+// is an incoming transformation Cloudinary applies to images before storing
+// them. All are part of the signature, so the browser cannot relax them.
+type UploadConstraints struct {
+	AllowedFormats []string
+	MaxFileSize    int64
+	Transformation string
+}
+
+// SignedUpload carries the form fields a browser posts to Cloudinary's upload
+// API alongside the file.
+type SignedUpload struct {
+	UploadURL      string
+	CloudName      string
+	APIKey         string
+	Timestamp      int64
+	Folder         string
+	PublicID       string
+	AllowedFormats string
+	MaxFileSize    int64
+	Transformation string
+	Signature      string
+}
+
+// SignUpload signs an upload of publicID into the configured folder.
+// Cloudinary rejects signatures whose timestamp is more than an hour old.
+func (s *Service) SignUpload(publicID string, constraints UploadConstraints, now time.Time) SignedUpload {
+	folder := s.Folder()
+	timestamp := now.Unix()
+	formats := strings.Join(constraints.AllowedFormats, ",")
+	params := map[string]string{
+		"allowed_formats": formats,
+		"folder":          folder,
+		"public_id":       publicID,
+		"timestamp":       strconv.FormatInt(timestamp, 10),
+		"transformation":  constraints.Transformation,
+	}
+	if constraints.MaxFileSize > 0 {
+		params["max_file_size"] = strconv.FormatInt(constraints.MaxFileSize, 10)
+	}
+
+	return SignedUpload{
+		UploadURL:      fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/auto/upload", s.cloudName),
+		CloudName:      s.cloudName,
+		APIKey:         s.apiKey,
+		Timestamp:      timestamp,
+		Folder:         folder,
+		PublicID:       publicID,
+		AllowedFormats: formats,
+		MaxFileSize:    constraints.MaxFileSize,
+		Transformation: constraints.Transformation,
+		Signature:      signParams(params, s.apiSecret),
+	}
+}
+
+// ErrAssetNotFound indicates Cloudinary has no asset with the requested ID.
+var ErrAssetNotFound = errors.New("cloudinary asset not found")
+
+// Asset describes a stored asset as Cloudinary reports it.
+type Asset struct {
+	PublicID     string
+	Version      int64
+	ResourceType string
+	Format       string
+	Bytes        int64
+	Etag         string
+	Width        int
+	Height       int
+	SecureURL    string
+}
+
+// Asset looks up an uploaded asset through the Admin API. Callers use it to
+// rely on what Cloudinary stored rather than on what a client reports.
+func (s *Service) Asset(ctx context.Context, resourceType, publicID string) (Asset, error) {
+	result, err := s.client.Admin.Asset(ctx, admin.AssetParams{
+		AssetType:    api.AssetType(resourceType),
+		DeliveryType: api.Upload,
+		PublicID:     publicID,
+	})
+	if err != nil {
+		return Asset{}, fmt.Errorf("failed to look up asset: %w", err)
+	}
+	if result.Error.Message != "" {
+		if strings.Contains(strings.ToLower(result.Error.Message), "not found") {
+			return Asset{}, ErrAssetNotFound
+		}
+		return Asset{}, fmt.Errorf("failed to look up asset: %s", result.Error.Message)
+	}
+
+	return Asset{
+		PublicID:     result.PublicID,
+		Version:      int64(result.Version),
+		ResourceType: result.ResourceType,
+		Format:       result.Format,
+		Bytes:        int64(result.Bytes),
+		Etag:         result.Etag,
+		Width:        result.Width,
+		Height:       result.Height,
+		SecureURL:    result.SecureURL,
+	}, nil
+}
+
+// VerifyUploadSignature reports whether signature is the one Cloudinary
+// returns for an upload of publicID at version, proving the upload response
+// came from Cloudinary rather than the client.
+func (s *Service) VerifyUploadSignature(publicID, version, signature string) bool {
+	expected := signParams(map[string]string{"public_id": publicID, "version": version}, s.apiSecret)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(signature))) == 1
+}
+
+// SignDownload returns a private download URL for the asset behind assetURL,
+// a delivery URL returned by Upload. Cloudinary serves it as an attachment
+// until expiresAt. The URL is signed for the asset's own delivery type, so it
+// also works for authenticated assets, whose delivery URLs are refused.
+func (s *Service) SignDownload(assetURL string, now, expiresAt time.Time) (string, error) {
+	resourceType, deliveryType, publicID, err := s.parseAssetURL(assetURL)
+	if err != nil {
+		return "", err
+	}
+
+	params := map[string]string{
+		"attachment": "true",
+		"expires_at": strconv.FormatInt(expiresAt.Unix(), 10),
+		"public_id":  publicID,
+		"timestamp":  strconv.FormatInt(now.Unix(), 10),
+		"type":       deliveryType,
+	}
+	if resourceType != "raw" {
+		params["format"] = strings.TrimPrefix(filepath.Ext(assetURL), ".")
+	}
+
+	query := url.Values{}
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	query.Set("api_key", s.apiKey)
+	query.Set("signature", signParams(params, s.apiSecret))
+
+	return fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/%s/download?%s", s.cloudName, resourceType, query.Encode()), nil
+}
+
+// AssetURLPrefix is the delivery URL prefix every asset of the cloud shares.
+func (s *Service) AssetURLPrefix() string {
+	return fmt.Sprintf("https://res.cloudinary.com/%s/", s.cloudName)
+}
+
+// Folder is the folder uploads are stored in, without surrounding slashes.
+func (s *Service) Folder() string {
+	return strings.Trim(s.folder, "/")
+}
+
+// signParams implements Cloudinary's request signature: the parameters
+// sorted by name, joined as key=value pairs with '&', suffixed with the API
+// secret and hashed with SHA-1.
+func signParams(params map[string]string, secret string) string {
+	keys := make([]string, 0, len(params))
+	for key, value := range params {
+		if value == "" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+params[key])
+	}
+
+	sum := sha1.Sum([]byte(strings.Join(pairs, "&") + secret))
+	return hex.EncodeToString(sum[:])
+}
+
 func buildPublicID(name string) string {
 	base := strings.TrimSuffix(name, filepath.Ext(name))
 	base = strings.Map(func(r rune) rune {
@@ -0,0 +1,123 @@
+package cloudinary
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignParamsMatchesCloudinaryExample(t *testing.T) {
+	// Example from Cloudinary's "Generating authentication signatures" guide.
+	signature := signParams(map[string]string{
+		"eager":     "w_400,h_300,c_pad|w_260,h_200,c_crop",
+		"public_id": "sample_image",
+		"timestamp": "1315060510",
+	}, "abcd")
+
+	require.Equal(t, "bfd09f95f331f558cbd1320e67aa8d488770583e", signature)
+}
+
+func TestSignUploadAndVerify(t *testing.T) {
+	svc := &Service{cloudName: "demo", apiKey: "key", apiSecret: "abcd", folder: "/gema/uploads/"}
+	now := time.Unix(1700000000, 0)
+
+	signed := svc.SignUpload("report-1", UploadConstraints{AllowedFormats: []string{"pdf", "zip"}, MaxFileSize: 1048576, Transformation: "a_exif"}, now)
+	require.Equal(t, "https://api.cloudinary.com/v1_1/demo/auto/upload", signed.UploadURL)
+	require.Equal(t, "gema/uploads", signed.Folder)
+	require.Equal(t, int64(1700000000), signed.Timestamp)
+	require.Equal(t, "pdf,zip", signed.AllowedFormats)
+	require.Equal(t, int64(1048576), signed.MaxFileSize)
+	require.Equal(t, "a_exif", signed.Transformation)
+	require.Equal(t, signParams(map[string]string{
+		"allowed_formats": "pdf,zip",
+		"folder":          "gema/uploads",
+		"max_file_size":   "1048576",
+		"public_id":       "report-1",
+		"timestamp":       "1700000000",
+		"transformation":  "a_exif",
+	}, "abcd"), signed.Signature)
+
+	unconstrained := svc.SignUpload("report-1", UploadConstraints{}, now)
+	require.Equal(t, signParams(map[string]string{
+		"folder":    "gema/uploads",
+		"public_id": "report-1",
+		"timestamp": "1700000000",
+	}, "abcd"), unconstrained.Signature)
+
+	response := signParams(map[string]string{"public_id": "gema/uploads/report-1", "version": "1700000005"}, "abcd")
+	require.True(t, svc.VerifyUploadSignature("gema/uploads/report-1", "1700000005", response))
+	require.False(t, svc.VerifyUploadSignature("gema/uploads/report-2", "1700000005", response))
+}
+
+func TestParseAssetURL(t *testing.T) {
+	svc := &Service{cloudName: "demo"}
+
+	resourceType, deliveryType, publicID, err := svc.parseAssetURL("https://res.cloudinary.com/demo/image/upload/v1700000005/gema/uploads/photo-1.png")
+	require.NoError(t, err)
+	require.Equal(t, "image", resourceType)
+	require.Equal(t, "upload", deliveryType)
+	require.Equal(t, "gema/uploads/photo-1", publicID)
+
+	resourceType, deliveryType, publicID, err = svc.parseAssetURL("https://res.cloudinary.com/demo/raw/upload/v1700000005/gema/uploads/archive-1.zip")
+	require.NoError(t, err)
+	require.Equal(t, "raw", resourceType)
+	require.Equal(t, "upload", deliveryType)
+	require.Equal(t, "gema/uploads/archive-1.zip", publicID)
+
+	resourceType, deliveryType, publicID, err = svc.parseAssetURL("https://res.cloudinary.com/demo/image/authenticated/s--Xk3Lq9ab--/v1700000005/gema/uploads/report-1.pdf")
+	require.NoError(t, err)
+	require.Equal(t, "image", resourceType)
+	require.Equal(t, "authenticated", deliveryType)
+	require.Equal(t, "gema/uploads/report-1", publicID)
+
+	_, _, _, err = svc.parseAssetURL("https://res.cloudinary.com/other/image/upload/v1/photo.png")
+	require.Error(t, err)
+
+	_, _, _, err = svc.parseAssetURL("https://res.cloudinary.com/demo/image/fetch/photo.png")
+	require.Error(t, err)
+}
+
+func TestSignDownload(t *testing.T) {
+	svc := &Service{cloudName: "demo", apiKey: "key", apiSecret: "abcd"}
+	now := time.Unix(1700000000, 0)
+
+	signed, err := svc.SignDownload("https://res.cloudinary.com/demo/image/upload/v1700000005/gema/uploads/report-1.pdf", now, now.Add(5*time.Minute))
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(signed)
+	require.NoError(t, err)
+	require.Equal(t, "/v1_1/demo/image/download", parsed.Path)
+	query := parsed.Query()
+	require.Equal(t, "gema/uploads/report-1", query.Get("public_id"))
+	require.Equal(t, "pdf", query.Get("format"))
+	require.Equal(t, "1700000300", query.Get("expires_at"))
+	require.Equal(t, "key", query.Get("api_key"))
+	require.Equal(t, signParams(map[string]string{
+		"attachment": "true",
+		"expires_at": "1700000300",
+		"format":     "pdf",
+		"public_id":  "gema/uploads/report-1",
+		"timestamp":  "1700000000",
+		"type":       "upload",
+	}, "abcd"), query.Get("signature"))
+
+	_, err = svc.SignDownload("https://example.com/report-1.pdf", now, now.Add(time.Minute))
+	require.Error(t, err)
+
+	signed, err = svc.Private().SignDownload("https://res.cloudinary.com/demo/raw/authenticated/s--Xk3Lq9ab--/v1700000005/gema/uploads/essay-1.txt", now, now.Add(5*time.Minute))
+	require.NoError(t, err)
+	parsed, err = url.Parse(signed)
+	require.NoError(t, err)
+	require.Equal(t, "/v1_1/demo/raw/download", parsed.Path)
+	query = parsed.Query()
+	require.Equal(t, "authenticated", query.Get("type"))
+	require.Equal(t, signParams(map[string]string{
+		"attachment": "true",
+		"expires_at": "1700000300",
+		"public_id":  "gema/uploads/essay-1.txt",
+		"timestamp":  "1700000000",
+		"type":       "authenticated",
+	}, "abcd"), query.Get("signature"))
+}
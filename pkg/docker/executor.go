@@ -7,10 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
@@ -48,23 +51,82 @@ var (
 	}, []string{"image"})
 )
 
+// ErrImageNotAllowed indicates the requested image is not on the executor allowlist.
+var ErrImageNotAllowed = errors.New("image not allowed")
+
 // Executor defines the behaviour for running code inside a sandboxed container.
 type Executor interface {
 	Run(ctx context.Context, req ExecutionRequest) (ExecutionResult, error)
 }
 
+// StreamingExecutor runs code while forwarding its output as it is produced.
+type StreamingExecutor interface {
+	Executor
+	RunStream(ctx context.Context, req ExecutionRequest, onChunk func(ExecutionChunk)) (ExecutionResult, error)
+}
+
+// Output stream names carried by ExecutionChunk.
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// ExecutionChunk is a piece of container output delivered while the program runs.
+type ExecutionChunk struct {
+	Stream string
+	Data   []byte
+}
+
+// logDrainTimeout bounds how long a streamed run waits for buffered log
+// frames after the container has exited.
+const logDrainTimeout = 2 * time.Second
+
+// DefaultMaxOutputBytes caps the log output read back from a container when
+// neither the request nor the executor configuration sets a limit.
+const DefaultMaxOutputBytes int64 = 1 << 20
+
+// defaultCPUPeriod is the CFS period, in microseconds, used when only a CPU
+// quota is configured.
+const defaultCPUPeriod int64 = 100000
+
 // ExecutionRequest describes the instruction to run a piece of code inside a container.
 type ExecutionRequest struct {
-	Image           string
-	Cmd             []string
-	Env             []string
-	Timeout         time.Duration
-	Workspace       string
-	WorkingDir      string
-	MemoryLimitMB   int64
-	CPUShares       int64
+	Image   string
+	Cmd     []string
+	Env     []string
+	Stdin   []byte
+	Timeout time.Duration
+	// Workspace is a host directory bind-mounted read-only at the working
+	// directory, so a program can only write to the /tmp scratch space
+	// DiskQuotaMB caps. WritableWorkspace lifts that for build steps.
+	Workspace     string
+	WorkingDir    string
+	MemoryLimitMB int64
+	CPUShares     int64
+	// CPUQuota and CPUPeriod (microseconds) cap CPU time per period; a
+	// quota of 50000 over a 100000 period limits the container to half a
+	// core. They take precedence over the relative CPUShares weight.
+	CPUQuota  int64
+	CPUPeriod int64
+	// PidsLimit caps the number of processes and threads in the container.
+	PidsLimit int64
+	// DiskQuotaMB sizes the tmpfs mounted at /tmp, the scratch space
+	// programs write to.
+	DiskQuotaMB int64
+	// WritableWorkspace mounts the workspace read-write so a build step can
+	// leave artifacts for later runs. The quota does not cover such writes;
+	// builds should produce their output under /tmp and copy it over, which
+	// keeps the artifact within the quota.
+	WritableWorkspace bool
+	// MaxOutputBytes caps how much of the container's multiplexed
+	// stdout/stderr log is read; the rest is discarded and the result is
+	// marked Truncated.
+	MaxOutputBytes  int64
 	NetworkDisabled bool
-	ReadOnlyFS      bool
+	// ReadOnlyFS mounts the image's root filesystem read-only. Together with
+	// a read-only workspace it leaves /tmp as the only writable path, so
+	// everything a program writes counts against DiskQuotaMB.
+	ReadOnlyFS bool
 }
 
 // ExecutionResult summarises the outcome of a container execution.
@@ -74,6 +136,7 @@ type ExecutionResult struct {
 	ExitCode         int
 	Duration         time.Duration
 	TimedOut         bool
+	Truncated        bool
 	MemoryUsageBytes int64
 	CPUUsageNanosec  uint64
 }
@@ -84,16 +147,36 @@ type Config struct {
 	Timeout       time.Duration
 	MemoryLimitMB int64
 	CPUShares     int64
+	CPUQuota      int64
+	CPUPeriod     int64
+	PidsLimit     int64
+	DiskQuotaMB   int64
+	// MaxOutputBytes is the default for ExecutionRequest.MaxOutputBytes;
+	// zero falls back to DefaultMaxOutputBytes.
+	MaxOutputBytes int64
+	// MaxConcurrent caps simultaneous executions; zero runs without a limit.
+	// Up to MaxQueue further runs wait at most QueueTimeout for a slot before
+	// failing with ErrExecutorBusy.
+	MaxConcurrent int
+	MaxQueue      int
+	QueueTimeout  time.Duration
 	WorkingDir    string
+	// AllowedImages restricts which images may be run or pulled. Empty allows any image.
+	AllowedImages []string
 	Logger        zerolog.Logger
 }
 
 // DockerExecutor implements code execution using Docker containers.
 type DockerExecutor struct {
-	client *client.Client
-	cfg    Config
-	tracer trace.Tracer
-	logger zerolog.Logger
+	client  *client.Client
+	cfg     Config
+	tracer  trace.Tracer
+	logger  zerolog.Logger
+	allowed map[string]struct{}
+	limiter *limiter
+
+	mu    sync.Mutex
+	ready map[string]struct{}
 }
 
 // NewDockerExecutor constructs a Docker backed executor.
@@ -119,30 +202,150 @@ func NewDockerExecutor(cfg Config) (*DockerExecutor, error) {
 		logger = zerolog.Nop()
 	}
 
+	var allowed map[string]struct{}
+	if len(cfg.AllowedImages) > 0 {
+		allowed = make(map[string]struct{}, len(cfg.AllowedImages))
+		for _, image := range cfg.AllowedImages {
+			if trimmed := strings.TrimSpace(image); trimmed != "" {
+				allowed[trimmed] = struct{}{}
+			}
+		}
+	}
+
 	return &DockerExecutor{
-		client: cli,
-		cfg:    cfg,
-		tracer: tracer,
-		logger: logger,
+		client:  cli,
+		cfg:     cfg,
+		tracer:  tracer,
+		logger:  logger,
+		allowed: allowed,
+		limiter: newLimiter(cfg.MaxConcurrent, cfg.MaxQueue, cfg.QueueTimeout),
+		ready:   make(map[string]struct{}),
 	}, nil
 }
 
+func (e *DockerExecutor) checkImage(image string) error {
+	if e.allowed == nil {
+		return nil
+	}
+	if _, ok := e.allowed[image]; !ok {
+		return fmt.Errorf("%w: %s", ErrImageNotAllowed, image)
+	}
+	return nil
+}
+
+// EnsureImages pulls any of the given images that are missing on the Docker
+// host so the first execution does not pay for a cold pull. Images already
+// present, or pulled by an earlier call, are skipped.
+func (e *DockerExecutor) EnsureImages(ctx context.Context, images []string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var errs []error
+	for _, image := range images {
+		image = strings.TrimSpace(image)
+		if image == "" {
+			continue
+		}
+		if _, ok := e.ready[image]; ok {
+			continue
+		}
+		if err := e.checkImage(image); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if _, _, err := e.client.ImageInspectWithRaw(ctx, image); err == nil {
+			e.logger.Debug().Str("image", image).Msg("executor image already present")
+			e.ready[image] = struct{}{}
+			continue
+		}
+
+		if err := e.pullImage(ctx, image); err != nil {
+			errs = append(errs, fmt.Errorf("pull %s: %w", image, err))
+			continue
+		}
+		e.ready[image] = struct{}{}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (e *DockerExecutor) pullImage(ctx context.Context, ref string) error {
+	start := time.Now()
+	e.logger.Info().Str("image", ref).Msg("pulling executor image")
+
+	reader, err := e.client.ImagePull(ctx, ref, image.PullOptions{})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	decoder := json.NewDecoder(reader)
+	lastStatus := ""
+	for {
+		var message struct {
+			Status string `json:"status"`
+			Error  string `json:"error"`
+		}
+		if err := decoder.Decode(&message); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+		if message.Error != "" {
+			return errors.New(message.Error)
+		}
+		if message.Status != "" && message.Status != lastStatus {
+			lastStatus = message.Status
+			e.logger.Debug().Str("image", ref).Str("status", message.Status).Msg("image pull progress")
+		}
+	}
+
+	e.logger.Info().Str("image", ref).Dur("duration", time.Since(start)).Msg("executor image ready")
+	return nil
+}
+
 // Run executes the provided command inside a sandboxed Docker container.
 func (e *DockerExecutor) Run(parent context.Context, req ExecutionRequest) (ExecutionResult, error) {
+	return e.execute(parent, req, nil)
+}
+
+// RunStream behaves like Run but follows the container logs while it runs,
+// passing each demultiplexed stdout/stderr frame to onChunk. Chunks are
+// delivered sequentially from a single goroutine and all of them have been
+// delivered by the time RunStream returns. The returned result still carries
+// the complete output.
+func (e *DockerExecutor) RunStream(parent context.Context, req ExecutionRequest, onChunk func(ExecutionChunk)) (ExecutionResult, error) {
+	if onChunk == nil {
+		onChunk = func(ExecutionChunk) {}
+	}
+	return e.execute(parent, req, onChunk)
+}
+
+func (e *DockerExecutor) execute(parent context.Context, req ExecutionRequest, onChunk func(ExecutionChunk)) (ExecutionResult, error) {
 	image := req.Image
 	if image == "" {
 		return ExecutionResult{}, errors.New("image is required")
 	}
+	if err := e.checkImage(image); err != nil {
+		return ExecutionResult{}, err
+	}
+
+	release, err := e.limiter.acquire(parent)
+	if err != nil {
+		return ExecutionResult{}, err
+	}
+	defer release()
 
 	ctx, span := e.tracer.Start(parent, "docker.executor.run", trace.WithAttributes(
 		attribute.String("docker.image", image),
+		attribute.Bool("docker.stream", onChunk != nil),
 	))
 	defer span.End()
 
-	timeout := req.Timeout
-	if timeout <= 0 {
-		timeout = e.cfg.Timeout
-	}
+	timeout := effectiveTimeout(parent, req.Timeout, e.cfg.Timeout)
+	span.SetAttributes(attribute.Int64("docker.timeout_ms", timeout.Milliseconds()))
 
 	if timeout > 0 {
 		var cancel context.CancelFunc
@@ -150,38 +353,7 @@ func (e *DockerExecutor) Run(parent context.Context, req ExecutionRequest) (Exec
 		defer cancel()
 	}
 
-	hostCfg := &container.HostConfig{
-		AutoRemove: false,
-		Resources: container.Resources{
-			Memory:    req.MemoryLimitMB * 1024 * 1024,
-			CPUShares: req.CPUShares,
-		},
-		NetworkMode:    "none",
-		ReadonlyRootfs: req.ReadOnlyFS,
-	}
-
-	if req.NetworkDisabled {
-		hostCfg.NetworkMode = "none"
-	} else {
-		hostCfg.NetworkMode = "bridge"
-	}
-
-	if req.Workspace != "" {
-		hostCfg.Mounts = append(hostCfg.Mounts, mount.Mount{
-			Type:     mount.TypeBind,
-			Source:   req.Workspace,
-			Target:   e.cfg.WorkingDir,
-			ReadOnly: false,
-		})
-	}
-
-	if hostCfg.Resources.Memory == 0 && e.cfg.MemoryLimitMB > 0 {
-		hostCfg.Resources.Memory = e.cfg.MemoryLimitMB * 1024 * 1024
-	}
-
-	if hostCfg.Resources.CPUShares == 0 && e.cfg.CPUShares > 0 {
-		hostCfg.Resources.CPUShares = e.cfg.CPUShares
-	}
+	hostCfg := e.hostConfig(req)
 
 	config := &container.Config{
 		Image:        image,
@@ -196,6 +368,12 @@ func (e *DockerExecutor) Run(parent context.Context, req ExecutionRequest) (Exec
 		config.WorkingDir = e.cfg.WorkingDir
 	}
 
+	if len(req.Stdin) > 0 {
+		config.AttachStdin = true
+		config.OpenStdin = true
+		config.StdinOnce = true
+	}
+
 	networking := &network.NetworkingConfig{}
 
 	start := time.Now()
@@ -218,6 +396,28 @@ func (e *DockerExecutor) Run(parent context.Context, req ExecutionRequest) (Exec
 		}
 	}()
 
+	if len(req.Stdin) > 0 {
+		attach, err := e.client.ContainerAttach(ctx, containerID, container.AttachOptions{Stream: true, Stdin: true})
+		if err != nil {
+			execFailures.WithLabelValues(image).Inc()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return result, fmt.Errorf("container attach: %w", err)
+		}
+		defer attach.Close()
+
+		// Write from a goroutine so large inputs cannot block container start;
+		// closing the write side delivers EOF to programs reading until end of input.
+		go func(input []byte) {
+			if _, err := attach.Conn.Write(input); err != nil {
+				e.logger.Warn().Err(err).Str("container_id", containerID).Msg("failed to write container stdin")
+			}
+			if err := attach.CloseWrite(); err != nil {
+				e.logger.Warn().Err(err).Str("container_id", containerID).Msg("failed to close container stdin")
+			}
+		}(req.Stdin)
+	}
+
 	if err := e.client.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
 		execFailures.WithLabelValues(image).Inc()
 		span.RecordError(err)
@@ -225,6 +425,16 @@ func (e *DockerExecutor) Run(parent context.Context, req ExecutionRequest) (Exec
 		return result, fmt.Errorf("container start: %w", err)
 	}
 
+	var streamed *logFollower
+	if onChunk != nil {
+		follower, err := e.followLogs(parent, containerID, e.maxOutput(req), onChunk)
+		if err != nil {
+			e.logger.Error().Err(err).Str("container_id", containerID).Msg("failed to follow container logs")
+		} else {
+			streamed = follower
+		}
+	}
+
 	statusCh, errCh := e.client.ContainerWait(ctx, containerID, container.WaitConditionNextExit)
 
 	var waitErr error
@@ -242,17 +452,24 @@ func (e *DockerExecutor) Run(parent context.Context, req ExecutionRequest) (Exec
 	execDuration.WithLabelValues(image).Observe(duration.Seconds())
 
 	if waitErr != nil {
-		if errors.Is(waitErr, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded {
+		switch {
+		case errors.Is(waitErr, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded:
 			result.TimedOut = true
 			execTimeouts.WithLabelValues(image).Inc()
-			killCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-			defer cancel()
-			if err := e.client.ContainerKill(killCtx, containerID, "KILL"); err != nil {
-				e.logger.Error().Err(err).Str("container_id", containerID).Msg("failed to kill timed out container")
-			}
+			e.killContainer(containerID, "timed out")
 			span.RecordError(waitErr)
 			span.SetStatus(codes.Error, "execution timed out")
-		} else if !errors.Is(waitErr, context.Canceled) {
+		case errors.Is(waitErr, context.Canceled) || ctx.Err() == context.Canceled:
+			// The caller went away, e.g. the HTTP request was aborted. Stop the
+			// container right away instead of leaving it to the deferred removal.
+			e.killContainer(containerID, "canceled")
+			if streamed != nil {
+				streamed.wait(containerID, e.logger)
+			}
+			span.RecordError(waitErr)
+			span.SetStatus(codes.Error, "execution canceled")
+			return result, fmt.Errorf("execution canceled: %w", context.Canceled)
+		default:
 			execFailures.WithLabelValues(image).Inc()
 			span.RecordError(waitErr)
 			span.SetStatus(codes.Error, waitErr.Error())
@@ -260,24 +477,35 @@ func (e *DockerExecutor) Run(parent context.Context, req ExecutionRequest) (Exec
 		}
 	}
 
-	logReader, err := e.client.ContainerLogs(parent, containerID, container.LogsOptions{
+	// The request context may already have expired if its deadline was the
+	// one that stopped the run, so collect output and stats without it.
+	collectCtx, cancelCollect := context.WithTimeout(context.WithoutCancel(parent), 5*time.Second)
+	defer cancelCollect()
+
+	if streamed != nil {
+		result.Stdout, result.Stderr, result.Truncated = streamed.wait(containerID, e.logger)
+	} else if logReader, err := e.client.ContainerLogs(collectCtx, containerID, container.LogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
-	})
-	if err == nil {
+	}); err == nil {
 		defer logReader.Close()
-		stdout, stderr, err := splitDockerLogs(logReader)
+		stdout, stderr, truncated, err := splitDockerLogs(logReader, e.maxOutput(req))
 		if err != nil {
 			e.logger.Error().Err(err).Str("container_id", containerID).Msg("failed to read container logs")
 		} else {
 			result.Stdout = stdout
 			result.Stderr = stderr
+			result.Truncated = truncated
+			if onChunk != nil {
+				// Following failed earlier; deliver the output in one piece instead.
+				emitBuffered(onChunk, result)
+			}
 		}
 	} else {
 		e.logger.Error().Err(err).Str("container_id", containerID).Msg("failed to fetch container logs")
 	}
 
-	statsCtx, cancelStats := context.WithTimeout(parent, 2*time.Second)
+	statsCtx, cancelStats := context.WithTimeout(collectCtx, 2*time.Second)
 	defer cancelStats()
 	stats, err := e.client.ContainerStatsOneShot(statsCtx, containerID)
 	if err == nil {
@@ -293,19 +521,228 @@ func (e *DockerExecutor) Run(parent context.Context, req ExecutionRequest) (Exec
 		return result, fmt.Errorf("execution timed out after %s", timeout)
 	}
 
-	if waitErr != nil && ctx.Err() != nil && ctx.Err() != context.Canceled {
-		return result, waitErr
+	return result, nil
+}
+
+// hostConfig builds the container resources for req, filling unset limits
+// from the executor configuration.
+func (e *DockerExecutor) hostConfig(req ExecutionRequest) *container.HostConfig {
+	hostCfg := &container.HostConfig{
+		AutoRemove: false,
+		Resources: container.Resources{
+			Memory:    firstPositive(req.MemoryLimitMB, e.cfg.MemoryLimitMB) * 1024 * 1024,
+			CPUShares: firstPositive(req.CPUShares, e.cfg.CPUShares),
+			CPUQuota:  firstPositive(req.CPUQuota, e.cfg.CPUQuota),
+			CPUPeriod: firstPositive(req.CPUPeriod, e.cfg.CPUPeriod),
+		},
+		NetworkMode:    "none",
+		ReadonlyRootfs: req.ReadOnlyFS,
+	}
+
+	if hostCfg.Resources.CPUQuota > 0 && hostCfg.Resources.CPUPeriod == 0 {
+		hostCfg.Resources.CPUPeriod = defaultCPUPeriod
 	}
 
-	return result, nil
+	if pids := firstPositive(req.PidsLimit, e.cfg.PidsLimit); pids > 0 {
+		hostCfg.Resources.PidsLimit = &pids
+	}
+
+	if req.NetworkDisabled {
+		hostCfg.NetworkMode = "none"
+	} else {
+		hostCfg.NetworkMode = "bridge"
+	}
+
+	if req.Workspace != "" {
+		hostCfg.Mounts = append(hostCfg.Mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   req.Workspace,
+			Target:   e.cfg.WorkingDir,
+			ReadOnly: !req.WritableWorkspace,
+		})
+	}
+
+	if quota := firstPositive(req.DiskQuotaMB, e.cfg.DiskQuotaMB); quota > 0 {
+		hostCfg.Mounts = append(hostCfg.Mounts, mount.Mount{
+			Type:   mount.TypeTmpfs,
+			Target: "/tmp",
+			TmpfsOptions: &mount.TmpfsOptions{
+				SizeBytes: quota * 1024 * 1024,
+			},
+		})
+	}
+
+	return hostCfg
+}
+
+// maxOutput is the log output limit for req.
+func (e *DockerExecutor) maxOutput(req ExecutionRequest) int64 {
+	return firstPositive(req.MaxOutputBytes, e.cfg.MaxOutputBytes, DefaultMaxOutputBytes)
 }
 
-func splitDockerLogs(reader io.Reader) (string, string, error) {
+func firstPositive(values ...int64) int64 {
+	for _, value := range values {
+		if value > 0 {
+			return value
+		}
+	}
+	return 0
+}
+
+// effectiveTimeout picks the request timeout, falling back to the configured
+// one, and shortens it to the time left before the parent context's deadline
+// so a run never outlives the request that started it.
+func effectiveTimeout(parent context.Context, requested, configured time.Duration) time.Duration {
+	timeout := requested
+	if timeout <= 0 {
+		timeout = configured
+	}
+	if deadline, ok := parent.Deadline(); ok {
+		if remaining := time.Until(deadline); timeout <= 0 || remaining < timeout {
+			timeout = remaining
+		}
+	}
+	return timeout
+}
+
+func (e *DockerExecutor) killContainer(containerID, reason string) {
+	killCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := e.client.ContainerKill(killCtx, containerID, "KILL"); err != nil {
+		e.logger.Error().Err(err).Str("container_id", containerID).Str("reason", reason).Msg("failed to kill container")
+	}
+}
+
+// logFollower demultiplexes a followed container log stream in the background.
+type logFollower struct {
+	reader  io.ReadCloser
+	limited *outputLimitReader
+	stdout  bytes.Buffer
+	stderr  bytes.Buffer
+	done    chan struct{}
+	err     error
+}
+
+func (e *DockerExecutor) followLogs(ctx context.Context, containerID string, limit int64, onChunk func(ExecutionChunk)) (*logFollower, error) {
+	reader, err := e.client.ContainerLogs(ctx, containerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newLogFollower(reader, limit, onChunk), nil
+}
+
+// newLogFollower reads at most limit bytes of the log stream; output past the
+// limit is neither collected nor forwarded.
+func newLogFollower(reader io.ReadCloser, limit int64, onChunk func(ExecutionChunk)) *logFollower {
+	follower := &logFollower{
+		reader:  reader,
+		limited: newOutputLimitReader(reader, limit),
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(follower.done)
+		stdout := &chunkWriter{stream: StreamStdout, buf: &follower.stdout, emit: onChunk}
+		stderr := &chunkWriter{stream: StreamStderr, buf: &follower.stderr, emit: onChunk}
+		_, follower.err = stdcopy.StdCopy(stdout, stderr, follower.limited)
+	}()
+	return follower
+}
+
+// wait blocks until the log stream ends, closing it if the daemon keeps it
+// open past logDrainTimeout, and returns the collected output and whether the
+// output limit cut it short.
+func (f *logFollower) wait(containerID string, logger zerolog.Logger) (string, string, bool) {
+	select {
+	case <-f.done:
+	case <-time.After(logDrainTimeout):
+		_ = f.reader.Close()
+		<-f.done
+	}
+	_ = f.reader.Close()
+
+	if f.err != nil && !errors.Is(f.err, context.Canceled) && !errors.Is(f.err, context.DeadlineExceeded) {
+		logger.Warn().Err(f.err).Str("container_id", containerID).Msg("container log stream ended with error")
+	}
+	return f.stdout.String(), f.stderr.String(), f.limited.exceeded
+}
+
+// chunkWriter receives one demultiplexed stream from stdcopy, keeps a copy for
+// the final result and forwards every frame to the caller.
+type chunkWriter struct {
+	stream string
+	buf    *bytes.Buffer
+	emit   func(ExecutionChunk)
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	// stdcopy reuses its frame buffer, so hand the callback its own copy.
+	w.emit(ExecutionChunk{Stream: w.stream, Data: append([]byte(nil), p...)})
+	return len(p), nil
+}
+
+func emitBuffered(onChunk func(ExecutionChunk), result ExecutionResult) {
+	if result.Stdout != "" {
+		onChunk(ExecutionChunk{Stream: StreamStdout, Data: []byte(result.Stdout)})
+	}
+	if result.Stderr != "" {
+		onChunk(ExecutionChunk{Stream: StreamStderr, Data: []byte(result.Stderr)})
+	}
+}
+
+// splitDockerLogs demultiplexes at most limit bytes of a container log stream
+// and reports whether the stream went past the limit. A frame cut off by the
+// limit is dropped.
+func splitDockerLogs(reader io.Reader, limit int64) (string, string, bool, error) {
 	var stdoutBuf, stderrBuf bytes.Buffer
-	if _, err := stdcopy.StdCopy(&stdoutBuf, &stderrBuf, reader); err != nil {
-		return "", "", err
+	limited := newOutputLimitReader(reader, limit)
+	if _, err := stdcopy.StdCopy(&stdoutBuf, &stderrBuf, limited); err != nil {
+		return "", "", false, err
+	}
+	return stdoutBuf.String(), stderrBuf.String(), limited.exceeded, nil
+}
+
+// outputLimitReader passes through at most limit bytes. Once the limit is
+// used up it reads one more byte to tell output that exactly fills the limit
+// from output that overflows it; only the latter sets exceeded.
+type outputLimitReader struct {
+	reader    io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func newOutputLimitReader(reader io.Reader, limit int64) *outputLimitReader {
+	return &outputLimitReader{reader: reader, remaining: limit}
+}
+
+func (r *outputLimitReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		if !r.exceeded {
+			var probe [1]byte
+			n, _ := io.ReadFull(r.reader, probe[:])
+			r.exceeded = n > 0
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
+
+// Ping checks that the Docker daemon is reachable.
+func (e *DockerExecutor) Ping(ctx context.Context) error {
+	if e.client == nil {
+		return fmt.Errorf("docker client not initialised")
 	}
-	return stdoutBuf.String(), stderrBuf.String(), nil
+	_, err := e.client.Ping(ctx)
+	return err
 }
 
 // Close shuts down the executor's underlying client.
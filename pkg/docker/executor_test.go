@@ -0,0 +1,350 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerExecutorRejectsImagesOutsideAllowlist(t *testing.T) {
+	executor, err := NewDockerExecutor(Config{
+		Host:          "tcp://127.0.0.1:1",
+		AllowedImages: []string{"python:3.11-alpine"},
+	})
+	require.NoError(t, err)
+	defer executor.Close()
+
+	_, err = executor.Run(context.Background(), ExecutionRequest{Image: "alpine:latest", Cmd: []string{"true"}})
+	require.ErrorIs(t, err, ErrImageNotAllowed)
+
+	err = executor.EnsureImages(context.Background(), []string{"alpine:latest"})
+	require.ErrorIs(t, err, ErrImageNotAllowed)
+}
+
+func TestLogFollowerStreamsFramesAsTheyArrive(t *testing.T) {
+	reader, writer := io.Pipe()
+	chunks := make(chan ExecutionChunk, 4)
+	follower := newLogFollower(reader, DefaultMaxOutputBytes, func(chunk ExecutionChunk) {
+		chunks <- chunk
+	})
+
+	stdout := stdcopy.NewStdWriter(writer, stdcopy.Stdout)
+	stderr := stdcopy.NewStdWriter(writer, stdcopy.Stderr)
+
+	_, err := stdout.Write([]byte("line 1\n"))
+	require.NoError(t, err)
+	// The first frame must be delivered before any further output is written.
+	require.Equal(t, ExecutionChunk{Stream: StreamStdout, Data: []byte("line 1\n")}, <-chunks)
+
+	_, err = stderr.Write([]byte("warning\n"))
+	require.NoError(t, err)
+	require.Equal(t, ExecutionChunk{Stream: StreamStderr, Data: []byte("warning\n")}, <-chunks)
+
+	_, err = stdout.Write([]byte("line 2\n"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	out, errOut, truncated := follower.wait("test", zerolog.Nop())
+	require.Equal(t, "line 1\nline 2\n", out)
+	require.Equal(t, "warning\n", errOut)
+	require.False(t, truncated)
+	require.Len(t, chunks, 1)
+}
+
+// chattyLogs returns a multiplexed log stream of a program that prints lines
+// of 100 bytes to stdout, with one stderr line up front.
+func chattyLogs(t *testing.T, lines int) *bytes.Buffer {
+	t.Helper()
+	var logs bytes.Buffer
+	_, err := stdcopy.NewStdWriter(&logs, stdcopy.Stderr).Write([]byte("starting\n"))
+	require.NoError(t, err)
+	stdout := stdcopy.NewStdWriter(&logs, stdcopy.Stdout)
+	line := []byte(strings.Repeat("x", 99) + "\n")
+	for i := 0; i < lines; i++ {
+		_, err := stdout.Write(line)
+		require.NoError(t, err)
+	}
+	return &logs
+}
+
+func TestSplitDockerLogsCapsOutput(t *testing.T) {
+	// Each frame is an 8 byte header plus the line.
+	stdout, stderr, truncated, err := splitDockerLogs(chattyLogs(t, 10_000), 1000)
+	require.NoError(t, err)
+	require.True(t, truncated)
+	require.Equal(t, "starting\n", stderr)
+	require.Equal(t, strings.Repeat(strings.Repeat("x", 99)+"\n", 9), stdout)
+
+	stdout, _, truncated, err = splitDockerLogs(chattyLogs(t, 3), 1000)
+	require.NoError(t, err)
+	require.False(t, truncated)
+	require.Len(t, stdout, 300)
+}
+
+func TestSplitDockerLogsOutputExactlyAtLimit(t *testing.T) {
+	exact := int64(chattyLogs(t, 3).Len())
+
+	stdout, _, truncated, err := splitDockerLogs(chattyLogs(t, 3), exact)
+	require.NoError(t, err)
+	require.False(t, truncated, "output that exactly fills the limit is complete")
+	require.Len(t, stdout, 300)
+
+	stdout, _, truncated, err = splitDockerLogs(chattyLogs(t, 3), exact-1)
+	require.NoError(t, err)
+	require.True(t, truncated)
+	require.Len(t, stdout, 200)
+}
+
+func TestLogFollowerStopsAtOutputLimit(t *testing.T) {
+	var forwarded int
+	follower := newLogFollower(io.NopCloser(chattyLogs(t, 10_000)), 1000, func(chunk ExecutionChunk) {
+		forwarded += len(chunk.Data)
+	})
+
+	out, _, truncated := follower.wait("test", zerolog.Nop())
+	require.True(t, truncated)
+	require.Len(t, out, 900)
+	require.Equal(t, 909, forwarded)
+}
+
+// fakeDaemon emulates the Docker API endpoints used by Run for a single
+// container whose wait call blocks until the container is killed.
+type fakeDaemon struct {
+	server *httptest.Server
+	killed chan struct{}
+	once   sync.Once
+}
+
+func newFakeDaemon(t *testing.T) *fakeDaemon {
+	t.Helper()
+	daemon := &fakeDaemon{killed: make(chan struct{})}
+	daemon.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		switch {
+		case strings.HasSuffix(path, "/_ping"):
+			w.Header().Set("API-Version", "1.45")
+			_, _ = w.Write([]byte("OK"))
+		case strings.HasSuffix(path, "/containers/create"):
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"Id":"c1","Warnings":[]}`))
+		case strings.HasSuffix(path, "/containers/c1/wait"):
+			select {
+			case <-daemon.killed:
+				_, _ = w.Write([]byte(`{"StatusCode":137}`))
+			case <-r.Context().Done():
+			}
+		case strings.HasSuffix(path, "/containers/c1/kill"):
+			daemon.once.Do(func() { close(daemon.killed) })
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(path, "/containers/c1/logs"):
+			w.Header().Set("Content-Type", "application/vnd.docker.raw-stream")
+		case strings.HasSuffix(path, "/containers/c1/stats"):
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			// start and remove
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(daemon.server.Close)
+	return daemon
+}
+
+func (d *fakeDaemon) executor(t *testing.T, timeout time.Duration) *DockerExecutor {
+	t.Helper()
+	executor, err := NewDockerExecutor(Config{Host: "tcp://" + strings.TrimPrefix(d.server.URL, "http://"), Timeout: timeout})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = executor.Close() })
+	return executor
+}
+
+func TestDockerExecutorKillsContainerWhenContextCanceled(t *testing.T) {
+	daemon := newFakeDaemon(t)
+	executor := daemon.executor(t, 30*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := executor.Run(ctx, ExecutionRequest{Image: "alpine:latest", Cmd: []string{"sleep", "60"}})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	select {
+	case <-daemon.killed:
+	case <-time.After(time.Second):
+		t.Fatal("container was not killed after the context was canceled")
+	}
+}
+
+func TestDockerExecutorHonoursShorterContextDeadline(t *testing.T) {
+	daemon := newFakeDaemon(t)
+	executor := daemon.executor(t, 30*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result, err := executor.Run(ctx, ExecutionRequest{Image: "alpine:latest", Cmd: []string{"sleep", "60"}})
+	require.Error(t, err)
+	require.True(t, result.TimedOut)
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.NotContains(t, err.Error(), "30s")
+
+	select {
+	case <-daemon.killed:
+	default:
+		t.Fatal("timed out container was not killed")
+	}
+}
+
+func TestEffectiveTimeout(t *testing.T) {
+	background := context.Background()
+	require.Equal(t, 5*time.Second, effectiveTimeout(background, 0, 5*time.Second))
+	require.Equal(t, 2*time.Second, effectiveTimeout(background, 2*time.Second, 5*time.Second))
+
+	ctx, cancel := context.WithTimeout(background, time.Second)
+	defer cancel()
+	require.LessOrEqual(t, effectiveTimeout(ctx, 0, 5*time.Second), time.Second)
+	require.LessOrEqual(t, effectiveTimeout(ctx, 0, 0), time.Second)
+	require.Positive(t, effectiveTimeout(ctx, 0, 0))
+}
+
+func TestHostConfigAppliesResourceLimits(t *testing.T) {
+	executor := &DockerExecutor{cfg: Config{WorkingDir: "/workspace", MemoryLimitMB: 256, CPUShares: 512, PidsLimit: 64, DiskQuotaMB: 32}}
+
+	hostCfg := executor.hostConfig(ExecutionRequest{Workspace: "/tmp/run", NetworkDisabled: true})
+	require.Equal(t, int64(256*1024*1024), hostCfg.Resources.Memory)
+	require.Equal(t, int64(512), hostCfg.Resources.CPUShares)
+	require.Zero(t, hostCfg.Resources.CPUQuota)
+	require.NotNil(t, hostCfg.Resources.PidsLimit)
+	require.Equal(t, int64(64), *hostCfg.Resources.PidsLimit)
+	require.Len(t, hostCfg.Mounts, 2)
+	require.Equal(t, "/workspace", hostCfg.Mounts[0].Target)
+	require.True(t, hostCfg.Mounts[0].ReadOnly)
+	require.Equal(t, "/tmp", hostCfg.Mounts[1].Target)
+	require.Equal(t, int64(32*1024*1024), hostCfg.Mounts[1].TmpfsOptions.SizeBytes)
+
+	hostCfg = executor.hostConfig(ExecutionRequest{Workspace: "/tmp/run", WritableWorkspace: true})
+	require.False(t, hostCfg.Mounts[0].ReadOnly)
+
+	hostCfg = executor.hostConfig(ExecutionRequest{PidsLimit: 8, DiskQuotaMB: 4, CPUQuota: 50000})
+	require.Equal(t, int64(8), *hostCfg.Resources.PidsLimit)
+	require.Equal(t, int64(50000), hostCfg.Resources.CPUQuota)
+	require.Equal(t, defaultCPUPeriod, hostCfg.Resources.CPUPeriod)
+	require.Len(t, hostCfg.Mounts, 1)
+	require.Equal(t, int64(4*1024*1024), hostCfg.Mounts[0].TmpfsOptions.SizeBytes)
+}
+
+// TestDockerExecutorBoundsWorkspaceWrites runs containers and is skipped
+// when no Docker daemon is reachable.
+func TestDockerExecutorBoundsWorkspaceWrites(t *testing.T) {
+	const image = "alpine:3.20"
+	executor, err := NewDockerExecutor(Config{Timeout: time.Minute, DiskQuotaMB: 4})
+	require.NoError(t, err)
+	defer executor.Close()
+
+	ctx := context.Background()
+	if err := executor.Ping(ctx); err != nil {
+		t.Skipf("docker daemon unavailable: %v", err)
+	}
+	if err := executor.EnsureImages(ctx, []string{image}); err != nil {
+		t.Skipf("cannot pull %s: %v", image, err)
+	}
+
+	workspace := t.TempDir()
+	write := func(path string, megabytes int) int {
+		result, err := executor.Run(ctx, ExecutionRequest{
+			Image:           image,
+			Cmd:             []string{"dd", "if=/dev/zero", "of=" + path, "bs=1M", fmt.Sprintf("count=%d", megabytes)},
+			Workspace:       workspace,
+			NetworkDisabled: true,
+			ReadOnlyFS:      true,
+		})
+		require.NoError(t, err)
+		return result.ExitCode
+	}
+
+	require.Zero(t, write("/tmp/fill", 2))
+	require.NotZero(t, write("/tmp/fill", 8), "writes past the quota must fail")
+	require.NotZero(t, write("/workspace/fill", 8), "the workspace must not escape the quota")
+	require.NotZero(t, write("/root/fill", 1), "the home directory must not escape the quota")
+	require.NotZero(t, write("/fill", 1), "the root filesystem must not escape the quota")
+
+	entries, err := os.ReadDir(workspace)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestHostConfigLeavesUnsetLimitsUnbounded(t *testing.T) {
+	executor := &DockerExecutor{cfg: Config{WorkingDir: "/workspace"}}
+
+	hostCfg := executor.hostConfig(ExecutionRequest{})
+	require.Nil(t, hostCfg.Resources.PidsLimit)
+	require.Zero(t, hostCfg.Resources.CPUPeriod)
+	require.Empty(t, hostCfg.Mounts)
+}
+
+func TestLimiterQueuesThenRejectsWhenFull(t *testing.T) {
+	lim := newLimiter(1, 1, time.Second)
+
+	releaseFirst, err := lim.acquire(context.Background())
+	require.NoError(t, err)
+
+	acquired := make(chan func(), 1)
+	go func() {
+		release, err := lim.acquire(context.Background())
+		if err == nil {
+			acquired <- release
+		}
+	}()
+	require.Eventually(t, func() bool { return lim.queued.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	_, err = lim.acquire(context.Background())
+	require.ErrorIs(t, err, ErrExecutorBusy)
+
+	releaseFirst()
+	select {
+	case release := <-acquired:
+		release()
+	case <-time.After(time.Second):
+		t.Fatal("queued execution did not acquire the released slot")
+	}
+	require.Zero(t, lim.queued.Load())
+}
+
+func TestLimiterQueueTimeout(t *testing.T) {
+	lim := newLimiter(1, 4, 50*time.Millisecond)
+
+	release, err := lim.acquire(context.Background())
+	require.NoError(t, err)
+	defer release()
+
+	_, err = lim.acquire(context.Background())
+	require.ErrorIs(t, err, ErrExecutorBusy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = lim.acquire(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNilLimiterIsUnbounded(t *testing.T) {
+	lim := newLimiter(0, 0, 0)
+	require.Nil(t, lim)
+
+	release, err := lim.acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/semaphore"
+)
+
+// ErrExecutorBusy indicates every execution slot is taken and the wait queue
+// is full, or the queued run waited longer than the configured QueueTimeout.
+var ErrExecutorBusy = errors.New("executor busy")
+
+var (
+	execInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "gema",
+		Subsystem: "executor",
+		Name:      "in_flight",
+		Help:      "Number of container executions currently running",
+	})
+
+	execQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "gema",
+		Subsystem: "executor",
+		Name:      "queue_depth",
+		Help:      "Number of executions waiting for a free slot",
+	})
+)
+
+// limiter bounds concurrent executions. Callers beyond maxConcurrent wait in
+// a queue of at most maxQueue entries for up to queueTimeout.
+type limiter struct {
+	sem          *semaphore.Weighted
+	maxQueue     int64
+	queueTimeout time.Duration
+	queued       atomic.Int64
+}
+
+// newLimiter returns nil, meaning unlimited, when maxConcurrent is not positive.
+func newLimiter(maxConcurrent, maxQueue int, queueTimeout time.Duration) *limiter {
+	if maxConcurrent <= 0 {
+		return nil
+	}
+	if maxQueue < 0 {
+		maxQueue = 0
+	}
+	return &limiter{
+		sem:          semaphore.NewWeighted(int64(maxConcurrent)),
+		maxQueue:     int64(maxQueue),
+		queueTimeout: queueTimeout,
+	}
+}
+
+// acquire reserves an execution slot and returns the function releasing it.
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	if !l.sem.TryAcquire(1) {
+		if l.queued.Add(1) > l.maxQueue {
+			l.queued.Add(-1)
+			return nil, ErrExecutorBusy
+		}
+		execQueueDepth.Inc()
+
+		waitCtx := ctx
+		if l.queueTimeout > 0 {
+			var cancel context.CancelFunc
+			waitCtx, cancel = context.WithTimeout(ctx, l.queueTimeout)
+			defer cancel()
+		}
+		err := l.sem.Acquire(waitCtx, 1)
+
+		l.queued.Add(-1)
+		execQueueDepth.Dec()
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return nil, ErrExecutorBusy
+		}
+	}
+
+	execInFlight.Inc()
+	return func() {
+		execInFlight.Dec()
+		l.sem.Release(1)
+	}, nil
+}
@@ -17,16 +17,25 @@ import (
 
 	"github.com/noah-isme/gema-go-api/internal/dto"
 	"github.com/noah-isme/gema-go-api/internal/handler"
+	"github.com/noah-isme/gema-go-api/internal/service"
 )
 
 type stubAnalyticsService struct {
 	response dto.AdminAnalyticsResponse
 }
 
-func (s stubAnalyticsService) GetSummary(context.Context) (dto.AdminAnalyticsResponse, error) {
+func (s stubAnalyticsService) GetSummary(context.Context, dto.AdminAnalyticsQuery) (dto.AdminAnalyticsResponse, error) {
 	return s.response, nil
 }
 
+func (s stubAnalyticsService) Export(context.Context, service.ActivityActor, string, dto.AdminAnalyticsQuery) (service.AnalyticsExport, error) {
+	return service.AnalyticsExport{}, nil
+}
+
+func (s stubAnalyticsService) AssignmentAnalytics(context.Context, uint) (dto.AdminAssignmentAnalyticsResponse, error) {
+	return dto.AdminAssignmentAnalyticsResponse{}, nil
+}
+
 func TestAdminAnalyticsContract(t *testing.T) {
 	schemaPath, err := filepath.Abs(filepath.Join("..", "contracts", "admin_analytics.schema.json"))
 	require.NoError(t, err)
@@ -66,3 +75,24 @@ func TestAdminAnalyticsContract(t *testing.T) {
 	require.NoError(t, json.Unmarshal(body, &payload))
 	require.NoError(t, schema.Validate(payload))
 }
+
+func TestAdminAnalyticsRejectsInvalidRange(t *testing.T) {
+	handler := handler.NewAdminAnalyticsHandler(stubAnalyticsService{}, zerolog.Nop())
+	app := fiber.New()
+	handler.Register(app.Group("/api/admin/analytics"))
+
+	for _, query := range []string{
+		"?from=yesterday",
+		"?from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z",
+	} {
+		for _, path := range []string{"/api/admin/analytics", "/api/admin/analytics/export"} {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, path+query, nil))
+			require.NoError(t, err)
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode, path+query)
+		}
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/admin/analytics?from=2024-01-01T00:00:00Z&to=2024-01-31T23:59:59%2B07:00", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
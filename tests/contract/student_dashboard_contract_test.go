@@ -23,7 +23,7 @@ type stubDashboardService struct {
 	response dto.StudentDashboardResponse
 }
 
-func (s stubDashboardService) GetDashboard(context.Context, uint) (dto.StudentDashboardResponse, bool, error) {
+func (s stubDashboardService) GetDashboard(context.Context, uint, dto.StudentDashboardQuery) (dto.StudentDashboardResponse, bool, error) {
 	return s.response, false, nil
 }
 
@@ -61,6 +61,16 @@ func TestStudentDashboardContract(t *testing.T) {
 				Overdue:       false,
 			},
 		},
+		UpcomingDeadlines: []dto.AssignmentProgress{
+			{
+				AssignmentID: 11,
+				Title:        "Project Proposal",
+				DueDate:      now.Add(72 * time.Hour),
+				Status:       "pending",
+				UpdatedAt:    now,
+			},
+		},
+		Streak: dto.SubmissionStreak{CurrentDays: 2, LongestDays: 4},
 		RecentSubmissions: []dto.SubmissionActivity{
 			{
 				SubmissionID:   55,
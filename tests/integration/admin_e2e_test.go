@@ -56,12 +56,12 @@ func setupAdminApp(t *testing.T) (*fiber.App, *gorm.DB) {
 	uploader := integrationUploader{}
 
 	assignmentService := service.NewAssignmentService(assignmentRepo, validate, uploader, logger)
-	submissionService := service.NewSubmissionService(submissionRepo, assignmentRepo, validate, uploader, logger)
-	activityService := service.NewActivityService(activityRepo, validate, logger)
+	submissionService := service.NewSubmissionService(submissionRepo, assignmentRepo, validate, uploader, nil, nil, service.SubmissionSizeLimits{}, nil, nil, logger)
+	activityService := service.NewActivityService(activityRepo, nil, nil, validate, logger)
 	adminStudentService := service.NewAdminStudentService(adminStudentRepo, validate, activityService, logger)
-	adminAssignmentService := service.NewAdminAssignmentService(assignmentRepo, validate, activityService, logger)
-	adminGradingService := service.NewAdminGradingService(adminSubmissionRepo, validate, activityService, logger)
-	adminAnalyticsService := service.NewAdminAnalyticsService(analyticsRepo, nil, 0, logger)
+	adminAssignmentService := service.NewAdminAssignmentService(assignmentRepo, validate, service.SubmissionSizeLimits{}, activityService, logger)
+	adminGradingService := service.NewAdminGradingService(adminSubmissionRepo, validate, activityService, nil, logger)
+	adminAnalyticsService := service.NewAdminAnalyticsService(analyticsRepo, nil, service.FixedTTL(0), activityService, logger)
 
 	assignmentHandler := handler.NewAssignmentHandler(assignmentService, validate, logger)
 	submissionHandler := handler.NewSubmissionHandler(submissionService, validate, logger)
@@ -226,4 +226,17 @@ func TestAdminEndToEndFlow(t *testing.T) {
 	require.Equal(t, int64(0), analyticsBody.Data.LateSubmissions)
 	require.Contains(t, analyticsBody.Data.GradeDistribution, "75-89")
 	require.Equal(t, int64(1), analyticsBody.Data.GradeDistribution["75-89"])
+
+	// Step 6: admin downloads the grades
+	exportReq := httptest.NewRequest(http.MethodGet, "/api/admin/submissions/export?format=csv&assignment_id="+strconv.Itoa(int(submissionBody.Data.AssignmentID)), nil)
+	exportResp, err := app.Test(exportReq)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, exportResp.StatusCode)
+	require.Contains(t, exportResp.Header.Get(fiber.HeaderContentDisposition), "grades-assignment-")
+	exportBody, err := io.ReadAll(exportResp.Body)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(exportBody)), "\n")
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasPrefix(lines[0], "submission_id,"))
+	require.Contains(t, lines[1], ",85,")
 }
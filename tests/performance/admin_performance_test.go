@@ -64,7 +64,7 @@ func setupAnalyticsPerformanceApp(t *testing.T) (*fiber.App, *gorm.DB) {
 	}
 
 	analyticsRepo := repository.NewAdminAnalyticsRepository(db)
-	analyticsService := service.NewAdminAnalyticsService(analyticsRepo, nil, 0, zerolog.Nop())
+	analyticsService := service.NewAdminAnalyticsService(analyticsRepo, nil, service.FixedTTL(0), nil, zerolog.Nop())
 	analyticsHandler := handler.NewAdminAnalyticsHandler(analyticsService, zerolog.Nop())
 
 	app := fiber.New()
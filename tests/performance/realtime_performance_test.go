@@ -182,22 +182,61 @@ func (s *stubChatService) ServeConnection(conn *fiberws.Conn, _ service.ChatConn
 	_ = conn.Close()
 }
 
-func (s *stubChatService) History(context.Context, dto.ChatHistoryQuery) ([]dto.ChatMessageResponse, error) {
+func (s *stubChatService) History(context.Context, string, string, dto.ChatHistoryQuery) ([]dto.ChatMessageResponse, error) {
 	return []dto.ChatMessageResponse{}, nil
 }
 
+func (s *stubChatService) RoomSummaries(context.Context, string, string, []string) ([]dto.ChatRoomSummary, error) {
+	return []dto.ChatRoomSummary{}, nil
+}
+
+func (s *stubChatService) MarkRoomRead(context.Context, string, string) error {
+	return nil
+}
+
+func (s *stubChatService) Disconnect(context.Context, service.ActivityActor, string, dto.ChatDisconnectRequest) (dto.ChatDisconnectResponse, error) {
+	return dto.ChatDisconnectResponse{}, nil
+}
+
+func (s *stubChatService) CreateRoom(context.Context, service.ActivityActor, dto.ChatRoomCreateRequest) (dto.ChatRoomResponse, error) {
+	return dto.ChatRoomResponse{}, nil
+}
+
+func (s *stubChatService) AddRoomMembers(context.Context, service.ActivityActor, string, dto.ChatRoomMembersRequest) (dto.ChatRoomResponse, error) {
+	return dto.ChatRoomResponse{}, nil
+}
+
+func (s *stubChatService) RemoveRoomMember(context.Context, service.ActivityActor, string, string) (dto.ChatRoomResponse, error) {
+	return dto.ChatRoomResponse{}, nil
+}
+
 func (s *stubChatService) Start(context.Context) {}
 
+func (s *stubChatService) Shutdown(context.Context) error { return nil }
+
 type stubNotificationService struct{}
 
 func (s *stubNotificationService) Publish(ctx context.Context, payload dto.NotificationCreateRequest) (dto.NotificationResponse, error) {
 	return dto.NotificationResponse{ID: 1, UserID: payload.UserID, Type: payload.Type, Message: payload.Message}, nil
 }
 
+func (s *stubNotificationService) PublishBulk(ctx context.Context, payload dto.NotificationBulkCreateRequest) ([]dto.NotificationResponse, error) {
+	out := make([]dto.NotificationResponse, 0, len(payload.UserIDs))
+	for i, userID := range payload.UserIDs {
+		out = append(out, dto.NotificationResponse{ID: uint(i + 1), UserID: userID, Type: payload.Type, Message: payload.Message})
+	}
+	return out, nil
+}
+
 func (s *stubNotificationService) List(ctx context.Context, userID string, limit, offset int) ([]dto.NotificationResponse, error) {
 	return []dto.NotificationResponse{{ID: 1, UserID: userID, Type: "system", Message: "hello", CreatedAt: time.Now(), UpdatedAt: time.Now()}}, nil
 }
 
+func (s *stubNotificationService) ListAfter(ctx context.Context, userID, cursor string, limit int) ([]dto.NotificationResponse, string, error) {
+	list, err := s.List(ctx, userID, limit, 0)
+	return list, "", err
+}
+
 func (s *stubNotificationService) MarkRead(ctx context.Context, id uint, userID string) (dto.NotificationResponse, error) {
 	return dto.NotificationResponse{ID: id, UserID: userID, Type: "system", Message: "hello", Read: true, CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
 }
@@ -210,3 +249,17 @@ func (s *stubNotificationService) Subscribe(userID string) (<-chan dto.Notificat
 }
 
 func (s *stubNotificationService) Start(context.Context) {}
+
+func (s *stubNotificationService) Shutdown(context.Context) error { return nil }
+
+func (s *stubNotificationService) ListMutes(context.Context, string) (dto.NotificationMuteResponse, error) {
+	return dto.NotificationMuteResponse{Types: []string{}}, nil
+}
+
+func (s *stubNotificationService) Mute(_ context.Context, _ string, notificationType string) (dto.NotificationMuteResponse, error) {
+	return dto.NotificationMuteResponse{Types: []string{notificationType}}, nil
+}
+
+func (s *stubNotificationService) Unmute(context.Context, string, string) (dto.NotificationMuteResponse, error) {
+	return dto.NotificationMuteResponse{Types: []string{}}, nil
+}
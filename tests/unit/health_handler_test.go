@@ -1,7 +1,9 @@
 package unit
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http/httptest"
 	"testing"
 	"time"
@@ -44,3 +46,56 @@ func TestHealthCheck(t *testing.T) {
 	assert.Equal(t, cfg.AppEnv, payload.Data.Environment)
 	assert.WithinDuration(t, time.Now().UTC(), payload.Data.Timestamp, 2*time.Second)
 }
+
+type readinessResponse struct {
+	Success bool                      `json:"success"`
+	Data    handler.ReadinessResponse `json:"data"`
+	Details handler.ReadinessResponse `json:"details"`
+}
+
+func TestReadinessCheck(t *testing.T) {
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	app := fiber.New()
+	app.Get("/ready", handler.ReadinessCheck(
+		handler.ReadinessProbe{Name: "postgres", Check: up},
+		handler.ReadinessProbe{Name: "docker", Check: down, Optional: true},
+	))
+	app.Get("/unready", handler.ReadinessCheck(
+		handler.ReadinessProbe{Name: "postgres", Check: up},
+		handler.ReadinessProbe{Name: "redis", Check: hang, Timeout: 20 * time.Millisecond},
+		handler.ReadinessProbe{Name: "nats", Check: hang, Timeout: 20 * time.Millisecond},
+	))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/ready", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var ready readinessResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&ready))
+	assert.True(t, ready.Success)
+	assert.Equal(t, "degraded", ready.Data.Status)
+	assert.Equal(t, "ok", ready.Data.Dependencies["postgres"].Status)
+	assert.Equal(t, "down", ready.Data.Dependencies["docker"].Status)
+	assert.Equal(t, "connection refused", ready.Data.Dependencies["docker"].Error)
+
+	start := time.Now()
+	resp, err = app.Test(httptest.NewRequest("GET", "/unready", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+	// Both hanging probes time out together rather than one after another.
+	assert.Less(t, time.Since(start), time.Second)
+
+	var unready readinessResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&unready))
+	assert.False(t, unready.Success)
+	assert.Equal(t, "unavailable", unready.Details.Status)
+	assert.Equal(t, "ok", unready.Details.Dependencies["postgres"].Status)
+	assert.Equal(t, "down", unready.Details.Dependencies["redis"].Status)
+	assert.Equal(t, "down", unready.Details.Dependencies["nats"].Status)
+}